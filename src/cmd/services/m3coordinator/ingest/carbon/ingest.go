@@ -25,6 +25,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"regexp"
 	"sync"
@@ -60,6 +61,11 @@ var (
 	carbonSeparatorByte  = byte('.')
 	carbonSeparatorBytes = []byte{carbonSeparatorByte}
 
+	// Used for parsing tagged carbon names, e.g. cpu.load;host=a;dc=x.
+	carbonTagSeparatorByte  = byte(';')
+	carbonTagSeparatorBytes = []byte{carbonTagSeparatorByte}
+	carbonTagValueSeparator = []byte{'='}
+
 	errCannotGenerateTagsFromEmptyName = errors.New("cannot generate tags from empty name")
 	errIOptsMustBeSet                  = errors.New("carbon ingester options: instrument options must be st")
 	errWorkerPoolMustBeSet             = errors.New("carbon ingester options: worker pool must be set")
@@ -90,12 +96,26 @@ func (o *Options) Validate() error {
 	return nil
 }
 
+// Ingester ingests carbon metrics, handling connections sending the plaintext
+// protocol.
+type Ingester interface {
+	m3xserver.Handler
+
+	// HandlePacket ingests the plaintext protocol lines in a packet, such as
+	// those received over UDP.
+	HandlePacket(packet []byte)
+
+	// PickleHandler returns a handler for connections sending the pickle
+	// protocol, which ingests metrics using the same rules.
+	PickleHandler() m3xserver.Handler
+}
+
 // NewIngester returns an ingester for carbon metrics.
 func NewIngester(
 	downsamplerAndWriter ingest.DownsamplerAndWriter,
 	rules CarbonIngesterRules,
 	opts Options,
-) (m3xserver.Handler, error) {
+) (Ingester, error) {
 	err := opts.Validate()
 	if err != nil {
 		return nil, err
@@ -154,41 +174,11 @@ type ingester struct {
 }
 
 func (i *ingester) Handle(conn net.Conn) {
-	var (
-		// Interfaces require a context be passed, but M3DB client already has timeouts
-		// built in and allocating a new context each time is expensive so we just pass
-		// the same context always and rely on M3DB client timeouts.
-		ctx    = context.Background()
-		wg     = sync.WaitGroup{}
-		s      = carbon.NewScanner(conn, i.opts.InstrumentOptions)
-		logger = i.opts.InstrumentOptions.Logger()
-	)
-
+	logger := i.opts.InstrumentOptions.Logger()
 	logger.Debug("handling new carbon ingestion connection")
-	for s.Scan() {
-		name, timestamp, value := s.Metric()
-
-		resources := i.getLineResources()
-		// Copy name since scanner bytes are recycled.
-		resources.name = append(resources.name[:0], name...)
 
-		wg.Add(1)
-		i.opts.WorkerPool.Go(func() {
-			ok := i.write(ctx, resources, timestamp, value)
-			if ok {
-				i.metrics.success.Inc(1)
-			}
-			// The contract is that after the DownsamplerAndWriter returns, any resources
-			// that it needed to hold onto have already been copied.
-			i.putLineResources(resources)
-			wg.Done()
-		})
-
-		i.metrics.malformed.Inc(int64(s.MalformedCount))
-		s.MalformedCount = 0
-	}
-
-	if err := s.Err(); err != nil {
+	wg := sync.WaitGroup{}
+	if err := i.handlePlaintext(conn, &wg); err != nil {
 		logger.Errorf("encountered error during carbon ingestion when scanning connection: %s", err)
 	}
 
@@ -199,6 +189,65 @@ func (i *ingester) Handle(conn net.Conn) {
 	// Don't close the connection, that is the server's responsibility.
 }
 
+func (i *ingester) HandlePacket(packet []byte) {
+	wg := sync.WaitGroup{}
+	if err := i.handlePlaintext(bytes.NewReader(packet), &wg); err != nil {
+		i.logger.Errorf("encountered error during carbon ingestion when scanning packet: %s", err)
+	}
+
+	// Wait for the writes to complete so that a flood of packets applies
+	// backpressure rather than queueing unbounded writes.
+	wg.Wait()
+}
+
+func (i *ingester) PickleHandler() m3xserver.Handler {
+	return &pickleHandler{ingester: i}
+}
+
+// handlePlaintext ingests the carbon plaintext lines read from r, adding the
+// writes it starts to wg.
+func (i *ingester) handlePlaintext(r io.Reader, wg *sync.WaitGroup) error {
+	s := carbon.NewScanner(r, i.opts.InstrumentOptions)
+	for s.Scan() {
+		name, timestamp, value := s.Metric()
+		i.ingest(name, timestamp, value, wg)
+
+		i.metrics.malformed.Inc(int64(s.MalformedCount))
+		s.MalformedCount = 0
+	}
+
+	return s.Err()
+}
+
+// ingest asynchronously writes a single metric, adding the write to wg.
+func (i *ingester) ingest(
+	name []byte,
+	timestamp time.Time,
+	value float64,
+	wg *sync.WaitGroup,
+) {
+	// Interfaces require a context be passed, but M3DB client already has timeouts
+	// built in and allocating a new context each time is expensive so we just pass
+	// the same context always and rely on M3DB client timeouts.
+	ctx := context.Background()
+
+	resources := i.getLineResources()
+	// Copy name since the bytes of the caller are recycled.
+	resources.name = append(resources.name[:0], name...)
+
+	wg.Add(1)
+	i.opts.WorkerPool.Go(func() {
+		ok := i.write(ctx, resources, timestamp, value)
+		if ok {
+			i.metrics.success.Inc(1)
+		}
+		// The contract is that after the DownsamplerAndWriter returns, any resources
+		// that it needed to hold onto have already been copied.
+		i.putLineResources(resources)
+		wg.Done()
+	})
+}
+
 func (i *ingester) write(
 	ctx context.Context,
 	resources *lineResources,
@@ -215,7 +264,7 @@ func (i *ingester) write(
 	}
 
 	for _, rule := range i.rules {
-		if rule.rule.Pattern == graphite.MatchAllPattern || rule.regexp.Match(resources.name) {
+		if rule.matches(resources.name) {
 			// Each rule should only have either mapping rules or storage policies so
 			// one of these should be a no-op.
 			downsampleAndStoragePolicies.DownsampleMappingRules = rule.mappingRules
@@ -291,6 +340,8 @@ type carbonIngesterMetrics struct {
 //      __g0__:foo
 //      __g1__:bar
 //      __g2__:baz
+// Tagged names of the form path;tag1=value1;tag2=value2 are instead turned into
+// the tags name=path, tag1=value1 and tag2=value2.
 func GenerateTagsFromName(
 	name []byte,
 	opts models.TagOptions,
@@ -317,6 +368,10 @@ func generateTagsFromName(
 		return models.EmptyTags(), errCannotGenerateTagsFromEmptyName
 	}
 
+	if bytes.IndexByte(name, carbonTagSeparatorByte) != -1 {
+		return generateTagsFromTaggedName(name, opts, tags)
+	}
+
 	numTags := bytes.Count(name, carbonSeparatorBytes) + 1
 
 	if cap(tags) >= numTags {
@@ -362,6 +417,51 @@ func generateTagsFromName(
 	return models.Tags{Opts: opts, Tags: tags}, nil
 }
 
+func generateTagsFromTaggedName(
+	name []byte,
+	opts models.TagOptions,
+	tags []models.Tag,
+) (models.Tags, error) {
+	numTags := bytes.Count(name, carbonTagSeparatorBytes) + 1
+	if cap(tags) >= numTags {
+		tags = tags[:0]
+	} else {
+		tags = make([]models.Tag, 0, numTags)
+	}
+
+	parts := bytes.Split(name, carbonTagSeparatorBytes)
+	if len(parts[0]) == 0 {
+		return models.EmptyTags(),
+			fmt.Errorf("carbon metric: %s has an empty path", string(name))
+	}
+
+	tags = append(tags, models.Tag{
+		Name:  models.GraphiteNameTag,
+		Value: parts[0],
+	})
+
+	for _, part := range parts[1:] {
+		idx := bytes.Index(part, carbonTagValueSeparator)
+		if idx <= 0 || idx == len(part)-1 {
+			return models.EmptyTags(),
+				fmt.Errorf("carbon metric: %s has invalid tag: %s", string(name), string(part))
+		}
+
+		tagName := part[:idx]
+		if bytes.Equal(tagName, models.GraphiteNameTag) {
+			return models.EmptyTags(),
+				fmt.Errorf("carbon metric: %s uses reserved tag: %s", string(name), string(tagName))
+		}
+
+		tags = append(tags, models.Tag{
+			Name:  tagName,
+			Value: part[idx+1:],
+		})
+	}
+
+	return models.Tags{Opts: opts, Tags: tags}.Normalize(), nil
+}
+
 // Compile all the carbon ingestion rules into regexp so that we can
 // perform matching. Also, generate all the mapping rules and storage
 // policies that we will need to pass to the DownsamplerAndWriter upfront
@@ -378,16 +478,19 @@ func compileRules(rules CarbonIngesterRules) ([]ruleAndRegex, error) {
 			return nil, err
 		}
 
-		storagePolicies := []policy.StoragePolicy{}
-		for _, currPolicy := range rule.Policies {
-			storagePolicy := policy.NewStoragePolicy(
-				currPolicy.Resolution, xtime.Second, currPolicy.Retention)
-			storagePolicies = append(storagePolicies, storagePolicy)
+		var andCompiled *regexp.Regexp
+		if rule.AndPattern != "" {
+			andCompiled, err = regexp.Compile(rule.AndPattern)
+			if err != nil {
+				return nil, err
+			}
 		}
 
+		storagePolicies := rule.StoragePolicies()
 		compiledRule := ruleAndRegex{
-			rule:   rule,
-			regexp: compiled,
+			rule:      rule,
+			regexp:    compiled,
+			andRegexp: andCompiled,
 		}
 
 		if rule.Aggregation.EnabledOrDefault() {
@@ -439,6 +542,16 @@ type lineResources struct {
 type ruleAndRegex struct {
 	rule            config.CarbonIngesterRuleConfiguration
 	regexp          *regexp.Regexp
+	andRegexp       *regexp.Regexp
 	mappingRules    []downsample.MappingRule
 	storagePolicies []policy.StoragePolicy
 }
+
+// matches returns whether the rule applies to the given metric name.
+func (r ruleAndRegex) matches(name []byte) bool {
+	if r.rule.Pattern != graphite.MatchAllPattern && !r.regexp.Match(name) {
+		return false
+	}
+
+	return r.andRegexp == nil || r.andRegexp.Match(name)
+}
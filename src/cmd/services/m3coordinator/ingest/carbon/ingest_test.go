@@ -149,6 +149,21 @@ var (
 					},
 				},
 			},
+			// Only matches names that also match the and pattern.
+			{
+				Pattern:    ".*match-regex4.*",
+				AndPattern: `\.count$`,
+				Aggregation: config.CarbonIngesterAggregationConfiguration{
+					Enabled: truePtr,
+					Type:    aggregateLastPtr,
+				},
+				Policies: []config.CarbonIngesterStoragePolicyConfiguration{
+					{
+						Resolution: time.Minute,
+						Retention:  24 * time.Hour,
+					},
+				},
+			},
 		},
 	}
 
@@ -184,6 +199,16 @@ var (
 				policy.NewStoragePolicy(time.Hour, xtime.Second, 7*24*time.Hour),
 			},
 		},
+		"match-regex4": ingest.WriteOptions{
+			DownsampleOverride: true,
+			DownsampleMappingRules: []downsample.MappingRule{
+				{
+					Aggregations: []aggregation.Type{aggregation.Last},
+					Policies:     []policy.StoragePolicy{policy.NewStoragePolicy(time.Minute, xtime.Second, 24*time.Hour)},
+				},
+			},
+			WriteOverride: true,
+		},
 	}
 )
 
@@ -272,7 +297,9 @@ func TestIngesterHonorsPatterns(t *testing.T) {
 		"foo.match-regex1.bar.baz 1 1\n" +
 		"foo.match-regex2.bar.baz 2 2\n" +
 		"foo.match-regex3.bar.baz 3 3\n" +
-		"foo.match-not-regex.bar.baz 4 4")
+		"foo.match-not-regex.bar.baz 4 4\n" +
+		"foo.match-regex4.bar.count 5 5\n" +
+		"foo.match-regex4.bar.baz 6 6")
 	byteConn := &byteConn{b: bytes.NewBuffer(packet)}
 	ingester, err := NewIngester(mockDownsamplerAndWriter, testRulesWithPatterns, testOptions)
 	require.NoError(t, err)
@@ -297,6 +324,12 @@ func TestIngesterHonorsPatterns(t *testing.T) {
 			timestamp: 3,
 			value:     3,
 		},
+		{
+			metric:    []byte("foo.match-regex4.bar.count"),
+			tags:      mustGenerateTagsFromName(t, []byte("foo.match-regex4.bar.count")),
+			timestamp: 5,
+			value:     5,
+		},
 	}, found)
 }
 
@@ -342,6 +375,30 @@ func TestGenerateTagsFromName(t *testing.T) {
 			expectedErr:  fmt.Errorf("carbon metric: foo.bar.baz.. has duplicate separator"),
 			expectedTags: []models.Tag{},
 		},
+		{
+			name: "foo.bar;host=a;dc=x",
+			id:   "foo.bar;dc=x;host=a",
+			expectedTags: []models.Tag{
+				{Name: []byte("dc"), Value: []byte("x")},
+				{Name: []byte("host"), Value: []byte("a")},
+				{Name: []byte("name"), Value: []byte("foo.bar")},
+			},
+		},
+		{
+			name:         "foo.bar;host",
+			expectedErr:  fmt.Errorf("carbon metric: foo.bar;host has invalid tag: host"),
+			expectedTags: []models.Tag{},
+		},
+		{
+			name:         "foo.bar;name=baz",
+			expectedErr:  fmt.Errorf("carbon metric: foo.bar;name=baz uses reserved tag: name"),
+			expectedTags: []models.Tag{},
+		},
+		{
+			name:         ";host=a",
+			expectedErr:  fmt.Errorf("carbon metric: ;host=a has an empty path"),
+			expectedTags: []models.Tag{},
+		},
 	}
 
 	opts := models.NewTagOptions().SetIDSchemeType(models.TypeGraphite)
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/carbon"
+
+	"github.com/hydrogen18/stalecucumber"
+)
+
+const (
+	// Pickle messages are prefixed by their length as a big endian uint32.
+	pickleHeaderLength = 4
+	// Same as the maximum message length of the graphite carbon receiver.
+	maxPickleMessageLength = 1 << 20
+)
+
+// pickleHandler ingests metrics from connections sending the carbon pickle
+// protocol, where each message is a pickled list of
+// (path, (timestamp, value)) tuples.
+type pickleHandler struct {
+	ingester *ingester
+}
+
+func (h *pickleHandler) Handle(conn net.Conn) {
+	var (
+		i       = h.ingester
+		logger  = i.opts.InstrumentOptions.Logger()
+		wg      = sync.WaitGroup{}
+		r       = bufio.NewReader(conn)
+		header  [pickleHeaderLength]byte
+		message []byte
+	)
+
+	logger.Debug("handling new carbon pickle ingestion connection")
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if err != io.EOF {
+				logger.Errorf("encountered error during carbon pickle ingestion when reading connection: %s", err)
+			}
+			break
+		}
+
+		length := binary.BigEndian.Uint32(header[:])
+		if length > maxPickleMessageLength {
+			// The connection cannot be resynchronized without reading the message, so
+			// stop reading from it altogether.
+			logger.Errorf("carbon pickle message length: %d exceeds max length: %d, closing connection",
+				length, maxPickleMessageLength)
+			i.metrics.malformed.Inc(1)
+			break
+		}
+
+		if cap(message) < int(length) {
+			message = make([]byte, length)
+		}
+		message = message[:length]
+		if _, err := io.ReadFull(r, message); err != nil {
+			logger.Errorf("encountered error during carbon pickle ingestion when reading connection: %s", err)
+			break
+		}
+
+		metrics, malformed, err := parsePickleMessage(message)
+		if err != nil {
+			logger.Errorf("err parsing carbon pickle message: %s", err)
+			i.metrics.malformed.Inc(1)
+			continue
+		}
+
+		for _, m := range metrics {
+			i.ingest(m.Name, m.Time, m.Val, &wg)
+		}
+		i.metrics.malformed.Inc(int64(malformed))
+	}
+
+	logger.Debugf("waiting for outstanding carbon pickle ingestion writes to complete")
+	wg.Wait()
+	logger.Debugf("all outstanding writes completed, shutting down carbon pickle ingestion handler")
+
+	// Don't close the connection, that is the server's responsibility.
+}
+
+func (h *pickleHandler) Close() {
+	// Shares the state of the ingester, so there is nothing to do here.
+}
+
+// parsePickleMessage parses a pickled list of (path, (timestamp, value))
+// tuples into metrics, also returning the number of malformed tuples skipped.
+func parsePickleMessage(message []byte) ([]carbon.Metric, int, error) {
+	unpickled, err := stalecucumber.Unpickle(bytes.NewReader(message))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tuples, ok := unpickled.([]interface{})
+	if !ok {
+		return nil, 0, fmt.Errorf("expected a list of metrics, got: %T", unpickled)
+	}
+
+	var (
+		metrics   = make([]carbon.Metric, 0, len(tuples))
+		malformed int
+	)
+	for _, tuple := range tuples {
+		m, ok := parsePickleMetric(tuple)
+		if !ok {
+			malformed++
+			continue
+		}
+
+		metrics = append(metrics, m)
+	}
+
+	return metrics, malformed, nil
+}
+
+func parsePickleMetric(tuple interface{}) (carbon.Metric, bool) {
+	pathAndDatapoint, ok := tuple.([]interface{})
+	if !ok || len(pathAndDatapoint) != 2 {
+		return carbon.Metric{}, false
+	}
+
+	var name []byte
+	switch path := pathAndDatapoint[0].(type) {
+	case string:
+		name = []byte(path)
+	case []byte:
+		name = path
+	default:
+		return carbon.Metric{}, false
+	}
+
+	if len(name) == 0 {
+		return carbon.Metric{}, false
+	}
+
+	datapoint, ok := pathAndDatapoint[1].([]interface{})
+	if !ok || len(datapoint) != 2 {
+		return carbon.Metric{}, false
+	}
+
+	timestamp, ok := pickleNumber(datapoint[0])
+	if !ok || math.IsNaN(timestamp) || math.IsInf(timestamp, 0) {
+		return carbon.Metric{}, false
+	}
+
+	value, ok := pickleNumber(datapoint[1])
+	if !ok {
+		return carbon.Metric{}, false
+	}
+
+	// Like the plaintext protocol, timestamps have a resolution of seconds.
+	return carbon.Metric{
+		Name: name,
+		Time: time.Unix(int64(timestamp), 0),
+		Val:  value,
+	}, true
+}
+
+func pickleNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
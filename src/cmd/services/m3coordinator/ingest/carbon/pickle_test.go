@@ -0,0 +1,156 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/api/v1/handler/graphite/pickle"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testPickleMetric struct {
+	name      string
+	timestamp int
+	value     interface{}
+}
+
+func newTestPickleMessage(t *testing.T, metrics []testPickleMetric) []byte {
+	var buf bytes.Buffer
+	w := pickle.NewWriter(&buf)
+	w.BeginList()
+	for _, m := range metrics {
+		w.BeginList()
+		w.WriteString(m.name)
+		w.BeginList()
+		w.WriteInt(m.timestamp)
+		switch v := m.value.(type) {
+		case float64:
+			w.WriteFloat64(v)
+		case int:
+			w.WriteInt(v)
+		default:
+			w.WriteNone()
+		}
+		w.EndList()
+		w.EndList()
+	}
+	w.EndList()
+	require.NoError(t, w.Close())
+
+	message := make([]byte, pickleHeaderLength, pickleHeaderLength+buf.Len())
+	binary.BigEndian.PutUint32(message, uint32(buf.Len()))
+	return append(message, buf.Bytes()...)
+}
+
+func newRecordingDownsamplerAndWriter(
+	ctrl *gomock.Controller,
+) (*ingest.MockDownsamplerAndWriter, func() []testMetric) {
+	var (
+		lock  sync.Mutex
+		found []testMetric
+	)
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().
+		Write(gomock.Any(), gomock.Any(), gomock.Any(), xtime.Second, gomock.Any()).DoAndReturn(func(
+		_ context.Context,
+		tags models.Tags,
+		dp ts.Datapoints,
+		unit xtime.Unit,
+		overrides ingest.WriteOptions,
+	) interface{} {
+		lock.Lock()
+		// Clone tags because they (and their underlying bytes) are pooled.
+		found = append(found, testMetric{
+			tags: tags.Clone(), timestamp: int(dp[0].Timestamp.Unix()), value: dp[0].Value})
+		lock.Unlock()
+		return nil
+	}).AnyTimes()
+
+	return mockDownsamplerAndWriter, func() []testMetric {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]testMetric(nil), found...)
+	}
+}
+
+func TestPickleHandlerHandleConn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDownsamplerAndWriter, found := newRecordingDownsamplerAndWriter(ctrl)
+	ingester, err := NewIngester(mockDownsamplerAndWriter, testRulesMatchAll, testOptions)
+	require.NoError(t, err)
+
+	var packet []byte
+	packet = append(packet, newTestPickleMessage(t, []testPickleMetric{
+		{name: "foo.bar", timestamp: 1, value: 1.5},
+		{name: "foo.baz;host=a", timestamp: 2, value: 2},
+		// Skipped since the value is not a number.
+		{name: "foo.none", timestamp: 3},
+	})...)
+	packet = append(packet, newTestPickleMessage(t, []testPickleMetric{
+		{name: "foo.qux", timestamp: 4, value: 4.0},
+	})...)
+	// Skipped since the message is not a valid pickle.
+	packet = append(packet, 0, 0, 0, 3, 'f', 'o', 'o')
+	packet = append(packet, newTestPickleMessage(t, []testPickleMetric{
+		{name: "foo.quux", timestamp: 5, value: 5.0},
+	})...)
+
+	ingester.PickleHandler().Handle(&byteConn{b: bytes.NewBuffer(packet)})
+
+	assertTestMetricsAreEqual(t, []testMetric{
+		{tags: mustGenerateTagsFromName(t, []byte("foo.bar")), timestamp: 1, value: 1.5},
+		{tags: mustGenerateTagsFromName(t, []byte("foo.baz;host=a")), timestamp: 2, value: 2},
+		{tags: mustGenerateTagsFromName(t, []byte("foo.qux")), timestamp: 4, value: 4},
+		{tags: mustGenerateTagsFromName(t, []byte("foo.quux")), timestamp: 5, value: 5},
+	}, found())
+}
+
+func TestPickleHandlerRejectsLargeMessages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDownsamplerAndWriter, found := newRecordingDownsamplerAndWriter(ctrl)
+	ingester, err := NewIngester(mockDownsamplerAndWriter, testRulesMatchAll, testOptions)
+	require.NoError(t, err)
+
+	packet := make([]byte, pickleHeaderLength)
+	binary.BigEndian.PutUint32(packet, maxPickleMessageLength+1)
+	// Not read since the connection cannot be resynchronized.
+	packet = append(packet, newTestPickleMessage(t, []testPickleMetric{
+		{name: "foo.bar", timestamp: 1, value: 1.0},
+	})...)
+
+	ingester.PickleHandler().Handle(&byteConn{b: bytes.NewBuffer(packet)})
+	require.Empty(t, found())
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestcarbon
+
+import (
+	"net"
+	"sync"
+
+	"github.com/m3db/m3x/instrument"
+	"github.com/m3db/m3x/log"
+)
+
+// Packets larger than the maximum UDP payload cannot be received.
+const maxUDPPacketSize = 65535
+
+// UDPServer ingests the carbon plaintext protocol lines received as UDP
+// packets.
+type UDPServer struct {
+	address  string
+	ingester Ingester
+	logger   log.Logger
+
+	conn   net.PacketConn
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewUDPServer returns a new UDP server ingesting packets using the ingester.
+func NewUDPServer(
+	address string,
+	ingester Ingester,
+	iOpts instrument.Options,
+) *UDPServer {
+	return &UDPServer{
+		address:  address,
+		ingester: ingester,
+		logger:   iOpts.Logger(),
+		closed:   make(chan struct{}),
+	}
+}
+
+// ListenAndServe listens on the address of the server and serves the received
+// packets in the background until the server is closed.
+func (s *UDPServer) ListenAndServe() error {
+	conn, err := net.ListenPacket("udp", s.address)
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	s.wg.Add(1)
+	go s.serve()
+	return nil
+}
+
+func (s *UDPServer) serve() {
+	defer s.wg.Done()
+
+	buf := make([]byte, maxUDPPacketSize)
+	for {
+		n, _, err := s.conn.ReadFrom(buf)
+		if n > 0 {
+			s.ingester.HandlePacket(buf[:n])
+		}
+
+		if err != nil {
+			select {
+			case <-s.closed:
+				return
+			default:
+				s.logger.Errorf("encountered error during carbon ingestion when reading udp packet: %s", err)
+			}
+		}
+	}
+}
+
+// Close stops the server, waiting for the packets being ingested.
+func (s *UDPServer) Close() {
+	close(s.closed)
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.wg.Wait()
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestopentsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	xtime "github.com/m3db/m3x/time"
+)
+
+const (
+	// maxSecondsTimestamp is the largest timestamp in seconds, larger
+	// timestamps are in milliseconds as in OpenTSDB.
+	maxSecondsTimestamp = 9999999999
+)
+
+var (
+	errMissingMetric    = errors.New("missing metric name")
+	errMissingTags      = errors.New("missing tags, at least one tag is required")
+	errInvalidTimestamp = errors.New("invalid timestamp, must be positive")
+)
+
+// Datapoint is a single OpenTSDB datapoint, as sent to the put API.
+type Datapoint struct {
+	Metric    string            `json:"metric"`
+	Timestamp int64             `json:"timestamp"`
+	Value     json.Number       `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// ParsePut parses the arguments of a telnet style put command, which are
+// the metric, timestamp, value and at least one tag:
+//
+//	put <metric> <timestamp> <value> <tagk1=tagv1 ...>
+func ParsePut(args []string) (Datapoint, error) {
+	if len(args) < 4 {
+		return Datapoint{}, fmt.Errorf(
+			"not enough arguments (need at least 4, got %d)", len(args))
+	}
+
+	timestamp, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return Datapoint{}, fmt.Errorf("invalid timestamp: %s", args[1])
+	}
+
+	dp := Datapoint{
+		Metric:    args[0],
+		Timestamp: timestamp,
+		Value:     json.Number(args[2]),
+		Tags:      make(map[string]string, len(args)-3),
+	}
+
+	for _, arg := range args[3:] {
+		idx := strings.IndexByte(arg, '=')
+		if idx < 0 {
+			return Datapoint{}, fmt.Errorf("invalid tag: %s", arg)
+		}
+
+		dp.Tags[arg[:idx]] = arg[idx+1:]
+	}
+
+	return dp, nil
+}
+
+// Series validates the datapoint and converts it to the tags, datapoints and
+// unit of a write, with the metric stored as the name tag.
+func (d Datapoint) Series(
+	tagOpts models.TagOptions,
+) (models.Tags, ts.Datapoints, xtime.Unit, error) {
+	if d.Metric == "" {
+		return models.EmptyTags(), nil, 0, errMissingMetric
+	}
+
+	if err := validateName("metric", d.Metric); err != nil {
+		return models.EmptyTags(), nil, 0, err
+	}
+
+	if len(d.Tags) == 0 {
+		return models.EmptyTags(), nil, 0, errMissingTags
+	}
+
+	tags := make([]models.Tag, 0, len(d.Tags))
+	for name, value := range d.Tags {
+		if err := validateName("tag name", name); err != nil {
+			return models.EmptyTags(), nil, 0, err
+		}
+
+		if err := validateName("tag value", value); err != nil {
+			return models.EmptyTags(), nil, 0, err
+		}
+
+		tags = append(tags, models.Tag{Name: []byte(name), Value: []byte(value)})
+	}
+
+	if d.Timestamp <= 0 {
+		return models.EmptyTags(), nil, 0, errInvalidTimestamp
+	}
+
+	timestamp, unit := time.Unix(d.Timestamp, 0), xtime.Second
+	if d.Timestamp > maxSecondsTimestamp {
+		timestamp = time.Unix(0, d.Timestamp*int64(time.Millisecond))
+		unit = xtime.Millisecond
+	}
+
+	value, err := strconv.ParseFloat(string(d.Value), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return models.EmptyTags(), nil, 0, fmt.Errorf("invalid value: %s", d.Value)
+	}
+
+	seriesTags := models.NewTags(len(tags)+1, tagOpts).
+		AddTags(tags).
+		SetName([]byte(d.Metric))
+	return seriesTags, ts.Datapoints{{Timestamp: timestamp, Value: value}}, unit, nil
+}
+
+// validateName checks that a metric or tag contains only the characters
+// OpenTSDB allows, which are letters, digits and `-_./`.
+func validateName(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("empty %s", kind)
+	}
+
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+		case r == '-', r == '_', r == '.', r == '/':
+		default:
+			return fmt.Errorf("invalid %s %q: illegal character %q", kind, name, r)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestopentsdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePut(t *testing.T) {
+	dp, err := ParsePut([]string{"sys.cpu.user", "1356998400", "42.5",
+		"host=web01", "cpu=0"})
+	require.NoError(t, err)
+	assert.Equal(t, Datapoint{
+		Metric:    "sys.cpu.user",
+		Timestamp: 1356998400,
+		Value:     "42.5",
+		Tags:      map[string]string{"host": "web01", "cpu": "0"},
+	}, dp)
+
+	for _, args := range [][]string{
+		{"sys.cpu.user", "1356998400", "42.5"},
+		{"sys.cpu.user", "abc", "42.5", "host=web01"},
+		{"sys.cpu.user", "1356998400", "42.5", "host"},
+	} {
+		_, err := ParsePut(args)
+		assert.Error(t, err, "%v", args)
+	}
+}
+
+func TestDatapointSeries(t *testing.T) {
+	tagOpts := models.NewTagOptions()
+	dp := Datapoint{
+		Metric:    "sys.cpu.user",
+		Timestamp: 1356998400123,
+		Value:     "42",
+		Tags:      map[string]string{"host": "web01"},
+	}
+
+	tags, datapoints, unit, err := dp.Series(tagOpts)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Tag{
+		{Name: []byte("__name__"), Value: []byte("sys.cpu.user")},
+		{Name: []byte("host"), Value: []byte("web01")},
+	}, tags.Tags)
+	assert.Equal(t, ts.Datapoints{
+		{Timestamp: time.Unix(1356998400, 123000000), Value: 42},
+	}, datapoints)
+	assert.Equal(t, xtime.Millisecond, unit)
+
+	dp.Timestamp = 1356998400
+	_, datapoints, unit, err = dp.Series(tagOpts)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1356998400, 0), datapoints[0].Timestamp)
+	assert.Equal(t, xtime.Second, unit)
+}
+
+func TestDatapointSeriesInvalid(t *testing.T) {
+	valid := func() Datapoint {
+		return Datapoint{
+			Metric:    "sys.cpu.user",
+			Timestamp: 1356998400,
+			Value:     "42",
+			Tags:      map[string]string{"host": "web01"},
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		modify func(dp *Datapoint)
+	}{
+		{name: "no metric", modify: func(dp *Datapoint) { dp.Metric = "" }},
+		{name: "invalid metric", modify: func(dp *Datapoint) { dp.Metric = "sys cpu" }},
+		{name: "no tags", modify: func(dp *Datapoint) { dp.Tags = nil }},
+		{name: "invalid tag", modify: func(dp *Datapoint) { dp.Tags["host"] = "a=b" }},
+		{name: "empty tag", modify: func(dp *Datapoint) { dp.Tags["host"] = "" }},
+		{name: "no timestamp", modify: func(dp *Datapoint) { dp.Timestamp = 0 }},
+		{name: "invalid value", modify: func(dp *Datapoint) { dp.Value = "abc" }},
+		{name: "nan value", modify: func(dp *Datapoint) { dp.Value = "NaN" }},
+	} {
+		dp := valid()
+		tt.modify(&dp)
+		_, _, _, err := dp.Series(models.NewTagOptions())
+		assert.Error(t, err, tt.name)
+	}
+}
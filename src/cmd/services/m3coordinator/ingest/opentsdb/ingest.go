@@ -0,0 +1,217 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestopentsdb
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3x/instrument"
+	"github.com/m3db/m3x/log"
+	m3xserver "github.com/m3db/m3x/server"
+	xsync "github.com/m3db/m3x/sync"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	putCommand     = "put"
+	versionCommand = "version"
+	exitCommand    = "exit"
+
+	versionResponse = "m3coordinator OpenTSDB put listener\n"
+)
+
+var (
+	errIOptsMustBeSet      = errors.New("opentsdb ingester options: instrument options must be set")
+	errWorkerPoolMustBeSet = errors.New("opentsdb ingester options: worker pool must be set")
+	errTagOptionsMustBeSet = errors.New("opentsdb ingester options: tag options must be set")
+)
+
+// Options configures the ingester.
+type Options struct {
+	InstrumentOptions instrument.Options
+	WorkerPool        xsync.PooledWorkerPool
+	// TagOptions are the tag options of written series, which should match
+	// those used to query them.
+	TagOptions models.TagOptions
+}
+
+// Validate validates the options struct.
+func (o *Options) Validate() error {
+	if o.InstrumentOptions == nil {
+		return errIOptsMustBeSet
+	}
+
+	if o.WorkerPool == nil {
+		return errWorkerPoolMustBeSet
+	}
+
+	if o.TagOptions == nil {
+		return errTagOptionsMustBeSet
+	}
+
+	return nil
+}
+
+// NewIngester returns an ingester handling connections sending the OpenTSDB
+// telnet style protocol, such as those of tcollector. Invalid commands are
+// answered with an error line as in OpenTSDB, while successful puts are not
+// answered.
+func NewIngester(
+	downsamplerAndWriter ingest.DownsamplerAndWriter,
+	opts Options,
+) (m3xserver.Handler, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &ingester{
+		downsamplerAndWriter: downsamplerAndWriter,
+		opts:                 opts,
+		logger:               opts.InstrumentOptions.Logger(),
+		tagOpts:              opts.TagOptions,
+		metrics: newOpenTSDBIngesterMetrics(
+			opts.InstrumentOptions.MetricsScope()),
+	}, nil
+}
+
+type ingester struct {
+	downsamplerAndWriter ingest.DownsamplerAndWriter
+	opts                 Options
+	logger               log.Logger
+	metrics              openTSDBIngesterMetrics
+	tagOpts              models.TagOptions
+}
+
+func (i *ingester) Handle(conn net.Conn) {
+	i.logger.Debug("handling new opentsdb ingestion connection")
+
+	wg := sync.WaitGroup{}
+	if err := i.handleCommands(conn, conn, &wg); err != nil {
+		i.logger.Errorf("encountered error during opentsdb ingestion when scanning connection: %s", err)
+	}
+
+	wg.Wait()
+
+	// Don't close the connection, that is the server's responsibility.
+}
+
+// handleCommands executes the commands read from r, writing responses to w
+// and adding the writes it starts to wg.
+func (i *ingester) handleCommands(r io.Reader, w io.Writer, wg *sync.WaitGroup) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		args := strings.Fields(s.Text())
+		if len(args) == 0 {
+			continue
+		}
+
+		var response string
+		switch args[0] {
+		case putCommand:
+			if err := i.put(args[1:], wg); err != nil {
+				i.metrics.malformed.Inc(1)
+				response = fmt.Sprintf("put: illegal argument: %s\n", err)
+			}
+		case versionCommand:
+			response = versionResponse
+		case exitCommand:
+			return nil
+		default:
+			response = fmt.Sprintf("unknown command: %s.\n", args[0])
+		}
+
+		if response == "" {
+			continue
+		}
+
+		if _, err := io.WriteString(w, response); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// put asynchronously writes the datapoint described by args, adding the
+// write to wg.
+func (i *ingester) put(args []string, wg *sync.WaitGroup) error {
+	dp, err := ParsePut(args)
+	if err != nil {
+		return err
+	}
+
+	tags, datapoints, unit, err := dp.Series(i.tagOpts)
+	if err != nil {
+		return err
+	}
+
+	wg.Add(1)
+	i.opts.WorkerPool.Go(func() {
+		i.write(tags, datapoints, unit)
+		wg.Done()
+	})
+
+	return nil
+}
+
+func (i *ingester) write(tags models.Tags, datapoints ts.Datapoints, unit xtime.Unit) {
+	// NB: as with carbon ingestion, rely on the M3DB client timeouts rather
+	// than allocating a context per write.
+	err := i.downsamplerAndWriter.Write(context.Background(), tags,
+		datapoints, unit, ingest.WriteOptions{})
+	if err != nil {
+		i.logger.Errorf("err writing opentsdb metric: %s, err: %s", tags.ID(), err)
+		i.metrics.err.Inc(1)
+		return
+	}
+
+	i.metrics.success.Inc(1)
+}
+
+func (i *ingester) Close() {
+	// We don't maintain any state in-between connections so there is nothing to do here.
+}
+
+func newOpenTSDBIngesterMetrics(m tally.Scope) openTSDBIngesterMetrics {
+	return openTSDBIngesterMetrics{
+		success:   m.Counter("success"),
+		err:       m.Counter("error"),
+		malformed: m.Counter("malformed"),
+	}
+}
+
+type openTSDBIngesterMetrics struct {
+	success   tally.Counter
+	err       tally.Counter
+	malformed tally.Counter
+}
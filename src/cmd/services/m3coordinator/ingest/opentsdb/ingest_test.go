@@ -0,0 +1,135 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package ingestopentsdb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3x/instrument"
+	xsync "github.com/m3db/m3x/sync"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIngester(
+	t *testing.T,
+	downsamplerAndWriter ingest.DownsamplerAndWriter,
+) *ingester {
+	workerPool, err := xsync.NewPooledWorkerPool(4, xsync.NewPooledWorkerPoolOptions())
+	require.NoError(t, err)
+	workerPool.Init()
+
+	i, err := NewIngester(downsamplerAndWriter, Options{
+		InstrumentOptions: instrument.NewOptions(),
+		WorkerPool:        workerPool,
+		TagOptions:        models.NewTagOptions(),
+	})
+	require.NoError(t, err)
+	return i.(*ingester)
+}
+
+func TestIngesterHandleCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		lock  sync.Mutex
+		found []string
+	)
+
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().
+		Write(gomock.Any(), gomock.Any(), gomock.Any(), xtime.Second, ingest.WriteOptions{}).
+		DoAndReturn(func(
+			_ context.Context,
+			tags models.Tags,
+			dp ts.Datapoints,
+			_ xtime.Unit,
+			_ ingest.WriteOptions,
+		) error {
+			lock.Lock()
+			found = append(found, string(tags.ID()))
+			lock.Unlock()
+			return nil
+		}).Times(2)
+
+	input := strings.Join([]string{
+		"put sys.cpu.user 1356998400 42.5 host=web01 cpu=0",
+		"",
+		"put sys.cpu.user 1356998400 42.5",
+		"version",
+		"stats",
+		"put sys.cpu.user 1356998401 43 host=web02",
+		"exit",
+		"put sys.cpu.user 1356998402 44 host=web03",
+	}, "\n")
+
+	var output bytes.Buffer
+	i := newTestIngester(t, mockDownsamplerAndWriter)
+	wg := sync.WaitGroup{}
+	require.NoError(t, i.handleCommands(strings.NewReader(input), &output, &wg))
+	wg.Wait()
+
+	assert.Equal(t, strings.Join([]string{
+		"put: illegal argument: not enough arguments (need at least 4, got 3)",
+		strings.TrimSuffix(versionResponse, "\n"),
+		"unknown command: stats.",
+		"",
+	}, "\n"), output.String())
+
+	sort.Strings(found)
+	assert.Equal(t, 2, len(found))
+	assert.Contains(t, found[0], "web01")
+	assert.Contains(t, found[1], "web02")
+}
+
+func TestIngesterHandleCommandsWriteError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().
+		Write(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(io.ErrUnexpectedEOF)
+
+	var output bytes.Buffer
+	i := newTestIngester(t, mockDownsamplerAndWriter)
+	wg := sync.WaitGroup{}
+	err := i.handleCommands(
+		strings.NewReader("put sys.cpu.user 1356998400 42 host=web01\n"), &output, &wg)
+	require.NoError(t, err)
+	wg.Wait()
+
+	// NB: write errors are only logged since the write is asynchronous.
+	assert.Empty(t, output.String())
+}
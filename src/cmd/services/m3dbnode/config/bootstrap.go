@@ -56,9 +56,18 @@ type BootstrapConfiguration struct {
 	// Commitlog bootstrapper configuration.
 	Commitlog *BootstrapCommitlogConfiguration `yaml:"commitlog"`
 
+	// Peers bootstrapper configuration.
+	Peers *BootstrapPeersConfiguration `yaml:"peers"`
+
 	// CacheSeriesMetadata determines whether individual bootstrappers cache
 	// series metadata across all calls (namespaces / shards / blocks).
 	CacheSeriesMetadata *bool `yaml:"cacheSeriesMetadata"`
+
+	// ShardBatchSize is the number of shards of a namespace to bootstrap
+	// together, when set shards begin serving reads as soon as their batch is
+	// bootstrapped rather than once the whole node has bootstrapped. The
+	// index is only queryable once all batches are bootstrapped.
+	ShardBatchSize int `yaml:"shardBatchSize" validate:"min=0"`
 }
 
 func (bsc BootstrapConfiguration) fsNumProcessors() int {
@@ -86,6 +95,18 @@ type BootstrapCommitlogConfiguration struct {
 	ReturnUnfulfilledForCorruptCommitLogFiles bool `yaml:"returnUnfulfilledForCorruptCommitLogFiles"`
 }
 
+// BootstrapPeersConfiguration specifies config for the peers bootstrapper.
+type BootstrapPeersConfiguration struct {
+	// ResumeEnabled controls whether the peers bootstrapper records the blocks
+	// it has persisted so that an interrupted bootstrap can skip them when it
+	// is restarted.
+	ResumeEnabled bool `yaml:"resumeEnabled"`
+
+	// PerPeerLimitMbps is the bandwidth limit in megabits per second when
+	// streaming blocks from any single peer, zero means unlimited.
+	PerPeerLimitMbps float64 `yaml:"perPeerLimitMbps" validate:"min=0.0"`
+}
+
 // New creates a bootstrap process based on the bootstrap configuration.
 func (bsc BootstrapConfiguration) New(
 	opts storage.Options,
@@ -152,7 +173,11 @@ func (bsc BootstrapConfiguration) New(
 				SetAdminClient(adminClient).
 				SetPersistManager(opts.PersistManager()).
 				SetDatabaseBlockRetrieverManager(opts.DatabaseBlockRetrieverManager()).
-				SetRuntimeOptionsManager(opts.RuntimeOptionsManager())
+				SetRuntimeOptionsManager(opts.RuntimeOptionsManager()).
+				SetFilesystemOptions(fsOpts)
+			if peersCfg := bsc.Peers; peersCfg != nil {
+				pOpts = pOpts.SetResumeEnabled(peersCfg.ResumeEnabled)
+			}
 			bs, err = peers.NewPeersBootstrapperProvider(pOpts, bs)
 			if err != nil {
 				return nil, err
@@ -137,6 +137,9 @@ type DBConfiguration struct {
 
 	// Write new series asynchronously for fast ingestion of new ID bursts.
 	WriteNewSeriesAsync bool `yaml:"writeNewSeriesAsync"`
+
+	// Limits on the resources used by queries, omit to disable limits.
+	Limits *LimitsConfiguration `yaml:"limits"`
 }
 
 // InitDefaultsAndValidate initializes all default values and validates the Configuration.
@@ -382,7 +382,9 @@ func TestConfiguration(t *testing.T) {
     fs:
       numProcessorsPerCPU: 0.125
     commitlog: null
+    peers: null
     cacheSeriesMetadata: null
+    shardBatchSize: 0
   blockRetrieve: null
   cache:
     series: null
@@ -606,6 +608,7 @@ func TestConfiguration(t *testing.T) {
   hashing:
     seed: 42
   writeNewSeriesAsync: true
+  limits: null
 coordinator: null
 `
 
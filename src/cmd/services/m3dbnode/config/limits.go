@@ -0,0 +1,68 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"github.com/m3db/m3/src/dbnode/storage/limits"
+
+	"github.com/uber-go/tally"
+)
+
+// LimitsConfiguration is the configuration for limits on the resources used
+// by queries, zero or negative values imply no limit.
+type LimitsConfiguration struct {
+	// PerQuery configures limits which apply to each query individually.
+	PerQuery QueryLimitsConfiguration `yaml:"perQuery"`
+
+	// Global configures limits which apply across all queries in flight.
+	Global QueryLimitsConfiguration `yaml:"global"`
+}
+
+// QueryLimitsConfiguration is the configuration for the limits on each of the
+// resources used by queries.
+type QueryLimitsConfiguration struct {
+	// MaxSeries limits the number of series matched.
+	MaxSeries int64 `yaml:"maxSeries"`
+
+	// MaxBlocks limits the number of blocks read.
+	MaxBlocks int64 `yaml:"maxBlocks"`
+
+	// MaxBytes limits the number of encoded bytes read.
+	MaxBytes int64 `yaml:"maxBytes"`
+}
+
+// NewQueryLimits returns the query limits described by the configuration.
+func (c LimitsConfiguration) NewQueryLimits(scope tally.Scope) limits.QueryLimits {
+	return limits.NewQueryLimits(limits.Limits{
+		Series: limits.Limit{
+			PerQuery: c.PerQuery.MaxSeries,
+			Global:   c.Global.MaxSeries,
+		},
+		Blocks: limits.Limit{
+			PerQuery: c.PerQuery.MaxBlocks,
+			Global:   c.Global.MaxBlocks,
+		},
+		Bytes: limits.Limit{
+			PerQuery: c.PerQuery.MaxBytes,
+			Global:   c.Global.MaxBytes,
+		},
+	}, scope)
+}
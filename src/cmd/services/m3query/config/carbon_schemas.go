@@ -0,0 +1,324 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/query/graphite/graphite"
+)
+
+var (
+	// graphiteUnits are the units supported by graphite retention definitions,
+	// matched by prefix so that e.g. "m" and "min" both refer to minutes.
+	graphiteUnits = []struct {
+		name     string
+		duration time.Duration
+	}{
+		{name: "seconds", duration: time.Second},
+		{name: "minutes", duration: time.Minute},
+		{name: "hours", duration: time.Hour},
+		{name: "days", duration: 24 * time.Hour},
+		{name: "weeks", duration: 7 * 24 * time.Hour},
+		{name: "years", duration: 365 * 24 * time.Hour},
+	}
+
+	graphiteAggregationMethods = map[string]aggregation.Type{
+		"average": aggregation.Mean,
+		"avg":     aggregation.Mean,
+		"sum":     aggregation.Sum,
+		"min":     aggregation.Min,
+		"max":     aggregation.Max,
+		"last":    aggregation.Last,
+	}
+)
+
+// graphiteConfSection is a section of a graphite storage-schemas.conf or
+// storage-aggregation.conf file.
+type graphiteConfSection struct {
+	name   string
+	values map[string]string
+}
+
+// parseGraphiteConf parses the sections of a graphite conf file in order,
+// lower casing option names as carbon does.
+func parseGraphiteConf(r io.Reader) ([]graphiteConfSection, error) {
+	var (
+		sections []graphiteConfSection
+		scanner  = bufio.NewScanner(r)
+		lineNum  = 0
+	)
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections = append(sections, graphiteConfSection{
+				name:   strings.TrimSpace(line[1 : len(line)-1]),
+				values: make(map[string]string),
+			})
+			continue
+		}
+
+		idx := strings.IndexAny(line, "=:")
+		if idx < 0 {
+			return nil, fmt.Errorf("line %d: expected option, got: %s", lineNum, line)
+		}
+
+		if len(sections) == 0 {
+			return nil, fmt.Errorf("line %d: option outside of section: %s", lineNum, line)
+		}
+
+		var (
+			key   = strings.ToLower(strings.TrimSpace(line[:idx]))
+			value = strings.TrimSpace(line[idx+1:])
+		)
+		sections[len(sections)-1].values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// parseGraphiteRetentions parses a graphite retentions definition such as
+// "10s:6h,1m:7d" into storage policies. Precisions without a unit are in
+// seconds and retentions without a unit are a number of points.
+func parseGraphiteRetentions(
+	retentions string,
+) ([]CarbonIngesterStoragePolicyConfiguration, error) {
+	var policies []CarbonIngesterStoragePolicyConfiguration
+	for _, def := range strings.Split(retentions, ",") {
+		parts := strings.Split(strings.TrimSpace(def), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid retention definition: %s", def)
+		}
+
+		precisionValue, precisionUnit, err := parseGraphiteDuration(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if precisionUnit == 0 {
+			precisionUnit = time.Second
+		}
+
+		resolution := time.Duration(precisionValue) * precisionUnit
+		retentionValue, retentionUnit, err := parseGraphiteDuration(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		retention := time.Duration(retentionValue) * retentionUnit
+		if retentionUnit == 0 {
+			// NB: a retention without a unit is the number of points retained.
+			retention = time.Duration(retentionValue) * resolution
+		}
+
+		if resolution <= 0 || retention <= 0 {
+			return nil, fmt.Errorf("invalid retention definition: %s", def)
+		}
+
+		policies = append(policies, CarbonIngesterStoragePolicyConfiguration{
+			Resolution: resolution,
+			Retention:  retention,
+		})
+	}
+
+	return policies, nil
+}
+
+// parseGraphiteDuration parses a value with an optional unit suffix,
+// returning a zero unit if none is specified.
+func parseGraphiteDuration(str string) (int64, time.Duration, error) {
+	str = strings.TrimSpace(str)
+	idx := strings.IndexFunc(str, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if idx < 0 {
+		idx = len(str)
+	}
+
+	value, err := strconv.ParseInt(str[:idx], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid retention value: %s", str)
+	}
+
+	unit := strings.ToLower(str[idx:])
+	if unit == "" {
+		return value, 0, nil
+	}
+
+	for _, u := range graphiteUnits {
+		if strings.HasPrefix(u.name, unit) {
+			return value, u.duration, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("invalid retention unit: %s", str)
+}
+
+type graphiteAggregationRule struct {
+	pattern string
+	aggType aggregation.Type
+}
+
+func parseGraphiteAggregations(r io.Reader) ([]graphiteAggregationRule, error) {
+	sections, err := parseGraphiteConf(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]graphiteAggregationRule, 0, len(sections))
+	for _, section := range sections {
+		pattern, ok := section.values["pattern"]
+		if !ok {
+			return nil, fmt.Errorf("storage aggregation %s: missing pattern", section.name)
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("storage aggregation %s: %v", section.name, err)
+		}
+
+		aggType := aggregation.Mean
+		if method, ok := section.values["aggregationmethod"]; ok {
+			aggType, ok = graphiteAggregationMethods[strings.ToLower(method)]
+			if !ok {
+				return nil, fmt.Errorf("storage aggregation %s: unsupported "+
+					"aggregation method: %s", section.name, method)
+			}
+		}
+
+		rules = append(rules, graphiteAggregationRule{
+			pattern: pattern,
+			aggType: aggType,
+		})
+	}
+
+	return rules, nil
+}
+
+// ParseGraphiteStorageSchemas converts a graphite storage-schemas.conf, and
+// optionally a storage-aggregation.conf, into carbon ingester rules.
+//
+// Since carbon applies the first matching schema and the first matching
+// aggregation to a metric independently, each schema is converted to a rule
+// per aggregation using the aggregation pattern as the rule's and pattern,
+// followed by a rule using graphite's default of averaging if no aggregation
+// matches all metrics. The xFilesFactor of aggregations is not supported.
+func ParseGraphiteStorageSchemas(
+	schemas io.Reader,
+	aggregations io.Reader,
+) ([]CarbonIngesterRuleConfiguration, error) {
+	sections, err := parseGraphiteConf(schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	var aggRules []graphiteAggregationRule
+	if aggregations != nil {
+		aggRules, err = parseGraphiteAggregations(aggregations)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var rules []CarbonIngesterRuleConfiguration
+	for _, section := range sections {
+		pattern, ok := section.values["pattern"]
+		if !ok {
+			return nil, fmt.Errorf("storage schema %s: missing pattern", section.name)
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("storage schema %s: %v", section.name, err)
+		}
+
+		retentions, ok := section.values["retentions"]
+		if !ok {
+			return nil, fmt.Errorf("storage schema %s: missing retentions", section.name)
+		}
+
+		policies, err := parseGraphiteRetentions(retentions)
+		if err != nil {
+			return nil, fmt.Errorf("storage schema %s: %v", section.name, err)
+		}
+
+		matchedAll := false
+		for _, aggRule := range aggRules {
+			rule := newGraphiteRule(pattern, aggRule.aggType, policies)
+			if isGraphiteMatchAllPattern(aggRule.pattern) {
+				rules = append(rules, rule)
+				matchedAll = true
+				break
+			}
+
+			rule.AndPattern = aggRule.pattern
+			rules = append(rules, rule)
+		}
+
+		if !matchedAll {
+			rules = append(rules, newGraphiteRule(pattern, aggregation.Mean, policies))
+		}
+	}
+
+	return rules, nil
+}
+
+func newGraphiteRule(
+	pattern string,
+	aggType aggregation.Type,
+	policies []CarbonIngesterStoragePolicyConfiguration,
+) CarbonIngesterRuleConfiguration {
+	var (
+		enabled = true
+		t       = aggType
+	)
+
+	if isGraphiteMatchAllPattern(pattern) {
+		pattern = graphite.MatchAllPattern
+	}
+
+	return CarbonIngesterRuleConfiguration{
+		Pattern: pattern,
+		Aggregation: CarbonIngesterAggregationConfiguration{
+			Enabled: &enabled,
+			Type:    &t,
+		},
+		Policies: policies,
+	}
+}
+
+func isGraphiteMatchAllPattern(pattern string) bool {
+	return pattern == "" || pattern == graphite.MatchAllPattern
+}
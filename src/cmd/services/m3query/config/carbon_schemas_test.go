@@ -0,0 +1,142 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/aggregation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testStorageSchemas = `
+# Schema definitions for Whisper files.
+[carbon]
+pattern = ^carbon\.
+retentions = 60:90d
+
+[default_1min_for_1day]
+pattern = .*
+retentions = 1m:1d,10min:1w,1h:1y
+`
+
+	testStorageAggregations = `
+[min]
+pattern = \.min$
+xFilesFactor = 0.1
+aggregationMethod = min
+
+[count]
+pattern = \.count$
+xFilesFactor = 0
+aggregationMethod = sum
+
+[default_average]
+pattern = .*
+xFilesFactor = 0.5
+aggregationMethod = average
+`
+)
+
+func TestParseGraphiteStorageSchemas(t *testing.T) {
+	rules, err := ParseGraphiteStorageSchemas(strings.NewReader(testStorageSchemas),
+		strings.NewReader(testStorageAggregations))
+	require.NoError(t, err)
+
+	carbonPolicies := []CarbonIngesterStoragePolicyConfiguration{
+		{Resolution: time.Minute, Retention: 90 * 24 * time.Hour},
+	}
+	defaultPolicies := []CarbonIngesterStoragePolicyConfiguration{
+		{Resolution: time.Minute, Retention: 24 * time.Hour},
+		{Resolution: 10 * time.Minute, Retention: 7 * 24 * time.Hour},
+		{Resolution: time.Hour, Retention: 365 * 24 * time.Hour},
+	}
+
+	expected := []struct {
+		pattern    string
+		andPattern string
+		aggType    aggregation.Type
+		policies   []CarbonIngesterStoragePolicyConfiguration
+	}{
+		{`^carbon\.`, `\.min$`, aggregation.Min, carbonPolicies},
+		{`^carbon\.`, `\.count$`, aggregation.Sum, carbonPolicies},
+		{`^carbon\.`, "", aggregation.Mean, carbonPolicies},
+		{".*", `\.min$`, aggregation.Min, defaultPolicies},
+		{".*", `\.count$`, aggregation.Sum, defaultPolicies},
+		{".*", "", aggregation.Mean, defaultPolicies},
+	}
+
+	require.Equal(t, len(expected), len(rules))
+	for i, ex := range expected {
+		rule := rules[i]
+		assert.Equal(t, ex.pattern, rule.Pattern)
+		assert.Equal(t, ex.andPattern, rule.AndPattern)
+		assert.True(t, rule.Aggregation.EnabledOrDefault())
+		assert.Equal(t, ex.aggType, rule.Aggregation.TypeOrDefault())
+		assert.Equal(t, ex.policies, rule.Policies)
+	}
+}
+
+func TestParseGraphiteStorageSchemasWithoutAggregations(t *testing.T) {
+	rules, err := ParseGraphiteStorageSchemas(strings.NewReader(`
+[stats]
+pattern = ^stats\.
+retentions = 10:2160
+`), nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rules))
+
+	assert.Equal(t, `^stats\.`, rules[0].Pattern)
+	assert.Equal(t, "", rules[0].AndPattern)
+	assert.Equal(t, aggregation.Mean, rules[0].Aggregation.TypeOrDefault())
+	assert.Equal(t, []CarbonIngesterStoragePolicyConfiguration{
+		{Resolution: 10 * time.Second, Retention: 6 * time.Hour},
+	}, rules[0].Policies)
+}
+
+func TestParseGraphiteStorageSchemasErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		schemas      string
+		aggregations string
+	}{
+		{"missing pattern", "[a]\nretentions = 60:90d", ""},
+		{"missing retentions", "[a]\npattern = .*", ""},
+		{"invalid pattern", "[a]\npattern = (\nretentions = 60:90d", ""},
+		{"invalid retention", "[a]\npattern = .*\nretentions = 60", ""},
+		{"invalid unit", "[a]\npattern = .*\nretentions = 60:90q", ""},
+		{"option outside section", "pattern = .*", ""},
+		{"unsupported method", "[a]\npattern = .*\nretentions = 60:90d",
+			"[b]\npattern = .*\naggregationMethod = avg_zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGraphiteStorageSchemas(strings.NewReader(tt.schemas),
+				strings.NewReader(tt.aggregations))
+			require.Error(t, err)
+		})
+	}
+}
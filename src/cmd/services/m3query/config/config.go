@@ -23,6 +23,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"runtime"
 	"time"
 
 	etcdclient "github.com/m3db/m3/src/cluster/client/etcd"
@@ -30,15 +31,20 @@ import (
 	ingestm3msg "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/m3msg"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/server/m3msg"
 	"github.com/m3db/m3/src/metrics/aggregation"
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/rules"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/m3"
+	"github.com/m3db/m3/src/query/tenant"
 	"github.com/m3db/m3/src/x/cost"
 	xdocs "github.com/m3db/m3/src/x/docs"
 	xconfig "github.com/m3db/m3x/config"
 	"github.com/m3db/m3x/config/listenaddress"
 	"github.com/m3db/m3x/instrument"
+	xtime "github.com/m3db/m3x/time"
 )
 
 // BackendStorageType is an enum for different backends.
@@ -50,12 +56,18 @@ const (
 	// M3DBStorageType is for m3db backend.
 	M3DBStorageType BackendStorageType = "m3db"
 
-	defaultCarbonIngesterListenAddress = "0.0.0.0:7204"
-	errNoIDGenerationScheme            = "error: a recent breaking change means that an ID " +
+	defaultCarbonIngesterListenAddress   = "0.0.0.0:7204"
+	defaultOpenTSDBIngesterListenAddress = "0.0.0.0:4242"
+	errNoIDGenerationScheme              = "error: a recent breaking change means that an ID " +
 		"generation scheme is required in coordinator configuration settings. " +
 		"More information is available here: %s"
 
 	defaultQueryConversionCacheSize = 4096
+
+	defaultQuerySplitInterval = 24 * time.Hour
+
+	defaultGraphiteFindCacheSize = 4096
+	defaultGraphiteFindCacheTTL  = time.Minute
 )
 
 var (
@@ -115,6 +127,12 @@ type Configuration struct {
 	// Carbon is the carbon configuration.
 	Carbon *CarbonConfiguration `yaml:"carbon"`
 
+	// OpenTSDB is the OpenTSDB configuration.
+	OpenTSDB *OpenTSDBConfiguration `yaml:"opentsdb"`
+
+	// Prometheus is the Prometheus configuration.
+	Prometheus *PrometheusConfiguration `yaml:"prometheus"`
+
 	// Limits specifies limits on per-query resource usage.
 	Limits LimitsConfiguration `yaml:"limits"`
 
@@ -124,8 +142,19 @@ type Configuration struct {
 	// Cache configurations.
 	Cache CacheConfiguration `yaml:"cache"`
 
+	// QuerySplit configures splitting long range queries into sub-queries
+	// which are executed concurrently, which is disabled if not set.
+	QuerySplit *QuerySplitConfiguration `yaml:"querySplit"`
+
+	// SlowQueryLog configures logging of slow queries, which is disabled if
+	// not set.
+	SlowQueryLog *SlowQueryLogConfiguration `yaml:"slowQueryLog"`
+
 	// ResultOptions are the results options for query.
 	ResultOptions ResultOptions `yaml:"resultOptions"`
+
+	// Rules configures recording and alerting rule evaluation.
+	Rules *rules.Configuration `yaml:"rules"`
 }
 
 // Filter is a query filter type.
@@ -160,6 +189,14 @@ type ResultOptions struct {
 type CacheConfiguration struct {
 	// QueryConversion cache policy.
 	QueryConversion *QueryConversionCacheConfiguration `yaml:"queryConversion"`
+
+	// Results configures caching of range query results, which is disabled
+	// if not set.
+	Results *cache.Configuration `yaml:"results"`
+
+	// GraphiteFind configures caching of the nodes completed by graphite
+	// find and expand queries, which is disabled if not set.
+	GraphiteFind *GraphiteFindCacheConfiguration `yaml:"graphiteFind"`
 }
 
 // QueryConversionCacheConfiguration is the query conversion cache configuration.
@@ -196,6 +233,83 @@ func (q *QueryConversionCacheConfiguration) Validate() error {
 	return nil
 }
 
+// GraphiteFindCacheConfiguration is the graphite find cache configuration.
+type GraphiteFindCacheConfiguration struct {
+	// Size is the number of completed prefixes to cache, which defaults to
+	// 4096.
+	Size *int `yaml:"size"`
+
+	// TTL is how long completed prefixes are cached for, which defaults to a
+	// minute.
+	TTL *time.Duration `yaml:"ttl"`
+}
+
+// SizeOrDefault returns the provided size or the default value if none is
+// provided.
+func (c *GraphiteFindCacheConfiguration) SizeOrDefault() int {
+	if c.Size == nil {
+		return defaultGraphiteFindCacheSize
+	}
+
+	return *c.Size
+}
+
+// TTLOrDefault returns the provided ttl or the default value if none is
+// provided.
+func (c *GraphiteFindCacheConfiguration) TTLOrDefault() time.Duration {
+	if c.TTL == nil {
+		return defaultGraphiteFindCacheTTL
+	}
+
+	return *c.TTL
+}
+
+// QuerySplitConfiguration is the query splitting configuration.
+type QuerySplitConfiguration struct {
+	// Interval is the interval at whose multiples queries are split, which
+	// defaults to a day.
+	Interval *time.Duration `yaml:"interval"`
+
+	// MaxConcurrency is the maximum number of sub-queries of a query which
+	// are executed at once, which defaults to the number of CPUs.
+	MaxConcurrency *int `yaml:"maxConcurrency"`
+}
+
+// IntervalOrDefault returns the provided split interval or the default value
+// if none is provided.
+func (q *QuerySplitConfiguration) IntervalOrDefault() time.Duration {
+	if q.Interval == nil {
+		return defaultQuerySplitInterval
+	}
+
+	return *q.Interval
+}
+
+// MaxConcurrencyOrDefault returns the provided max concurrency or the default
+// value if none is provided.
+func (q *QuerySplitConfiguration) MaxConcurrencyOrDefault() int {
+	if q.MaxConcurrency == nil {
+		return runtime.NumCPU()
+	}
+
+	return *q.MaxConcurrency
+}
+
+// SlowQueryLogConfiguration is the slow query log configuration. Queries
+// exceeding either threshold are logged, a zero threshold is disabled.
+type SlowQueryLogConfiguration struct {
+	// LatencyThreshold is the latency above which queries are logged.
+	LatencyThreshold time.Duration `yaml:"latencyThreshold"`
+
+	// SeriesThreshold is the number of fetched series above which queries
+	// are logged.
+	SeriesThreshold int64 `yaml:"seriesThreshold"`
+
+	// Headers are the request headers included in the log, such as user or
+	// tenant headers, which defaults to the tenant header.
+	Headers []string `yaml:"headers"`
+}
+
 // LimitsConfiguration represents limitations on resource usage in the query instance. Limits are split between per-query
 // and global limits.
 type LimitsConfiguration struct {
@@ -208,6 +322,9 @@ type LimitsConfiguration struct {
 
 	// PerQuery configures limits which apply to each query individually.
 	PerQuery PerQueryLimitsConfiguration `yaml:"perQuery"`
+
+	// PerTenant configures limits which apply to the queries of each tenant.
+	PerTenant PerTenantLimitsConfiguration `yaml:"perTenant"`
 }
 
 // MaxComputedDatapoints is a getter providing backwards compatibility between
@@ -227,6 +344,11 @@ func (lc *LimitsConfiguration) MaxComputedDatapoints() int64 {
 type GlobalLimitsConfiguration struct {
 	// MaxFetchedDatapoints limits the total number of datapoints actually fetched by all queries at any given time.
 	MaxFetchedDatapoints int64 `yaml:"maxFetchedDatapoints"`
+
+	// MaxConcurrentQueries limits the number of queries executing at any
+	// given time, with further queries queued and scheduled round robin
+	// across tenants.
+	MaxConcurrentQueries int `yaml:"maxConcurrentQueries"`
 }
 
 // AsLimitManagerOptions converts this configuration to cost.LimitManagerOptions for MaxFetchedDatapoints.
@@ -247,6 +369,10 @@ type PerQueryLimitsConfiguration struct {
 
 	// MaxFetchedDatapoints limits the number of datapoints actually used by a given query.
 	MaxFetchedDatapoints int64 `yaml:"maxFetchedDatapoints"`
+
+	// MaxGraphiteFindResults limits the number of nodes a graphite find or
+	// expand query may match.
+	MaxGraphiteFindResults int `yaml:"maxGraphiteFindResults"`
 }
 
 // AsLimitManagerOptions converts this configuration to cost.LimitManagerOptions for MaxFetchedDatapoints.
@@ -254,6 +380,40 @@ func (l *PerQueryLimitsConfiguration) AsLimitManagerOptions() cost.LimitManagerO
 	return toLimitManagerOptions(l.MaxFetchedDatapoints)
 }
 
+// PerTenantLimitsConfiguration represents limits on resource usage by the queries of each tenant. Zero or negative
+// values imply no limit.
+type PerTenantLimitsConfiguration struct {
+	// Header is the request header identifying the tenant of a query, which
+	// defaults to M3-Tenant. When set, the M3-Tenant header of requests is
+	// ignored so that clients cannot choose their tenant.
+	Header string `yaml:"header"`
+
+	// MaxConcurrentQueries limits the number of queries a tenant can execute
+	// at any given time, with further queries queued.
+	MaxConcurrentQueries int `yaml:"maxConcurrentQueries"`
+
+	// MaxFetchedSeries limits the number of series fetched by the executing
+	// queries of a tenant.
+	MaxFetchedSeries int64 `yaml:"maxFetchedSeries"`
+
+	// MaxFetchedDatapoints limits the number of datapoints fetched by the
+	// executing queries of a tenant.
+	MaxFetchedDatapoints int64 `yaml:"maxFetchedDatapoints"`
+
+	// MaxQueryRange limits the time range of each query.
+	MaxQueryRange time.Duration `yaml:"maxQueryRange"`
+}
+
+// AsLimits converts this configuration to tenant.Limits.
+func (l *PerTenantLimitsConfiguration) AsLimits() tenant.Limits {
+	return tenant.Limits{
+		MaxConcurrentQueries: l.MaxConcurrentQueries,
+		MaxFetchedSeries:     l.MaxFetchedSeries,
+		MaxFetchedDatapoints: l.MaxFetchedDatapoints,
+		MaxQueryRange:        l.MaxQueryRange,
+	}
+}
+
 func toLimitManagerOptions(limit int64) cost.LimitManagerOptions {
 	return cost.NewLimitManagerOptions().SetDefaultLimit(cost.Limit{
 		Threshold: cost.Cost(limit),
@@ -277,10 +437,16 @@ type CarbonConfiguration struct {
 
 // CarbonIngesterConfiguration is the configuration struct for carbon ingestion.
 type CarbonIngesterConfiguration struct {
-	Debug          bool                              `yaml:"debug"`
-	ListenAddress  string                            `yaml:"listenAddress"`
-	MaxConcurrency int                               `yaml:"maxConcurrency"`
-	Rules          []CarbonIngesterRuleConfiguration `yaml:"rules"`
+	Debug         bool   `yaml:"debug"`
+	ListenAddress string `yaml:"listenAddress"`
+	// UDPListenAddress is the address to receive plaintext protocol UDP
+	// packets on, disabled if not set.
+	UDPListenAddress string `yaml:"udpListenAddress"`
+	// PickleListenAddress is the address to accept pickle protocol
+	// connections on, disabled if not set.
+	PickleListenAddress string                            `yaml:"pickleListenAddress"`
+	MaxConcurrency      int                               `yaml:"maxConcurrency"`
+	Rules               []CarbonIngesterRuleConfiguration `yaml:"rules"`
 }
 
 // LookbackDurationOrDefault validates the LookbackDuration
@@ -351,11 +517,26 @@ func (c *CarbonIngesterConfiguration) RulesOrDefault(namespaces m3.ClusterNamesp
 // CarbonIngesterRuleConfiguration is the configuration struct for a carbon
 // ingestion rule.
 type CarbonIngesterRuleConfiguration struct {
-	Pattern     string                                     `yaml:"pattern"`
+	Pattern string `yaml:"pattern"`
+	// AndPattern is an optional pattern that metric names must also match
+	// for the rule to apply, e.g. to combine the patterns of graphite storage
+	// schemas with those of storage aggregations.
+	AndPattern  string                                     `yaml:"andPattern"`
 	Aggregation CarbonIngesterAggregationConfiguration     `yaml:"aggregation"`
 	Policies    []CarbonIngesterStoragePolicyConfiguration `yaml:"policies"`
 }
 
+// StoragePolicies returns the storage policies that metrics matching the
+// rule are written to.
+func (c CarbonIngesterRuleConfiguration) StoragePolicies() []policy.StoragePolicy {
+	storagePolicies := make([]policy.StoragePolicy, 0, len(c.Policies))
+	for _, currPolicy := range c.Policies {
+		storagePolicies = append(storagePolicies, policy.NewStoragePolicy(
+			currPolicy.Resolution, xtime.Second, currPolicy.Retention))
+	}
+	return storagePolicies
+}
+
 // CarbonIngesterAggregationConfiguration is the configuration struct
 // for the aggregation for a carbon ingest rule's storage policy.
 type CarbonIngesterAggregationConfiguration struct {
@@ -390,6 +571,45 @@ type CarbonIngesterStoragePolicyConfiguration struct {
 	Retention  time.Duration `yaml:"retention" validate:"nonzero"`
 }
 
+// OpenTSDBConfiguration is the configuration for the OpenTSDB server.
+type OpenTSDBConfiguration struct {
+	Ingester *OpenTSDBIngesterConfiguration `yaml:"ingester"`
+}
+
+// OpenTSDBIngesterConfiguration is the configuration struct for ingesting
+// datapoints sent with the OpenTSDB telnet put command.
+type OpenTSDBIngesterConfiguration struct {
+	ListenAddress  string `yaml:"listenAddress"`
+	MaxConcurrency int    `yaml:"maxConcurrency"`
+}
+
+// ListenAddressOrDefault returns the specified OpenTSDB ingester listen
+// address if provided, or the default value if not.
+func (c *OpenTSDBIngesterConfiguration) ListenAddressOrDefault() string {
+	if c.ListenAddress != "" {
+		return c.ListenAddress
+	}
+
+	return defaultOpenTSDBIngesterListenAddress
+}
+
+// PrometheusConfiguration is the configuration for Prometheus.
+type PrometheusConfiguration struct {
+	// Metadata configures storing the metric metadata sent with remote
+	// writes, which is dropped if not set.
+	Metadata *PrometheusMetadataConfiguration `yaml:"metadata"`
+}
+
+// PrometheusMetadataConfiguration is the configuration for storing metric
+// metadata.
+type PrometheusMetadataConfiguration struct {
+	// Namespace is the namespace metric metadata is stored in. It must live
+	// in the same cluster as the unaggregated namespace but must not be one
+	// of the configured cluster namespaces, so that it is not queried for
+	// series.
+	Namespace string `yaml:"namespace" validate:"nonzero"`
+}
+
 // LocalConfiguration is the local embedded configuration if running
 // coordinator embedded in the DB.
 type LocalConfiguration struct {
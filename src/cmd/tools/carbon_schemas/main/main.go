@@ -0,0 +1,98 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+// carbon_schemas is a tool for converting graphite storage schemas into
+// carbon ingester rules.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+)
+
+func main() {
+	var (
+		schemasPath     = flag.String("schemas", "", "Path to storage-schemas.conf")
+		aggregationPath = flag.String("aggregation", "", "Optional path to storage-aggregation.conf")
+	)
+
+	flag.Parse()
+	if len(*schemasPath) == 0 {
+		flag.Usage()
+		os.Exit(-1)
+	}
+
+	schemas, err := os.Open(*schemasPath)
+	if err != nil {
+		exitWithError(err)
+	}
+	defer schemas.Close()
+
+	var aggregations io.Reader
+	if len(*aggregationPath) != 0 {
+		f, err := os.Open(*aggregationPath)
+		if err != nil {
+			exitWithError(err)
+		}
+		defer f.Close()
+		aggregations = f
+	}
+
+	rules, err := config.ParseGraphiteStorageSchemas(schemas, aggregations)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	writeRules(os.Stdout, rules)
+}
+
+func writeRules(w io.Writer, rules []config.CarbonIngesterRuleConfiguration) {
+	fmt.Fprintln(w, "carbon:")
+	fmt.Fprintln(w, "  ingester:")
+	fmt.Fprintln(w, "    rules:")
+	for _, rule := range rules {
+		fmt.Fprintf(w, "      - pattern: %s\n", quote(rule.Pattern))
+		if rule.AndPattern != "" {
+			fmt.Fprintf(w, "        andPattern: %s\n", quote(rule.AndPattern))
+		}
+		fmt.Fprintln(w, "        aggregation:")
+		fmt.Fprintf(w, "          enabled: %v\n", rule.Aggregation.EnabledOrDefault())
+		fmt.Fprintf(w, "          type: %s\n", rule.Aggregation.TypeOrDefault())
+		fmt.Fprintln(w, "        policies:")
+		for _, policy := range rule.Policies {
+			fmt.Fprintf(w, "          - resolution: %v\n", policy.Resolution)
+			fmt.Fprintf(w, "            retention: %v\n", policy.Retention)
+		}
+	}
+}
+
+// quote single quotes a string for yaml, which treats backslashes literally.
+func quote(str string) string {
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
+
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
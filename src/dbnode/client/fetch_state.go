@@ -166,6 +166,33 @@ func (f *fetchState) completionFn(
 	}
 }
 
+// cancelOnDone marks the fetch as failed once done is closed, unless it has
+// completed by then, so that the caller waiting on it returns. The returned
+// function must be called once the fetch completes.
+func (f *fetchState) cancelOnDone(done <-chan struct{}) func() {
+	if done == nil {
+		return noopCancel
+	}
+
+	f.incRef() // indicate the cancelling go-routine has a reference to the fetchState
+	completed := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			f.Lock()
+			if !f.done {
+				f.markDoneWithLock(errFetchCancelled)
+			}
+			f.Unlock()
+		case <-completed:
+		}
+
+		f.decRef() // release the ref for the cancelling go-routine
+	}()
+
+	return func() { close(completed) }
+}
+
 func (f *fetchState) markDoneWithLock(err error) {
 	f.done = true
 	f.err = err
@@ -59,6 +59,42 @@ func TestFetchStatePoolValidInteraction(t *testing.T) {
 	require.Nil(t, s.fetchTaggedOp)
 }
 
+func TestFetchStateCancelOnDone(t *testing.T) {
+	s := newFetchState(nil)
+	s.incRef()
+	s.Lock()
+
+	done := make(chan struct{})
+	stopCancelling := s.cancelOnDone(done)
+	close(done)
+	s.Wait()
+	stopCancelling()
+
+	require.True(t, s.done)
+	require.Equal(t, errFetchCancelled, s.err)
+	s.Unlock()
+}
+
+func TestCancelContextOnDone(t *testing.T) {
+	var (
+		done      = make(chan struct{})
+		cancelled = make(chan struct{})
+	)
+
+	stopCancelling := cancelContextOnDone(done, func() { close(cancelled) })
+	close(done)
+	<-cancelled
+	stopCancelling()
+
+	// Completed requests are not cancelled.
+	stopCancelling = cancelContextOnDone(make(chan struct{}), func() {
+		require.FailNow(t, "unexpected cancel")
+	})
+	stopCancelling()
+
+	cancelContextOnDone(nil, nil)()
+}
+
 type testFetchStatePool struct {
 	t             *testing.T
 	expectedState *fetchState
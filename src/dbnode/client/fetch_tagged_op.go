@@ -32,6 +32,7 @@ var (
 type fetchTaggedOp struct {
 	refCounter
 	request      rpc.FetchTaggedRequest
+	done         <-chan struct{}
 	completionFn completionFn
 
 	pool fetchTaggedOpPool
@@ -40,8 +41,9 @@ type fetchTaggedOp struct {
 func (f *fetchTaggedOp) Size() int                  { return 1 }
 func (f *fetchTaggedOp) CompletionFn() completionFn { return f.completionFn }
 
-func (f *fetchTaggedOp) update(req rpc.FetchTaggedRequest, fn completionFn) {
+func (f *fetchTaggedOp) update(req rpc.FetchTaggedRequest, done <-chan struct{}, fn completionFn) {
 	f.request = req
+	f.done = done
 	f.completionFn = fn
 }
 
@@ -54,6 +56,7 @@ func (f *fetchTaggedOp) requestLimit(defaultValue int) int {
 
 func (f *fetchTaggedOp) close() {
 	f.completionFn = nil
+	f.done = nil
 	f.request = fetchTaggedOpRequestZeroed
 	// return to pool
 	if f.pool == nil {
@@ -55,7 +55,7 @@ func TestFetchTaggedOp(t *testing.T) {
 		require.Equal(t, err, e)
 		count++
 	}
-	op.update(rpc.FetchTaggedRequest{}, fn)
+	op.update(rpc.FetchTaggedRequest{}, nil, fn)
 	op.CompletionFn()(inter, err)
 	require.Equal(t, 1, count)
 }
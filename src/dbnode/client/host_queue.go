@@ -491,8 +491,10 @@ func (q *queue) asyncFetchTagged(op *fetchTaggedOp) {
 			return
 		}
 
-		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
+		ctx, cancel := thrift.NewContext(q.opts.FetchRequestTimeout())
+		stopCancelling := cancelContextOnDone(op.done, cancel)
 		result, err := client.FetchTagged(ctx, &op.request)
+		stopCancelling()
 		if err != nil {
 			op.CompletionFn()(fetchTaggedResultAccumulatorOpts{host: q.host}, err)
 			cleanup()
@@ -776,3 +778,25 @@ func (s namespaceWriteTaggedBatchOpsSlice) resetAt(
 	s[index].ops = nil
 	s[index].elems = nil
 }
+
+// cancelContextOnDone calls cancel once done is closed, which cancels the
+// request using the context. The returned function must be called once the
+// request completes.
+func cancelContextOnDone(done <-chan struct{}, cancel func()) func() {
+	if done == nil {
+		return noopCancel
+	}
+
+	completed := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-completed:
+		}
+	}()
+
+	return func() { close(completed) }
+}
+
+func noopCancel() {}
@@ -242,6 +242,7 @@ type options struct {
 	fetchSeriesBlocksMetadataBatchTimeout   time.Duration
 	fetchSeriesBlocksBatchTimeout           time.Duration
 	fetchSeriesBlocksBatchConcurrency       int
+	fetchSeriesBlocksPerPeerLimitMbps       float64
 }
 
 // NewOptions creates a new set of client options with defaults
@@ -848,3 +849,13 @@ func (o *options) SetFetchSeriesBlocksBatchConcurrency(value int) AdminOptions {
 func (o *options) FetchSeriesBlocksBatchConcurrency() int {
 	return o.fetchSeriesBlocksBatchConcurrency
 }
+
+func (o *options) SetFetchSeriesBlocksPerPeerLimitMbps(value float64) AdminOptions {
+	opts := *o
+	opts.fetchSeriesBlocksPerPeerLimitMbps = value
+	return &opts
+}
+
+func (o *options) FetchSeriesBlocksPerPeerLimitMbps() float64 {
+	return o.fetchSeriesBlocksPerPeerLimitMbps
+}
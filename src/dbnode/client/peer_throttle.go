@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+)
+
+const (
+	peerThrottleBytesPerMegabit = 1024 * 1024 / 8
+
+	// peerThrottleWindow is the period after which accounting for a peer is
+	// reset so that time spent idle does not accrue as burst credit.
+	peerThrottleWindow = 10 * time.Second
+)
+
+// peerThrottler limits the bandwidth used when streaming blocks from
+// each individual peer, it is safe for concurrent use.
+type peerThrottler struct {
+	sync.Mutex
+
+	limitMbps float64
+	nowFn     clock.NowFn
+	sleepFn   func(time.Duration)
+	peers     map[string]*peerThrottle
+}
+
+type peerThrottle struct {
+	sync.Mutex
+
+	start time.Time
+	bytes int64
+}
+
+func newPeerThrottler(limitMbps float64, nowFn clock.NowFn) *peerThrottler {
+	return &peerThrottler{
+		limitMbps: limitMbps,
+		nowFn:     nowFn,
+		sleepFn:   time.Sleep,
+		peers:     make(map[string]*peerThrottle),
+	}
+}
+
+// throttle accounts for bytes streamed from a peer and blocks until the
+// bandwidth used by the peer is back under the limit.
+func (t *peerThrottler) throttle(hostID string, bytes int64) {
+	if t == nil || t.limitMbps <= 0 || bytes <= 0 {
+		return
+	}
+
+	p := t.peer(hostID)
+	p.Lock()
+	now := t.nowFn()
+	if p.start.IsZero() || now.Sub(p.start) > peerThrottleWindow {
+		p.start = now
+		p.bytes = 0
+	}
+	p.bytes += bytes
+	target := time.Duration(float64(time.Second) * float64(p.bytes) /
+		(t.limitMbps * peerThrottleBytesPerMegabit))
+	elapsed := now.Sub(p.start)
+	p.Unlock()
+
+	if elapsed < target {
+		t.sleepFn(target - elapsed)
+	}
+}
+
+func (t *peerThrottler) peer(hostID string) *peerThrottle {
+	t.Lock()
+	p, ok := t.peers[hostID]
+	if !ok {
+		p = &peerThrottle{}
+		t.peers[hostID] = p
+	}
+	t.Unlock()
+	return p
+}
+
+func fetchBlocksRawResultBytes(result *rpc.FetchBlocksRawResult_) int64 {
+	var total int64
+	if result == nil {
+		return total
+	}
+	for _, elem := range result.Elements {
+		if elem == nil {
+			continue
+		}
+		for _, block := range elem.Blocks {
+			if block == nil || block.Segments == nil {
+				continue
+			}
+			total += segmentBytes(block.Segments.Merged)
+			for _, segment := range block.Segments.Unmerged {
+				total += segmentBytes(segment)
+			}
+		}
+	}
+	return total
+}
+
+func segmentBytes(segment *rpc.Segment) int64 {
+	if segment == nil {
+		return 0
+	}
+	return int64(len(segment.Head) + len(segment.Tail))
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerThrottlerThrottlesPerPeer(t *testing.T) {
+	now := time.Now()
+	throttler := newPeerThrottler(1, func() time.Time { return now })
+
+	var slept []time.Duration
+	throttler.sleepFn = func(d time.Duration) {
+		slept = append(slept, d)
+	}
+
+	// 1Mbps is 128KiB per second.
+	throttler.throttle("a", peerThrottleBytesPerMegabit)
+	require.Equal(t, []time.Duration{time.Second}, slept)
+
+	now = now.Add(500 * time.Millisecond)
+	throttler.throttle("a", peerThrottleBytesPerMegabit)
+	require.Equal(t, []time.Duration{time.Second, 1500 * time.Millisecond}, slept)
+
+	// Other peers are accounted for independently.
+	throttler.throttle("b", peerThrottleBytesPerMegabit/2)
+	require.Equal(t, []time.Duration{
+		time.Second, 1500 * time.Millisecond, 500 * time.Millisecond,
+	}, slept)
+
+	// Accounting resets after the window has passed.
+	now = now.Add(2 * peerThrottleWindow)
+	throttler.throttle("a", peerThrottleBytesPerMegabit/4)
+	assert.Equal(t, 250*time.Millisecond, slept[len(slept)-1])
+}
+
+func TestPeerThrottlerUnlimited(t *testing.T) {
+	throttler := newPeerThrottler(0, time.Now)
+	throttler.sleepFn = func(time.Duration) {
+		require.FailNow(t, "unexpected sleep")
+	}
+	throttler.throttle("a", 1<<30)
+
+	var nilThrottler *peerThrottler
+	nilThrottler.throttle("a", 1<<30)
+}
+
+func TestFetchBlocksRawResultBytes(t *testing.T) {
+	result := &rpc.FetchBlocksRawResult_{
+		Elements: []*rpc.Blocks{
+			{
+				Blocks: []*rpc.Block{
+					{Segments: &rpc.Segments{
+						Merged: &rpc.Segment{Head: []byte{1, 2}, Tail: []byte{3}},
+					}},
+					{Segments: &rpc.Segments{
+						Unmerged: []*rpc.Segment{
+							{Head: []byte{1}, Tail: []byte{2}},
+							{Head: []byte{1, 2, 3}},
+						},
+					}},
+					{Err: &rpc.Error{}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, int64(8), fetchBlocksRawResultBytes(result))
+	assert.Equal(t, int64(0), fetchBlocksRawResultBytes(nil))
+}
@@ -94,6 +94,8 @@ var (
 	// errSessionStatusNotOpen is raised when operations are requested when the
 	// session is not in the open state
 	errSessionStatusNotOpen = errors.New("session not in open state")
+	// errFetchCancelled is raised when a fetch is cancelled by its caller
+	errFetchCancelled = xerrors.NewNonRetryableError(errors.New("fetch cancelled"))
 	// errSessionBadBlockResultFromPeer is raised when there is a bad block
 	// return from a peer when fetching blocks from peers
 	errSessionBadBlockResultFromPeer = errors.New("session fetched bad block result from peer")
@@ -149,6 +151,7 @@ type session struct {
 	streamBlocksBatchSize            int
 	streamBlocksMetadataBatchTimeout time.Duration
 	streamBlocksBatchTimeout         time.Duration
+	streamBlocksThrottler            *peerThrottler
 	metrics                          sessionMetrics
 }
 
@@ -317,6 +320,8 @@ func newSession(opts Options) (clientSession, error) {
 		s.streamBlocksMetadataBatchTimeout = opts.FetchSeriesBlocksMetadataBatchTimeout()
 		s.streamBlocksBatchTimeout = opts.FetchSeriesBlocksBatchTimeout()
 		s.streamBlocksRetrier = opts.StreamBlocksRetrier()
+		s.streamBlocksThrottler = newPeerThrottler(
+			opts.FetchSeriesBlocksPerPeerLimitMbps(), s.nowFn)
 	}
 
 	if runtimeOptsMgr := opts.RuntimeOptionsManager(); runtimeOptsMgr != nil {
@@ -1229,6 +1234,7 @@ func (s *session) fetchTaggedAttempt(
 		fetchTaggedRequest: req,
 		startInclusive:     opts.StartInclusive,
 		endExclusive:       opts.EndExclusive,
+		done:               opts.Done,
 	})
 	s.state.RUnlock()
 
@@ -1238,7 +1244,9 @@ func (s *session) fetchTaggedAttempt(
 
 	// it's safe to Wait() here, as we still hold the lock on fetchState, after it's
 	// returned from newFetchStateWithRLock.
+	stopCancelling := fetchState.cancelOnDone(opts.Done)
 	fetchState.Wait()
+	stopCancelling()
 
 	// must Unlock before calling `asEncodingSeriesIterators` as the latter needs to acquire
 	// the fetchState Lock
@@ -1282,6 +1290,7 @@ func (s *session) fetchTaggedIDsAttempt(
 		fetchTaggedRequest: req,
 		startInclusive:     opts.StartInclusive,
 		endExclusive:       opts.EndExclusive,
+		done:               opts.Done,
 	})
 	s.state.RUnlock()
 
@@ -1291,7 +1300,9 @@ func (s *session) fetchTaggedIDsAttempt(
 
 	// it's safe to Wait() here, as we still hold the lock on fetchState, after it's
 	// returned from newFetchStateWithRLock.
+	stopCancelling := fetchState.cancelOnDone(opts.Done)
 	fetchState.Wait()
+	stopCancelling()
 
 	// must Unlock before calling `asTaggedIDsIterator` as the latter needs to acquire
 	// the fetchState Lock
@@ -1312,6 +1323,7 @@ type newFetchStateOpts struct {
 
 	// only valid if stateType == fetchTaggedFetchState
 	fetchTaggedRequest rpc.FetchTaggedRequest
+	done               <-chan struct{}
 
 	// only valid if stateType == aggregateFetchState
 	aggregateRequest rpc.AggregateQueryRawRequest
@@ -1342,7 +1354,7 @@ func (s *session) newFetchStateWithRLock(
 		fetchOp := s.pools.fetchTaggedOp.Get()
 		fetchOp.incRef()        // indicate current go-routine has a reference to the op
 		closer = fetchOp.decRef // release the ref for the current go-routine
-		fetchOp.update(opts.fetchTaggedRequest, fetchState.completionFn)
+		fetchOp.update(opts.fetchTaggedRequest, opts.done, fetchState.completionFn)
 		fetchState.ResetFetchTagged(opts.startInclusive, opts.endExclusive,
 			fetchOp, topoMap, s.state.majority, s.state.readLevel)
 		op = fetchOp
@@ -2848,6 +2860,11 @@ func (s *session) streamBlocksBatchFromPeer(
 		return
 	}
 
+	// Throttle once the result has been handed off so that the bandwidth
+	// used when streaming from this peer stays under the configured limit
+	defer s.streamBlocksThrottler.throttle(peer.Host().ID(),
+		fetchBlocksRawResultBytes(result))
+
 	// Parse and act on result
 	tooManyIDsLogged := false
 	for i := range result.Elements {
@@ -544,6 +544,14 @@ type AdminOptions interface {
 	// FetchSeriesBlocksBatchConcurrency gets the concurrency for fetching series blocks in batch.
 	FetchSeriesBlocksBatchConcurrency() int
 
+	// SetFetchSeriesBlocksPerPeerLimitMbps sets the bandwidth limit in megabits
+	// per second when streaming blocks from a single peer, zero means unlimited.
+	SetFetchSeriesBlocksPerPeerLimitMbps(value float64) AdminOptions
+
+	// FetchSeriesBlocksPerPeerLimitMbps returns the bandwidth limit in megabits
+	// per second when streaming blocks from a single peer, zero means unlimited.
+	FetchSeriesBlocksPerPeerLimitMbps() float64
+
 	// SetStreamBlocksRetrier sets the retrier for streaming blocks.
 	SetStreamBlocksRetrier(value xretry.Retrier) AdminOptions
 
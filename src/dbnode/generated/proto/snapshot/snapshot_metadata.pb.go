@@ -85,6 +85,7 @@ func (m *Metadata) GetCommitlogID() *CommitLogID {
 type CommitLogID struct {
 	FilePath string `protobuf:"bytes,1,opt,name=filePath,proto3" json:"filePath,omitempty"`
 	Index    int64  `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	Offset   int64  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
 }
 
 func (m *CommitLogID) Reset()                    { *m = CommitLogID{} }
@@ -106,6 +107,13 @@ func (m *CommitLogID) GetIndex() int64 {
 	return 0
 }
 
+func (m *CommitLogID) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Metadata)(nil), "snapshot.Metadata")
 	proto.RegisterType((*CommitLogID)(nil), "snapshot.CommitLogID")
@@ -175,6 +183,11 @@ func (m *CommitLogID) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintSnapshotMetadata(dAtA, i, uint64(m.Index))
 	}
+	if m.Offset != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintSnapshotMetadata(dAtA, i, uint64(m.Offset))
+	}
 	return i, nil
 }
 
@@ -214,6 +227,9 @@ func (m *CommitLogID) Size() (n int) {
 	if m.Index != 0 {
 		n += 1 + sovSnapshotMetadata(uint64(m.Index))
 	}
+	if m.Offset != 0 {
+		n += 1 + sovSnapshotMetadata(uint64(m.Offset))
+	}
 	return n
 }
 
@@ -440,6 +456,25 @@ func (m *CommitLogID) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
+			}
+			m.Offset = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSnapshotMetadata
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Offset |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSnapshotMetadata(dAtA[iNdEx:])
@@ -571,21 +606,21 @@ func init() {
 }
 
 var fileDescriptorSnapshotMetadata = []byte{
-	// 241 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xf2, 0x4b, 0xcf, 0x2c, 0xc9,
-	0x28, 0x4d, 0xd2, 0x4b, 0xce, 0xcf, 0xd5, 0xcf, 0x35, 0x4e, 0x49, 0xd2, 0xcf, 0x35, 0xd6, 0x2f,
-	0x2e, 0x4a, 0xd6, 0x4f, 0x49, 0xca, 0xcb, 0x4f, 0x49, 0xd5, 0x4f, 0x4f, 0xcd, 0x4b, 0x2d, 0x4a,
-	0x2c, 0x49, 0x4d, 0xd1, 0x2f, 0x28, 0xca, 0x2f, 0xc9, 0xd7, 0x2f, 0xce, 0x4b, 0x2c, 0x28, 0xce,
-	0xc8, 0x2f, 0x81, 0x33, 0xe2, 0x73, 0x53, 0x4b, 0x12, 0x53, 0x12, 0x4b, 0x12, 0xf5, 0xc0, 0x0a,
-	0x84, 0x38, 0x60, 0x12, 0x4a, 0xbd, 0x8c, 0x5c, 0x1c, 0xbe, 0x50, 0x49, 0x21, 0x15, 0x2e, 0x5e,
-	0x98, 0x84, 0x67, 0x5e, 0x4a, 0x6a, 0x85, 0x04, 0xa3, 0x02, 0xa3, 0x06, 0x73, 0x10, 0xaa, 0xa0,
-	0x90, 0x12, 0x17, 0x0f, 0x4c, 0x20, 0x34, 0xd4, 0xd3, 0x45, 0x82, 0x49, 0x81, 0x51, 0x83, 0x27,
-	0x08, 0x45, 0x4c, 0xc8, 0x9c, 0x8b, 0x3b, 0x39, 0x3f, 0x37, 0x37, 0xb3, 0x24, 0x27, 0x3f, 0xdd,
-	0xd3, 0x45, 0x82, 0x59, 0x81, 0x51, 0x83, 0xdb, 0x48, 0x54, 0x0f, 0xa6, 0x46, 0xcf, 0x19, 0x2c,
-	0xe9, 0x03, 0x92, 0x0c, 0x42, 0x56, 0xa9, 0x64, 0xcf, 0xc5, 0x8d, 0x24, 0x27, 0x24, 0xc5, 0xc5,
-	0x91, 0x96, 0x99, 0x93, 0x1a, 0x90, 0x58, 0x92, 0x01, 0x76, 0x0c, 0x67, 0x10, 0x9c, 0x2f, 0x24,
-	0xc2, 0xc5, 0x9a, 0x09, 0x76, 0x25, 0x13, 0xd8, 0x95, 0x10, 0x8e, 0x93, 0xc0, 0x89, 0x47, 0x72,
-	0x8c, 0x17, 0x1e, 0xc9, 0x31, 0x3e, 0x78, 0x24, 0xc7, 0x38, 0xe1, 0xb1, 0x1c, 0x43, 0x12, 0x1b,
-	0xd8, 0xcf, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x6b, 0x66, 0xf1, 0x77, 0x45, 0x01, 0x00,
-	0x00,
+	// 244 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xf2, 0x4b, 0xcf, 0x2c, 0xc9,
+	0x28, 0x4d, 0xd2, 0x4b, 0xce, 0xcf, 0xd5, 0xcf, 0x35, 0x4e, 0x49, 0x02, 0x12, 0xfa, 0xc5, 0x45,
+	0xc9, 0xfa, 0x29, 0x49, 0x79, 0xf9, 0x29, 0xa9, 0xfa, 0xe9, 0xa9, 0x79, 0xa9, 0x45, 0x89, 0x25,
+	0xa9, 0x29, 0xfa, 0x05, 0x45, 0xf9, 0x25, 0xf9, 0xfa, 0xc5, 0x79, 0x89, 0x05, 0xc5, 0x19, 0xf9,
+	0x25, 0x70, 0x46, 0x7c, 0x6e, 0x6a, 0x49, 0x62, 0x4a, 0x62, 0x49, 0xa2, 0x1e, 0x58, 0x81, 0x10,
+	0x07, 0x4c, 0x42, 0xa9, 0x97, 0x91, 0x8b, 0xc3, 0x17, 0x2a, 0x29, 0xa4, 0xc2, 0xc5, 0x0b, 0x93,
+	0xf0, 0xcc, 0x4b, 0x49, 0xad, 0x90, 0x60, 0x54, 0x60, 0xd4, 0x60, 0x0e, 0x42, 0x15, 0x14, 0x52,
+	0xe2, 0xe2, 0x81, 0x09, 0x84, 0x86, 0x7a, 0xba, 0x48, 0x30, 0x01, 0x15, 0xf1, 0x04, 0xa1, 0x88,
+	0x09, 0x99, 0x73, 0x71, 0x03, 0xdd, 0x9a, 0x9b, 0x59, 0x92, 0x93, 0x9f, 0x0e, 0x54, 0xc2, 0x0c,
+	0x54, 0xc2, 0x6d, 0x24, 0xaa, 0x07, 0x53, 0xa3, 0xe7, 0x0c, 0x96, 0xf4, 0x01, 0x49, 0x06, 0x21,
+	0xab, 0x54, 0x0a, 0xe7, 0xe2, 0x46, 0x92, 0x13, 0x92, 0xe2, 0xe2, 0x48, 0xcb, 0xcc, 0x49, 0x0d,
+	0x48, 0x2c, 0xc9, 0x00, 0x3b, 0x86, 0x33, 0x08, 0xce, 0x17, 0x12, 0xe1, 0x62, 0xcd, 0x04, 0xbb,
+	0x92, 0x09, 0xec, 0x4a, 0x08, 0x47, 0x48, 0x8c, 0x8b, 0x2d, 0x3f, 0x2d, 0xad, 0x38, 0xb5, 0x04,
+	0x6c, 0x29, 0x73, 0x10, 0x94, 0xe7, 0x24, 0x70, 0xe2, 0x91, 0x1c, 0xe3, 0x05, 0x20, 0x7e, 0x00,
+	0xc4, 0x13, 0x1e, 0xcb, 0x31, 0x24, 0xb1, 0x81, 0xc3, 0xc2, 0x18, 0x00, 0x34, 0xc8, 0x4d, 0x55,
+	0x5d, 0x01, 0x00, 0x00,
 }
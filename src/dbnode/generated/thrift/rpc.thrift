@@ -144,6 +144,8 @@ struct FetchTaggedRequest {
 	5: required bool fetchData
 	6: optional i64 limit
 	7: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+	8: optional bool orderedByID = false
+	9: optional binary afterID
 }
 
 struct FetchTaggedResult {
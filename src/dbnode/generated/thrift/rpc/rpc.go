@@ -2557,6 +2557,8 @@ func (p *Segment) String() string {
 //  - FetchData
 //  - Limit
 //  - RangeTimeType
+//  - OrderedByID
+//  - AfterID
 type FetchTaggedRequest struct {
 	NameSpace     []byte   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
 	Query         []byte   `thrift:"query,2,required" db:"query" json:"query"`
@@ -2565,6 +2567,8 @@ type FetchTaggedRequest struct {
 	FetchData     bool     `thrift:"fetchData,5,required" db:"fetchData" json:"fetchData"`
 	Limit         *int64   `thrift:"limit,6" db:"limit" json:"limit,omitempty"`
 	RangeTimeType TimeType `thrift:"rangeTimeType,7" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+	OrderedByID   bool     `thrift:"orderedByID,8" db:"orderedByID" json:"orderedByID,omitempty"`
+	AfterID       []byte   `thrift:"afterID,9" db:"afterID" json:"afterID,omitempty"`
 }
 
 func NewFetchTaggedRequest() *FetchTaggedRequest {
@@ -2607,6 +2611,18 @@ var FetchTaggedRequest_RangeTimeType_DEFAULT TimeType = 0
 func (p *FetchTaggedRequest) GetRangeTimeType() TimeType {
 	return p.RangeTimeType
 }
+
+var FetchTaggedRequest_OrderedByID_DEFAULT bool = false
+
+func (p *FetchTaggedRequest) GetOrderedByID() bool {
+	return p.OrderedByID
+}
+
+var FetchTaggedRequest_AfterID_DEFAULT []byte
+
+func (p *FetchTaggedRequest) GetAfterID() []byte {
+	return p.AfterID
+}
 func (p *FetchTaggedRequest) IsSetLimit() bool {
 	return p.Limit != nil
 }
@@ -2615,6 +2631,14 @@ func (p *FetchTaggedRequest) IsSetRangeTimeType() bool {
 	return p.RangeTimeType != FetchTaggedRequest_RangeTimeType_DEFAULT
 }
 
+func (p *FetchTaggedRequest) IsSetOrderedByID() bool {
+	return p.OrderedByID != FetchTaggedRequest_OrderedByID_DEFAULT
+}
+
+func (p *FetchTaggedRequest) IsSetAfterID() bool {
+	return p.AfterID != nil
+}
+
 func (p *FetchTaggedRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -2668,6 +2692,14 @@ func (p *FetchTaggedRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField7(iprot); err != nil {
 				return err
 			}
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
+		case 9:
+			if err := p.ReadField9(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -2762,6 +2794,24 @@ func (p *FetchTaggedRequest) ReadField7(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchTaggedRequest) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 8: ", err)
+	} else {
+		p.OrderedByID = v
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField9(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 9: ", err)
+	} else {
+		p.AfterID = v
+	}
+	return nil
+}
+
 func (p *FetchTaggedRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchTaggedRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -2788,6 +2838,12 @@ func (p *FetchTaggedRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField7(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField8(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField9(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -2893,6 +2949,36 @@ func (p *FetchTaggedRequest) writeField7(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchTaggedRequest) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetOrderedByID() {
+		if err := oprot.WriteFieldBegin("orderedByID", thrift.BOOL, 8); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:orderedByID: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(p.OrderedByID)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.orderedByID (8) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:orderedByID: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField9(oprot thrift.TProtocol) (err error) {
+	if p.IsSetAfterID() {
+		if err := oprot.WriteFieldBegin("afterID", thrift.STRING, 9); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:afterID: ", p), err)
+		}
+		if err := oprot.WriteBinary(p.AfterID); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.afterID (9) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 9:afterID: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchTaggedRequest) String() string {
 	if p == nil {
 		return "<nil>"
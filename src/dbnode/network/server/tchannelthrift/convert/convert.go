@@ -219,6 +219,8 @@ func FromRPCFetchTaggedRequest(
 	opts := index.QueryOptions{
 		StartInclusive: start,
 		EndExclusive:   end,
+		OrderedByID:    req.OrderedByID,
+		AfterID:        req.AfterID,
 	}
 	if l := req.Limit; l != nil {
 		opts.Limit = int(*l)
@@ -262,11 +264,13 @@ func ToRPCFetchTaggedRequest(
 	}
 
 	request := rpc.FetchTaggedRequest{
-		NameSpace:  ns.Bytes(),
-		RangeStart: rangeStart,
-		RangeEnd:   rangeEnd,
-		FetchData:  fetchData,
-		Query:      query,
+		NameSpace:   ns.Bytes(),
+		RangeStart:  rangeStart,
+		RangeEnd:    rangeEnd,
+		FetchData:   fetchData,
+		Query:       query,
+		OrderedByID: opts.OrderedByID,
+		AfterID:     opts.AfterID,
 	}
 
 	if opts.Limit > 0 {
@@ -106,15 +106,19 @@ func TestConvertFetchTaggedRequest(t *testing.T) {
 		StartInclusive: time.Now().Add(-900 * time.Hour),
 		EndExclusive:   time.Now(),
 		Limit:          10,
+		OrderedByID:    true,
+		AfterID:        []byte("foo"),
 	}
 	fetchData := true
 	var limit int64 = 10
 	requestSkeleton := &rpc.FetchTaggedRequest{
-		NameSpace:  ns.Bytes(),
-		RangeStart: mustToRpcTime(t, opts.StartInclusive),
-		RangeEnd:   mustToRpcTime(t, opts.EndExclusive),
-		FetchData:  fetchData,
-		Limit:      &limit,
+		NameSpace:   ns.Bytes(),
+		RangeStart:  mustToRpcTime(t, opts.StartInclusive),
+		RangeEnd:    mustToRpcTime(t, opts.EndExclusive),
+		FetchData:   fetchData,
+		Limit:       &limit,
+		OrderedByID: true,
+		AfterID:     []byte("foo"),
 	}
 	requireEqual := func(a, b interface{}) {
 		d := cmp.Diff(a, b)
@@ -23,6 +23,7 @@ package node
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -34,7 +35,9 @@ import (
 	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/dbnode/x/xpool"
@@ -95,6 +98,8 @@ type serviceMetrics struct {
 	fetchBlocksMetadata instrument.MethodMetrics
 	repair              instrument.MethodMetrics
 	truncate            instrument.MethodMetrics
+	flushNamespace      instrument.MethodMetrics
+	snapshot            instrument.MethodMetrics
 	fetchBatchRaw       instrument.BatchMethodMetrics
 	writeBatchRaw       instrument.BatchMethodMetrics
 	writeTaggedBatchRaw instrument.BatchMethodMetrics
@@ -112,6 +117,8 @@ func newServiceMetrics(scope tally.Scope, samplingRate float64) serviceMetrics {
 		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
 		repair:              instrument.NewMethodMetrics(scope, "repair", samplingRate),
 		truncate:            instrument.NewMethodMetrics(scope, "truncate", samplingRate),
+		flushNamespace:      instrument.NewMethodMetrics(scope, "flushNamespace", samplingRate),
+		snapshot:            instrument.NewMethodMetrics(scope, "snapshot", samplingRate),
 		fetchBatchRaw:       instrument.NewBatchMethodMetrics(scope, "fetchBatchRaw", samplingRate),
 		writeBatchRaw:       instrument.NewBatchMethodMetrics(scope, "writeBatchRaw", samplingRate),
 		writeTaggedBatchRaw: instrument.NewBatchMethodMetrics(scope, "writeTaggedBatchRaw", samplingRate),
@@ -278,9 +285,11 @@ func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryR
 	}
 
 	nsID := s.pools.id.GetStringID(ctx, req.NameSpace)
+	cost := s.newQueryCost(ctx)
 	opts := index.QueryOptions{
 		StartInclusive: start,
 		EndExclusive:   end,
+		Cost:           cost,
 	}
 	if l := req.Limit; l != nil {
 		opts.Limit = int(*l)
@@ -314,7 +323,7 @@ func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryR
 			continue
 		}
 		tsID := entry.Key()
-		datapoints, err := s.readDatapoints(ctx, nsID, tsID, start, end,
+		datapoints, err := s.readDatapoints(ctx, cost, nsID, tsID, start, end,
 			req.ResultTimeType)
 		if err != nil {
 			return nil, convert.ToRPCError(err)
@@ -346,8 +355,8 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 	nsID := s.pools.id.GetStringID(ctx, req.NameSpace)
 
 	// Make datapoints an initialized empty array for JSON serialization as empty array than null
-	datapoints, err := s.readDatapoints(ctx, nsID, tsID, start, end,
-		req.ResultTimeType)
+	datapoints, err := s.readDatapoints(ctx, s.newQueryCost(ctx), nsID, tsID,
+		start, end, req.ResultTimeType)
 	if err != nil {
 		s.metrics.fetch.ReportError(s.nowFn().Sub(callStart))
 		return nil, convert.ToRPCError(err)
@@ -359,6 +368,7 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 
 func (s *service) readDatapoints(
 	ctx context.Context,
+	cost limits.QueryCost,
 	nsID, tsID ident.ID,
 	start, end time.Time,
 	timeType rpc.TimeType,
@@ -367,6 +377,9 @@ func (s *service) readDatapoints(
 	if err != nil {
 		return nil, err
 	}
+	if err := cost.AddBlockReaders(encoded); err != nil {
+		return nil, err
+	}
 
 	// Make datapoints an initialized empty array for JSON serialization as empty array than null
 	datapoints := make([]*rpc.Datapoint, 0)
@@ -412,6 +425,8 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 		return nil, tterrors.NewBadRequestError(err)
 	}
 
+	cost := s.newQueryCost(ctx)
+	opts.Cost = cost
 	queryResult, err := s.db.QueryIDs(ctx, ns, query, opts)
 	if err != nil {
 		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
@@ -445,7 +460,17 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 		if !fetchData {
 			continue
 		}
-		segments, rpcErr := s.readEncoded(ctx, nsID, tsID, opts.StartInclusive, opts.EndExclusive)
+
+		// Stop reading data once the caller has cancelled the request.
+		select {
+		case <-tctx.Done():
+			s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+			return nil, tterrors.NewInternalError(tctx.Err())
+		default:
+		}
+
+		segments, rpcErr := s.readEncoded(ctx, cost, nsID, tsID,
+			opts.StartInclusive, opts.EndExclusive)
 		if rpcErr != nil {
 			elem.Err = rpcErr
 			continue
@@ -586,6 +611,7 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 	}
 
 	nsID := s.newID(ctx, req.NameSpace)
+	cost := s.newQueryCost(ctx)
 
 	result := rpc.NewFetchBatchRawResult_()
 
@@ -600,7 +626,7 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 		result.Elements = append(result.Elements, rawResult)
 
 		tsID := s.newID(ctx, req.Ids[i])
-		segments, rpcErr := s.readEncoded(ctx, nsID, tsID, start, end)
+		segments, rpcErr := s.readEncoded(ctx, cost, nsID, tsID, start, end)
 		if rpcErr != nil {
 			rawResult.Err = rpcErr
 			if tterrors.IsBadRequestError(rawResult.Err) {
@@ -1126,6 +1152,215 @@ func (s *service) Truncate(tctx thrift.Context, req *rpc.TruncateRequest) (r *rp
 	return res, nil
 }
 
+// FileOpsStateResult is the flush and snapshot state of every shard owned
+// by the node keyed by namespace and then shard ID.
+type FileOpsStateResult struct {
+	Namespaces map[string]map[uint32]ShardFileOpsState `json:"namespaces"`
+}
+
+// ShardFileOpsState is the flush and snapshot state of a single shard.
+type ShardFileOpsState struct {
+	IsSnapshotting         bool              `json:"isSnapshotting"`
+	LastSuccessfulSnapshot *time.Time        `json:"lastSuccessfulSnapshot,omitempty"`
+	UnflushedBlocks        []BlockFlushState `json:"unflushedBlocks"`
+}
+
+// BlockFlushState is the flush state of a single block of a shard.
+type BlockFlushState struct {
+	BlockStart  time.Time `json:"blockStart"`
+	Status      string    `json:"status"`
+	NumFailures int       `json:"numFailures"`
+}
+
+// FlushNamespaceRequest is a request to flush a namespace.
+type FlushNamespaceRequest struct {
+	NameSpace string `json:"nameSpace"`
+}
+
+// SnapshotRequest is a request to snapshot all namespaces, it has no fields
+// but makes the snapshot endpoint accept only POST requests as it mutates
+// the state of the node.
+type SnapshotRequest struct{}
+
+// FileOpsState returns the flush and snapshot state of the node, it is not
+// part of the thrift service and is only served over HTTP.
+func (s *service) FileOpsState(tctx thrift.Context) (*FileOpsStateResult, error) {
+	state, err := s.db.FileOpsState()
+	if err != nil {
+		return nil, convert.ToRPCError(err)
+	}
+
+	res := &FileOpsStateResult{
+		Namespaces: make(map[string]map[uint32]ShardFileOpsState,
+			len(state.NamespaceFileOpsStates)),
+	}
+	for ns, shardStates := range state.NamespaceFileOpsStates {
+		shards := make(map[uint32]ShardFileOpsState, len(shardStates))
+		for shardID, shardState := range shardStates {
+			result := ShardFileOpsState{
+				IsSnapshotting:  shardState.IsSnapshotting,
+				UnflushedBlocks: make([]BlockFlushState, 0, len(shardState.UnflushedBlocks)),
+			}
+			if !shardState.LastSuccessfulSnapshot.IsZero() {
+				lastSuccessfulSnapshot := shardState.LastSuccessfulSnapshot
+				result.LastSuccessfulSnapshot = &lastSuccessfulSnapshot
+			}
+			for _, block := range shardState.UnflushedBlocks {
+				result.UnflushedBlocks = append(result.UnflushedBlocks, BlockFlushState{
+					BlockStart:  block.BlockStart,
+					Status:      block.Status,
+					NumFailures: block.NumFailures,
+				})
+			}
+			shards[shardID] = result
+		}
+		res.Namespaces[ns] = shards
+	}
+	return res, nil
+}
+
+// FlushNamespace flushes all flushable blocks of a namespace immediately, it
+// is not part of the thrift service and is only served over HTTP.
+func (s *service) FlushNamespace(tctx thrift.Context, req *FlushNamespaceRequest) error {
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+	if err := s.db.FlushNamespace(s.newID(ctx, []byte(req.NameSpace))); err != nil {
+		s.metrics.flushNamespace.ReportError(s.nowFn().Sub(callStart))
+		return convert.ToRPCError(err)
+	}
+
+	s.metrics.flushNamespace.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return nil
+}
+
+// Snapshot snapshots all namespaces immediately, it is not part of the
+// thrift service and is only served over HTTP.
+func (s *service) Snapshot(tctx thrift.Context, req *SnapshotRequest) error {
+	callStart := s.nowFn()
+
+	if err := s.db.Snapshot(); err != nil {
+		s.metrics.snapshot.ReportError(s.nowFn().Sub(callStart))
+		return convert.ToRPCError(err)
+	}
+
+	s.metrics.snapshot.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return nil
+}
+
+// BootstrapProgressResult is the progress of the current, or otherwise the
+// most recent, bootstrap of the node.
+type BootstrapProgressResult struct {
+	Bootstrapped  bool                                  `json:"bootstrapped"`
+	Bootstrapping bool                                  `json:"bootstrapping"`
+	StartTime     *time.Time                            `json:"startTime,omitempty"`
+	EndTime       *time.Time                            `json:"endTime,omitempty"`
+	Error         string                                `json:"error,omitempty"`
+	Namespaces    map[string]NamespaceBootstrapProgress `json:"namespaces"`
+}
+
+// NamespaceBootstrapProgress is the bootstrap progress of a namespace,
+// bootstrapped shards are able to serve reads.
+type NamespaceBootstrapProgress struct {
+	NumShards          int                  `json:"numShards"`
+	BootstrappedShards []uint32             `json:"bootstrappedShards"`
+	Data               BootstrapRunProgress `json:"data"`
+	Index              BootstrapRunProgress `json:"index"`
+	PeerBytesStreamed  int64                `json:"peerBytesStreamed"`
+}
+
+// BootstrapRunProgress is the progress of data or index bootstrap runs, the
+// durations are shard time summed across all shards.
+type BootstrapRunProgress struct {
+	InProgress  bool                      `json:"inProgress"`
+	Requested   string                    `json:"requested"`
+	Fulfilled   string                    `json:"fulfilled"`
+	Remaining   string                    `json:"remaining"`
+	Unfulfilled string                    `json:"unfulfilled"`
+	Sources     []BootstrapSourceProgress `json:"sources"`
+}
+
+// BootstrapSourceProgress is the progress of a single bootstrapper source.
+type BootstrapSourceProgress struct {
+	Source     string `json:"source"`
+	InProgress bool   `json:"inProgress"`
+	Attempted  string `json:"attempted"`
+	Fulfilled  string `json:"fulfilled"`
+	Error      string `json:"error,omitempty"`
+}
+
+// BootstrapProgress returns the bootstrap progress of the node, it is not
+// part of the thrift service and is only served over HTTP.
+func (s *service) BootstrapProgress(tctx thrift.Context) (*BootstrapProgressResult, error) {
+	var (
+		progress = s.db.BootstrapProgress()
+		state    = s.db.BootstrapState()
+		res      = &BootstrapProgressResult{
+			Bootstrapped:  s.db.IsBootstrapped(),
+			Bootstrapping: progress.Bootstrapping,
+			Error:         progress.Error,
+			Namespaces:    make(map[string]NamespaceBootstrapProgress),
+		}
+	)
+	if !progress.StartTime.IsZero() {
+		res.StartTime = &progress.StartTime
+	}
+	if !progress.EndTime.IsZero() {
+		res.EndTime = &progress.EndTime
+	}
+
+	for ns, nsProgress := range progress.Namespaces {
+		res.Namespaces[ns] = NamespaceBootstrapProgress{
+			BootstrappedShards: []uint32{},
+			Data:               newBootstrapRunProgress(nsProgress.Data),
+			Index:              newBootstrapRunProgress(nsProgress.Index),
+			PeerBytesStreamed:  nsProgress.PeerBytesStreamed,
+		}
+	}
+	for ns, shardStates := range state.NamespaceBootstrapStates {
+		nsProgress, ok := res.Namespaces[ns]
+		if !ok {
+			nsProgress = NamespaceBootstrapProgress{
+				BootstrappedShards: []uint32{},
+			}
+		}
+		nsProgress.NumShards = len(shardStates)
+		for shardID, shardState := range shardStates {
+			if shardState == storage.Bootstrapped {
+				nsProgress.BootstrappedShards = append(nsProgress.BootstrappedShards, shardID)
+			}
+		}
+		sort.Slice(nsProgress.BootstrappedShards, func(i, j int) bool {
+			return nsProgress.BootstrappedShards[i] < nsProgress.BootstrappedShards[j]
+		})
+		res.Namespaces[ns] = nsProgress
+	}
+
+	return res, nil
+}
+
+func newBootstrapRunProgress(progress bootstrap.RunProgress) BootstrapRunProgress {
+	result := BootstrapRunProgress{
+		InProgress:  progress.InProgress,
+		Requested:   progress.Requested.String(),
+		Fulfilled:   progress.Fulfilled.String(),
+		Remaining:   progress.Remaining.String(),
+		Unfulfilled: progress.Unfulfilled.String(),
+		Sources:     make([]BootstrapSourceProgress, 0, len(progress.Sources)),
+	}
+	for _, src := range progress.Sources {
+		result.Sources = append(result.Sources, BootstrapSourceProgress{
+			Source:     src.Source,
+			InProgress: src.InProgress,
+			Attempted:  src.Attempted.String(),
+			Fulfilled:  src.Fulfilled.String(),
+			Error:      src.Error,
+		})
+	}
+	return result
+}
+
 func (s *service) GetPersistRateLimit(
 	ctx thrift.Context,
 ) (*rpc.NodePersistRateLimitResult_, error) {
@@ -1274,6 +1509,7 @@ func (s *service) newPooledID(
 
 func (s *service) readEncoded(
 	ctx context.Context,
+	cost limits.QueryCost,
 	nsID, tsID ident.ID,
 	start, end time.Time,
 ) ([]*rpc.Segments, *rpc.Error) {
@@ -1281,6 +1517,9 @@ func (s *service) readEncoded(
 	if err != nil {
 		return nil, convert.ToRPCError(err)
 	}
+	if err := cost.AddBlockReaders(encoded); err != nil {
+		return nil, convert.ToRPCError(err)
+	}
 
 	segments := s.pools.segmentsArray.Get()
 	segments = segmentsArr(segments).grow(len(encoded))
@@ -1303,6 +1542,17 @@ func (s *service) readEncoded(
 	return segments, nil
 }
 
+// newQueryCost returns the accounting for the resources used by a query,
+// the resources are released once the request context is closed after the
+// response has been written. Blocks are accounted for once they have been
+// read from the database, so the limits bound the data held by the response
+// rather than the data read by a single series.
+func (s *service) newQueryCost(ctx context.Context) limits.QueryCost {
+	cost := s.db.Options().QueryLimits().NewQueryCost()
+	ctx.RegisterFinalizer(resource.FinalizerFn(cost.Close))
+	return cost
+}
+
 func (s *service) newTagsDecoder(ctx context.Context, encodedTags []byte) (serialize.TagDecoder, error) {
 	checkedBytes := s.pools.checkedBytesWrapper.Get(encodedTags)
 	dec := s.pools.tagDecoder.Get()
@@ -35,7 +35,9 @@ import (
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
@@ -121,6 +123,83 @@ func TestServiceBootstrapped(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceBootstrapProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	start := time.Now()
+	mockDB.EXPECT().IsBootstrapped().Return(false)
+	mockDB.EXPECT().BootstrapProgress().Return(bootstrap.ProgressSnapshot{
+		Bootstrapping: true,
+		StartTime:     start,
+		Namespaces: map[string]bootstrap.NamespaceProgress{
+			"testns": {
+				Data: bootstrap.RunProgress{
+					InProgress: true,
+					Requested:  4 * time.Hour,
+					Fulfilled:  time.Hour,
+					Remaining:  3 * time.Hour,
+					Sources: []bootstrap.SourceProgress{
+						{
+							Source:     "peers",
+							InProgress: true,
+							Attempted:  4 * time.Hour,
+							Fulfilled:  time.Hour,
+						},
+					},
+				},
+				PeerBytesStreamed: 2048,
+			},
+		},
+	})
+	mockDB.EXPECT().BootstrapState().Return(storage.DatabaseBootstrapState{
+		NamespaceBootstrapStates: storage.NamespaceBootstrapStates{
+			"testns": storage.ShardBootstrapStates{
+				0: storage.Bootstrapping,
+				1: storage.Bootstrapped,
+				2: storage.Bootstrapped,
+			},
+		},
+	})
+
+	tctx, _ := thrift.NewContext(time.Minute)
+	result, err := service.BootstrapProgress(tctx)
+	require.NoError(t, err)
+
+	assert.False(t, result.Bootstrapped)
+	assert.True(t, result.Bootstrapping)
+	require.NotNil(t, result.StartTime)
+	assert.True(t, start.Equal(*result.StartTime))
+	assert.Nil(t, result.EndTime)
+
+	require.Equal(t, 1, len(result.Namespaces))
+	ns := result.Namespaces["testns"]
+	assert.Equal(t, 3, ns.NumShards)
+	assert.Equal(t, []uint32{1, 2}, ns.BootstrappedShards)
+	assert.Equal(t, int64(2048), ns.PeerBytesStreamed)
+	assert.Equal(t, BootstrapRunProgress{
+		InProgress:  true,
+		Requested:   "4h0m0s",
+		Fulfilled:   "1h0m0s",
+		Remaining:   "3h0m0s",
+		Unfulfilled: "0s",
+		Sources: []BootstrapSourceProgress{
+			{
+				Source:     "peers",
+				InProgress: true,
+				Attempted:  "4h0m0s",
+				Fulfilled:  "1h0m0s",
+			},
+		},
+	}, ns.Data)
+	assert.False(t, ns.Index.InProgress)
+}
+
 func TestServiceQuery(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -210,6 +289,7 @@ func TestServiceQuery(t *testing.T) {
 			StartInclusive: start,
 			EndExclusive:   end,
 			Limit:          10,
+			Cost:           limits.NewNoopQueryLimits().NewQueryCost(),
 		}).Return(index.QueryResult{Results: resMap, Exhaustive: true}, nil)
 
 	limit := int64(10)
@@ -329,6 +409,7 @@ func TestServiceQueryUnknownErr(t *testing.T) {
 			StartInclusive: start,
 			EndExclusive:   end,
 			Limit:          10,
+			Cost:           limits.NewNoopQueryLimits().NewQueryCost(),
 		}).Return(index.QueryResult{}, unknownErr)
 
 	limit := int64(10)
@@ -1083,6 +1164,7 @@ func TestServiceFetchTagged(t *testing.T) {
 			StartInclusive: start,
 			EndExclusive:   end,
 			Limit:          10,
+			Cost:           limits.NewNoopQueryLimits().NewQueryCost(),
 		}).Return(index.QueryResult{Results: resMap, Exhaustive: true}, nil)
 
 	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
@@ -1226,6 +1308,7 @@ func TestServiceFetchTaggedNoData(t *testing.T) {
 			StartInclusive: start,
 			EndExclusive:   end,
 			Limit:          10,
+			Cost:           limits.NewNoopQueryLimits().NewQueryCost(),
 		}).Return(index.QueryResult{Results: resMap, Exhaustive: true}, nil)
 
 	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
@@ -1299,6 +1382,7 @@ func TestServiceFetchTaggedErrs(t *testing.T) {
 			StartInclusive: start,
 			EndExclusive:   end,
 			Limit:          10,
+			Cost:           limits.NewNoopQueryLimits().NewQueryCost(),
 		}).Return(index.QueryResult{}, fmt.Errorf("random err"))
 	_, err = service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
 		NameSpace:  []byte(nsID),
@@ -43,6 +43,8 @@ var (
 	ErrCommitLogQueueFull = errors.New("commit log queue is full")
 
 	errCommitLogClosed = errors.New("commit log is closed")
+
+	errCommitLogNoActiveFile = errors.New("commit log has no active file")
 )
 
 type newCommitLogWriterFn func(
@@ -126,6 +128,12 @@ func (f *flushState) getLastFlushAt() time.Time {
 type writerState struct {
 	writer     commitLogWriter
 	activeFile *persist.CommitLogFile
+	// activeFileEntries is the number of entries written to the active file.
+	activeFileEntries int64
+	// activeFileEntriesInvalid is set once a write or flush to the active file
+	// failed, as entries may then be partially written or lost and the number
+	// of entries readers of the file will see is unknown.
+	activeFileEntriesInvalid bool
 }
 
 type closedState struct {
@@ -153,6 +161,7 @@ const (
 	flushEventType
 	activeLogsEventType
 	rotateLogsEventType
+	activePositionEventType
 )
 
 type callbackFn func(callbackResult)
@@ -162,6 +171,8 @@ type callbackResult struct {
 	err        error
 	activeLogs activeLogsCallbackResult
 	rotateLogs rotateLogsResult
+
+	activePosition activePositionResult
 }
 
 type activeLogsCallbackResult struct {
@@ -172,6 +183,10 @@ type rotateLogsResult struct {
 	file persist.CommitLogFile
 }
 
+type activePositionResult struct {
+	file persist.CommitLogFile
+}
+
 func (r callbackResult) activeLogsCallbackResult() (activeLogsCallbackResult, error) {
 	if r.eventType != activeLogsEventType {
 		return activeLogsCallbackResult{}, fmt.Errorf(
@@ -200,6 +215,20 @@ func (r callbackResult) rotateLogsResult() (rotateLogsResult, error) {
 	return r.rotateLogs, nil
 }
 
+func (r callbackResult) activePositionResult() (activePositionResult, error) {
+	if r.eventType != activePositionEventType {
+		return activePositionResult{}, fmt.Errorf(
+			"wrong event type: expected %d but got %d",
+			activePositionEventType, r.eventType)
+	}
+
+	if r.err != nil {
+		return activePositionResult{}, r.err
+	}
+
+	return r.activePosition, nil
+}
+
 type commitLogWrite struct {
 	eventType  eventType
 	write      writeOrWriteBatch
@@ -355,6 +384,40 @@ func (l *commitLog) RotateLogs() (persist.CommitLogFile, error) {
 	return file, nil
 }
 
+func (l *commitLog) ActivePosition() (persist.CommitLogFile, error) {
+	l.closedState.RLock()
+	defer l.closedState.RUnlock()
+
+	if l.closedState.closed {
+		return persist.CommitLogFile{}, errCommitLogClosed
+	}
+
+	var (
+		err  error
+		file persist.CommitLogFile
+		wg   sync.WaitGroup
+	)
+	wg.Add(1)
+
+	l.writes <- commitLogWrite{
+		eventType: activePositionEventType,
+		callbackFn: func(r callbackResult) {
+			defer wg.Done()
+
+			result, e := r.activePositionResult()
+			file, err = result.file, e
+		},
+	}
+
+	wg.Wait()
+
+	if err != nil {
+		return persist.CommitLogFile{}, err
+	}
+
+	return file, nil
+}
+
 func (l *commitLog) QueueLength() int64 {
 	return atomic.LoadInt64(&l.numWritesInQueue)
 }
@@ -424,6 +487,29 @@ func (l *commitLog) write() {
 			continue
 		}
 
+		if write.eventType == activePositionEventType {
+			var (
+				result activePositionResult
+				err    error
+			)
+			if l.writerState.activeFile != nil {
+				result.file = *l.writerState.activeFile
+				// No entries can be skipped when the number of entries of
+				// the file is unknown.
+				if !l.writerState.activeFileEntriesInvalid {
+					result.file.Offset = l.writerState.activeFileEntries
+				}
+			} else {
+				err = errCommitLogNoActiveFile
+			}
+			write.callbackFn(callbackResult{
+				eventType:      write.eventType,
+				err:            err,
+				activePosition: result,
+			})
+			continue
+		}
+
 		// For writes requiring acks add to pending acks
 		if write.eventType == writeEventType && write.callbackFn != nil {
 			l.pendingFlushFns = append(l.pendingFlushFns, write.callbackFn)
@@ -491,9 +577,11 @@ func (l *commitLog) write() {
 			err := l.writerState.writer.Write(write.Series,
 				write.Datapoint, write.Unit, write.Annotation)
 			if err != nil {
+				l.writerState.activeFileEntriesInvalid = true
 				l.handleWriteErr(err)
 				continue
 			}
+			l.writerState.activeFileEntries++
 			numWritesSuccess++
 		}
 
@@ -520,6 +608,9 @@ func (l *commitLog) onFlush(err error) {
 		l.metrics.flushErrors.Inc(1)
 		l.log.Errorf("failed to flush commit log: %v", err)
 
+		// The entries of the failed flush may be missing from the file.
+		l.writerState.activeFileEntriesInvalid = true
+
 		if l.commitLogFailFn != nil {
 			l.commitLogFailFn(err)
 		}
@@ -567,6 +658,8 @@ func (l *commitLog) openWriter() (persist.CommitLogFile, error) {
 	}
 
 	l.writerState.activeFile = &file
+	l.writerState.activeFileEntries = 0
+	l.writerState.activeFileEntriesInvalid = false
 
 	return file, nil
 }
@@ -26,6 +26,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -476,10 +477,10 @@ func TestCommitLogReaderIsNotReusable(t *testing.T) {
 
 	// Assert commitlog cannot be opened more than once
 	reader := newCommitLogReader(opts, ReadAllSeriesPredicate())
-	_, err = reader.Open(files[0])
+	_, err = reader.Open(files[0], 0)
 	require.NoError(t, err)
 	reader.Close()
-	_, err = reader.Open(files[0])
+	_, err = reader.Open(files[0], 0)
 	require.Equal(t, errCommitLogReaderIsNotReusable, err)
 }
 
@@ -714,6 +715,57 @@ func TestCommitLogFailOnWriteError(t *testing.T) {
 	require.Equal(t, int64(1), errors.Value())
 }
 
+func TestCommitLogActivePositionAfterWriteError(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{
+		strategy: StrategyWriteBehind,
+	})
+	defer cleanup(t, opts)
+
+	commitLogI, err := NewCommitLog(opts)
+	require.NoError(t, err)
+	commitLog := commitLogI.(*commitLog)
+	writer := newMockCommitLogWriter()
+
+	var numWrites int64
+	writer.writeFn = func(ts.Series, ts.Datapoint, xtime.Unit, ts.Annotation) error {
+		if atomic.AddInt64(&numWrites, 1) == 2 {
+			return fmt.Errorf("an error")
+		}
+		return nil
+	}
+
+	commitLog.newCommitLogWriterFn = func(
+		_ flushFn,
+		_ Options,
+	) commitLogWriter {
+		return writer
+	}
+
+	require.NoError(t, commitLog.Open())
+	commitLog.commitLogFailFn = func(error) {}
+
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", testTags1, 127), time.Now(), 123.456, xtime.Millisecond, nil, nil},
+	}
+
+	writeCommitLogs(t, scope, commitLog, writes)
+
+	position, err := commitLog.ActivePosition()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), position.Offset)
+
+	// The entries following a failed write can not be counted reliably so no
+	// entries of the file can be skipped.
+	writeCommitLogs(t, scope, commitLog, writes)
+	writeCommitLogs(t, scope, commitLog, writes)
+
+	position, err = commitLog.ActivePosition()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), position.Offset)
+
+	require.NoError(t, commitLog.Close())
+}
+
 func TestCommitLogFailOnOpenError(t *testing.T) {
 	opts, scope := newTestOptions(t, overrides{
 		strategy: StrategyWriteBehind,
@@ -900,6 +952,74 @@ func TestCommitLogRotateLogs(t *testing.T) {
 	assertCommitLogWritesByIterating(t, commitLog, writes)
 }
 
+func TestCommitLogActivePositionSkipsPrecedingEntries(t *testing.T) {
+	var (
+		clock       = mclock.NewMock()
+		opts, scope = newTestOptions(t, overrides{
+			clock:    clock,
+			strategy: StrategyWriteWait,
+		})
+	)
+	defer cleanup(t, opts)
+
+	var (
+		commitLog = newTestCommitLog(t, opts)
+		start     = clock.Now()
+	)
+
+	before := []testWrite{
+		{testSeries(0, "foo.bar", testTags1, 127), start, 123.456, xtime.Millisecond, nil, nil},
+		{testSeries(1, "foo.baz", testTags2, 150), start.Add(1 * time.Second), 456.789, xtime.Millisecond, nil, nil},
+	}
+	// Includes a series written before the position to ensure the metadata of
+	// skipped entries is still available for entries after the position.
+	after := []testWrite{
+		{testSeries(0, "foo.bar", testTags1, 127), start.Add(2 * time.Second), 789.123, xtime.Millisecond, nil, nil},
+		{testSeries(2, "foo.qux", testTags3, 291), start.Add(3 * time.Second), 321.654, xtime.Millisecond, nil, nil},
+	}
+
+	wg := writeCommitLogs(t, scope, commitLog, before)
+	flushUntilDone(commitLog, wg)
+
+	position, err := commitLog.ActivePosition()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(before)), position.Offset)
+
+	wg = writeCommitLogs(t, scope, commitLog, after)
+	flushUntilDone(commitLog, wg)
+
+	require.NoError(t, commitLog.Close())
+
+	iter, corruptFiles, err := NewIterator(IteratorOpts{
+		CommitLogOptions:      opts,
+		FileFilterPredicate:   ReadAllPredicate(),
+		SeriesFilterPredicate: ReadAllSeriesPredicate(),
+		FileEntryOffsetFn: func(f persist.CommitLogFile) int64 {
+			if f.Index == position.Index {
+				return position.Offset
+			}
+			return 0
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(corruptFiles))
+	defer iter.Close()
+
+	var read []string
+	for iter.Next() {
+		series, datapoint, unit, annotation := iter.Current()
+		for _, write := range after {
+			if write.series.ID.Equal(series.ID) {
+				write.assert(t, series, datapoint, unit, annotation)
+			}
+		}
+		read = append(read, series.ID.String())
+	}
+	require.NoError(t, iter.Err())
+	sort.Strings(read)
+	require.Equal(t, []string{"foo.bar", "foo.qux"}, read)
+}
+
 var (
 	testTag1 = ident.StringTag("name1", "val1")
 	testTag2 = ident.StringTag("name2", "val2")
@@ -46,6 +46,7 @@ type iterator struct {
 	metrics    iteratorMetrics
 	log        xlog.Logger
 	files      []persist.CommitLogFile
+	offsetFn   FileEntryOffsetFn
 	reader     commitLogReader
 	read       iteratorRead
 	err        error
@@ -88,6 +89,7 @@ func NewIterator(iterOpts IteratorOpts) (iter Iterator, corruptFiles []ErrorWith
 		},
 		log:        iops.Logger(),
 		files:      filteredFiles,
+		offsetFn:   iterOpts.FileEntryOffsetFn,
 		seriesPred: iterOpts.SeriesFilterPredicate,
 	}, corruptFiles, nil
 }
@@ -165,8 +167,13 @@ func (i *iterator) nextReader() bool {
 	file := i.files[0]
 	i.files = i.files[1:]
 
+	var entryOffset int64
+	if i.offsetFn != nil {
+		entryOffset = i.offsetFn(file)
+	}
+
 	reader := newCommitLogReader(i.opts, i.seriesPred)
-	index, err := reader.Open(file.FilePath)
+	index, err := reader.Open(file.FilePath, entryOffset)
 	if err != nil {
 		i.err = err
 		return false
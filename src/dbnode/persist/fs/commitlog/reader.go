@@ -68,8 +68,9 @@ type seriesMetadata struct {
 }
 
 type commitLogReader interface {
-	// Open opens the commit log for reading
-	Open(filePath string) (int64, error)
+	// Open opens the commit log for reading, the first entryOffset entries
+	// are decoded but not returned
+	Open(filePath string, entryOffset int64) (int64, error)
 
 	// Read returns the next id and data pair or error, will return io.EOF at end of volume
 	Read() (ts.Series, ts.Datapoint, xtime.Unit, ts.Annotation, error)
@@ -93,6 +94,7 @@ type decoderArg struct {
 	uniqueIndex          uint64
 	offset               int
 	bufPool              chan []byte
+	skip                 bool
 }
 
 type readerMetadata struct {
@@ -115,6 +117,7 @@ type reader struct {
 	shutdownCh           chan error
 	metadata             readerMetadata
 	nextIndex            int64
+	entryOffset          int64
 	hasBeenOpened        bool
 	bgWorkersInitialized int64
 	seriesPredicate      SeriesFilterPredicate
@@ -159,12 +162,13 @@ func newCommitLogReader(opts Options, seriesPredicate SeriesFilterPredicate) com
 	return reader
 }
 
-func (r *reader) Open(filePath string) (int64, error) {
+func (r *reader) Open(filePath string, entryOffset int64) (int64, error) {
 	// Commitlog reader does not currently support being reused
 	if r.hasBeenOpened {
 		return 0, errCommitLogReaderIsNotReusable
 	}
 	r.hasBeenOpened = true
+	r.entryOffset = entryOffset
 
 	fd, err := os.Open(filePath)
 	if err != nil {
@@ -239,6 +243,10 @@ func (r *reader) readLoop() {
 
 	reusedBytes := make([]byte, 0, r.opts.FlushSize())
 
+	// numEntries is the number of entries read so far, entries before the
+	// entry offset still need to be decoded for their series metadata.
+	var numEntries int64
+
 	for {
 		select {
 		case <-r.cancelCtx.Done():
@@ -257,6 +265,9 @@ func (r *reader) readLoop() {
 				continue
 			}
 
+			skip := numEntries < r.entryOffset
+			numEntries++
+
 			decoderStream.Reset(data)
 			decoder.Reset(decoderStream)
 			decodeRemainingToken, uniqueIndex, err := decoder.DecodeLogEntryUniqueIndex()
@@ -289,6 +300,7 @@ func (r *reader) readLoop() {
 				uniqueIndex:          uniqueIndex,
 				offset:               decoderStream.Offset(),
 				bufPool:              bufPool,
+				skip:                 skip,
 			}
 		}
 	}
@@ -350,9 +362,10 @@ func (r *reader) decoderLoop(inBuf <-chan decoderArg, outBuf chan<- readResponse
 			continue
 		}
 
-		if !metadata.passedPredicate {
+		if !metadata.passedPredicate || arg.skip {
 			// Pass nil for outBuf because we don't want to send a readResponse along since this
-			// was just a series that the caller didn't want us to read.
+			// was just a series that the caller didn't want us to read or an entry preceding
+			// the entry offset.
 			r.handleDecoderLoopIterationEnd(arg, nil, readResponse{}, nil)
 			continue
 		}
@@ -79,6 +79,11 @@ type CommitLog interface {
 	// the new commitlog file.
 	RotateLogs() (persist.CommitLogFile, error)
 
+	// ActivePosition returns the File that represents the active commitlog
+	// file with its Offset set to the number of entries written to it once
+	// every write enqueued before the call has been processed.
+	ActivePosition() (persist.CommitLogFile, error)
+
 	// QueueLength returns the number of writes that are currently in the commitlog
 	// queue.
 	QueueLength() int64
@@ -104,6 +109,9 @@ type IteratorOpts struct {
 	CommitLogOptions      Options
 	FileFilterPredicate   FileFilterPredicate
 	SeriesFilterPredicate SeriesFilterPredicate
+	// FileEntryOffsetFn is optional and returns the number of leading
+	// entries to skip for each commit log file that is read.
+	FileEntryOffsetFn FileEntryOffsetFn
 }
 
 // Options represents the options for the commit log.
@@ -190,6 +198,10 @@ type Options interface {
 // which commitlogs the iterator should read from.
 type FileFilterPredicate func(f persist.CommitLogFile) bool
 
+// FileEntryOffsetFn returns the number of leading entries of a commit log
+// file that the iterator should skip because they are covered elsewhere.
+type FileEntryOffsetFn func(f persist.CommitLogFile) int64
+
 // SeriesFilterPredicate is a predicate that determines whether datapoints for a given series
 // should be returned from the Commit log reader. The predicate is pushed down to the
 // reader level to prevent having to run the same function for every datapoint for a
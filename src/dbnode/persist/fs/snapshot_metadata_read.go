@@ -105,6 +105,7 @@ func (w *SnapshotMetadataReader) Read(id SnapshotMetadataIdentifier) (SnapshotMe
 		CommitlogIdentifier: persist.CommitLogFile{
 			FilePath: protoMetadata.CommitlogID.FilePath,
 			Index:    protoMetadata.CommitlogID.Index,
+			Offset:   protoMetadata.CommitlogID.Offset,
 		},
 		MetadataFilePath:   snapshotMetadataFilePathFromIdentifier(prefix, id),
 		CheckpointFilePath: snapshotMetadataCheckpointFilePathFromIdentifier(prefix, id),
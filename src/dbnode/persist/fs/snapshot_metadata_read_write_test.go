@@ -40,6 +40,7 @@ func TestSnapshotMetadataWriteAndRead(t *testing.T) {
 		commitlogIdentifier = persist.CommitLogFile{
 			FilePath: "some_path",
 			Index:    1,
+			Offset:   42,
 		}
 		numMetadataFiles = 10
 	)
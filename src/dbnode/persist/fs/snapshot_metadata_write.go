@@ -110,6 +110,7 @@ func (w *SnapshotMetadataWriter) Write(args SnapshotMetadataWriteArgs) (finalErr
 		CommitlogID: &snapshot.CommitLogID{
 			FilePath: args.CommitlogIdentifier.FilePath,
 			Index:    args.CommitlogIdentifier.Index,
+			Offset:   args.CommitlogIdentifier.Offset,
 		},
 	})
 	if err != nil {
@@ -63,6 +63,20 @@ func (c CommitLogFiles) Contains(path string) bool {
 type CommitLogFile struct {
 	FilePath string
 	Index    int64
+
+	// Offset is the number of entries written to the file prior to the
+	// position being identified, it is zero when identifying the file as
+	// a whole.
+	Offset int64
+}
+
+// Before returns whether the position identified by the commit log file
+// and its offset precedes the position identified by other.
+func (f CommitLogFile) Before(other CommitLogFile) bool {
+	if f.Index != other.Index {
+		return f.Index < other.Index
+	}
+	return f.Offset < other.Offset
 }
 
 // IndexFn is a function that persists a m3ninx MutableSegment.
@@ -255,6 +255,11 @@ func Run(runOpts RunOptions) {
 		logger.Warnf("max index query IDs concurrency was not set, falling back to default value")
 	}
 
+	if cfg.Limits != nil {
+		limitsScope := scope.SubScope("query-limits")
+		opts = opts.SetQueryLimits(cfg.Limits.NewQueryLimits(limitsScope))
+	}
+
 	buildReporter := instrument.NewBuildReporter(iopts)
 	if err := buildReporter.Start(); err != nil {
 		logger.Fatalf("unable to start build reporter: %v", err)
@@ -490,6 +495,12 @@ func Run(runOpts RunOptions) {
 		},
 		func(opts client.AdminOptions) client.AdminOptions {
 			return opts.SetOrigin(origin)
+		},
+		func(opts client.AdminOptions) client.AdminOptions {
+			if peersCfg := cfg.Bootstrap.Peers; peersCfg != nil {
+				return opts.SetFetchSeriesBlocksPerPeerLimitMbps(peersCfg.PerPeerLimitMbps)
+			}
+			return opts
 		})
 	if err != nil {
 		logger.Fatalf("could not create m3db client: %v", err)
@@ -528,7 +539,9 @@ func Run(runOpts RunOptions) {
 		logger.Fatalf("could not create bootstrap process: %v", err)
 	}
 
-	opts = opts.SetBootstrapProcessProvider(bs)
+	opts = opts.
+		SetBootstrapProcessProvider(bs).
+		SetBootstrapShardBatchSize(cfg.Bootstrap.ShardBatchSize)
 	timeout := bootstrapConfigInitTimeout
 	kvWatchBootstrappers(envCfg.KVStore, logger, timeout, cfg.Bootstrap.Bootstrappers,
 		func(bootstrappers []string) {
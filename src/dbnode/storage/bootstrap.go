@@ -169,7 +169,7 @@ func (m *bootstrapManager) Report() {
 	}
 }
 
-func (m *bootstrapManager) bootstrap() error {
+func (m *bootstrapManager) bootstrap() (err error) {
 	// NB(r): construct new instance of the bootstrap process to avoid
 	// state being kept around by bootstrappers.
 	process, err := m.processProvider.Provide()
@@ -177,6 +177,12 @@ func (m *bootstrapManager) bootstrap() error {
 		return err
 	}
 
+	progress := m.processProvider.Progress()
+	progress.Start(m.nowFn())
+	defer func() {
+		progress.Complete(m.nowFn(), err)
+	}()
+
 	// NB(xichen): each bootstrapper should be responsible for choosing the most
 	// efficient way of bootstrapping database shards, be it sequential or parallel.
 	multiErr := xerrors.NewMultiError()
@@ -87,7 +87,8 @@ func (b baseBootstrapper) BootstrapData(
 		return result.NewDataBootstrapResult(), nil
 	}
 	step := newBootstrapDataStep(namespace, b.src, b.next, opts)
-	err := b.runBootstrapStep(namespace, shardsTimeRanges, step)
+	err := b.runBootstrapStep(namespace, shardsTimeRanges, step,
+		bootstrap.DataRunType, opts.Progress())
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +104,8 @@ func (b baseBootstrapper) BootstrapIndex(
 		return result.NewIndexBootstrapResult(), nil
 	}
 	step := newBootstrapIndexStep(namespace, b.src, b.next, opts)
-	err := b.runBootstrapStep(namespace, shardsTimeRanges, step)
+	err := b.runBootstrapStep(namespace, shardsTimeRanges, step,
+		bootstrap.IndexRunType, opts.Progress())
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +116,8 @@ func (b baseBootstrapper) runBootstrapStep(
 	namespace namespace.Metadata,
 	totalRanges result.ShardTimeRanges,
 	step bootstrapStep,
+	runType bootstrap.RunType,
+	progress bootstrap.Progress,
 ) error {
 	prepareResult, err := step.prepare(totalRanges)
 	if err != nil {
@@ -151,11 +155,14 @@ func (b baseBootstrapper) runBootstrapStep(
 		xlog.NewField("shards", len(currRanges)),
 	}
 	b.log.WithFields(logFields...).Infof("bootstrapping from source starting")
+	progress.StartSource(namespace.ID(), runType, b.name, currRanges)
 
 	nowFn := b.opts.ClockOptions().NowFn()
 	begin := nowFn()
 
 	currStatus, currErr = step.runCurrStep(currRanges)
+	progress.CompleteSource(namespace.ID(), runType, b.name,
+		currStatus.fulfilled, currErr)
 
 	logFields = append(logFields, xlog.NewField("took", nowFn().Sub(begin).String()))
 	if currErr != nil {
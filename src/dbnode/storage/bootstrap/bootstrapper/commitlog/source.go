@@ -61,6 +61,8 @@ type newIteratorFn func(opts commitlog.IteratorOpts) (
 	iter commitlog.Iterator, corruptFiles []commitlog.ErrorWithPath, err error)
 type snapshotFilesFn func(filePathPrefix string, namespace ident.ID, shard uint32) (fs.FileSetFilesSlice, error)
 type newReaderFn func(bytesPool pool.CheckedBytesPool, opts fs.Options) (fs.DataFileSetReader, error)
+type snapshotMetadataFilesFn func(opts fs.Options) (
+	[]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error)
 
 type commitLogSource struct {
 	opts Options
@@ -69,11 +71,15 @@ type commitLogSource struct {
 	// Filesystem inspection capture before node was started.
 	inspection fs.Inspection
 
-	newIteratorFn   newIteratorFn
-	snapshotFilesFn snapshotFilesFn
-	newReaderFn     newReaderFn
+	newIteratorFn           newIteratorFn
+	snapshotFilesFn         snapshotFilesFn
+	snapshotMetadataFilesFn snapshotMetadataFilesFn
+	newReaderFn             newReaderFn
 
 	metrics commitLogSourceDataAndIndexMetrics
+
+	batchReadsLock sync.Mutex
+	batchReads     map[batchReadKey]commitLogRead
 }
 
 type encoder struct {
@@ -98,11 +104,14 @@ func newCommitLogSource(opts Options, inspection fs.Inspection) bootstrap.Source
 
 		inspection: inspection,
 
-		newIteratorFn:   commitlog.NewIterator,
-		snapshotFilesFn: fs.SnapshotFiles,
-		newReaderFn:     fs.NewReader,
+		newIteratorFn:           commitlog.NewIterator,
+		snapshotFilesFn:         fs.SnapshotFiles,
+		snapshotMetadataFilesFn: fs.SortedSnapshotMetadataFiles,
+		newReaderFn:             fs.NewReader,
 
 		metrics: newCommitLogSourceDataAndIndexMetrics(scope),
+
+		batchReads: make(map[batchReadKey]commitLogRead),
 	}
 }
 
@@ -122,10 +131,9 @@ func (s *commitLogSource) AvailableData(
 	return s.availability(ns, shardsTimeRanges, runOpts)
 }
 
-// ReadData will read all commitlog files on disk, as well as as the latest snapshot for
-// each shard/block combination (if it exists) and merge them.
-// TODO(rartoul): Make this take the SnapshotMetadata files into account to reduce the
-// number of commitlogs / snapshots that we need to read.
+// ReadData will read the latest snapshot for each shard/block combination (if it exists)
+// and only the commitlog entries written after the commitlog position captured by those
+// snapshots, and merge them.
 func (s *commitLogSource) ReadData(
 	ns namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
@@ -135,20 +143,147 @@ func (s *commitLogSource) ReadData(
 		return result.NewDataBootstrapResult(), nil
 	}
 
+	// Emit bootstrapping gauge for duration of ReadData
+	doneReadingData := s.metrics.data.emitBootstrapping()
+	defer doneReadingData()
+
+	var (
+		read commitLogRead
+		err  error
+	)
+	if batch := runOpts.ShardBatch(); batch.IsBatched() {
+		read, err = s.readCommitLogForBatch(ns, shardsTimeRanges, batch, runOpts)
+	} else {
+		read, err = s.readCommitLog(ns, shardsTimeRanges, runOpts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge all the different encoders from the commit log that we created with
+	// the data that is available in the snapshot files.
+	s.log.Infof("starting merge...")
+	mergeStart := time.Now()
+	bootstrapResult, err := s.mergeAllShardsCommitLogEncodersAndSnapshots(
+		ns,
+		shardsTimeRanges,
+		read.snapshotFilesByShard,
+		read.mostRecentCompleteSnapshotByBlockShard,
+		len(read.shardDataByShard),
+		ns.Options().RetentionOptions().BlockSize(),
+		read.shardDataByShard,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Infof("done merging..., took: %s", time.Since(mergeStart).String())
+
+	shouldReturnUnfulfilled, err := s.shouldReturnUnfulfilled(
+		read.encounteredCorruptData, ns, shardsTimeRanges, runOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if shouldReturnUnfulfilled {
+		bootstrapResult.SetUnfulfilled(shardsTimeRanges)
+	}
+
+	return bootstrapResult, nil
+}
+
+// readCommitLogForBatch returns the commit log data of the shards of a batch
+// of a batched bootstrap. The commit log is read once for the remaining
+// shards of the batches the first time it is needed, each batch then takes
+// the data of its shards and the data of shards fulfilled before reaching
+// this source is released.
+func (s *commitLogSource) readCommitLogForBatch(
+	ns namespace.Metadata,
+	shardsTimeRanges result.ShardTimeRanges,
+	batch bootstrap.ShardBatch,
+	runOpts bootstrap.RunOptions,
+) (commitLogRead, error) {
+	min, max := batch.Remaining.MinMax()
+	key := batchReadKey{
+		namespace: ns.ID().String(),
+		start:     xtime.ToUnixNano(min),
+		end:       xtime.ToUnixNano(max),
+	}
+
+	s.batchReadsLock.Lock()
+	defer s.batchReadsLock.Unlock()
+
+	all, ok := s.batchReads[key]
+	if !ok {
+		var err error
+		all, err = s.readCommitLog(ns, batch.Remaining, runOpts)
+		if err != nil {
+			return commitLogRead{}, err
+		}
+		s.batchReads[key] = all
+	}
+
+	var (
+		blockSize = ns.Options().RetentionOptions().BlockSize()
+		inBatch   = make(map[uint32]struct{}, len(batch.Shards))
+		read      = commitLogRead{
+			snapshotFilesByShard:                   all.snapshotFilesByShard,
+			mostRecentCompleteSnapshotByBlockShard: all.mostRecentCompleteSnapshotByBlockShard,
+			shardDataByShard:                       make([]shardData, len(all.shardDataByShard)),
+			encounteredCorruptData:                 all.encounteredCorruptData,
+		}
+		pending = 0
+	)
+	for _, shard := range batch.Shards {
+		inBatch[shard] = struct{}{}
+	}
+	for i, data := range all.shardDataByShard {
+		if data.series == nil {
+			continue
+		}
+
+		shard := uint32(i)
+		_, isBatch := inBatch[shard]
+		_, isRemaining := batch.Remaining[shard]
+		if !isBatch && isRemaining {
+			// Belongs to a later batch.
+			pending++
+			continue
+		}
+
+		all.shardDataByShard[shard] = shardData{}
+		ranges, ok := shardsTimeRanges[shard]
+		if !isBatch || !ok {
+			s.closeShardData(data)
+			continue
+		}
+		read.shardDataByShard[shard] = s.restrictShardData(data, ranges, blockSize)
+	}
+
+	if pending == 0 {
+		delete(s.batchReads, key)
+	}
+
+	return read, nil
+}
+
+// readCommitLog reads the commit log entries of the shards for the ranges
+// that are not already captured by the most recent snapshots.
+func (s *commitLogSource) readCommitLog(
+	ns namespace.Metadata,
+	shardsTimeRanges result.ShardTimeRanges,
+	runOpts bootstrap.RunOptions,
+) (commitLogRead, error) {
 	var (
-		// Emit bootstrapping gauge for duration of ReadData
-		doneReadingData        = s.metrics.data.emitBootstrapping()
 		encounteredCorruptData = false
 		fsOpts                 = s.opts.CommitLogOptions().FilesystemOptions()
 		filePathPrefix         = fsOpts.FilePathPrefix()
 	)
-	defer doneReadingData()
 
 	// Determine which snapshot files are available.
 	snapshotFilesByShard, err := s.snapshotFilesByShard(
 		ns.ID(), filePathPrefix, shardsTimeRanges)
 	if err != nil {
-		return nil, err
+		return commitLogRead{}, err
 	}
 
 	var (
@@ -157,10 +292,10 @@ func (s *commitLogSource) ReadData(
 		blockSize = ns.Options().RetentionOptions().BlockSize()
 	)
 
-	readCommitLogPred, mostRecentCompleteSnapshotByBlockShard, err := s.newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
+	readCommitLogPred, readCommitLogOffsetFn, mostRecentCompleteSnapshotByBlockShard, err := s.newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
 		ns, shardsTimeRanges, snapshotFilesByShard)
 	if err != nil {
-		return nil, err
+		return commitLogRead{}, err
 	}
 
 	// Setup the commit log iterator.
@@ -186,6 +321,7 @@ func (s *commitLogSource) ReadData(
 			CommitLogOptions:      s.opts.CommitLogOptions(),
 			FileFilterPredicate:   readCommitLogPred,
 			SeriesFilterPredicate: readSeriesPredicate,
+			FileEntryOffsetFn:     readCommitLogOffsetFn,
 		}
 	)
 
@@ -195,9 +331,10 @@ func (s *commitLogSource) ReadData(
 		s.log.Infof("datapointsRead: %d", datapointsRead)
 	}()
 
+	readStart := time.Now()
 	iter, corruptFiles, err := s.newIteratorFn(iterOpts)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create commit log iterator: %v", err)
+		return commitLogRead{}, fmt.Errorf("unable to create commit log iterator: %v", err)
 	}
 
 	if len(corruptFiles) > 0 {
@@ -278,37 +415,55 @@ func (s *commitLogSource) ReadData(
 	// Block until all required data from the commit log has been read and
 	// encoded by the worker goroutines
 	wg.Wait()
+	s.metrics.data.readDuration.Record(time.Since(readStart))
 	s.logEncodingOutcome(workerErrs, iter)
 
-	// Merge all the different encoders from the commit log that we created with
-	// the data that is available in the snapshot files.
-	s.log.Infof("starting merge...")
-	mergeStart := time.Now()
-	bootstrapResult, err := s.mergeAllShardsCommitLogEncodersAndSnapshots(
-		ns,
-		shardsTimeRanges,
-		snapshotFilesByShard,
-		mostRecentCompleteSnapshotByBlockShard,
-		int(numShards),
-		blockSize,
-		shardDataByShard,
-	)
-	if err != nil {
-		return nil, err
-	}
-	s.log.Infof("done merging..., took: %s", time.Since(mergeStart).String())
+	return commitLogRead{
+		snapshotFilesByShard:                   snapshotFilesByShard,
+		mostRecentCompleteSnapshotByBlockShard: mostRecentCompleteSnapshotByBlockShard,
+		shardDataByShard:                       shardDataByShard,
+		encounteredCorruptData:                 encounteredCorruptData,
+	}, nil
+}
 
-	shouldReturnUnfulfilled, err := s.shouldReturnUnfulfilled(
-		encounteredCorruptData, ns, shardsTimeRanges, runOpts)
-	if err != nil {
-		return nil, err
+// restrictShardData removes the blocks of the commit log data of a shard
+// that are outside of the ranges.
+func (s *commitLogSource) restrictShardData(
+	data shardData,
+	ranges xtime.Ranges,
+	blockSize time.Duration,
+) shardData {
+	for _, entry := range data.series.Iter() {
+		val := entry.Value()
+		for blockStart, encoders := range val.encoders {
+			start := blockStart.ToTime()
+			blockRange := xtime.Range{Start: start, End: start.Add(blockSize)}
+			if ranges.Overlaps(blockRange) {
+				continue
+			}
+			closeEncoders(encoders)
+			delete(val.encoders, blockStart)
+		}
+		if len(val.encoders) == 0 {
+			data.series.Delete(val.id)
+		}
 	}
+	data.ranges = ranges
+	return data
+}
 
-	if shouldReturnUnfulfilled {
-		bootstrapResult.SetUnfulfilled(shardsTimeRanges)
+func (s *commitLogSource) closeShardData(data shardData) {
+	for _, entry := range data.series.Iter() {
+		for _, encoders := range entry.Value().encoders {
+			closeEncoders(encoders)
+		}
 	}
+}
 
-	return bootstrapResult, nil
+func closeEncoders(encoders []encoder) {
+	for _, enc := range encoders {
+		enc.enc.Close()
+	}
 }
 
 func (s *commitLogSource) snapshotFilesByShard(
@@ -633,6 +788,7 @@ func (s *commitLogSource) newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
 	snapshotFilesByShard map[uint32]fs.FileSetFilesSlice,
 ) (
 	commitlog.FileFilterPredicate,
+	commitlog.FileEntryOffsetFn,
 	map[xtime.UnixNano]map[uint32]fs.FileSetFile,
 	error,
 ) {
@@ -645,7 +801,7 @@ func (s *commitLogSource) newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
 
 			if mostRecent.CachedSnapshotTime.IsZero() {
 				// Should never happen.
-				return nil, nil, instrument.InvariantErrorf(
+				return nil, nil, nil, instrument.InvariantErrorf(
 					"shard: %d and block: %s had zero value for most recent snapshot time",
 					shard, block.ToTime().String())
 			}
@@ -656,15 +812,91 @@ func (s *commitLogSource) newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
 		}
 	}
 
-	return func(f persist.CommitLogFile) bool {
-		// Read all the commitlog files that were available on disk before the node started
-		// accepting writes.
-		// TODO(rartoul): Refactor this to take the SnapshotMetadata files into account to reduce
-		// the number of commitlog files that need to be read.
+	replayFrom, canSkip := s.snapshotsCommitlogPosition(mostRecentCompleteSnapshotByBlockShard)
+	if canSkip {
+		s.log.Infof(
+			"snapshots cover commitlog entries before file index: %d and entry offset: %d",
+			replayFrom.Index, replayFrom.Offset)
+	}
+
+	readCommitLogPred := func(f persist.CommitLogFile) bool {
+		// Read the commitlog files that were available on disk before the node started
+		// accepting writes, skipping any files entirely covered by the snapshots.
 		commitlogFilesPresentBeforeStart := s.inspection.CommitLogFilesSet()
 		_, ok := commitlogFilesPresentBeforeStart[f.FilePath]
-		return ok
-	}, mostRecentCompleteSnapshotByBlockShard, nil
+		if !ok {
+			return false
+		}
+		return !canSkip || f.Index >= replayFrom.Index
+	}
+	readCommitLogOffsetFn := func(f persist.CommitLogFile) int64 {
+		// Skip the entries of the first file that are covered by the snapshots.
+		if canSkip && f.Index == replayFrom.Index {
+			return replayFrom.Offset
+		}
+		return 0
+	}
+	return readCommitLogPred, readCommitLogOffsetFn, mostRecentCompleteSnapshotByBlockShard, nil
+}
+
+// snapshotsCommitlogPosition returns the earliest commitlog position captured by
+// the snapshots that will be read for each shard/block combination, commitlog entries
+// before the position are covered by the snapshots. Returns false if any shard/block
+// combination has no snapshot or the snapshot metadata can't be resolved, in which
+// case all the commitlogs need to be read.
+func (s *commitLogSource) snapshotsCommitlogPosition(
+	mostRecentCompleteSnapshotByBlockShard map[xtime.UnixNano]map[uint32]fs.FileSetFile,
+) (persist.CommitLogFile, bool) {
+	if len(mostRecentCompleteSnapshotByBlockShard) == 0 {
+		return persist.CommitLogFile{}, false
+	}
+
+	fsOpts := s.opts.CommitLogOptions().FilesystemOptions()
+	metadatas, _, err := s.snapshotMetadataFilesFn(fsOpts)
+	if err != nil {
+		s.log.Errorf("unable to read snapshot metadata files, replaying all commitlogs: %v", err)
+		return persist.CommitLogFile{}, false
+	}
+
+	metadataByID := make(map[string]fs.SnapshotMetadata, len(metadatas))
+	for _, metadata := range metadatas {
+		metadataByID[metadata.ID.UUID.String()] = metadata
+	}
+
+	var (
+		earliest persist.CommitLogFile
+		found    bool
+	)
+	for block, mostRecentByShard := range mostRecentCompleteSnapshotByBlockShard {
+		for shard, mostRecent := range mostRecentByShard {
+			if mostRecent.IsZero() {
+				s.log.Debugf(
+					"no snapshot for block: %s and shard: %d, replaying all commitlogs",
+					block.ToTime().String(), shard)
+				return persist.CommitLogFile{}, false
+			}
+
+			_, snapshotID, err := mostRecent.SnapshotTimeAndID()
+			if err != nil || snapshotID == nil {
+				return persist.CommitLogFile{}, false
+			}
+
+			metadata, ok := metadataByID[snapshotID.String()]
+			if !ok {
+				s.log.Debugf(
+					"no snapshot metadata for snapshot: %s, replaying all commitlogs",
+					snapshotID.String())
+				return persist.CommitLogFile{}, false
+			}
+
+			if !found || metadata.CommitlogIdentifier.Before(earliest) {
+				earliest = metadata.CommitlogIdentifier
+				found = true
+			}
+		}
+	}
+
+	return earliest, found
 }
 
 func (s *commitLogSource) startM3TSZEncodingWorker(
@@ -821,6 +1053,7 @@ func (s *commitLogSource) mergeAllShardsCommitLogEncodersAndSnapshots(
 		workerPool          = xsync.NewWorkerPool(s.opts.MergeShardsConcurrency())
 		bootstrapResultLock sync.Mutex
 		wg                  sync.WaitGroup
+		snapshotErr         error
 	)
 	workerPool.Init()
 
@@ -830,32 +1063,31 @@ func (s *commitLogSource) mergeAllShardsCommitLogEncodersAndSnapshots(
 			continue
 		}
 
-		snapshotData, err := s.bootstrapShardSnapshots(
-			ns.ID(),
-			uint32(shard),
-			false,
-			shardsTimeRanges[uint32(shard)],
-			blockSize,
-			snapshotFiles[uint32(shard)],
-			mostRecentCompleteSnapshotByBlockShard,
-		)
-		if err != nil {
-			bootstrapResultLock.Lock()
-			// Mark the shard time ranges as unfulfilled so a subsequent bootstrapper
-			// has the chance to fulfill it.
-			bootstrapResult.Add(
+		// Read the snapshots and merge them with the commit log data for each
+		// shard in parallel.
+		wg.Add(1)
+		shard, unmergedShard := shard, unmergedShard
+		mergeShardFunc := func() {
+			defer wg.Done()
+
+			snapshotData, err := s.bootstrapShardSnapshots(
+				ns.ID(),
 				uint32(shard),
-				result.NewShardResult(0, s.opts.ResultOptions()),
+				false,
 				shardsTimeRanges[uint32(shard)],
+				blockSize,
+				snapshotFiles[uint32(shard)],
+				mostRecentCompleteSnapshotByBlockShard,
 			)
-			bootstrapResultLock.Unlock()
-			return nil, err
-		}
+			if err != nil {
+				bootstrapResultLock.Lock()
+				if snapshotErr == nil {
+					snapshotErr = err
+				}
+				bootstrapResultLock.Unlock()
+				return
+			}
 
-		// Merge snapshot and commit log data
-		wg.Add(1)
-		shard, unmergedShard := shard, unmergedShard
-		mergeShardFunc := func() {
 			var shardResult result.ShardResult
 			shardResult, shardEmptyErrs[shard], shardErrs[shard] = s.mergeShardCommitLogEncodersAndSnapshots(
 				shard, snapshotData, unmergedShard, blockSize)
@@ -872,13 +1104,15 @@ func (s *commitLogSource) mergeAllShardsCommitLogEncodersAndSnapshots(
 				}
 				bootstrapResultLock.Unlock()
 			}
-			wg.Done()
 		}
 		workerPool.Go(mergeShardFunc)
 	}
 
 	// Wait for all merge goroutines to complete
 	wg.Wait()
+	if snapshotErr != nil {
+		return nil, snapshotErr
+	}
 	s.logMergeShardsOutcome(shardErrs, shardEmptyErrs)
 	return bootstrapResult, nil
 }
@@ -1162,7 +1396,7 @@ func (s *commitLogSource) ReadIndex(
 
 	// Determine which commit log files we need to read based on which snapshot
 	// snapshot files are available.
-	readCommitLogPredicate, mostRecentCompleteSnapshotByBlockShard, err := s.newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
+	readCommitLogPredicate, readCommitLogOffsetFn, mostRecentCompleteSnapshotByBlockShard, err := s.newReadCommitlogPredAndMostRecentSnapshotByBlockShard(
 		ns, shardsTimeRanges, snapshotFilesByShard)
 	if err != nil {
 		return nil, err
@@ -1174,6 +1408,7 @@ func (s *commitLogSource) ReadIndex(
 			CommitLogOptions:      s.opts.CommitLogOptions(),
 			FileFilterPredicate:   readCommitLogPredicate,
 			SeriesFilterPredicate: readSeriesPredicate,
+			FileEntryOffsetFn:     readCommitLogOffsetFn,
 		}
 	)
 
@@ -1200,6 +1435,7 @@ func (s *commitLogSource) ReadIndex(
 
 	// Next, read all of the data from the commit log files that wasn't covered
 	// by the snapshot files.
+	readStart := time.Now()
 	iter, corruptFiles, err := s.newIteratorFn(iterOpts)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create commit log iterator: %v", err)
@@ -1229,6 +1465,7 @@ func (s *commitLogSource) ReadIndex(
 		encounteredCorruptData = true
 		s.metrics.index.corruptCommitlogFile.Inc(1)
 	}
+	s.metrics.index.readDuration.Record(time.Since(readStart))
 
 	// If all successful then we mark each index block as fulfilled
 	for _, block := range indexResult.IndexResults() {
@@ -1446,6 +1683,23 @@ type shardData struct {
 	ranges xtime.Ranges
 }
 
+// commitLogRead is the commit log data read for a set of shards along with
+// the snapshots it is to be merged with.
+type commitLogRead struct {
+	snapshotFilesByShard                   map[uint32]fs.FileSetFilesSlice
+	mostRecentCompleteSnapshotByBlockShard map[xtime.UnixNano]map[uint32]fs.FileSetFile
+	shardDataByShard                       []shardData
+	encounteredCorruptData                 bool
+}
+
+// batchReadKey identifies the commit log read shared by the batches of a
+// batched bootstrap of a namespace for a target range.
+type batchReadKey struct {
+	namespace string
+	start     xtime.UnixNano
+	end       xtime.UnixNano
+}
+
 type metadataAndEncodersByTime struct {
 	id   ident.ID
 	tags ident.Tags
@@ -1517,6 +1771,7 @@ func newCommitLogSourceDataAndIndexMetrics(scope tally.Scope) commitLogSourceDat
 
 type commitLogSourceMetrics struct {
 	corruptCommitlogFile tally.Counter
+	readDuration         tally.Timer
 	bootstrapping        tally.Gauge
 }
 
@@ -1543,6 +1798,7 @@ func (m commitLogSourceMetrics) emitBootstrapping() gaugeLoopCloserFn {
 func newCommitLogSourceMetrics(scope tally.Scope) commitLogSourceMetrics {
 	return commitLogSourceMetrics{
 		corruptCommitlogFile: scope.SubScope("commitlog").Counter("corrupt"),
+		readDuration:         scope.SubScope("commitlog").Timer("read-duration"),
 		bootstrapping:        scope.SubScope("status").Gauge("bootstrapping"),
 	}
 }
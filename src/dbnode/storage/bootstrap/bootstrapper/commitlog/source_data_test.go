@@ -46,6 +46,7 @@ import (
 	xtime "github.com/m3db/m3x/time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -277,6 +278,66 @@ func TestReadTrimsToRanges(t *testing.T) {
 		values[1:3], blockSize, res.ShardResults(), opts))
 }
 
+func TestReadShardBatchesReadsCommitLogOnce(t *testing.T) {
+	opts := testDefaultOpts
+	md := testNsMetadata(t)
+	src := newCommitLogSource(opts, fs.Inspection{}).(*commitLogSource)
+
+	blockSize := md.Options().RetentionOptions().BlockSize()
+	now := time.Now()
+	start := now.Truncate(blockSize).Add(-blockSize)
+	end := now.Truncate(blockSize)
+
+	require.True(t, blockSize >= minCommitLogRetention)
+	ranges := xtime.Ranges{}
+	ranges = ranges.AddRange(xtime.Range{
+		Start: start,
+		End:   end,
+	})
+
+	foo := ts.Series{Namespace: testNamespaceID, Shard: 0, ID: ident.StringID("foo")}
+	bar := ts.Series{Namespace: testNamespaceID, Shard: 1, ID: ident.StringID("bar")}
+
+	values := []testValue{
+		{foo, start, 1.0, xtime.Second, nil},
+		{bar, start.Add(1 * time.Minute), 2.0, xtime.Second, nil},
+		{foo, start.Add(2 * time.Minute), 3.0, xtime.Second, nil},
+		{bar, start.Add(3 * time.Minute), 4.0, xtime.Second, nil},
+	}
+	numReads := 0
+	src.newIteratorFn = func(_ commitlog.IteratorOpts) (commitlog.Iterator, []commitlog.ErrorWithPath, error) {
+		numReads++
+		return newTestCommitLogIterator(values, nil), nil, nil
+	}
+
+	remaining := result.ShardTimeRanges{0: ranges, 1: ranges}
+	for _, shard := range []uint32{0, 1} {
+		runOpts := testDefaultRunOpts.SetShardBatch(bootstrap.ShardBatch{
+			Shards:    []uint32{shard},
+			Remaining: remaining.Copy(),
+		})
+		targetRanges := result.ShardTimeRanges{shard: ranges}
+		res, err := src.ReadData(md, targetRanges, runOpts)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(res.ShardResults()))
+		require.Equal(t, 0, len(res.Unfulfilled()))
+
+		var expected []testValue
+		for _, v := range values {
+			if v.s.Shard == shard {
+				expected = append(expected, v)
+			}
+		}
+		require.NoError(t, verifyShardResultsAreCorrect(
+			expected, blockSize, res.ShardResults(), opts))
+
+		delete(remaining, shard)
+	}
+
+	require.Equal(t, 1, numReads)
+	require.Equal(t, 0, len(src.batchReads))
+}
+
 func TestItMergesSnapshotsAndCommitLogs(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -404,6 +465,60 @@ type seriesShardResult struct {
 	result block.DatabaseSeriesBlocks
 }
 
+func TestSnapshotsCommitlogPosition(t *testing.T) {
+	var (
+		opts      = testDefaultOpts
+		src       = newCommitLogSource(opts, fs.Inspection{}).(*commitLogSource)
+		blockSize = time.Hour
+		start     = time.Now().Truncate(blockSize)
+		block0    = xtime.ToUnixNano(start)
+		block1    = xtime.ToUnixNano(start.Add(blockSize))
+		uuid0     = uuid.NewRandom()
+		uuid1     = uuid.NewRandom()
+	)
+
+	snapshot := func(id uuid.UUID) fs.FileSetFile {
+		return fs.FileSetFile{
+			AbsoluteFilepaths:  []string{"snapshots/checkpoint"},
+			CachedSnapshotTime: start.Add(time.Minute),
+			CachedSnapshotID:   id,
+		}
+	}
+	src.snapshotMetadataFilesFn = func(_ fs.Options) (
+		[]fs.SnapshotMetadata, []fs.SnapshotMetadataErrorWithPaths, error) {
+		return []fs.SnapshotMetadata{
+			{
+				ID:                  fs.SnapshotMetadataIdentifier{Index: 0, UUID: uuid0},
+				CommitlogIdentifier: persist.CommitLogFile{Index: 3, Offset: 20},
+			},
+			{
+				ID:                  fs.SnapshotMetadataIdentifier{Index: 1, UUID: uuid1},
+				CommitlogIdentifier: persist.CommitLogFile{Index: 3, Offset: 100},
+			},
+		}, nil, nil
+	}
+
+	// The earliest position of all the snapshots is used.
+	position, ok := src.snapshotsCommitlogPosition(map[xtime.UnixNano]map[uint32]fs.FileSetFile{
+		block0: {0: snapshot(uuid1), 1: snapshot(uuid0)},
+		block1: {0: snapshot(uuid1), 1: snapshot(uuid1)},
+	})
+	require.True(t, ok)
+	require.Equal(t, persist.CommitLogFile{Index: 3, Offset: 20}, position)
+
+	// A shard/block without a snapshot requires all commitlogs to be read.
+	_, ok = src.snapshotsCommitlogPosition(map[xtime.UnixNano]map[uint32]fs.FileSetFile{
+		block0: {0: snapshot(uuid1), 1: {CachedSnapshotTime: start}},
+	})
+	require.False(t, ok)
+
+	// A snapshot without metadata requires all commitlogs to be read.
+	_, ok = src.snapshotsCommitlogPosition(map[xtime.UnixNano]map[uint32]fs.FileSetFile{
+		block0: {0: snapshot(uuid1), 1: snapshot(uuid.NewRandom())},
+	})
+	require.False(t, ok)
+}
+
 func verifyShardResultsAreCorrect(
 	values []testValue,
 	blockSize time.Duration,
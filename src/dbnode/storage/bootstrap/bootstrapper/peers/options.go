@@ -27,6 +27,7 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
@@ -53,6 +54,8 @@ type options struct {
 	persistManager              persist.Manager
 	blockRetrieverManager       block.DatabaseBlockRetrieverManager
 	runtimeOptionsManager       m3dbruntime.OptionsManager
+	fsOpts                      fs.Options
+	resumeEnabled               bool
 }
 
 // NewOptions creates new bootstrap options
@@ -62,6 +65,7 @@ func NewOptions() Options {
 		defaultShardConcurrency:     defaultDefaultShardConcurrency,
 		shardPersistenceConcurrency: defaultShardPersistenceConcurrency,
 		persistenceMaxQueueSize:     defaultPersistenceMaxQueueSize,
+		fsOpts:                      fs.NewOptions(),
 	}
 }
 
@@ -159,3 +163,23 @@ func (o *options) SetRuntimeOptionsManager(value m3dbruntime.OptionsManager) Opt
 func (o *options) RuntimeOptionsManager() m3dbruntime.OptionsManager {
 	return o.runtimeOptionsManager
 }
+
+func (o *options) SetFilesystemOptions(value fs.Options) Options {
+	opts := *o
+	opts.fsOpts = value
+	return &opts
+}
+
+func (o *options) FilesystemOptions() fs.Options {
+	return o.fsOpts
+}
+
+func (o *options) SetResumeEnabled(value bool) Options {
+	opts := *o
+	opts.resumeEnabled = value
+	return &opts
+}
+
+func (o *options) ResumeEnabled() bool {
+	return o.resumeEnabled
+}
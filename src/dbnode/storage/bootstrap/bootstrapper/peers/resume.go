@@ -0,0 +1,189 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package peers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+)
+
+const (
+	resumeDirName       = "peers-bootstrap"
+	resumeFileSuffix    = ".json"
+	resumeTmpFileSuffix = ".tmp"
+)
+
+// resumeStore records the block starts of each shard that have been fetched
+// from peers and flushed to disk during bootstraps with persistence so that
+// a restarted node can skip fetching them again.
+type resumeStore struct {
+	sync.Mutex
+
+	filePathPrefix   string
+	newFileMode      os.FileMode
+	newDirectoryMode os.FileMode
+}
+
+type shardResumeState struct {
+	BlockStarts []int64 `json:"blockStarts"`
+}
+
+func newResumeStore(fsOpts fs.Options) *resumeStore {
+	return &resumeStore{
+		filePathPrefix:   fsOpts.FilePathPrefix(),
+		newFileMode:      fsOpts.NewFileMode(),
+		newDirectoryMode: fsOpts.NewDirectoryMode(),
+	}
+}
+
+func (r *resumeStore) namespaceDirPath(namespace ident.ID) string {
+	return path.Join(r.filePathPrefix, resumeDirName, namespace.String())
+}
+
+func (r *resumeStore) shardFilePath(namespace ident.ID, shard uint32) string {
+	return path.Join(r.namespaceDirPath(namespace),
+		strconv.Itoa(int(shard))+resumeFileSuffix)
+}
+
+// flushedBlockStarts returns the block starts recorded as flushed for a shard.
+func (r *resumeStore) flushedBlockStarts(
+	namespace ident.ID,
+	shard uint32,
+) (map[xtime.UnixNano]struct{}, error) {
+	r.Lock()
+	state, err := r.readWithLock(namespace, shard)
+	r.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[xtime.UnixNano]struct{}, len(state.BlockStarts))
+	for _, blockStart := range state.BlockStarts {
+		result[xtime.UnixNano(blockStart)] = struct{}{}
+	}
+	return result, nil
+}
+
+// markFlushed records a block start of a shard as flushed, any recorded
+// block starts before the earliest block start are dropped as they have
+// fallen out of retention.
+func (r *resumeStore) markFlushed(
+	namespace ident.ID,
+	shard uint32,
+	blockStart time.Time,
+	earliestBlockStart time.Time,
+) error {
+	r.Lock()
+	defer r.Unlock()
+
+	state, err := r.readWithLock(namespace, shard)
+	if err != nil {
+		return err
+	}
+
+	var (
+		earliest    = earliestBlockStart.UnixNano()
+		blockStarts = make([]int64, 0, len(state.BlockStarts)+1)
+		exists      = false
+	)
+	for _, existing := range state.BlockStarts {
+		if existing < earliest {
+			continue
+		}
+		if existing == blockStart.UnixNano() {
+			exists = true
+		}
+		blockStarts = append(blockStarts, existing)
+	}
+	if !exists {
+		blockStarts = append(blockStarts, blockStart.UnixNano())
+	}
+	sort.Slice(blockStarts, func(i, j int) bool {
+		return blockStarts[i] < blockStarts[j]
+	})
+
+	return r.writeWithLock(namespace, shard, shardResumeState{
+		BlockStarts: blockStarts,
+	})
+}
+
+// remove deletes the resume state of a shard.
+func (r *resumeStore) remove(namespace ident.ID, shard uint32) error {
+	r.Lock()
+	defer r.Unlock()
+
+	err := os.Remove(r.shardFilePath(namespace, shard))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *resumeStore) readWithLock(
+	namespace ident.ID,
+	shard uint32,
+) (shardResumeState, error) {
+	var state shardResumeState
+	data, err := ioutil.ReadFile(r.shardFilePath(namespace, shard))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+func (r *resumeStore) writeWithLock(
+	namespace ident.ID,
+	shard uint32,
+	state shardResumeState,
+) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(r.namespaceDirPath(namespace), r.newDirectoryMode); err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename so that a crash mid write
+	// never leaves a partially written resume state behind.
+	filePath := r.shardFilePath(namespace, shard)
+	tmpFilePath := filePath + resumeTmpFileSuffix
+	if err := ioutil.WriteFile(tmpFilePath, data, r.newFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilePath, filePath)
+}
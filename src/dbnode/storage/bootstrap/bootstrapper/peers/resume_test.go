@@ -0,0 +1,161 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package peers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/ident"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeStoreMarkFlushed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peers-resume")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		store     = newResumeStore(fs.NewOptions().SetFilePathPrefix(dir))
+		ns        = ident.StringID("testns")
+		blockSize = 2 * time.Hour
+		start     = time.Now().Truncate(blockSize)
+	)
+
+	// No resume state recorded yet.
+	flushed, err := store.flushedBlockStarts(ns, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(flushed))
+
+	require.NoError(t, store.markFlushed(ns, 0, start, start))
+	require.NoError(t, store.markFlushed(ns, 0, start.Add(blockSize), start))
+	require.NoError(t, store.markFlushed(ns, 0, start.Add(blockSize), start))
+
+	flushed, err = store.flushedBlockStarts(ns, 0)
+	require.NoError(t, err)
+	require.Equal(t, map[xtime.UnixNano]struct{}{
+		xtime.ToUnixNano(start):                {},
+		xtime.ToUnixNano(start.Add(blockSize)): {},
+	}, flushed)
+
+	// Other shards are recorded separately.
+	flushed, err = store.flushedBlockStarts(ns, 1)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(flushed))
+
+	// Block starts that fall out of retention are dropped.
+	require.NoError(t, store.markFlushed(ns, 0, start.Add(2*blockSize),
+		start.Add(blockSize)))
+
+	flushed, err = store.flushedBlockStarts(ns, 0)
+	require.NoError(t, err)
+	require.Equal(t, map[xtime.UnixNano]struct{}{
+		xtime.ToUnixNano(start.Add(blockSize)):     {},
+		xtime.ToUnixNano(start.Add(2 * blockSize)): {},
+	}, flushed)
+
+	// Removing the resume state of a shard leaves other shards untouched.
+	require.NoError(t, store.markFlushed(ns, 1, start, start))
+	require.NoError(t, store.remove(ns, 0))
+	require.NoError(t, store.remove(ns, 0))
+
+	flushed, err = store.flushedBlockStarts(ns, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(flushed))
+
+	flushed, err = store.flushedBlockStarts(ns, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(flushed))
+}
+
+func TestPeersSourceResumesOnlyVerifiedFileSets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peers-resume")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		fsOpts     = fs.NewOptions().SetFilePathPrefix(dir)
+		nsMetadata = testNamespaceMetadata(t)
+		blockSize  = nsMetadata.Options().RetentionOptions().BlockSize()
+		start      = time.Now().Truncate(blockSize).Add(-4 * blockSize)
+		end        = start.Add(2 * blockSize)
+		ranges     = result.ShardTimeRanges{
+			0: xtime.Ranges{}.AddRange(xtime.Range{Start: start, End: end}),
+		}
+	)
+
+	src, err := newPeersSource(testDefaultOpts.
+		SetFilesystemOptions(fsOpts).
+		SetResumeEnabled(true))
+	require.NoError(t, err)
+	source := src.(*peersSource)
+
+	// Both blocks are recorded as flushed but only the first has a fileset.
+	require.NoError(t, source.resume.markFlushed(testNamespace, 0, start, start))
+	require.NoError(t, source.resume.markFlushed(testNamespace, 0,
+		start.Add(blockSize), start))
+
+	w, err := fs.NewWriter(fsOpts)
+	require.NoError(t, err)
+	require.NoError(t, w.Open(fs.DataWriterOpenOptions{
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:  testNamespace,
+			Shard:      0,
+			BlockStart: start,
+		},
+		BlockSize: blockSize,
+	}))
+	data := checked.NewBytes([]byte{1, 2, 3}, nil)
+	data.IncRef()
+	require.NoError(t, w.Write(ident.StringID("foo"), ident.Tags{}, data,
+		digest.Checksum(data.Bytes())))
+	data.DecRef()
+	require.NoError(t, w.Close())
+
+	remaining := source.withoutResumedRanges(nsMetadata, ranges)
+	require.Equal(t, result.ShardTimeRanges{
+		0: xtime.Ranges{}.AddRange(xtime.Range{
+			Start: start.Add(blockSize),
+			End:   end,
+		}),
+	}.String(), remaining.String())
+
+	// A fileset that no longer reads back is fetched again.
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !strings.HasSuffix(path, "-data.db") {
+			return err
+		}
+		return ioutil.WriteFile(path, make([]byte, info.Size()), info.Mode())
+	})
+	require.NoError(t, err)
+
+	remaining = source.withoutResumedRanges(nsMetadata, ranges)
+	require.Equal(t, ranges.String(), remaining.String())
+}
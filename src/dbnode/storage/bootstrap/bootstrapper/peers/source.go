@@ -22,13 +22,16 @@ package peers
 
 import (
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
@@ -37,16 +40,41 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3x/context"
+	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/instrument"
 	xlog "github.com/m3db/m3x/log"
 	xsync "github.com/m3db/m3x/sync"
 	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
 )
 
 type peersSource struct {
-	opts  Options
-	log   xlog.Logger
-	nowFn clock.NowFn
+	opts    Options
+	log     xlog.Logger
+	nowFn   clock.NowFn
+	resume  *resumeStore
+	metrics peersSourceMetrics
+
+	pendingBlocks int64
+}
+
+type peersSourceMetrics struct {
+	blocksFetched tally.Counter
+	blocksFailed  tally.Counter
+	blocksResumed tally.Counter
+	bytesFetched  tally.Counter
+	blocksPending tally.Gauge
+}
+
+func newPeersSourceMetrics(scope tally.Scope) peersSourceMetrics {
+	return peersSourceMetrics{
+		blocksFetched: scope.Counter("blocks-fetched"),
+		blocksFailed:  scope.Counter("blocks-failed"),
+		blocksResumed: scope.Counter("blocks-resumed"),
+		bytesFetched:  scope.Counter("bytes-fetched"),
+		blocksPending: scope.Gauge("blocks-pending"),
+	}
 }
 
 type persistenceFlush struct {
@@ -58,11 +86,17 @@ type persistenceFlush struct {
 }
 
 func newPeersSource(opts Options) (bootstrap.Source, error) {
-	return &peersSource{
-		opts:  opts,
-		log:   opts.ResultOptions().InstrumentOptions().Logger(),
-		nowFn: opts.ResultOptions().ClockOptions().NowFn(),
-	}, nil
+	iOpts := opts.ResultOptions().InstrumentOptions()
+	src := &peersSource{
+		opts:    opts,
+		log:     iOpts.Logger(),
+		nowFn:   opts.ResultOptions().ClockOptions().NowFn(),
+		metrics: newPeersSourceMetrics(iOpts.MetricsScope().SubScope("peers-bootstrapper")),
+	}
+	if opts.ResumeEnabled() {
+		src.resume = newResumeStore(opts.FilesystemOptions())
+	}
+	return src, nil
 }
 
 func (s *peersSource) Can(strategy bootstrap.Strategy) bool {
@@ -180,16 +214,29 @@ func (s *peersSource) ReadData(
 			opts, persistenceWorkerDoneCh, persistenceQueue, persistFlush, result, &resultLock)
 	}
 
+	fetchShardsTimeRanges := shardsTimeRanges
+	if shouldPersist && s.resume != nil {
+		fetchShardsTimeRanges = s.withoutResumedRanges(nsMetadata,
+			shardsTimeRanges)
+	}
+
+	// Fetch up to concurrency blocks at a time across all the shards, each
+	// block being assigned to one of the replicas of its shard so that all
+	// the replicas stream blocks at the same time.
 	workers := xsync.NewWorkerPool(concurrency)
 	workers.Init()
-	for shard, ranges := range shardsTimeRanges {
-		shard, ranges := shard, ranges
+	for _, fetch := range s.blockFetches(opts, fetchShardsTimeRanges, blockSize) {
+		fetch := fetch
+		s.updatePendingBlocks(1)
 		wg.Add(1)
 		workers.Go(func() {
-			defer wg.Done()
-			s.fetchBootstrapBlocksFromPeers(shard, ranges, nsMetadata, session,
+			defer func() {
+				s.updatePendingBlocks(-1)
+				wg.Done()
+			}()
+			s.fetchBootstrapBlockFromPeers(fetch, nsMetadata, session,
 				resultOpts, result, &resultLock, shouldPersist, persistenceQueue,
-				shardRetrieverMgr, blockSize)
+				shardRetrieverMgr, opts.Progress())
 		})
 	}
 
@@ -206,6 +253,10 @@ func (s *peersSource) ReadData(
 		if err != nil {
 			return nil, err
 		}
+
+		if s.resume != nil {
+			s.clearResumable(nsMetadata, shardsTimeRanges, result.Unfulfilled())
+		}
 	}
 
 	return result, nil
@@ -234,6 +285,7 @@ func (s *peersSource) startPersistenceQueueWorkerLoop(
 			lock.Lock()
 			bootstrapResult.Add(flush.shard, flush.shardResult, xtime.Ranges{})
 			lock.Unlock()
+			s.markResumable(flush.nsMetadata, flush.shard, flush.timeRange)
 			continue
 		}
 
@@ -250,15 +302,59 @@ func (s *peersSource) startPersistenceQueueWorkerLoop(
 	close(doneCh)
 }
 
-// fetchBootstrapBlocksFromPeers loops through all the provided ranges for a given shard and
-// fetches all the bootstrap blocks from the appropriate peers.
+// blockFetch is a block of a shard to fetch from peers, from the assigned
+// replica if any.
+type blockFetch struct {
+	shard      uint32
+	blockRange xtime.Range
+	replica    topology.Host
+}
+
+// blockFetches splits the shard time ranges into the blocks to fetch, each
+// block being assigned to the available replica of its shard with the fewest
+// blocks assigned so far. Blocks are left unassigned, and fetched from the
+// most fulfilled replicas, without an initial topology.
+func (s *peersSource) blockFetches(
+	runOpts bootstrap.RunOptions,
+	shardsTimeRanges result.ShardTimeRanges,
+	blockSize time.Duration,
+) []blockFetch {
+	var (
+		fetches  []blockFetch
+		assigned = make(map[string]int)
+	)
+	for shard, ranges := range shardsTimeRanges {
+		replicas := s.shardReplicas(runOpts, shard)
+		it := ranges.Iter()
+		for it.Next() {
+			currRange := it.Value()
+			for blockStart := currRange.Start; blockStart.Before(currRange.End); blockStart = blockStart.Add(blockSize) {
+				fetch := blockFetch{
+					shard:      shard,
+					blockRange: xtime.Range{Start: blockStart, End: blockStart.Add(blockSize)},
+				}
+				for _, replica := range replicas {
+					if fetch.replica == nil || assigned[replica.ID()] < assigned[fetch.replica.ID()] {
+						fetch.replica = replica
+					}
+				}
+				if fetch.replica != nil {
+					assigned[fetch.replica.ID()]++
+				}
+				fetches = append(fetches, fetch)
+			}
+		}
+	}
+	return fetches
+}
+
+// fetchBootstrapBlockFromPeers fetches a bootstrap block from the appropriate peers.
 // 		Persistence enabled case: Immediately add the results to the bootstrap result
 // 		Persistence disabled case: Don't add the results yet, but push a flush into the
 // 						  persistenceQueue. The persistenceQueue worker will eventually
 // 						  add the results once its performed the flush.
-func (s *peersSource) fetchBootstrapBlocksFromPeers(
-	shard uint32,
-	ranges xtime.Ranges,
+func (s *peersSource) fetchBootstrapBlockFromPeers(
+	fetch blockFetch,
 	nsMetadata namespace.Metadata,
 	session client.AdminSession,
 	bopts result.Options,
@@ -267,44 +363,352 @@ func (s *peersSource) fetchBootstrapBlocksFromPeers(
 	shouldPersist bool,
 	persistenceQueue chan persistenceFlush,
 	shardRetrieverMgr block.DatabaseShardBlockRetrieverManager,
-	blockSize time.Duration,
+	progress bootstrap.Progress,
 ) {
-	it := ranges.Iter()
-	for it.Next() {
-		currRange := it.Value()
+	var (
+		shard       = fetch.shard
+		blockRange  = fetch.blockRange
+		shardResult result.ShardResult
+		err         error
+	)
+	if fetch.replica != nil {
+		shardResult, err = s.fetchBootstrapBlockFromReplica(fetch, nsMetadata,
+			session, bopts)
+	} else {
+		shardResult, err = session.FetchBootstrapBlocksFromPeers(
+			nsMetadata, shard, blockRange.Start, blockRange.End, bopts)
+	}
+
+	s.logFetchBootstrapBlocksFromPeersOutcome(shard, shardResult, err)
+
+	if err != nil {
+		s.metrics.blocksFailed.Inc(1)
+		// Do not add result at all to the bootstrap result
+		lock.Lock()
+		bootstrapResult.Add(shard, nil, xtime.NewRanges(blockRange))
+		lock.Unlock()
+		return
+	}
 
-		for blockStart := currRange.Start; blockStart.Before(currRange.End); blockStart = blockStart.Add(blockSize) {
-			blockEnd := blockStart.Add(blockSize)
-			shardResult, err := session.FetchBootstrapBlocksFromPeers(
-				nsMetadata, shard, blockStart, blockEnd, bopts)
+	bytes := shardResultBytes(shardResult)
+	s.metrics.blocksFetched.Inc(1)
+	s.metrics.bytesFetched.Inc(bytes)
+	progress.AddPeerBytesStreamed(nsMetadata.ID(), bytes)
 
-			s.logFetchBootstrapBlocksFromPeersOutcome(shard, shardResult, err)
+	if shouldPersist {
+		persistenceQueue <- persistenceFlush{
+			nsMetadata:        nsMetadata,
+			shard:             shard,
+			shardRetrieverMgr: shardRetrieverMgr,
+			shardResult:       shardResult,
+			timeRange:         blockRange,
+		}
+		return
+	}
 
-			if err != nil {
-				// Do not add result at all to the bootstrap result
-				lock.Lock()
-				bootstrapResult.Add(shard, nil, xtime.NewRanges(currRange))
-				lock.Unlock()
-				continue
-			}
+	// If not waiting to flush, add straight away to bootstrap result
+	lock.Lock()
+	bootstrapResult.Add(shard, shardResult, xtime.Ranges{})
+	lock.Unlock()
+}
+
+func (s *peersSource) updatePendingBlocks(delta int64) {
+	pending := atomic.AddInt64(&s.pendingBlocks, delta)
+	s.metrics.blocksPending.Update(float64(pending))
+}
+
+// fetchBootstrapBlockFromReplica fetches a bootstrap block from the replica
+// assigned to it, only fetching the blocks of series from other replicas
+// when they are more fulfilled or missing from the assigned replica.
+func (s *peersSource) fetchBootstrapBlockFromReplica(
+	fetch blockFetch,
+	nsMetadata namespace.Metadata,
+	session client.AdminSession,
+	bopts result.Options,
+) (result.ShardResult, error) {
+	metadataIter, err := session.FetchBootstrapBlocksMetadataFromPeers(nsMetadata.ID(),
+		fetch.shard, fetch.blockRange.Start, fetch.blockRange.End, bopts)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]block.ReplicaMetadata)
+	defer func() {
+		// Finalize the metadata of the blocks not added to the result.
+		for _, metadata := range selected {
+			metadata.Finalize()
+		}
+	}()
+
+	for metadataIter.Next() {
+		host, metadata := metadataIter.Current()
+		id := metadata.ID.String()
+		curr, ok := selected[id]
+		if !ok {
+			selected[id] = block.ReplicaMetadata{Metadata: metadata, Host: host}
+			continue
+		}
+
+		moreFulfilled := metadata.Size > curr.Size ||
+			(metadata.Size == curr.Size && host.ID() == fetch.replica.ID())
+		if !moreFulfilled {
+			metadata.Finalize()
+			continue
+		}
+
+		curr.Finalize()
+		selected[id] = block.ReplicaMetadata{Metadata: metadata, Host: host}
+	}
+	if err := metadataIter.Err(); err != nil {
+		return nil, err
+	}
+
+	metadatas := make([]block.ReplicaMetadata, 0, len(selected))
+	for _, metadata := range selected {
+		metadatas = append(metadatas, metadata)
+	}
+
+	blocksIter, err := session.FetchBlocksFromPeers(nsMetadata, fetch.shard,
+		topology.ReadConsistencyLevelOne, metadatas, bopts)
+	if err != nil {
+		return nil, err
+	}
+
+	shardResult := result.NewShardResult(len(metadatas), bopts)
+	for blocksIter.Next() {
+		_, blockID, dataBlock := blocksIter.Current()
+		id := blockID.String()
+		metadata, ok := selected[id]
+		if !ok {
+			dataBlock.Close()
+			continue
+		}
+
+		// The ID and tags of the metadata are now owned by the result.
+		shardResult.AddBlock(metadata.ID, metadata.Tags, dataBlock)
+		delete(selected, id)
+	}
+	if err := blocksIter.Err(); err != nil {
+		shardResult.Close()
+		return nil, err
+	}
+
+	return shardResult, nil
+}
+
+// shardReplicas returns the peers available to fetch the shard from, sorted
+// by their ID.
+func (s *peersSource) shardReplicas(
+	runOpts bootstrap.RunOptions,
+	shardID uint32,
+) []topology.Host {
+	initialTopologyState := runOpts.InitialTopologyState()
+	if initialTopologyState == nil {
+		return nil
+	}
+
+	var replicas []topology.Host
+	hostShardStates := initialTopologyState.ShardStates[topology.ShardID(shardID)]
+	for _, hostShardState := range hostShardStates {
+		if hostShardState.Host.ID() == initialTopologyState.Origin.ID() {
+			// Don't take self into account
+			continue
+		}
+
+		switch hostShardState.ShardState {
+		case shard.Leaving, shard.Available:
+			replicas = append(replicas, hostShardState.Host)
+		}
+	}
+
+	// Sort the replicas so that blocks are assigned in a stable order.
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].ID() < replicas[j].ID()
+	})
+	return replicas
+}
 
-			if shouldPersist {
-				persistenceQueue <- persistenceFlush{
-					nsMetadata:        nsMetadata,
-					shard:             shard,
-					shardRetrieverMgr: shardRetrieverMgr,
-					shardResult:       shardResult,
-					timeRange:         xtime.Range{Start: blockStart, End: blockEnd},
+// withoutResumedRanges returns the shard time ranges that remain to be
+// fetched after removing blocks that a previous bootstrap with persistence
+// already fetched from peers and flushed to disk. It is only used when
+// persisting, i.e. never with CacheAll, since the resumed blocks are not
+// loaded into the result and are instead served from their filesets by the
+// block retriever, whose shard indices are cached for every range including
+// the resumed ones.
+func (s *peersSource) withoutResumedRanges(
+	nsMetadata namespace.Metadata,
+	shardsTimeRanges result.ShardTimeRanges,
+) result.ShardTimeRanges {
+	var (
+		filePathPrefix = s.opts.FilesystemOptions().FilePathPrefix()
+		blockSize      = nsMetadata.Options().RetentionOptions().BlockSize()
+		resumed        = result.ShardTimeRanges{}
+		numResumed     int64
+	)
+	for shard, ranges := range shardsTimeRanges {
+		flushed, err := s.resume.flushedBlockStarts(nsMetadata.ID(), shard)
+		if err != nil {
+			s.log.WithFields(
+				xlog.NewField("namespace", nsMetadata.ID().String()),
+				xlog.NewField("shard", shard),
+				xlog.NewField("error", err.Error()),
+			).Errorf("peers bootstrapper unable to read resume state")
+			continue
+		}
+		if len(flushed) == 0 {
+			continue
+		}
+
+		shardResumed := xtime.Ranges{}
+		it := ranges.Iter()
+		for it.Next() {
+			currRange := it.Value()
+			for blockStart := currRange.Start; blockStart.Before(currRange.End); blockStart = blockStart.Add(blockSize) {
+				if _, ok := flushed[xtime.ToUnixNano(blockStart)]; !ok {
+					continue
 				}
-				continue
+
+				// Only skip the block if the fileset flushed for it is still
+				// complete on disk and its metadata validates, otherwise fetch
+				// it from peers again.
+				exists, err := fs.DataFileSetExistsAt(filePathPrefix,
+					nsMetadata.ID(), shard, blockStart)
+				if err != nil || !exists {
+					continue
+				}
+				if err := s.verifyFlushedFileSet(nsMetadata.ID(), shard, blockStart); err != nil {
+					s.log.WithFields(
+						xlog.NewField("namespace", nsMetadata.ID().String()),
+						xlog.NewField("shard", shard),
+						xlog.NewField("blockStart", blockStart.String()),
+						xlog.NewField("error", err.Error()),
+					).Warnf("peers bootstrapper unable to verify flushed fileset, fetching block again")
+					continue
+				}
+
+				shardResumed = shardResumed.AddRange(xtime.Range{
+					Start: blockStart,
+					End:   blockStart.Add(blockSize),
+				})
+				numResumed++
 			}
+		}
+		if !shardResumed.IsEmpty() {
+			resumed[shard] = shardResumed
+		}
+	}
 
-			// If not waiting to flush, add straight away to bootstrap result
-			lock.Lock()
-			bootstrapResult.Add(shard, shardResult, xtime.Ranges{})
-			lock.Unlock()
+	if resumed.IsEmpty() {
+		return shardsTimeRanges
+	}
+
+	s.metrics.blocksResumed.Inc(numResumed)
+	s.log.WithFields(
+		xlog.NewField("namespace", nsMetadata.ID().String()),
+		xlog.NewField("numBlocks", numResumed),
+		xlog.NewField("resumed", resumed.SummaryString()),
+	).Infof("peers bootstrapper resuming, skipping blocks already flushed")
+
+	remaining := shardsTimeRanges.Copy()
+	remaining.Subtract(resumed)
+	return remaining
+}
+
+// verifyFlushedFileSet validates the flushed fileset of a block from its
+// checkpoint, digests, info and index files without reading its data, which
+// is only complete once a checkpoint file with the digest of the digests of
+// all the files has been written.
+func (s *peersSource) verifyFlushedFileSet(
+	nsID ident.ID,
+	shard uint32,
+	blockStart time.Time,
+) error {
+	reader, err := fs.NewReader(nil, s.opts.FilesystemOptions())
+	if err != nil {
+		return err
+	}
+
+	err = reader.Open(fs.DataReaderOpenOptions{
+		Identifier: fs.FileSetFileIdentifier{
+			Namespace:  nsID,
+			Shard:      shard,
+			BlockStart: blockStart,
+		},
+		FileSetType: persist.FileSetFlushType,
+	})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if start := reader.Range().Start; !start.Equal(blockStart) {
+		return fmt.Errorf("fileset info block start %v does not match %v",
+			start, blockStart)
+	}
+
+	return reader.ValidateMetadata()
+}
+
+// clearResumable removes the resume state of the shards that have been
+// bootstrapped in full, their blocks are all flushed and are loaded from
+// disk by any later bootstrap.
+func (s *peersSource) clearResumable(
+	nsMetadata namespace.Metadata,
+	shardsTimeRanges result.ShardTimeRanges,
+	unfulfilled result.ShardTimeRanges,
+) {
+	for shard := range shardsTimeRanges {
+		if ranges, ok := unfulfilled[shard]; ok && !ranges.IsEmpty() {
+			continue
+		}
+		if err := s.resume.remove(nsMetadata.ID(), shard); err != nil {
+			s.log.WithFields(
+				xlog.NewField("namespace", nsMetadata.ID().String()),
+				xlog.NewField("shard", shard),
+				xlog.NewField("error", err.Error()),
+			).Errorf("peers bootstrapper unable to remove resume state")
+		}
+	}
+}
+
+// markResumable records that the blocks of a time range have been fetched
+// and flushed so that they are not fetched again if the node restarts.
+func (s *peersSource) markResumable(
+	nsMetadata namespace.Metadata,
+	shard uint32,
+	tr xtime.Range,
+) {
+	if s.resume == nil {
+		return
+	}
+
+	var (
+		ropts              = nsMetadata.Options().RetentionOptions()
+		blockSize          = ropts.BlockSize()
+		earliestBlockStart = s.nowFn().Add(-ropts.RetentionPeriod()).Truncate(blockSize)
+	)
+	for blockStart := tr.Start; blockStart.Before(tr.End); blockStart = blockStart.Add(blockSize) {
+		err := s.resume.markFlushed(nsMetadata.ID(), shard, blockStart,
+			earliestBlockStart)
+		if err != nil {
+			s.log.WithFields(
+				xlog.NewField("namespace", nsMetadata.ID().String()),
+				xlog.NewField("shard", shard),
+				xlog.NewField("blockStart", blockStart.String()),
+				xlog.NewField("error", err.Error()),
+			).Errorf("peers bootstrapper unable to write resume state")
+		}
+	}
+}
+
+func shardResultBytes(shardResult result.ShardResult) int64 {
+	var bytes int64
+	for _, entry := range shardResult.AllSeries().Iter() {
+		for _, dbBlock := range entry.Value().Blocks.AllBlocks() {
+			bytes += int64(dbBlock.Len())
 		}
 	}
+	return bytes
 }
 
 func (s *peersSource) logFetchBootstrapBlocksFromPeersOutcome(
@@ -738,3 +738,94 @@ func assertBlockChecksum(t *testing.T, expectedChecksum uint32, block block.Data
 	require.NoError(t, err)
 	require.Equal(t, expectedChecksum, checksum)
 }
+
+func TestPeersSourceFetchesBlocksFromAssignedReplica(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		opts       = testDefaultOpts
+		nsMetadata = testNamespaceMetadata(t)
+		ropts      = nsMetadata.Options().RetentionOptions()
+		start      = time.Now().Add(-ropts.RetentionPeriod()).Truncate(ropts.BlockSize())
+		end        = start.Add(ropts.BlockSize())
+		assigned   = topology.NewHost("assigned", "assigned:9000")
+		other      = topology.NewHost("other", "other:9000")
+		fooBlock   = block.NewDatabaseBlock(start, ropts.BlockSize(), ts.Segment{}, testBlockOpts)
+		barBlock   = block.NewDatabaseBlock(start, ropts.BlockSize(), ts.Segment{}, testBlockOpts)
+	)
+
+	newMetadata := func(id string, size int64) block.Metadata {
+		return block.NewMetadata(ident.StringID(id),
+			ident.NewTags(ident.StringTag(id, "tag")), start, size, nil, time.Time{})
+	}
+
+	// The assigned replica is preferred unless another replica has a more
+	// fulfilled block of the series.
+	metadataIter := client.NewMockPeerBlockMetadataIter(ctrl)
+	gomock.InOrder(
+		metadataIter.EXPECT().Next().Return(true),
+		metadataIter.EXPECT().Current().Return(other, newMetadata("foo", 10)),
+		metadataIter.EXPECT().Next().Return(true),
+		metadataIter.EXPECT().Current().Return(assigned, newMetadata("foo", 10)),
+		metadataIter.EXPECT().Next().Return(true),
+		metadataIter.EXPECT().Current().Return(assigned, newMetadata("bar", 5)),
+		metadataIter.EXPECT().Next().Return(true),
+		metadataIter.EXPECT().Current().Return(other, newMetadata("bar", 20)),
+		metadataIter.EXPECT().Next().Return(false),
+		metadataIter.EXPECT().Err().Return(nil),
+	)
+
+	blocksIter := client.NewMockPeerBlocksIter(ctrl)
+	gomock.InOrder(
+		blocksIter.EXPECT().Next().Return(true),
+		blocksIter.EXPECT().Current().Return(assigned, ident.StringID("foo"), fooBlock),
+		blocksIter.EXPECT().Next().Return(true),
+		blocksIter.EXPECT().Current().Return(other, ident.StringID("bar"), barBlock),
+		blocksIter.EXPECT().Next().Return(false),
+		blocksIter.EXPECT().Err().Return(nil),
+	)
+
+	mockAdminSession := client.NewMockAdminSession(ctrl)
+	mockAdminSession.EXPECT().
+		FetchBootstrapBlocksMetadataFromPeers(ident.NewIDMatcher(testNamespace.String()),
+			uint32(0), start, end, gomock.Any()).
+		Return(metadataIter, nil)
+	mockAdminSession.EXPECT().
+		FetchBlocksFromPeers(namespace.NewMetadataMatcher(nsMetadata), uint32(0),
+			topology.ReadConsistencyLevelOne, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ namespace.Metadata,
+			_ uint32,
+			_ topology.ReadConsistencyLevel,
+			metadatas []block.ReplicaMetadata,
+			_ result.Options,
+		) (client.PeerBlocksIter, error) {
+			hosts := make(map[string]string, len(metadatas))
+			for _, metadata := range metadatas {
+				hosts[metadata.ID.String()] = metadata.Host.ID()
+			}
+			require.Equal(t, map[string]string{
+				"foo": assigned.ID(),
+				"bar": other.ID(),
+			}, hosts)
+			return blocksIter, nil
+		})
+
+	src, err := newPeersSource(opts)
+	require.NoError(t, err)
+
+	shardResult, err := src.(*peersSource).fetchBootstrapBlockFromReplica(blockFetch{
+		shard:      0,
+		blockRange: xtime.Range{Start: start, End: end},
+		replica:    assigned,
+	}, nsMetadata, mockAdminSession, opts.ResultOptions())
+	require.NoError(t, err)
+
+	block, ok := shardResult.BlockAt(ident.StringID("foo"), start)
+	require.True(t, ok)
+	require.Equal(t, fooBlock, block)
+	block, ok = shardResult.BlockAt(ident.StringID("bar"), start)
+	require.True(t, ok)
+	require.Equal(t, barBlock, block)
+}
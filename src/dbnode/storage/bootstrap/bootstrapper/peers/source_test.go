@@ -172,6 +172,61 @@ func TestPeersSourceAvailableDataAndIndex(t *testing.T) {
 	}
 }
 
+func TestPeersSourceBlockFetches(t *testing.T) {
+	src, err := newPeersSource(testDefaultOpts)
+	require.NoError(t, err)
+	peersSrc := src.(*peersSource)
+
+	var (
+		blockSize  = 2 * time.Hour
+		blockStart = time.Now().Truncate(blockSize)
+		ranges     = xtime.NewRanges(xtime.Range{
+			Start: blockStart,
+			End:   blockStart.Add(4 * blockSize),
+		})
+	)
+
+	// Without a topology the blocks are not assigned to a replica.
+	fetches := peersSrc.blockFetches(testDefaultRunOpts,
+		result.ShardTimeRanges{0: ranges}, blockSize)
+	require.Len(t, fetches, 4)
+	for _, fetch := range fetches {
+		require.Nil(t, fetch.replica)
+	}
+
+	runOpts := testDefaultRunOpts.SetInitialTopologyState(
+		tu.NewStateSnapshot(2, tu.HostShardStates{
+			tu.SelfID:  tu.ShardsRange(0, 1, shard.Initializing),
+			notSelfID1: tu.ShardsRange(0, 1, shard.Available),
+			notSelfID2: tu.ShardsRange(0, 0, shard.Leaving),
+		}))
+
+	// The blocks of a shard are spread across its replicas.
+	fetches = peersSrc.blockFetches(runOpts,
+		result.ShardTimeRanges{0: ranges}, blockSize)
+	require.Len(t, fetches, 4)
+	assigned := make(map[string]int)
+	for i, fetch := range fetches {
+		require.Equal(t, uint32(0), fetch.shard)
+		require.Equal(t, blockStart.Add(time.Duration(i)*blockSize), fetch.blockRange.Start)
+		require.NotNil(t, fetch.replica)
+		assigned[fetch.replica.ID()]++
+	}
+	require.Equal(t, map[string]int{notSelfID1: 2, notSelfID2: 2}, assigned)
+
+	// Shards are only fetched from their own replicas.
+	fetches = peersSrc.blockFetches(runOpts,
+		result.ShardTimeRanges{1: ranges, 5: ranges}, blockSize)
+	require.Len(t, fetches, 8)
+	for _, fetch := range fetches {
+		if fetch.shard == 1 {
+			require.Equal(t, notSelfID1, fetch.replica.ID())
+		} else {
+			require.Nil(t, fetch.replica)
+		}
+	}
+}
+
 func TestPeersSourceReturnsErrorIfUnknownPersistenceFileSetType(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -23,6 +23,7 @@ package peers
 import (
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
 	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
@@ -100,4 +101,22 @@ type Options interface {
 
 	// RuntimeOptionsManagers returns the RuntimeOptionsManager.
 	RuntimeOptionsManager() m3dbruntime.OptionsManager
+
+	// SetFilesystemOptions sets the filesystem options, used to locate
+	// the resume state of bootstraps with persistence.
+	SetFilesystemOptions(value fs.Options) Options
+
+	// FilesystemOptions returns the filesystem options, used to locate
+	// the resume state of bootstraps with persistence.
+	FilesystemOptions() fs.Options
+
+	// SetResumeEnabled sets whether the blocks fetched and flushed during a
+	// bootstrap with persistence are recorded on disk so that a restarted
+	// node does not fetch them from peers again.
+	SetResumeEnabled(value bool) Options
+
+	// ResumeEnabled returns whether the blocks fetched and flushed during a
+	// bootstrap with persistence are recorded on disk so that a restarted
+	// node does not fetch them from peers again.
+	ResumeEnabled() bool
 }
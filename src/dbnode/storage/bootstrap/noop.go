@@ -45,6 +45,10 @@ func (b noOpBootstrapProcessProvider) Provide() (Process, error) {
 	return noOpBootstrapProcess{}, nil
 }
 
+func (b noOpBootstrapProcessProvider) Progress() Progress {
+	return NewNoOpProgress()
+}
+
 type noOpBootstrapProcess struct{}
 
 func (b noOpBootstrapProcess) Run(
@@ -57,3 +61,17 @@ func (b noOpBootstrapProcess) Run(
 		IndexResult: result.NewIndexBootstrapResult(),
 	}, nil
 }
+
+func (b noOpBootstrapProcess) RunBatches(
+	start time.Time,
+	ns namespace.Metadata,
+	batches [][]uint32,
+	fn ProcessBatchFn,
+) (result.IndexBootstrapResult, error) {
+	for _, shards := range batches {
+		if err := fn(shards, result.NewDataBootstrapResult()); err != nil {
+			return nil, err
+		}
+	}
+	return result.NewIndexBootstrapResult(), nil
+}
@@ -41,15 +41,9 @@ type bootstrapProcessProvider struct {
 	resultOpts           result.Options
 	log                  xlog.Logger
 	bootstrapperProvider BootstrapperProvider
+	progress             Progress
 }
 
-type bootstrapRunType string
-
-const (
-	bootstrapDataRunType  = bootstrapRunType("bootstrap-data")
-	bootstrapIndexRunType = bootstrapRunType("bootstrap-index")
-)
-
 // NewProcessProvider creates a new bootstrap process provider.
 func NewProcessProvider(
 	bootstrapperProvider BootstrapperProvider,
@@ -65,6 +59,7 @@ func NewProcessProvider(
 		resultOpts:           resultOpts,
 		log:                  resultOpts.InstrumentOptions().Logger(),
 		bootstrapperProvider: bootstrapperProvider,
+		progress:             NewProgress(),
 	}, nil
 }
 
@@ -100,9 +95,14 @@ func (b *bootstrapProcessProvider) Provide() (Process, error) {
 		log:                  b.log,
 		bootstrapper:         bootstrapper,
 		initialTopologyState: initialTopologyState,
+		progress:             b.progress,
 	}, nil
 }
 
+func (b *bootstrapProcessProvider) Progress() Progress {
+	return b.progress
+}
+
 func (b *bootstrapProcessProvider) newInitialTopologyState() (*topology.StateSnapshot, error) {
 	topoMap, err := b.processOpts.TopologyMapProvider().TopologyMap()
 	if err != nil {
@@ -145,6 +145,7 @@ type bootstrapProcess struct {
 	log                  xlog.Logger
 	bootstrapper         Bootstrapper
 	initialTopologyState *topology.StateSnapshot
+	progress             Progress
 }
 
 func (b bootstrapProcess) Run(
@@ -152,7 +153,7 @@ func (b bootstrapProcess) Run(
 	namespace namespace.Metadata,
 	shards []uint32,
 ) (ProcessResult, error) {
-	dataResult, err := b.bootstrapData(start, namespace, shards)
+	dataResult, err := b.bootstrapData(start, namespace, shards, nil)
 	if err != nil {
 		return ProcessResult{}, err
 	}
@@ -168,28 +169,71 @@ func (b bootstrapProcess) Run(
 	}, nil
 }
 
+func (b bootstrapProcess) RunBatches(
+	start time.Time,
+	namespace namespace.Metadata,
+	batches [][]uint32,
+	fn ProcessBatchFn,
+) (result.IndexBootstrapResult, error) {
+	var shards []uint32
+	for _, batch := range batches {
+		shards = append(shards, batch...)
+	}
+
+	remaining := shards
+	for _, batch := range batches {
+		dataResult, err := b.bootstrapData(start, namespace, batch, remaining)
+		if err != nil {
+			return nil, err
+		}
+		remaining = remaining[len(batch):]
+
+		if err := fn(batch, dataResult); err != nil {
+			return nil, err
+		}
+	}
+
+	// NB: The index is bootstrapped once for the shards of all batches so
+	// that it is only ever queried once it covers all of them.
+	return b.bootstrapIndex(start, namespace, shards)
+}
+
+// bootstrapData bootstraps the data of shards, when the shards are a batch
+// of a batched bootstrap the remaining shards are the shards of the batch and
+// of all the batches that follow it.
 func (b bootstrapProcess) bootstrapData(
 	at time.Time,
 	namespace namespace.Metadata,
 	shards []uint32,
+	remaining []uint32,
 ) (result.DataBootstrapResult, error) {
 	bootstrapResult := result.NewDataBootstrapResult()
 	ropts := namespace.Options().RetentionOptions()
 	targetRanges := b.targetRangesForData(at, ropts)
 	for _, target := range targetRanges {
-		logFields := b.logFields(bootstrapDataRunType, namespace,
+		if len(remaining) > 0 {
+			target.RunOptions = target.RunOptions.SetShardBatch(ShardBatch{
+				Shards:    shards,
+				Remaining: b.newShardTimeRanges(target.Range, remaining),
+			})
+		}
+
+		logFields := b.logFields(DataRunType, namespace,
 			shards, target.Range)
 		b.logBootstrapRun(logFields)
 
 		begin := b.nowFn()
 		shardsTimeRanges := b.newShardTimeRanges(target.Range, shards)
+		b.progress.StartRun(namespace.ID(), DataRunType, shardsTimeRanges)
 		res, err := b.bootstrapper.BootstrapData(namespace,
 			shardsTimeRanges, target.RunOptions)
 
 		b.logBootstrapResult(logFields, err, begin)
 		if err != nil {
+			b.progress.CompleteRun(namespace.ID(), DataRunType, shardsTimeRanges)
 			return nil, err
 		}
+		b.progress.CompleteRun(namespace.ID(), DataRunType, res.Unfulfilled())
 
 		bootstrapResult = result.MergedDataBootstrapResult(bootstrapResult, res)
 	}
@@ -212,19 +256,22 @@ func (b bootstrapProcess) bootstrapIndex(
 
 	targetRanges := b.targetRangesForIndex(at, ropts, idxopts)
 	for _, target := range targetRanges {
-		logFields := b.logFields(bootstrapIndexRunType, namespace,
+		logFields := b.logFields(IndexRunType, namespace,
 			shards, target.Range)
 		b.logBootstrapRun(logFields)
 
 		begin := b.nowFn()
 		shardsTimeRanges := b.newShardTimeRanges(target.Range, shards)
+		b.progress.StartRun(namespace.ID(), IndexRunType, shardsTimeRanges)
 		res, err := b.bootstrapper.BootstrapIndex(namespace,
 			shardsTimeRanges, target.RunOptions)
 
 		b.logBootstrapResult(logFields, err, begin)
 		if err != nil {
+			b.progress.CompleteRun(namespace.ID(), IndexRunType, shardsTimeRanges)
 			return nil, err
 		}
+		b.progress.CompleteRun(namespace.ID(), IndexRunType, res.Unfulfilled())
 
 		bootstrapResult = result.MergedIndexBootstrapResult(bootstrapResult, res)
 	}
@@ -233,7 +280,7 @@ func (b bootstrapProcess) bootstrapIndex(
 }
 
 func (b bootstrapProcess) logFields(
-	runType bootstrapRunType,
+	runType RunType,
 	namespace namespace.Metadata,
 	shards []uint32,
 	window xtime.Range,
@@ -365,5 +412,6 @@ func (b bootstrapProcess) newRunOptions() RunOptions {
 		SetCacheSeriesMetadata(
 			b.processOpts.CacheSeriesMetadata(),
 		).
-		SetInitialTopologyState(b.initialTopologyState)
+		SetInitialTopologyState(b.initialTopologyState).
+		SetProgress(b.progress)
 }
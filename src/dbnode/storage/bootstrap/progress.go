@@ -0,0 +1,268 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package bootstrap
+
+import (
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3x/ident"
+)
+
+type progress struct {
+	sync.RWMutex
+	bootstrapping bool
+	start         time.Time
+	end           time.Time
+	err           error
+	namespaces    map[string]*namespaceProgress
+}
+
+type namespaceProgress struct {
+	runs              map[RunType]*runProgress
+	peerBytesStreamed int64
+}
+
+type runProgress struct {
+	inProgress  bool
+	requested   result.ShardTimeRanges
+	fulfilled   result.ShardTimeRanges
+	unfulfilled result.ShardTimeRanges
+	sources     []*sourceProgress
+}
+
+type sourceProgress struct {
+	source     string
+	inProgress bool
+	attempted  time.Duration
+	fulfilled  time.Duration
+	err        error
+}
+
+// NewProgress returns a new bootstrap progress tracker.
+func NewProgress() Progress {
+	return &progress{
+		namespaces: make(map[string]*namespaceProgress),
+	}
+}
+
+func (p *progress) Start(at time.Time) {
+	p.Lock()
+	p.bootstrapping = true
+	p.start = at
+	p.end = time.Time{}
+	p.err = nil
+	p.namespaces = make(map[string]*namespaceProgress)
+	p.Unlock()
+}
+
+func (p *progress) Complete(at time.Time, err error) {
+	p.Lock()
+	p.bootstrapping = false
+	p.end = at
+	p.err = err
+	p.Unlock()
+}
+
+func (p *progress) StartRun(
+	ns ident.ID,
+	runType RunType,
+	requested result.ShardTimeRanges,
+) {
+	p.Lock()
+	run := p.runWithLock(ns, runType)
+	run.inProgress = true
+	run.requested.AddRanges(requested)
+	p.Unlock()
+}
+
+func (p *progress) CompleteRun(
+	ns ident.ID,
+	runType RunType,
+	unfulfilled result.ShardTimeRanges,
+) {
+	p.Lock()
+	run := p.runWithLock(ns, runType)
+	run.inProgress = false
+	run.unfulfilled.AddRanges(unfulfilled)
+	p.Unlock()
+}
+
+func (p *progress) StartSource(
+	ns ident.ID,
+	runType RunType,
+	source string,
+	attempted result.ShardTimeRanges,
+) {
+	p.Lock()
+	src := p.sourceWithLock(ns, runType, source)
+	src.inProgress = true
+	src.attempted += attempted.Duration()
+	p.Unlock()
+}
+
+func (p *progress) CompleteSource(
+	ns ident.ID,
+	runType RunType,
+	source string,
+	fulfilled result.ShardTimeRanges,
+	err error,
+) {
+	p.Lock()
+	run := p.runWithLock(ns, runType)
+	run.fulfilled.AddRanges(fulfilled)
+	src := p.sourceWithLock(ns, runType, source)
+	src.inProgress = false
+	src.fulfilled += fulfilled.Duration()
+	if err != nil {
+		src.err = err
+	}
+	p.Unlock()
+}
+
+func (p *progress) AddPeerBytesStreamed(ns ident.ID, bytes int64) {
+	p.Lock()
+	p.namespaceWithLock(ns).peerBytesStreamed += bytes
+	p.Unlock()
+}
+
+func (p *progress) Snapshot() ProgressSnapshot {
+	p.RLock()
+	defer p.RUnlock()
+
+	snapshot := ProgressSnapshot{
+		Bootstrapping: p.bootstrapping,
+		StartTime:     p.start,
+		EndTime:       p.end,
+		Namespaces:    make(map[string]NamespaceProgress, len(p.namespaces)),
+	}
+	if p.err != nil {
+		snapshot.Error = p.err.Error()
+	}
+	for name, ns := range p.namespaces {
+		snapshot.Namespaces[name] = NamespaceProgress{
+			Data:              ns.runs[DataRunType].snapshot(),
+			Index:             ns.runs[IndexRunType].snapshot(),
+			PeerBytesStreamed: ns.peerBytesStreamed,
+		}
+	}
+	return snapshot
+}
+
+func (p *progress) namespaceWithLock(ns ident.ID) *namespaceProgress {
+	name := ns.String()
+	nsProgress, ok := p.namespaces[name]
+	if !ok {
+		nsProgress = &namespaceProgress{
+			runs: make(map[RunType]*runProgress),
+		}
+		p.namespaces[name] = nsProgress
+	}
+	return nsProgress
+}
+
+func (p *progress) runWithLock(ns ident.ID, runType RunType) *runProgress {
+	nsProgress := p.namespaceWithLock(ns)
+	run, ok := nsProgress.runs[runType]
+	if !ok {
+		run = &runProgress{
+			requested:   result.ShardTimeRanges{},
+			fulfilled:   result.ShardTimeRanges{},
+			unfulfilled: result.ShardTimeRanges{},
+		}
+		nsProgress.runs[runType] = run
+	}
+	return run
+}
+
+func (p *progress) sourceWithLock(
+	ns ident.ID,
+	runType RunType,
+	source string,
+) *sourceProgress {
+	run := p.runWithLock(ns, runType)
+	for _, src := range run.sources {
+		if src.source == source {
+			return src
+		}
+	}
+	src := &sourceProgress{source: source}
+	run.sources = append(run.sources, src)
+	return src
+}
+
+func (r *runProgress) snapshot() RunProgress {
+	if r == nil {
+		return RunProgress{}
+	}
+
+	remaining := r.requested.Copy()
+	remaining.Subtract(r.fulfilled)
+	remaining.Subtract(r.unfulfilled)
+
+	snapshot := RunProgress{
+		InProgress:  r.inProgress,
+		Requested:   r.requested.Duration(),
+		Fulfilled:   r.fulfilled.Duration(),
+		Remaining:   remaining.Duration(),
+		Unfulfilled: r.unfulfilled.Duration(),
+		Sources:     make([]SourceProgress, 0, len(r.sources)),
+	}
+	for _, src := range r.sources {
+		srcSnapshot := SourceProgress{
+			Source:     src.source,
+			InProgress: src.inProgress,
+			Attempted:  src.attempted,
+			Fulfilled:  src.fulfilled,
+		}
+		if src.err != nil {
+			srcSnapshot.Error = src.err.Error()
+		}
+		snapshot.Sources = append(snapshot.Sources, srcSnapshot)
+	}
+	return snapshot
+}
+
+type noOpProgress struct{}
+
+// NewNoOpProgress returns a bootstrap progress tracker that discards all progress.
+func NewNoOpProgress() Progress {
+	return noOpProgress{}
+}
+
+func (p noOpProgress) Start(at time.Time)               {}
+func (p noOpProgress) Complete(at time.Time, err error) {}
+func (p noOpProgress) StartRun(ns ident.ID, runType RunType, requested result.ShardTimeRanges) {
+}
+func (p noOpProgress) CompleteRun(ns ident.ID, runType RunType, unfulfilled result.ShardTimeRanges) {
+}
+func (p noOpProgress) StartSource(ns ident.ID, runType RunType, source string, attempted result.ShardTimeRanges) {
+}
+func (p noOpProgress) CompleteSource(
+	ns ident.ID,
+	runType RunType,
+	source string,
+	fulfilled result.ShardTimeRanges,
+	err error,
+) {
+}
+func (p noOpProgress) AddPeerBytesStreamed(ns ident.ID, bytes int64) {}
+func (p noOpProgress) Snapshot() ProgressSnapshot                    { return ProgressSnapshot{} }
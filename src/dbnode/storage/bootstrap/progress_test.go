@@ -0,0 +1,97 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package bootstrap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3x/ident"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressSnapshot(t *testing.T) {
+	var (
+		progress  = NewProgress()
+		ns        = ident.StringID("testns")
+		blockSize = 2 * time.Hour
+		start     = time.Now().Truncate(blockSize)
+		requested = result.NewShardTimeRanges(start, start.Add(2*blockSize), 0, 1)
+		fsRanges  = result.NewShardTimeRanges(start, start.Add(blockSize), 0, 1)
+		peerRange = result.NewShardTimeRanges(start.Add(blockSize), start.Add(2*blockSize), 0)
+	)
+
+	progress.Start(start)
+	progress.StartRun(ns, DataRunType, requested)
+	progress.StartSource(ns, DataRunType, "filesystem", requested)
+	progress.CompleteSource(ns, DataRunType, "filesystem", fsRanges, nil)
+	progress.StartSource(ns, DataRunType, "peers", peerRange)
+	progress.AddPeerBytesStreamed(ns, 1024)
+
+	snapshot := progress.Snapshot()
+	require.True(t, snapshot.Bootstrapping)
+	nsProgress, ok := snapshot.Namespaces["testns"]
+	require.True(t, ok)
+	assert.Equal(t, int64(1024), nsProgress.PeerBytesStreamed)
+
+	data := nsProgress.Data
+	assert.True(t, data.InProgress)
+	assert.Equal(t, 4*blockSize, data.Requested)
+	assert.Equal(t, 2*blockSize, data.Fulfilled)
+	assert.Equal(t, 2*blockSize, data.Remaining)
+	require.Equal(t, 2, len(data.Sources))
+	assert.Equal(t, SourceProgress{
+		Source:    "filesystem",
+		Attempted: 4 * blockSize,
+		Fulfilled: 2 * blockSize,
+	}, data.Sources[0])
+	assert.Equal(t, SourceProgress{
+		Source:     "peers",
+		InProgress: true,
+		Attempted:  blockSize,
+	}, data.Sources[1])
+	assert.Equal(t, RunProgress{}, nsProgress.Index)
+
+	progress.CompleteSource(ns, DataRunType, "peers", peerRange, errors.New("an error"))
+	unfulfilled := result.NewShardTimeRanges(start.Add(blockSize), start.Add(2*blockSize), 1)
+	progress.CompleteRun(ns, DataRunType, unfulfilled)
+	progress.Complete(start.Add(time.Minute), nil)
+
+	snapshot = progress.Snapshot()
+	assert.False(t, snapshot.Bootstrapping)
+	assert.Equal(t, start.Add(time.Minute), snapshot.EndTime)
+
+	data = snapshot.Namespaces["testns"].Data
+	assert.False(t, data.InProgress)
+	assert.Equal(t, 3*blockSize, data.Fulfilled)
+	assert.Equal(t, time.Duration(0), data.Remaining)
+	assert.Equal(t, blockSize, data.Unfulfilled)
+	assert.Equal(t, "an error", data.Sources[1].Error)
+
+	// Starting a new bootstrap resets the progress.
+	progress.Start(start.Add(time.Hour))
+	snapshot = progress.Snapshot()
+	assert.True(t, snapshot.Bootstrapping)
+	assert.Equal(t, 0, len(snapshot.Namespaces))
+}
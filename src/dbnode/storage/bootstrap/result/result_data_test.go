@@ -488,3 +488,24 @@ func TestShardTimeRangesSummaryString(t *testing.T) {
 
 	assert.Equal(t, expected, str.SummaryString())
 }
+
+func TestShardTimeRangesDuration(t *testing.T) {
+	start := time.Unix(1472824800, 0)
+
+	str := ShardTimeRanges{
+		0: xtime.NewRanges(xtime.Range{
+			Start: start,
+			End:   start.Add(testBlockSize),
+		}).AddRange(xtime.Range{
+			Start: start.Add(2 * testBlockSize),
+			End:   start.Add(4 * testBlockSize),
+		}),
+		1: xtime.NewRanges(xtime.Range{
+			Start: start,
+			End:   start.Add(2 * testBlockSize),
+		}),
+	}
+
+	assert.Equal(t, 5*testBlockSize, str.Duration())
+	assert.Equal(t, time.Duration(0), ShardTimeRanges{}.Duration())
+}
@@ -190,7 +190,16 @@ func (r ShardTimeRanges) String() string {
 	return r.summarize(xtime.Ranges.String)
 }
 
-func rangesDuration(ranges xtime.Ranges) string {
+// Duration returns the total duration of the time ranges summed across all shards.
+func (r ShardTimeRanges) Duration() time.Duration {
+	var duration time.Duration
+	for _, ranges := range r {
+		duration += totalDuration(ranges)
+	}
+	return duration
+}
+
+func totalDuration(ranges xtime.Ranges) time.Duration {
 	var (
 		duration time.Duration
 		it       = ranges.Iter()
@@ -199,7 +208,11 @@ func rangesDuration(ranges xtime.Ranges) string {
 		curr := it.Value()
 		duration += curr.End.Sub(curr.Start)
 	}
-	return duration.String()
+	return duration
+}
+
+func rangesDuration(ranges xtime.Ranges) string {
+	return totalDuration(ranges).String()
 }
 
 // SummaryString returns a summary description of the time ranges
@@ -34,6 +34,8 @@ type runOptions struct {
 	persistConfig        PersistConfig
 	cacheSeriesMetadata  bool
 	initialTopologyState *topology.StateSnapshot
+	progress             Progress
+	shardBatch           ShardBatch
 }
 
 // NewRunOptions creates new bootstrap run options
@@ -42,6 +44,7 @@ func NewRunOptions() RunOptions {
 		persistConfig:        defaultPersistConfig,
 		cacheSeriesMetadata:  defaultCacheSeriesMetadata,
 		initialTopologyState: nil,
+		progress:             NewNoOpProgress(),
 	}
 }
 
@@ -74,3 +77,23 @@ func (o *runOptions) SetInitialTopologyState(value *topology.StateSnapshot) RunO
 func (o *runOptions) InitialTopologyState() *topology.StateSnapshot {
 	return o.initialTopologyState
 }
+
+func (o *runOptions) SetProgress(value Progress) RunOptions {
+	opts := *o
+	opts.progress = value
+	return &opts
+}
+
+func (o *runOptions) Progress() Progress {
+	return o.progress
+}
+
+func (o *runOptions) SetShardBatch(value ShardBatch) RunOptions {
+	opts := *o
+	opts.shardBatch = value
+	return &opts
+}
+
+func (o *runOptions) ShardBatch() ShardBatch {
+	return o.shardBatch
+}
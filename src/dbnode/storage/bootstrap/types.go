@@ -27,6 +27,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3x/ident"
 	xtime "github.com/m3db/m3x/time"
 )
 
@@ -43,6 +44,10 @@ type ProcessProvider interface {
 
 	// Provide constructs a bootstrap process.
 	Provide() (Process, error)
+
+	// Progress returns the progress tracker for bootstrap processes
+	// constructed by the provider.
+	Progress() Progress
 }
 
 // Process represents the bootstrap process. Note that a bootstrap process can and will
@@ -51,14 +56,109 @@ type ProcessProvider interface {
 type Process interface {
 	// Run runs the bootstrap process, returning the bootstrap result and any error encountered.
 	Run(start time.Time, ns namespace.Metadata, shards []uint32) (ProcessResult, error)
+
+	// RunBatches runs the data bootstrap for each batch of shards in turn,
+	// calling fn with the data result of each batch as soon as it completes,
+	// then runs the index bootstrap once for the shards of all batches and
+	// returns its result. Sources may read data shared by all shards, such as
+	// the commit log, once for all the batches.
+	RunBatches(
+		start time.Time,
+		ns namespace.Metadata,
+		batches [][]uint32,
+		fn ProcessBatchFn,
+	) (result.IndexBootstrapResult, error)
 }
 
+// ProcessBatchFn is called with the data result of a batch of shards.
+type ProcessBatchFn func(shards []uint32, res result.DataBootstrapResult) error
+
 // ProcessResult is the result of a bootstrap process.
 type ProcessResult struct {
 	DataResult  result.DataBootstrapResult
 	IndexResult result.IndexBootstrapResult
 }
 
+// RunType describes whether a bootstrap run is for data or the index.
+type RunType string
+
+const (
+	// DataRunType is a bootstrap run for data.
+	DataRunType = RunType("bootstrap-data")
+	// IndexRunType is a bootstrap run for the index.
+	IndexRunType = RunType("bootstrap-index")
+)
+
+// Progress tracks the progress of bootstrap runs so that it can be
+// inspected while a node is bootstrapping.
+type Progress interface {
+	// Start resets the progress and marks a bootstrap as started.
+	Start(at time.Time)
+
+	// Complete marks the current bootstrap as completed.
+	Complete(at time.Time, err error)
+
+	// StartRun records the shard time ranges requested by a run for a namespace.
+	StartRun(ns ident.ID, runType RunType, requested result.ShardTimeRanges)
+
+	// CompleteRun records the shard time ranges a run left unfulfilled for a namespace.
+	CompleteRun(ns ident.ID, runType RunType, unfulfilled result.ShardTimeRanges)
+
+	// StartSource records the shard time ranges a source is attempting.
+	StartSource(ns ident.ID, runType RunType, source string, attempted result.ShardTimeRanges)
+
+	// CompleteSource records the shard time ranges a source fulfilled.
+	CompleteSource(
+		ns ident.ID,
+		runType RunType,
+		source string,
+		fulfilled result.ShardTimeRanges,
+		err error,
+	)
+
+	// AddPeerBytesStreamed records bytes streamed from peers for a namespace.
+	AddPeerBytesStreamed(ns ident.ID, bytes int64)
+
+	// Snapshot returns a point in time copy of the progress.
+	Snapshot() ProgressSnapshot
+}
+
+// ProgressSnapshot is a point in time copy of bootstrap progress.
+type ProgressSnapshot struct {
+	Bootstrapping bool
+	StartTime     time.Time
+	EndTime       time.Time
+	Error         string
+	Namespaces    map[string]NamespaceProgress
+}
+
+// NamespaceProgress is the bootstrap progress of a namespace.
+type NamespaceProgress struct {
+	Data              RunProgress
+	Index             RunProgress
+	PeerBytesStreamed int64
+}
+
+// RunProgress is the progress of data or index bootstrap runs, durations
+// are shard time, that is the sum of time ranges across all shards.
+type RunProgress struct {
+	InProgress  bool
+	Requested   time.Duration
+	Fulfilled   time.Duration
+	Remaining   time.Duration
+	Unfulfilled time.Duration
+	Sources     []SourceProgress
+}
+
+// SourceProgress is the progress of a single bootstrapper source.
+type SourceProgress struct {
+	Source     string
+	InProgress bool
+	Attempted  time.Duration
+	Fulfilled  time.Duration
+	Error      string
+}
+
 // TargetRange is a bootstrap target range.
 type TargetRange struct {
 	// Range is the time range to bootstrap for.
@@ -127,6 +227,37 @@ type RunOptions interface {
 	// InitialTopologyState returns the initial topology as it was measured
 	// before the bootstrap process began.
 	InitialTopologyState() *topology.StateSnapshot
+
+	// SetProgress sets the progress tracker to report the run to.
+	SetProgress(value Progress) RunOptions
+
+	// Progress returns the progress tracker to report the run to.
+	Progress() Progress
+
+	// SetShardBatch sets the batch of shards the run is for when the shards
+	// of a namespace are bootstrapped in batches.
+	SetShardBatch(value ShardBatch) RunOptions
+
+	// ShardBatch returns the batch of shards the run is for when the shards
+	// of a namespace are bootstrapped in batches.
+	ShardBatch() ShardBatch
+}
+
+// ShardBatch describes the batch of shards a run is for when the shards of a
+// namespace are bootstrapped in batches, the zero value is used for runs that
+// bootstrap all the shards at once.
+type ShardBatch struct {
+	// Shards are the shards of the batch.
+	Shards []uint32
+
+	// Remaining are the target shard time ranges of this batch and of all the
+	// batches that follow it.
+	Remaining result.ShardTimeRanges
+}
+
+// IsBatched returns whether the run is for one of several batches of shards.
+func (b ShardBatch) IsBatched() bool {
+	return len(b.Remaining) > 0
 }
 
 // BootstrapperProvider constructs a bootstrapper.
@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/sharding"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
@@ -70,6 +71,9 @@ var (
 	// errWriterDoesNotImplementWriteBatch is raised when the provided ts.BatchWriter does not implement
 	// ts.WriteBatch.
 	errWriterDoesNotImplementWriteBatch = errors.New("provided writer does not implement ts.WriteBatch")
+
+	// errNamespaceFlushDisabled raised when trying to flush a namespace that has flushing disabled.
+	errNamespaceFlushDisabled = xerrors.NewInvalidParamsError(errors.New("namespace has flushing disabled"))
 )
 
 type databaseState int
@@ -881,6 +885,48 @@ func (d *db) BootstrapState() DatabaseBootstrapState {
 	}
 }
 
+func (d *db) BootstrapProgress() bootstrap.ProgressSnapshot {
+	processProvider := d.opts.BootstrapProcessProvider()
+	if processProvider == nil {
+		return bootstrap.ProgressSnapshot{}
+	}
+	return processProvider.Progress().Snapshot()
+}
+
+func (d *db) FileOpsState() (DatabaseFileOpsState, error) {
+	namespaces, err := d.GetOwnedNamespaces()
+	if err != nil {
+		return DatabaseFileOpsState{}, err
+	}
+
+	var (
+		now             = d.nowFn()
+		nsFileOpsStates = make(NamespaceFileOpsStates, len(namespaces))
+	)
+	for _, ns := range namespaces {
+		nsFileOpsStates[ns.ID().String()] = namespaceFileOpsState(ns, now)
+	}
+
+	return DatabaseFileOpsState{
+		NamespaceFileOpsStates: nsFileOpsStates,
+	}, nil
+}
+
+func (d *db) FlushNamespace(namespace ident.ID) error {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return err
+	}
+	if !n.Options().FlushEnabled() {
+		return errNamespaceFlushDisabled
+	}
+	return d.mediator.FlushNamespace(n)
+}
+
+func (d *db) Snapshot() error {
+	return d.mediator.Snapshot()
+}
+
 func (d *db) namespaceFor(namespace ident.ID) (databaseNamespace, error) {
 	d.RLock()
 	n, exists := d.namespaces.Get(namespace)
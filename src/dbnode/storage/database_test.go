@@ -312,6 +312,24 @@ func TestDatabaseReadEncodedNamespaceOwned(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestDatabaseFlushNamespaceFlushDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d, mapCh, _ := newTestDatabase(t, ctrl, Bootstrapped)
+	defer func() {
+		close(mapCh)
+	}()
+
+	mockNamespace := dbAddNewMockNamespace(ctrl, d, "testns1")
+	mockNamespace.EXPECT().Options().
+		Return(namespace.NewOptions().SetFlushEnabled(false)).AnyTimes()
+
+	err := d.FlushNamespace(ident.StringID("testns1"))
+	require.Equal(t, errNamespaceFlushDisabled, err)
+	require.True(t, xerrors.IsInvalidParams(err))
+}
+
 func TestDatabaseFetchBlocksNamespaceNotOwned(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
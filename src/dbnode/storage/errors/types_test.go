@@ -31,3 +31,11 @@ func TestUnknownNamespaceError(t *testing.T) {
 	require.Equal(t, "unknown namespace: ns", err.Error())
 	require.True(t, IsUnknownNamespaceError(err))
 }
+
+func TestQueryLimitExceededError(t *testing.T) {
+	err := NewQueryLimitExceededError("series")
+	require.Equal(t, "query limit exceeded: series", err.Error())
+	require.True(t, IsQueryLimitExceededError(err))
+	require.False(t, IsUnknownNamespaceError(err))
+	require.False(t, IsQueryLimitExceededError(NewUnknownNamespaceError("ns")))
+}
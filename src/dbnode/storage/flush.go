@@ -70,6 +70,7 @@ type flushManager struct {
 	maxBlocksSnapshottedByNamespace tally.Gauge
 
 	lastSuccessfulSnapshotStartTime time.Time
+	lastCommitlogRotationTime       time.Time
 }
 
 func newFlushManager(
@@ -92,14 +93,9 @@ func (m *flushManager) Flush(
 	dbBootstrapStateAtTickStart DatabaseBootstrapState,
 ) error {
 	// ensure only a single flush is happening at a time
-	m.Lock()
-	if m.state != flushManagerIdle {
-		m.Unlock()
-		return errFlushOperationsInProgress
+	if err := m.beginOperation(); err != nil {
+		return err
 	}
-	m.state = flushManagerNotIdle
-	m.Unlock()
-
 	defer m.setState(flushManagerIdle)
 
 	// create flush-er
@@ -174,13 +170,59 @@ func (m *flushManager) Flush(
 	return multiErr.FinalError()
 }
 
+func (m *flushManager) FlushNamespace(
+	ns databaseNamespace,
+	tickStart time.Time,
+	shardBootstrapStatesAtTickStart ShardBootstrapStates,
+) error {
+	if err := m.beginOperation(); err != nil {
+		return err
+	}
+	defer m.setState(flushManagerIdle)
+
+	flushPersist, err := m.pm.StartFlushPersist()
+	if err != nil {
+		return err
+	}
+
+	m.setState(flushManagerFlushInProgress)
+	multiErr := xerrors.NewMultiError()
+	flushTimes := m.namespaceFlushTimes(ns, tickStart)
+	err = m.flushNamespaceWithTimes(
+		ns, shardBootstrapStatesAtTickStart, flushTimes, flushPersist)
+	if err != nil {
+		multiErr = multiErr.Add(err)
+	}
+	if err := flushPersist.DoneFlush(); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+	return multiErr.FinalError()
+}
+
+// Snapshot always snapshots every owned namespace, the snapshot metadata
+// file written at the end of a snapshot references the rotated commitlog
+// and cleanup relies on it to delete every commitlog before it, so a
+// snapshot of only a subset of namespaces could lead to data loss.
+func (m *flushManager) Snapshot(tickStart time.Time) error {
+	if err := m.beginOperation(); err != nil {
+		return err
+	}
+	defer m.setState(flushManagerIdle)
+
+	namespaces, err := m.database.GetOwnedNamespaces()
+	if err != nil {
+		return err
+	}
+	return m.rotateCommitlogAndSnapshot(namespaces, tickStart)
+}
+
 func (m *flushManager) rotateCommitlogAndSnapshot(
 	namespaces []databaseNamespace,
 	tickStart time.Time,
 ) error {
-	rotatedCommitlogID, err := m.commitlog.RotateLogs()
+	commitlogPosition, err := m.snapshotCommitlogPosition(tickStart)
 	if err != nil {
-		return fmt.Errorf("error rotating commitlog in mediator tick: %v", err)
+		return err
 	}
 
 	snapshotID := uuid.NewUUID()
@@ -214,7 +256,7 @@ func (m *flushManager) rotateCommitlogAndSnapshot(
 	}
 	m.maxBlocksSnapshottedByNamespace.Update(float64(maxBlocksSnapshottedByNamespace))
 
-	err = snapshotPersist.DoneSnapshot(snapshotID, rotatedCommitlogID)
+	err = snapshotPersist.DoneSnapshot(snapshotID, commitlogPosition)
 	multiErr = multiErr.Add(err)
 
 	finalErr := multiErr.FinalError()
@@ -224,6 +266,35 @@ func (m *flushManager) rotateCommitlogAndSnapshot(
 	return finalErr
 }
 
+// snapshotCommitlogPosition returns the commitlog position that a snapshot
+// starting now covers, every entry written before the position is captured
+// by the snapshot. The commitlog is only rotated once per commitlog block
+// so that cleanup can remove files, between rotations the position within
+// the active file is used so that bootstrap only needs to replay the
+// entries written after it.
+func (m *flushManager) snapshotCommitlogPosition(
+	tickStart time.Time,
+) (persist.CommitLogFile, error) {
+	blockSize := m.opts.CommitLogOptions().BlockSize()
+	if m.lastCommitlogRotationTime.IsZero() ||
+		tickStart.Sub(m.lastCommitlogRotationTime) >= blockSize {
+		file, err := m.commitlog.RotateLogs()
+		if err != nil {
+			return persist.CommitLogFile{}, fmt.Errorf(
+				"error rotating commitlog in mediator tick: %v", err)
+		}
+		m.lastCommitlogRotationTime = tickStart
+		return file, nil
+	}
+
+	file, err := m.commitlog.ActivePosition()
+	if err != nil {
+		return persist.CommitLogFile{}, fmt.Errorf(
+			"error determining commitlog position in mediator tick: %v", err)
+	}
+	return file, nil
+}
+
 func (m *flushManager) Report() {
 	m.RLock()
 	state := m.state
@@ -248,6 +319,16 @@ func (m *flushManager) Report() {
 	}
 }
 
+func (m *flushManager) beginOperation() error {
+	m.Lock()
+	defer m.Unlock()
+	if m.state != flushManagerIdle {
+		return errFlushOperationsInProgress
+	}
+	m.state = flushManagerNotIdle
+	return nil
+}
+
 func (m *flushManager) setState(state flushManagerState) {
 	m.Lock()
 	m.state = state
@@ -314,6 +395,43 @@ func (m *flushManager) flushNamespaceWithTimes(
 	return multiErr.FinalError()
 }
 
+// namespaceFileOpsState returns the snapshot state of each shard of the
+// namespace along with the flush state of every block that is yet to be
+// successfully flushed, from the earliest flushable block up until the
+// latest snapshottable block.
+func namespaceFileOpsState(ns databaseNamespace, curr time.Time) ShardFileOpsStates {
+	var (
+		rOpts     = ns.Options().RetentionOptions()
+		blockSize = rOpts.BlockSize()
+		earliest  = retention.FlushTimeStart(rOpts, curr)
+		latest    = curr.Add(rOpts.BufferFuture()).Truncate(blockSize)
+		times     = timesInRange(earliest, latest, blockSize)
+		shards    = ns.GetOwnedShards()
+		states    = make(ShardFileOpsStates, len(shards))
+	)
+	for _, shard := range shards {
+		isSnapshotting, lastSuccessfulSnapshot := shard.SnapshotState()
+		state := ShardFileOpsState{
+			IsSnapshotting:         isSnapshotting,
+			LastSuccessfulSnapshot: lastSuccessfulSnapshot,
+		}
+		// NB: iterate in chronological order, times are in reverse order.
+		for i := len(times) - 1; i >= 0; i-- {
+			flushState := shard.FlushState(times[i])
+			if flushState.Status == fileOpSuccess {
+				continue
+			}
+			state.UnflushedBlocks = append(state.UnflushedBlocks, BlockFlushState{
+				BlockStart:  times[i],
+				Status:      flushState.Status.String(),
+				NumFailures: flushState.NumFailures,
+			})
+		}
+		states[shard.ID()] = state
+	}
+	return states
+}
+
 func (m *flushManager) LastSuccessfulSnapshotStartTime() (time.Time, bool) {
 	return m.lastSuccessfulSnapshotStartTime, !m.lastSuccessfulSnapshotStartTime.IsZero()
 }
@@ -64,6 +64,7 @@ func newMultipleFlushManagerNeedsFlush(t *testing.T, ctrl *gomock.Controller) (
 
 	cl := commitlog.NewMockCommitLog(ctrl)
 	cl.EXPECT().RotateLogs().Return(testCommitlogFile, nil).AnyTimes()
+	cl.EXPECT().ActivePosition().Return(testCommitlogFile, nil).AnyTimes()
 
 	fm := newFlushManager(db, cl, tally.NoopScope).(*flushManager)
 
@@ -113,6 +114,7 @@ func TestFlushManagerFlushAlreadyInProgress(t *testing.T) {
 
 	cl := commitlog.NewMockCommitLog(ctrl)
 	cl.EXPECT().RotateLogs().Return(testCommitlogFile, nil).AnyTimes()
+	cl.EXPECT().ActivePosition().Return(testCommitlogFile, nil).AnyTimes()
 
 	fm := newFlushManager(db, cl, tally.NoopScope).(*flushManager)
 	fm.pm = mockPersistManager
@@ -178,6 +180,7 @@ func TestFlushManagerFlushDoneFlushError(t *testing.T) {
 
 	cl := commitlog.NewMockCommitLog(ctrl)
 	cl.EXPECT().RotateLogs().Return(testCommitlogFile, nil).AnyTimes()
+	cl.EXPECT().ActivePosition().Return(testCommitlogFile, nil).AnyTimes()
 
 	fm := newFlushManager(db, cl, tally.NoopScope).(*flushManager)
 	fm.pm = mockPersistManager
@@ -216,6 +219,7 @@ func TestFlushManagerFlushDoneSnapshotError(t *testing.T) {
 
 	cl := commitlog.NewMockCommitLog(ctrl)
 	cl.EXPECT().RotateLogs().Return(testCommitlogFile, nil).AnyTimes()
+	cl.EXPECT().ActivePosition().Return(testCommitlogFile, nil).AnyTimes()
 
 	fm := newFlushManager(db, cl, tally.NoopScope).(*flushManager)
 	fm.pm = mockPersistManager
@@ -252,6 +256,7 @@ func TestFlushManagerFlushDoneIndexError(t *testing.T) {
 
 	cl := commitlog.NewMockCommitLog(ctrl)
 	cl.EXPECT().RotateLogs().Return(testCommitlogFile, nil).AnyTimes()
+	cl.EXPECT().ActivePosition().Return(testCommitlogFile, nil).AnyTimes()
 
 	fm := newFlushManager(db, cl, tally.NoopScope).(*flushManager)
 	fm.pm = mockPersistManager
@@ -295,6 +300,7 @@ func TestFlushManagerSkipNamespaceIndexingDisabled(t *testing.T) {
 
 	cl := commitlog.NewMockCommitLog(ctrl)
 	cl.EXPECT().RotateLogs().Return(testCommitlogFile, nil).AnyTimes()
+	cl.EXPECT().ActivePosition().Return(testCommitlogFile, nil).AnyTimes()
 
 	fm := newFlushManager(db, cl, tally.NoopScope).(*flushManager)
 	fm.pm = mockPersistManager
@@ -344,6 +350,7 @@ func TestFlushManagerNamespaceIndexingEnabled(t *testing.T) {
 
 	cl := commitlog.NewMockCommitLog(ctrl)
 	cl.EXPECT().RotateLogs().Return(testCommitlogFile, nil).AnyTimes()
+	cl.EXPECT().ActivePosition().Return(testCommitlogFile, nil).AnyTimes()
 
 	fm := newFlushManager(db, cl, tally.NoopScope).(*flushManager)
 	fm.pm = mockPersistManager
@@ -357,6 +364,47 @@ func TestFlushManagerNamespaceIndexingEnabled(t *testing.T) {
 	require.NoError(t, fm.Flush(now, bootstrapStates))
 }
 
+func TestFlushManagerSnapshotCommitlogPosition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		rotated  = persist.CommitLogFile{FilePath: "rotated", Index: 1}
+		position = persist.CommitLogFile{FilePath: "rotated", Index: 1, Offset: 10}
+		rotated2 = persist.CommitLogFile{FilePath: "rotated2", Index: 2}
+	)
+
+	cl := commitlog.NewMockCommitLog(ctrl)
+	gomock.InOrder(
+		cl.EXPECT().RotateLogs().Return(rotated, nil),
+		cl.EXPECT().ActivePosition().Return(position, nil),
+		cl.EXPECT().RotateLogs().Return(rotated2, nil),
+	)
+
+	testOpts := testDatabaseOptions()
+	db := newMockdatabase(ctrl)
+	db.EXPECT().Options().Return(testOpts).AnyTimes()
+
+	fm := newFlushManager(db, cl, tally.NoopScope).(*flushManager)
+	blockSize := testOpts.CommitLogOptions().BlockSize()
+
+	// First snapshot always rotates.
+	now := time.Unix(0, 0)
+	file, err := fm.snapshotCommitlogPosition(now)
+	require.NoError(t, err)
+	require.Equal(t, rotated, file)
+
+	// Snapshots within the commitlog block use the active position.
+	file, err = fm.snapshotCommitlogPosition(now.Add(blockSize / 2))
+	require.NoError(t, err)
+	require.Equal(t, position, file)
+
+	// Snapshots once the commitlog block has passed rotate again.
+	file, err = fm.snapshotCommitlogPosition(now.Add(blockSize))
+	require.NoError(t, err)
+	require.Equal(t, rotated2, file)
+}
+
 func TestFlushManagerFlushTimeStart(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
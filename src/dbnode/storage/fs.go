@@ -21,6 +21,7 @@
 package storage
 
 import (
+	"errors"
 	"sync"
 	"time"
 
@@ -28,6 +29,12 @@ import (
 	xlog "github.com/m3db/m3x/log"
 )
 
+var (
+	errFileOpsDisabled        = errors.New("file operations are disabled")
+	errFileOpsInProgress      = errors.New("file operations already in progress")
+	errFileOpsNotBootstrapped = errors.New("database is not bootstrapped")
+)
+
 type fileOpStatus int
 
 const (
@@ -37,6 +44,20 @@ const (
 	fileOpFailed
 )
 
+func (s fileOpStatus) String() string {
+	switch s {
+	case fileOpNotStarted:
+		return "not_started"
+	case fileOpInProgress:
+		return "in_progress"
+	case fileOpSuccess:
+		return "success"
+	case fileOpFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
 type fileOpState struct {
 	Status      fileOpStatus
 	NumFailures int
@@ -147,6 +168,52 @@ func (m *fileSystemManager) Run(
 	return true
 }
 
+func (m *fileSystemManager) FlushNamespace(
+	ns databaseNamespace,
+	tickStart time.Time,
+	shardBootstrapStatesAtTickStart ShardBootstrapStates,
+) error {
+	return m.runExclusive(func() error {
+		return m.databaseFlushManager.FlushNamespace(
+			ns, tickStart, shardBootstrapStatesAtTickStart)
+	})
+}
+
+func (m *fileSystemManager) Snapshot(tickStart time.Time) error {
+	return m.runExclusive(func() error {
+		return m.databaseFlushManager.Snapshot(tickStart)
+	})
+}
+
+// runExclusive runs a file operation triggered outside of the regular
+// mediator tick, marking the manager as busy for the duration so that
+// it never overlaps with a cleanup or flush started by Run.
+func (m *fileSystemManager) runExclusive(fn func() error) error {
+	m.Lock()
+	if !m.enabled {
+		m.Unlock()
+		return errFileOpsDisabled
+	}
+	if m.status == fileOpInProgress {
+		m.Unlock()
+		return errFileOpsInProgress
+	}
+	if !m.database.IsBootstrapped() {
+		m.Unlock()
+		return errFileOpsNotBootstrapped
+	}
+	m.status = fileOpInProgress
+	m.Unlock()
+
+	defer func() {
+		m.Lock()
+		m.status = fileOpNotStarted
+		m.Unlock()
+	}()
+
+	return fn()
+}
+
 func (m *fileSystemManager) Report() {
 	m.databaseCleanupManager.Report()
 	m.databaseFlushManager.Report()
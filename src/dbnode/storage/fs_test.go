@@ -92,3 +92,40 @@ func TestFileSystemManagerRun(t *testing.T) {
 	mgr.Run(ts, DatabaseBootstrapState{}, syncRun, noForce)
 	require.Equal(t, fileOpNotStarted, mgr.status)
 }
+
+func TestFileSystemManagerFlushNamespaceAndSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	database := newMockdatabase(ctrl)
+	database.EXPECT().IsBootstrapped().Return(true).AnyTimes()
+
+	fm := NewMockdatabaseFlushManager(ctrl)
+	fsm := newFileSystemManager(database, nil, testDatabaseOptions())
+	mgr := fsm.(*fileSystemManager)
+	mgr.databaseFlushManager = fm
+
+	var (
+		ts                   = time.Now()
+		ns                   = NewMockdatabaseNamespace(ctrl)
+		shardBootstrapStates = ShardBootstrapStates{0: Bootstrapped}
+	)
+	gomock.InOrder(
+		fm.EXPECT().FlushNamespace(ns, ts, shardBootstrapStates).Do(
+			func(_ databaseNamespace, _ time.Time, _ ShardBootstrapStates) {
+				require.Equal(t, fileOpInProgress, mgr.status)
+			}).Return(nil),
+		fm.EXPECT().Snapshot(ts).Return(errors.New("foo")),
+	)
+
+	require.NoError(t, mgr.FlushNamespace(ns, ts, shardBootstrapStates))
+	require.Equal(t, fileOpNotStarted, mgr.status)
+	require.Error(t, mgr.Snapshot(ts))
+	require.Equal(t, fileOpNotStarted, mgr.status)
+
+	mgr.status = fileOpInProgress
+	require.Equal(t, errFileOpsInProgress, mgr.Snapshot(ts))
+
+	mgr.status = fileOpNotStarted
+	mgr.Disable()
+	require.Equal(t, errFileOpsDisabled, mgr.FlushNamespace(ns, ts, shardBootstrapStates))
+}
@@ -863,10 +863,14 @@ func (i *nsIndex) Query(
 	// Get results and set the namespace ID and size limit.
 	results := i.resultsPool.Get()
 	results.Reset(i.nsMetadata.ID(), index.QueryResultsOptions{
-		SizeLimit: opts.Limit,
+		SizeLimit:   opts.Limit,
+		Cost:        opts.Cost,
+		OrderedByID: opts.OrderedByID,
+		AfterID:     opts.AfterID,
 	})
 	exhaustive, err := i.query(ctx, query, results, opts)
 	if err != nil {
+		results.Finalize()
 		return index.QueryResult{}, err
 	}
 	return index.QueryResult{
@@ -993,7 +997,7 @@ func (i *nsIndex) query(
 		// is no value in kicking off more parallel queries, so we break out of
 		// the loop.
 		size := results.Size()
-		alreadyExceededLimit := opts.LimitExceeded(size)
+		alreadyExceededLimit := opts.StopAtLimit(size)
 		if alreadyExceededLimit {
 			state.Lock()
 			state.exhaustive = false
@@ -1002,6 +1006,15 @@ func (i *nsIndex) query(
 			break
 		}
 
+		// Similarly there is no value in querying more blocks once a block
+		// query has failed, such as when the query exceeded its cost limits.
+		state.Lock()
+		failed := state.multiErr.FinalError() != nil
+		state.Unlock()
+		if failed {
+			break
+		}
+
 		if applyTimeout := timeout > 0; !applyTimeout {
 			// No timeout, just wait blockingly for a worker.
 			wg.Add(1)
@@ -816,7 +816,7 @@ func (b *block) Query(
 	}()
 
 	for iter.Next() {
-		if opts.LimitExceeded(size) {
+		if opts.StopAtLimit(size) {
 			break
 		}
 
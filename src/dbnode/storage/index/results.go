@@ -21,6 +21,8 @@
 package index
 
 import (
+	"bytes"
+	"container/heap"
 	"errors"
 	"sync"
 
@@ -40,6 +42,7 @@ type results struct {
 	opts QueryResultsOptions
 
 	resultsMap *ResultsMap
+	orderedIDs idHeap
 
 	idPool    ident.Pool
 	bytesPool pool.CheckedBytesPool
@@ -88,6 +91,11 @@ func (r *results) Reset(nsID ident.ID, opts QueryResultsOptions) {
 	// Reset all keys in the map next, this will finalize the keys.
 	r.resultsMap.Reset()
 
+	for i := range r.orderedIDs {
+		r.orderedIDs[i] = nil
+	}
+	r.orderedIDs = r.orderedIDs[:0]
+
 	// NB: could do keys+value in one step but I'm trying to avoid
 	// using an internal method of a code-gen'd type.
 
@@ -98,8 +106,14 @@ func (r *results) Reset(nsID ident.ID, opts QueryResultsOptions) {
 // the first document added with an ID is returned.
 func (r *results) AddDocuments(batch []doc.Document) (int, error) {
 	r.Lock()
+	prevSize := r.resultsMap.Len()
 	err := r.addDocumentsBatchWithLock(batch)
 	size := r.resultsMap.Len()
+	if err == nil && r.opts.Cost != nil && size > prevSize {
+		// Account for the series as they are added so that an overly broad
+		// query stops as soon as it exceeds its limits.
+		err = r.opts.Cost.AddSeries(size - prevSize)
+	}
 	r.Unlock()
 	return size, err
 }
@@ -110,7 +124,7 @@ func (r *results) addDocumentsBatchWithLock(batch []doc.Document) error {
 		if err != nil {
 			return err
 		}
-		if r.opts.SizeLimit > 0 && size >= r.opts.SizeLimit {
+		if r.opts.SizeLimit > 0 && size >= r.opts.SizeLimit && !r.opts.OrderedByID {
 			// Early return if limit enforced and we hit our limit.
 			break
 		}
@@ -125,6 +139,10 @@ func (r *results) addDocumentWithLock(
 		return false, r.resultsMap.Len(), errUnableToAddResultMissingID
 	}
 
+	if r.opts.AfterID != nil && bytes.Compare(d.ID, r.opts.AfterID) <= 0 {
+		return false, r.resultsMap.Len(), nil
+	}
+
 	// NB: can cast the []byte -> ident.ID to avoid an alloc
 	// before we're sure we need it.
 	tsID := ident.BytesID(d.ID)
@@ -134,6 +152,13 @@ func (r *results) addDocumentWithLock(
 		return false, r.resultsMap.Len(), nil
 	}
 
+	ordered := r.opts.OrderedByID && r.opts.SizeLimit > 0
+	if ordered && r.resultsMap.Len() >= r.opts.SizeLimit &&
+		bytes.Compare(d.ID, r.orderedIDs[0]) > 0 {
+		// Ordered after all the results kept.
+		return false, r.resultsMap.Len(), nil
+	}
+
 	// i.e. it doesn't exist in the map, so we create the tags wrapping
 	// fields prodided by the document.
 	tags := r.cloneTagsFromFields(d.Fields)
@@ -142,9 +167,25 @@ func (r *results) addDocumentWithLock(
 	// the tsID's bytes.
 	r.resultsMap.Set(tsID, tags)
 
+	if ordered {
+		heap.Push(&r.orderedIDs, append([]byte(nil), d.ID...))
+		if r.resultsMap.Len() > r.opts.SizeLimit {
+			r.evictLastWithLock()
+		}
+	}
+
 	return true, r.resultsMap.Len(), nil
 }
 
+// evictLastWithLock removes the last result in ID order.
+func (r *results) evictLastWithLock() {
+	id := ident.BytesID(heap.Pop(&r.orderedIDs).([]byte))
+	if tags, ok := r.resultsMap.Get(id); ok {
+		tags.Finalize()
+	}
+	r.resultsMap.Delete(id)
+}
+
 func (r *results) cloneTagsFromFields(fields doc.Fields) ident.Tags {
 	tags := r.idPool.Tags()
 	for _, f := range fields {
@@ -209,3 +250,23 @@ func (r *results) NoFinalize() {
 
 	r.Unlock()
 }
+
+// idHeap is a max heap of the IDs of the results of ordered queries.
+type idHeap [][]byte
+
+func (h idHeap) Len() int           { return len(h) }
+func (h idHeap) Less(i, j int) bool { return bytes.Compare(h[i], h[j]) > 0 }
+func (h idHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *idHeap) Push(x interface{}) {
+	*h = append(*h, x.([]byte))
+}
+
+func (h *idHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return x
+}
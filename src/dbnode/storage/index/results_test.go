@@ -22,14 +22,17 @@ package index
 
 import (
 	"bytes"
+	"sort"
 	"testing"
 
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	xtest "github.com/m3db/m3/src/x/test"
 	"github.com/m3db/m3x/ident"
 	"github.com/m3db/m3x/pool"
 
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 var (
@@ -73,6 +76,54 @@ func TestResultsInsertIdempotency(t *testing.T) {
 	require.Equal(t, 1, size)
 }
 
+func TestResultsInsertAccountsSeriesCost(t *testing.T) {
+	queryLimits := limits.NewQueryLimits(limits.Limits{
+		Series: limits.Limit{PerQuery: 2},
+	}, tally.NoopScope)
+	cost := queryLimits.NewQueryCost()
+	defer cost.Close()
+
+	res := NewQueryResults(nil, QueryResultsOptions{Cost: cost}, testOpts)
+	size, err := res.AddDocuments([]doc.Document{
+		{ID: []byte("abc")},
+		{ID: []byte("def")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, size)
+
+	// Series already in the results are not accounted for again.
+	_, err = res.AddDocuments([]doc.Document{{ID: []byte("abc")}})
+	require.NoError(t, err)
+
+	_, err = res.AddDocuments([]doc.Document{{ID: []byte("ghi")}})
+	require.Error(t, err)
+}
+
+func TestResultsInsertOrderedByIDKeepsFirstIDsAfter(t *testing.T) {
+	res := NewQueryResults(nil, QueryResultsOptions{
+		SizeLimit:   2,
+		OrderedByID: true,
+		AfterID:     []byte("b"),
+	}, testOpts)
+	size, err := res.AddDocuments([]doc.Document{
+		{ID: []byte("e")},
+		{ID: []byte("a")},
+		{ID: []byte("d")},
+		{ID: []byte("b")},
+		{ID: []byte("c")},
+		{ID: []byte("f")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, size)
+
+	ids := make([]string, 0, res.Size())
+	for _, entry := range res.Map().Iter() {
+		ids = append(ids, entry.Key().String())
+	}
+	sort.Strings(ids)
+	require.Equal(t, []string{"c", "d"}, ids)
+}
+
 func TestResultsFirstInsertWins(t *testing.T) {
 	res := NewQueryResults(nil, QueryResultsOptions{}, testOpts)
 	d1 := doc.Document{ID: []byte("abc")}
@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index/compaction"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/m3ninx/doc"
 	"github.com/m3db/m3/src/m3ninx/idx"
 	"github.com/m3db/m3/src/m3ninx/index/segment/builder"
@@ -76,6 +77,19 @@ type QueryOptions struct {
 	StartInclusive time.Time
 	EndExclusive   time.Time
 	Limit          int
+	// Cost accounts for the resources used by the query, optional.
+	Cost limits.QueryCost
+	// Done is closed when the query is cancelled, optional. Clients stop
+	// waiting on and cancel any outstanding requests for the query once it
+	// is closed.
+	Done <-chan struct{}
+	// OrderedByID returns the first series in ID order up to the limit
+	// rather than the first series matched, so that queries can page through
+	// their series with AfterID. At most limit series are held at a time but
+	// all the series matched are visited.
+	OrderedByID bool
+	// AfterID only matches the series with an ID ordered after it, optional.
+	AfterID []byte
 }
 
 // LimitExceeded returns whether a given size exceeds the limit
@@ -84,6 +98,13 @@ func (o QueryOptions) LimitExceeded(size int) bool {
 	return o.Limit > 0 && size >= o.Limit
 }
 
+// StopAtLimit returns whether a query can stop matching series once a given
+// size exceeds the limit, which ordered queries can not as the series after
+// may be ordered before the ones already matched.
+func (o QueryOptions) StopAtLimit(size int) bool {
+	return !o.OrderedByID && o.LimitExceeded(size)
+}
+
 // AggregationOptions enables users to specify constraints on aggregations.
 type AggregationOptions struct {
 	QueryOptions
@@ -152,6 +173,17 @@ type QueryResultsOptions struct {
 	// SizeLimit will limit the total results set to a given limit and if
 	// overflown will return early successfully.
 	SizeLimit int
+
+	// Cost accounts for the series as they are added to the results, adding
+	// documents fails once the cost exceeds its limits, optional.
+	Cost limits.QueryCost
+
+	// OrderedByID keeps the first series in ID order up to the size limit,
+	// evicting the last series once a series ordered before it is added.
+	OrderedByID bool
+
+	// AfterID ignores the series with an ID not ordered after it, optional.
+	AfterID []byte
 }
 
 // QueryResultsAllocator allocates QueryResults types.
@@ -0,0 +1,239 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package limits
+
+import (
+	"fmt"
+
+	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3/src/x/cost"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	seriesResource = "series"
+	blocksResource = "blocks"
+	bytesResource  = "bytes"
+
+	overLimitMetric = "over-limit"
+)
+
+type queryLimits struct {
+	series *resourceLimits
+	blocks *resourceLimits
+	bytes  *resourceLimits
+}
+
+// NewQueryLimits returns a new QueryLimits enforcing the provided limits.
+func NewQueryLimits(limits Limits, scope tally.Scope) QueryLimits {
+	return &queryLimits{
+		series: newResourceLimits(seriesResource, limits.Series, scope),
+		blocks: newResourceLimits(blocksResource, limits.Blocks, scope),
+		bytes:  newResourceLimits(bytesResource, limits.Bytes, scope),
+	}
+}
+
+func (q *queryLimits) NewQueryCost() QueryCost {
+	return &queryCost{
+		series: newResourceCost(q.series),
+		blocks: newResourceCost(q.blocks),
+		bytes:  newResourceCost(q.bytes),
+	}
+}
+
+func (q *queryLimits) Report() {
+	q.series.report()
+	q.blocks.report()
+	q.bytes.report()
+}
+
+// resourceLimits holds the global enforcer of a resource, which tracks the
+// resource used by all queries in flight, and the model enforcer which is
+// cloned for every new query.
+type resourceLimits struct {
+	resource      string
+	global        cost.Enforcer
+	globalLimit   int64
+	perQuery      cost.Enforcer
+	perQueryLimit int64
+	inFlight      tally.Gauge
+}
+
+func newResourceLimits(
+	resource string,
+	limit Limit,
+	scope tally.Scope,
+) *resourceLimits {
+	scope = scope.SubScope(resource)
+	return &resourceLimits{
+		resource:      resource,
+		global:        newEnforcer(limit.Global, scope.SubScope("global")),
+		globalLimit:   limit.Global,
+		perQuery:      newEnforcer(limit.PerQuery, scope.SubScope("per-query")),
+		perQueryLimit: limit.PerQuery,
+		inFlight:      scope.Gauge("in-flight"),
+	}
+}
+
+func newEnforcer(limit int64, scope tally.Scope) cost.Enforcer {
+	// NB: the enforcer errors once the cost reaches its threshold, so the
+	// threshold is one over the limit for queries to be able to use exactly
+	// the limit.
+	limitOpts := cost.NewLimitManagerOptions().SetDefaultLimit(cost.Limit{
+		Threshold: cost.Cost(limit + 1),
+		Enabled:   limit > 0,
+	})
+	opts := cost.NewEnforcerOptions().
+		SetReporter(newOverLimitReporter(scope))
+	return cost.NewEnforcer(cost.NewStaticLimitManager(limitOpts),
+		cost.NewTracker(), opts)
+}
+
+func (r *resourceLimits) exceededError(kind string, limit int64, current cost.Cost) error {
+	return dberrors.NewQueryLimitExceededError(fmt.Sprintf(
+		"%s %s limit of %d exceeded (current = %v)", kind, r.resource, limit, current))
+}
+
+func (r *resourceLimits) report() {
+	report, _ := r.global.State()
+	r.inFlight.Update(float64(report.Cost))
+}
+
+type queryCost struct {
+	series resourceCost
+	blocks resourceCost
+	bytes  resourceCost
+}
+
+func (c *queryCost) AddSeries(n int) error {
+	return c.series.add(n)
+}
+
+func (c *queryCost) AddBlockReaders(readers [][]xio.BlockReader) error {
+	var blocks, bytes int
+	for _, blockReaders := range readers {
+		for _, reader := range blockReaders {
+			if reader.SegmentReader == nil {
+				continue
+			}
+			segment, err := reader.Segment()
+			if err != nil {
+				return err
+			}
+			blocks++
+			bytes += segment.Len()
+		}
+	}
+	if err := c.blocks.add(blocks); err != nil {
+		return err
+	}
+	return c.bytes.add(bytes)
+}
+
+func (c *queryCost) Close() {
+	c.series.release()
+	c.blocks.release()
+	c.bytes.release()
+}
+
+type resourceCost struct {
+	limits *resourceLimits
+	query  cost.Enforcer
+}
+
+func newResourceCost(limits *resourceLimits) resourceCost {
+	return resourceCost{
+		limits: limits,
+		query:  limits.perQuery.Clone(),
+	}
+}
+
+// add adds to both the query and global totals, preferring to return the
+// query limit error as it is the most specific.
+func (c resourceCost) add(n int) error {
+	if n == 0 {
+		return nil
+	}
+	queryReport := c.query.Add(cost.Cost(n))
+	globalReport := c.limits.global.Add(cost.Cost(n))
+	if queryReport.Error != nil {
+		return c.limits.exceededError("per query", c.limits.perQueryLimit, queryReport.Cost)
+	}
+	if globalReport.Error != nil {
+		return c.limits.exceededError("global", c.limits.globalLimit, globalReport.Cost)
+	}
+	return nil
+}
+
+// release returns the resources used by the query to the global total.
+func (c resourceCost) release() {
+	report, _ := c.query.State()
+	if report.Cost == 0 {
+		return
+	}
+	c.limits.global.Add(-report.Cost)
+}
+
+type overLimitReporter struct {
+	overLimitDisabled tally.Counter
+	overLimitEnabled  tally.Counter
+}
+
+func newOverLimitReporter(scope tally.Scope) cost.EnforcerReporter {
+	return &overLimitReporter{
+		overLimitDisabled: scope.Tagged(map[string]string{
+			"enabled": "false",
+		}).Counter(overLimitMetric),
+		overLimitEnabled: scope.Tagged(map[string]string{
+			"enabled": "true",
+		}).Counter(overLimitMetric),
+	}
+}
+
+func (r *overLimitReporter) ReportCost(c cost.Cost)    {}
+func (r *overLimitReporter) ReportCurrent(c cost.Cost) {}
+
+func (r *overLimitReporter) ReportOverLimit(enabled bool) {
+	if enabled {
+		r.overLimitEnabled.Inc(1)
+	} else {
+		r.overLimitDisabled.Inc(1)
+	}
+}
+
+type noopQueryLimits struct{}
+
+// NewNoopQueryLimits returns a QueryLimits which neither tracks nor limits
+// the resources used by queries.
+func NewNoopQueryLimits() QueryLimits {
+	return noopQueryLimits{}
+}
+
+func (noopQueryLimits) NewQueryCost() QueryCost { return noopQueryCost{} }
+func (noopQueryLimits) Report()                 {}
+
+type noopQueryCost struct{}
+
+func (noopQueryCost) AddSeries(n int) error                             { return nil }
+func (noopQueryCost) AddBlockReaders(readers [][]xio.BlockReader) error { return nil }
+func (noopQueryCost) Close()                                            {}
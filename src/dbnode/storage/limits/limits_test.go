@@ -0,0 +1,123 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package limits
+
+import (
+	"testing"
+
+	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3x/checked"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestBlockReader(size int) xio.BlockReader {
+	segment := ts.NewSegment(checked.NewBytes(make([]byte, size), nil), nil, ts.FinalizeNone)
+	return xio.BlockReader{SegmentReader: xio.NewSegmentReader(segment)}
+}
+
+func TestQueryCostPerQueryLimit(t *testing.T) {
+	limits := NewQueryLimits(Limits{
+		Series: Limit{PerQuery: 10},
+	}, tally.NoopScope)
+
+	first := limits.NewQueryCost()
+	require.NoError(t, first.AddSeries(9))
+
+	// Per query limits are independent of one another.
+	second := limits.NewQueryCost()
+	require.NoError(t, second.AddSeries(9))
+
+	// Queries can use exactly the limit.
+	require.NoError(t, first.AddSeries(1))
+
+	err := first.AddSeries(1)
+	require.Error(t, err)
+	require.True(t, dberrors.IsQueryLimitExceededError(err))
+	require.Contains(t, err.Error(), "per query series limit of 10 exceeded")
+
+	first.Close()
+	second.Close()
+}
+
+func TestQueryCostAtLimit(t *testing.T) {
+	limits := NewQueryLimits(Limits{
+		Series: Limit{PerQuery: 5, Global: 8},
+	}, tally.NoopScope)
+
+	first := limits.NewQueryCost()
+	require.NoError(t, first.AddSeries(5))
+
+	second := limits.NewQueryCost()
+	require.NoError(t, second.AddSeries(3))
+
+	err := second.AddSeries(1)
+	require.True(t, dberrors.IsQueryLimitExceededError(err))
+	require.Contains(t, err.Error(), "global series limit of 8 exceeded")
+
+	first.Close()
+	second.Close()
+}
+
+func TestQueryCostGlobalLimitReleasedOnClose(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	limits := NewQueryLimits(Limits{
+		Blocks: Limit{Global: 3},
+		Bytes:  Limit{PerQuery: 100},
+	}, scope)
+
+	first := limits.NewQueryCost()
+	require.NoError(t, first.AddBlockReaders([][]xio.BlockReader{
+		{newTestBlockReader(10), newTestBlockReader(20)},
+	}))
+
+	limits.Report()
+	gauges := scope.Snapshot().Gauges()
+	require.Equal(t, float64(2), gauges["blocks.in-flight+"].Value())
+	require.Equal(t, float64(30), gauges["bytes.in-flight+"].Value())
+
+	second := limits.NewQueryCost()
+	err := second.AddBlockReaders([][]xio.BlockReader{
+		{newTestBlockReader(10), newTestBlockReader(10)},
+	})
+	require.True(t, dberrors.IsQueryLimitExceededError(err))
+	second.Close()
+
+	first.Close()
+	third := limits.NewQueryCost()
+	require.NoError(t, third.AddBlockReaders([][]xio.BlockReader{
+		{newTestBlockReader(10), xio.EmptyBlockReader},
+	}))
+	third.Close()
+
+	limits.Report()
+	gauges = scope.Snapshot().Gauges()
+	require.Equal(t, float64(0), gauges["blocks.in-flight+"].Value())
+}
+
+func TestNoopQueryLimits(t *testing.T) {
+	cost := NewNoopQueryLimits().NewQueryCost()
+	require.NoError(t, cost.AddSeries(1<<30))
+	cost.Close()
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package limits provides accounting and enforcement of the resources used
+// by queries executed by the database.
+package limits
+
+import (
+	"github.com/m3db/m3/src/dbnode/x/xio"
+)
+
+// Limit is a limit on a single resource, both for each individual query and
+// across all queries in flight. A non-positive value disables the limit.
+type Limit struct {
+	PerQuery int64
+	Global   int64
+}
+
+// Limits are the limits on the resources used by queries.
+type Limits struct {
+	// Series limits the number of series matched by queries.
+	Series Limit
+	// Blocks limits the number of blocks read by queries.
+	Blocks Limit
+	// Bytes limits the number of encoded bytes read by queries.
+	Bytes Limit
+}
+
+// QueryLimits creates the accounting for individual queries and tracks the
+// resources used by all queries in flight.
+type QueryLimits interface {
+	// NewQueryCost returns the accounting for a new query, the caller must
+	// close it once the query completes to release its resources.
+	NewQueryCost() QueryCost
+
+	// Report reports metrics on the resources used by queries in flight.
+	Report()
+}
+
+// QueryCost accounts for the resources used by a single query, returning a
+// query limit exceeded error once either the query or the global limit for
+// a resource has been exceeded.
+type QueryCost interface {
+	// AddSeries accounts for series matched by the query.
+	AddSeries(n int) error
+
+	// AddBlockReaders accounts for the blocks, and the bytes of those
+	// blocks, read by the query.
+	AddBlockReaders(readers [][]xio.BlockReader) error
+
+	// Close releases the resources accounted for by the query.
+	Close()
+}
@@ -22,6 +22,7 @@ package storage
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -46,6 +47,7 @@ var (
 	errMediatorAlreadyOpen   = errors.New("mediator is already open")
 	errMediatorNotOpen       = errors.New("mediator is not open")
 	errMediatorAlreadyClosed = errors.New("mediator is already closed")
+	errMediatorNoTickYet     = errors.New("mediator has not completed a tick yet")
 )
 
 type mediatorMetrics struct {
@@ -78,6 +80,13 @@ type mediator struct {
 	metrics  mediatorMetrics
 	state    mediatorState
 	closedCh chan struct{}
+
+	// lastTickStart and lastTickBootstrapState are captured at the start of
+	// the last successfully completed tick so that file operations triggered
+	// outside of the tick loop are held to the same guarantees as the ones
+	// performed at the end of a tick.
+	lastTickStart          time.Time
+	lastTickBootstrapState DatabaseBootstrapState
 }
 
 func newMediator(database database, commitlog commitlog.CommitLog, opts Options) (databaseMediator, error) {
@@ -162,6 +171,11 @@ func (m *mediator) Tick(runType runType, forceType forceType) error {
 		return err
 	}
 
+	m.Lock()
+	m.lastTickStart = tickStart
+	m.lastTickBootstrapState = dbBootstrapStateAtTickStart
+	m.Unlock()
+
 	// NB(r): Cleanup and/or flush if required to cleanup files and/or
 	// flush blocks to disk. Note this has to run after the tick as
 	// blocks may only have just become available during a tick beginning
@@ -170,10 +184,44 @@ func (m *mediator) Tick(runType runType, forceType forceType) error {
 	return nil
 }
 
+// FlushNamespace flushes a namespace as if the flush was performed at the end
+// of the last completed tick, this is required because a block may only be
+// flushed once a complete tick has occurred since it became flushable.
+func (m *mediator) FlushNamespace(ns databaseNamespace) error {
+	tickStart, dbBootstrapState, ok := m.lastTick()
+	if !ok {
+		return errMediatorNoTickYet
+	}
+	shardBootstrapStates, ok := dbBootstrapState.NamespaceBootstrapStates[ns.ID().String()]
+	if !ok {
+		// Could happen if the namespace was added after the last tick started.
+		return fmt.Errorf(
+			"tried to flush ns: %s, but did not have shard bootstrap times", ns.ID().String())
+	}
+	return m.databaseFileSystemManager.FlushNamespace(ns, tickStart, shardBootstrapStates)
+}
+
+// Snapshot snapshots all namespaces as if the snapshot was performed at the
+// end of the last completed tick.
+func (m *mediator) Snapshot() error {
+	tickStart, _, ok := m.lastTick()
+	if !ok {
+		return errMediatorNoTickYet
+	}
+	return m.databaseFileSystemManager.Snapshot(tickStart)
+}
+
+func (m *mediator) lastTick() (time.Time, DatabaseBootstrapState, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	return m.lastTickStart, m.lastTickBootstrapState, !m.lastTickStart.IsZero()
+}
+
 func (m *mediator) Report() {
 	m.databaseBootstrapManager.Report()
 	m.databaseRepairer.Report()
 	m.databaseFileSystemManager.Report()
+	m.opts.QueryLimits().Report()
 }
 
 func (m *mediator) Close() error {
@@ -89,3 +89,51 @@ func TestDatabaseMediatorDisableFileOps(t *testing.T) {
 	m.DisableFileOps()
 	require.Equal(t, 3, len(slept))
 }
+
+func TestDatabaseMediatorFlushNamespaceAndSnapshotUseLastTickStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions().SetRepairEnabled(false)
+	now := time.Now()
+	opts = opts.
+		SetBootstrapProcessProvider(nil).
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+			return now
+		}))
+
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().ID().Return(defaultTestNs1ID).AnyTimes()
+
+	shardBootstrapStates := ShardBootstrapStates{0: Bootstrapped}
+	bootstrapState := DatabaseBootstrapState{
+		NamespaceBootstrapStates: NamespaceBootstrapStates{
+			defaultTestNs1ID.String(): shardBootstrapStates,
+		},
+	}
+
+	db := NewMockdatabase(ctrl)
+	db.EXPECT().Options().Return(opts).AnyTimes()
+	db.EXPECT().BootstrapState().Return(bootstrapState)
+	med, err := newMediator(db, nil, opts)
+	require.NoError(t, err)
+
+	m := med.(*mediator)
+	fsm := NewMockdatabaseFileSystemManager(ctrl)
+	m.databaseFileSystemManager = fsm
+	tm := NewMockdatabaseTickManager(ctrl)
+	m.databaseTickManager = tm
+
+	require.Equal(t, errMediatorNoTickYet, m.FlushNamespace(ns))
+	require.Equal(t, errMediatorNoTickYet, m.Snapshot())
+
+	tm.EXPECT().Tick(noForce, now).Return(nil)
+	fsm.EXPECT().Run(now, bootstrapState, syncRun, noForce).Return(true)
+	require.NoError(t, m.Tick(asyncRun, noForce))
+
+	fsm.EXPECT().FlushNamespace(ns, now, shardBootstrapStates).Return(nil)
+	require.NoError(t, m.FlushNamespace(ns))
+
+	fsm.EXPECT().Snapshot(now).Return(nil)
+	require.NoError(t, m.Snapshot())
+}
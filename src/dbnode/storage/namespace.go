@@ -155,6 +155,7 @@ type databaseNamespaceMetrics struct {
 	unfulfilled         tally.Counter
 	bootstrapStart      tally.Counter
 	bootstrapEnd        tally.Counter
+	bootstrapBatch      tally.Timer
 	shards              databaseNamespaceShardMetrics
 	tick                databaseNamespaceTickMetrics
 	status              databaseNamespaceStatusMetrics
@@ -233,6 +234,7 @@ func newDatabaseNamespaceMetrics(scope tally.Scope, samplingRate float64) databa
 		unfulfilled:         scope.Counter("bootstrap.unfulfilled"),
 		bootstrapStart:      scope.Counter("bootstrap.start"),
 		bootstrapEnd:        scope.Counter("bootstrap.end"),
+		bootstrapBatch:      scope.Timer("bootstrap.batch"),
 		shards: databaseNamespaceShardMetrics{
 			add:         shardsScope.Counter("add"),
 			close:       shardsScope.Counter("close"),
@@ -744,12 +746,70 @@ func (n *dbNamespace) Bootstrap(start time.Time, process bootstrap.Process) erro
 		return nil
 	}
 
-	shardIDs := make([]uint32, len(shards))
-	for i, shard := range shards {
-		shardIDs[i] = shard.ID()
+	// NB: When a shard batch size is set the data of each batch of shards is
+	// bootstrapped in turn and each batch is bootstrapped as soon as its
+	// result is available, allowing those shards to serve reads while the
+	// remaining shards are still bootstrapping. The index is bootstrapped
+	// once the data of all batches is, so index queries are only served once
+	// the index covers all the shards.
+	batchSize := n.opts.BootstrapShardBatchSize()
+	if batchSize <= 0 || batchSize >= len(shards) {
+		bootstrapResult, err := process.Run(start, n.metadata, shardIDs(shards))
+		if err != nil {
+			n.log.Errorf("bootstrap for namespace %s aborted due to error: %v",
+				n.id.String(), err)
+			return err
+		}
+		n.metrics.bootstrap.Success.Inc(1)
+
+		multiErr := xerrors.NewMultiError().
+			Add(n.bootstrapShards(shards, bootstrapResult.DataResult)).
+			Add(n.bootstrapIndex(bootstrapResult.IndexResult))
+		err = multiErr.FinalError()
+		n.metrics.bootstrap.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+		success = err == nil
+		return err
+	}
+
+	var (
+		batches      [][]uint32
+		shardsByID   = make(map[uint32]databaseShard, len(shards))
+		numRemaining = len(shards)
+		batchStart   = n.nowFn()
+		multiErr     = xerrors.NewMultiError()
+		remainingIDs = shardIDs(shards)
+	)
+	for _, shard := range shards {
+		shardsByID[shard.ID()] = shard
+	}
+	for len(remainingIDs) > 0 {
+		batch := remainingIDs
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		remainingIDs = remainingIDs[len(batch):]
+		batches = append(batches, batch)
 	}
 
-	bootstrapResult, err := process.Run(start, n.metadata, shardIDs)
+	indexResult, err := process.RunBatches(start, n.metadata, batches,
+		func(ids []uint32, dataResult result.DataBootstrapResult) error {
+			batch := make([]databaseShard, 0, len(ids))
+			for _, id := range ids {
+				batch = append(batch, shardsByID[id])
+			}
+			multiErr = multiErr.Add(n.bootstrapShards(batch, dataResult))
+
+			numRemaining -= len(batch)
+			batchTook := n.nowFn().Sub(batchStart)
+			n.metrics.bootstrapBatch.Record(batchTook)
+			n.log.WithFields(
+				xlog.NewField("numShards", len(batch)),
+				xlog.NewField("remainingShards", numRemaining),
+				xlog.NewField("took", batchTook.String()),
+			).Infof("bootstrap shard batch complete")
+			batchStart = n.nowFn()
+			return nil
+		})
 	if err != nil {
 		n.log.Errorf("bootstrap for namespace %s aborted due to error: %v",
 			n.id.String(), err)
@@ -757,11 +817,30 @@ func (n *dbNamespace) Bootstrap(start time.Time, process bootstrap.Process) erro
 	}
 	n.metrics.bootstrap.Success.Inc(1)
 
+	multiErr = multiErr.Add(n.bootstrapIndex(indexResult))
+	err = multiErr.FinalError()
+	n.metrics.bootstrap.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
+	success = err == nil
+	return err
+}
+
+func shardIDs(shards []databaseShard) []uint32 {
+	ids := make([]uint32, 0, len(shards))
+	for _, shard := range shards {
+		ids = append(ids, shard.ID())
+	}
+	return ids
+}
+
+func (n *dbNamespace) bootstrapShards(
+	shards []databaseShard,
+	dataResult result.DataBootstrapResult,
+) error {
 	// Bootstrap shards using at least half the CPUs available
 	workers := xsync.NewWorkerPool(int(math.Ceil(float64(runtime.NumCPU()) / 2)))
 	workers.Init()
 
-	numSeries := bootstrapResult.DataResult.ShardResults().NumSeries()
+	numSeries := dataResult.ShardResults().NumSeries()
 	n.log.WithFields(
 		xlog.NewField("numShards", len(shards)),
 		xlog.NewField("numSeries", numSeries),
@@ -769,7 +848,7 @@ func (n *dbNamespace) Bootstrap(start time.Time, process bootstrap.Process) erro
 
 	var (
 		multiErr = xerrors.NewMultiError()
-		results  = bootstrapResult.DataResult.ShardResults()
+		results  = dataResult.ShardResults()
 		mutex    sync.Mutex
 		wg       sync.WaitGroup
 	)
@@ -796,30 +875,37 @@ func (n *dbNamespace) Bootstrap(start time.Time, process bootstrap.Process) erro
 
 	wg.Wait()
 
+	multiErr = multiErr.Add(n.markAnyUnfulfilled("data", dataResult.Unfulfilled()))
+	return multiErr.FinalError()
+}
+
+func (n *dbNamespace) bootstrapIndex(indexResult result.IndexBootstrapResult) error {
+	multiErr := xerrors.NewMultiError()
 	if n.reverseIndex != nil {
-		err := n.reverseIndex.Bootstrap(bootstrapResult.IndexResult.IndexResults())
+		err := n.reverseIndex.Bootstrap(indexResult.IndexResults())
 		multiErr = multiErr.Add(err)
 	}
 
-	markAnyUnfulfilled := func(label string, unfulfilled result.ShardTimeRanges) {
-		shardsUnfulfilled := int64(len(unfulfilled))
-		n.metrics.unfulfilled.Inc(shardsUnfulfilled)
-		if shardsUnfulfilled > 0 {
-			str := unfulfilled.SummaryString()
-			err := fmt.Errorf("bootstrap completed with unfulfilled ranges: %s", str)
-			multiErr = multiErr.Add(err)
-			n.log.WithFields(
-				xlog.NewField("namespace", n.id.String()),
-				xlog.NewField("bootstrap-type", label),
-			).Errorf(err.Error())
-		}
+	multiErr = multiErr.Add(n.markAnyUnfulfilled("index", indexResult.Unfulfilled()))
+	return multiErr.FinalError()
+}
+
+func (n *dbNamespace) markAnyUnfulfilled(
+	label string,
+	unfulfilled result.ShardTimeRanges,
+) error {
+	shardsUnfulfilled := int64(len(unfulfilled))
+	n.metrics.unfulfilled.Inc(shardsUnfulfilled)
+	if shardsUnfulfilled == 0 {
+		return nil
 	}
-	markAnyUnfulfilled("data", bootstrapResult.DataResult.Unfulfilled())
-	markAnyUnfulfilled("index", bootstrapResult.IndexResult.Unfulfilled())
 
-	err = multiErr.FinalError()
-	n.metrics.bootstrap.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
-	success = err == nil
+	str := unfulfilled.SummaryString()
+	err := fmt.Errorf("bootstrap completed with unfulfilled ranges: %s", str)
+	n.log.WithFields(
+		xlog.NewField("namespace", n.id.String()),
+		xlog.NewField("bootstrap-type", label),
+	).Errorf(err.Error())
 	return err
 }
 
@@ -367,6 +367,55 @@ func TestNamespaceBootstrapOnlyNonBootstrappedShards(t *testing.T) {
 	require.Equal(t, Bootstrapped, ns.bootstrapState)
 }
 
+func TestNamespaceBootstrapShardBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	idx := NewMocknamespaceIndex(ctrl)
+	ns, closer := newTestNamespaceWithIndex(t, idx)
+	defer closer()
+	ns.opts = ns.opts.SetBootstrapShardBatchSize(1)
+
+	start := time.Now()
+
+	var (
+		batches [][]uint32
+		calls   []*gomock.Call
+	)
+	for _, testShard := range testShardIDs {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().IsBootstrapped().Return(false)
+		shard.EXPECT().ID().Return(testShard.ID()).AnyTimes()
+		ns.shards[testShard.ID()] = shard
+
+		batches = append(batches, []uint32{testShard.ID()})
+		calls = append(calls, shard.EXPECT().Bootstrap(gomock.Any()).Return(nil))
+	}
+
+	// The index is only bootstrapped once every batch has been.
+	indexResult := result.NewIndexBootstrapResult()
+	calls = append(calls, idx.EXPECT().Bootstrap(indexResult.IndexResults()).Return(nil))
+	gomock.InOrder(calls...)
+
+	bs := bootstrap.NewMockProcess(ctrl)
+	bs.EXPECT().
+		RunBatches(start, ns.metadata, batches, gomock.Any()).
+		DoAndReturn(func(
+			_ time.Time,
+			_ namespace.Metadata,
+			batches [][]uint32,
+			fn bootstrap.ProcessBatchFn,
+		) (result.IndexBootstrapResult, error) {
+			for _, batch := range batches {
+				require.NoError(t, fn(batch, result.NewDataBootstrapResult()))
+			}
+			return indexResult, nil
+		})
+
+	require.NoError(t, ns.Bootstrap(start, bs))
+	require.Equal(t, Bootstrapped, ns.bootstrapState)
+}
+
 func TestNamespaceFlushNotBootstrapped(t *testing.T) {
 	ns, closer := newTestNamespace(t)
 	defer closer()
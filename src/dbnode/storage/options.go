@@ -38,6 +38,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/storage/series"
@@ -121,6 +122,7 @@ type options struct {
 	newEncoderFn                   encoding.NewEncoderFn
 	newDecoderFn                   encoding.NewDecoderFn
 	bootstrapProcessProvider       bootstrap.ProcessProvider
+	bootstrapShardBatchSize        int
 	persistManager                 persist.Manager
 	blockRetrieverManager          block.DatabaseBlockRetrieverManager
 	poolOpts                       pool.ObjectPoolOptions
@@ -138,6 +140,7 @@ type options struct {
 	fetchBlocksMetadataResultsPool block.FetchBlocksMetadataResultsPool
 	queryIDsWorkerPool             xsync.WorkerPool
 	writeBatchPool                 *ts.WriteBatchPool
+	queryLimits                    limits.QueryLimits
 }
 
 // NewOptions creates a new set of storage options with defaults
@@ -193,6 +196,7 @@ func newOptions(poolOpts pool.ObjectPoolOptions) Options {
 		fetchBlocksMetadataResultsPool: block.NewFetchBlocksMetadataResultsPool(poolOpts, 0),
 		queryIDsWorkerPool:             queryIDsWorkerPool,
 		writeBatchPool:                 writeBatchPool,
+		queryLimits:                    limits.NewNoopQueryLimits(),
 	}
 	return o.SetEncodingM3TSZPooled()
 }
@@ -450,6 +454,16 @@ func (o *options) BootstrapProcessProvider() bootstrap.ProcessProvider {
 	return o.bootstrapProcessProvider
 }
 
+func (o *options) SetBootstrapShardBatchSize(value int) Options {
+	opts := *o
+	opts.bootstrapShardBatchSize = value
+	return &opts
+}
+
+func (o *options) BootstrapShardBatchSize() int {
+	return o.bootstrapShardBatchSize
+}
+
 func (o *options) SetPersistManager(value persist.Manager) Options {
 	opts := *o
 	opts.persistManager = value
@@ -610,3 +624,13 @@ func (o *options) SetWriteBatchPool(value *ts.WriteBatchPool) Options {
 func (o *options) WriteBatchPool() *ts.WriteBatchPool {
 	return o.writeBatchPool
 }
+
+func (o *options) SetQueryLimits(value limits.QueryLimits) Options {
+	opts := *o
+	opts.queryLimits = value
+	return &opts
+}
+
+func (o *options) QueryLimits() limits.QueryLimits {
+	return o.queryLimits
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/limits"
 	"github.com/m3db/m3/src/dbnode/storage/namespace"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/storage/series"
@@ -209,6 +210,22 @@ type Database interface {
 	// BootstrapState captures and returns a snapshot of the databases'
 	// bootstrap state.
 	BootstrapState() DatabaseBootstrapState
+
+	// BootstrapProgress returns a snapshot of the progress of the current,
+	// or otherwise the most recent, bootstrap of the database.
+	BootstrapProgress() bootstrap.ProgressSnapshot
+
+	// FileOpsState captures and returns a snapshot of the flush and
+	// snapshot state of all the shards owned by the database.
+	FileOpsState() (DatabaseFileOpsState, error)
+
+	// FlushNamespace flushes all flushable blocks of a namespace to disk
+	// immediately rather than waiting for the next mediator tick.
+	FlushNamespace(namespace ident.ID) error
+
+	// Snapshot snapshots all unflushed blocks across all namespaces
+	// immediately rather than waiting for the next mediator tick.
+	Snapshot() error
 }
 
 // database is the internal database interface
@@ -574,6 +591,18 @@ type databaseFlushManager interface {
 	// Flush flushes in-memory data to persistent storage.
 	Flush(tickStart time.Time, dbBootstrapStateAtTickStart DatabaseBootstrapState) error
 
+	// FlushNamespace flushes in-memory data of a single namespace to
+	// persistent storage.
+	FlushNamespace(
+		ns databaseNamespace,
+		tickStart time.Time,
+		shardBootstrapStatesAtTickStart ShardBootstrapStates,
+	) error
+
+	// Snapshot rotates the commitlog and snapshots unflushed in-memory data
+	// of all namespaces.
+	Snapshot(tickStart time.Time) error
+
 	// LastSuccessfulSnapshotStartTime returns the start time of the last
 	// successful snapshot, if any.
 	LastSuccessfulSnapshotStartTime() (time.Time, bool)
@@ -599,6 +628,18 @@ type databaseFileSystemManager interface {
 	// Flush flushes in-memory data to persistent storage.
 	Flush(t time.Time, dbBootstrapStateAtTickStart DatabaseBootstrapState) error
 
+	// FlushNamespace flushes in-memory data of a single namespace to
+	// persistent storage, failing if other file operations are in progress.
+	FlushNamespace(
+		ns databaseNamespace,
+		tickStart time.Time,
+		shardBootstrapStatesAtTickStart ShardBootstrapStates,
+	) error
+
+	// Snapshot snapshots unflushed in-memory data of all namespaces,
+	// failing if other file operations are in progress.
+	Snapshot(tickStart time.Time) error
+
 	// Disable disables the filesystem manager and prevents it from
 	// performing file operations, returns the current file operation status.
 	Disable() fileOpStatus
@@ -687,6 +728,14 @@ type databaseMediator interface {
 	// Tick performs a tick.
 	Tick(runType runType, forceType forceType) error
 
+	// FlushNamespace flushes a namespace using the start time of the last
+	// completed tick.
+	FlushNamespace(ns databaseNamespace) error
+
+	// Snapshot snapshots all namespaces using the start time of the last
+	// completed tick.
+	Snapshot() error
+
 	// Repair repairs the database.
 	Repair() error
 
@@ -793,6 +842,17 @@ type Options interface {
 	// BootstrapProcessProvider returns the bootstrap process provider for the database.
 	BootstrapProcessProvider() bootstrap.ProcessProvider
 
+	// SetBootstrapShardBatchSize sets the number of shards of a namespace to
+	// bootstrap together, when set shards begin serving reads as soon as their
+	// batch is bootstrapped rather than once all shards of the namespace are,
+	// a value of zero bootstraps all shards of a namespace together. The
+	// index of the namespace is bootstrapped once all batches are.
+	SetBootstrapShardBatchSize(value int) Options
+
+	// BootstrapShardBatchSize returns the number of shards of a namespace to
+	// bootstrap together.
+	BootstrapShardBatchSize() int
+
 	// SetPersistManager sets the persistence manager.
 	SetPersistManager(value persist.Manager) Options
 
@@ -899,6 +959,12 @@ type Options interface {
 
 	// WriteBatchPool returns the WriteBatch pool.
 	WriteBatchPool() *ts.WriteBatchPool
+
+	// SetQueryLimits sets the limits on the resources used by queries.
+	SetQueryLimits(value limits.QueryLimits) Options
+
+	// QueryLimits returns the limits on the resources used by queries.
+	QueryLimits() limits.QueryLimits
 }
 
 // DatabaseBootstrapState stores a snapshot of the bootstrap state for all shards across all
@@ -915,6 +981,39 @@ type NamespaceBootstrapStates map[string]ShardBootstrapStates
 // namespace.
 type ShardBootstrapStates map[uint32]BootstrapState
 
+// DatabaseFileOpsState stores a snapshot of the flush and snapshot state for all
+// shards across all namespaces at a given moment in time.
+type DatabaseFileOpsState struct {
+	NamespaceFileOpsStates NamespaceFileOpsStates
+}
+
+// NamespaceFileOpsStates stores a snapshot of the flush and snapshot state for all
+// shards across a number of namespaces at a given moment in time.
+type NamespaceFileOpsStates map[string]ShardFileOpsStates
+
+// ShardFileOpsStates stores a snapshot of the flush and snapshot state for all
+// shards for a given namespace.
+type ShardFileOpsStates map[uint32]ShardFileOpsState
+
+// ShardFileOpsState is the flush and snapshot state of a shard.
+type ShardFileOpsState struct {
+	// IsSnapshotting is true if the shard is currently being snapshotted.
+	IsSnapshotting bool
+	// LastSuccessfulSnapshot is the time the last successful snapshot of the
+	// shard completed, zero if the shard has never been snapshotted.
+	LastSuccessfulSnapshot time.Time
+	// UnflushedBlocks are the flush states of the blocks within the flushable
+	// and snapshottable range that have not been successfully flushed yet.
+	UnflushedBlocks []BlockFlushState
+}
+
+// BlockFlushState is the flush state of a single block of a shard.
+type BlockFlushState struct {
+	BlockStart  time.Time
+	Status      string
+	NumFailures int
+}
+
 // BootstrapState is an enum representing the possible bootstrap states for a shard.
 type BootstrapState int
 
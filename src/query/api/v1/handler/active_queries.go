@@ -0,0 +1,115 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// ActiveQueriesURL is the url to list the queries being executed.
+	ActiveQueriesURL = RoutePrefixV1 + "/debug/queries"
+
+	// ActiveQueriesHTTPMethod is the HTTP method used to list the queries
+	// being executed.
+	ActiveQueriesHTTPMethod = http.MethodGet
+
+	// CancelQueryURL is the url to cancel a query being executed.
+	CancelQueryURL = ActiveQueriesURL + "/{" + queryIDVar + "}"
+
+	// CancelQueryHTTPMethod is the HTTP method used to cancel a query being
+	// executed.
+	CancelQueryHTTPMethod = http.MethodDelete
+
+	queryIDVar = "id"
+)
+
+var (
+	errQueryNotFound = errors.New("unable to find an active query with specified ID")
+
+	errEmptyQueryID = errors.New("must specify query ID to cancel")
+)
+
+// QueryTracker lists and cancels the queries being executed.
+type QueryTracker interface {
+	// ActiveQueries returns the queries being executed.
+	ActiveQueries() []executor.ActiveQuery
+
+	// CancelQuery cancels the query with the given ID, returning false if
+	// there is no such active query.
+	CancelQuery(id string) bool
+}
+
+// ActiveQueriesHandler is the handler listing the queries being executed.
+type ActiveQueriesHandler struct {
+	tracker QueryTracker
+}
+
+// NewActiveQueriesHandler returns a new instance of ActiveQueriesHandler.
+func NewActiveQueriesHandler(tracker QueryTracker) http.Handler {
+	return &ActiveQueriesHandler{tracker: tracker}
+}
+
+func (h *ActiveQueriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context())
+	xhttp.WriteJSONResponse(w, struct {
+		Queries []executor.ActiveQuery `json:"queries"`
+	}{
+		Queries: h.tracker.ActiveQueries(),
+	}, logger)
+}
+
+// CancelQueryHandler is the handler cancelling a query being executed.
+type CancelQueryHandler struct {
+	tracker QueryTracker
+}
+
+// NewCancelQueryHandler returns a new instance of CancelQueryHandler.
+func NewCancelQueryHandler(tracker QueryTracker) http.Handler {
+	return &CancelQueryHandler{tracker: tracker}
+}
+
+func (h *CancelQueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := logging.WithContext(r.Context())
+	id := strings.TrimSpace(mux.Vars(r)[queryIDVar])
+	if id == "" {
+		xhttp.Error(w, errEmptyQueryID, http.StatusBadRequest)
+		return
+	}
+
+	if !h.tracker.CancelQuery(id) {
+		xhttp.Error(w, errQueryNotFound, http.StatusNotFound)
+		return
+	}
+
+	xhttp.WriteJSONResponse(w, struct {
+		Cancelled bool `json:"cancelled"`
+	}{
+		Cancelled: true,
+	}, logger)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/query/executor"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testQueryTracker struct {
+	queries   []executor.ActiveQuery
+	cancelled []string
+}
+
+func (t *testQueryTracker) ActiveQueries() []executor.ActiveQuery {
+	return t.queries
+}
+
+func (t *testQueryTracker) CancelQuery(id string) bool {
+	for _, q := range t.queries {
+		if q.ID == id {
+			t.cancelled = append(t.cancelled, id)
+			return true
+		}
+	}
+
+	return false
+}
+
+func newActiveQueriesRouter(tracker QueryTracker) *mux.Router {
+	router := mux.NewRouter()
+	router.Handle(ActiveQueriesURL, NewActiveQueriesHandler(tracker)).
+		Methods(ActiveQueriesHTTPMethod)
+	router.Handle(CancelQueryURL, NewCancelQueryHandler(tracker)).
+		Methods(CancelQueryHTTPMethod)
+	return router
+}
+
+func TestActiveQueriesHandler(t *testing.T) {
+	tracker := &testQueryTracker{
+		queries: []executor.ActiveQuery{{ID: "1", Query: "up", Cost: 10}},
+	}
+
+	req := httptest.NewRequest(ActiveQueriesHTTPMethod, ActiveQueriesURL, nil)
+	w := httptest.NewRecorder()
+	newActiveQueriesRouter(tracker).ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Queries []executor.ActiveQuery `json:"queries"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Queries, 1)
+	assert.Equal(t, "1", resp.Queries[0].ID)
+	assert.Equal(t, "up", resp.Queries[0].Query)
+	assert.Equal(t, 10.0, resp.Queries[0].Cost)
+}
+
+func TestCancelQueryHandler(t *testing.T) {
+	tracker := &testQueryTracker{
+		queries: []executor.ActiveQuery{{ID: "1", Query: "up"}},
+	}
+	router := newActiveQueriesRouter(tracker)
+
+	req := httptest.NewRequest(CancelQueryHTTPMethod, ActiveQueriesURL+"/2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, tracker.cancelled)
+
+	req = httptest.NewRequest(CancelQueryHTTPMethod, ActiveQueriesURL+"/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"1"}, tracker.cancelled)
+}
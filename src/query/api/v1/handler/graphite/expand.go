@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ExpandURL is the url for expanding graphite queries to metric paths.
+	ExpandURL = handler.RoutePrefixV1 + "/graphite/metrics/expand"
+)
+
+var (
+	// ExpandHTTPMethods is the HTTP methods used with this resource.
+	ExpandHTTPMethods = []string{http.MethodGet, http.MethodPost}
+)
+
+type grahiteExpandHandler struct {
+	finder *finder
+}
+
+// NewExpandHandler returns a new instance of handler.
+func NewExpandHandler(
+	storage storage.Storage,
+	opts FindOptions,
+) http.Handler {
+	return &grahiteExpandHandler{
+		finder: newFinder(storage, opts),
+	}
+}
+
+func (h *grahiteExpandHandler) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+	w.Header().Set("Content-Type", "application/json")
+
+	params, rErr := parseExpandParams(r)
+	if rErr != nil {
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	paths := make(map[string][]string, len(params.queries))
+	for _, query := range params.queries {
+		seenMap, err := h.finder.find(ctx, query, params.from, params.until)
+		if err != nil {
+			logger.Error("unable to complete tags", zap.Error(err))
+			xhttp.Error(w, err, http.StatusBadRequest)
+			return
+		}
+
+		nodes := newFindNodes(seenMap, params.leavesOnly)
+		queryPaths := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			queryPaths = append(queryPaths, node.path)
+		}
+
+		paths[query] = queryPaths
+	}
+
+	err := expandResultsJSON(w, params.queries, paths, params.groupByExpr)
+	if err != nil {
+		logger.Error("unable to print expand results", zap.Error(err))
+		xhttp.Error(w, err, http.StatusBadRequest)
+	}
+}
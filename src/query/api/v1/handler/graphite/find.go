@@ -24,14 +24,11 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"sync"
 
 	"github.com/m3db/m3/src/query/api/v1/handler"
-	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/util/logging"
 	"github.com/m3db/m3/src/x/net/http"
-	xerrors "github.com/m3db/m3x/errors"
 
 	"go.uber.org/zap"
 )
@@ -47,15 +44,16 @@ var (
 )
 
 type grahiteFindHandler struct {
-	storage storage.Storage
+	finder *finder
 }
 
 // NewFindHandler returns a new instance of handler.
 func NewFindHandler(
 	storage storage.Storage,
+	opts FindOptions,
 ) http.Handler {
 	return &grahiteFindHandler{
-		storage: storage,
+		finder: newFinder(storage, opts),
 	}
 }
 
@@ -100,59 +98,31 @@ func (h *grahiteFindHandler) ServeHTTP(
 	logger := logging.WithContext(ctx)
 	w.Header().Set("Content-Type", "application/json")
 
-	// NB: need to run two separate queries, one of which will match only the
-	// provided matchers, and one which will match the provided matchers with at
-	// least one more child node. For further information, refer to the comment
-	// for parseFindParamsToQueries
-	terminatedQuery, childQuery, raw, rErr := parseFindParamsToQueries(r)
+	params, rErr := parseFindParams(r)
 	if rErr != nil {
 		xhttp.Error(w, rErr.Inner(), rErr.Code())
 		return
 	}
 
-	var (
-		terminatedResult *storage.CompleteTagsResult
-		tErr             error
-		childResult      *storage.CompleteTagsResult
-		cErr             error
-		opts             = storage.NewFetchOptions()
-
-		wg sync.WaitGroup
-	)
-
-	wg.Add(2)
-	go func() {
-		terminatedResult, tErr = h.storage.CompleteTags(ctx, terminatedQuery, opts)
-		wg.Done()
-	}()
-
-	go func() {
-		childResult, cErr = h.storage.CompleteTags(ctx, childQuery, opts)
-		wg.Done()
-	}()
-
-	wg.Wait()
-	if err := xerrors.FirstError(tErr, cErr); err != nil {
+	seenMap, err := h.finder.find(ctx, params.query, params.from, params.until)
+	if err != nil {
 		logger.Error("unable to complete tags", zap.Error(err))
 		xhttp.Error(w, err, http.StatusBadRequest)
 		return
 	}
 
-	// NB: merge results from both queries to specify which series have children
-	seenMap, err := mergeTags(terminatedResult, childResult)
-	if err != nil {
-		logger.Error("unable to complete tags", zap.Error(err))
-		xhttp.Error(w, err, http.StatusBadRequest)
-		return
+	nodes := newFindNodes(seenMap, params.leavesOnly)
+	if params.wildcards && len(nodes) > 1 {
+		nodes = append([]findNode{newWildcardNode(params.query, nodes)}, nodes...)
 	}
 
-	prefix := graphite.DropLastMetricPart(raw)
-	if len(prefix) > 0 {
-		prefix += "."
+	if params.format == completerFormat {
+		err = findResultsCompleterJSON(w, nodes)
+	} else {
+		err = findResultsJSON(w, nodes)
 	}
 
-	// TODO: Support multiple result types
-	if err = findResultsJSON(w, prefix, seenMap); err != nil {
+	if err != nil {
 		logger.Error("unable to print find results", zap.Error(err))
 		xhttp.Error(w, err, http.StatusBadRequest)
 	}
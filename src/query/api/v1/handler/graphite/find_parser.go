@@ -24,6 +24,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/m3db/m3/src/query/errors"
@@ -35,35 +38,113 @@ import (
 	"github.com/m3db/m3/src/x/net/http"
 )
 
-// parseFindParamsToQueries parses an incoming request to two find queries,
-// which are then combined to give the final result.
-// It returns, in order:
-//  the given query; this will return all values for exactly that tag which have
-// _terminatedQuery, which adds an explicit terminator after the last term in
-//  no child nodes
-// _childQuery, which adds an explicit match all after the last term in the
-// given query; this will return all values for exactly that tag which have at
-// least one child node.
-// _rawQueryString, which is the initial query request (bar final
-// matcher), which  is used to reconstruct the return values.
-// _err, any error encountered during parsing.
-//
-// As an example, given the query `a.b*`, and metrics `a.bar.c` and `a.biz`,
-// terminatedQuery will return only [biz], and childQuery will return only
-// [bar].
-func parseFindParamsToQueries(r *http.Request) (
-	_terminatedQuery *storage.CompleteTagsQuery,
-	_childQuery *storage.CompleteTagsQuery,
-	_rawQueryString string,
-	_err *xhttp.ParseError,
-) {
-	values := r.URL.Query()
-	query := values.Get("query")
-	if query == "" {
-		return nil, nil, "",
-			xhttp.NewParseError(errors.ErrNoQueryFound, http.StatusBadRequest)
+const (
+	treeJSONFormat  = "treejson"
+	completerFormat = "completer"
+)
+
+// findParams are the parameters of a find request.
+type findParams struct {
+	query      string
+	from       time.Time
+	until      time.Time
+	format     string
+	leavesOnly bool
+	wildcards  bool
+}
+
+// parseFindParams parses the parameters of a find request. Completer requests
+// match anything beginning with the query, as graphite-web does.
+func parseFindParams(r *http.Request) (findParams, *xhttp.ParseError) {
+	var (
+		p   findParams
+		err *xhttp.ParseError
+	)
+
+	p.query = r.FormValue("query")
+	if p.query == "" {
+		return p, xhttp.NewParseError(errors.ErrNoQueryFound, http.StatusBadRequest)
+	}
+
+	p.format = r.FormValue("format")
+	switch p.format {
+	case "", treeJSONFormat:
+	case completerFormat:
+		p.query = strings.Replace(p.query, "..", "*.", -1)
+		if !strings.HasSuffix(p.query, "*") {
+			p.query += "*"
+		}
+	default:
+		return p, xhttp.NewParseError(fmt.Errorf("invalid 'format': %s",
+			p.format), http.StatusBadRequest)
+	}
+
+	if p.from, p.until, err = parseFindTimeRange(r); err != nil {
+		return p, err
+	}
+
+	if p.leavesOnly, err = parseFindBoolParam(r, "leavesOnly"); err != nil {
+		return p, err
+	}
+
+	if p.wildcards, err = parseFindBoolParam(r, "wildcards"); err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// expandParams are the parameters of an expand request.
+type expandParams struct {
+	queries     []string
+	from        time.Time
+	until       time.Time
+	groupByExpr bool
+	leavesOnly  bool
+}
+
+// parseExpandParams parses the parameters of an expand request, which may
+// contain several queries.
+func parseExpandParams(r *http.Request) (expandParams, *xhttp.ParseError) {
+	var (
+		p   expandParams
+		err *xhttp.ParseError
+	)
+
+	if err := r.ParseForm(); err != nil {
+		return p, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	seen := make(map[string]struct{})
+	for _, query := range r.Form["query"] {
+		if _, ok := seen[query]; ok || query == "" {
+			continue
+		}
+
+		seen[query] = struct{}{}
+		p.queries = append(p.queries, query)
+	}
+
+	if len(p.queries) == 0 {
+		return p, xhttp.NewParseError(errors.ErrNoQueryFound, http.StatusBadRequest)
+	}
+
+	if p.from, p.until, err = parseFindTimeRange(r); err != nil {
+		return p, err
+	}
+
+	if p.groupByExpr, err = parseFindBoolParam(r, "groupByExpr"); err != nil {
+		return p, err
+	}
+
+	if p.leavesOnly, err = parseFindBoolParam(r, "leavesOnly"); err != nil {
+		return p, err
 	}
 
+	return p, nil
+}
+
+func parseFindTimeRange(r *http.Request) (time.Time, time.Time, *xhttp.ParseError) {
 	now := time.Now()
 	fromString, untilString := r.FormValue("from"), r.FormValue("until")
 	if len(fromString) == 0 {
@@ -81,7 +162,7 @@ func parseFindParamsToQueries(r *http.Request) (
 	)
 
 	if err != nil {
-		return nil, nil, "",
+		return time.Time{}, time.Time{},
 			xhttp.NewParseError(fmt.Errorf("invalid 'from': %s", fromString),
 				http.StatusBadRequest)
 	}
@@ -93,25 +174,65 @@ func parseFindParamsToQueries(r *http.Request) (
 	)
 
 	if err != nil {
-		return nil, nil, "",
+		return time.Time{}, time.Time{},
 			xhttp.NewParseError(fmt.Errorf("invalid 'until': %s", untilString),
 				http.StatusBadRequest)
 	}
 
+	return from, until, nil
+}
+
+// parseFindBoolParam parses a flag given either as 0/1, as graphite-web
+// clients send them, or as true/false.
+func parseFindBoolParam(r *http.Request, name string) (bool, *xhttp.ParseError) {
+	value := r.FormValue(name)
+	if len(value) == 0 {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, xhttp.NewParseError(fmt.Errorf("invalid '%s': %s",
+			name, value), http.StatusBadRequest)
+	}
+
+	return b, nil
+}
+
+// newFindQueries converts a query, whose parts other than the last are all
+// literal, to two find queries which are then combined to give the final
+// result.
+// It returns, in order:
+// _terminatedQuery, which adds an explicit terminator after the last term in
+// the given query; this will return all values for exactly that tag which have
+// no child nodes.
+// _childQuery, which adds an explicit match all after the last term in the
+// given query; this will return all values for exactly that tag which have at
+// least one child node.
+// _err, any error encountered during parsing.
+//
+// As an example, given the query `a.b*`, and metrics `a.bar.c` and `a.biz`,
+// terminatedQuery will return only [biz], and childQuery will return only
+// [bar].
+func newFindQueries(
+	query string,
+	from time.Time,
+	until time.Time,
+) (
+	_terminatedQuery *storage.CompleteTagsQuery,
+	_childQuery *storage.CompleteTagsQuery,
+	_err error,
+) {
 	matchers, err := graphiteStorage.TranslateQueryToMatchersWithTerminator(query)
 	if err != nil {
-		return nil, nil, "",
-			xhttp.NewParseError(fmt.Errorf("invalid 'query': %s", query),
-				http.StatusBadRequest)
+		return nil, nil, fmt.Errorf("invalid 'query': %s", query)
 	}
 
 	// NB: Filter will always be the second last term in the matchers, and the
 	// matchers should always have a length of at least 2 (term + terminator)
 	// so this is a sanity check and unexpected in actual execution.
 	if len(matchers) < 2 {
-		return nil, nil, "", xhttp.NewParseError(fmt.Errorf("unable to parse "+
-			"'query': %s", query),
-			http.StatusBadRequest)
+		return nil, nil, fmt.Errorf("unable to parse 'query': %s", query)
 	}
 
 	filter := [][]byte{matchers[len(matchers)-2].Name}
@@ -136,29 +257,74 @@ func parseFindParamsToQueries(r *http.Request) (
 		End:              until,
 	}
 
-	return terminatedQuery, childQuery, query, nil
+	return terminatedQuery, childQuery, nil
+}
+
+// findNode is a node matched by a find query.
+type findNode struct {
+	path        string
+	name        string
+	hasChildren bool
+}
+
+// newFindNodes returns the matched nodes sorted by path, dropping nodes with
+// children if only leaves are requested.
+func newFindNodes(seenMap map[string]bool, leavesOnly bool) []findNode {
+	nodes := make([]findNode, 0, len(seenMap))
+	for path, hasChildren := range seenMap {
+		if leavesOnly && hasChildren {
+			continue
+		}
+
+		nodes = append(nodes, findNode{
+			path:        path,
+			name:        path[strings.LastIndexByte(path, '.')+1:],
+			hasChildren: hasChildren,
+		})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].path < nodes[j].path
+	})
+
+	return nodes
+}
+
+// newWildcardNode returns a node matching all of the given nodes, which
+// graphite-web clients offer as a choice alongside them.
+func newWildcardNode(query string, nodes []findNode) findNode {
+	prefix := graphite.DropLastMetricPart(query)
+	if len(prefix) > 0 {
+		prefix += "."
+	}
+
+	wildcard := findNode{path: prefix + "*", name: "*"}
+	for _, node := range nodes {
+		wildcard.hasChildren = wildcard.hasChildren || node.hasChildren
+	}
+
+	return wildcard
 }
 
 func findResultsJSON(
 	w io.Writer,
-	prefix string,
-	tags map[string]bool,
+	nodes []findNode,
 ) error {
 	jw := json.NewWriter(w)
 	jw.BeginArray()
 
-	for value, hasChildren := range tags {
+	for _, node := range nodes {
 		leaf := 1
-		if hasChildren {
+		if node.hasChildren {
 			leaf = 0
 		}
 		jw.BeginObject()
 
 		jw.BeginObjectField("id")
-		jw.WriteString(fmt.Sprintf("%s%s", prefix, value))
+		jw.WriteString(node.path)
 
 		jw.BeginObjectField("text")
-		jw.WriteString(value)
+		jw.WriteString(node.name)
 
 		jw.BeginObjectField("leaf")
 		jw.WriteInt(leaf)
@@ -175,3 +341,86 @@ func findResultsJSON(
 	jw.EndArray()
 	return jw.Close()
 }
+
+// findResultsCompleterJSON writes nodes in the format used by graphite-web
+// autocompletion, where paths of nodes with children end in a delimiter.
+func findResultsCompleterJSON(
+	w io.Writer,
+	nodes []findNode,
+) error {
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+
+	jw.BeginObjectField("metrics")
+	jw.BeginArray()
+
+	for _, node := range nodes {
+		path, isLeaf := node.path, "1"
+		if node.hasChildren {
+			path, isLeaf = path+".", "0"
+		}
+		jw.BeginObject()
+
+		jw.BeginObjectField("path")
+		jw.WriteString(path)
+
+		jw.BeginObjectField("name")
+		jw.WriteString(node.name)
+
+		jw.BeginObjectField("is_leaf")
+		jw.WriteString(isLeaf)
+
+		jw.EndObject()
+	}
+
+	jw.EndArray()
+	jw.EndObject()
+	return jw.Close()
+}
+
+// expandResultsJSON writes the paths matched by each query, either merged
+// into a single list or keyed by query if grouped.
+func expandResultsJSON(
+	w io.Writer,
+	queries []string,
+	paths map[string][]string,
+	groupByExpr bool,
+) error {
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+
+	jw.BeginObjectField("results")
+	if groupByExpr {
+		jw.BeginObject()
+		for _, query := range queries {
+			jw.BeginObjectField(query)
+			writeStringArray(jw, paths[query])
+		}
+		jw.EndObject()
+	} else {
+		var merged []string
+		seen := make(map[string]struct{})
+		for _, query := range queries {
+			for _, path := range paths[query] {
+				if _, ok := seen[path]; !ok {
+					seen[path] = struct{}{}
+					merged = append(merged, path)
+				}
+			}
+		}
+
+		sort.Strings(merged)
+		writeStringArray(jw, merged)
+	}
+
+	jw.EndObject()
+	return jw.Close()
+}
+
+func writeStringArray(jw *json.Writer, values []string) {
+	jw.BeginArray()
+	for _, value := range values {
+		jw.WriteString(value)
+	}
+	jw.EndArray()
+}
@@ -25,6 +25,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"sort"
 	"strings"
@@ -57,6 +58,7 @@ var (
 )
 
 type completeTagQueryMatcher struct {
+	filter   string
 	matchers []models.Matcher
 }
 
@@ -85,8 +87,13 @@ func (m *completeTagQueryMatcher) Matches(x interface{}) bool {
 		return false
 	}
 
-	// both queries should filter on __g1__
-	if !bytes.Equal(q.FilterNameTags[0], []byte("__g1__")) {
+	// both queries should filter on __g1__ unless otherwise specified
+	filter := m.filter
+	if filter == "" {
+		filter = "__g1__"
+	}
+
+	if !bytes.Equal(q.FilterNameTags[0], []byte(filter)) {
 		return false
 	}
 
@@ -121,7 +128,7 @@ func bs(ss ...string) [][]byte {
 	return bb
 }
 
-func setupStorage(ctrl *gomock.Controller) storage.Storage {
+func setupStorage(ctrl *gomock.Controller) *storage.MockStorage {
 	store := storage.NewMockStorage(ctrl)
 	// set up no children case
 	noChildrenMatcher := &completeTagQueryMatcher{
@@ -205,7 +212,7 @@ func TestFind(t *testing.T) {
 
 	// setup storage and handler
 	store := setupStorage(ctrl)
-	handler := NewFindHandler(store)
+	handler := NewFindHandler(store, FindOptions{})
 
 	// execute the query
 	w := &writer{}
@@ -243,3 +250,292 @@ func TestFind(t *testing.T) {
 
 	require.Equal(t, expected, r)
 }
+
+// expectLevel sets up the terminated and child queries completing the last
+// part of a query, filtered on the given tag.
+func expectLevel(
+	store *storage.MockStorage,
+	filter string,
+	matchers []models.Matcher,
+	noChildren []string,
+	withChildren []string,
+) {
+	terminatedMatchers := append(matchers, models.Matcher{
+		Type: models.MatchNotRegexp, Name: b(nextTag(filter)), Value: b(".*")})
+	store.EXPECT().CompleteTags(gomock.Any(),
+		&completeTagQueryMatcher{filter: filter, matchers: terminatedMatchers},
+		gomock.Any()).
+		Return(&storage.CompleteTagsResult{
+			CompletedTags: []storage.CompletedTag{
+				{Name: b(filter), Values: bs(noChildren...)},
+			},
+		}, nil)
+
+	childMatchers := append([]models.Matcher{}, matchers...)
+	childMatchers = append(childMatchers, models.Matcher{
+		Type: models.MatchRegexp, Name: b(nextTag(filter)), Value: b(".*")})
+	store.EXPECT().CompleteTags(gomock.Any(),
+		&completeTagQueryMatcher{filter: filter, matchers: childMatchers},
+		gomock.Any()).
+		Return(&storage.CompleteTagsResult{
+			CompletedTags: []storage.CompletedTag{
+				{Name: b(filter), Values: bs(withChildren...)},
+			},
+		}, nil)
+}
+
+func nextTag(tag string) string {
+	return map[string]string{"__g0__": "__g1__", "__g1__": "__g2__"}[tag]
+}
+
+func serveFind(h http.Handler, rawQuery string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, FindURL+"?"+rawQuery, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func findQuery(query string) string {
+	return fmt.Sprintf("query=%s&from=%s&until=%s", url.QueryEscape(query),
+		from.s, until.s)
+}
+
+func decodeFindResults(t *testing.T, w *httptest.ResponseRecorder) results {
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var r results
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&r))
+	return r
+}
+
+func TestFindGlobPrefix(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := setupStorage(ctrl)
+	expectLevel(store, "__g0__", []models.Matcher{
+		{Type: models.MatchRegexp, Name: b("__g0__"), Value: b(`f[^\.]*`)},
+	}, []string{"fizz"}, []string{"foo"})
+
+	h := NewFindHandler(store, FindOptions{})
+	r := decodeFindResults(t, serveFind(h, findQuery("f*.b*")))
+
+	ids := make([]string, 0, len(r))
+	for _, result := range r {
+		ids = append(ids, result.ID)
+	}
+
+	require.Equal(t, []string{"foo.bar", "foo.baz", "foo.bix", "foo.bug"}, ids)
+}
+
+func TestFindBraceExpansion(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// NB: the brace level is expanded without querying storage.
+	store := setupStorage(ctrl)
+	expectLevel(store, "__g1__", []models.Matcher{
+		{Type: models.MatchEqual, Name: b("__g0__"), Value: b("qux")},
+		{Type: models.MatchRegexp, Name: b("__g1__"), Value: b(`b[^\.]*`)},
+	}, []string{"bop"}, nil)
+
+	h := NewFindHandler(store, FindOptions{})
+	r := decodeFindResults(t, serveFind(h, findQuery("{foo,qux}.b*")))
+
+	ids := make([]string, 0, len(r))
+	for _, result := range r {
+		ids = append(ids, result.ID)
+	}
+
+	require.Equal(t, []string{"foo.bar", "foo.baz", "foo.bix", "foo.bug",
+		"qux.bop"}, ids)
+}
+
+func TestFindLeavesOnlyAndWildcards(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewFindHandler(setupStorage(ctrl), FindOptions{})
+	r := decodeFindResults(t, serveFind(h, findQuery("foo.b*")+"&wildcards=1"))
+	require.Equal(t, 5, len(r))
+	require.Equal(t, result{ID: "foo.*", Text: "*", Leaf: 0, Expandable: 1,
+		AllowChildren: 1}, r[0])
+
+	h = NewFindHandler(setupStorage(ctrl), FindOptions{})
+	r = decodeFindResults(t, serveFind(h, findQuery("foo.b*")+"&leavesOnly=1"))
+	require.Equal(t, results{
+		{ID: "foo.bar", Text: "bar", Leaf: 1},
+	}, r)
+}
+
+func TestFindCompleter(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// NB: completer queries match anything beginning with the query.
+	h := NewFindHandler(setupStorage(ctrl), FindOptions{})
+	w := serveFind(h, findQuery("foo.b")+"&format=completer")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	type metric struct {
+		Path   string `json:"path"`
+		Name   string `json:"name"`
+		IsLeaf string `json:"is_leaf"`
+	}
+
+	var r struct {
+		Metrics []metric `json:"metrics"`
+	}
+
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&r))
+	require.Equal(t, []metric{
+		{Path: "foo.bar", Name: "bar", IsLeaf: "1"},
+		{Path: "foo.baz.", Name: "baz", IsLeaf: "0"},
+		{Path: "foo.bix.", Name: "bix", IsLeaf: "0"},
+		{Path: "foo.bug.", Name: "bug", IsLeaf: "0"},
+	}, r.Metrics)
+}
+
+func TestFindInvalidParams(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewFindHandler(storage.NewMockStorage(ctrl), FindOptions{})
+	for _, rawQuery := range []string{
+		"",
+		findQuery("foo.b*") + "&format=pickle",
+		findQuery("foo.b*") + "&leavesOnly=maybe",
+		findQuery("foo.b*") + "&wildcards=maybe",
+	} {
+		w := serveFind(h, rawQuery)
+		require.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+	}
+}
+
+func TestFindMaxResults(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewFindHandler(setupStorage(ctrl), FindOptions{MaxResults: 3})
+	w := serveFind(h, findQuery("foo.b*"))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "limit of 3 nodes")
+}
+
+func TestFindCache(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	findCache, err := NewFindCache(10, time.Hour)
+	require.NoError(t, err)
+
+	// NB: storage is only expected to be queried once.
+	h := NewFindHandler(setupStorage(ctrl), FindOptions{Cache: findCache})
+	first := decodeFindResults(t, serveFind(h, findQuery("foo.b*")))
+	second := decodeFindResults(t, serveFind(h, findQuery("foo.b*")))
+	require.Equal(t, 4, len(first))
+	require.Equal(t, first, second)
+}
+
+func TestFindCacheExpires(t *testing.T) {
+	findCache, err := NewFindCache(10, time.Minute)
+	require.NoError(t, err)
+
+	now := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	findCache.nowFn = func() time.Time { return now }
+
+	end := now
+	nodes := map[string]bool{"foo.bar": true}
+	findCache.set("foo.*", from.t, end, nodes)
+
+	cached, ok := findCache.get("foo.*", from.t, end)
+	require.True(t, ok)
+	require.Equal(t, nodes, cached)
+
+	now = now.Add(time.Minute)
+	_, ok = findCache.get("foo.*", from.t, end)
+	require.False(t, ok)
+}
+
+func TestExpandLiteralPart(t *testing.T) {
+	tests := []struct {
+		part     string
+		expected []string
+		ok       bool
+	}{
+		{part: "foo", expected: []string{"foo"}, ok: true},
+		{part: "{foo,bar}", expected: []string{"foo", "bar"}, ok: true},
+		{part: "a{b,c}d{e,f}", expected: []string{"abde", "abdf", "acde", "acdf"}, ok: true},
+		{part: "f*"},
+		{part: "{foo,b?r}"},
+		{part: "[ab]c"},
+		{part: "{foo,bar"},
+		{part: "foo}"},
+	}
+
+	for _, tt := range tests {
+		expanded, ok := expandLiteralPart(tt.part)
+		require.Equal(t, tt.ok, ok, tt.part)
+		require.Equal(t, tt.expected, expanded, tt.part)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setupExpandStorage := func() *storage.MockStorage {
+		store := setupStorage(ctrl)
+		expectLevel(store, "__g1__", []models.Matcher{
+			{Type: models.MatchEqual, Name: b("__g0__"), Value: b("qux")},
+			{Type: models.MatchRegexp, Name: b("__g1__"), Value: b(`b[^\.]*`)},
+		}, []string{"bar"}, nil)
+		return store
+	}
+
+	serveExpand := func(h http.Handler, rawQuery string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, ExpandURL+"?"+rawQuery, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w
+	}
+
+	rawQuery := findQuery("foo.b*") + "&query=qux.b*"
+
+	h := NewExpandHandler(setupExpandStorage(), FindOptions{})
+	var merged struct {
+		Results []string `json:"results"`
+	}
+
+	w := serveExpand(h, rawQuery+"&leavesOnly=1")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&merged))
+	require.Equal(t, []string{"foo.bar", "qux.bar"}, merged.Results)
+
+	h = NewExpandHandler(setupExpandStorage(), FindOptions{})
+	var grouped struct {
+		Results map[string][]string `json:"results"`
+	}
+
+	w = serveExpand(h, rawQuery+"&groupByExpr=1")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&grouped))
+	require.Equal(t, map[string][]string{
+		"foo.b*": {"foo.bar", "foo.baz", "foo.bix", "foo.bug"},
+		"qux.b*": {"qux.bar"},
+	}, grouped.Results)
+}
@@ -0,0 +1,370 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/query/cache"
+	"github.com/m3db/m3/src/query/graphite/graphite"
+	graphitestorage "github.com/m3db/m3/src/query/graphite/storage"
+	"github.com/m3db/m3/src/query/storage"
+
+	"github.com/m3db/m3x/clock"
+	xerrors "github.com/m3db/m3x/errors"
+)
+
+// FindOptions are the options for the find and expand handlers.
+type FindOptions struct {
+	// PolicyResolver, if set, restricts completion of each path to the
+	// namespaces that the path is written to.
+	PolicyResolver *graphitestorage.PolicyResolver
+
+	// MaxResults limits the number of nodes a query may match, where zero
+	// or negative values imply no limit.
+	MaxResults int
+
+	// Cache, if set, caches the nodes completed for each level of a query.
+	Cache *FindCache
+}
+
+// FindCache caches the nodes completed for hot prefixes, so that repeated
+// browsing of the same tree does not query storage each time.
+type FindCache struct {
+	backend cache.Backend
+	ttl     time.Duration
+	nowFn   clock.NowFn
+}
+
+type findCacheEntry struct {
+	Expires time.Time       `json:"expires"`
+	Nodes   map[string]bool `json:"nodes"`
+}
+
+// NewFindCache returns a cache holding at most size completed queries, each
+// for at most ttl.
+func NewFindCache(size int, ttl time.Duration) (*FindCache, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("must provide a positive ttl, instead got: %v", ttl)
+	}
+
+	backend, err := cache.NewLRUBackend(cache.BackendLimits{MaxEntries: size})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FindCache{
+		backend: backend,
+		ttl:     ttl,
+		nowFn:   time.Now,
+	}, nil
+}
+
+// NB: the range is truncated to the ttl so that queries relative to now
+// share entries for the lifetime of each entry.
+func (c *FindCache) key(query string, start, end time.Time) string {
+	return fmt.Sprintf("%s|%d|%d", query,
+		start.Truncate(c.ttl).UnixNano(), end.Truncate(c.ttl).UnixNano())
+}
+
+// NB: cache errors are treated as misses since the cache is only an
+// optimization.
+func (c *FindCache) get(query string, start, end time.Time) (map[string]bool, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	value, ok, err := c.backend.Get(c.key(query, start, end))
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	var entry findCacheEntry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return nil, false
+	}
+
+	if !c.nowFn().Before(entry.Expires) {
+		return nil, false
+	}
+
+	return entry.Nodes, true
+}
+
+func (c *FindCache) set(query string, start, end time.Time, nodes map[string]bool) {
+	if c == nil {
+		return
+	}
+
+	value, err := json.Marshal(findCacheEntry{
+		Expires: c.nowFn().Add(c.ttl),
+		Nodes:   nodes,
+	})
+	if err != nil {
+		return
+	}
+
+	// NB: as with reads, a failed write only results in a later miss.
+	_ = c.backend.Set(c.key(query, start, end), value)
+}
+
+// finder expands graphite path queries into the nodes they match, using
+// complete tags queries over the `__gN__` tags one level at a time.
+type finder struct {
+	storage storage.Storage
+	opts    FindOptions
+}
+
+func newFinder(storage storage.Storage, opts FindOptions) *finder {
+	return &finder{
+		storage: storage,
+		opts:    opts,
+	}
+}
+
+// find returns the paths of all nodes matching the query, and whether each
+// of those nodes has children.
+//
+// Each level before the last is resolved to concrete prefixes first, so
+// that returned paths never contain globs; literal levels, including brace
+// alternatives such as `{a,b}`, are expanded without querying storage.
+func (f *finder) find(
+	ctx context.Context,
+	query string,
+	start time.Time,
+	end time.Time,
+) (map[string]bool, error) {
+	parts := strings.Split(query, ".")
+	prefixes := []string{""}
+	for _, part := range parts[:len(parts)-1] {
+		var next []string
+		if alternatives, ok := expandLiteralPart(part); ok {
+			for _, prefix := range prefixes {
+				for _, alternative := range alternatives {
+					next = append(next, prefix+alternative+".")
+				}
+			}
+		} else {
+			for _, prefix := range prefixes {
+				nodes, err := f.completeLevel(ctx, prefix+part, start, end)
+				if err != nil {
+					return nil, err
+				}
+
+				for path, hasChildren := range nodes {
+					if hasChildren {
+						next = append(next, path+".")
+					}
+				}
+			}
+		}
+
+		if err := f.checkLimit(len(next)); err != nil {
+			return nil, err
+		}
+
+		prefixes = next
+	}
+
+	last := parts[len(parts)-1]
+	results := make(map[string]bool)
+	for _, prefix := range prefixes {
+		nodes, err := f.completeLevel(ctx, prefix+last, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		for path, hasChildren := range nodes {
+			results[path] = results[path] || hasChildren
+		}
+
+		if err := f.checkLimit(len(results)); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+func (f *finder) checkLimit(matched int) error {
+	if f.opts.MaxResults > 0 && matched > f.opts.MaxResults {
+		return fmt.Errorf("query matched more than the limit of %d nodes",
+			f.opts.MaxResults)
+	}
+
+	return nil
+}
+
+// completeLevel returns the nodes matching the last part of a query whose
+// other parts are all literal, keyed by their full paths.
+func (f *finder) completeLevel(
+	ctx context.Context,
+	query string,
+	start time.Time,
+	end time.Time,
+) (map[string]bool, error) {
+	if nodes, ok := f.opts.Cache.get(query, start, end); ok {
+		return nodes, nil
+	}
+
+	// NB: need to run two separate queries, one of which will match only the
+	// provided matchers, and one which will match the provided matchers with
+	// at least one more child node. For further information, refer to the
+	// comment for newFindQueries.
+	terminatedQuery, childQuery, err := newFindQueries(query, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var seenMap map[string]bool
+	if f.opts.PolicyResolver == nil {
+		seenMap, err = f.completeTags(ctx, terminatedQuery, childQuery,
+			storage.NewFetchOptions())
+	} else {
+		seenMap, err = f.completeTagsWithPolicyResolver(ctx, terminatedQuery,
+			childQuery)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := graphite.DropLastMetricPart(query)
+	if len(prefix) > 0 {
+		prefix += "."
+	}
+
+	nodes := make(map[string]bool, len(seenMap))
+	for value, hasChildren := range seenMap {
+		nodes[prefix+value] = hasChildren
+	}
+
+	f.opts.Cache.set(query, start, end, nodes)
+	return nodes, nil
+}
+
+// completeTags runs the terminated and child queries, merging the results to
+// specify which series have children.
+func (f *finder) completeTags(
+	ctx context.Context,
+	terminatedQuery *storage.CompleteTagsQuery,
+	childQuery *storage.CompleteTagsQuery,
+	opts *storage.FetchOptions,
+) (map[string]bool, error) {
+	var (
+		terminatedResult *storage.CompleteTagsResult
+		tErr             error
+		childResult      *storage.CompleteTagsResult
+		cErr             error
+
+		wg sync.WaitGroup
+	)
+
+	wg.Add(2)
+	go func() {
+		terminatedResult, tErr = f.storage.CompleteTags(ctx, terminatedQuery, opts)
+		wg.Done()
+	}()
+
+	go func() {
+		childResult, cErr = f.storage.CompleteTags(ctx, childQuery, opts)
+		wg.Done()
+	}()
+
+	wg.Wait()
+	if err := xerrors.FirstError(tErr, cErr); err != nil {
+		return nil, err
+	}
+
+	return mergeTags(terminatedResult, childResult)
+}
+
+// completeTagsWithPolicyResolver completes tags in the namespace of each
+// rule, rather than across all namespaces.
+func (f *finder) completeTagsWithPolicyResolver(
+	ctx context.Context,
+	terminatedQuery *storage.CompleteTagsQuery,
+	childQuery *storage.CompleteTagsQuery,
+) (map[string]bool, error) {
+	seenMap := make(map[string]bool)
+	for _, p := range f.opts.PolicyResolver.Policies(terminatedQuery.Start) {
+		opts := storage.NewFetchOptions()
+		opts.RestrictFetchOptions = graphitestorage.NewRestrictFetchOptions(p)
+
+		policySeenMap, err := f.completeTags(ctx, terminatedQuery, childQuery, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for value, hasChildren := range policySeenMap {
+			// NB: a value has children if it has children in any namespace.
+			seenMap[value] = seenMap[value] || hasChildren
+		}
+	}
+
+	return seenMap, nil
+}
+
+// expandLiteralPart returns the values a query part can take if it contains
+// no wildcards, expanding any brace alternatives such as `a{b,c}`.
+func expandLiteralPart(part string) ([]string, bool) {
+	if strings.ContainsAny(part, "*?[]") {
+		return nil, false
+	}
+
+	left := strings.IndexByte(part, '{')
+	if left < 0 {
+		if strings.IndexByte(part, '}') >= 0 {
+			return nil, false
+		}
+
+		return []string{part}, true
+	}
+
+	right := strings.IndexByte(part[left:], '}')
+	if right < 0 {
+		return nil, false
+	}
+
+	right += left
+	suffixes, ok := expandLiteralPart(part[right+1:])
+	if !ok {
+		return nil, false
+	}
+
+	var expanded []string
+	for _, alternative := range strings.Split(part[left+1:right], ",") {
+		if strings.IndexByte(alternative, '{') >= 0 {
+			return nil, false
+		}
+
+		for _, suffix := range suffixes {
+			expanded = append(expanded, part[:left]+alternative+suffix)
+		}
+	}
+
+	return expanded, true
+}
@@ -60,12 +60,16 @@ type respError struct {
 	code int
 }
 
-// NewRenderHandler returns a new render handler around the given storage.
+// NewRenderHandler returns a new render handler around the given storage. If
+// the policy resolver is set, each path is only fetched from the namespace
+// that it is written to.
 func NewRenderHandler(
 	storage storage.Storage,
 	enforcer cost.ChainedEnforcer,
+	resolver *graphite.PolicyResolver,
 ) http.Handler {
-	wrappedStore := graphite.NewM3WrappedStorage(storage, enforcer)
+	wrappedStore := graphite.NewM3WrappedStorage(storage, enforcer,
+		graphite.M3WrappedStorageOptions{PolicyResolver: resolver})
 	return &renderHandler{
 		engine: native.NewEngine(wrappedStore),
 	}
@@ -78,6 +82,26 @@ func sendError(errorCh chan error, err error) {
 	}
 }
 
+// consolidateSeries reduces a series to at most maxDataPoints values. Series
+// with an explicit consolidation function (e.g. from consolidateBy) are
+// resized using that function, all others are downsampled with LTTB.
+func consolidateSeries(s *ts.Series, maxDataPoints int64) (*ts.Series, error) {
+	if int64(s.Len()) <= maxDataPoints {
+		return s, nil
+	}
+
+	var (
+		samplingMultiplier = math.Ceil(float64(s.Len()) / float64(maxDataPoints))
+		newMillisPerStep   = int(samplingMultiplier * float64(s.MillisPerStep()))
+	)
+	if !s.IsConsolidationFuncSet() {
+		return ts.LTTB(s, s.StartTime(), s.EndTime(), newMillisPerStep), nil
+	}
+
+	return s.IntersectAndResize(s.StartTime(), s.EndTime(), newMillisPerStep,
+		s.ConsolidationFunc())
+}
+
 // ServeHTTP processes the render requests.
 func (h *renderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	respErr := h.serveHTTP(w, r)
@@ -146,15 +170,13 @@ func (h *renderHandler) serveHTTP(
 			}
 
 			for i, s := range targetSeries.Values {
-				if s.Len() <= int(p.MaxDataPoints) {
-					continue
+				consolidated, err := consolidateSeries(s, p.MaxDataPoints)
+				if err != nil {
+					sendError(errorCh, errors.NewRenamedError(err,
+						fmt.Errorf("error: target %s could not be consolidated: %s", target, err)))
+					return
 				}
-
-				var (
-					samplingMultiplier = math.Ceil(float64(s.Len()) / float64(p.MaxDataPoints))
-					newMillisPerStep   = int(samplingMultiplier * float64(s.MillisPerStep()))
-				)
-				targetSeries.Values[i] = ts.LTTB(s, s.StartTime(), s.EndTime(), newMillisPerStep)
+				targetSeries.Values[i] = consolidated
 			}
 
 			mu.Lock()
@@ -194,6 +216,8 @@ func (h *renderHandler) serveHTTP(
 		SortApplied: true,
 	}
 
-	err = WriteRenderResponse(w, response, p.Format)
+	err = WriteRenderResponse(w, response, p.Format, RenderResponseOptions{
+		NoNullPoints: p.NoNullPoints,
+	})
 	return respError{err: err, code: http.StatusOK}
 }
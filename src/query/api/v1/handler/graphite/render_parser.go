@@ -21,6 +21,8 @@
 package graphite
 
 import (
+	"bufio"
+	"encoding/csv"
 	"fmt"
 	"io"
 	"math"
@@ -40,6 +42,9 @@ const (
 	queryRangeShiftThreshold = 55 * time.Minute
 	queryRangeShift          = 15 * time.Second
 	pickleFormat             = "pickle"
+	csvFormat                = "csv"
+	rawFormat                = "raw"
+	csvTimeFormat            = "2006-01-02 15:04:05"
 )
 
 var (
@@ -47,20 +52,35 @@ var (
 	errFromNotBeforeUntil = errors.NewInvalidParamsError(errors.New("'from' must come before 'until'"))
 )
 
+// RenderResponseOptions are the options used when writing a render response.
+type RenderResponseOptions struct {
+	// NoNullPoints drops null datapoints from the json and csv formats, and
+	// omits json series that have no datapoints left.
+	NoNullPoints bool
+}
+
 // WriteRenderResponse writes the response to a render request
 func WriteRenderResponse(
 	w http.ResponseWriter,
 	series ts.SeriesList,
 	format string,
+	opts RenderResponseOptions,
 ) error {
-	if format == pickleFormat {
+	switch format {
+	case pickleFormat:
 		w.Header().Set("Content-Type", "application/octet-stream")
 		return renderResultsPickle(w, series.Values)
+	case csvFormat:
+		w.Header().Set("Content-Type", "text/csv")
+		return renderResultsCSV(w, series.Values, opts)
+	case rawFormat:
+		w.Header().Set("Content-Type", "text/plain")
+		return renderResultsRaw(w, series.Values)
 	}
 
-	// NB: return json unless requesting specifically `pickleFormat`
+	// NB: return json unless requesting specifically another known format
 	w.Header().Set("Content-Type", "application/json")
-	return renderResultsJSON(w, series.Values)
+	return renderResultsJSON(w, series.Values, opts)
 }
 
 const (
@@ -78,6 +98,7 @@ type RenderRequest struct {
 	MaxDataPoints int64
 	Compare       time.Duration
 	Timeout       time.Duration
+	NoNullPoints  bool
 }
 
 // ParseRenderRequest parses the arguments to a render call from an incoming request.
@@ -98,6 +119,8 @@ func ParseRenderRequest(r *http.Request) (RenderRequest, error) {
 		return p, errNoTarget
 	}
 
+	p.Format = r.FormValue("format")
+
 	fromString, untilString := r.FormValue("from"), r.FormValue("until")
 	if len(fromString) == 0 {
 		fromString = "-30min"
@@ -163,6 +186,14 @@ func ParseRenderRequest(r *http.Request) (RenderRequest, error) {
 		p.MaxDataPoints = math.MaxInt64
 	}
 
+	noNullPointsString := r.FormValue("noNullPoints")
+	if len(noNullPointsString) != 0 {
+		p.NoNullPoints, err = strconv.ParseBool(noNullPointsString)
+		if err != nil {
+			return p, errors.NewInvalidParamsError(fmt.Errorf("invalid 'noNullPoints': %s", noNullPointsString))
+		}
+	}
+
 	compareString := r.FormValue("compare")
 
 	if compareFrom, err := graphite.ParseTime(
@@ -194,10 +225,18 @@ func ParseRenderRequest(r *http.Request) (RenderRequest, error) {
 	return p, nil
 }
 
-func renderResultsJSON(w io.Writer, series []*ts.Series) error {
+func renderResultsJSON(
+	w io.Writer,
+	series []*ts.Series,
+	opts RenderResponseOptions,
+) error {
 	jw := json.NewWriter(w)
 	jw.BeginArray()
 	for _, s := range series {
+		if opts.NoNullPoints && s.AllNaN() {
+			continue
+		}
+
 		jw.BeginObject()
 		jw.BeginObjectField("target")
 		jw.WriteString(s.Name())
@@ -207,6 +246,10 @@ func renderResultsJSON(w io.Writer, series []*ts.Series) error {
 		if !s.AllNaN() {
 			for i := 0; i < s.Len(); i++ {
 				timestamp, val := s.StartTimeForStep(i), s.ValueAt(i)
+				if opts.NoNullPoints && math.IsNaN(val) {
+					continue
+				}
+
 				jw.BeginArray()
 				jw.WriteFloat64(val)
 				jw.WriteInt(int(timestamp.Unix()))
@@ -256,3 +299,63 @@ func renderResultsPickle(w io.Writer, series []*ts.Series) error {
 
 	return pw.Close()
 }
+
+func renderResultsCSV(
+	w io.Writer,
+	series []*ts.Series,
+	opts RenderResponseOptions,
+) error {
+	cw := csv.NewWriter(w)
+	for _, s := range series {
+		for i := 0; i < s.Len(); i++ {
+			timestamp, val := s.StartTimeForStep(i), s.ValueAt(i)
+			if opts.NoNullPoints && math.IsNaN(val) {
+				continue
+			}
+
+			record := []string{
+				s.Name(),
+				timestamp.UTC().Format(csvTimeFormat),
+				formatRawValue(val, ""),
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// renderResultsRaw writes each series on its own line as
+// name,start,end,step|value,value,... with null values written as None.
+func renderResultsRaw(w io.Writer, series []*ts.Series) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range series {
+		bw.WriteString(s.Name())
+		bw.WriteByte(',')
+		bw.WriteString(strconv.FormatInt(s.StartTime().UTC().Unix(), 10))
+		bw.WriteByte(',')
+		bw.WriteString(strconv.FormatInt(s.EndTime().UTC().Unix(), 10))
+		bw.WriteByte(',')
+		bw.WriteString(strconv.Itoa(s.MillisPerStep() / 1000))
+		bw.WriteByte('|')
+		for i := 0; i < s.Len(); i++ {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			bw.WriteString(formatRawValue(s.ValueAt(i), "None"))
+		}
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+func formatRawValue(v float64, null string) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return null
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -23,6 +23,7 @@ package graphite
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -39,7 +40,7 @@ import (
 
 func TestParseNoQuery(t *testing.T) {
 	mockStorage := mock.NewMockStorage()
-	handler := NewRenderHandler(mockStorage, nil)
+	handler := NewRenderHandler(mockStorage, nil, nil)
 
 	recorder := httptest.NewRecorder()
 	handler.ServeHTTP(recorder, newGraphiteReadHTTPRequest(t))
@@ -51,7 +52,7 @@ func TestParseNoQuery(t *testing.T) {
 func TestParseQueryNoResults(t *testing.T) {
 	mockStorage := mock.NewMockStorage()
 	mockStorage.SetFetchResult(&storage.FetchResult{}, nil)
-	handler := NewRenderHandler(mockStorage, nil)
+	handler := NewRenderHandler(mockStorage, nil, nil)
 
 	req := newGraphiteReadHTTPRequest(t)
 	req.URL.RawQuery = "target=foo.bar&from=-2h&until=now"
@@ -82,7 +83,7 @@ func TestParseQueryResults(t *testing.T) {
 	}
 
 	mockStorage.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
-	handler := NewRenderHandler(mockStorage, nil)
+	handler := NewRenderHandler(mockStorage, nil, nil)
 
 	req := newGraphiteReadHTTPRequest(t)
 	req.URL.RawQuery = fmt.Sprintf("target=foo.bar&from=%d&until=%d",
@@ -123,7 +124,7 @@ func TestParseQueryResultsMaxDatapoints(t *testing.T) {
 	}
 
 	mockStorage.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
-	handler := NewRenderHandler(mockStorage, nil)
+	handler := NewRenderHandler(mockStorage, nil, nil)
 
 	req := newGraphiteReadHTTPRequest(t)
 	req.URL.RawQuery = "target=foo.bar&from=" + startStr + "&until=" + endStr + "&maxDataPoints=1"
@@ -158,7 +159,7 @@ func TestParseQueryResultsMultiTarget(t *testing.T) {
 	}
 
 	mockStorage.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
-	handler := NewRenderHandler(mockStorage, nil)
+	handler := NewRenderHandler(mockStorage, nil, nil)
 
 	req := newGraphiteReadHTTPRequest(t)
 	req.URL.RawQuery = fmt.Sprintf("target=foo.bar&target=baz.qux&from=%d&until=%d",
@@ -183,6 +184,139 @@ func TestParseQueryResultsMultiTarget(t *testing.T) {
 	require.Equal(t, expected, string(buf))
 }
 
+func newRenderHandlerWithValues(
+	start time.Time,
+	values ...float64,
+) http.Handler {
+	mockStorage := mock.NewMockStorage()
+	resolution := 10 * time.Second
+	vals := ts.NewFixedStepValues(resolution, len(values), 0, start)
+	for i, v := range values {
+		vals.SetValueAt(i, v)
+	}
+
+	series := ts.NewSeries([]byte("a"), vals, models.NewTags(0, nil))
+	series.SetResolution(resolution)
+	mockStorage.SetFetchResult(&storage.FetchResult{
+		SeriesList: ts.SeriesList{series},
+	}, nil)
+	return NewRenderHandler(mockStorage, nil, nil)
+}
+
+func TestParseQueryResultsFormats(t *testing.T) {
+	start := time.Unix(time.Now().Add(-30*time.Minute).Unix(), 0)
+	csvTime := func(offset time.Duration) string {
+		return start.Add(offset).UTC().Format(csvTimeFormat)
+	}
+
+	tests := []struct {
+		query       string
+		contentType string
+		expected    string
+	}{
+		{
+			query:       "format=csv",
+			contentType: "text/csv",
+			expected: fmt.Sprintf("a,%s,1\na,%s,\na,%s,3.5\n",
+				csvTime(0), csvTime(10*time.Second), csvTime(20*time.Second)),
+		},
+		{
+			query:       "format=csv&noNullPoints=true",
+			contentType: "text/csv",
+			expected: fmt.Sprintf("a,%s,1\na,%s,3.5\n",
+				csvTime(0), csvTime(20*time.Second)),
+		},
+		{
+			query:       "format=raw",
+			contentType: "text/plain",
+			expected: fmt.Sprintf("a,%d,%d,10|1,None,3.5\n",
+				start.Unix(), start.Unix()+30),
+		},
+		{
+			query:       "noNullPoints=true",
+			contentType: "application/json",
+			expected: fmt.Sprintf(
+				`[{"target":"a","datapoints":[[1.000000,%d],`+
+					`[3.500000,%d]],"step_size_ms":10000}]`,
+				start.Unix(), start.Unix()+20),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			handler := newRenderHandlerWithValues(start, 1, math.NaN(), 3.5)
+
+			req := newGraphiteReadHTTPRequest(t)
+			req.URL.RawQuery = fmt.Sprintf("target=foo.bar&from=%d&until=%d&%s",
+				start.Unix(), start.Unix()+30, tt.query)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			res := recorder.Result()
+			require.Equal(t, 200, res.StatusCode)
+			require.Equal(t, tt.contentType, res.Header.Get("Content-Type"))
+
+			buf, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(buf))
+		})
+	}
+}
+
+func TestParseQueryNoNullPointsOmitsEmptySeries(t *testing.T) {
+	start := time.Unix(time.Now().Add(-30*time.Minute).Unix(), 0)
+	handler := newRenderHandlerWithValues(start, math.NaN(), math.NaN())
+
+	req := newGraphiteReadHTTPRequest(t)
+	req.URL.RawQuery = fmt.Sprintf("target=foo.bar&from=%d&until=%d&noNullPoints=true",
+		start.Unix(), start.Unix()+20)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	require.Equal(t, 200, res.StatusCode)
+
+	buf, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, "[]", string(buf))
+}
+
+func TestParseQueryInvalidNoNullPoints(t *testing.T) {
+	handler := NewRenderHandler(mock.NewMockStorage(), nil, nil)
+
+	req := newGraphiteReadHTTPRequest(t)
+	req.URL.RawQuery = "target=foo.bar&noNullPoints=bad"
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	require.Equal(t, 400, res.StatusCode)
+}
+
+func TestParseQueryResultsMaxDatapointsConsolidateBy(t *testing.T) {
+	start := time.Unix(time.Now().Add(-30*time.Minute).Unix(), 0)
+	handler := newRenderHandlerWithValues(start, 1, 2, 3, 4)
+
+	req := newGraphiteReadHTTPRequest(t)
+	req.URL.RawQuery = fmt.Sprintf(
+		"target=consolidateBy(foo.bar,'max')&from=%d&until=%d&maxDataPoints=2",
+		start.Unix(), start.Unix()+40)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	res := recorder.Result()
+	require.Equal(t, 200, res.StatusCode)
+
+	buf, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	expected := fmt.Sprintf(
+		`[{"target":"consolidateBy(a,\"max\")","datapoints":[[2.000000,%d],`+
+			`[4.000000,%d]],"step_size_ms":20000}]`,
+		start.Unix(), start.Unix()+20)
+	require.Equal(t, expected, string(buf))
+}
+
 func newGraphiteReadHTTPRequest(t *testing.T) *http.Request {
 	req, err := http.NewRequest(ReadHTTPMethods[0], ReadURL, nil)
 	require.NoError(t, err)
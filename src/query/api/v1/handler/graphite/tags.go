@@ -0,0 +1,324 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/graphite/graphite"
+	graphiteStorage "github.com/m3db/m3/src/query/graphite/storage"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	// TagsURL is the url for listing the tags of tagged graphite series.
+	TagsURL = handler.RoutePrefixV1 + "/graphite/tags"
+
+	// TagValuesURL is the url for listing the values of a graphite tag.
+	TagValuesURL = TagsURL + "/{" + tagNameVar + "}"
+
+	// AutoCompleteTagsURL is the url for auto-completing graphite tags.
+	AutoCompleteTagsURL = TagsURL + "/autoComplete/tags"
+
+	// AutoCompleteValuesURL is the url for auto-completing graphite tag values.
+	AutoCompleteValuesURL = TagsURL + "/autoComplete/values"
+
+	tagNameVar = "tag"
+
+	defaultAutoCompleteLimit = 100
+)
+
+var (
+	// TagsHTTPMethods is the HTTP methods used with the tags resources.
+	TagsHTTPMethods = []string{http.MethodGet, http.MethodPost}
+
+	// Every tagged series has a name tag, so matching it selects all tagged
+	// series.
+	allTaggedSeriesMatcher = models.Matcher{
+		Type:  models.MatchRegexp,
+		Name:  []byte(graphite.TaggedNameTag),
+		Value: []byte(".+"),
+	}
+)
+
+type tagsMode int
+
+const (
+	listTags tagsMode = iota
+	listTagValues
+	autoCompleteTags
+	autoCompleteValues
+)
+
+type graphiteTagsHandler struct {
+	storage storage.Storage
+	mode    tagsMode
+	nowFn   func() time.Time
+}
+
+// NewTagsHandler returns a new instance of handler listing the tags of tagged
+// series, optionally filtered by the regular expression in filter.
+func NewTagsHandler(storage storage.Storage) http.Handler {
+	return newTagsHandler(storage, listTags)
+}
+
+// NewTagValuesHandler returns a new instance of handler listing the values of
+// a tag, optionally filtered by the regular expression in filter.
+func NewTagValuesHandler(storage storage.Storage) http.Handler {
+	return newTagsHandler(storage, listTagValues)
+}
+
+// NewAutoCompleteTagsHandler returns a new instance of handler completing the
+// tags starting with tagPrefix, of series matching the tag expressions in expr.
+func NewAutoCompleteTagsHandler(storage storage.Storage) http.Handler {
+	return newTagsHandler(storage, autoCompleteTags)
+}
+
+// NewAutoCompleteValuesHandler returns a new instance of handler completing the
+// values of tag starting with valuePrefix, of series matching the tag
+// expressions in expr.
+func NewAutoCompleteValuesHandler(storage storage.Storage) http.Handler {
+	return newTagsHandler(storage, autoCompleteValues)
+}
+
+func newTagsHandler(storage storage.Storage, mode tagsMode) http.Handler {
+	return &graphiteTagsHandler{
+		storage: storage,
+		mode:    mode,
+		nowFn:   time.Now,
+	}
+}
+
+type tagsParams struct {
+	tag    string
+	filter *regexp.Regexp
+	prefix string
+	// exprTags are the tags constrained by the expressions of the request.
+	exprTags map[string]struct{}
+	limit    int
+}
+
+type tagResult struct {
+	Tag string `json:"tag"`
+}
+
+type tagValueResult struct {
+	Value string `json:"value"`
+}
+
+type tagValuesResult struct {
+	Tag    string           `json:"tag"`
+	Values []tagValueResult `json:"values"`
+}
+
+func (h *graphiteTagsHandler) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+	w.Header().Set("Content-Type", "application/json")
+
+	params, query, rErr := h.parseParams(r)
+	if rErr != nil {
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	result, err := h.storage.CompleteTags(ctx, query, storage.NewFetchOptions())
+	if err != nil {
+		logger.Error("unable to complete tags", zap.Error(err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	values := matchingValues(result, params)
+	switch h.mode {
+	case listTags:
+		tags := make([]tagResult, 0, len(values))
+		for _, v := range values {
+			tags = append(tags, tagResult{Tag: v})
+		}
+		xhttp.WriteJSONResponse(w, tags, logger)
+	case listTagValues:
+		tagValues := make([]tagValueResult, 0, len(values))
+		for _, v := range values {
+			tagValues = append(tagValues, tagValueResult{Value: v})
+		}
+		xhttp.WriteJSONResponse(w, tagValuesResult{
+			Tag:    params.tag,
+			Values: tagValues,
+		}, logger)
+	default:
+		xhttp.WriteJSONResponse(w, values, logger)
+	}
+}
+
+func (h *graphiteTagsHandler) parseParams(r *http.Request) (
+	tagsParams,
+	*storage.CompleteTagsQuery,
+	*xhttp.ParseError,
+) {
+	var params tagsParams
+	if err := r.ParseForm(); err != nil {
+		return params, nil, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	switch h.mode {
+	case listTags, listTagValues:
+		if filter := r.Form.Get("filter"); filter != "" {
+			// NB: graphite only anchors the filter at the start of the value.
+			re, err := regexp.Compile("^(?:" + filter + ")")
+			if err != nil {
+				return params, nil, xhttp.NewParseError(
+					fmt.Errorf("invalid 'filter': %s", filter), http.StatusBadRequest)
+			}
+			params.filter = re
+		}
+	case autoCompleteTags:
+		params.prefix = r.Form.Get("tagPrefix")
+	case autoCompleteValues:
+		params.prefix = r.Form.Get("valuePrefix")
+	}
+
+	switch h.mode {
+	case listTagValues:
+		params.tag = strings.TrimSpace(mux.Vars(r)[tagNameVar])
+	case autoCompleteValues:
+		params.tag = strings.TrimSpace(r.Form.Get("tag"))
+	}
+
+	if (h.mode == listTagValues || h.mode == autoCompleteValues) &&
+		params.tag == "" {
+		return params, nil, xhttp.NewParseError(
+			fmt.Errorf("missing 'tag'"), http.StatusBadRequest)
+	}
+
+	matchers := models.Matchers{allTaggedSeriesMatcher}
+	if h.mode == autoCompleteTags || h.mode == autoCompleteValues {
+		params.limit = defaultAutoCompleteLimit
+		if l := r.Form.Get("limit"); l != "" {
+			limit, err := strconv.Atoi(l)
+			if err != nil || limit <= 0 {
+				return params, nil, xhttp.NewParseError(
+					fmt.Errorf("invalid 'limit': %s", l), http.StatusBadRequest)
+			}
+			params.limit = limit
+		}
+
+		var (
+			rawExprs = r.Form["expr"]
+			exprs    = make([]graphite.TagExpression, 0, len(rawExprs))
+		)
+		params.exprTags = make(map[string]struct{}, len(rawExprs))
+		for _, e := range rawExprs {
+			expr, err := graphite.ParseTagExpression(e)
+			if err != nil {
+				return params, nil, xhttp.NewParseError(
+					fmt.Errorf("invalid 'expr': %s", e), http.StatusBadRequest)
+			}
+			exprs = append(exprs, expr)
+			params.exprTags[expr.Name] = struct{}{}
+		}
+
+		exprMatchers, err := graphiteStorage.TranslateTagExpressionsToMatchers(exprs)
+		if err != nil {
+			return params, nil, xhttp.NewParseError(err, http.StatusBadRequest)
+		}
+		matchers = append(matchers, exprMatchers...)
+	}
+
+	query := &storage.CompleteTagsQuery{
+		TagMatchers: matchers,
+		Start:       time.Unix(0, 0),
+		End:         h.nowFn(),
+	}
+
+	if params.tag != "" {
+		query.FilterNameTags = [][]byte{[]byte(params.tag)}
+	} else {
+		query.CompleteNameOnly = true
+	}
+
+	return params, query, nil
+}
+
+// matchingValues returns the sorted tag names, or values of the requested
+// tag, in the result which match the filter or prefix of the request.
+func matchingValues(
+	result *storage.CompleteTagsResult,
+	params tagsParams,
+) []string {
+	seen := make(map[string]struct{})
+	for _, tag := range result.CompletedTags {
+		candidates := [][]byte{tag.Name}
+		if params.tag != "" {
+			if string(tag.Name) != params.tag {
+				continue
+			}
+			candidates = tag.Values
+		}
+
+		for _, c := range candidates {
+			v := string(c)
+			if params.tag == "" {
+				// NB: tags already constrained by an expression are not worth
+				// completing.
+				if _, ok := params.exprTags[v]; ok {
+					continue
+				}
+			}
+			if params.filter != nil && !params.filter.MatchString(v) {
+				continue
+			}
+			if !strings.HasPrefix(v, params.prefix) {
+				continue
+			}
+			seen[v] = struct{}{}
+		}
+	}
+
+	values := make([]string, 0, len(seen))
+	for v := range seen {
+		values = append(values, v)
+	}
+
+	sort.Strings(values)
+	if params.limit > 0 && len(values) > params.limit {
+		values = values[:params.limit]
+	}
+
+	return values
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTagsResult = &storage.CompleteTagsResult{
+	CompletedTags: []storage.CompletedTag{
+		{Name: b("dc"), Values: bs("y", "x")},
+		{Name: b("host"), Values: bs("web-2", "web-1", "db-1")},
+		{Name: b("name"), Values: bs("cpu.load")},
+	},
+}
+
+func serveTags(
+	t *testing.T,
+	h http.Handler,
+	url string,
+	vars map[string]string,
+) *httptest.ResponseRecorder {
+	h.(*graphiteTagsHandler).nowFn = func() time.Time { return time.Unix(100, 0) }
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	if vars != nil {
+		req = mux.SetURLVars(req, vars)
+	}
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestTags(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), &storage.CompleteTagsQuery{
+		CompleteNameOnly: true,
+		TagMatchers:      models.Matchers{allTaggedSeriesMatcher},
+		Start:            time.Unix(0, 0),
+		End:              time.Unix(100, 0),
+	}, gomock.Any()).Return(testTagsResult, nil)
+
+	recorder := serveTags(t, NewTagsHandler(store), TagsURL+"?filter=d|h", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `[{"tag":"dc"},{"tag":"host"}]`, recorder.Body.String())
+}
+
+func TestTagValues(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), &storage.CompleteTagsQuery{
+		FilterNameTags: [][]byte{b("host")},
+		TagMatchers:    models.Matchers{allTaggedSeriesMatcher},
+		Start:          time.Unix(0, 0),
+		End:            time.Unix(100, 0),
+	}, gomock.Any()).Return(testTagsResult, nil)
+
+	recorder := serveTags(t, NewTagValuesHandler(store),
+		TagsURL+"/host?filter=web", map[string]string{tagNameVar: "host"})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"tag":"host","values":[{"value":"web-1"},{"value":"web-2"}]}`,
+		recorder.Body.String())
+}
+
+func TestAutoCompleteTags(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), &storage.CompleteTagsQuery{
+		CompleteNameOnly: true,
+		TagMatchers: models.Matchers{
+			allTaggedSeriesMatcher,
+			{Type: models.MatchEqual, Name: b("name"), Value: b("cpu.load")},
+		},
+		Start: time.Unix(0, 0),
+		End:   time.Unix(100, 0),
+	}, gomock.Any()).Return(testTagsResult, nil)
+
+	recorder := serveTags(t, NewAutoCompleteTagsHandler(store),
+		AutoCompleteTagsURL+"?expr=name%3Dcpu.load&limit=1", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `["dc"]`, recorder.Body.String())
+}
+
+func TestAutoCompleteValues(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), &storage.CompleteTagsQuery{
+		FilterNameTags: [][]byte{b("host")},
+		TagMatchers: models.Matchers{
+			allTaggedSeriesMatcher,
+			{Type: models.MatchRegexp, Name: b("dc"), Value: b("(?:x).*")},
+		},
+		Start: time.Unix(0, 0),
+		End:   time.Unix(100, 0),
+	}, gomock.Any()).Return(testTagsResult, nil)
+
+	recorder := serveTags(t, NewAutoCompleteValuesHandler(store),
+		AutoCompleteValuesURL+"?tag=host&valuePrefix=web&expr=dc%3D~x", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `["web-1","web-2"]`, recorder.Body.String())
+}
+
+func TestTagsInvalidParams(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	for _, test := range []struct {
+		h   http.Handler
+		url string
+	}{
+		{NewTagsHandler(store), TagsURL + "?filter=("},
+		{NewAutoCompleteValuesHandler(store), AutoCompleteValuesURL},
+		{NewAutoCompleteTagsHandler(store), AutoCompleteTagsURL + "?expr=dc"},
+		{NewAutoCompleteTagsHandler(store), AutoCompleteTagsURL + "?limit=0"},
+	} {
+		recorder := serveTags(t, test.h, test.url, nil)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, test.url)
+	}
+}
+
+func TestTagsStorageError(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMockStorage(ctrl)
+	store.EXPECT().CompleteTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("storage error"))
+
+	recorder := serveTags(t, NewTagsHandler(store), TagsURL, nil)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -33,6 +33,13 @@ const (
 	// DeprecatedHeader is the M3 deprecated header
 	DeprecatedHeader = "M3-Deprecated"
 
+	// TenantHeader is the header used to identify the tenant of a request
+	TenantHeader = "M3-Tenant"
+
+	// ExportCursorHeader is the trailer with the cursor to continue an export
+	// from when more series match than the limit of the export
+	ExportCursorHeader = "M3-Export-Cursor"
+
 	// DefaultServiceEnvironment is the default service ID environment.
 	DefaultServiceEnvironment = "default_env"
 	// DefaultServiceZone is the default service ID zone.
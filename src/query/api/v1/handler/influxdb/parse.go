@@ -0,0 +1,251 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const escapable = ",= \\"
+
+var (
+	errMissingMeasurement = errors.New("missing measurement")
+	errMissingFields      = errors.New("missing fields")
+	errMissingTagValue    = errors.New("missing tag value")
+	errMissingFieldValue  = errors.New("missing field value")
+	errUnterminatedString = errors.New("unterminated string field value")
+)
+
+// point is a single line of the InfluxDB line protocol, with only the
+// numeric and boolean fields retained.
+type point struct {
+	measurement []byte
+	tags        []tag
+	fields      []field
+	// timestamp is in units of the request precision.
+	timestamp    int64
+	hasTimestamp bool
+}
+
+type tag struct {
+	name  []byte
+	value []byte
+}
+
+type field struct {
+	key   []byte
+	value float64
+}
+
+// parsePoints parses InfluxDB line protocol, skipping empty lines and
+// comments. Each line has the format:
+//
+//	measurement[,tag=value...] field=value[,field=value...] [timestamp]
+func parsePoints(data []byte) ([]point, error) {
+	var points []point
+	for lineNum := 1; len(data) > 0; lineNum++ {
+		var line []byte
+		if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+			line, data = data[:idx], data[idx+1:]
+		} else {
+			line, data = data, nil
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		p, err := parsePoint(line)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse line %d: %v", lineNum, err)
+		}
+
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+func parsePoint(line []byte) (point, error) {
+	var (
+		p   point
+		i   int
+		err error
+	)
+
+	p.measurement, i = scanToken(line, 0, ", ")
+	if len(p.measurement) == 0 {
+		return p, errMissingMeasurement
+	}
+
+	for i < len(line) && line[i] == ',' {
+		var t tag
+		t.name, i = scanToken(line, i+1, ",= ")
+		if len(t.name) == 0 || i >= len(line) || line[i] != '=' {
+			return p, fmt.Errorf("invalid tag: %q", t.name)
+		}
+
+		t.value, i = scanToken(line, i+1, ", ")
+		if len(t.value) == 0 {
+			return p, errMissingTagValue
+		}
+
+		p.tags = append(p.tags, t)
+	}
+
+	i = skipSpaces(line, i)
+	if i >= len(line) {
+		return p, errMissingFields
+	}
+
+	for numFields := 0; ; numFields++ {
+		var key []byte
+		key, i = scanToken(line, i, ",= ")
+		if len(key) == 0 || i >= len(line) || line[i] != '=' {
+			return p, fmt.Errorf("invalid field: %q", key)
+		}
+
+		i++
+		if i < len(line) && line[i] == '"' {
+			// NB: string fields cannot be stored as datapoints so are dropped.
+			if i, err = skipString(line, i); err != nil {
+				return p, err
+			}
+		} else {
+			var value []byte
+			value, i = scanToken(line, i, ", ")
+			f := field{key: key}
+			if f.value, err = parseFieldValue(value); err != nil {
+				return p, err
+			}
+
+			p.fields = append(p.fields, f)
+		}
+
+		if i >= len(line) || line[i] != ',' {
+			break
+		}
+
+		i++
+	}
+
+	i = skipSpaces(line, i)
+	if i < len(line) {
+		p.timestamp, err = strconv.ParseInt(string(line[i:]), 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid timestamp: %q", line[i:])
+		}
+
+		p.hasTimestamp = true
+	}
+
+	return p, nil
+}
+
+// scanToken returns the unescaped token starting at i and ending before the
+// first unescaped byte in stops, along with the index of that byte. Commas,
+// equals signs, spaces and backslashes may be escaped with a backslash.
+func scanToken(line []byte, i int, stops string) ([]byte, int) {
+	var token []byte
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' && i+1 < len(line) && strings.IndexByte(escapable, line[i+1]) >= 0 {
+			i++
+			token = append(token, line[i])
+			continue
+		}
+
+		if strings.IndexByte(stops, c) >= 0 {
+			break
+		}
+
+		token = append(token, c)
+	}
+
+	return token, i
+}
+
+func skipSpaces(line []byte, i int) int {
+	for i < len(line) && line[i] == ' ' {
+		i++
+	}
+
+	return i
+}
+
+// skipString returns the index after the string starting at the quote at i.
+func skipString(line []byte, i int) (int, error) {
+	for i++; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+
+	return 0, errUnterminatedString
+}
+
+// parseFieldValue parses float, integer (`1i`), unsigned (`1u`) and boolean
+// field values, with booleans stored as 1 or 0.
+func parseFieldValue(value []byte) (float64, error) {
+	if len(value) == 0 {
+		return 0, errMissingFieldValue
+	}
+
+	s := string(value)
+	switch s {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+
+	switch s[len(s)-1] {
+	case 'i':
+		v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer field value: %s", s)
+		}
+
+		return float64(v), nil
+	case 'u':
+		v, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid unsigned field value: %s", s)
+		}
+
+		return float64(v), nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid float field value: %s", s)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePoints(t *testing.T) {
+	data := []byte(`# comment
+cpu,host=server\ 01,region=us-west usage_idle=98.5,usage_user=1i 1556813561098000000
+
+mem\,total,host=a free=10u,active=true,note="a \"b\" c" 1556813561
+disk used=0.5`)
+
+	points, err := parsePoints(data)
+	require.NoError(t, err)
+	require.Equal(t, []point{
+		{
+			measurement: []byte("cpu"),
+			tags: []tag{
+				{name: []byte("host"), value: []byte("server 01")},
+				{name: []byte("region"), value: []byte("us-west")},
+			},
+			fields: []field{
+				{key: []byte("usage_idle"), value: 98.5},
+				{key: []byte("usage_user"), value: 1},
+			},
+			timestamp:    1556813561098000000,
+			hasTimestamp: true,
+		},
+		{
+			measurement: []byte("mem,total"),
+			tags: []tag{
+				{name: []byte("host"), value: []byte("a")},
+			},
+			fields: []field{
+				{key: []byte("free"), value: 10},
+				{key: []byte("active"), value: 1},
+			},
+			timestamp:    1556813561,
+			hasTimestamp: true,
+		},
+		{
+			measurement: []byte("disk"),
+			fields: []field{
+				{key: []byte("used"), value: 0.5},
+			},
+		},
+	}, points)
+}
+
+func TestParsePointsInvalid(t *testing.T) {
+	for _, line := range []string{
+		"cpu",
+		"cpu ",
+		",host=a value=1",
+		"cpu,host value=1",
+		"cpu,host= value=1",
+		"cpu value",
+		"cpu value=",
+		"cpu value=abc",
+		"cpu value=1.5i",
+		"cpu value=-1u",
+		`cpu value="unterminated`,
+		"cpu value=1 abc",
+	} {
+		_, err := parsePoints([]byte("cpu value=1\n" + line))
+		require.Error(t, err, line)
+		assert.Contains(t, err.Error(), "line 2", line)
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// InfluxWriteURL is the url for the InfluxDB write handler.
+	InfluxWriteURL = handler.RoutePrefixV1 + "/influxdb/write"
+
+	// InfluxWriteHTTPMethod is the HTTP method used with this resource.
+	InfluxWriteHTTPMethod = http.MethodPost
+)
+
+var (
+	errNoDownsamplerAndWriter = errors.New("no ingest.DownsamplerAndWriter was set")
+	errEmptyBody              = errors.New("empty request body")
+
+	// precisions are the precisions of written timestamps, by the values of
+	// the precision parameter accepted by InfluxDB.
+	precisions = map[string]precision{
+		"":   {duration: time.Nanosecond, unit: xtime.Nanosecond},
+		"n":  {duration: time.Nanosecond, unit: xtime.Nanosecond},
+		"ns": {duration: time.Nanosecond, unit: xtime.Nanosecond},
+		"u":  {duration: time.Microsecond, unit: xtime.Microsecond},
+		"us": {duration: time.Microsecond, unit: xtime.Microsecond},
+		"ms": {duration: time.Millisecond, unit: xtime.Millisecond},
+		"s":  {duration: time.Second, unit: xtime.Second},
+		// NB: coarser timestamps are stored with second units, since those
+		// are the coarsest units storage encodes.
+		"m": {duration: time.Minute, unit: xtime.Second},
+		"h": {duration: time.Hour, unit: xtime.Second},
+	}
+)
+
+// precision is the precision of the timestamps of a write request.
+type precision struct {
+	duration time.Duration
+	unit     xtime.Unit
+}
+
+// InfluxWriteHandler represents a handler for the InfluxDB line protocol
+// write endpoint. Each numeric field of a line is written as a series named
+// after the measurement and field, such as `cpu_usage_idle`, labeled with
+// the tags of the line.
+type InfluxWriteHandler struct {
+	downsamplerAndWriter ingest.DownsamplerAndWriter
+	tagOptions           models.TagOptions
+	influxWriteMetrics   influxWriteMetrics
+	nowFn                func() time.Time
+}
+
+// NewInfluxWriteHandler returns a new instance of handler.
+func NewInfluxWriteHandler(
+	downsamplerAndWriter ingest.DownsamplerAndWriter,
+	tagOptions models.TagOptions,
+	scope tally.Scope,
+) (http.Handler, error) {
+	if downsamplerAndWriter == nil {
+		return nil, errNoDownsamplerAndWriter
+	}
+
+	return &InfluxWriteHandler{
+		downsamplerAndWriter: downsamplerAndWriter,
+		tagOptions:           tagOptions,
+		influxWriteMetrics:   newInfluxWriteMetrics(scope),
+		nowFn:                time.Now,
+	}, nil
+}
+
+type influxWriteMetrics struct {
+	writeSuccess      tally.Counter
+	writeErrorsServer tally.Counter
+	writeErrorsClient tally.Counter
+}
+
+func newInfluxWriteMetrics(scope tally.Scope) influxWriteMetrics {
+	return influxWriteMetrics{
+		writeSuccess:      scope.Counter("write.success"),
+		writeErrorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("write.errors"),
+		writeErrorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("write.errors"),
+	}
+}
+
+func (h *InfluxWriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	points, precision, rErr := h.parseRequest(r)
+	if rErr != nil {
+		h.influxWriteMetrics.writeErrorsClient.Inc(1)
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	iter := newPointsIter(points, precision, h.tagOptions, h.nowFn())
+	if err := h.downsamplerAndWriter.WriteBatch(r.Context(), iter); err != nil {
+		h.influxWriteMetrics.writeErrorsServer.Inc(1)
+		logging.WithContext(r.Context()).Error("Write error", zap.Any("err", err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	h.influxWriteMetrics.writeSuccess.Inc(1)
+	// NB: InfluxDB clients expect no content on success.
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *InfluxWriteHandler) parseRequest(
+	r *http.Request,
+) ([]point, precision, *xhttp.ParseError) {
+	precisionString := r.FormValue("precision")
+	p, ok := precisions[precisionString]
+	if !ok {
+		return nil, p, xhttp.NewParseError(
+			fmt.Errorf("invalid 'precision': %s", precisionString), http.StatusBadRequest)
+	}
+
+	if r.Body == nil {
+		return nil, p, xhttp.NewParseError(errEmptyBody, http.StatusBadRequest)
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, p, xhttp.NewParseError(err, http.StatusBadRequest)
+		}
+
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, p, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	points, err := parsePoints(data)
+	if err != nil {
+		return nil, p, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	return points, p, nil
+}
+
+// newPointsIter converts points to series, using now as the timestamp of
+// points without one.
+func newPointsIter(
+	points []point,
+	precision precision,
+	tagOpts models.TagOptions,
+	now time.Time,
+) *pointsIter {
+	// Construct the tags and datapoints upfront so that if the iterator
+	// is reset, we don't have to generate them twice.
+	var (
+		tags       []models.Tags
+		datapoints []ts.Datapoints
+	)
+	for _, p := range points {
+		timestamp := now.Truncate(precision.duration)
+		if p.hasTimestamp {
+			timestamp = time.Unix(0, p.timestamp*int64(precision.duration))
+		}
+
+		pointTags := make([]models.Tag, 0, len(p.tags))
+		for _, t := range p.tags {
+			pointTags = append(pointTags, models.Tag{
+				Name:  sanitizeName(t.name, false),
+				Value: t.value,
+			})
+		}
+
+		for _, f := range p.fields {
+			name := make([]byte, 0, len(p.measurement)+1+len(f.key))
+			name = append(name, p.measurement...)
+			name = append(name, '_')
+			name = append(name, f.key...)
+
+			seriesTags := models.NewTags(len(pointTags)+1, tagOpts).
+				AddTags(pointTags).
+				SetName(sanitizeName(name, true))
+			tags = append(tags, seriesTags)
+			datapoints = append(datapoints, ts.Datapoints{
+				{Timestamp: timestamp, Value: f.value},
+			})
+		}
+	}
+
+	return &pointsIter{
+		idx:        -1,
+		tags:       tags,
+		datapoints: datapoints,
+		unit:       precision.unit,
+	}
+}
+
+// sanitizeName replaces the bytes of a name which are not valid in
+// Prometheus metric names, or label names if not a metric name, so that the
+// series can be queried with PromQL.
+func sanitizeName(name []byte, metricName bool) []byte {
+	sanitized := make([]byte, 0, len(name)+1)
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c == ':' && metricName:
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				sanitized = append(sanitized, '_')
+			}
+		default:
+			c = '_'
+		}
+
+		sanitized = append(sanitized, c)
+	}
+
+	return sanitized
+}
+
+type pointsIter struct {
+	idx        int
+	tags       []models.Tags
+	datapoints []ts.Datapoints
+	unit       xtime.Unit
+}
+
+func (i *pointsIter) Next() bool {
+	i.idx++
+	return i.idx < len(i.tags)
+}
+
+func (i *pointsIter) Current() (models.Tags, ts.Datapoints, xtime.Unit) {
+	if len(i.tags) == 0 || i.idx < 0 || i.idx >= len(i.tags) {
+		return models.EmptyTags(), nil, 0
+	}
+
+	return i.tags[i.idx], i.datapoints[i.idx], i.unit
+}
+
+func (i *pointsIter) Reset() error {
+	i.idx = -1
+	return nil
+}
+
+func (i *pointsIter) Error() error {
+	return nil
+}
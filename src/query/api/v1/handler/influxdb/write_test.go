@@ -0,0 +1,193 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type writtenSeries struct {
+	tags       map[string]string
+	datapoints ts.Datapoints
+	unit       xtime.Unit
+}
+
+func newTestHandler(
+	t *testing.T,
+	ctrl *gomock.Controller,
+	written *[]writtenSeries,
+	writeErr error,
+) http.Handler {
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, iter ingest.DownsampleAndWriteIter) error {
+			for iter.Next() {
+				tags, datapoints, unit := iter.Current()
+				tagMap := make(map[string]string, len(tags.Tags))
+				for _, tag := range tags.Tags {
+					tagMap[string(tag.Name)] = string(tag.Value)
+				}
+
+				*written = append(*written, writtenSeries{
+					tags:       tagMap,
+					datapoints: datapoints,
+					unit:       unit,
+				})
+			}
+
+			return writeErr
+		}).AnyTimes()
+
+	h, err := NewInfluxWriteHandler(mockDownsamplerAndWriter,
+		models.NewTagOptions(), tally.NoopScope)
+	require.NoError(t, err)
+	return h
+}
+
+func TestInfluxWrite(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []writtenSeries
+	h := newTestHandler(t, ctrl, &written, nil)
+
+	body := "cpu,host=a,cpu-id=0 usage_idle=98.5,usage_user=1i 1556813561\n"
+	req := httptest.NewRequest(InfluxWriteHTTPMethod,
+		InfluxWriteURL+"?precision=s", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	timestamp := time.Unix(1556813561, 0)
+	require.Equal(t, []writtenSeries{
+		{
+			tags: map[string]string{
+				"__name__": "cpu_usage_idle",
+				"host":     "a",
+				"cpu_id":   "0",
+			},
+			datapoints: ts.Datapoints{{Timestamp: timestamp, Value: 98.5}},
+			unit:       xtime.Second,
+		},
+		{
+			tags: map[string]string{
+				"__name__": "cpu_usage_user",
+				"host":     "a",
+				"cpu_id":   "0",
+			},
+			datapoints: ts.Datapoints{{Timestamp: timestamp, Value: 1}},
+			unit:       xtime.Second,
+		},
+	}, written)
+}
+
+func TestInfluxWriteGzipWithoutTimestamp(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []writtenSeries
+	h := newTestHandler(t, ctrl, &written, nil)
+	now := time.Date(2019, time.May, 2, 16, 12, 41, 123456789, time.UTC)
+	h.(*InfluxWriteHandler).nowFn = func() time.Time { return now }
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	_, err := gzipWriter.Write([]byte("mem used=1.5"))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	req := httptest.NewRequest(InfluxWriteHTTPMethod,
+		InfluxWriteURL+"?precision=ms", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	require.Equal(t, []writtenSeries{
+		{
+			tags: map[string]string{"__name__": "mem_used"},
+			datapoints: ts.Datapoints{
+				{Timestamp: now.Truncate(time.Millisecond), Value: 1.5},
+			},
+			unit: xtime.Millisecond,
+		},
+	}, written)
+}
+
+func TestInfluxWriteErrors(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []writtenSeries
+	h := newTestHandler(t, ctrl, &written, nil)
+	for _, tt := range []struct {
+		url  string
+		body string
+	}{
+		{url: InfluxWriteURL + "?precision=d", body: "cpu value=1"},
+		{url: InfluxWriteURL, body: "cpu value=abc"},
+	} {
+		req := httptest.NewRequest(InfluxWriteHTTPMethod, tt.url,
+			strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code, tt.url)
+	}
+
+	require.Empty(t, written)
+
+	h = newTestHandler(t, ctrl, &written, errors.New("write error"))
+	req := httptest.NewRequest(InfluxWriteHTTPMethod, InfluxWriteURL,
+		strings.NewReader("cpu value=1"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestSanitizeName(t *testing.T) {
+	require.Equal(t, "cpu_usage:idle", string(sanitizeName([]byte("cpu.usage:idle"), true)))
+	require.Equal(t, "cpu_usage_idle", string(sanitizeName([]byte("cpu.usage:idle"), false)))
+	require.Equal(t, "_0cpu", string(sanitizeName([]byte("0cpu"), true)))
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	ingestopentsdb "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/opentsdb"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// PutURL is the url for the OpenTSDB put handler, which is served at
+	// the same path as OpenTSDB so existing collectors can be pointed at it.
+	PutURL = "/api/put"
+
+	// PutHTTPMethod is the HTTP method used with this resource.
+	PutHTTPMethod = http.MethodPost
+
+	detailsParam = "details"
+	summaryParam = "summary"
+)
+
+var (
+	errNoDownsamplerAndWriter = errors.New("no ingest.DownsamplerAndWriter was set")
+	errEmptyBody              = errors.New("empty request body")
+	errDatapointsFailed       = errors.New("one or more data points had errors, " +
+		"append \"details\" to the request to see them")
+)
+
+// PutHandler represents a handler for the OpenTSDB put endpoint. Invalid
+// datapoints are reported while the valid datapoints of the same request
+// are still written.
+type PutHandler struct {
+	downsamplerAndWriter ingest.DownsamplerAndWriter
+	tagOptions           models.TagOptions
+	putMetrics           putMetrics
+}
+
+// NewPutHandler returns a new instance of handler.
+func NewPutHandler(
+	downsamplerAndWriter ingest.DownsamplerAndWriter,
+	tagOptions models.TagOptions,
+	scope tally.Scope,
+) (http.Handler, error) {
+	if downsamplerAndWriter == nil {
+		return nil, errNoDownsamplerAndWriter
+	}
+
+	return &PutHandler{
+		downsamplerAndWriter: downsamplerAndWriter,
+		tagOptions:           tagOptions,
+		putMetrics:           newPutMetrics(scope),
+	}, nil
+}
+
+type putMetrics struct {
+	writeSuccess      tally.Counter
+	writeErrorsServer tally.Counter
+	writeErrorsClient tally.Counter
+}
+
+func newPutMetrics(scope tally.Scope) putMetrics {
+	return putMetrics{
+		writeSuccess:      scope.Counter("write.success"),
+		writeErrorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("write.errors"),
+		writeErrorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("write.errors"),
+	}
+}
+
+// putError is a datapoint which could not be written, as reported when
+// details are requested.
+type putError struct {
+	Datapoint ingestopentsdb.Datapoint `json:"datapoint"`
+	Error     string                   `json:"error"`
+}
+
+// putSummary is the response when a summary or details are requested.
+type putSummary struct {
+	Errors  []putError `json:"errors,omitempty"`
+	Failed  int        `json:"failed"`
+	Success int        `json:"success"`
+}
+
+func (h *PutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	datapoints, rErr := parsePutRequest(r)
+	if rErr != nil {
+		h.putMetrics.writeErrorsClient.Inc(1)
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	var (
+		iter   = &seriesIter{idx: -1}
+		failed []putError
+	)
+	for _, dp := range datapoints {
+		tags, dps, unit, err := dp.Series(h.tagOptions)
+		if err != nil {
+			failed = append(failed, putError{Datapoint: dp, Error: err.Error()})
+			continue
+		}
+
+		iter.tags = append(iter.tags, tags)
+		iter.datapoints = append(iter.datapoints, dps)
+		iter.units = append(iter.units, unit)
+	}
+
+	if len(iter.tags) > 0 {
+		if err := h.downsamplerAndWriter.WriteBatch(r.Context(), iter); err != nil {
+			h.putMetrics.writeErrorsServer.Inc(1)
+			logging.WithContext(r.Context()).Error("Write error", zap.Any("err", err))
+			xhttp.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	code := http.StatusOK
+	if len(failed) > 0 {
+		h.putMetrics.writeErrorsClient.Inc(1)
+		code = http.StatusBadRequest
+	} else {
+		h.putMetrics.writeSuccess.Inc(1)
+	}
+
+	query := r.URL.Query()
+	_, details := query[detailsParam]
+	_, summary := query[summaryParam]
+	if !details && !summary {
+		if len(failed) > 0 {
+			xhttp.Error(w, errDatapointsFailed, code)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := putSummary{
+		Failed:  len(failed),
+		Success: len(datapoints) - len(failed),
+	}
+	if details {
+		response.Errors = failed
+		if response.Errors == nil {
+			response.Errors = []putError{}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logging.WithContext(r.Context()).Error("unable to write put response",
+			zap.Error(err))
+	}
+}
+
+// parsePutRequest parses a body of either a single datapoint or an array of
+// datapoints, optionally gzip compressed.
+func parsePutRequest(r *http.Request) ([]ingestopentsdb.Datapoint, *xhttp.ParseError) {
+	if r.Body == nil {
+		return nil, xhttp.NewParseError(errEmptyBody, http.StatusBadRequest)
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+		}
+
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, xhttp.NewParseError(errEmptyBody, http.StatusBadRequest)
+	}
+
+	var datapoints []ingestopentsdb.Datapoint
+	if data[0] == '[' {
+		err = json.Unmarshal(data, &datapoints)
+	} else {
+		var dp ingestopentsdb.Datapoint
+		err = json.Unmarshal(data, &dp)
+		datapoints = append(datapoints, dp)
+	}
+
+	if err != nil {
+		return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	return datapoints, nil
+}
+
+type seriesIter struct {
+	idx        int
+	tags       []models.Tags
+	datapoints []ts.Datapoints
+	units      []xtime.Unit
+}
+
+func (i *seriesIter) Next() bool {
+	i.idx++
+	return i.idx < len(i.tags)
+}
+
+func (i *seriesIter) Current() (models.Tags, ts.Datapoints, xtime.Unit) {
+	if len(i.tags) == 0 || i.idx < 0 || i.idx >= len(i.tags) {
+		return models.EmptyTags(), nil, 0
+	}
+
+	return i.tags[i.idx], i.datapoints[i.idx], i.units[i.idx]
+}
+
+func (i *seriesIter) Reset() error {
+	i.idx = -1
+	return nil
+}
+
+func (i *seriesIter) Error() error {
+	return nil
+}
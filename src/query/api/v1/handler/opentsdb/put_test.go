@@ -0,0 +1,219 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+type writtenSeries struct {
+	tags       map[string]string
+	datapoints ts.Datapoints
+	unit       xtime.Unit
+}
+
+func newTestPutHandler(
+	t *testing.T,
+	ctrl *gomock.Controller,
+	written *[]writtenSeries,
+	writeErr error,
+) http.Handler {
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().WriteBatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, iter ingest.DownsampleAndWriteIter) error {
+			for iter.Next() {
+				tags, datapoints, unit := iter.Current()
+				tagMap := make(map[string]string, len(tags.Tags))
+				for _, tag := range tags.Tags {
+					tagMap[string(tag.Name)] = string(tag.Value)
+				}
+
+				*written = append(*written, writtenSeries{
+					tags:       tagMap,
+					datapoints: datapoints,
+					unit:       unit,
+				})
+			}
+
+			return writeErr
+		}).AnyTimes()
+
+	h, err := NewPutHandler(mockDownsamplerAndWriter,
+		models.NewTagOptions(), tally.NoopScope)
+	require.NoError(t, err)
+	return h
+}
+
+func TestPut(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []writtenSeries
+	h := newTestPutHandler(t, ctrl, &written, nil)
+
+	body := `[
+		{"metric": "sys.cpu.user", "timestamp": 1556813561, "value": 42.5, "tags": {"host": "web01"}},
+		{"metric": "sys.cpu.user", "timestamp": 1556813561123, "value": "7", "tags": {"host": "web02"}}
+	]`
+	req := httptest.NewRequest(PutHTTPMethod, PutURL, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	require.Equal(t, []writtenSeries{
+		{
+			tags: map[string]string{
+				"__name__": "sys.cpu.user",
+				"host":     "web01",
+			},
+			datapoints: ts.Datapoints{{Timestamp: time.Unix(1556813561, 0), Value: 42.5}},
+			unit:       xtime.Second,
+		},
+		{
+			tags: map[string]string{
+				"__name__": "sys.cpu.user",
+				"host":     "web02",
+			},
+			datapoints: ts.Datapoints{{
+				Timestamp: time.Unix(0, 1556813561123*int64(time.Millisecond)),
+				Value:     7,
+			}},
+			unit: xtime.Millisecond,
+		},
+	}, written)
+}
+
+func TestPutSingleDatapoint(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []writtenSeries
+	h := newTestPutHandler(t, ctrl, &written, nil)
+
+	body := `{"metric": "sys.cpu.user", "timestamp": 1556813561, "value": 1, "tags": {"host": "web01"}}`
+	req := httptest.NewRequest(PutHTTPMethod, PutURL, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	require.Len(t, written, 1)
+}
+
+func TestPutDetails(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []writtenSeries
+	h := newTestPutHandler(t, ctrl, &written, nil)
+
+	body := `[
+		{"metric": "sys.cpu.user", "timestamp": 1556813561, "value": 1, "tags": {"host": "web01"}},
+		{"metric": "sys.cpu.user", "timestamp": 1556813561, "value": 1, "tags": {}}
+	]`
+
+	// Without details, the valid datapoints are written and only the failure
+	// is reported.
+	req := httptest.NewRequest(PutHTTPMethod, PutURL, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Len(t, written, 1)
+
+	req = httptest.NewRequest(PutHTTPMethod, PutURL+"?details",
+		strings.NewReader(body))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Len(t, written, 2)
+
+	var response putSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Success)
+	assert.Equal(t, 1, response.Failed)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "sys.cpu.user", response.Errors[0].Datapoint.Metric)
+	assert.NotEmpty(t, response.Errors[0].Error)
+
+	req = httptest.NewRequest(PutHTTPMethod, PutURL+"?summary",
+		strings.NewReader(body))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"failed": 1, "success": 1}`, w.Body.String())
+}
+
+func TestPutInvalidBody(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []writtenSeries
+	h := newTestPutHandler(t, ctrl, &written, nil)
+
+	for _, body := range []string{"", "not json", `[{"metric": 1}]`} {
+		req := httptest.NewRequest(PutHTTPMethod, PutURL, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	assert.Empty(t, written)
+}
+
+func TestPutWriteError(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []writtenSeries
+	h := newTestPutHandler(t, ctrl, &written, errors.New("write failed"))
+
+	body := `{"metric": "sys.cpu.user", "timestamp": 1556813561, "value": 1, "tags": {"host": "web01"}}`
+	req := httptest.NewRequest(PutHTTPMethod, PutURL, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
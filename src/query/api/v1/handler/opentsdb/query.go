@@ -0,0 +1,437 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// QueryURL is the url for the OpenTSDB query handler, which is served
+	// at the same path as OpenTSDB so existing dashboards can query it.
+	QueryURL = "/api/query"
+
+	startParam = "start"
+	endParam   = "end"
+	queryParam = "m"
+	msParam    = "ms"
+
+	nowTime   = "now"
+	agoSuffix = "-ago"
+
+	// defaultStep is the interval of the datapoints of queries which are
+	// not downsampled.
+	defaultStep = time.Minute
+
+	// maxSecondsDigits is the number of digits of timestamps in seconds,
+	// longer timestamps are in milliseconds.
+	maxSecondsDigits = 10
+)
+
+var (
+	// QueryHTTPMethods are the HTTP methods used with this resource.
+	QueryHTTPMethods = []string{http.MethodGet, http.MethodPost}
+
+	errMissingStart   = errors.New("missing start time")
+	errMissingQueries = errors.New("missing sub queries")
+	errStartAfterEnd  = errors.New("start time must be before end time")
+
+	absoluteTimeFormats = []string{
+		"2006/01/02-15:04:05",
+		"2006/01/02 15:04:05",
+		"2006/01/02-15:04",
+		"2006/01/02 15:04",
+		"2006/01/02",
+	}
+)
+
+// QueryHandler represents a handler for the OpenTSDB query endpoint.
+type QueryHandler struct {
+	engine      *executor.Engine
+	tagOpts     models.TagOptions
+	timeoutOpts *prometheus.TimeoutOpts
+}
+
+// NewQueryHandler returns a new instance of handler.
+func NewQueryHandler(
+	engine *executor.Engine,
+	tagOpts models.TagOptions,
+	timeoutOpts *prometheus.TimeoutOpts,
+) http.Handler {
+	return &QueryHandler{
+		engine:      engine,
+		tagOpts:     tagOpts,
+		timeoutOpts: timeoutOpts,
+	}
+}
+
+// queryParams are the parsed parameters of a query request.
+type queryParams struct {
+	start        time.Time
+	end          time.Time
+	now          time.Time
+	timeout      time.Duration
+	queries      []subQuery
+	msResolution bool
+}
+
+// queryRequest is the JSON body of a POST query request.
+type queryRequest struct {
+	Start        timeParam         `json:"start"`
+	End          timeParam         `json:"end"`
+	Queries      []subQueryRequest `json:"queries"`
+	MsResolution bool              `json:"msResolution"`
+}
+
+type subQueryRequest struct {
+	Aggregator  string            `json:"aggregator"`
+	Metric      string            `json:"metric"`
+	Rate        bool              `json:"rate"`
+	RateOptions rateOptions       `json:"rateOptions"`
+	Downsample  string            `json:"downsample"`
+	Tags        map[string]string `json:"tags"`
+	Filters     []filterRequest   `json:"filters"`
+}
+
+type rateOptions struct {
+	Counter bool `json:"counter"`
+}
+
+type filterRequest struct {
+	Type    string `json:"type"`
+	TagKey  string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
+
+// timeParam is a time given either as a number or a string.
+type timeParam string
+
+func (t *timeParam) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = timeParam(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid time: %s", data)
+	}
+
+	*t = timeParam(n.String())
+	return nil
+}
+
+// queryResult is a series of the query response.
+type queryResult struct {
+	Metric        string             `json:"metric"`
+	Tags          map[string]string  `json:"tags"`
+	AggregateTags []string           `json:"aggregateTags"`
+	Datapoints    map[string]float64 `json:"dps"`
+}
+
+func (h *QueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+
+	params, rErr := parseQueryParams(r, h.timeoutOpts)
+	if rErr != nil {
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, params.timeout)
+	defer cancel()
+
+	// Detect clients closing connections
+	handler.CloseWatcher(ctx, cancel, w)
+
+	results := make([]queryResult, 0, len(params.queries))
+	for _, q := range params.queries {
+		step := q.step(defaultStep)
+		seriesList, err := native.ReadWithParser(ctx, h.engine, q.parseFn(step),
+			h.tagOpts, models.RequestParams{
+				Start:      params.start,
+				End:        params.end,
+				Now:        params.now,
+				Timeout:    params.timeout,
+				Step:       step,
+				Query:      q.String(),
+				IncludeEnd: true,
+			})
+		if err != nil {
+			logger.Error("unable to fetch data", zap.Error(err))
+			xhttp.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		results = append(results,
+			newQueryResults(q, seriesList, params, h.tagOpts)...)
+	}
+
+	xhttp.WriteJSONResponse(w, results, logger)
+}
+
+// newQueryResults returns the results of the series of a sub query, which
+// are already aggregated by group unless the aggregator is none.
+func newQueryResults(
+	q subQuery,
+	seriesList []*ts.Series,
+	params queryParams,
+	tagOpts models.TagOptions,
+) []queryResult {
+	aggregateTags := q.aggregateTags()
+	results := make([]queryResult, 0, len(seriesList))
+	for _, s := range seriesList {
+		results = append(results,
+			newQueryResult(q.metric, s, aggregateTags, params, tagOpts))
+	}
+
+	return results
+}
+
+// newQueryResult returns the result of a series, skipping the steps without
+// values. The tags filtered but not grouped by are reported as aggregated,
+// the series only keeping the grouped tags once aggregated.
+func newQueryResult(
+	metric string,
+	s *ts.Series,
+	aggregateTags []string,
+	params queryParams,
+	tagOpts models.TagOptions,
+) queryResult {
+	values := s.Values()
+	result := queryResult{
+		Metric:        metric,
+		Tags:          make(map[string]string, len(s.Tags.Tags)),
+		AggregateTags: aggregateTags,
+		Datapoints:    make(map[string]float64, values.Len()),
+	}
+
+	for _, tag := range s.Tags.Tags {
+		if !bytes.Equal(tag.Name, tagOpts.MetricName()) {
+			result.Tags[string(tag.Name)] = string(tag.Value)
+		}
+	}
+
+	for i := 0; i < values.Len(); i++ {
+		dp := values.DatapointAt(i)
+		if math.IsNaN(dp.Value) || dp.Timestamp.Before(params.start) {
+			continue
+		}
+
+		var timestamp int64
+		if params.msResolution {
+			timestamp = dp.Timestamp.UnixNano() / int64(time.Millisecond)
+		} else {
+			timestamp = dp.Timestamp.Unix()
+		}
+
+		result.Datapoints[strconv.FormatInt(timestamp, 10)] = dp.Value
+	}
+
+	return result
+}
+
+// parseQueryParams parses either the JSON body of a POST request or the
+// parameters of a GET request.
+func parseQueryParams(
+	r *http.Request,
+	timeoutOpts *prometheus.TimeoutOpts,
+) (queryParams, *xhttp.ParseError) {
+	params := queryParams{now: time.Now()}
+	timeout, err := prometheus.ParseRequestTimeout(r, timeoutOpts.FetchTimeout)
+	if err != nil {
+		return params, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	params.timeout = timeout
+	var start, end string
+	if r.Method == http.MethodPost {
+		start, end, err = parseQueryBody(r, &params)
+	} else {
+		start, end, err = parseQueryValues(r, &params)
+	}
+
+	if err != nil {
+		return params, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	if start == "" {
+		return params, xhttp.NewParseError(errMissingStart, http.StatusBadRequest)
+	}
+
+	if len(params.queries) == 0 {
+		return params, xhttp.NewParseError(errMissingQueries, http.StatusBadRequest)
+	}
+
+	params.start, err = parseTime(start, params.now)
+	if err != nil {
+		return params, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	params.end = params.now
+	if end != "" {
+		params.end, err = parseTime(end, params.now)
+		if err != nil {
+			return params, xhttp.NewParseError(err, http.StatusBadRequest)
+		}
+	}
+
+	if !params.start.Before(params.end) {
+		return params, xhttp.NewParseError(errStartAfterEnd, http.StatusBadRequest)
+	}
+
+	return params, nil
+}
+
+func parseQueryValues(r *http.Request, params *queryParams) (string, string, error) {
+	values := r.URL.Query()
+	for _, m := range values[queryParam] {
+		q, err := parseSubQuery(m)
+		if err != nil {
+			return "", "", err
+		}
+
+		params.queries = append(params.queries, q)
+	}
+
+	_, params.msResolution = values[msParam]
+	return values.Get(startParam), values.Get(endParam), nil
+}
+
+func parseQueryBody(r *http.Request, params *queryParams) (string, string, error) {
+	if r.Body == nil {
+		return "", "", errEmptyBody
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", "", err
+	}
+
+	var req queryRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return "", "", err
+	}
+
+	for _, sq := range req.Queries {
+		q, err := sq.subQuery()
+		if err != nil {
+			return "", "", err
+		}
+
+		params.queries = append(params.queries, q)
+	}
+
+	params.msResolution = req.MsResolution
+	return string(req.Start), string(req.End), nil
+}
+
+// subQuery converts the sub query of a JSON request, whose tags use the
+// same syntax as the tags of the "m" parameter and are grouped by.
+func (r subQueryRequest) subQuery() (subQuery, error) {
+	q := subQuery{
+		aggregator: r.Aggregator,
+		metric:     r.Metric,
+		rate:       r.Rate,
+		counter:    r.RateOptions.Counter,
+	}
+
+	if r.Downsample != "" {
+		ds, err := parseDownsample(r.Downsample)
+		if err != nil {
+			return subQuery{}, err
+		}
+
+		q.downsample = &ds
+	}
+
+	for k, v := range r.Tags {
+		q.filters = append(q.filters, newFilter(k, v, true))
+	}
+
+	for _, f := range r.Filters {
+		q.filters = append(q.filters, filter{
+			filterType: strings.ToLower(f.Type),
+			tagKey:     f.TagKey,
+			expr:       f.Filter,
+			groupBy:    f.GroupBy,
+		})
+	}
+
+	return q, q.validate()
+}
+
+// parseTime parses an absolute time, a timestamp in seconds or milliseconds
+// or a relative time such as "1h-ago".
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if s == nowTime {
+		return now, nil
+	}
+
+	if strings.HasSuffix(s, agoSuffix) {
+		d, err := parseDuration(strings.TrimSuffix(s, agoSuffix))
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return now.Add(-d), nil
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if len(s) > maxSecondsDigits {
+			return time.Unix(0, n*int64(time.Millisecond)), nil
+		}
+
+		return time.Unix(n, 0), nil
+	}
+
+	for _, layout := range absoluteTimeFormats {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time: %s", s)
+}
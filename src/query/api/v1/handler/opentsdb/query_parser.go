@@ -0,0 +1,758 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/linear"
+	"github.com/m3db/m3/src/query/functions/temporal"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+const (
+	noneAggregator     = "none"
+	lastDownsample     = "last"
+	zeroFillPolicy     = "zero"
+	literalOrFilter    = "literal_or"
+	iliteralOrFilter   = "iliteral_or"
+	notLiteralOrFilter = "not_literal_or"
+	notIliteralFilter  = "not_iliteral_or"
+	wildcardFilter     = "wildcard"
+	iwildcardFilter    = "iwildcard"
+	regexpFilter       = "regexp"
+	rateKeyword        = "rate"
+	counterOption      = "counter"
+)
+
+var (
+	// aggregatorTypes maps the OpenTSDB aggregators to the aggregation
+	// applied across the series of a group at each step, the interpolating
+	// and non interpolating variants behave the same as series are aligned
+	// to steps. The series are not aggregated with the none aggregator.
+	aggregatorTypes = map[string]string{
+		"sum":          aggregation.SumType,
+		"zimsum":       aggregation.SumType,
+		"avg":          aggregation.AverageType,
+		"min":          aggregation.MinType,
+		"mimmin":       aggregation.MinType,
+		"max":          aggregation.MaxType,
+		"mimmax":       aggregation.MaxType,
+		"count":        aggregation.CountType,
+		"dev":          aggregation.StandardDeviationType,
+		noneAggregator: "",
+	}
+
+	// downsampleTypes maps the OpenTSDB downsampling functions to the
+	// temporal aggregation applied over each interval, the last value needs
+	// no aggregation as it is what the engine returns for each step.
+	downsampleTypes = map[string]string{
+		"avg":          temporal.AvgType,
+		"sum":          temporal.SumType,
+		"zimsum":       temporal.SumType,
+		"min":          temporal.MinType,
+		"mimmin":       temporal.MinType,
+		"max":          temporal.MaxType,
+		"mimmax":       temporal.MaxType,
+		"count":        temporal.CountType,
+		"dev":          temporal.StdDevType,
+		lastDownsample: "",
+	}
+
+	fillPolicies = map[string]bool{
+		"none":         true,
+		"nan":          true,
+		"null":         true,
+		zeroFillPolicy: true,
+	}
+
+	filterTypes = map[string]bool{
+		literalOrFilter:    true,
+		iliteralOrFilter:   true,
+		notLiteralOrFilter: true,
+		notIliteralFilter:  true,
+		wildcardFilter:     true,
+		iwildcardFilter:    true,
+		regexpFilter:       true,
+	}
+
+	durationUnits = map[string]time.Duration{
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  24 * time.Hour,
+		"w":  7 * 24 * time.Hour,
+		"n":  30 * 24 * time.Hour,
+		"y":  365 * 24 * time.Hour,
+	}
+
+	// durationUnitNames are the duration units from the largest.
+	durationUnitNames = []string{"y", "n", "w", "d", "h", "m", "s", "ms"}
+
+	filterExpr = regexp.MustCompile(`^([a-z_]+)\((.*)\)$`)
+)
+
+// subQuery is a single metric query of an OpenTSDB query request.
+type subQuery struct {
+	aggregator string
+	metric     string
+	rate       bool
+	counter    bool
+	downsample *downsample
+	filters    []filter
+}
+
+// downsample is the interval, function and fill policy used to reduce the
+// datapoints of each series before aggregating them.
+type downsample struct {
+	interval time.Duration
+	fn       string
+	fill     string
+}
+
+// filter matches the values of a tag, the tag is part of the grouping of the
+// series when groupBy is set.
+type filter struct {
+	filterType string
+	tagKey     string
+	expr       string
+	groupBy    bool
+}
+
+// parseSubQuery parses a query in the "m" parameter format of OpenTSDB:
+// <aggregator>:[<downsample>:][rate[{counter}]:]<metric>[{<tags>}][{<filters>}]
+func parseSubQuery(m string) (subQuery, error) {
+	parts, err := splitOutsideBraces(m, ':')
+	if err != nil {
+		return subQuery{}, err
+	}
+
+	if len(parts) < 2 {
+		return subQuery{}, fmt.Errorf("invalid query, expected an aggregator "+
+			"and a metric: %s", m)
+	}
+
+	q := subQuery{aggregator: parts[0]}
+	for _, part := range parts[1 : len(parts)-1] {
+		if part == rateKeyword || strings.HasPrefix(part, rateKeyword+"{") {
+			q.rate = true
+			q.counter, err = parseRateOptions(strings.TrimPrefix(part, rateKeyword))
+			if err != nil {
+				return subQuery{}, err
+			}
+
+			continue
+		}
+
+		if q.downsample != nil {
+			return subQuery{}, fmt.Errorf("invalid query part: %s", part)
+		}
+
+		ds, err := parseDownsample(part)
+		if err != nil {
+			return subQuery{}, err
+		}
+
+		q.downsample = &ds
+	}
+
+	metric := parts[len(parts)-1]
+	idx := strings.IndexByte(metric, '{')
+	if idx < 0 {
+		q.metric = metric
+		return q, q.validate()
+	}
+
+	q.metric = metric[:idx]
+	groups, err := splitFilterGroups(metric[idx:])
+	if err != nil {
+		return subQuery{}, err
+	}
+
+	for i, group := range groups {
+		// Tags of the first group are grouped by, those of the second only
+		// filter the series.
+		filters, err := parseFilters(group, i == 0)
+		if err != nil {
+			return subQuery{}, err
+		}
+
+		q.filters = append(q.filters, filters...)
+	}
+
+	return q, q.validate()
+}
+
+// validate returns an error if the query uses an unsupported aggregator or
+// filter.
+func (q subQuery) validate() error {
+	if q.metric == "" {
+		return fmt.Errorf("missing metric")
+	}
+
+	if _, ok := aggregatorTypes[q.aggregator]; !ok {
+		return fmt.Errorf("unsupported aggregator: %s", q.aggregator)
+	}
+
+	for _, f := range q.filters {
+		if f.tagKey == "" {
+			return fmt.Errorf("missing tag key for filter: %s", f.expr)
+		}
+
+		if !filterTypes[f.filterType] {
+			return fmt.Errorf("unsupported filter type: %s", f.filterType)
+		}
+	}
+
+	return nil
+}
+
+// step returns the interval of the datapoints of the query results.
+func (q subQuery) step(defaultStep time.Duration) time.Duration {
+	if q.downsample != nil {
+		return q.downsample.interval
+	}
+
+	return defaultStep
+}
+
+// String returns the query in the "m" parameter format.
+func (q subQuery) String() string {
+	parts := []string{q.aggregator}
+	if q.downsample != nil {
+		parts = append(parts, q.downsample.String())
+	}
+
+	if q.rate {
+		if q.counter {
+			parts = append(parts, rateKeyword+"{"+counterOption+"}")
+		} else {
+			parts = append(parts, rateKeyword)
+		}
+	}
+
+	var groupBy, other []string
+	for _, f := range q.filters {
+		s := f.tagKey + "=" + f.filterType + "(" + f.expr + ")"
+		if f.groupBy {
+			groupBy = append(groupBy, s)
+		} else {
+			other = append(other, s)
+		}
+	}
+
+	metric := q.metric
+	if len(groupBy) > 0 || len(other) > 0 {
+		metric += "{" + strings.Join(groupBy, ",") + "}"
+	}
+
+	if len(other) > 0 {
+		metric += "{" + strings.Join(other, ",") + "}"
+	}
+
+	return strings.Join(append(parts, metric), ":")
+}
+
+func (d downsample) String() string {
+	s := formatDuration(d.interval) + "-" + d.fn
+	if d.fill != "" {
+		s += "-" + d.fill
+	}
+
+	return s
+}
+
+// parseRateOptions parses the optional "{counter[,max[,reset]]}" options of
+// a rate, returning whether the rate is of a counter. Counter resets are
+// always accounted for, so the max and reset values are ignored.
+func parseRateOptions(s string) (bool, error) {
+	if s == "" {
+		return false, nil
+	}
+
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return false, fmt.Errorf("invalid rate options: %s", s)
+	}
+
+	options := strings.Split(s[1:len(s)-1], ",")
+	return options[0] == counterOption, nil
+}
+
+// parseDownsample parses a downsampler in the "<interval>-<fn>[-<fill>]"
+// format.
+func parseDownsample(s string) (downsample, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 2 || len(parts) > 3 {
+		return downsample{}, fmt.Errorf("invalid downsample: %s", s)
+	}
+
+	interval, err := parseDuration(parts[0])
+	if err != nil {
+		return downsample{}, fmt.Errorf("invalid downsample interval: %s", parts[0])
+	}
+
+	if interval <= 0 {
+		return downsample{}, fmt.Errorf("downsample interval must be positive: %s", parts[0])
+	}
+
+	ds := downsample{interval: interval, fn: parts[1]}
+	if _, ok := downsampleTypes[ds.fn]; !ok {
+		return downsample{}, fmt.Errorf("unsupported downsample function: %s", ds.fn)
+	}
+
+	if len(parts) == 3 {
+		ds.fill = parts[2]
+		if !fillPolicies[ds.fill] {
+			return downsample{}, fmt.Errorf("unsupported fill policy: %s", ds.fill)
+		}
+	}
+
+	return ds, nil
+}
+
+// parseDuration parses a duration such as "5m" using the OpenTSDB units,
+// where "n" is a month of 30 days and "y" a year of 365 days.
+func parseDuration(s string) (time.Duration, error) {
+	idx := strings.IndexFunc(s, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+
+	if idx <= 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+
+	unit, ok := durationUnits[s[idx:]]
+	if !ok {
+		return 0, fmt.Errorf("invalid duration unit: %s", s)
+	}
+
+	n, err := strconv.Atoi(s[:idx])
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// formatDuration formats a duration using the largest OpenTSDB unit which
+// divides it.
+func formatDuration(d time.Duration) string {
+	for _, unit := range durationUnitNames {
+		if size := durationUnits[unit]; d%size == 0 {
+			return strconv.FormatInt(int64(d/size), 10) + unit
+		}
+	}
+
+	return strconv.FormatInt(int64(d/time.Millisecond), 10) + "ms"
+}
+
+// splitOutsideBraces splits s on sep, ignoring separators within braces.
+func splitOutsideBraces(s string, sep byte) ([]string, error) {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced braces: %s", s)
+			}
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced braces: %s", s)
+	}
+
+	return append(parts, s[start:]), nil
+}
+
+// splitFilterGroups returns the contents of the "{...}{...}" filter groups
+// following a metric name.
+func splitFilterGroups(s string) ([]string, error) {
+	var groups []string
+	for len(s) > 0 {
+		end := strings.IndexByte(s, '}')
+		if s[0] != '{' || end < 0 {
+			return nil, fmt.Errorf("invalid tag filters: %s", s)
+		}
+
+		groups = append(groups, s[1:end])
+		s = s[end+1:]
+	}
+
+	if len(groups) > 2 {
+		return nil, fmt.Errorf("too many tag filter groups")
+	}
+
+	return groups, nil
+}
+
+// parseFilters parses a comma separated list of tag filters, commas within
+// the parentheses of a filter function do not separate filters.
+func parseFilters(s string, groupBy bool) ([]filter, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var (
+		filters []filter
+		depth   int
+		start   int
+	)
+
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			switch s[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+
+			if s[i] != ',' || depth > 0 {
+				continue
+			}
+		}
+
+		pair := s[start:i]
+		start = i + 1
+		idx := strings.IndexByte(pair, '=')
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid tag filter: %s", pair)
+		}
+
+		filters = append(filters, newFilter(pair[:idx], pair[idx+1:], groupBy))
+	}
+
+	return filters, nil
+}
+
+// newFilter returns the filter for a tag value, which is either a filter
+// function such as "regexp(web.*)", a "|" separated list of literals or a
+// wildcard.
+func newFilter(tagKey, value string, groupBy bool) filter {
+	f := filter{tagKey: tagKey, expr: value, groupBy: groupBy}
+	if match := filterExpr.FindStringSubmatch(value); match != nil &&
+		filterTypes[match[1]] {
+		f.filterType = match[1]
+		f.expr = match[2]
+		return f
+	}
+
+	if strings.Contains(value, "*") {
+		f.filterType = wildcardFilter
+	} else {
+		f.filterType = literalOrFilter
+	}
+
+	return f
+}
+
+// matcher returns the matcher for the values of the filter.
+func (f filter) matcher() (models.Matcher, error) {
+	name := []byte(f.tagKey)
+	switch f.filterType {
+	case literalOrFilter, notLiteralOrFilter:
+		values := strings.Split(f.expr, "|")
+		matchType := models.MatchEqual
+		if f.filterType == notLiteralOrFilter {
+			matchType = models.MatchNotEqual
+		}
+
+		if len(values) == 1 {
+			return models.NewMatcher(matchType, name, []byte(values[0]))
+		}
+
+		matchType = models.MatchRegexp
+		if f.filterType == notLiteralOrFilter {
+			matchType = models.MatchNotRegexp
+		}
+
+		return models.NewMatcher(matchType, name, []byte(literalsToRegexp(values)))
+	case iliteralOrFilter:
+		return models.NewMatcher(models.MatchRegexp, name,
+			[]byte("(?i)"+literalsToRegexp(strings.Split(f.expr, "|"))))
+	case notIliteralFilter:
+		return models.NewMatcher(models.MatchNotRegexp, name,
+			[]byte("(?i)"+literalsToRegexp(strings.Split(f.expr, "|"))))
+	case wildcardFilter:
+		return models.NewMatcher(models.MatchRegexp, name,
+			[]byte(wildcardToRegexp(f.expr)))
+	case iwildcardFilter:
+		return models.NewMatcher(models.MatchRegexp, name,
+			[]byte("(?i)"+wildcardToRegexp(f.expr)))
+	case regexpFilter:
+		// Regular expression filters match anywhere within the value.
+		return models.NewMatcher(models.MatchRegexp, name,
+			[]byte(".*(?:"+f.expr+").*"))
+	}
+
+	return models.Matcher{}, fmt.Errorf("unsupported filter type: %s", f.filterType)
+}
+
+func literalsToRegexp(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, regexp.QuoteMeta(v))
+	}
+
+	return strings.Join(quoted, "|")
+}
+
+// wildcardToRegexp converts a wildcard, where "*" matches any characters, to
+// a regular expression. A lone "*" matches any value of the tag, requiring
+// the tag to be set.
+func wildcardToRegexp(wildcard string) string {
+	if wildcard == "*" {
+		return ".+"
+	}
+
+	parts := strings.Split(wildcard, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	return strings.Join(parts, ".*")
+}
+
+// groupByTags returns the sorted and deduplicated tags grouped by the query.
+func (q subQuery) groupByTags() [][]byte {
+	seen := make(map[string]struct{}, len(q.filters))
+	for _, f := range q.filters {
+		if f.groupBy {
+			seen[f.tagKey] = struct{}{}
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	tags := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, []byte(k))
+	}
+
+	return tags
+}
+
+// aggregateTags returns the sorted and deduplicated tags filtered but not
+// grouped by the query, whose values are aggregated unless the aggregator
+// is none.
+func (q subQuery) aggregateTags() []string {
+	if aggregatorTypes[q.aggregator] == "" {
+		return []string{}
+	}
+
+	groupBy := make(map[string]struct{}, len(q.filters))
+	for _, f := range q.filters {
+		if f.groupBy {
+			groupBy[f.tagKey] = struct{}{}
+		}
+	}
+
+	seen := make(map[string]struct{}, len(q.filters))
+	keys := make([]string, 0, len(q.filters))
+	for _, f := range q.filters {
+		if _, ok := groupBy[f.tagKey]; ok {
+			continue
+		}
+
+		if _, ok := seen[f.tagKey]; !ok {
+			seen[f.tagKey] = struct{}{}
+			keys = append(keys, f.tagKey)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// queryParser translates a sub query to a DAG fetching the metric, reducing
+// each series over the step with the downsample function, computing the rate
+// of the downsampled values and aggregating the series of each group, in the
+// order OpenTSDB applies them.
+type queryParser struct {
+	query   subQuery
+	step    time.Duration
+	tagOpts models.TagOptions
+
+	edges      parser.Edges
+	transforms parser.Nodes
+}
+
+// parseFn returns a function parsing the query for the engine, the query
+// string being ignored as the query is already parsed.
+func (q subQuery) parseFn(step time.Duration) native.QueryParser {
+	return func(_ string, tagOpts models.TagOptions) (parser.Parser, error) {
+		return &queryParser{
+			query:   q,
+			step:    step,
+			tagOpts: tagOpts,
+		}, nil
+	}
+}
+
+func (p *queryParser) DAG() (parser.Nodes, parser.Edges, error) {
+	p.edges, p.transforms = nil, nil
+	fetchOp, err := p.newFetchOp()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := p.addTransform(fetchOp)
+	temporalOp, err := p.newTemporalOp()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if temporalOp != nil {
+		id = p.addTransform(temporalOp, id)
+	}
+
+	if ds := p.query.downsample; ds != nil && ds.fill == zeroFillPolicy {
+		op, err := linear.NewTransformNullOp([]interface{}{0.0})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		id = p.addTransform(op, id)
+	}
+
+	if p.query.rate && p.query.downsample != nil {
+		opType := linear.StepRateType
+		if p.query.counter {
+			opType = linear.StepCounterRateType
+		}
+
+		op, err := linear.NewStepRateOp(opType)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		id = p.addTransform(op, id)
+	}
+
+	if aggType := aggregatorTypes[p.query.aggregator]; aggType != "" {
+		op, err := aggregation.NewAggregationOp(aggType, aggregation.NodeParams{
+			MatchingTags: p.query.groupByTags(),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		p.addTransform(op, id)
+	}
+
+	return p.transforms, p.edges, nil
+}
+
+func (p *queryParser) String() string {
+	return p.query.String()
+}
+
+func (p *queryParser) addTransform(op parser.Params, parents ...parser.NodeID) parser.NodeID {
+	opTransform := parser.NewTransformFromOperation(op, len(p.transforms))
+	for _, parent := range parents {
+		p.edges = append(p.edges, parser.Edge{
+			ParentID: parent,
+			ChildID:  opTransform.ID,
+		})
+	}
+
+	p.transforms = append(p.transforms, opTransform)
+	return opTransform.ID
+}
+
+func (p *queryParser) newFetchOp() (parser.Params, error) {
+	nameMatcher, err := models.NewMatcher(models.MatchEqual,
+		p.tagOpts.MetricName(), []byte(p.query.metric))
+	if err != nil {
+		return nil, err
+	}
+
+	matchers := models.Matchers{nameMatcher}
+	for _, f := range p.query.filters {
+		matcher, err := f.matcher()
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	return functions.FetchOp{
+		Name:     p.query.metric,
+		Matchers: matchers,
+	}, nil
+}
+
+// newTemporalOp returns the operation reducing each series over the step,
+// or nil if the value of each step is the last datapoint. The rate of
+// downsampled series is computed from the downsampled values instead.
+func (p *queryParser) newTemporalOp() (parser.Params, error) {
+	if p.query.rate && p.query.downsample == nil {
+		// The rate at each step is the per second change between the last
+		// two datapoints preceding it, which are looked up over two steps so
+		// that a step with a single datapoint uses one of the previous step.
+		args := []interface{}{2 * p.step}
+		if p.query.counter {
+			return temporal.NewRateOp(args, temporal.IRateType)
+		}
+
+		return temporal.NewRateOp(args, temporal.IDerivType)
+	}
+
+	if p.query.downsample == nil {
+		return nil, nil
+	}
+
+	opType := downsampleTypes[p.query.downsample.fn]
+	if opType == "" {
+		return nil, nil
+	}
+
+	return temporal.NewAggOp([]interface{}{p.step}, opType)
+}
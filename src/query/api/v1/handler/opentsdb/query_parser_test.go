@@ -0,0 +1,243 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/linear"
+	"github.com/m3db/m3/src/query/functions/temporal"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSubQuery(t *testing.T) {
+	q, err := parseSubQuery(
+		"sum:5m-max-zero:sys.cpu.user{host=web*,dc=lga|sjc}{env=not_literal_or(dev)}")
+	require.NoError(t, err)
+	assert.Equal(t, subQuery{
+		aggregator: "sum",
+		metric:     "sys.cpu.user",
+		downsample: &downsample{interval: 5 * time.Minute, fn: "max", fill: "zero"},
+		filters: []filter{
+			{filterType: wildcardFilter, tagKey: "host", expr: "web*", groupBy: true},
+			{filterType: literalOrFilter, tagKey: "dc", expr: "lga|sjc", groupBy: true},
+			{filterType: notLiteralOrFilter, tagKey: "env", expr: "dev"},
+		},
+	}, q)
+	assert.Equal(t, "sum:5m-max-zero:sys.cpu.user{host=wildcard(web*),"+
+		"dc=literal_or(lga|sjc)}{env=not_literal_or(dev)}", q.String())
+	assert.Equal(t, [][]byte{[]byte("dc"), []byte("host")}, q.groupByTags())
+
+	q, err = parseSubQuery("avg:rate{counter,100,0}:if.bytes{host=regexp(web[0-9],1)}")
+	require.NoError(t, err)
+	assert.True(t, q.rate)
+	assert.True(t, q.counter)
+	assert.Nil(t, q.downsample)
+	assert.Equal(t, []filter{
+		{filterType: regexpFilter, tagKey: "host", expr: "web[0-9],1", groupBy: true},
+	}, q.filters)
+	assert.Equal(t, defaultStep, q.step(defaultStep))
+}
+
+func TestParseSubQueryErrors(t *testing.T) {
+	for _, m := range []string{
+		"sys.cpu.user",
+		"foo:sys.cpu.user",
+		"sum:1m-avg:5m-avg:sys.cpu.user",
+		"sum:1x-avg:sys.cpu.user",
+		"sum:1m-median:sys.cpu.user",
+		"sum:1m-avg-previous:sys.cpu.user",
+		"sum:sys.cpu.user{host=web",
+		"sum:sys.cpu.user{host}",
+		"sum:sys.cpu.user{a=b}{c=d}{e=f}",
+	} {
+		_, err := parseSubQuery(m)
+		assert.Error(t, err, m)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		out time.Duration
+	}{
+		{"500ms", 500 * time.Millisecond},
+		{"30s", 30 * time.Second},
+		{"5m", 5 * time.Minute},
+		{"2h", 2 * time.Hour},
+		{"1d", 24 * time.Hour},
+		{"1w", 7 * 24 * time.Hour},
+		{"1n", 30 * 24 * time.Hour},
+		{"1y", 365 * 24 * time.Hour},
+	} {
+		d, err := parseDuration(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.out, d, tt.in)
+		assert.Equal(t, tt.in, formatDuration(d))
+	}
+
+	for _, in := range []string{"", "m", "5", "5x", "-5m"} {
+		_, err := parseDuration(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestFilterMatchers(t *testing.T) {
+	for _, tt := range []struct {
+		filter   filter
+		matches  []string
+		excludes []string
+	}{
+		{
+			filter:   filter{filterType: literalOrFilter, expr: "web01"},
+			matches:  []string{"web01"},
+			excludes: []string{"WEB01", "web012"},
+		},
+		{
+			filter:   filter{filterType: literalOrFilter, expr: "web01|web.2"},
+			matches:  []string{"web01", "web.2"},
+			excludes: []string{"web02", "web0"},
+		},
+		{
+			filter:   filter{filterType: iliteralOrFilter, expr: "web01"},
+			matches:  []string{"web01", "WEB01"},
+			excludes: []string{"web02"},
+		},
+		{
+			filter:   filter{filterType: notLiteralOrFilter, expr: "web01|web02"},
+			matches:  []string{"web03"},
+			excludes: []string{"web01", "web02"},
+		},
+		{
+			filter:   filter{filterType: notIliteralFilter, expr: "web01"},
+			matches:  []string{"web02"},
+			excludes: []string{"WEB01"},
+		},
+		{
+			filter:   filter{filterType: wildcardFilter, expr: "*"},
+			matches:  []string{"web01"},
+			excludes: []string{""},
+		},
+		{
+			filter:   filter{filterType: wildcardFilter, expr: "web*.lga"},
+			matches:  []string{"web.lga", "web01.lga"},
+			excludes: []string{"web01xlga", "db01.lga"},
+		},
+		{
+			filter:   filter{filterType: iwildcardFilter, expr: "web*"},
+			matches:  []string{"WEB01"},
+			excludes: []string{"db01"},
+		},
+		{
+			filter:   filter{filterType: regexpFilter, expr: "eb[0-9]"},
+			matches:  []string{"web1", "web1.lga"},
+			excludes: []string{"webx"},
+		},
+	} {
+		tt.filter.tagKey = "host"
+		matcher, err := tt.filter.matcher()
+		require.NoError(t, err, tt.filter.expr)
+		for _, v := range tt.matches {
+			assert.True(t, matcher.Matches([]byte(v)), "%s: %s", tt.filter.expr, v)
+		}
+
+		for _, v := range tt.excludes {
+			assert.False(t, matcher.Matches([]byte(v)), "%s: %s", tt.filter.expr, v)
+		}
+	}
+}
+
+func TestQueryParserDAG(t *testing.T) {
+	q, err := parseSubQuery("sum:5m-avg-zero:sys.cpu.user{host=*}")
+	require.NoError(t, err)
+
+	p, err := q.parseFn(q.step(defaultStep))("", models.NewTagOptions())
+	require.NoError(t, err)
+	assert.Equal(t, q.String(), p.String())
+
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 4)
+	assert.Equal(t, functions.FetchType, transforms[0].Op.OpType())
+	assert.Equal(t, temporal.AvgType, transforms[1].Op.OpType())
+	assert.Equal(t, linear.TransformNullType, transforms[2].Op.OpType())
+	assert.Equal(t, aggregation.SumType, transforms[3].Op.OpType())
+
+	fetch, ok := transforms[0].Op.(functions.FetchOp)
+	require.True(t, ok)
+	assert.Equal(t, "sys.cpu.user", fetch.Name)
+	require.Len(t, fetch.Matchers, 2)
+	assert.Equal(t, models.MatchEqual, fetch.Matchers[0].Type)
+	assert.Equal(t, "__name__", string(fetch.Matchers[0].Name))
+	assert.Equal(t, "host", string(fetch.Matchers[1].Name))
+
+	require.Len(t, edges, 3)
+	assert.Equal(t, parser.Edge{ParentID: "0", ChildID: "1"}, edges[0])
+	assert.Equal(t, parser.Edge{ParentID: "1", ChildID: "2"}, edges[1])
+	assert.Equal(t, parser.Edge{ParentID: "2", ChildID: "3"}, edges[2])
+}
+
+func TestQueryParserDAGOperations(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		ops   []string
+	}{
+		{"none:sys.cpu.user", []string{functions.FetchType}},
+		{"max:1h-last:sys.cpu.user", []string{functions.FetchType,
+			aggregation.MaxType}},
+		{"dev:1m-count-nan:sys.cpu.user", []string{functions.FetchType,
+			temporal.CountType, aggregation.StandardDeviationType}},
+		{"avg:rate:sys.cpu.user", []string{functions.FetchType,
+			temporal.IDerivType, aggregation.AverageType}},
+		{"zimsum:rate{counter}:if.bytes", []string{functions.FetchType,
+			temporal.IRateType, aggregation.SumType}},
+		{"none:1m-max:rate:sys.cpu.user", []string{functions.FetchType,
+			temporal.MaxType, linear.StepRateType}},
+		{"sum:1m-last:rate{counter}:if.bytes", []string{functions.FetchType,
+			linear.StepCounterRateType, aggregation.SumType}},
+		{"count:1m-avg-zero:rate:sys.cpu.user", []string{functions.FetchType,
+			temporal.AvgType, linear.TransformNullType, linear.StepRateType,
+			aggregation.CountType}},
+	} {
+		q, err := parseSubQuery(tt.query)
+		require.NoError(t, err, tt.query)
+
+		p, err := q.parseFn(q.step(defaultStep))("", models.NewTagOptions())
+		require.NoError(t, err, tt.query)
+
+		transforms, _, err := p.DAG()
+		require.NoError(t, err, tt.query)
+
+		ops := make([]string, 0, len(transforms))
+		for _, transform := range transforms {
+			ops = append(ops, transform.Op.OpType())
+		}
+
+		assert.Equal(t, tt.ops, ops, tt.query)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package opentsdb
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+var timeoutOpts = &prometheus.TimeoutOpts{
+	FetchTimeout: 15 * time.Second,
+}
+
+func TestQuery(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	start := time.Unix(1556813520, 0)
+	values, bounds := test.GenerateValuesAndBounds(nil, &models.Bounds{
+		Start:    start,
+		Duration: 5 * time.Minute,
+		StepSize: time.Minute,
+	})
+	storage := mock.NewMockStorage()
+	b := test.NewBlockFromValues(bounds, values)
+	storage.SetFetchBlocksResult(block.Result{Blocks: []block.Block{b}}, nil)
+
+	h := NewQueryHandler(
+		executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil, nil),
+		models.NewTagOptions(),
+		timeoutOpts,
+	)
+
+	params := url.Values{}
+	params.Set(startParam, "1556813520")
+	params.Set(endParam, "1556813820")
+	params.Set(queryParam, "none:sys.cpu.user")
+	req := httptest.NewRequest(http.MethodGet, QueryURL+"?"+params.Encode(), nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var results []queryResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	require.Len(t, results, 2)
+	assert.Equal(t, "sys.cpu.user", results[0].Metric)
+	assert.Equal(t, map[string]string{"dummy0": "dummy0"}, results[0].Tags)
+	assert.Equal(t, []string{}, results[0].AggregateTags)
+	assert.Len(t, results[0].Datapoints, 5)
+	assert.Equal(t, float64(0), results[0].Datapoints["1556813520"])
+	assert.Equal(t, float64(9), results[1].Datapoints["1556813760"])
+}
+
+func TestQueryAggregated(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	start := time.Unix(1556813520, 0)
+	values, bounds := test.GenerateValuesAndBounds([][]float64{
+		{60, 120, 180, 240, 300},
+		{0, 60, 240, 360, 480},
+	}, &models.Bounds{
+		Start:    start,
+		Duration: 5 * time.Minute,
+		StepSize: time.Minute,
+	})
+	storage := mock.NewMockStorage()
+	b := test.NewBlockFromValues(bounds, values)
+	storage.SetFetchBlocksResult(block.Result{Blocks: []block.Block{b}}, nil)
+
+	h := NewQueryHandler(
+		executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil, nil),
+		models.NewTagOptions(),
+		timeoutOpts,
+	)
+
+	for _, tt := range []struct {
+		query    string
+		expected map[string]float64
+	}{
+		{"sum:sys.cpu.user", map[string]float64{
+			"1556813520": 60, "1556813580": 180, "1556813640": 420,
+			"1556813700": 600, "1556813760": 780,
+		}},
+		{"max:1m-last:rate:sys.cpu.user", map[string]float64{
+			"1556813580": 1, "1556813640": 3, "1556813700": 2,
+			"1556813760": 2,
+		}},
+	} {
+		params := url.Values{}
+		params.Set(startParam, "1556813520")
+		params.Set(endParam, "1556813820")
+		params.Set(queryParam, tt.query)
+		req := httptest.NewRequest(http.MethodGet, QueryURL+"?"+params.Encode(), nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var results []queryResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+		require.Len(t, results, 1, tt.query)
+		assert.Equal(t, map[string]string{}, results[0].Tags, tt.query)
+		assert.Equal(t, tt.expected, results[0].Datapoints, tt.query)
+	}
+}
+
+func TestNewQueryResultsAggregated(t *testing.T) {
+	start := time.Unix(1556813520, 0)
+	newSeries := func(values []float64, tags ...string) *ts.Series {
+		dps := make(ts.Datapoints, 0, len(values))
+		for i, v := range values {
+			dps = append(dps, ts.Datapoint{
+				Timestamp: start.Add(time.Duration(i) * time.Minute),
+				Value:     v,
+			})
+		}
+
+		modelTags := models.EmptyTags()
+		for i := 0; i < len(tags); i += 2 {
+			modelTags = modelTags.AddTag(models.Tag{
+				Name:  []byte(tags[i]),
+				Value: []byte(tags[i+1]),
+			})
+		}
+
+		return ts.NewSeries([]byte("sys.cpu.user"), dps, modelTags)
+	}
+
+	q, err := parseSubQuery("sum:sys.cpu.user{host=*}{dc=lga|sjc,env=prod}")
+	require.NoError(t, err)
+
+	results := newQueryResults(q, []*ts.Series{
+		newSeries([]float64{3, 3}, "host", "a"),
+		newSeries([]float64{5, math.NaN()}, "host", "b"),
+	}, queryParams{start: start}, models.NewTagOptions())
+	require.Len(t, results, 2)
+
+	assert.Equal(t, map[string]string{"host": "a"}, results[0].Tags)
+	assert.Equal(t, []string{"dc", "env"}, results[0].AggregateTags)
+	assert.Equal(t, map[string]float64{"1556813520": 3, "1556813580": 3},
+		results[0].Datapoints)
+
+	assert.Equal(t, map[string]string{"host": "b"}, results[1].Tags)
+	assert.Equal(t, []string{"dc", "env"}, results[1].AggregateTags)
+	assert.Equal(t, map[string]float64{"1556813520": 5}, results[1].Datapoints)
+
+	q, err = parseSubQuery("none:sys.cpu.user{dc=lga}")
+	require.NoError(t, err)
+
+	results = newQueryResults(q, []*ts.Series{
+		newSeries([]float64{1}, "__name__", "sys.cpu.user", "dc", "lga", "host", "a"),
+	}, queryParams{start: start}, models.NewTagOptions())
+	require.Len(t, results, 1)
+	assert.Equal(t, map[string]string{"dc": "lga", "host": "a"}, results[0].Tags)
+	assert.Equal(t, []string{}, results[0].AggregateTags)
+}
+
+func TestQueryInvalidParams(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	h := NewQueryHandler(
+		executor.NewEngine(mock.NewMockStorage(), tally.NewTestScope("test", nil),
+			time.Minute, nil, nil),
+		models.NewTagOptions(),
+		timeoutOpts,
+	)
+
+	for _, rawQuery := range []string{
+		"m=sum:sys.cpu.user",
+		"start=1h-ago",
+		"start=1h-ago&m=foo:sys.cpu.user",
+		"start=yesterday&m=sum:sys.cpu.user",
+		"start=now&end=1h-ago&m=sum:sys.cpu.user",
+	} {
+		req := httptest.NewRequest(http.MethodGet, QueryURL+"?"+rawQuery, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, rawQuery)
+	}
+}
+
+func TestParseQueryParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet,
+		QueryURL+"?start=1556813520000&end=1556813820&ms&"+
+			"m=sum:1m-avg:sys.cpu.user{host=web01}&m=max:sys.cpu.sys", nil)
+	params, err := parseQueryParams(req, timeoutOpts)
+	require.Nil(t, err)
+	assert.Equal(t, time.Unix(1556813520, 0), params.start)
+	assert.Equal(t, time.Unix(1556813820, 0), params.end)
+	assert.True(t, params.msResolution)
+	assert.Equal(t, timeoutOpts.FetchTimeout, params.timeout)
+	require.Len(t, params.queries, 2)
+	assert.Equal(t, "sum:1m-avg:sys.cpu.user{host=literal_or(web01)}",
+		params.queries[0].String())
+	assert.Equal(t, "max:sys.cpu.sys", params.queries[1].String())
+}
+
+func TestParseQueryParamsBody(t *testing.T) {
+	body := `{
+		"start": "2019/05/02-16:12:00",
+		"end": 1556813820,
+		"msResolution": true,
+		"queries": [
+			{
+				"aggregator": "sum",
+				"metric": "if.bytes",
+				"rate": true,
+				"rateOptions": {"counter": true},
+				"filters": [
+					{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": true},
+					{"type": "LITERAL_OR", "tagk": "dc", "filter": "lga", "groupBy": false}
+				]
+			}
+		]
+	}`
+	req := httptest.NewRequest(http.MethodPost, QueryURL, strings.NewReader(body))
+	params, err := parseQueryParams(req, timeoutOpts)
+	require.Nil(t, err)
+	assert.True(t, time.Date(2019, time.May, 2, 16, 12, 0, 0, time.UTC).Equal(params.start))
+	assert.Equal(t, time.Unix(1556813820, 0), params.end)
+	assert.True(t, params.msResolution)
+	require.Len(t, params.queries, 1)
+	assert.Equal(t, "sum:rate{counter}:if.bytes{host=wildcard(web*)}"+
+		"{dc=literal_or(lga)}", params.queries[0].String())
+
+	req = httptest.NewRequest(http.MethodPost, QueryURL, strings.NewReader(
+		`{"start": "1h-ago", "queries": [{"aggregator": "sum", "metric": "a", "filters": [{"type": "foo", "tagk": "a"}]}]}`))
+	_, err = parseQueryParams(req, timeoutOpts)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Code())
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Unix(1556813820, 0)
+	for _, tt := range []struct {
+		in  string
+		out time.Time
+	}{
+		{"now", now},
+		{"1h-ago", now.Add(-time.Hour)},
+		{"30s-ago", now.Add(-30 * time.Second)},
+		{"1556813520", time.Unix(1556813520, 0)},
+		{"1556813520123", time.Unix(1556813520, 123*int64(time.Millisecond))},
+		{"2019/05/02-16:12:00", time.Date(2019, time.May, 2, 16, 12, 0, 0, time.UTC)},
+		{"2019/05/02 16:12:00", time.Date(2019, time.May, 2, 16, 12, 0, 0, time.UTC)},
+		{"2019/05/02-16:12", time.Date(2019, time.May, 2, 16, 12, 0, 0, time.UTC)},
+		{"2019/05/02", time.Date(2019, time.May, 2, 0, 0, 0, 0, time.UTC)},
+	} {
+		parsed, err := parseTime(tt.in, now)
+		require.NoError(t, err, tt.in)
+		assert.True(t, tt.out.Equal(parsed), tt.in)
+	}
+
+	for _, in := range []string{"", "yesterday", "1x-ago", "2019-05-02"} {
+		_, err := parseTime(in, now)
+		assert.Error(t, err, in)
+	}
+}
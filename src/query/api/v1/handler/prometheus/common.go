@@ -26,9 +26,12 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/m3db/m3/src/query/errors"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
 	"github.com/m3db/m3/src/query/models"
 	xpromql "github.com/m3db/m3/src/query/parser/promql"
 	"github.com/m3db/m3/src/query/storage"
@@ -46,6 +49,7 @@ const (
 	NameReplace         = "name"
 	queryParam          = "query"
 	filterNameTagsParam = "tag"
+	matchParam          = "match[]"
 	errFormatStr        = "error parsing param: %s, error: %v"
 
 	maxTimeout = 5 * time.Minute
@@ -191,7 +195,7 @@ func ParseSeriesMatchQuery(
 	tagOptions models.TagOptions,
 ) ([]*storage.FetchQuery, *xhttp.ParseError) {
 	r.ParseForm()
-	matcherValues := r.Form["match[]"]
+	matcherValues := r.Form[matchParam]
 	if len(matcherValues) == 0 {
 		return nil, xhttp.NewParseError(errors.ErrInvalidMatchers, http.StatusBadRequest)
 	}
@@ -208,12 +212,7 @@ func ParseSeriesMatchQuery(
 
 	queries := make([]*storage.FetchQuery, len(matcherValues))
 	for i, s := range matcherValues {
-		promMatchers, err := promql.ParseMetricSelector(s)
-		if err != nil {
-			return nil, xhttp.NewParseError(err, http.StatusBadRequest)
-		}
-
-		matchers, err := xpromql.LabelMatchersToModelMatcher(promMatchers, tagOptions)
+		matchers, err := parseSeriesSelector(s, tagOptions)
 		if err != nil {
 			return nil, xhttp.NewParseError(err, http.StatusBadRequest)
 		}
@@ -229,28 +228,104 @@ func ParseSeriesMatchQuery(
 	return queries, nil
 }
 
-// ParseTagValuesToQuery parses a tag values request to a complete tags query.
-func ParseTagValuesToQuery(
+func parseSeriesSelector(
+	selector string,
+	tagOptions models.TagOptions,
+) (models.Matchers, error) {
+	promMatchers, err := promql.ParseMetricSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return xpromql.LabelMatchersToModelMatcher(promMatchers, tagOptions)
+}
+
+// ParseTagValuesToQueries parses a tag values request to complete tags
+// queries, one for each of the series selectors of the request. Requests
+// without selectors match every series with the tag.
+func ParseTagValuesToQueries(
 	r *http.Request,
-) (*storage.CompleteTagsQuery, error) {
+	tagOptions models.TagOptions,
+) ([]*storage.CompleteTagsQuery, *xhttp.ParseError) {
 	vars := mux.Vars(r)
 	name, ok := vars[NameReplace]
 	if !ok || len(name) == 0 {
-		return nil, errors.ErrNoName
+		return nil, xhttp.NewParseError(errors.ErrNoName, http.StatusBadRequest)
 	}
 
 	nameBytes := []byte(name)
-	return &storage.CompleteTagsQuery{
-		CompleteNameOnly: false,
-		FilterNameTags:   [][]byte{nameBytes},
-		TagMatchers: models.Matchers{
-			models.Matcher{
-				Type:  models.MatchRegexp,
-				Name:  nameBytes,
-				Value: matchValues,
-			},
-		},
-	}, nil
+	return parseCompleteTagsQueries(r, tagOptions, false, [][]byte{nameBytes},
+		models.Matcher{
+			Type:  models.MatchRegexp,
+			Name:  nameBytes,
+			Value: matchValues,
+		})
+}
+
+// ParseTagNamesToQueries parses a tag names request to complete tags
+// queries, one for each of the series selectors of the request. Requests
+// without selectors match every series with a metric name.
+func ParseTagNamesToQueries(
+	r *http.Request,
+	tagOptions models.TagOptions,
+) ([]*storage.CompleteTagsQuery, *xhttp.ParseError) {
+	return parseCompleteTagsQueries(r, tagOptions, true, nil,
+		models.Matcher{
+			Type:  models.MatchRegexp,
+			Name:  tagOptions.MetricName(),
+			Value: matchValues,
+		})
+}
+
+func parseCompleteTagsQueries(
+	r *http.Request,
+	tagOptions models.TagOptions,
+	nameOnly bool,
+	filterNameTags [][]byte,
+	defaultMatcher models.Matcher,
+) ([]*storage.CompleteTagsQuery, *xhttp.ParseError) {
+	if err := r.ParseForm(); err != nil {
+		return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	start, err := parseTimeWithDefault(r, "start", time.Time{})
+	if err != nil {
+		return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	end, err := parseTimeWithDefault(r, "end", time.Now())
+	if err != nil {
+		return nil, xhttp.NewParseError(err, http.StatusBadRequest)
+	}
+
+	selectors := r.Form[matchParam]
+	matchers := make([]models.Matchers, 0, len(selectors))
+	for _, s := range selectors {
+		m, err := parseSeriesSelector(s, tagOptions)
+		if err != nil {
+			return nil, xhttp.NewParseError(
+				fmt.Errorf(errFormatStr, matchParam, err), http.StatusBadRequest)
+		}
+
+		matchers = append(matchers, m)
+	}
+
+	if len(matchers) == 0 {
+		matchers = append(matchers, models.Matchers{defaultMatcher})
+	}
+
+	queries := make([]*storage.CompleteTagsQuery, 0, len(matchers))
+	for _, m := range matchers {
+		queries = append(queries, &storage.CompleteTagsQuery{
+			CompleteNameOnly: nameOnly,
+			FilterNameTags:   filterNameTags,
+			TagMatchers:      m,
+			Start:            start,
+			End:              end,
+		})
+	}
+
+	return queries, nil
 }
 
 func renderNameOnlyTagCompletionResultsJSON(
@@ -317,6 +392,35 @@ func RenderTagCompletionResultsJSON(
 	return renderDefaultTagCompletionResultsJSON(w, results)
 }
 
+// RenderTagNamesResultsJSON renders tag names results to json format.
+func RenderTagNamesResultsJSON(
+	w io.Writer,
+	result *storage.CompleteTagsResult,
+) error {
+	if !result.CompleteNameOnly {
+		return errors.ErrWithNames
+	}
+
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+
+	jw.BeginObjectField("status")
+	jw.WriteString("success")
+
+	jw.BeginObjectField("data")
+	jw.BeginArray()
+
+	for _, tag := range result.CompletedTags {
+		jw.WriteString(string(tag.Name))
+	}
+
+	jw.EndArray()
+
+	jw.EndObject()
+
+	return jw.Close()
+}
+
 // RenderTagValuesResultsJSON renders tag values results to json format.
 func RenderTagValuesResultsJSON(
 	w io.Writer,
@@ -362,6 +466,65 @@ func RenderTagValuesResultsJSON(
 	return jw.Close()
 }
 
+// RenderMetricMetadataResultsJSON renders metric metadata results to json
+// format, grouped by metric family name. At most limit metric families are
+// rendered, or all of them if limit is negative.
+func RenderMetricMetadataResultsJSON(
+	w io.Writer,
+	metadata []*prompb.MetricMetadata,
+	limit int,
+) error {
+	byMetric := make(map[string][]*prompb.MetricMetadata)
+	for _, m := range metadata {
+		byMetric[m.MetricFamilyName] = append(byMetric[m.MetricFamilyName], m)
+	}
+
+	metrics := make([]string, 0, len(byMetric))
+	for metric := range byMetric {
+		metrics = append(metrics, metric)
+	}
+
+	sort.Strings(metrics)
+	if limit >= 0 && limit < len(metrics) {
+		metrics = metrics[:limit]
+	}
+
+	jw := json.NewWriter(w)
+	jw.BeginObject()
+
+	jw.BeginObjectField("status")
+	jw.WriteString("success")
+
+	jw.BeginObjectField("data")
+	jw.BeginObject()
+
+	for _, metric := range metrics {
+		jw.BeginObjectField(metric)
+		jw.BeginArray()
+		for _, m := range byMetric[metric] {
+			jw.BeginObject()
+
+			jw.BeginObjectField("type")
+			jw.WriteString(strings.ToLower(m.Type.String()))
+
+			jw.BeginObjectField("help")
+			jw.WriteString(m.Help)
+
+			jw.BeginObjectField("unit")
+			jw.WriteString(m.Unit)
+
+			jw.EndObject()
+		}
+		jw.EndArray()
+	}
+
+	jw.EndObject()
+
+	jw.EndObject()
+
+	return jw.Close()
+}
+
 // RenderSeriesMatchResultsJSON renders series match results to json format.
 func RenderSeriesMatchResultsJSON(
 	w io.Writer,
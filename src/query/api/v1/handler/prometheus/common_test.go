@@ -24,14 +24,18 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/test"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPromCompressedReadSuccess(t *testing.T) {
@@ -153,3 +157,93 @@ func TestRenderSeriesMatchResultsNoTags(t *testing.T) {
 		assert.Equal(t, expected, w.value)
 	}
 }
+
+func TestParseTagValuesToQueries(t *testing.T) {
+	params := url.Values{
+		"match[]": []string{"up", `{env="prod"}`},
+		"start":   []string{"100"},
+		"end":     []string{"200"},
+	}
+
+	req, _ := http.NewRequest("GET", "/label/job/values?"+params.Encode(), nil)
+	req = mux.SetURLVars(req, map[string]string{NameReplace: "job"})
+
+	queries, err := ParseTagValuesToQueries(req, models.NewTagOptions())
+	require.Nil(t, err)
+	require.Len(t, queries, 2)
+	for _, query := range queries {
+		assert.False(t, query.CompleteNameOnly)
+		assert.Equal(t, [][]byte{[]byte("job")}, query.FilterNameTags)
+		assert.Equal(t, time.Unix(100, 0), query.Start)
+		assert.Equal(t, time.Unix(200, 0), query.End)
+	}
+
+	assert.Equal(t, models.Matchers{{
+		Type:  models.MatchEqual,
+		Name:  []byte("__name__"),
+		Value: []byte("up"),
+	}}, queries[0].TagMatchers)
+	assert.Equal(t, models.Matchers{{
+		Type:  models.MatchEqual,
+		Name:  []byte("env"),
+		Value: []byte("prod"),
+	}}, queries[1].TagMatchers)
+}
+
+func TestParseTagValuesToQueriesNoSelectors(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/label/job/values", nil)
+	req = mux.SetURLVars(req, map[string]string{NameReplace: "job"})
+
+	queries, err := ParseTagValuesToQueries(req, models.NewTagOptions())
+	require.Nil(t, err)
+	require.Len(t, queries, 1)
+	assert.Equal(t, models.Matchers{{
+		Type:  models.MatchRegexp,
+		Name:  []byte("job"),
+		Value: []byte(".*"),
+	}}, queries[0].TagMatchers)
+	assert.True(t, queries[0].Start.IsZero())
+	assert.False(t, queries[0].End.IsZero())
+}
+
+func TestParseTagValuesToQueriesBadSelector(t *testing.T) {
+	params := url.Values{"match[]": []string{"up{"}}
+	req, _ := http.NewRequest("GET", "/label/job/values?"+params.Encode(), nil)
+	req = mux.SetURLVars(req, map[string]string{NameReplace: "job"})
+
+	_, err := ParseTagValuesToQueries(req, models.NewTagOptions())
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.Code())
+}
+
+func TestParseTagNamesToQueries(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/labels", nil)
+
+	queries, err := ParseTagNamesToQueries(req, models.NewTagOptions())
+	require.Nil(t, err)
+	require.Len(t, queries, 1)
+	assert.True(t, queries[0].CompleteNameOnly)
+	assert.Nil(t, queries[0].FilterNameTags)
+	assert.Equal(t, models.Matchers{{
+		Type:  models.MatchRegexp,
+		Name:  []byte("__name__"),
+		Value: []byte(".*"),
+	}}, queries[0].TagMatchers)
+}
+
+func TestRenderTagNamesResults(t *testing.T) {
+	w := &writer{value: ""}
+	err := RenderTagNamesResultsJSON(w, &storage.CompleteTagsResult{
+		CompleteNameOnly: true,
+		CompletedTags: []storage.CompletedTag{
+			{Name: []byte("__name__")},
+			{Name: []byte("job")},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"status":"success","data":["__name__","job"]}`, w.value)
+
+	err = RenderTagNamesResultsJSON(w, &storage.CompleteTagsResult{})
+	assert.Error(t, err)
+}
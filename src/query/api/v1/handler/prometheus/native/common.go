@@ -29,6 +29,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/m3db/m3/src/query/api/v1/handler"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/functions/utils"
@@ -51,6 +52,11 @@ const (
 	debugParam        = "debug"
 	endExclusiveParam = "end-exclusive"
 	blockTypeParam    = "block-type"
+	statsParam        = "stats"
+
+	// statsAll is the value of the stats param requesting the execution
+	// statistics of the query in the response.
+	statsAll = "all"
 
 	formatErrStr = "error parsing param: %s, error: %v"
 )
@@ -121,6 +127,7 @@ func parseParams(r *http.Request, timeoutOpts *prometheus.TimeoutOpts) (models.R
 	params.Query = query
 	params.Debug = parseDebugFlag(r)
 	params.BlockType = parseBlockType(r)
+	params.Tenant = r.Header.Get(handler.TenantHeader)
 	// Default to including end if unable to parse the flag
 	endExclusiveVal := r.FormValue(endExclusiveParam)
 	params.IncludeEnd = true
@@ -140,6 +147,10 @@ func parseParams(r *http.Request, timeoutOpts *prometheus.TimeoutOpts) (models.R
 	return params, nil
 }
 
+func parseStatsFlag(r *http.Request) bool {
+	return r.FormValue(statsParam) == statsAll
+}
+
 func parseDebugFlag(r *http.Request) bool {
 	var (
 		debug bool
@@ -211,6 +222,7 @@ func parseInstantaneousParams(r *http.Request, timeoutOpts *prometheus.TimeoutOp
 	params.Query = query
 	params.Debug = parseDebugFlag(r)
 	params.BlockType = parseBlockType(r)
+	params.Tenant = r.Header.Get(handler.TenantHeader)
 	return params, nil
 }
 
@@ -233,6 +245,7 @@ func renderResultsJSON(
 	series []*ts.Series,
 	params models.RequestParams,
 	keepNans bool,
+	stats *models.QueryStats,
 ) {
 	jw := json.NewWriter(w)
 	jw.BeginObject()
@@ -295,12 +308,45 @@ func renderResultsJSON(
 	}
 	jw.EndArray()
 
+	if stats != nil {
+		jw.BeginObjectField("stats")
+		renderStatsJSON(jw, stats.Summary())
+	}
+
 	jw.EndObject()
 
 	jw.EndObject()
 	jw.Close()
 }
 
+func renderStatsJSON(jw *json.Writer, summary models.QueryStatsSummary) {
+	jw.BeginObject()
+
+	jw.BeginObjectField("seriesFetched")
+	jw.WriteInt(int(summary.SeriesFetched))
+
+	jw.BeginObjectField("datapointsDecoded")
+	jw.WriteInt(int(summary.DatapointsDecoded))
+
+	jw.BeginObjectField("nodes")
+	jw.BeginArray()
+	for _, node := range summary.Nodes {
+		jw.BeginObject()
+		jw.BeginObjectField("id")
+		jw.WriteString(node.ID)
+		jw.BeginObjectField("op")
+		jw.WriteString(node.Op)
+		jw.BeginObjectField("blocks")
+		jw.WriteInt(node.Blocks)
+		jw.BeginObjectField("timeNanos")
+		jw.WriteInt(int(node.Time))
+		jw.EndObject()
+	}
+	jw.EndArray()
+
+	jw.EndObject()
+}
+
 func renderResultsInstantaneousJSON(
 	w io.Writer,
 	series []*ts.Series,
@@ -180,7 +180,7 @@ func TestRenderResultsJSON(t *testing.T) {
 			})),
 	}
 
-	renderResultsJSON(buffer, series, params, true)
+	renderResultsJSON(buffer, series, params, true, nil)
 
 	expected := mustPrettyJSON(t, `
 	{
@@ -273,7 +273,7 @@ func TestRenderResultsJSONWithDroppedNaNs(t *testing.T) {
 			})),
 	}
 
-	renderResultsJSON(buffer, series, params, false)
+	renderResultsJSON(buffer, series, params, false, nil)
 
 	expected := mustPrettyJSON(t, `
 	{
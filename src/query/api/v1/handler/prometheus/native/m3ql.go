@@ -0,0 +1,75 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"net/http"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/m3ql"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	// M3QLReadURL is the url for the M3QL range read handler
+	M3QLReadURL = handler.RoutePrefixV1 + "/m3ql/query_range"
+
+	// M3QLReadHTTPMethod is the HTTP method used with this resource.
+	M3QLReadHTTPMethod = http.MethodGet
+
+	// M3QLReadInstantURL is the url for the M3QL instantaneous read handler
+	M3QLReadInstantURL = handler.RoutePrefixV1 + "/m3ql/query"
+
+	// M3QLReadInstantHTTPMethod is the HTTP method used with this resource.
+	M3QLReadInstantHTTPMethod = http.MethodGet
+)
+
+// NewM3QLReadHandler returns a new instance of a range read handler for
+// M3QL queries, which responds with the same JSON as the Prometheus handler.
+func NewM3QLReadHandler(
+	engine *executor.Engine,
+	tagOpts models.TagOptions,
+	limitsCfg *config.LimitsConfiguration,
+	scope tally.Scope,
+	timeoutOpts *prometheus.TimeoutOpts,
+	keepNans bool,
+) *PromReadHandler {
+	h := NewPromReadHandler(engine, tagOpts, limitsCfg, scope, timeoutOpts, keepNans, nil, nil, nil)
+	h.parseFn = m3ql.Parse
+	return h
+}
+
+// NewM3QLReadInstantHandler returns a new instance of an instantaneous read
+// handler for M3QL queries.
+func NewM3QLReadInstantHandler(
+	engine *executor.Engine,
+	tagOpts models.TagOptions,
+	timeoutOpts *prometheus.TimeoutOpts,
+) *PromReadInstantHandler {
+	h := NewPromReadInstantHandler(engine, tagOpts, timeoutOpts)
+	h.parseFn = m3ql.Parse
+	return h
+}
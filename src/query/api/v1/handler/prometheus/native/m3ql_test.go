@@ -0,0 +1,84 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3query/config"
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestM3QLReadHandler_Read(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	values, bounds := test.GenerateValuesAndBounds(nil, nil)
+	storage := mock.NewMockStorage()
+	b := test.NewBlockFromValues(bounds, values)
+	storage.SetFetchBlocksResult(block.Result{Blocks: []block.Block{b}}, nil)
+
+	m3qlRead := NewM3QLReadHandler(
+		executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil, nil),
+		models.NewTagOptions(),
+		&config.LimitsConfiguration{},
+		tally.NewTestScope("", nil),
+		timeoutOpts,
+		false,
+	)
+
+	params := defaultParams()
+	params.Set(queryParam, "fetch name:http_requests_total job:prometheus | abs")
+	req, err := http.NewRequest("GET", M3QLReadURL, nil)
+	require.NoError(t, err)
+	req.URL.RawQuery = params.Encode()
+
+	r, parseErr := parseParams(req, timeoutOpts)
+	require.Nil(t, parseErr)
+	seriesList, err := read(context.TODO(), m3qlRead.engine, m3qlRead.parseFn,
+		m3qlRead.tagOpts, httptest.NewRecorder(), r)
+	require.NoError(t, err)
+	require.Len(t, seriesList, 2)
+
+	s := seriesList[0]
+	assert.Equal(t, 5, s.Values().Len())
+	for i := 0; i < s.Values().Len(); i++ {
+		assert.Equal(t, float64(i), s.Values().ValueAt(i))
+	}
+
+	// PromQL queries are not valid M3QL.
+	r.Query = promQuery
+	_, err = read(context.TODO(), m3qlRead.engine, m3qlRead.parseFn,
+		m3qlRead.tagOpts, httptest.NewRecorder(), r)
+	assert.Error(t, err)
+}
@@ -24,17 +24,21 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/query/api/v1/handler"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
 	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/util/httperrors"
 	"github.com/m3db/m3/src/query/util/logging"
 	opentracingutil "github.com/m3db/m3/src/query/util/opentracing"
+	xhttp "github.com/m3db/m3/src/x/net/http"
 
 	opentracingext "github.com/opentracing/opentracing-go/ext"
 	opentracinglog "github.com/opentracing/opentracing-go/log"
@@ -62,11 +66,15 @@ var (
 // PromReadHandler represents a handler for prometheus read endpoint.
 type PromReadHandler struct {
 	engine          *executor.Engine
+	parseFn         QueryParser
 	tagOpts         models.TagOptions
 	limitsCfg       *config.LimitsConfiguration
 	promReadMetrics promReadMetrics
 	timeoutOps      *prometheus.TimeoutOpts
 	keepNans        bool
+	resultsCache    *cache.ResultsCache
+	splitter        *QuerySplitter
+	slowQueryLogger *SlowQueryLogger
 }
 
 type promReadMetrics struct {
@@ -111,14 +119,21 @@ func NewPromReadHandler(
 	scope tally.Scope,
 	timeoutOpts *prometheus.TimeoutOpts,
 	keepNans bool,
+	resultsCache *cache.ResultsCache,
+	splitter *QuerySplitter,
+	slowQueryLogger *SlowQueryLogger,
 ) *PromReadHandler {
 	h := &PromReadHandler{
 		engine:          engine,
+		parseFn:         promql.Parse,
 		tagOpts:         tagOpts,
 		limitsCfg:       limitsCfg,
 		promReadMetrics: newPromReadMetrics(scope),
 		timeoutOps:      timeoutOpts,
 		keepNans:        keepNans,
+		resultsCache:    resultsCache,
+		splitter:        splitter,
+		slowQueryLogger: slowQueryLogger,
 	}
 
 	h.promReadMetrics.maxDatapoints.Update(float64(limitsCfg.MaxComputedDatapoints()))
@@ -128,7 +143,7 @@ func NewPromReadHandler(
 func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	timer := h.promReadMetrics.fetchTimerSuccess.Start()
 
-	result, params, respErr := h.ServeHTTPWithEngine(w, r, h.engine)
+	result, params, stats, respErr := h.serveHTTP(w, r, h.engine, h.resultsCache)
 	if respErr != nil {
 		httperrors.ErrorWithReqInfo(w, r, respErr.Code, respErr.Err)
 		return
@@ -145,7 +160,7 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.promReadMetrics.fetchSuccess.Inc(1)
 	timer.Stop()
 	// TODO: Support multiple result types
-	renderResultsJSON(w, result, params, h.keepNans)
+	renderResultsJSON(w, result, params, h.keepNans, stats)
 }
 
 // ServeHTTPWithEngine returns query results from the storage
@@ -153,13 +168,23 @@ func (h *PromReadHandler) ServeHTTPWithEngine(
 	w http.ResponseWriter,
 	r *http.Request, engine *executor.Engine,
 ) ([]*ts.Series, models.RequestParams, *RespError) {
+	result, params, _, respErr := h.serveHTTP(w, r, engine, nil)
+	return result, params, respErr
+}
+
+func (h *PromReadHandler) serveHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+	engine *executor.Engine,
+	resultsCache *cache.ResultsCache,
+) ([]*ts.Series, models.RequestParams, *models.QueryStats, *RespError) {
 	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
 	logger := logging.WithContext(ctx)
 
 	params, rErr := parseParams(r, h.timeoutOps)
 	if rErr != nil {
 		h.promReadMetrics.fetchErrorsClient.Inc(1)
-		return nil, emptyReqParams, &RespError{Err: rErr.Inner(), Code: rErr.Code()}
+		return nil, emptyReqParams, nil, &RespError{Err: rErr.Inner(), Code: rErr.Code()}
 	}
 
 	if params.Debug {
@@ -168,23 +193,91 @@ func (h *PromReadHandler) ServeHTTPWithEngine(
 
 	if err := h.validateRequest(&params); err != nil {
 		h.promReadMetrics.fetchErrorsClient.Inc(1)
-		return nil, emptyReqParams, &RespError{Err: err, Code: http.StatusBadRequest}
+		return nil, emptyReqParams, nil, &RespError{Err: err, Code: http.StatusBadRequest}
+	}
+
+	// Collect execution statistics if requested or needed for the slow query log.
+	var (
+		includeStats = parseStatsFlag(r)
+		stats        *models.QueryStats
+	)
+
+	if includeStats || h.slowQueryLogger != nil {
+		stats = models.NewQueryStats()
+		ctx = models.NewQueryStatsContext(ctx, stats)
+	}
+
+	start := time.Now()
+	result, params, err := h.read(ctx, engine, resultsCache, w, r, params)
+	h.slowQueryLogger.Log(ctx, r, params, stats, time.Since(start), err)
+	if !includeStats {
+		stats = nil
 	}
 
-	result, err := read(ctx, engine, h.tagOpts, w, params)
 	if err != nil {
 		sp := opentracingutil.SpanFromContextOrNoop(ctx)
 		sp.LogFields(opentracinglog.Error(err))
 		opentracingext.Error.Set(sp, true)
+		if xhttp.IsInvalidParams(err) {
+			// Such as queries exceeding the limits of their tenant.
+			h.promReadMetrics.fetchErrorsClient.Inc(1)
+			return nil, emptyReqParams, nil, &RespError{Err: err, Code: http.StatusBadRequest}
+		}
+
 		logger.Error("unable to fetch data", zap.Error(err))
 		h.promReadMetrics.fetchErrorsServer.Inc(1)
-		return nil, emptyReqParams, &RespError{Err: err, Code: http.StatusInternalServerError}
+		return nil, emptyReqParams, nil, &RespError{Err: err, Code: http.StatusInternalServerError}
 	}
 
 	// TODO: Support multiple result types
 	w.Header().Set("Content-Type", "application/json")
 
-	return result, params, nil
+	return result, params, stats, nil
+}
+
+// read reads the results of the query, through the results cache if one is
+// provided, which aligns the returned results and params to the query step.
+func (h *PromReadHandler) read(
+	reqCtx context.Context,
+	engine *executor.Engine,
+	resultsCache *cache.ResultsCache,
+	w http.ResponseWriter,
+	r *http.Request,
+	params models.RequestParams,
+) ([]*ts.Series, models.RequestParams, error) {
+	ctx, cancel := context.WithTimeout(reqCtx, params.Timeout)
+	defer cancel()
+
+	// Detect clients closing connections
+	handler.CloseWatcher(ctx, cancel, w)
+
+	// Admit the request over its whole range before it is split or read
+	// through the cache, so that the limits of its tenant apply to the request
+	// rather than to each of the queries it executes.
+	ctx, done, err := engine.Admit(ctx, params)
+	if err != nil {
+		return nil, params, err
+	}
+	defer done()
+
+	read := func(ctx context.Context, params models.RequestParams) ([]*ts.Series, error) {
+		return readWithParser(ctx, engine, h.parseFn, h.tagOpts, params)
+	}
+
+	if h.splitter != nil {
+		readUnsplit := read
+		read = func(ctx context.Context, params models.RequestParams) ([]*ts.Series, error) {
+			return h.splitter.Read(ctx, params, readUnsplit)
+		}
+	}
+
+	if resultsCache == nil {
+		result, err := read(ctx, params)
+		return result, params, err
+	}
+
+	key := cache.Key(r.Header.Get(handler.TenantHeader), params)
+	return resultsCache.Read(ctx, key, params, read)
 }
 
 func (h *PromReadHandler) validateRequest(params *models.RequestParams) error {
@@ -31,6 +31,7 @@ import (
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/parser/promql"
 	"github.com/m3db/m3/src/query/ts"
 	opentracingutil "github.com/m3db/m3/src/query/util/opentracing"
@@ -38,9 +39,13 @@ import (
 	opentracinglog "github.com/opentracing/opentracing-go/log"
 )
 
+// QueryParser parses a query into a DAG which can be executed by the engine.
+type QueryParser func(query string, tagOpts models.TagOptions) (parser.Parser, error)
+
 func read(
 	reqCtx context.Context,
 	engine *executor.Engine,
+	parseFn QueryParser,
 	tagOpts models.TagOptions,
 	w http.ResponseWriter,
 	params models.RequestParams,
@@ -48,6 +53,44 @@ func read(
 	ctx, cancel := context.WithTimeout(reqCtx, params.Timeout)
 	defer cancel()
 
+	// Detect clients closing connections
+	handler.CloseWatcher(ctx, cancel, w)
+
+	return readWithParser(ctx, engine, parseFn, tagOpts, params)
+}
+
+// Read executes the PromQL query described by params using the engine and
+// returns the resulting series, it does not apply the params timeout which is
+// left to the caller.
+func Read(
+	ctx context.Context,
+	engine *executor.Engine,
+	tagOpts models.TagOptions,
+	params models.RequestParams,
+) ([]*ts.Series, error) {
+	return readWithParser(ctx, engine, promql.Parse, tagOpts, params)
+}
+
+// ReadWithParser executes the query described by params, which is parsed
+// into a DAG by parseFn, using the engine and returns the resulting series.
+// As with Read, it does not apply the params timeout.
+func ReadWithParser(
+	ctx context.Context,
+	engine *executor.Engine,
+	parseFn QueryParser,
+	tagOpts models.TagOptions,
+	params models.RequestParams,
+) ([]*ts.Series, error) {
+	return readWithParser(ctx, engine, parseFn, tagOpts, params)
+}
+
+func readWithParser(
+	ctx context.Context,
+	engine *executor.Engine,
+	parseFn QueryParser,
+	tagOpts models.TagOptions,
+	params models.RequestParams,
+) ([]*ts.Series, error) {
 	sp := opentracingutil.SpanFromContextOrNoop(ctx)
 	sp.LogFields(
 		opentracinglog.String("params.query", params.Query),
@@ -58,11 +101,9 @@ func read(
 	)
 
 	opts := &executor.EngineOptions{}
-	// Detect clients closing connections
-	handler.CloseWatcher(ctx, cancel, w)
 
 	// TODO: Capture timing
-	parser, err := promql.Parse(params.Query, tagOpts)
+	parser, err := parseFn(params.Query, tagOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
 	"github.com/m3db/m3/src/query/util/httperrors"
 	"github.com/m3db/m3/src/query/util/logging"
 
@@ -47,6 +48,7 @@ const (
 // PromReadInstantHandler represents a handler for prometheus instantaneous read endpoint.
 type PromReadInstantHandler struct {
 	engine      *executor.Engine
+	parseFn     QueryParser
 	tagOpts     models.TagOptions
 	timeoutOpts *prometheus.TimeoutOpts
 }
@@ -59,6 +61,7 @@ func NewPromReadInstantHandler(
 ) *PromReadInstantHandler {
 	return &PromReadInstantHandler{
 		engine:      engine,
+		parseFn:     promql.Parse,
 		tagOpts:     tagOpts,
 		timeoutOpts: timeoutOpts,
 	}
@@ -77,7 +80,7 @@ func (h *PromReadInstantHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 		logger.Info("Request params", zap.Any("params", params))
 	}
 
-	result, err := read(ctx, h.engine, h.tagOpts, w, params)
+	result, err := read(ctx, h.engine, h.parseFn, h.tagOpts, w, params)
 	if err != nil {
 		logger.Error("unable to fetch data", zap.Error(err))
 		httperrors.ErrorWithReqInfo(w, r, http.StatusBadRequest, rErr)
@@ -60,7 +60,7 @@ func TestPromReadHandler_Read(t *testing.T) {
 	r, parseErr := parseParams(req, timeoutOpts)
 	require.Nil(t, parseErr)
 	assert.Equal(t, models.FormatPromQL, r.FormatType)
-	seriesList, err := read(context.TODO(), promRead.engine, promRead.tagOpts, httptest.NewRecorder(), r)
+	seriesList, err := read(context.TODO(), promRead.engine, promRead.parseFn, promRead.tagOpts, httptest.NewRecorder(), r)
 	require.NoError(t, err)
 	require.Len(t, seriesList, 2)
 	s := seriesList[0]
@@ -71,6 +71,42 @@ func TestPromReadHandler_Read(t *testing.T) {
 	}
 }
 
+func TestPromReadHandler_ServeHTTPStats(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	values, bounds := test.GenerateValuesAndBounds(nil, nil)
+
+	setup := newTestSetup()
+	b := test.NewBlockFromValues(bounds, values)
+	setup.Storage.SetFetchBlocksResult(block.Result{Blocks: []block.Block{b}}, nil)
+
+	params := defaultParams()
+	params.Set(statsParam, statsAll)
+	recorder := httptest.NewRecorder()
+	setup.Handler.ServeHTTP(recorder, newReadRequest(t, params))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp struct {
+		Data struct {
+			Stats *models.QueryStatsSummary `json:"stats"`
+		} `json:"data"`
+	}
+
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Data.Stats)
+	require.NotEmpty(t, resp.Data.Stats.Nodes)
+	assert.Equal(t, "fetch", resp.Data.Stats.Nodes[0].Op)
+	assert.Equal(t, 1, resp.Data.Stats.Nodes[0].Blocks)
+
+	// Stats are only included when requested.
+	recorder = httptest.NewRecorder()
+	setup.Handler.ServeHTTP(recorder, newReadRequest(t, defaultParams()))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	resp.Data.Stats = nil
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.Nil(t, resp.Data.Stats)
+}
+
 type M3QLResp []struct {
 	Target     string            `json:"target"`
 	Tags       map[string]string `json:"tags"`
@@ -126,12 +162,15 @@ func newTestSetup() *testSetup {
 	return &testSetup{
 		Storage: mockStorage,
 		Handler: NewPromReadHandler(
-			executor.NewEngine(mockStorage, tally.NewTestScope("test", nil), time.Minute, nil),
+			executor.NewEngine(mockStorage, tally.NewTestScope("test", nil), time.Minute, nil, nil),
 			models.NewTagOptions(),
 			&config.LimitsConfiguration{},
 			tally.NewTestScope("", nil),
 			timeoutOpts,
 			false,
+			nil,
+			nil,
+			nil,
 		),
 	}
 }
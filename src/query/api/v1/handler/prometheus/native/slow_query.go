@@ -0,0 +1,105 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"go.uber.org/zap"
+)
+
+// SlowQueryLogger logs queries which exceed a latency or fetched series
+// threshold, along with their execution statistics.
+type SlowQueryLogger struct {
+	latencyThreshold time.Duration
+	seriesThreshold  int64
+	headers          []string
+}
+
+// NewSlowQueryLogger returns a new slow query logger. A zero threshold is
+// disabled, and the given request headers are included in the log, which
+// defaults to the tenant header.
+func NewSlowQueryLogger(
+	latencyThreshold time.Duration,
+	seriesThreshold int64,
+	headers []string,
+) *SlowQueryLogger {
+	if len(headers) == 0 {
+		headers = []string{handler.TenantHeader}
+	}
+
+	return &SlowQueryLogger{
+		latencyThreshold: latencyThreshold,
+		seriesThreshold:  seriesThreshold,
+		headers:          headers,
+	}
+}
+
+func (l *SlowQueryLogger) isSlow(elapsed time.Duration, stats *models.QueryStats) bool {
+	if l.latencyThreshold > 0 && elapsed >= l.latencyThreshold {
+		return true
+	}
+
+	return l.seriesThreshold > 0 && stats.SeriesFetched() >= l.seriesThreshold
+}
+
+// Log logs the query if it exceeds a threshold.
+func (l *SlowQueryLogger) Log(
+	ctx context.Context,
+	r *http.Request,
+	params models.RequestParams,
+	stats *models.QueryStats,
+	elapsed time.Duration,
+	err error,
+) {
+	if l == nil || !l.isSlow(elapsed, stats) {
+		return
+	}
+
+	headers := make(map[string]string, len(l.headers))
+	for _, name := range l.headers {
+		if value := r.Header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+
+	fields := []zap.Field{
+		zap.String("query", params.Query),
+		zap.Time("start", params.Start),
+		zap.Time("end", params.End),
+		zap.Duration("step", params.Step),
+		zap.Duration("elapsed", elapsed),
+		zap.Any("headers", headers),
+		zap.Any("stats", stats.Summary()),
+	}
+
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+
+	logging.WithContext(ctx).Info("slow query", fields...)
+}
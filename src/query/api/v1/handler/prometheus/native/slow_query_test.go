@@ -0,0 +1,45 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowQueryLoggerIsSlow(t *testing.T) {
+	stats := models.NewQueryStats()
+	stats.AddSeriesFetched(10)
+
+	logger := NewSlowQueryLogger(time.Second, 0, nil)
+	assert.Equal(t, []string{handler.TenantHeader}, logger.headers)
+	assert.True(t, logger.isSlow(time.Second, stats))
+	assert.False(t, logger.isSlow(time.Millisecond, stats))
+
+	logger = NewSlowQueryLogger(0, 10, []string{"X-User"})
+	assert.True(t, logger.isSlow(time.Millisecond, stats))
+	assert.False(t, logger.isSlow(time.Hour, models.NewQueryStats()))
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+)
+
+var (
+	errInvalidSplitInterval    = errors.New("split interval must be positive")
+	errInvalidSplitConcurrency = errors.New("split max concurrency must be positive")
+)
+
+// readFn reads the results of the query described by params.
+type readFn func(ctx context.Context, params models.RequestParams) ([]*ts.Series, error)
+
+// QuerySplitter splits range queries spanning multiple intervals into
+// sub-queries aligned to the interval, executes them concurrently and
+// stitches their results together. Each sub-query is planned independently,
+// so it fetches the data required by the lookback and by temporal functions
+// from before its own start.
+type QuerySplitter struct {
+	interval       time.Duration
+	maxConcurrency int
+}
+
+// NewQuerySplitter returns a new query splitter which splits queries at
+// multiples of the interval and executes at most maxConcurrency sub-queries
+// of a query at once.
+func NewQuerySplitter(interval time.Duration, maxConcurrency int) (*QuerySplitter, error) {
+	if interval <= 0 {
+		return nil, errInvalidSplitInterval
+	}
+
+	if maxConcurrency <= 0 {
+		return nil, errInvalidSplitConcurrency
+	}
+
+	return &QuerySplitter{
+		interval:       interval,
+		maxConcurrency: maxConcurrency,
+	}, nil
+}
+
+// Read executes the query described by params with the read function, as
+// concurrent sub-queries if its range spans multiple intervals.
+func (s *QuerySplitter) Read(
+	ctx context.Context,
+	params models.RequestParams,
+	read readFn,
+) ([]*ts.Series, error) {
+	subQueries := s.split(params)
+	if len(subQueries) <= 1 {
+		return read(ctx, params)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results = make([][]*ts.Series, len(subQueries))
+		errs    = make([]error, len(subQueries))
+		tokens  = make(chan struct{}, s.maxConcurrency)
+		wg      sync.WaitGroup
+	)
+
+	for i, subQuery := range subQueries {
+		i, subQuery := i, subQuery
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+
+			results[i], errs[i] = read(ctx, subQuery)
+			if errs[i] != nil {
+				// No need to finish the remaining sub-queries.
+				cancel()
+			}
+		}()
+	}
+
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil && err != context.Canceled {
+			return nil, err
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stitchSeries(params, subQueries, results), nil
+}
+
+// split returns the sub-queries for the steps of the query, each ending at
+// the last step before an interval boundary.
+func (s *QuerySplitter) split(params models.RequestParams) []models.RequestParams {
+	step := params.Step
+	if step <= 0 {
+		return []models.RequestParams{params}
+	}
+
+	numSteps := int(params.ExclusiveEnd().Sub(params.Start) / step)
+	if numSteps <= 0 {
+		return []models.RequestParams{params}
+	}
+
+	var subQueries []models.RequestParams
+	for first := 0; first < numSteps; {
+		start := params.Start.Add(time.Duration(first) * step)
+		boundary := start.Truncate(s.interval).Add(s.interval)
+		last := first + int((boundary.Sub(start)-1)/step)
+		if last >= numSteps {
+			last = numSteps - 1
+		}
+
+		subQuery := params
+		subQuery.Start = start
+		subQuery.End = params.Start.Add(time.Duration(last) * step)
+		subQuery.IncludeEnd = true
+		subQueries = append(subQueries, subQuery)
+		first = last + 1
+	}
+
+	return subQueries
+}
+
+// stitchSeries combines the results of the sub-queries into series over the
+// range of the query, filling the steps missing from a series with NaNs.
+func stitchSeries(
+	params models.RequestParams,
+	subQueries []models.RequestParams,
+	results [][]*ts.Series,
+) []*ts.Series {
+	var (
+		step     = params.Step
+		numSteps = int(params.ExclusiveEnd().Sub(params.Start) / step)
+		byID     = make(map[string]int)
+		series   []*ts.Series
+		values   []ts.FixedResolutionMutableValues
+	)
+
+	for i, result := range results {
+		offset := int(subQueries[i].Start.Sub(params.Start) / step)
+		for _, s := range result {
+			id := string(s.Tags.ID())
+			idx, ok := byID[id]
+			if !ok {
+				idx = len(series)
+				byID[id] = idx
+				vals := ts.NewFixedStepValues(step, numSteps, math.NaN(), params.Start)
+				values = append(values, vals)
+				series = append(series, ts.NewSeries(s.Name(), vals, s.Tags))
+			}
+
+			vals := s.Values()
+			for j := 0; j < vals.Len() && offset+j < numSteps; j++ {
+				values[idx].SetValueAt(offset+j, vals.ValueAt(j))
+			}
+		}
+	}
+
+	if len(series) == 0 {
+		return emptySeriesList
+	}
+
+	return series
+}
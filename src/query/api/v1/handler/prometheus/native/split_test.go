@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package native
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQuerySplitterValidates(t *testing.T) {
+	_, err := NewQuerySplitter(0, 1)
+	assert.Error(t, err)
+
+	_, err = NewQuerySplitter(time.Hour, 0)
+	assert.Error(t, err)
+}
+
+func TestQuerySplitterSplit(t *testing.T) {
+	splitter, err := NewQuerySplitter(24*time.Hour, 2)
+	require.NoError(t, err)
+
+	start := time.Date(2018, 1, 1, 22, 30, 0, 0, time.UTC)
+	params := models.RequestParams{
+		Start:      start,
+		End:        start.Add(48 * time.Hour),
+		Step:       time.Hour,
+		IncludeEnd: false,
+	}
+
+	subQueries := splitter.split(params)
+	require.Len(t, subQueries, 3)
+
+	expected := []struct {
+		start, end time.Time
+	}{
+		{start, start.Add(time.Hour)},
+		{start.Add(2 * time.Hour), start.Add(25 * time.Hour)},
+		{start.Add(26 * time.Hour), start.Add(47 * time.Hour)},
+	}
+
+	for i, e := range expected {
+		assert.Equal(t, e.start, subQueries[i].Start)
+		assert.Equal(t, e.end, subQueries[i].End)
+		assert.True(t, subQueries[i].IncludeEnd)
+	}
+
+	// Queries within an interval are not split.
+	params.End = start.Add(time.Hour)
+	assert.Len(t, splitter.split(params), 1)
+}
+
+func TestQuerySplitterRead(t *testing.T) {
+	splitter, err := NewQuerySplitter(24*time.Hour, 2)
+	require.NoError(t, err)
+
+	var (
+		start = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+		step  = 6 * time.Hour
+		opts  = models.NewTagOptions()
+		lock  sync.Mutex
+
+		running, maxRunning int
+	)
+
+	// Series "a" is present throughout while series "b" only appears on the
+	// second day.
+	read := func(_ context.Context, params models.RequestParams) ([]*ts.Series, error) {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		defer func() {
+			lock.Lock()
+			running--
+			lock.Unlock()
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		n := int(params.ExclusiveEnd().Sub(params.Start) / params.Step)
+		var series []*ts.Series
+		for _, name := range []string{"a", "b"} {
+			if name == "b" && params.Start.Day() != 2 {
+				continue
+			}
+
+			values := ts.NewFixedStepValues(params.Step, n, math.NaN(), params.Start)
+			for i := 0; i < n; i++ {
+				values.SetValueAt(i, float64(params.Start.Add(time.Duration(i)*params.Step).Unix()))
+			}
+
+			tags := models.NewTags(1, opts).SetName([]byte(name))
+			series = append(series, ts.NewSeries([]byte(name), values, tags))
+		}
+
+		return series, nil
+	}
+
+	params := models.RequestParams{
+		Start:      start,
+		End:        start.Add(4 * 24 * time.Hour),
+		Step:       step,
+		IncludeEnd: true,
+	}
+
+	series, err := splitter.Read(context.Background(), params, read)
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	assert.Equal(t, 2, maxRunning)
+
+	for _, s := range series {
+		vals := s.Values()
+		require.Equal(t, 17, vals.Len())
+		for i := 0; i < vals.Len(); i++ {
+			dp := vals.DatapointAt(i)
+			assert.Equal(t, start.Add(time.Duration(i)*step), dp.Timestamp)
+			if string(s.Name()) == "b" && (i < 4 || i >= 8) {
+				assert.True(t, math.IsNaN(dp.Value))
+				continue
+			}
+
+			assert.Equal(t, float64(dp.Timestamp.Unix()), dp.Value)
+		}
+	}
+}
+
+func TestQuerySplitterReadError(t *testing.T) {
+	splitter, err := NewQuerySplitter(time.Hour, 1)
+	require.NoError(t, err)
+
+	readErr := errors.New("read error")
+	read := func(ctx context.Context, params models.RequestParams) ([]*ts.Series, error) {
+		if params.Start.Minute() == 0 && params.Start.Hour() == 1 {
+			return nil, readErr
+		}
+
+		return nil, ctx.Err()
+	}
+
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err = splitter.Read(context.Background(), models.RequestParams{
+		Start:      start,
+		End:        start.Add(3 * time.Hour),
+		Step:       time.Minute,
+		IncludeEnd: true,
+	}, read)
+	assert.Equal(t, readErr, err)
+}
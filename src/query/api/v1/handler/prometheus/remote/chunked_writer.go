@@ -0,0 +1,93 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package remote
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"net/http"
+
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	// StreamedReadContentType is the content type of streamed chunked read
+	// responses.
+	StreamedReadContentType = "application/x-streamed-protobuf; proto=prometheus.ChunkedReadResponse"
+
+	// frameChecksumSize is the size of the checksum written before each frame.
+	frameChecksumSize = 4
+)
+
+var (
+	castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+	errStreamingUnsupported = errors.New("response writer does not support streaming")
+)
+
+// chunkedWriter writes chunked read responses as frames made of the varint
+// encoded size of the response, its big endian CRC32 Castagnoli checksum and
+// the response itself, flushing each frame to the client as it is written.
+type chunkedWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	frames  int
+}
+
+func newChunkedWriter(w http.ResponseWriter) (*chunkedWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errStreamingUnsupported
+	}
+
+	return &chunkedWriter{
+		w:       w,
+		flusher: flusher,
+	}, nil
+}
+
+func (w *chunkedWriter) write(resp *prompb.ChunkedReadResponse) error {
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	if w.frames == 0 {
+		w.w.Header().Set("Content-Type", StreamedReadContentType)
+	}
+
+	var header [binary.MaxVarintLen64 + frameChecksumSize]byte
+	n := binary.PutUvarint(header[:], uint64(len(data)))
+	binary.BigEndian.PutUint32(header[n:], crc32.Checksum(data, castagnoliTable))
+	if _, err := w.w.Write(header[:n+frameChecksumSize]); err != nil {
+		return err
+	}
+
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+
+	w.flusher.Flush()
+	w.frames++
+	return nil
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ExportURL is the url for the export handler.
+	ExportURL = handler.RoutePrefixV1 + "/export"
+
+	// ExportHTTPMethod is the HTTP method used with this resource.
+	ExportHTTPMethod = http.MethodGet
+
+	exportContentType = "application/x-ndjson"
+	exportStartParam  = "start"
+	exportLimitParam  = "limit"
+	exportCursorParam = "cursor"
+
+	// exportBatchSize is the number of series fetched at a time.
+	exportBatchSize = 256
+)
+
+var errExportStartRequired = errors.New("must specify a start time to export from")
+
+// ExportHandler streams the raw datapoints of the series matching the
+// selectors of a request over a time range as newline delimited JSON.
+//
+// The matching series are fetched in batches in order of their IDs, each
+// batch resuming in the index after the last series of the previous one, so
+// that the memory used is bounded by the datapoints of a batch of series. At
+// most limit series are written if set, along with a cursor to continue the
+// export from in a later request.
+type ExportHandler struct {
+	storage     storage.Storage
+	tagOptions  models.TagOptions
+	timeoutOpts *prometheus.TimeoutOpts
+}
+
+// NewExportHandler returns a new instance of handler.
+func NewExportHandler(
+	storage storage.Storage,
+	tagOptions models.TagOptions,
+	timeoutOpts *prometheus.TimeoutOpts,
+) http.Handler {
+	return &ExportHandler{
+		storage:     storage,
+		tagOptions:  tagOptions,
+		timeoutOpts: timeoutOpts,
+	}
+}
+
+// ExportedSeries is a line of the export response.
+type ExportedSeries struct {
+	Metric     map[string]string `json:"metric"`
+	Values     []float64         `json:"values"`
+	Timestamps []int64           `json:"timestamps"`
+}
+
+func (h *ExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+
+	queries, rErr := prometheus.ParseSeriesMatchQuery(r, h.tagOptions)
+	if rErr != nil {
+		logger.Error("unable to parse export selectors", zap.Error(rErr))
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	if r.Form.Get(exportStartParam) == "" {
+		xhttp.Error(w, errExportStartRequired, http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if v := r.Form.Get(exportLimitParam); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			xhttp.Error(w, fmt.Errorf("%s: invalid '%s': %s", xhttp.ErrInvalidParams,
+				exportLimitParam, v), http.StatusBadRequest)
+			return
+		}
+
+		limit = parsed
+	}
+
+	var cursor []byte
+	if v := r.Form.Get(exportCursorParam); v != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil {
+			xhttp.Error(w, fmt.Errorf("%s: invalid '%s': %s", xhttp.ErrInvalidParams,
+				exportCursorParam, v), http.StatusBadRequest)
+			return
+		}
+
+		cursor = decoded
+	}
+
+	timeout, err := prometheus.ParseRequestTimeout(r, h.timeoutOpts.FetchTimeout)
+	if err != nil {
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	// The cursor is only known once the last series is exported, so it is
+	// sent as a trailer.
+	w.Header().Set("Trailer", handler.ExportCursorHeader)
+	w.Header().Set("Content-Type", exportContentType)
+	var (
+		start     = queries[0].Start
+		end       = queries[0].End
+		enc       = json.NewEncoder(w)
+		written   = false
+		remaining = limit
+	)
+
+	flusher, _ := w.(http.Flusher)
+	for {
+		size := exportBatchSize
+		if limit > 0 && remaining < size {
+			// Fetch one more series than remaining to know if the export
+			// ends with this batch.
+			size = remaining + 1
+		}
+
+		batch, more, err := h.fetchBatch(ctx, queries, cursor, size, timeout)
+		if err != nil {
+			logger.Error("unable to fetch exported series", zap.Error(err))
+			if !written {
+				xhttp.Error(w, err, http.StatusInternalServerError)
+			}
+
+			// The response has already started, so end it early rather than
+			// writing an error status.
+			return
+		}
+
+		if limit > 0 && len(batch) > remaining {
+			batch, more = batch[:remaining], true
+		}
+
+		for _, series := range batch {
+			exported := exportSeries(series, start, end)
+			if len(exported.Values) == 0 {
+				continue
+			}
+
+			if err := enc.Encode(exported); err != nil {
+				logger.Error("unable to write exported series", zap.Error(err))
+				return
+			}
+
+			written = true
+		}
+
+		if flusher != nil && written {
+			flusher.Flush()
+		}
+
+		if len(batch) > 0 {
+			cursor = batch[len(batch)-1].Name()
+		}
+
+		remaining -= len(batch)
+		if !more {
+			return
+		}
+
+		if limit > 0 && remaining == 0 {
+			w.Header().Set(handler.ExportCursorHeader,
+				base64.RawURLEncoding.EncodeToString(cursor))
+			return
+		}
+	}
+}
+
+// fetchBatch fetches the first size series in ID order ordered after the
+// cursor which match any of the queries, along with whether more series may
+// match after them.
+func (h *ExportHandler) fetchBatch(
+	reqCtx context.Context,
+	queries []*storage.FetchQuery,
+	cursor []byte,
+	size int,
+	timeout time.Duration,
+) ([]*ts.Series, bool, error) {
+	ctx, cancel := context.WithTimeout(reqCtx, timeout)
+	defer cancel()
+
+	var (
+		batch []*ts.Series
+		more  bool
+		seen  = make(map[string]struct{})
+	)
+
+	for _, query := range queries {
+		opts := storage.NewFetchOptions()
+		opts.Limit = size
+		opts.OrderedByID = true
+		opts.AfterID = cursor
+		result, err := h.storage.Fetch(ctx, query, opts)
+		if err != nil {
+			return nil, false, err
+		}
+
+		matched := 0
+		for _, series := range result.SeriesList {
+			// Stores which do not page their series by ID return all the
+			// series which match.
+			id := series.Name()
+			if cursor != nil && bytes.Compare(id, cursor) <= 0 {
+				continue
+			}
+
+			matched++
+			if _, ok := seen[string(id)]; ok {
+				continue
+			}
+
+			seen[string(id)] = struct{}{}
+			batch = append(batch, series)
+		}
+
+		// Only the first size series of each query are fetched, so more may
+		// match after them.
+		if matched >= size {
+			more = true
+		}
+	}
+
+	sort.Slice(batch, func(i, j int) bool {
+		return bytes.Compare(batch[i].Name(), batch[j].Name()) < 0
+	})
+
+	if len(batch) > size {
+		batch, more = batch[:size], true
+	}
+
+	return batch, more, nil
+}
+
+// exportSeries returns the datapoints of a series between start and end,
+// both inclusive.
+func exportSeries(series *ts.Series, start, end time.Time) ExportedSeries {
+	exported := ExportedSeries{Metric: make(map[string]string, series.Tags.Len())}
+	for _, tag := range series.Tags.Tags {
+		exported.Metric[string(tag.Name)] = string(tag.Value)
+	}
+
+	values := series.Values()
+	for i := 0; i < values.Len(); i++ {
+		dp := values.DatapointAt(i)
+		if dp.Timestamp.Before(start) || dp.Timestamp.After(end) {
+			continue
+		}
+
+		// Skip values which cannot be represented in JSON, such as
+		// staleness markers.
+		if math.IsNaN(dp.Value) || math.IsInf(dp.Value, 0) {
+			continue
+		}
+
+		exported.Values = append(exported.Values, dp.Value)
+		exported.Timestamps = append(exported.Timestamps,
+			dp.Timestamp.UnixNano()/int64(time.Millisecond))
+	}
+
+	return exported
+}
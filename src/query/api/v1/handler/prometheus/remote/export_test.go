@@ -0,0 +1,145 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportTestSeries returns an "up" series named by the ID of its tags as
+// the series fetched from M3DB are.
+func newExportTestSeries(
+	tagOpts models.TagOptions,
+	tags map[string]string,
+	dps ts.Datapoints,
+) *ts.Series {
+	series := newTestSeries(tagOpts, "up", tags, dps)
+	return ts.NewSeries(series.Tags.ID(), dps, series.Tags)
+}
+
+func TestExportHandlerPaginates(t *testing.T) {
+	var (
+		tagOpts = models.NewTagOptions()
+		start   = time.Unix(0, 0)
+		store   = mock.NewMockStorage()
+		dps     ts.Datapoints
+	)
+
+	// A datapoint every 30 minutes over 2 hours, both ends inclusive.
+	for i := 0; i <= 4; i++ {
+		dps = append(dps, ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * 30 * time.Minute),
+			Value:     float64(i),
+		})
+	}
+	dps = append(dps, ts.Datapoint{Timestamp: start.Add(10 * time.Minute), Value: math.NaN()})
+
+	seriesList := ts.SeriesList{
+		newExportTestSeries(tagOpts, map[string]string{"job": "c"}, dps),
+		newExportTestSeries(tagOpts, map[string]string{"job": "a"}, dps),
+		newExportTestSeries(tagOpts, map[string]string{"job": "b"}, dps[:1]),
+	}
+
+	// The storage returns every series for each fetch rather than paging
+	// them, only the series of the page being exported must be written.
+	store.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
+
+	export := func(cursor string) ([]ExportedSeries, string) {
+		params := url.Values{}
+		params.Add("match[]", "up")
+		params.Add("start", "0")
+		params.Add("end", strconv.Itoa(int((2 * time.Hour).Seconds())))
+		params.Add("limit", "2")
+		if cursor != "" {
+			params.Add("cursor", cursor)
+		}
+
+		req := httptest.NewRequest(ExportHTTPMethod, ExportURL+"?"+params.Encode(), nil)
+		w := httptest.NewRecorder()
+		NewExportHandler(store, tagOpts, timeoutOpts).ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, exportContentType, w.Header().Get("Content-Type"))
+
+		var lines []ExportedSeries
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var s ExportedSeries
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &s))
+			lines = append(lines, s)
+		}
+
+		return lines, w.Result().Trailer.Get(handler.ExportCursorHeader)
+	}
+
+	// Series are exported in order of their IDs, at most limit at a time.
+	lines, cursor := export("")
+	require.Len(t, lines, 2)
+	require.NotEmpty(t, cursor)
+	assert.Equal(t, map[string]string{"__name__": "up", "job": "a"}, lines[0].Metric)
+	assert.Equal(t, []float64{0, 1, 2, 3, 4}, lines[0].Values)
+	assert.Equal(t, []int64{0, 1800000, 3600000, 5400000, 7200000}, lines[0].Timestamps)
+	assert.Equal(t, map[string]string{"__name__": "up", "job": "b"}, lines[1].Metric)
+	assert.Equal(t, []float64{0}, lines[1].Values)
+
+	// Series are fetched in order of their IDs resuming after the cursor.
+	lines, next := export(cursor)
+	require.Len(t, lines, 1)
+	assert.Empty(t, next)
+	assert.Equal(t, map[string]string{"__name__": "up", "job": "c"}, lines[0].Metric)
+
+	fetchOpts := store.LastFetchOptions()
+	require.NotNil(t, fetchOpts)
+	assert.True(t, fetchOpts.OrderedByID)
+	assert.Equal(t, 3, fetchOpts.Limit)
+	assert.Equal(t, seriesList[2].Name(), fetchOpts.AfterID)
+}
+
+func TestExportHandlerValidatesParams(t *testing.T) {
+	h := NewExportHandler(mock.NewMockStorage(), models.NewTagOptions(), timeoutOpts)
+	for _, query := range []string{
+		"match[]=up",
+		"match[]=up&start=0&limit=0",
+		"match[]=up&start=0&limit=foo",
+		"match[]=up&start=0&cursor=!",
+		"start=0",
+	} {
+		req := httptest.NewRequest(ExportHTTPMethod, ExportURL+"?"+query, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// FederateURL is the url for the prometheus federation handler, which
+	// matches the path scraped from a Prometheus server.
+	FederateURL = "/federate"
+
+	// FederateHTTPMethod is the HTTP method used with this resource.
+	FederateHTTPMethod = http.MethodGet
+
+	federateContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// FederateHandler returns the latest value of each series matching the
+// selectors of a request in the Prometheus text exposition format.
+type FederateHandler struct {
+	storage          storage.Storage
+	tagOptions       models.TagOptions
+	lookbackDuration time.Duration
+	timeoutOpts      *prometheus.TimeoutOpts
+	nowFn            func() time.Time
+}
+
+// NewFederateHandler returns a new instance of handler. Series without a
+// value within the lookback duration are not returned.
+func NewFederateHandler(
+	storage storage.Storage,
+	tagOptions models.TagOptions,
+	lookbackDuration time.Duration,
+	timeoutOpts *prometheus.TimeoutOpts,
+) http.Handler {
+	return &FederateHandler{
+		storage:          storage,
+		tagOptions:       tagOptions,
+		lookbackDuration: lookbackDuration,
+		timeoutOpts:      timeoutOpts,
+		nowFn:            time.Now,
+	}
+}
+
+// federatedSample is the latest value of a series.
+type federatedSample struct {
+	name  []byte
+	tags  models.Tags
+	value ts.Datapoint
+}
+
+func (h *FederateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+
+	queries, rErr := prometheus.ParseSeriesMatchQuery(r, h.tagOptions)
+	if rErr != nil {
+		logger.Error("unable to parse federate selectors", zap.Error(rErr))
+		xhttp.Error(w, rErr.Inner(), rErr.Code())
+		return
+	}
+
+	timeout, err := prometheus.ParseRequestTimeout(r, h.timeoutOpts.FetchTimeout)
+	if err != nil {
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		end     = h.nowFn()
+		start   = end.Add(-h.lookbackDuration)
+		samples []federatedSample
+		seen    = make(map[string]struct{})
+	)
+
+	for _, query := range queries {
+		query.Start, query.End = start, end
+		result, err := h.storage.Fetch(ctx, query, storage.NewFetchOptions())
+		if err != nil {
+			logger.Error("unable to fetch federated series", zap.Error(err))
+			xhttp.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		for _, series := range result.SeriesList {
+			// Series matched by several selectors are only returned once.
+			id := string(series.Tags.ID())
+			if _, ok := seen[id]; ok {
+				continue
+			}
+
+			sample, ok := latestSample(series, end)
+			if !ok {
+				continue
+			}
+
+			seen[id] = struct{}{}
+			samples = append(samples, sample)
+		}
+	}
+
+	w.Header().Set("Content-Type", federateContentType)
+	if err := writeFederatedSamples(w, samples); err != nil {
+		logger.Error("unable to write federated series", zap.Error(err))
+	}
+}
+
+// latestSample returns the latest non-NaN value of a named series at or
+// before end.
+func latestSample(series *ts.Series, end time.Time) (federatedSample, bool) {
+	name, ok := series.Tags.Name()
+	if !ok || len(name) == 0 {
+		return federatedSample{}, false
+	}
+
+	values := series.Values()
+	for i := values.Len() - 1; i >= 0; i-- {
+		dp := values.DatapointAt(i)
+		if dp.Timestamp.After(end) || math.IsNaN(dp.Value) {
+			continue
+		}
+
+		return federatedSample{
+			name:  name,
+			tags:  series.Tags.WithoutName(),
+			value: dp,
+		}, true
+	}
+
+	return federatedSample{}, false
+}
+
+// writeFederatedSamples writes the samples in the text exposition format,
+// grouped by metric name.
+func writeFederatedSamples(w io.Writer, samples []federatedSample) error {
+	sort.Slice(samples, func(i, j int) bool {
+		if c := bytes.Compare(samples[i].name, samples[j].name); c != 0 {
+			return c < 0
+		}
+
+		return bytes.Compare(samples[i].tags.ID(), samples[j].tags.ID()) < 0
+	})
+
+	buf := bufio.NewWriter(w)
+	var lastName []byte
+	for _, s := range samples {
+		if !bytes.Equal(s.name, lastName) {
+			buf.WriteString("# TYPE ")
+			buf.Write(s.name)
+			buf.WriteString(" untyped\n")
+			lastName = s.name
+		}
+
+		buf.Write(s.name)
+		if len(s.tags.Tags) > 0 {
+			buf.WriteByte('{')
+			for i, tag := range s.tags.Tags {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+
+				buf.Write(tag.Name)
+				buf.WriteString(`="`)
+				buf.WriteString(escapeLabelValue(string(tag.Value)))
+				buf.WriteByte('"')
+			}
+			buf.WriteByte('}')
+		}
+
+		buf.WriteByte(' ')
+		buf.WriteString(formatSampleValue(s.value.Value))
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(s.value.Timestamp.UnixNano()/int64(time.Millisecond), 10))
+		buf.WriteByte('\n')
+	}
+
+	return buf.Flush()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func formatSampleValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package remote
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSeries(
+	tagOpts models.TagOptions,
+	name string,
+	tags map[string]string,
+	dps ts.Datapoints,
+) *ts.Series {
+	t := models.NewTags(len(tags)+1, tagOpts).SetName([]byte(name))
+	for k, v := range tags {
+		t = t.AddTag(models.Tag{Name: []byte(k), Value: []byte(v)})
+	}
+
+	return ts.NewSeries([]byte(name), dps, t)
+}
+
+func TestFederateHandler(t *testing.T) {
+	var (
+		tagOpts = models.NewTagOptions()
+		now     = time.Unix(1000, 0)
+		store   = mock.NewMockStorage()
+	)
+
+	store.SetFetchResult(&storage.FetchResult{
+		SeriesList: ts.SeriesList{
+			newTestSeries(tagOpts, "up", map[string]string{"job": "b"}, ts.Datapoints{
+				{Timestamp: now.Add(-time.Minute), Value: 1},
+				{Timestamp: now.Add(-30 * time.Second), Value: 0},
+			}),
+			newTestSeries(tagOpts, "up", map[string]string{"job": `a"x`}, ts.Datapoints{
+				{Timestamp: now.Add(-time.Minute), Value: 1},
+				{Timestamp: now.Add(-30 * time.Second), Value: math.NaN()},
+			}),
+			newTestSeries(tagOpts, "errors", nil, ts.Datapoints{
+				{Timestamp: now.Add(-time.Minute), Value: math.Inf(1)},
+			}),
+			// Series without a recent value are skipped.
+			newTestSeries(tagOpts, "stale", nil, ts.Datapoints{}),
+		},
+	}, nil)
+
+	h := NewFederateHandler(store, tagOpts, 5*time.Minute, timeoutOpts).(*FederateHandler)
+	h.nowFn = func() time.Time { return now }
+
+	params := url.Values{}
+	params.Add("match[]", "up")
+	params.Add("match[]", `{__name__=~".+"}`)
+	req := httptest.NewRequest(FederateHTTPMethod, FederateURL+"?"+params.Encode(), nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, federateContentType, w.Header().Get("Content-Type"))
+	expected := `# TYPE errors untyped
+errors +Inf 940000
+# TYPE up untyped
+up{job="a\"x"} 1 940000
+up{job="b"} 0 970000
+`
+	assert.Equal(t, expected, w.Body.String())
+}
+
+func TestFederateHandlerRequiresSelectors(t *testing.T) {
+	h := NewFederateHandler(mock.NewMockStorage(), models.NewTagOptions(),
+		time.Minute, timeoutOpts)
+	req := httptest.NewRequest(FederateHTTPMethod, FederateURL, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWriteFederatedSamplesEscapes(t *testing.T) {
+	tags := models.NewTags(1, models.NewTagOptions()).
+		AddTag(models.Tag{Name: []byte("path"), Value: []byte("a\\b\nc")})
+	var buf bytes.Buffer
+	require.NoError(t, writeFederatedSamples(&buf, []federatedSample{{
+		name:  []byte("foo"),
+		tags:  tags,
+		value: ts.Datapoint{Timestamp: time.Unix(1, 0), Value: 1.5},
+	}}))
+	assert.Equal(t, "# TYPE foo untyped\nfoo{path=\"a\\\\b\\nc\"} 1.5 1000\n", buf.String())
+}
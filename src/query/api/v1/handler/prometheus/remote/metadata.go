@@ -0,0 +1,99 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/m3db/m3/src/query/api/v1/handler"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/x/net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// PromMetadataURL is the url for the prometheus metric metadata handler.
+	PromMetadataURL = handler.RoutePrefixV1 + "/metadata"
+
+	// PromMetadataHTTPMethod is the HTTP method used with this resource.
+	PromMetadataHTTPMethod = http.MethodGet
+
+	metadataLimitParam  = "limit"
+	metadataMetricParam = "metric"
+)
+
+// PromMetadataHandler represents a handler for prometheus metric metadata
+// endpoint.
+type PromMetadataHandler struct {
+	metadataStore storage.MetricMetadataStore
+}
+
+// NewPromMetadataHandler returns a new instance of handler, which returns
+// no metadata if metadataStore is nil.
+func NewPromMetadataHandler(
+	metadataStore storage.MetricMetadataStore,
+) http.Handler {
+	return &PromMetadataHandler{
+		metadataStore: metadataStore,
+	}
+}
+
+func (h *PromMetadataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), handler.HeaderKey, r.Header)
+	logger := logging.WithContext(ctx)
+	w.Header().Set("Content-Type", "application/json")
+
+	limit := -1
+	if str := r.FormValue(metadataLimitParam); str != "" {
+		var err error
+		limit, err = strconv.Atoi(str)
+		if err != nil {
+			err = fmt.Errorf("%s: invalid '%s': %v", xhttp.ErrInvalidParams,
+				metadataLimitParam, err)
+			xhttp.Error(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var metadata []*prompb.MetricMetadata
+	if h.metadataStore != nil {
+		var err error
+		metadata, err = h.metadataStore.FetchMetadata(ctx,
+			r.FormValue(metadataMetricParam))
+		if err != nil {
+			logger.Error("unable to fetch metric metadata", zap.Error(err))
+			xhttp.Error(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err := prometheus.RenderMetricMetadataResultsJSON(w, metadata, limit)
+	if err != nil {
+		logger.Error("unable to render metric metadata", zap.Error(err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/util/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMetricMetadataStore struct {
+	written  []*prompb.MetricMetadata
+	writeErr error
+}
+
+func (s *testMetricMetadataStore) WriteMetadata(
+	_ context.Context,
+	metadata []*prompb.MetricMetadata,
+) error {
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+
+	s.written = append(s.written, metadata...)
+	return nil
+}
+
+func (s *testMetricMetadataStore) FetchMetadata(
+	_ context.Context,
+	metric string,
+) ([]*prompb.MetricMetadata, error) {
+	var result []*prompb.MetricMetadata
+	for _, m := range s.written {
+		if metric == "" || m.MetricFamilyName == metric {
+			result = append(result, m)
+		}
+	}
+
+	return result, nil
+}
+
+func newTestMetricMetadataStore() *testMetricMetadataStore {
+	return &testMetricMetadataStore{
+		written: []*prompb.MetricMetadata{
+			{
+				Type:             prompb.MetricMetadata_GAUGE,
+				MetricFamilyName: "up",
+				Help:             "Whether the target is up.",
+			},
+			{
+				Type:             prompb.MetricMetadata_COUNTER,
+				MetricFamilyName: "http_requests_total",
+				Help:             "Total number of HTTP requests.",
+			},
+			{
+				Type:             prompb.MetricMetadata_COUNTER,
+				MetricFamilyName: "http_requests_total",
+				Help:             "Total HTTP requests.",
+			},
+		},
+	}
+}
+
+func TestPromMetadata(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query: "",
+			expected: `{"status":"success","data":{` +
+				`"http_requests_total":[` +
+				`{"type":"counter","help":"Total number of HTTP requests.","unit":""},` +
+				`{"type":"counter","help":"Total HTTP requests.","unit":""}],` +
+				`"up":[{"type":"gauge","help":"Whether the target is up.","unit":""}]}}`,
+		},
+		{
+			query: "?limit=1",
+			expected: `{"status":"success","data":{` +
+				`"http_requests_total":[` +
+				`{"type":"counter","help":"Total number of HTTP requests.","unit":""},` +
+				`{"type":"counter","help":"Total HTTP requests.","unit":""}]}}`,
+		},
+		{
+			query: "?metric=up",
+			expected: `{"status":"success","data":{` +
+				`"up":[{"type":"gauge","help":"Whether the target is up.","unit":""}]}}`,
+		},
+	}
+
+	handler := NewPromMetadataHandler(newTestMetricMetadataStore())
+	for _, tt := range tests {
+		req := httptest.NewRequest(PromMetadataHTTPMethod, PromMetadataURL+tt.query, nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		require.Equal(t, http.StatusOK, recorder.Code, tt.query)
+		assert.Equal(t, tt.expected, recorder.Body.String(), tt.query)
+	}
+}
+
+func TestPromMetadataNoStore(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	req := httptest.NewRequest(PromMetadataHTTPMethod, PromMetadataURL, nil)
+	recorder := httptest.NewRecorder()
+	NewPromMetadataHandler(nil).ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `{"status":"success","data":{}}`, recorder.Body.String())
+}
+
+func TestPromMetadataInvalidLimit(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	req := httptest.NewRequest(PromMetadataHTTPMethod, PromMetadataURL+"?limit=a", nil)
+	recorder := httptest.NewRecorder()
+	NewPromMetadataHandler(newTestMetricMetadataStore()).ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -28,8 +28,10 @@ import (
 
 	"github.com/m3db/m3/src/query/api/v1/handler"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
+	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/util/logging"
 	xhttp "github.com/m3db/m3/src/x/net/http"
@@ -46,19 +48,34 @@ const (
 
 	// PromReadHTTPMethod is the HTTP method used with this resource.
 	PromReadHTTPMethod = http.MethodPost
+
+	// samplesPerChunk is the number of samples encoded in each XOR chunk of
+	// a streamed response, matching the chunk size used by prometheus.
+	samplesPerChunk = 120
+
+	// maxBytesInFrame is the size at which a streamed response frame is
+	// written to the client.
+	maxBytesInFrame = 1024 * 1024
 )
 
 // PromReadHandler represents a handler for prometheus read endpoint.
 type PromReadHandler struct {
 	engine          *executor.Engine
+	tagOptions      models.TagOptions
 	promReadMetrics promReadMetrics
 	timeoutOpts     *prometheus.TimeoutOpts
 }
 
 // NewPromReadHandler returns a new instance of handler.
-func NewPromReadHandler(engine *executor.Engine, scope tally.Scope, timeoutOpts *prometheus.TimeoutOpts) http.Handler {
+func NewPromReadHandler(
+	engine *executor.Engine,
+	tagOptions models.TagOptions,
+	scope tally.Scope,
+	timeoutOpts *prometheus.TimeoutOpts,
+) http.Handler {
 	return &PromReadHandler{
 		engine:          engine,
+		tagOptions:      tagOptions,
 		promReadMetrics: newPromReadMetrics(scope),
 		timeoutOpts:     timeoutOpts,
 	}
@@ -97,6 +114,18 @@ func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	responseType, err := negotiateResponseType(req.AcceptedResponseTypes)
+	if err != nil {
+		h.promReadMetrics.fetchErrorsClient.Inc(1)
+		xhttp.Error(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if responseType == prompb.ReadRequest_STREAMED_XOR_CHUNKS {
+		h.serveStreamed(ctx, w, req, timeout)
+		return
+	}
+
 	result, err := h.read(ctx, w, req, timeout)
 	if err != nil {
 		h.promReadMetrics.fetchErrorsServer.Inc(1)
@@ -160,7 +189,111 @@ func (h *PromReadHandler) read(
 
 	ctx, cancel := context.WithTimeout(reqCtx, timeout)
 	defer cancel()
-	promQuery := r.Queries[0]
+	// Detect clients closing connections
+	handler.CloseWatcher(ctx, cancel, w)
+
+	fetchResults, err := h.fetch(ctx, r.Queries[0])
+	if err != nil {
+		return nil, err
+	}
+
+	promResults := make([]*prompb.QueryResult, 0, len(fetchResults))
+	for _, result := range fetchResults {
+		promRes := storage.FetchResultToPromResult(result)
+		promResults = append(promResults, promRes)
+	}
+
+	return promResults, nil
+}
+
+// serveStreamed writes the results of every query of the request as a
+// stream of chunked read responses. Once the first frame has been written
+// errors can no longer be surfaced as a status code, so the stream is cut
+// short instead.
+func (h *PromReadHandler) serveStreamed(
+	reqCtx context.Context,
+	w http.ResponseWriter,
+	r *prompb.ReadRequest,
+	timeout time.Duration,
+) {
+	logger := logging.WithContext(reqCtx)
+	writer, err := newChunkedWriter(w)
+	if err != nil {
+		h.promReadMetrics.fetchErrorsServer.Inc(1)
+		logger.Error("unable to stream read results", zap.Any("error", err))
+		xhttp.Error(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(reqCtx, timeout)
+	defer cancel()
+	// Detect clients closing connections
+	handler.CloseWatcher(ctx, cancel, w)
+
+	for i, promQuery := range r.Queries {
+		if err := h.streamQuery(ctx, writer, int64(i), promQuery); err != nil {
+			h.promReadMetrics.fetchErrorsServer.Inc(1)
+			logger.Error("unable to stream read results", zap.Any("error", err))
+			if writer.frames == 0 {
+				xhttp.Error(w, err, http.StatusInternalServerError)
+			}
+
+			return
+		}
+	}
+
+	h.promReadMetrics.fetchSuccess.Inc(1)
+}
+
+// streamQuery writes the series of a query as chunked read responses. Series
+// are decompressed and re-encoded one at a time so that memory is bounded by
+// the size of a frame rather than by the size of the result.
+func (h *PromReadHandler) streamQuery(
+	ctx context.Context,
+	w *chunkedWriter,
+	queryIndex int64,
+	promQuery *prompb.Query,
+) error {
+	query, err := storage.PromReadQueryToM3(promQuery)
+	if err != nil {
+		return err
+	}
+
+	iters, cleanup, err := h.engine.ExecuteCompressed(ctx, query)
+	if err == errors.ErrNotImplemented {
+		// Fall back to decompressing every series upfront for storage which
+		// cannot fetch compressed series.
+		fetchResults, err := h.fetch(ctx, promQuery)
+		if err != nil {
+			return err
+		}
+
+		return writeChunkedReadResponses(w, queryIndex, fetchResults)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer cleanup()
+
+	frame := newChunkedFrame(w, queryIndex)
+	for _, iter := range iters.Iters() {
+		frame.nextSeries()
+		err := storage.SeriesIteratorToPromChunks(iter, samplesPerChunk,
+			h.tagOptions, frame.add)
+		if err != nil {
+			return err
+		}
+	}
+
+	return frame.flush()
+}
+
+func (h *PromReadHandler) fetch(
+	ctx context.Context,
+	promQuery *prompb.Query,
+) ([]*storage.FetchResult, error) {
 	query, err := storage.PromReadQueryToM3(promQuery)
 	if err != nil {
 		return nil, err
@@ -170,19 +303,117 @@ func (h *PromReadHandler) read(
 	results := make(chan *storage.QueryResult)
 
 	opts := &executor.EngineOptions{}
-	// Detect clients closing connections
-	handler.CloseWatcher(ctx, cancel, w)
 	go h.engine.Execute(ctx, query, opts, results)
 
-	promResults := make([]*prompb.QueryResult, 0, 1)
+	fetchResults := make([]*storage.FetchResult, 0, 1)
 	for result := range results {
 		if result.Err != nil {
 			return nil, result.Err
 		}
 
-		promRes := storage.FetchResultToPromResult(result.FetchResult)
-		promResults = append(promResults, promRes)
+		fetchResults = append(fetchResults, result.FetchResult)
 	}
 
-	return promResults, nil
+	return fetchResults, nil
+}
+
+// negotiateResponseType returns the first of the response types accepted by
+// the client that is supported, clients which do not list any response type
+// only accept sampled responses.
+func negotiateResponseType(
+	accepted []prompb.ReadRequest_ResponseType,
+) (prompb.ReadRequest_ResponseType, error) {
+	if len(accepted) == 0 {
+		return prompb.ReadRequest_SAMPLES, nil
+	}
+
+	for _, responseType := range accepted {
+		switch responseType {
+		case prompb.ReadRequest_SAMPLES, prompb.ReadRequest_STREAMED_XOR_CHUNKS:
+			return responseType, nil
+		}
+	}
+
+	return 0, fmt.Errorf("none of the accepted response types are supported: %v",
+		accepted)
+}
+
+// writeChunkedReadResponses encodes the series of the results of a query to
+// XOR chunks, writing them as frames of at least maxBytesInFrame bytes.
+func writeChunkedReadResponses(
+	w *chunkedWriter,
+	queryIndex int64,
+	results []*storage.FetchResult,
+) error {
+	frame := newChunkedFrame(w, queryIndex)
+	for _, result := range results {
+		for _, series := range result.SeriesList {
+			chunked, err := storage.SeriesToPromChunkedSeries(series, samplesPerChunk)
+			if err != nil {
+				return err
+			}
+
+			frame.nextSeries()
+			for _, chunk := range chunked.Chunks {
+				if err := frame.add(chunked.Labels, chunk); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return frame.flush()
+}
+
+// chunkedFrame accumulates the chunks of the series of a query, writing them
+// as a frame once it holds at least maxBytesInFrame bytes. The chunks of a
+// single series may be split across several frames.
+type chunkedFrame struct {
+	w       *chunkedWriter
+	frame   *prompb.ChunkedReadResponse
+	bytes   int
+	current *prompb.ChunkedSeries
+}
+
+func newChunkedFrame(w *chunkedWriter, queryIndex int64) *chunkedFrame {
+	return &chunkedFrame{
+		w:     w,
+		frame: &prompb.ChunkedReadResponse{QueryIndex: queryIndex},
+	}
+}
+
+// nextSeries starts a new series, chunks added afterwards belong to it.
+func (f *chunkedFrame) nextSeries() {
+	f.current = nil
+}
+
+func (f *chunkedFrame) add(labels []*prompb.Label, chunk *prompb.Chunk) error {
+	if f.current == nil {
+		f.current = &prompb.ChunkedSeries{Labels: labels}
+		f.frame.ChunkedSeries = append(f.frame.ChunkedSeries, f.current)
+	}
+
+	f.current.Chunks = append(f.current.Chunks, chunk)
+	f.bytes += chunk.Size()
+	if f.bytes < maxBytesInFrame {
+		return nil
+	}
+
+	return f.flush()
+}
+
+// flush writes the chunks accumulated so far, if any.
+func (f *chunkedFrame) flush() error {
+	if len(f.frame.ChunkedSeries) == 0 {
+		return nil
+	}
+
+	if err := f.w.write(f.frame); err != nil {
+		return err
+	}
+
+	f.frame.ChunkedSeries = f.frame.ChunkedSeries[:0]
+	f.bytes = 0
+	f.current = nil
+	return nil
 }
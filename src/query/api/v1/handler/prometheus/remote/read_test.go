@@ -21,8 +21,13 @@
 package remote
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
+	"hash/crc32"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -32,14 +37,19 @@ import (
 	xmetrics "github.com/m3db/m3/src/dbnode/x/metrics"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/test"
 	"github.com/m3db/m3/src/query/test/m3"
+	"github.com/m3db/m3/src/query/test/seriesiter"
+	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/util/logging"
 	xclock "github.com/m3db/m3x/clock"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
@@ -69,7 +79,8 @@ func setupServer(t *testing.T) *httptest.Server {
 }
 
 func readHandler(store storage.Storage, timeoutOpts *prometheus.TimeoutOpts) *PromReadHandler {
-	return &PromReadHandler{engine: executor.NewEngine(store, tally.NewTestScope("test", nil), defaultLookbackDuration, nil),
+	return &PromReadHandler{engine: executor.NewEngine(store, tally.NewTestScope("test", nil), defaultLookbackDuration, nil, nil),
+		tagOptions:      models.NewTagOptions(),
 		promReadMetrics: promReadTestMetrics,
 		timeoutOpts:     timeoutOpts,
 	}
@@ -79,7 +90,7 @@ func TestPromReadParsing(t *testing.T) {
 	logging.InitWithCores(nil)
 	ctrl := gomock.NewController(t)
 	storage, _ := m3.NewStorageAndSession(t, ctrl)
-	promRead := &PromReadHandler{engine: executor.NewEngine(storage, tally.NewTestScope("test", nil), defaultLookbackDuration, nil), promReadMetrics: promReadTestMetrics}
+	promRead := &PromReadHandler{engine: executor.NewEngine(storage, tally.NewTestScope("test", nil), defaultLookbackDuration, nil, nil), promReadMetrics: promReadTestMetrics}
 	req, _ := http.NewRequest("POST", PromReadURL, test.GeneratePromReadBody(t))
 
 	r, err := promRead.parseRequest(req)
@@ -92,7 +103,7 @@ func TestPromFetchTimeoutParsing(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	storage, _ := m3.NewStorageAndSession(t, ctrl)
 	promRead := &PromReadHandler{
-		engine:          executor.NewEngine(storage, tally.NewTestScope("test", nil), defaultLookbackDuration, nil),
+		engine:          executor.NewEngine(storage, tally.NewTestScope("test", nil), defaultLookbackDuration, nil, nil),
 		promReadMetrics: promReadTestMetrics,
 		timeoutOpts: &prometheus.TimeoutOpts{
 			FetchTimeout: 2 * time.Minute,
@@ -180,7 +191,7 @@ func TestReadErrorMetricsCount(t *testing.T) {
 	defer closer.Close()
 	readMetrics := newPromReadMetrics(scope)
 
-	promRead := &PromReadHandler{engine: executor.NewEngine(storage, scope, defaultLookbackDuration, nil), promReadMetrics: readMetrics, timeoutOpts: timeoutOpts}
+	promRead := &PromReadHandler{engine: executor.NewEngine(storage, scope, defaultLookbackDuration, nil, nil), promReadMetrics: readMetrics, timeoutOpts: timeoutOpts}
 	req, _ := http.NewRequest("POST", PromReadURL, test.GeneratePromReadBody(t))
 	promRead.ServeHTTP(httptest.NewRecorder(), req)
 
@@ -190,3 +201,190 @@ func TestReadErrorMetricsCount(t *testing.T) {
 	}, 5*time.Second)
 	require.True(t, foundMetric)
 }
+
+func TestNegotiateResponseType(t *testing.T) {
+	tests := []struct {
+		accepted []prompb.ReadRequest_ResponseType
+		expected prompb.ReadRequest_ResponseType
+		err      bool
+	}{
+		{
+			expected: prompb.ReadRequest_SAMPLES,
+		},
+		{
+			accepted: []prompb.ReadRequest_ResponseType{
+				prompb.ReadRequest_STREAMED_XOR_CHUNKS,
+				prompb.ReadRequest_SAMPLES,
+			},
+			expected: prompb.ReadRequest_STREAMED_XOR_CHUNKS,
+		},
+		{
+			accepted: []prompb.ReadRequest_ResponseType{
+				prompb.ReadRequest_ResponseType(5),
+				prompb.ReadRequest_SAMPLES,
+			},
+			expected: prompb.ReadRequest_SAMPLES,
+		},
+		{
+			accepted: []prompb.ReadRequest_ResponseType{
+				prompb.ReadRequest_ResponseType(5),
+			},
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		responseType, err := negotiateResponseType(tt.accepted)
+		if tt.err {
+			assert.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, responseType)
+	}
+}
+
+func readChunkedFrames(
+	t *testing.T,
+	r io.Reader,
+) []*prompb.ChunkedReadResponse {
+	var (
+		reader = bufio.NewReader(r)
+		frames []*prompb.ChunkedReadResponse
+	)
+
+	for {
+		size, err := binary.ReadUvarint(reader)
+		if err == io.EOF {
+			return frames
+		}
+
+		require.NoError(t, err)
+
+		checksum := make([]byte, frameChecksumSize)
+		_, err = io.ReadFull(reader, checksum)
+		require.NoError(t, err)
+
+		data := make([]byte, size)
+		_, err = io.ReadFull(reader, data)
+		require.NoError(t, err)
+		require.Equal(t, binary.BigEndian.Uint32(checksum),
+			crc32.Checksum(data, castagnoliTable))
+
+		var frame prompb.ChunkedReadResponse
+		require.NoError(t, proto.Unmarshal(data, &frame))
+		frames = append(frames, &frame)
+	}
+}
+
+func TestWriteChunkedReadResponses(t *testing.T) {
+	start := time.Unix(1556813520, 0)
+	datapoints := make(ts.Datapoints, 0, 2*samplesPerChunk+1)
+	for i := 0; i < 2*samplesPerChunk+1; i++ {
+		datapoints = append(datapoints, ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * 10 * time.Second),
+			Value:     float64(i),
+		})
+	}
+
+	tags := models.NewTags(1, models.NewTagOptions()).
+		AddTag(models.Tag{Name: []byte("__name__"), Value: []byte("first")})
+	results := []*storage.FetchResult{
+		{
+			SeriesList: ts.SeriesList{
+				ts.NewSeries([]byte("first"), datapoints, tags),
+				ts.NewSeries([]byte("first"), ts.Datapoints{}, tags),
+			},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	writer, err := newChunkedWriter(recorder)
+	require.NoError(t, err)
+	require.NoError(t, writeChunkedReadResponses(writer, 3, results))
+	require.NoError(t, writeChunkedReadResponses(writer, 4, nil))
+
+	assert.Equal(t, StreamedReadContentType, recorder.Header().Get("Content-Type"))
+	assert.True(t, recorder.Flushed)
+
+	frames := readChunkedFrames(t, recorder.Body)
+	require.Len(t, frames, 1)
+	assert.Equal(t, int64(3), frames[0].QueryIndex)
+	require.Len(t, frames[0].ChunkedSeries, 1)
+
+	series := frames[0].ChunkedSeries[0]
+	assert.Equal(t, storage.TagsToPromLabels(tags), series.Labels)
+	require.Len(t, series.Chunks, 3)
+	for i, chunk := range series.Chunks {
+		assert.Equal(t, prompb.Chunk_XOR, chunk.Type)
+		assert.Equal(t, storage.TimeToTimestamp(
+			datapoints[i*samplesPerChunk].Timestamp), chunk.MinTimeMs)
+	}
+}
+
+func TestPromReadStreamedWithFetchError(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	storage, session := m3.NewStorageAndSession(t, ctrl)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, true, fmt.Errorf("unable to get data"))
+	session.EXPECT().IteratorPools().
+		Return(nil, nil)
+
+	promRead := readHandler(storage, timeoutOpts)
+	readReq := test.GeneratePromReadRequest()
+	readReq.AcceptedResponseTypes = []prompb.ReadRequest_ResponseType{
+		prompb.ReadRequest_STREAMED_XOR_CHUNKS,
+	}
+
+	data, err := proto.Marshal(readReq)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", PromReadURL,
+		bytes.NewReader(snappy.Encode(nil, data)))
+	recorder := httptest.NewRecorder()
+	promRead.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.NotEqual(t, StreamedReadContentType, recorder.Header().Get("Content-Type"))
+}
+
+func TestPromReadStreamedFromIterators(t *testing.T) {
+	logging.InitWithCores(nil)
+	ctrl := gomock.NewController(t)
+	storage, session := m3.NewStorageAndSession(t, ctrl)
+	session.EXPECT().FetchTagged(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(seriesiter.NewMockSeriesIters(ctrl, seriesiter.GenerateTag(), 2, 3),
+			true, nil)
+	session.EXPECT().IteratorPools().
+		Return(nil, nil)
+
+	promRead := readHandler(storage, timeoutOpts)
+	readReq := test.GeneratePromReadRequest()
+	readReq.AcceptedResponseTypes = []prompb.ReadRequest_ResponseType{
+		prompb.ReadRequest_STREAMED_XOR_CHUNKS,
+	}
+
+	data, err := proto.Marshal(readReq)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", PromReadURL,
+		bytes.NewReader(snappy.Encode(nil, data)))
+	recorder := httptest.NewRecorder()
+	promRead.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, StreamedReadContentType, recorder.Header().Get("Content-Type"))
+
+	frames := readChunkedFrames(t, recorder.Body)
+	require.Len(t, frames, 1)
+	require.Len(t, frames[0].ChunkedSeries, 2)
+	for _, series := range frames[0].ChunkedSeries {
+		assert.Equal(t, []*prompb.Label{
+			{Name: []byte("foo"), Value: []byte("bar")},
+		}, series.Labels)
+		require.Len(t, series.Chunks, 1)
+		assert.Equal(t, prompb.Chunk_XOR, series.Chunks[0].Type)
+	}
+}
@@ -56,13 +56,16 @@ var (
 // PromWriteHandler represents a handler for prometheus write endpoint.
 type PromWriteHandler struct {
 	downsamplerAndWriter ingest.DownsamplerAndWriter
+	metadataStore        storage.MetricMetadataStore
 	promWriteMetrics     promWriteMetrics
 	tagOptions           models.TagOptions
 }
 
-// NewPromWriteHandler returns a new instance of handler.
+// NewPromWriteHandler returns a new instance of handler, the metric metadata
+// of write requests is dropped if metadataStore is nil.
 func NewPromWriteHandler(
 	downsamplerAndWriter ingest.DownsamplerAndWriter,
+	metadataStore storage.MetricMetadataStore,
 	tagOptions models.TagOptions,
 	scope tally.Scope,
 ) (http.Handler, error) {
@@ -72,6 +75,7 @@ func NewPromWriteHandler(
 
 	return &PromWriteHandler{
 		downsamplerAndWriter: downsamplerAndWriter,
+		metadataStore:        metadataStore,
 		promWriteMetrics:     newPromWriteMetrics(scope),
 		tagOptions:           tagOptions,
 	}, nil
@@ -81,6 +85,7 @@ type promWriteMetrics struct {
 	writeSuccess      tally.Counter
 	writeErrorsServer tally.Counter
 	writeErrorsClient tally.Counter
+	metadataErrors    tally.Counter
 }
 
 func newPromWriteMetrics(scope tally.Scope) promWriteMetrics {
@@ -88,6 +93,7 @@ func newPromWriteMetrics(scope tally.Scope) promWriteMetrics {
 		writeSuccess:      scope.Counter("write.success"),
 		writeErrorsServer: scope.Tagged(map[string]string{"code": "5XX"}).Counter("write.errors"),
 		writeErrorsClient: scope.Tagged(map[string]string{"code": "4XX"}).Counter("write.errors"),
+		metadataErrors:    scope.Counter("write.metadata.errors"),
 	}
 }
 
@@ -126,7 +132,22 @@ func (h *PromWriteHandler) parseRequest(r *http.Request) (*prompb.WriteRequest,
 
 func (h *PromWriteHandler) write(ctx context.Context, r *prompb.WriteRequest) error {
 	iter := newPromTSIter(r.Timeseries, h.tagOptions)
-	return h.downsamplerAndWriter.WriteBatch(ctx, iter)
+	if err := h.downsamplerAndWriter.WriteBatch(ctx, iter); err != nil {
+		return err
+	}
+
+	if h.metadataStore == nil || len(r.Metadata) == 0 {
+		return nil
+	}
+
+	// Metadata is best effort, Prometheus sends it again with later writes
+	// so it must not fail the write of the samples.
+	if err := h.metadataStore.WriteMetadata(ctx, r.Metadata); err != nil {
+		h.promWriteMetrics.metadataErrors.Inc(1)
+		logging.WithContext(ctx).Warn("Metadata write error", zap.Any("err", err))
+	}
+
+	return nil
 }
 
 func newPromTSIter(timeseries []*prompb.TimeSeries, tagOpts models.TagOptions) *promTSIter {
@@ -22,6 +22,7 @@ package remote
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -30,6 +31,7 @@ import (
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
 	"github.com/m3db/m3/src/dbnode/x/metrics"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote/test"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
 	"github.com/m3db/m3/src/query/util/logging"
 	xclock "github.com/m3db/m3x/clock"
 
@@ -72,6 +74,79 @@ func TestPromWrite(t *testing.T) {
 	require.NoError(t, writeErr)
 }
 
+func TestPromWriteMetadata(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().WriteBatch(gomock.Any(), gomock.Any())
+
+	metadataStore := &testMetricMetadataStore{}
+	promWrite := &PromWriteHandler{
+		downsamplerAndWriter: mockDownsamplerAndWriter,
+		metadataStore:        metadataStore,
+	}
+
+	promReq := test.GeneratePromWriteRequest()
+	promReq.Metadata = []*prompb.MetricMetadata{
+		{
+			Type:             prompb.MetricMetadata_COUNTER,
+			MetricFamilyName: "http_requests_total",
+			Help:             "Total number of HTTP requests.",
+		},
+	}
+
+	promReqBody := test.GeneratePromWriteRequestBody(t, promReq)
+	req, _ := http.NewRequest("POST", PromWriteURL, promReqBody)
+
+	r, err := promWrite.parseRequest(req)
+	require.Nil(t, err, "unable to parse request")
+
+	writeErr := promWrite.write(context.TODO(), r)
+	require.NoError(t, writeErr)
+	require.Equal(t, promReq.Metadata, metadataStore.written)
+}
+
+func TestPromWriteMetadataError(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	ctrl := gomock.NewController(t)
+	mockDownsamplerAndWriter := ingest.NewMockDownsamplerAndWriter(ctrl)
+	mockDownsamplerAndWriter.EXPECT().WriteBatch(gomock.Any(), gomock.Any())
+
+	reporter := xmetrics.NewTestStatsReporter(xmetrics.NewTestStatsReporterOptions())
+	scope, closer := tally.NewRootScope(tally.ScopeOptions{Reporter: reporter}, time.Millisecond)
+	defer closer.Close()
+
+	promWrite := &PromWriteHandler{
+		downsamplerAndWriter: mockDownsamplerAndWriter,
+		metadataStore:        &testMetricMetadataStore{writeErr: errors.New("boom")},
+		promWriteMetrics:     newPromWriteMetrics(scope),
+	}
+
+	promReq := test.GeneratePromWriteRequest()
+	promReq.Metadata = []*prompb.MetricMetadata{
+		{
+			Type:             prompb.MetricMetadata_COUNTER,
+			MetricFamilyName: "http_requests_total",
+		},
+	}
+
+	promReqBody := test.GeneratePromWriteRequestBody(t, promReq)
+	req, _ := http.NewRequest("POST", PromWriteURL, promReqBody)
+
+	// Metadata write failures are counted but do not fail the write.
+	recorder := httptest.NewRecorder()
+	promWrite.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	foundMetric := xclock.WaitUntil(func() bool {
+		return reporter.Counters()["write.metadata.errors"] == 1 &&
+			reporter.Counters()["write.success"] == 1
+	}, 5*time.Second)
+	require.True(t, foundMetric)
+}
+
 func TestWriteErrorMetricCount(t *testing.T) {
 	logging.InitWithCores(nil)
 
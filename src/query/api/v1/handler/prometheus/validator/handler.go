@@ -109,7 +109,7 @@ func (h *PromDebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	engine := executor.NewEngine(s, h.scope.SubScope("debug_engine"), h.lookbackDuration, nil)
+	engine := executor.NewEngine(s, h.scope.SubScope("debug_engine"), h.lookbackDuration, nil, nil)
 	results, _, respErr := h.readHandler.ServeHTTPWithEngine(w, r, engine)
 	if respErr != nil {
 		logger.Error("unable to read data", zap.Error(respErr.Err))
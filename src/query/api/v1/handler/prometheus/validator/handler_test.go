@@ -302,12 +302,15 @@ func newServer() (*httptest.Server, *PromDebugHandler) {
 	mockStorage := mock.NewMockStorage()
 	debugHandler := NewPromDebugHandler(
 		native.NewPromReadHandler(
-			executor.NewEngine(mockStorage, tally.NewTestScope("test_engine", nil), defaultLookbackDuration, cost.NoopChainedEnforcer()),
+			executor.NewEngine(mockStorage, tally.NewTestScope("test_engine", nil), defaultLookbackDuration, cost.NoopChainedEnforcer(), nil),
 			models.NewTagOptions(),
 			&config.LimitsConfiguration{},
 			tally.NewTestScope("test", nil),
 			timeoutOpts,
 			true,
+			nil,
+			nil,
+			nil,
 		), tally.NewTestScope("test", nil),
 		defaultLookbackDuration,
 	)
@@ -35,17 +35,21 @@ import (
 	"github.com/m3db/m3/src/query/api/v1/handler"
 	"github.com/m3db/m3/src/query/api/v1/handler/database"
 	"github.com/m3db/m3/src/query/api/v1/handler/graphite"
+	"github.com/m3db/m3/src/query/api/v1/handler/influxdb"
 	m3json "github.com/m3db/m3/src/query/api/v1/handler/json"
 	"github.com/m3db/m3/src/query/api/v1/handler/namespace"
 	"github.com/m3db/m3/src/query/api/v1/handler/openapi"
+	"github.com/m3db/m3/src/query/api/v1/handler/opentsdb"
 	"github.com/m3db/m3/src/query/api/v1/handler/placement"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/validator"
 	"github.com/m3db/m3/src/query/api/v1/handler/topic"
+	"github.com/m3db/m3/src/query/cache"
 	"github.com/m3db/m3/src/query/cost"
 	"github.com/m3db/m3/src/query/executor"
+	graphitestorage "github.com/m3db/m3/src/query/graphite/storage"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/m3"
@@ -54,6 +58,8 @@ import (
 	"github.com/m3db/m3/src/x/net/http/cors"
 
 	"github.com/gorilla/mux"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/uber-go/tally"
@@ -65,8 +71,11 @@ const (
 )
 
 var (
-	remoteSource = map[string]string{"source": "remote"}
-	nativeSource = map[string]string{"source": "native"}
+	remoteSource   = map[string]string{"source": "remote"}
+	nativeSource   = map[string]string{"source": "native"}
+	m3qlSource     = map[string]string{"source": "m3ql"}
+	influxSource   = map[string]string{"source": "influxdb"}
+	openTSDBSource = map[string]string{"source": "opentsdb"}
 
 	defaultTimeout = 30 * time.Second
 )
@@ -109,6 +118,10 @@ func NewHandler(
 	r := mux.NewRouter()
 
 	handlerWithMiddleware := applyMiddleware(r, opentracing.GlobalTracer())
+	if header := cfg.Limits.PerTenant.Header; header != "" &&
+		http.CanonicalHeaderKey(header) != handler.TenantHeader {
+		handlerWithMiddleware = withTenantHeader(handlerWithMiddleware, header)
+	}
 
 	var timeoutOpts = &prometheus.TimeoutOpts{}
 	if embeddedDbCfg == nil || embeddedDbCfg.Client.FetchTimeout == nil {
@@ -157,6 +170,20 @@ func applyMiddleware(base *mux.Router, tracer opentracing.Tracer) http.Handler {
 	return withMiddleware
 }
 
+// withTenantHeader identifies the tenant of requests using the given header
+// by copying it to the tenant header used by the handlers, any tenant header
+// set by the client is dropped so that clients cannot choose their tenant.
+func withTenantHeader(base http.Handler, header string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(handler.TenantHeader)
+		if tenant := r.Header.Get(header); tenant != "" {
+			r.Header.Set(handler.TenantHeader, tenant)
+		}
+
+		base.ServeHTTP(w, r)
+	})
+}
+
 // RegisterRoutes registers all http routes.
 func (h *Handler) RegisterRoutes() error {
 	// Wrap requests with response time logging as well as panic recovery.
@@ -168,10 +195,17 @@ func (h *Handler) RegisterRoutes() error {
 	).Methods(openapi.HTTPMethod)
 	h.router.PathPrefix(openapi.StaticURLPrefix).Handler(wrapped(openapi.StaticHandler()))
 
+	metadataStore, err := h.metricMetadataStore()
+	if err != nil {
+		return err
+	}
+
 	// Prometheus remote read/write endpoints
-	promRemoteReadHandler := remote.NewPromReadHandler(h.engine, h.scope.Tagged(remoteSource), h.timeoutOpts)
+	promRemoteReadHandler := remote.NewPromReadHandler(h.engine, h.tagOptions,
+		h.scope.Tagged(remoteSource), h.timeoutOpts)
 	promRemoteWriteHandler, err := remote.NewPromWriteHandler(
 		h.downsamplerAndWriter,
+		metadataStore,
 		h.tagOptions,
 		h.scope.Tagged(remoteSource),
 	)
@@ -179,6 +213,30 @@ func (h *Handler) RegisterRoutes() error {
 		return err
 	}
 
+	var resultsCache *cache.ResultsCache
+	if cacheCfg := h.config.Cache.Results; cacheCfg != nil {
+		instrumentOpts := instrument.NewOptions().SetMetricsScope(h.scope)
+		resultsCache, err = cacheCfg.NewResultsCache(h.tagOptions, instrumentOpts)
+		if err != nil {
+			return err
+		}
+	}
+
+	var splitter *native.QuerySplitter
+	if splitCfg := h.config.QuerySplit; splitCfg != nil {
+		splitter, err = native.NewQuerySplitter(splitCfg.IntervalOrDefault(),
+			splitCfg.MaxConcurrencyOrDefault())
+		if err != nil {
+			return err
+		}
+	}
+
+	var slowQueryLogger *native.SlowQueryLogger
+	if logCfg := h.config.SlowQueryLog; logCfg != nil {
+		slowQueryLogger = native.NewSlowQueryLogger(logCfg.LatencyThreshold,
+			logCfg.SeriesThreshold, logCfg.Headers)
+	}
+
 	nativePromReadHandler := native.NewPromReadHandler(
 		h.engine,
 		h.tagOptions,
@@ -186,6 +244,9 @@ func (h *Handler) RegisterRoutes() error {
 		h.scope.Tagged(nativeSource),
 		h.timeoutOpts,
 		h.config.ResultOptions.KeepNans,
+		resultsCache,
+		splitter,
+		slowQueryLogger,
 	)
 
 	h.router.HandleFunc(remote.PromReadURL,
@@ -201,6 +262,23 @@ func (h *Handler) RegisterRoutes() error {
 		wrapped(native.NewPromReadInstantHandler(h.engine, h.tagOptions, h.timeoutOpts)).ServeHTTP,
 	).Methods(native.PromReadInstantHTTPMethod)
 
+	// M3QL read endpoints
+	m3qlReadHandler := native.NewM3QLReadHandler(
+		h.engine,
+		h.tagOptions,
+		&h.config.Limits,
+		h.scope.Tagged(m3qlSource),
+		h.timeoutOpts,
+		h.config.ResultOptions.KeepNans,
+	)
+
+	h.router.HandleFunc(native.M3QLReadURL,
+		wrapped(m3qlReadHandler).ServeHTTP,
+	).Methods(native.M3QLReadHTTPMethod)
+	h.router.HandleFunc(native.M3QLReadInstantURL,
+		wrapped(native.NewM3QLReadInstantHandler(h.engine, h.tagOptions, h.timeoutOpts)).ServeHTTP,
+	).Methods(native.M3QLReadInstantHTTPMethod)
+
 	// Native M3 search and write endpoints
 	h.router.HandleFunc(handler.SearchURL,
 		wrapped(handler.NewSearchHandler(h.storage)).ServeHTTP,
@@ -209,33 +287,120 @@ func (h *Handler) RegisterRoutes() error {
 		wrapped(m3json.NewWriteJSONHandler(h.storage)).ServeHTTP,
 	).Methods(m3json.JSONWriteHTTPMethod)
 
+	// InfluxDB line protocol write endpoint
+	influxWriteHandler, err := influxdb.NewInfluxWriteHandler(
+		h.downsamplerAndWriter,
+		h.tagOptions,
+		h.scope.Tagged(influxSource),
+	)
+	if err != nil {
+		return err
+	}
+
+	h.router.HandleFunc(influxdb.InfluxWriteURL,
+		panicOnly(influxWriteHandler).ServeHTTP,
+	).Methods(influxdb.InfluxWriteHTTPMethod)
+
+	// OpenTSDB HTTP API endpoints
+	openTSDBPutHandler, err := opentsdb.NewPutHandler(
+		h.downsamplerAndWriter,
+		h.tagOptions,
+		h.scope.Tagged(openTSDBSource),
+	)
+	if err != nil {
+		return err
+	}
+
+	h.router.HandleFunc(opentsdb.PutURL,
+		panicOnly(openTSDBPutHandler).ServeHTTP,
+	).Methods(opentsdb.PutHTTPMethod)
+	h.router.HandleFunc(opentsdb.QueryURL,
+		wrapped(opentsdb.NewQueryHandler(h.engine, h.tagOptions, h.timeoutOpts)).ServeHTTP,
+	).Methods(opentsdb.QueryHTTPMethods...)
+
 	// Tag completion endpoints
 	h.router.HandleFunc(native.CompleteTagsURL,
 		wrapped(native.NewCompleteTagsHandler(h.storage)).ServeHTTP,
 	).Methods(native.CompleteTagsHTTPMethod)
+	h.router.HandleFunc(remote.TagNamesURL,
+		wrapped(remote.NewTagNamesHandler(h.storage, h.tagOptions)).ServeHTTP,
+	).Methods(remote.TagNamesHTTPMethod)
 	h.router.HandleFunc(remote.TagValuesURL,
-		wrapped(remote.NewTagValuesHandler(h.storage)).ServeHTTP,
+		wrapped(remote.NewTagValuesHandler(h.storage, h.tagOptions)).ServeHTTP,
 	).Methods(remote.TagValuesHTTPMethod)
 
+	// Metric metadata endpoint
+	h.router.HandleFunc(remote.PromMetadataURL,
+		wrapped(remote.NewPromMetadataHandler(metadataStore)).ServeHTTP,
+	).Methods(remote.PromMetadataHTTPMethod)
+
 	// Series match endpoints
 	h.router.HandleFunc(remote.PromSeriesMatchURL,
 		wrapped(remote.NewPromSeriesMatchHandler(h.storage, h.tagOptions)).ServeHTTP,
 	).Methods(remote.PromSeriesMatchHTTPMethod)
 
+	// Federation and export endpoints
+	h.router.HandleFunc(remote.FederateURL,
+		wrapped(remote.NewFederateHandler(h.storage, h.tagOptions,
+			*h.config.LookbackDuration, h.timeoutOpts)).ServeHTTP,
+	).Methods(remote.FederateHTTPMethod)
+
+	h.router.HandleFunc(remote.ExportURL,
+		wrapped(remote.NewExportHandler(h.storage, h.tagOptions, h.timeoutOpts)).ServeHTTP,
+	).Methods(remote.ExportHTTPMethod)
+
 	// Debug endpoints
 	h.router.HandleFunc(validator.PromDebugURL,
 		wrapped(validator.NewPromDebugHandler(nativePromReadHandler, h.scope, *h.config.LookbackDuration)).ServeHTTP,
 	).Methods(validator.PromDebugHTTPMethod)
 
+	h.router.HandleFunc(handler.ActiveQueriesURL,
+		wrapped(handler.NewActiveQueriesHandler(h.engine)).ServeHTTP,
+	).Methods(handler.ActiveQueriesHTTPMethod)
+
+	h.router.HandleFunc(handler.CancelQueryURL,
+		wrapped(handler.NewCancelQueryHandler(h.engine)).ServeHTTP,
+	).Methods(handler.CancelQueryHTTPMethod)
+
 	// Graphite endpoints
+	graphitePolicyResolver, err := h.graphitePolicyResolver()
+	if err != nil {
+		return err
+	}
+
 	h.router.HandleFunc(graphite.ReadURL,
-		wrapped(graphite.NewRenderHandler(h.storage, h.enforcer)).ServeHTTP,
+		wrapped(graphite.NewRenderHandler(h.storage, h.enforcer,
+			graphitePolicyResolver)).ServeHTTP,
 	).Methods(graphite.ReadHTTPMethods...)
 
+	graphiteFindOpts, err := h.graphiteFindOptions(graphitePolicyResolver)
+	if err != nil {
+		return err
+	}
+
 	h.router.HandleFunc(graphite.FindURL,
-		wrapped(graphite.NewFindHandler(h.storage)).ServeHTTP,
+		wrapped(graphite.NewFindHandler(h.storage,
+			graphiteFindOpts)).ServeHTTP,
 	).Methods(graphite.FindHTTPMethods...)
 
+	h.router.HandleFunc(graphite.ExpandURL,
+		wrapped(graphite.NewExpandHandler(h.storage,
+			graphiteFindOpts)).ServeHTTP,
+	).Methods(graphite.ExpandHTTPMethods...)
+
+	h.router.HandleFunc(graphite.AutoCompleteTagsURL,
+		wrapped(graphite.NewAutoCompleteTagsHandler(h.storage)).ServeHTTP,
+	).Methods(graphite.TagsHTTPMethods...)
+	h.router.HandleFunc(graphite.AutoCompleteValuesURL,
+		wrapped(graphite.NewAutoCompleteValuesHandler(h.storage)).ServeHTTP,
+	).Methods(graphite.TagsHTTPMethods...)
+	h.router.HandleFunc(graphite.TagsURL,
+		wrapped(graphite.NewTagsHandler(h.storage)).ServeHTTP,
+	).Methods(graphite.TagsHTTPMethods...)
+	h.router.HandleFunc(graphite.TagValuesURL,
+		wrapped(graphite.NewTagValuesHandler(h.storage)).ServeHTTP,
+	).Methods(graphite.TagsHTTPMethods...)
+
 	if h.clusterClient != nil {
 		placementOpts := placement.HandlerOptions{
 			ClusterClient:       h.clusterClient,
@@ -256,6 +421,29 @@ func (h *Handler) RegisterRoutes() error {
 	return nil
 }
 
+// metricMetadataStore returns the store for the metric metadata sent with
+// Prometheus remote writes, or nil if no metadata namespace is configured in
+// which case the metadata is dropped.
+func (h *Handler) metricMetadataStore() (storage.MetricMetadataStore, error) {
+	promCfg := h.config.Prometheus
+	if promCfg == nil || promCfg.Metadata == nil {
+		return nil, nil
+	}
+
+	if h.clusters == nil {
+		return nil, errors.New("prometheus metadata is only supported when " +
+			"connecting to M3DB clusters directly")
+	}
+
+	// The metadata namespace lives in the cluster of the unaggregated
+	// namespace but is not one of the cluster namespaces, so it is never
+	// queried for series.
+	session := h.clusters.UnaggregatedClusterNamespace().Session()
+	instrumentOpts := instrument.NewOptions().SetMetricsScope(h.scope)
+	return m3.NewMetricMetadataStore(ident.StringID(promCfg.Metadata.Namespace),
+		session, h.tagOptions, instrumentOpts), nil
+}
+
 func (h *Handler) m3AggServiceOptions() *handler.M3AggServiceOptions {
 	if h.clusters == nil {
 		return nil
@@ -278,6 +466,50 @@ func (h *Handler) m3AggServiceOptions() *handler.M3AggServiceOptions {
 	}
 }
 
+// graphitePolicyResolver returns a resolver for the namespaces that carbon
+// ingestion rules write graphite paths to, or nil if no rules are configured
+// in which case graphite queries fan out to all aggregated namespaces.
+func (h *Handler) graphitePolicyResolver() (*graphitestorage.PolicyResolver, error) {
+	if h.config.Carbon == nil || h.config.Carbon.Ingester == nil ||
+		len(h.config.Carbon.Ingester.Rules) == 0 {
+		return nil, nil
+	}
+
+	rules := make([]graphitestorage.PolicyRule, 0, len(h.config.Carbon.Ingester.Rules))
+	for _, rule := range h.config.Carbon.Ingester.Rules {
+		rules = append(rules, graphitestorage.PolicyRule{
+			Pattern:    rule.Pattern,
+			AndPattern: rule.AndPattern,
+			Policies:   rule.StoragePolicies(),
+		})
+	}
+
+	return graphitestorage.NewPolicyResolver(rules)
+}
+
+// graphiteFindOptions returns the options shared by the graphite find and
+// expand handlers, so that both use the same cache of completed prefixes.
+func (h *Handler) graphiteFindOptions(
+	resolver *graphitestorage.PolicyResolver,
+) (graphite.FindOptions, error) {
+	opts := graphite.FindOptions{
+		PolicyResolver: resolver,
+		MaxResults:     h.config.Limits.PerQuery.MaxGraphiteFindResults,
+	}
+
+	if cacheCfg := h.config.Cache.GraphiteFind; cacheCfg != nil {
+		findCache, err := graphite.NewFindCache(cacheCfg.SizeOrDefault(),
+			cacheCfg.TTLOrDefault())
+		if err != nil {
+			return opts, err
+		}
+
+		opts.Cache = findCache
+	}
+
+	return opts, nil
+}
+
 // Endpoints useful for profiling the service
 func (h *Handler) registerHealthEndpoints() {
 	h.router.HandleFunc(healthURL, func(w http.ResponseWriter, r *http.Request) {
@@ -32,6 +32,7 @@ import (
 	dbconfig "github.com/m3db/m3/src/cmd/services/m3dbnode/config"
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/query/api/v1/handler"
 	m3json "github.com/m3db/m3/src/query/api/v1/handler/json"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
 	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/remote"
@@ -66,7 +67,7 @@ func setupHandler(store storage.Storage) (*Handler, error) {
 		downsamplerAndWriter,
 		makeTagOptions(),
 		executor.NewEngine(store, tally.NewTestScope("test", nil),
-			time.Minute, nil),
+			time.Minute, nil, nil),
 		nil,
 		nil,
 		config.Configuration{LookbackDuration: &defaultLookbackDuration},
@@ -84,7 +85,7 @@ func TestHandlerFetchTimeoutError(t *testing.T) {
 
 	negValue := -1 * time.Second
 	dbconfig := &dbconfig.DBConfiguration{Client: client.Configuration{FetchTimeout: &negValue}}
-	engine := executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil)
+	engine := executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil, nil)
 	cfg := config.Configuration{LookbackDuration: &defaultLookbackDuration}
 	_, err := NewHandler(downsamplerAndWriter, makeTagOptions(), engine, nil, nil,
 		cfg, dbconfig, nil, tally.NewTestScope("", nil))
@@ -101,7 +102,7 @@ func TestHandlerFetchTimeout(t *testing.T) {
 
 	fourMin := 4 * time.Minute
 	dbconfig := &dbconfig.DBConfiguration{Client: client.Configuration{FetchTimeout: &fourMin}}
-	engine := executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil)
+	engine := executor.NewEngine(storage, tally.NewTestScope("test", nil), time.Minute, nil, nil)
 	cfg := config.Configuration{LookbackDuration: &defaultLookbackDuration}
 	h, err := NewHandler(downsamplerAndWriter, makeTagOptions(), engine,
 		nil, nil, cfg, dbconfig, nil, tally.NewTestScope("", nil))
@@ -172,6 +173,21 @@ func TestPromNativeReadPost(t *testing.T) {
 	require.Equal(t, res.Code, http.StatusMethodNotAllowed, "POST method not defined")
 }
 
+func TestM3QLReadGet(t *testing.T) {
+	logging.InitWithCores(nil)
+
+	req, _ := http.NewRequest("GET", native.M3QLReadURL, nil)
+	res := httptest.NewRecorder()
+	ctrl := gomock.NewController(t)
+	storage, _ := m3.NewStorageAndSession(t, ctrl)
+
+	h, err := setupHandler(storage)
+	require.NoError(t, err, "unable to setup handler")
+	h.RegisterRoutes()
+	h.Router().ServeHTTP(res, req)
+	require.Equal(t, res.Code, http.StatusBadRequest, "Empty request")
+}
+
 func TestJSONWritePost(t *testing.T) {
 	logging.InitWithCores(nil)
 
@@ -282,6 +298,27 @@ func TestTracingMiddleware(t *testing.T) {
 	assert.NotEmpty(t, mtr.FinishedSpans())
 }
 
+func TestTenantHeaderMiddleware(t *testing.T) {
+	var tenant string
+	base := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get(handler.TenantHeader)
+	})
+	h := withTenantHeader(base, "X-Org")
+
+	// The configured header identifies the tenant.
+	req := httptest.NewRequest("GET", testRoute, nil)
+	req.Header.Set("X-Org", "foo")
+	req.Header.Set(handler.TenantHeader, "bar")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "foo", tenant)
+
+	// Clients cannot choose their tenant without the configured header.
+	req = httptest.NewRequest("GET", testRoute, nil)
+	req.Header.Set(handler.TenantHeader, "bar")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "", tenant)
+}
+
 const testRoute = "/foobar"
 
 func setupTestRoute(r *mux.Router) {
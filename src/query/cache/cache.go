@@ -0,0 +1,227 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+type resultsCacheMetrics struct {
+	hits          tally.Counter
+	partialHits   tally.Counter
+	misses        tally.Counter
+	uncacheable   tally.Counter
+	backendErrors tally.Counter
+}
+
+func newResultsCacheMetrics(scope tally.Scope) resultsCacheMetrics {
+	return resultsCacheMetrics{
+		hits:          scope.Counter("hits"),
+		partialHits:   scope.Counter("partial-hits"),
+		misses:        scope.Counter("misses"),
+		uncacheable:   scope.Counter("uncacheable"),
+		backendErrors: scope.Counter("backend-errors"),
+	}
+}
+
+// ResultsCache caches range query results in step aligned extents, so that
+// repeated queries only fetch the parts of their range not cached yet.
+type ResultsCache struct {
+	opts    Options
+	backend Backend
+	logger  *zap.Logger
+	metrics resultsCacheMetrics
+}
+
+// NewResultsCache creates a new results cache.
+func NewResultsCache(opts Options) (*ResultsCache, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	iOpts := opts.InstrumentOptions()
+	return &ResultsCache{
+		opts:    opts,
+		backend: opts.Backend(),
+		logger:  iOpts.ZapLogger(),
+		metrics: newResultsCacheMetrics(iOpts.MetricsScope().SubScope("results-cache")),
+	}, nil
+}
+
+// Key returns the cache key of the query for the tenant. Results are only
+// shared between queries with the same expression and step.
+func Key(tenant string, params models.RequestParams) string {
+	return fmt.Sprintf("%s:%d:%s", tenant, params.Step, params.Query)
+}
+
+// Read returns the results of the query described by params, aligned to its
+// step, fetching only the parts of its range which are not cached. It also
+// returns the aligned params, which describe the returned results.
+func (c *ResultsCache) Read(
+	ctx context.Context,
+	key string,
+	params models.RequestParams,
+	fetch FetchFn,
+) ([]*ts.Series, models.RequestParams, error) {
+	step := params.Step
+	if step <= 0 || !params.IncludeEnd {
+		c.metrics.uncacheable.Inc(1)
+		series, err := fetch(ctx, params)
+		return series, params, err
+	}
+
+	params.Start = alignTime(params.Start, step)
+	params.End = alignTime(params.End, step)
+	if params.End.Before(params.Start) {
+		params.End = params.Start
+	}
+
+	var (
+		requested = timeRange{start: params.Start, end: params.End}
+		cacheEnd  = alignTime(c.opts.NowFn()().Add(-c.opts.MaxFreshness()), step)
+	)
+
+	if cacheEnd.Before(params.Start) {
+		// The whole range is too fresh to be cached.
+		c.metrics.uncacheable.Inc(1)
+		series, err := fetch(ctx, params)
+		return series, params, err
+	}
+
+	// Steps up to the freshness window are kept cached, even if they are
+	// beyond the end of this query.
+	retainEnd := cacheEnd
+	if cacheEnd.After(params.End) {
+		cacheEnd = params.End
+	}
+
+	extents := c.load(key)
+	cacheable := timeRange{start: params.Start, end: cacheEnd}
+	missing := missingRanges(extents, cacheable, step)
+	switch {
+	case len(missing) == 0:
+		c.metrics.hits.Inc(1)
+	case len(missing) == 1 && missing[0].start.Equal(cacheable.start) &&
+		missing[0].end.Equal(cacheable.end):
+		c.metrics.misses.Inc(1)
+	default:
+		c.metrics.partialHits.Inc(1)
+	}
+
+	for _, r := range missing {
+		ext, err := c.fetchExtent(ctx, params, r, fetch)
+		if err != nil {
+			return nil, params, err
+		}
+
+		extents = append(extents, ext)
+	}
+
+	extents = mergeExtents(extents, step)
+	if len(missing) > 0 {
+		// Only keep the requested range and the steps within the max age, so
+		// that older steps of sliding ranges are not kept forever.
+		retained := timeRange{
+			start: alignTime(c.opts.NowFn()().Add(-c.opts.MaxAge()), step),
+			end:   retainEnd,
+		}
+		if params.Start.Before(retained.start) {
+			retained.start = params.Start
+		}
+
+		c.store(key, trimExtents(extents, retained, step))
+	}
+
+	// Restrict the cached extents to the requested range.
+	result := trimExtents(extents, cacheable, step)
+
+	if cacheEnd.Before(params.End) {
+		fresh := timeRange{start: cacheEnd.Add(step), end: params.End}
+		ext, err := c.fetchExtent(ctx, params, fresh, fetch)
+		if err != nil {
+			return nil, params, err
+		}
+
+		result = append(result, ext)
+	}
+
+	merged := combine(requested, step, result...)
+	return merged.toSeries(step, c.opts.TagOptions()), params, nil
+}
+
+func (c *ResultsCache) fetchExtent(
+	ctx context.Context,
+	params models.RequestParams,
+	r timeRange,
+	fetch FetchFn,
+) (extent, error) {
+	params.Start = r.start
+	params.End = r.end
+	series, err := fetch(ctx, params)
+	if err != nil {
+		return extent{}, err
+	}
+
+	return newExtent(r, params.Step, series), nil
+}
+
+// load returns the cached extents for the key. Backend failures are not
+// fatal since the results can always be fetched instead.
+func (c *ResultsCache) load(key string) []extent {
+	data, ok, err := c.backend.Get(key)
+	if err != nil {
+		c.metrics.backendErrors.Inc(1)
+		c.logger.Warn("unable to read cached results", zap.Error(err))
+		return nil
+	}
+
+	if !ok {
+		return nil
+	}
+
+	extents, err := decodeExtents(data)
+	if err != nil {
+		c.metrics.backendErrors.Inc(1)
+		c.logger.Warn("unable to decode cached results", zap.Error(err))
+		return nil
+	}
+
+	return extents
+}
+
+func (c *ResultsCache) store(key string, extents []extent) {
+	data, err := encodeExtents(extents)
+	if err == nil {
+		err = c.backend.Set(key, data)
+	}
+
+	if err != nil {
+		c.metrics.backendErrors.Inc(1)
+		c.logger.Warn("unable to cache results", zap.Error(err))
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingFetch returns a series whose value at each step is its unix time,
+// recording the range of each fetch.
+type recordingFetch struct {
+	ranges []timeRange
+}
+
+func (f *recordingFetch) fetch(
+	_ context.Context,
+	params models.RequestParams,
+) ([]*ts.Series, error) {
+	f.ranges = append(f.ranges, timeRange{start: params.Start, end: params.End})
+	n := numSteps(params.Start, params.End, params.Step)
+	values := ts.NewFixedStepValues(params.Step, n, math.NaN(), params.Start)
+	for i := 0; i < n; i++ {
+		values.SetValueAt(i, float64(params.Start.Add(time.Duration(i)*params.Step).Unix()))
+	}
+
+	tags := models.NewTags(1, models.NewTagOptions()).SetName([]byte("foo"))
+	return []*ts.Series{ts.NewSeries([]byte("foo"), values, tags)}, nil
+}
+
+func newTestCache(t *testing.T, now time.Time) *ResultsCache {
+	backend, err := NewLRUBackend(BackendLimits{MaxEntries: 10})
+	require.NoError(t, err)
+
+	c, err := NewResultsCache(NewOptions().
+		SetBackend(backend).
+		SetMaxFreshness(10 * time.Minute).
+		SetNowFn(func() time.Time { return now }))
+	require.NoError(t, err)
+	return c
+}
+
+func requireTimestampValues(
+	t *testing.T,
+	series []*ts.Series,
+	start, end time.Time,
+	step time.Duration,
+) {
+	require.Len(t, series, 1)
+	name, ok := series[0].Tags.Name()
+	require.True(t, ok)
+	assert.Equal(t, "foo", string(name))
+
+	vals := series[0].Values()
+	require.Equal(t, numSteps(start, end, step), vals.Len())
+	for i := 0; i < vals.Len(); i++ {
+		dp := vals.DatapointAt(i)
+		assert.True(t, start.Add(time.Duration(i)*step).Equal(dp.Timestamp))
+		assert.Equal(t, float64(dp.Timestamp.Unix()), dp.Value)
+	}
+}
+
+func requireRanges(t *testing.T, expected, actual []timeRange) {
+	require.Equal(t, len(expected), len(actual))
+	for i, r := range expected {
+		assert.True(t, r.start.Equal(actual[i].start), "range %d start", i)
+		assert.True(t, r.end.Equal(actual[i].end), "range %d end", i)
+	}
+}
+
+func TestResultsCacheReusesExtents(t *testing.T) {
+	var (
+		step  = time.Minute
+		now   = time.Unix(1543449600, 0)
+		cache = newTestCache(t, now)
+		fetch = &recordingFetch{}
+		ctx   = context.Background()
+	)
+
+	params := models.RequestParams{
+		Query:      "foo",
+		Start:      now.Add(-time.Hour).Add(10 * time.Second),
+		End:        now.Add(-30 * time.Minute),
+		Step:       step,
+		IncludeEnd: true,
+	}
+
+	key := Key("tenant", params)
+	series, aligned, err := cache.Read(ctx, key, params, fetch.fetch)
+	require.NoError(t, err)
+	assert.True(t, now.Add(-time.Hour).Equal(aligned.Start))
+	requireTimestampValues(t, series, aligned.Start, aligned.End, step)
+	require.Len(t, fetch.ranges, 1)
+
+	// The same range is served from the cache.
+	fetch.ranges = nil
+	series, _, err = cache.Read(ctx, key, params, fetch.fetch)
+	require.NoError(t, err)
+	requireTimestampValues(t, series, aligned.Start, aligned.End, step)
+	assert.Empty(t, fetch.ranges)
+
+	// Extending the range only fetches the new steps, and the steps within
+	// the freshness window are fetched without being cached.
+	params.End = now
+	fetch.ranges = nil
+	series, aligned, err = cache.Read(ctx, key, params, fetch.fetch)
+	require.NoError(t, err)
+	requireTimestampValues(t, series, aligned.Start, now, step)
+	requireRanges(t, []timeRange{
+		{start: now.Add(-29 * time.Minute), end: now.Add(-10 * time.Minute)},
+		{start: now.Add(-9 * time.Minute), end: now},
+	}, fetch.ranges)
+
+	// Only the fresh steps are fetched again.
+	fetch.ranges = nil
+	_, _, err = cache.Read(ctx, key, params, fetch.fetch)
+	require.NoError(t, err)
+	requireRanges(t, []timeRange{
+		{start: now.Add(-9 * time.Minute), end: now},
+	}, fetch.ranges)
+
+	// Other tenants do not share the results.
+	fetch.ranges = nil
+	_, _, err = cache.Read(ctx, Key("other", params), params, fetch.fetch)
+	require.NoError(t, err)
+	assert.Len(t, fetch.ranges, 2)
+}
+
+func TestResultsCacheFillsGaps(t *testing.T) {
+	var (
+		step  = time.Minute
+		now   = time.Unix(1543449600, 0)
+		cache = newTestCache(t, now)
+		fetch = &recordingFetch{}
+		ctx   = context.Background()
+		start = now.Add(-2 * time.Hour)
+	)
+
+	params := models.RequestParams{
+		Query:      "foo",
+		Step:       step,
+		IncludeEnd: true,
+	}
+	key := Key("", params)
+
+	for _, r := range []timeRange{
+		{start: start, end: start.Add(10 * time.Minute)},
+		{start: start.Add(20 * time.Minute), end: start.Add(30 * time.Minute)},
+	} {
+		params.Start, params.End = r.start, r.end
+		_, _, err := cache.Read(ctx, key, params, fetch.fetch)
+		require.NoError(t, err)
+	}
+
+	fetch.ranges = nil
+	params.Start, params.End = start, start.Add(40*time.Minute)
+	series, _, err := cache.Read(ctx, key, params, fetch.fetch)
+	require.NoError(t, err)
+	requireTimestampValues(t, series, params.Start, params.End, step)
+	requireRanges(t, []timeRange{
+		{start: start.Add(11 * time.Minute), end: start.Add(19 * time.Minute)},
+		{start: start.Add(31 * time.Minute), end: start.Add(40 * time.Minute)},
+	}, fetch.ranges)
+}
+
+func TestResultsCacheTrimsExtents(t *testing.T) {
+	var (
+		step  = time.Minute
+		now   = time.Unix(1543449600, 0)
+		cache = newTestCache(t, now)
+		fetch = &recordingFetch{}
+		ctx   = context.Background()
+	)
+
+	params := models.RequestParams{
+		Query:      "foo",
+		Start:      now.Add(-30 * time.Hour),
+		End:        now.Add(-29 * time.Hour),
+		Step:       step,
+		IncludeEnd: true,
+	}
+	key := Key("", params)
+
+	_, _, err := cache.Read(ctx, key, params, fetch.fetch)
+	require.NoError(t, err)
+	require.Len(t, cache.load(key), 1)
+
+	// Steps outside of both the requested range and the max age are no
+	// longer kept once the entry is extended.
+	params.Start, params.End = now.Add(-30*time.Minute), now.Add(-20*time.Minute)
+	_, _, err = cache.Read(ctx, key, params, fetch.fetch)
+	require.NoError(t, err)
+
+	extents := cache.load(key)
+	require.Len(t, extents, 1)
+	assert.True(t, params.Start.Equal(extents[0].Start))
+	assert.True(t, params.End.Equal(extents[0].End))
+}
+
+func TestResultsCacheSkipsFreshQueries(t *testing.T) {
+	var (
+		now   = time.Unix(1543449600, 0)
+		cache = newTestCache(t, now)
+		fetch = &recordingFetch{}
+	)
+
+	params := models.RequestParams{
+		Query:      "foo",
+		Start:      now.Add(-5 * time.Minute),
+		End:        now,
+		Step:       time.Minute,
+		IncludeEnd: true,
+	}
+
+	for i := 0; i < 2; i++ {
+		_, _, err := cache.Read(context.Background(), Key("", params), params, fetch.fetch)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, fetch.ranges, 2)
+}
+
+func TestBackends(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	disk, err := NewDiskBackend(dir, BackendLimits{MaxBytes: 1 << 20})
+	require.NoError(t, err)
+	lru, err := NewLRUBackend(BackendLimits{MaxEntries: 1})
+	require.NoError(t, err)
+
+	for _, backend := range []Backend{disk, lru} {
+		_, ok, err := backend.Get("foo")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		require.NoError(t, backend.Set("foo", []byte("bar")))
+		value, ok, err := backend.Get("foo")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, "bar", string(value))
+	}
+
+	// The LRU evicts beyond its size.
+	require.NoError(t, lru.Set("baz", []byte("qux")))
+	_, ok, err := lru.Get("foo")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = NewLRUBackend(BackendLimits{})
+	assert.Error(t, err)
+	_, err = NewDiskBackend(dir, BackendLimits{MaxEntries: -1})
+	assert.Error(t, err)
+}
+
+func TestBackendsLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		now    = time.Unix(1543449600, 0)
+		limits = BackendLimits{MaxBytes: 10, TTL: time.Hour}
+	)
+
+	disk, err := NewDiskBackend(dir, limits)
+	require.NoError(t, err)
+	disk.(*diskBackend).index.nowFn = func() time.Time { return now }
+
+	// Memory entries are sized by their key and value.
+	lru, err := NewLRUBackend(BackendLimits{MaxBytes: 16, TTL: time.Hour})
+	require.NoError(t, err)
+	lru.(*lruBackend).index.nowFn = func() time.Time { return now }
+
+	for _, backend := range []Backend{disk, lru} {
+		require.NoError(t, backend.Set("a", []byte("12345")))
+		require.NoError(t, backend.Set("b", []byte("12345")))
+
+		// Reading an entry makes it the most recently used.
+		_, ok, err := backend.Get("a")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// The least recently used entries are evicted beyond the max bytes.
+		require.NoError(t, backend.Set("c", []byte("12345")))
+		_, ok, err = backend.Get("b")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		_, ok, err = backend.Get("a")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	// Entries evicted from disk are removed.
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// Entries on disk are kept across restarts, without temporary files.
+	tmp, err := ioutil.TempFile(dir, diskTempPrefix)
+	require.NoError(t, err)
+	require.NoError(t, tmp.Close())
+
+	disk, err = NewDiskBackend(dir, limits)
+	require.NoError(t, err)
+	_, ok, err := disk.Get("c")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// Entries expire after their TTL.
+	now = now.Add(time.Hour)
+	_, ok, err = lru.Get("c")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Entries read from disk were written when their file was last modified.
+	disk.(*diskBackend).index.nowFn = func() time.Time { return time.Now().Add(time.Hour) }
+	_, ok, err = disk.Get("c")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestExtentsRoundTrip(t *testing.T) {
+	extents := []extent{{
+		Start: time.Unix(60, 0),
+		End:   time.Unix(120, 0),
+		Series: []extentSeries{{
+			Name:   []byte("foo"),
+			Tags:   []models.Tag{{Name: []byte("a"), Value: []byte("b")}},
+			Values: []float64{1, math.NaN()},
+		}},
+	}}
+
+	data, err := encodeExtents(extents)
+	require.NoError(t, err)
+	decoded, err := decodeExtents(data)
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	assert.True(t, extents[0].Start.Equal(decoded[0].Start))
+	assert.Equal(t, extents[0].Series[0].Tags, decoded[0].Series[0].Tags)
+	assert.Equal(t, 1.0, decoded[0].Series[0].Values[0])
+	assert.True(t, math.IsNaN(decoded[0].Series[0].Values[1]))
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/m3db/m3x/instrument"
+)
+
+// BackendType is a type of results cache backend.
+type BackendType string
+
+const (
+	// MemoryBackendType caches results in an in-memory LRU.
+	MemoryBackendType BackendType = "memory"
+
+	// DiskBackendType caches results in files on local disk.
+	DiskBackendType BackendType = "disk"
+
+	defaultMemorySize     = 1024
+	defaultMemoryMaxBytes = 256 << 20
+	defaultDiskMaxBytes   = 1 << 30
+	defaultTTL            = 24 * time.Hour
+)
+
+// Configuration is the configuration for the query results cache.
+type Configuration struct {
+	// Backend is the backend to cache results in, defaults to memory.
+	Backend BackendType `yaml:"backend"`
+
+	// Size is the maximum number of queries cached by the memory backend.
+	Size int `yaml:"size"`
+
+	// MaxBytes is the maximum size of the cached results, defaults to 256MiB
+	// for the memory backend and 1GiB for the disk backend.
+	MaxBytes int64 `yaml:"maxBytes"`
+
+	// TTL is how long the results of a query are cached for after they were
+	// last extended, defaults to 24 hours.
+	TTL *time.Duration `yaml:"ttl"`
+
+	// Directory is the directory the disk backend caches results in.
+	Directory string `yaml:"directory"`
+
+	// MaxFreshness is the window before now of results which are not
+	// cached, defaults to 10 minutes.
+	MaxFreshness *time.Duration `yaml:"maxFreshness"`
+
+	// MaxAge is the window before now of results which are kept cached
+	// beyond the range of the last query, defaults to 24 hours.
+	MaxAge *time.Duration `yaml:"maxAge"`
+}
+
+// NewResultsCache creates a results cache from the configuration.
+func (c Configuration) NewResultsCache(
+	tagOptions models.TagOptions,
+	instrumentOpts instrument.Options,
+) (*ResultsCache, error) {
+	var (
+		backend Backend
+		err     error
+		limits  = BackendLimits{MaxBytes: c.MaxBytes, TTL: defaultTTL}
+	)
+
+	if c.TTL != nil {
+		limits.TTL = *c.TTL
+	}
+
+	switch c.Backend {
+	case "", MemoryBackendType:
+		limits.MaxEntries = c.Size
+		if limits.MaxEntries == 0 {
+			limits.MaxEntries = defaultMemorySize
+		}
+
+		if limits.MaxBytes == 0 {
+			limits.MaxBytes = defaultMemoryMaxBytes
+		}
+
+		backend, err = NewLRUBackend(limits)
+	case DiskBackendType:
+		if c.Directory == "" {
+			return nil, fmt.Errorf("directory is required for %s results cache", c.Backend)
+		}
+
+		if limits.MaxBytes == 0 {
+			limits.MaxBytes = defaultDiskMaxBytes
+		}
+
+		backend, err = NewDiskBackend(c.Directory, limits)
+	default:
+		return nil, fmt.Errorf("unknown results cache backend: %s", c.Backend)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts := NewOptions().
+		SetBackend(backend).
+		SetTagOptions(tagOptions).
+		SetInstrumentOptions(instrumentOpts)
+	if c.MaxFreshness != nil {
+		opts = opts.SetMaxFreshness(*c.MaxFreshness)
+	}
+
+	if c.MaxAge != nil {
+		opts = opts.SetMaxAge(*c.MaxAge)
+	}
+
+	return NewResultsCache(opts)
+}
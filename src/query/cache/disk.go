@@ -0,0 +1,155 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const diskTempPrefix = ".tmp-"
+
+// diskBackend is a backend storing each entry as a file in a local directory,
+// evicting the least recently used entries beyond its limits.
+type diskBackend struct {
+	sync.Mutex
+	dir   string
+	index *lruIndex
+}
+
+// NewDiskBackend creates a backend storing entries within the limits under
+// the directory, creating it if it does not exist. Entries already in the
+// directory are kept, ordered by when they were written.
+func NewDiskBackend(dir string, limits BackendLimits) (Backend, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	b := &diskBackend{dir: dir}
+	b.index = newLRUIndex(limits, b.evict)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		// Temporary files are left behind by writes which did not complete.
+		if strings.HasPrefix(f.Name(), diskTempPrefix) {
+			os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+
+		b.index.set(&lruEntry{
+			key:     f.Name(),
+			size:    f.Size(),
+			written: f.ModTime(),
+		})
+	}
+
+	return b, nil
+}
+
+// name returns the file name of the key, hashed since keys contain queries.
+func (b *diskBackend) name(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (b *diskBackend) evict(entry *lruEntry) {
+	os.Remove(filepath.Join(b.dir, entry.key))
+}
+
+func (b *diskBackend) Get(key string) ([]byte, bool, error) {
+	name := b.name(key)
+	b.Lock()
+	_, ok := b.index.get(name)
+	b.Unlock()
+	if !ok {
+		return nil, false, nil
+	}
+
+	// The entry may be evicted concurrently, in which case it is a miss.
+	value, err := ioutil.ReadFile(filepath.Join(b.dir, name))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (b *diskBackend) Set(key string, value []byte) error {
+	// Write to a temporary file first so that readers never see a partially
+	// written entry.
+	f, err := ioutil.TempFile(b.dir, diskTempPrefix)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	name := b.name(key)
+	b.Lock()
+	defer b.Unlock()
+
+	if err := os.Rename(f.Name(), filepath.Join(b.dir, name)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	b.index.set(&lruEntry{
+		key:     name,
+		size:    int64(len(value)),
+		written: b.index.nowFn(),
+	})
+	return nil
+}
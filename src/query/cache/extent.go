@@ -0,0 +1,285 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+)
+
+// extent holds the results of a query between its start and end steps,
+// both inclusive.
+type extent struct {
+	Start  time.Time
+	End    time.Time
+	Series []extentSeries
+}
+
+type extentSeries struct {
+	Name   []byte
+	Tags   []models.Tag
+	Values []float64
+}
+
+// timeRange is a range of steps, both inclusive.
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// alignTime aligns the time down to a multiple of the step since the epoch.
+func alignTime(t time.Time, step time.Duration) time.Time {
+	nanos := t.UnixNano()
+	return time.Unix(0, nanos-nanos%int64(step))
+}
+
+func numSteps(start, end time.Time, step time.Duration) int {
+	return int(end.Sub(start)/step) + 1
+}
+
+func seriesKey(tags []models.Tag) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		b.Write(tag.Name)
+		b.WriteByte(0)
+		b.Write(tag.Value)
+		b.WriteByte(0)
+	}
+
+	return b.String()
+}
+
+// newExtent places the datapoints of the series on the steps of the range.
+func newExtent(r timeRange, step time.Duration, series []*ts.Series) extent {
+	n := numSteps(r.start, r.end, step)
+	ext := extent{
+		Start:  r.start,
+		End:    r.end,
+		Series: make([]extentSeries, 0, len(series)),
+	}
+
+	for _, s := range series {
+		values := make([]float64, n)
+		ts.Memset(values, math.NaN())
+		vals := s.Values()
+		for i := 0; i < vals.Len(); i++ {
+			dp := vals.DatapointAt(i)
+			offset := dp.Timestamp.Sub(r.start)
+			if offset < 0 || offset%step != 0 {
+				continue
+			}
+
+			if idx := int(offset / step); idx < n {
+				values[idx] = dp.Value
+			}
+		}
+
+		ext.Series = append(ext.Series, extentSeries{
+			Name:   s.Name(),
+			Tags:   s.Tags.Tags,
+			Values: values,
+		})
+	}
+
+	return ext
+}
+
+// missingRanges returns the parts of the range not covered by the extents,
+// which must be sorted by start.
+func missingRanges(
+	extents []extent,
+	r timeRange,
+	step time.Duration,
+) []timeRange {
+	var (
+		missing []timeRange
+		cursor  = r.start
+	)
+
+	for _, ext := range extents {
+		if ext.End.Before(cursor) {
+			continue
+		}
+
+		if ext.Start.After(r.end) {
+			break
+		}
+
+		if ext.Start.After(cursor) {
+			missing = append(missing, timeRange{
+				start: cursor,
+				end:   ext.Start.Add(-step),
+			})
+		}
+
+		cursor = ext.End.Add(step)
+	}
+
+	if !cursor.After(r.end) {
+		missing = append(missing, timeRange{start: cursor, end: r.end})
+	}
+
+	return missing
+}
+
+// mergeExtents sorts the extents and combines those which overlap or are
+// adjacent, preferring the values of later extents.
+func mergeExtents(extents []extent, step time.Duration) []extent {
+	if len(extents) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(extents, func(i, j int) bool {
+		return extents[i].Start.Before(extents[j].Start)
+	})
+
+	merged := []extent{extents[0]}
+	for _, ext := range extents[1:] {
+		last := &merged[len(merged)-1]
+		if ext.Start.After(last.End.Add(step)) {
+			merged = append(merged, ext)
+			continue
+		}
+
+		end := last.End
+		if ext.End.After(end) {
+			end = ext.End
+		}
+
+		*last = combine(timeRange{start: last.Start, end: end}, step, *last, ext)
+	}
+
+	return merged
+}
+
+// combine builds an extent over the range from the values of the extents,
+// with later extents taking precedence.
+func combine(r timeRange, step time.Duration, extents ...extent) extent {
+	var (
+		n      = numSteps(r.start, r.end, step)
+		result = extent{Start: r.start, End: r.end}
+		byKey  = make(map[string]int)
+	)
+
+	for _, ext := range extents {
+		offset := int(ext.Start.Sub(r.start) / step)
+		for _, s := range ext.Series {
+			key := seriesKey(s.Tags)
+			idx, ok := byKey[key]
+			if !ok {
+				values := make([]float64, n)
+				ts.Memset(values, math.NaN())
+				idx = len(result.Series)
+				byKey[key] = idx
+				result.Series = append(result.Series, extentSeries{
+					Name:   s.Name,
+					Tags:   s.Tags,
+					Values: values,
+				})
+			}
+
+			values := result.Series[idx].Values
+			for i, v := range s.Values {
+				j := offset + i
+				if j < 0 || j >= n {
+					continue
+				}
+
+				values[j] = v
+			}
+		}
+	}
+
+	return result
+}
+
+// trimExtents restricts the extents to the range, dropping those which do
+// not overlap it.
+func trimExtents(extents []extent, r timeRange, step time.Duration) []extent {
+	var result []extent
+	for _, ext := range extents {
+		if ext.End.Before(r.start) || ext.Start.After(r.end) {
+			continue
+		}
+
+		result = append(result, trimExtent(ext, r, step))
+	}
+
+	return result
+}
+
+// trimExtent restricts the extent to the range, which it must overlap.
+func trimExtent(ext extent, r timeRange, step time.Duration) extent {
+	if ext.Start.Before(r.start) || ext.End.After(r.end) {
+		start, end := ext.Start, ext.End
+		if start.Before(r.start) {
+			start = r.start
+		}
+
+		if end.After(r.end) {
+			end = r.end
+		}
+
+		return combine(timeRange{start: start, end: end}, step, ext)
+	}
+
+	return ext
+}
+
+func (e extent) toSeries(step time.Duration, tagOpts models.TagOptions) []*ts.Series {
+	series := make([]*ts.Series, 0, len(e.Series))
+	for _, s := range e.Series {
+		values := ts.NewFixedStepValues(step, len(s.Values), math.NaN(), e.Start)
+		for i, v := range s.Values {
+			values.SetValueAt(i, v)
+		}
+
+		tags := models.NewTags(len(s.Tags), tagOpts).AddTags(s.Tags)
+		series = append(series, ts.NewSeries(s.Name, values, tags))
+	}
+
+	return series
+}
+
+func encodeExtents(extents []extent) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(extents); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeExtents(data []byte) ([]extent, error) {
+	var extents []extent
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&extents); err != nil {
+		return nil, err
+	}
+
+	return extents, nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3x/clock"
+)
+
+var errUnboundedBackend = errors.New("must bound the number or size of entries")
+
+// BackendLimits bound the entries held by a backend.
+type BackendLimits struct {
+	// MaxEntries is the maximum number of entries, unbounded if zero.
+	MaxEntries int
+
+	// MaxBytes is the maximum total size of the entries, unbounded if zero.
+	MaxBytes int64
+
+	// TTL is how long entries are kept for after being set, forever if zero.
+	TTL time.Duration
+}
+
+// Validate validates the limits, which must bound either the number or the
+// size of the entries.
+func (l BackendLimits) Validate() error {
+	if l.MaxEntries < 0 {
+		return fmt.Errorf("must provide a non-negative max entries, instead got: %d", l.MaxEntries)
+	}
+
+	if l.MaxBytes < 0 {
+		return fmt.Errorf("must provide a non-negative max bytes, instead got: %d", l.MaxBytes)
+	}
+
+	if l.TTL < 0 {
+		return fmt.Errorf("must provide a non-negative ttl, instead got: %v", l.TTL)
+	}
+
+	if l.MaxEntries == 0 && l.MaxBytes == 0 {
+		return errUnboundedBackend
+	}
+
+	return nil
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	size    int64
+	written time.Time
+}
+
+// lruIndex orders the entries of a backend by use, evicting the least
+// recently used entries beyond its limits and expiring entries older than
+// its TTL when they are next read.
+type lruIndex struct {
+	limits    BackendLimits
+	nowFn     clock.NowFn
+	onEvict   func(entry *lruEntry)
+	bytes     int64
+	evictList *list.List
+	items     map[string]*list.Element
+}
+
+func newLRUIndex(limits BackendLimits, onEvict func(entry *lruEntry)) *lruIndex {
+	return &lruIndex{
+		limits:    limits,
+		nowFn:     time.Now,
+		onEvict:   onEvict,
+		evictList: list.New(),
+		items:     make(map[string]*list.Element),
+	}
+}
+
+func (i *lruIndex) get(key string) (*lruEntry, bool) {
+	elem, ok := i.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if i.limits.TTL > 0 && i.nowFn().Sub(entry.written) >= i.limits.TTL {
+		i.remove(elem)
+		return nil, false
+	}
+
+	i.evictList.MoveToFront(elem)
+	return entry, true
+}
+
+// set adds the entry as the most recently used, replacing any entry with
+// the same key, then evicts entries until the index is within its limits.
+func (i *lruIndex) set(entry *lruEntry) {
+	if elem, ok := i.items[entry.key]; ok {
+		i.bytes -= elem.Value.(*lruEntry).size
+		elem.Value = entry
+		i.evictList.MoveToFront(elem)
+	} else {
+		i.items[entry.key] = i.evictList.PushFront(entry)
+	}
+
+	i.bytes += entry.size
+	for i.evictList.Len() > 0 && i.exceeded() {
+		i.remove(i.evictList.Back())
+	}
+}
+
+func (i *lruIndex) exceeded() bool {
+	return (i.limits.MaxEntries > 0 && i.evictList.Len() > i.limits.MaxEntries) ||
+		(i.limits.MaxBytes > 0 && i.bytes > i.limits.MaxBytes)
+}
+
+func (i *lruIndex) remove(elem *list.Element) {
+	entry := i.evictList.Remove(elem).(*lruEntry)
+	delete(i.items, entry.key)
+	i.bytes -= entry.size
+	if i.onEvict != nil {
+		i.onEvict(entry)
+	}
+}
+
+// lruBackend is an in-memory backend which evicts the least recently used
+// entries beyond its limits.
+type lruBackend struct {
+	sync.Mutex
+	index *lruIndex
+}
+
+// NewLRUBackend creates an in-memory backend holding entries within the
+// limits.
+func NewLRUBackend(limits BackendLimits) (Backend, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &lruBackend{index: newLRUIndex(limits, nil)}, nil
+}
+
+func (b *lruBackend) Get(key string) ([]byte, bool, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	entry, ok := b.index.get(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+func (b *lruBackend) Set(key string, value []byte) error {
+	b.Lock()
+	defer b.Unlock()
+
+	b.index.set(&lruEntry{
+		key:     key,
+		value:   value,
+		size:    int64(len(key) + len(value)),
+		written: b.index.nowFn(),
+	})
+	return nil
+}
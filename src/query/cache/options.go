@@ -0,0 +1,148 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/instrument"
+)
+
+const (
+	// defaultMaxFreshness is the default window before now of results which
+	// are not cached.
+	defaultMaxFreshness = 10 * time.Minute
+
+	// defaultMaxAge is the default window before now of results which are
+	// kept cached beyond the range of the last query.
+	defaultMaxAge = 24 * time.Hour
+)
+
+var (
+	errNoBackend         = errors.New("results cache backend is not set")
+	errNegativeFreshness = errors.New("results cache max freshness must not be negative")
+	errNegativeMaxAge    = errors.New("results cache max age must not be negative")
+	errNoTagOptions      = errors.New("results cache tag options are not set")
+)
+
+type options struct {
+	backend        Backend
+	maxFreshness   time.Duration
+	maxAge         time.Duration
+	tagOptions     models.TagOptions
+	nowFn          clock.NowFn
+	instrumentOpts instrument.Options
+}
+
+// NewOptions creates new results cache options.
+func NewOptions() Options {
+	return &options{
+		maxFreshness:   defaultMaxFreshness,
+		maxAge:         defaultMaxAge,
+		tagOptions:     models.NewTagOptions(),
+		nowFn:          time.Now,
+		instrumentOpts: instrument.NewOptions(),
+	}
+}
+
+func (o *options) Validate() error {
+	if o.backend == nil {
+		return errNoBackend
+	}
+
+	if o.maxFreshness < 0 {
+		return errNegativeFreshness
+	}
+
+	if o.maxAge < 0 {
+		return errNegativeMaxAge
+	}
+
+	if o.tagOptions == nil {
+		return errNoTagOptions
+	}
+
+	return nil
+}
+
+func (o *options) SetBackend(value Backend) Options {
+	opts := *o
+	opts.backend = value
+	return &opts
+}
+
+func (o *options) Backend() Backend {
+	return o.backend
+}
+
+func (o *options) SetMaxFreshness(value time.Duration) Options {
+	opts := *o
+	opts.maxFreshness = value
+	return &opts
+}
+
+func (o *options) MaxFreshness() time.Duration {
+	return o.maxFreshness
+}
+
+func (o *options) SetMaxAge(value time.Duration) Options {
+	opts := *o
+	opts.maxAge = value
+	return &opts
+}
+
+func (o *options) MaxAge() time.Duration {
+	return o.maxAge
+}
+
+func (o *options) SetTagOptions(value models.TagOptions) Options {
+	opts := *o
+	opts.tagOptions = value
+	return &opts
+}
+
+func (o *options) TagOptions() models.TagOptions {
+	return o.tagOptions
+}
+
+func (o *options) SetNowFn(value clock.NowFn) Options {
+	opts := *o
+	opts.nowFn = value
+	return &opts
+}
+
+func (o *options) NowFn() clock.NowFn {
+	return o.nowFn
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
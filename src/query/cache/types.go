@@ -0,0 +1,91 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/instrument"
+)
+
+// Backend stores encoded cache entries by key.
+type Backend interface {
+	// Get returns the entry for the key and whether it was found.
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores the entry for the key, replacing any existing entry.
+	Set(key string, value []byte) error
+}
+
+// FetchFn executes the query described by params.
+type FetchFn func(ctx context.Context, params models.RequestParams) ([]*ts.Series, error)
+
+// Options are the options for a results cache.
+type Options interface {
+	// Validate validates the options.
+	Validate() error
+
+	// SetBackend sets the backend storing the cached results.
+	SetBackend(value Backend) Options
+
+	// Backend returns the backend storing the cached results.
+	Backend() Backend
+
+	// SetMaxFreshness sets the window before now of results which are not
+	// cached, since they may still change as late data arrives.
+	SetMaxFreshness(value time.Duration) Options
+
+	// MaxFreshness returns the window before now of results which are not
+	// cached.
+	MaxFreshness() time.Duration
+
+	// SetMaxAge sets the window before now of results which are kept cached
+	// beyond the range of the last query of each entry, so that entries of
+	// queries over a sliding range do not grow without bound.
+	SetMaxAge(value time.Duration) Options
+
+	// MaxAge returns the window before now of results which are kept cached
+	// beyond the range of the last query of each entry.
+	MaxAge() time.Duration
+
+	// SetTagOptions sets the tag options used to rebuild cached series tags.
+	SetTagOptions(value models.TagOptions) Options
+
+	// TagOptions returns the tag options used to rebuild cached series tags.
+	TagOptions() models.TagOptions
+
+	// SetNowFn sets the function returning the current time.
+	SetNowFn(value clock.NowFn) Options
+
+	// NowFn returns the function returning the current time.
+	NowFn() clock.NowFn
+
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+}
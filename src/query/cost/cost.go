@@ -32,6 +32,8 @@ const (
 	BlockLevel = "block"
 	// QueryLevel identifies per-query enforcers.
 	QueryLevel = "query"
+	// TenantLevel identifies per-tenant enforcers.
+	TenantLevel = "tenant"
 	// GlobalLevel identifies global enforcers.
 	GlobalLevel = "global"
 )
@@ -44,6 +46,12 @@ type ChainedEnforcer interface {
 	// Child creates a new ChainedEnforcer which rolls up to this one.
 	Child(resourceName string) ChainedEnforcer
 
+	// ChildWithEnforcer creates a new ChainedEnforcer which rolls up to this
+	// one and enforces the given enforcer, while its own children are created
+	// as if they were children of this one. This inserts a level into the
+	// tree, such as per tenant limits between the global and per query ones.
+	ChildWithEnforcer(resourceName string, local cost.Enforcer) ChainedEnforcer
+
 	// Close indicates that all resources have been returned for this
 	// ChainedEnforcer. It should inform all parent enforcers that the
 	// resources have been freed.
@@ -176,6 +184,22 @@ func (ce *chainedEnforcer) Child(resourceName string) ChainedEnforcer {
 	}
 }
 
+// ChildWithEnforcer creates a new chainedEnforcer enforcing local whose
+// resource consumption rolls up into this instance, and whose children are
+// created using the remaining models of this instance.
+func (ce *chainedEnforcer) ChildWithEnforcer(
+	resourceName string,
+	local cost.Enforcer,
+) ChainedEnforcer {
+	return &chainedEnforcer{
+		resourceName: resourceName,
+		parent:       ce,
+		local:        local.Clone(),
+		models:       ce.models,
+		reporter:     upcastReporterOrNoop(local.Reporter()),
+	}
+}
+
 // Clone on a chainedEnforcer is a noop--TODO: implement?
 func (ce *chainedEnforcer) Clone() cost.Enforcer {
 	return ce
@@ -60,6 +60,37 @@ func TestChainedEnforcer_Child(t *testing.T) {
 	})
 }
 
+func TestChainedEnforcer_ChildWithEnforcer(t *testing.T) {
+	globalEnforcer := newTestEnforcer(cost.Limit{Threshold: 10.0, Enabled: true})
+	queryEnforcer := newTestEnforcer(cost.Limit{Threshold: 5.0, Enabled: true})
+	tenantEnforcer := newTestEnforcer(cost.Limit{Threshold: 3.0, Enabled: true})
+
+	pef, err := NewChainedEnforcer(GlobalLevel, []cost.Enforcer{globalEnforcer, queryEnforcer})
+	require.NoError(t, err)
+
+	tenant := pef.ChildWithEnforcer(TenantLevel, tenantEnforcer)
+	query := tenant.Child(QueryLevel)
+
+	r := query.Add(2)
+	require.NoError(t, r.Error)
+	test.AssertCurrentCost(t, 2.0, query)
+	test.AssertCurrentCost(t, 2.0, tenant)
+	test.AssertCurrentCost(t, 2.0, globalEnforcer)
+
+	// The tenant limit applies before the query limit is reached.
+	r = query.Add(2)
+	if assert.Error(t, r.Error) {
+		assert.Regexp(t, "exceeded tenant limit", r.Error.Error())
+	}
+
+	query.Close()
+	test.AssertCurrentCost(t, 0.0, tenant)
+	test.AssertCurrentCost(t, 0.0, globalEnforcer)
+
+	// The tenant enforcer is cloned rather than shared.
+	test.AssertCurrentCost(t, 0.0, tenantEnforcer)
+}
+
 func TestChainedEnforcer_Close(t *testing.T) {
 	t.Run("removes local total from global", func(t *testing.T) {
 		parentIface, err := NewChainedEnforcer(
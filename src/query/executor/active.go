@@ -0,0 +1,144 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/models"
+)
+
+// ActiveQuery describes a query being executed by the engine.
+type ActiveQuery struct {
+	ID     string    `json:"id"`
+	Query  string    `json:"query"`
+	Start  time.Time `json:"start"`
+	Tenant string    `json:"tenant,omitempty"`
+	// Queued is set while the query waits to be admitted under the limits
+	// of its tenant.
+	Queued bool `json:"queued"`
+	// Cost is the current cost of the query, which is the number of
+	// datapoints it is holding onto.
+	Cost float64 `json:"cost"`
+}
+
+type activeQuery struct {
+	query    ActiveQuery
+	enforcer qcost.ChainedEnforcer
+	cancel   context.CancelFunc
+}
+
+// activeQueries tracks the queries being executed by the engine so that they
+// can be listed and cancelled.
+type activeQueries struct {
+	sync.RWMutex
+	nextID  uint64
+	queries map[string]*activeQuery
+}
+
+func newActiveQueries() *activeQueries {
+	return &activeQueries{queries: make(map[string]*activeQuery)}
+}
+
+// add tracks a query until the returned function is called, the query is
+// queued until it is admitted with its enforcer.
+func (a *activeQueries) add(
+	params models.RequestParams,
+	start time.Time,
+	cancel context.CancelFunc,
+) (string, func()) {
+	a.Lock()
+	a.nextID++
+	id := strconv.FormatUint(a.nextID, 10)
+	a.queries[id] = &activeQuery{
+		query: ActiveQuery{
+			ID:     id,
+			Query:  params.Query,
+			Start:  start,
+			Tenant: params.Tenant,
+			Queued: true,
+		},
+		cancel: cancel,
+	}
+	a.Unlock()
+
+	return id, func() {
+		a.Lock()
+		delete(a.queries, id)
+		a.Unlock()
+	}
+}
+
+// admit marks the query with the given ID as running, its cost being
+// tracked by the enforcer.
+func (a *activeQueries) admit(id string, enforcer qcost.ChainedEnforcer) {
+	a.Lock()
+	if q, ok := a.queries[id]; ok {
+		q.query.Queued = false
+		q.enforcer = enforcer
+	}
+	a.Unlock()
+}
+
+// list returns the active queries ordered by start time.
+func (a *activeQueries) list() []ActiveQuery {
+	a.RLock()
+	queries := make([]ActiveQuery, 0, len(a.queries))
+	for _, q := range a.queries {
+		query := q.query
+		if q.enforcer != nil {
+			report, _ := q.enforcer.State()
+			query.Cost = float64(report.Cost)
+		}
+
+		queries = append(queries, query)
+	}
+	a.RUnlock()
+
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Start.Equal(queries[j].Start) {
+			return queries[i].ID < queries[j].ID
+		}
+
+		return queries[i].Start.Before(queries[j].Start)
+	})
+
+	return queries
+}
+
+// cancel cancels the query with the given ID, returning false if there is
+// no such active query.
+func (a *activeQueries) cancel(id string) bool {
+	a.RLock()
+	q, ok := a.queries[id]
+	a.RUnlock()
+	if !ok {
+		return false
+	}
+
+	q.cancel()
+	return true
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveQueries(t *testing.T) {
+	active := newActiveQueries()
+	now := time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstID, removeFirst := active.add(
+		models.RequestParams{Query: "up", Tenant: "foo"}, now, cancel)
+	_, removeSecond := active.add(models.RequestParams{Query: "down"},
+		now.Add(-time.Minute), func() {})
+
+	queries := active.list()
+	require.Len(t, queries, 2)
+	assert.Equal(t, "down", queries[0].Query)
+	assert.Equal(t, "up", queries[1].Query)
+	assert.Equal(t, "foo", queries[1].Tenant)
+	assert.True(t, queries[1].Queued)
+
+	active.admit(firstID, nil)
+	queries = active.list()
+	require.Len(t, queries, 2)
+	assert.False(t, queries[1].Queued)
+
+	assert.False(t, active.cancel("unknown"))
+	assert.True(t, active.cancel(queries[1].ID))
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	removeFirst()
+	removeSecond()
+	assert.Empty(t, active.list())
+	assert.False(t, active.cancel(queries[1].ID))
+}
@@ -24,10 +24,14 @@ import (
 	"context"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/encoding"
 	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
+	"github.com/m3db/m3/src/query/tenant"
 	"github.com/m3db/m3/src/query/util/opentracing"
 
 	"github.com/uber-go/tally"
@@ -40,6 +44,8 @@ type Engine struct {
 	globalEnforcer   qcost.ChainedEnforcer
 	store            storage.Storage
 	lookbackDuration time.Duration
+	active           *activeQueries
+	tenants          *tenant.Limiter
 }
 
 // EngineOptions can be used to pass custom flags to engine
@@ -52,8 +58,15 @@ type Query struct {
 	Result Result
 }
 
-// NewEngine returns a new instance of QueryExecutor.
-func NewEngine(store storage.Storage, scope tally.Scope, lookbackDuration time.Duration, factory qcost.ChainedEnforcer) *Engine {
+// NewEngine returns a new instance of QueryExecutor. If tenants is set, queries
+// are admitted subject to the limits of their tenant.
+func NewEngine(
+	store storage.Storage,
+	scope tally.Scope,
+	lookbackDuration time.Duration,
+	factory qcost.ChainedEnforcer,
+	tenants *tenant.Limiter,
+) *Engine {
 	if factory == nil {
 		factory = qcost.NoopChainedEnforcer()
 	}
@@ -63,6 +76,8 @@ func NewEngine(store storage.Storage, scope tally.Scope, lookbackDuration time.D
 		store:            store,
 		lookbackDuration: lookbackDuration,
 		globalEnforcer:   factory,
+		active:           newActiveQueries(),
+		tenants:          tenants,
 	}
 }
 
@@ -131,6 +146,43 @@ func (e *Engine) Execute(
 	results <- &storage.QueryResult{FetchResult: result}
 }
 
+// ExecuteCompressed fetches the series matching the query without
+// decompressing them, so that they can be consumed one series at a time. It
+// returns errors.ErrNotImplemented if the storage is unable to.
+func (e *Engine) ExecuteCompressed(
+	ctx context.Context,
+	query *storage.FetchQuery,
+) (encoding.SeriesIterators, m3.Cleanup, error) {
+	fetcher, ok := e.store.(m3.CompressedFetcher)
+	if !ok {
+		return nil, nil, errors.ErrNotImplemented
+	}
+
+	fetchOpts := storage.NewFetchOptions()
+	fetchOpts.Limit = 0
+	return fetcher.FetchCompressed(ctx, query, fetchOpts)
+}
+
+// Admit admits a request under the limits of its tenant, returning a context
+// under which the queries of the request execute without being admitted again
+// and a function to call once the request completes. Requests executing
+// several queries over parts of their range are admitted once here so that
+// the limits apply to the whole request.
+func (e *Engine) Admit(
+	ctx context.Context,
+	params models.RequestParams,
+) (context.Context, func(), error) {
+	if e.tenants == nil || tenant.FromContext(ctx) != nil {
+		return ctx, func() {}, nil
+	}
+
+	admission, err := e.tenants.Admit(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tenant.NewContext(ctx, admission), admission.Close, nil
+}
+
 // ExecuteExpr runs the query DAG and closes the results channel once done
 // nolint: unparam
 func (e *Engine) ExecuteExpr(
@@ -142,8 +194,36 @@ func (e *Engine) ExecuteExpr(
 ) {
 	defer close(results)
 
-	perQueryEnforcer := e.globalEnforcer.Child(qcost.QueryLevel)
-	defer perQueryEnforcer.Close()
+	// Track the query until it completes, including while it waits to be
+	// admitted, so that it can be listed and cancelled.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	id, remove := e.active.add(params, time.Now(), cancel)
+	defer remove()
+
+	var perQueryEnforcer, seriesEnforcer qcost.ChainedEnforcer
+	if admission := tenant.FromContext(ctx); admission != nil {
+		// The request was admitted as a whole, see Admit.
+		perQueryEnforcer = admission.Enforcer
+		seriesEnforcer = admission.SeriesEnforcer
+	} else if e.tenants != nil {
+		// Wait for the query to be scheduled under the limits of its tenant.
+		admission, err := e.tenants.Admit(ctx, params)
+		if err != nil {
+			results <- Query{Err: err}
+			return
+		}
+
+		defer admission.Close()
+		perQueryEnforcer = admission.Enforcer
+		seriesEnforcer = admission.SeriesEnforcer
+	} else {
+		perQueryEnforcer = e.globalEnforcer.Child(qcost.QueryLevel)
+		defer perQueryEnforcer.Close()
+	}
+
+	e.active.admit(id, perQueryEnforcer)
+
 	req := newRequest(e, params)
 
 	nodes, edges, err := req.compile(ctx, parser)
@@ -171,13 +251,27 @@ func (e *Engine) ExecuteExpr(
 	result := state.resultNode
 	results <- Query{Result: result}
 
-	if err := state.Execute(models.NewQueryContext(ctx, e.costScope, perQueryEnforcer)); err != nil {
+	queryCtx := models.NewQueryContext(ctx, e.costScope, perQueryEnforcer)
+	queryCtx.SeriesEnforcer = seriesEnforcer
+	queryCtx.Stats = models.QueryStatsFromContext(ctx)
+	if err := state.Execute(queryCtx); err != nil {
 		result.abort(err)
 	} else {
 		result.done()
 	}
 }
 
+// ActiveQueries returns the queries being executed, ordered by start time.
+func (e *Engine) ActiveQueries() []ActiveQuery {
+	return e.active.list()
+}
+
+// CancelQuery cancels the active query with the given ID, returning false if
+// there is no such query.
+func (e *Engine) CancelQuery(id string) bool {
+	return e.active.cancel(id)
+}
+
 // Close kills all running queries and prevents new queries from being attached.
 func (e *Engine) Close() error {
 	return nil
@@ -31,6 +31,7 @@ import (
 	"github.com/m3db/m3/src/query/parser/promql"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/tenant"
 	"github.com/m3db/m3/src/query/test/m3"
 	"github.com/m3db/m3/src/query/util/logging"
 
@@ -49,7 +50,7 @@ func TestEngine_Execute(t *testing.T) {
 
 	// Results is closed by execute
 	results := make(chan *storage.QueryResult)
-	engine := NewEngine(store, tally.NewTestScope("test", nil), time.Minute, nil)
+	engine := NewEngine(store, tally.NewTestScope("test", nil), time.Minute, nil, nil)
 	go engine.Execute(context.TODO(), &storage.FetchQuery{}, &EngineOptions{}, results)
 	res := <-results
 	assert.NotNil(t, res.Err)
@@ -68,7 +69,7 @@ func TestEngine_ExecuteExpr(t *testing.T) {
 		require.NoError(t, err)
 
 		results := make(chan Query)
-		engine := NewEngine(mock.NewMockStorage(), tally.NewTestScope("", nil), defaultLookbackDuration, mockParent)
+		engine := NewEngine(mock.NewMockStorage(), tally.NewTestScope("", nil), defaultLookbackDuration, mockParent, nil)
 		go engine.ExecuteExpr(context.TODO(), parser, &EngineOptions{}, models.RequestParams{
 			Start: time.Now().Add(-2 * time.Second),
 			End:   time.Now(),
@@ -85,4 +86,84 @@ func TestEngine_ExecuteExpr(t *testing.T) {
 		res := resSl[0]
 		require.NoError(t, res.Err)
 	})
+
+	t.Run("admits queries through the tenant limiter", func(t *testing.T) {
+		limiter, err := tenant.NewLimiter(tenant.Options{
+			Limits: tenant.Limits{MaxQueryRange: time.Second},
+		})
+		require.NoError(t, err)
+
+		parser, err := promql.Parse("foo", models.NewTagOptions())
+		require.NoError(t, err)
+
+		results := make(chan Query)
+		engine := NewEngine(mock.NewMockStorage(), tally.NewTestScope("", nil),
+			defaultLookbackDuration, nil, limiter)
+		go engine.ExecuteExpr(context.TODO(), parser, &EngineOptions{}, models.RequestParams{
+			Start:  time.Now().Add(-2 * time.Second),
+			End:    time.Now(),
+			Step:   time.Second,
+			Tenant: "foo",
+		}, results)
+
+		var resSl []Query
+		for r := range results {
+			resSl = append(resSl, r)
+		}
+		require.Len(t, resSl, 1)
+		assert.Error(t, resSl[0].Err)
+		assert.Empty(t, engine.ActiveQueries())
+	})
+
+	t.Run("executes queries under the admission of their request", func(t *testing.T) {
+		limiter, err := tenant.NewLimiter(tenant.Options{
+			Limits: tenant.Limits{
+				MaxConcurrentQueries: 1,
+				MaxQueryRange:        time.Minute,
+			},
+		})
+		require.NoError(t, err)
+
+		parser, err := promql.Parse("foo", models.NewTagOptions())
+		require.NoError(t, err)
+
+		engine := NewEngine(mock.NewMockStorage(), tally.NewTestScope("", nil),
+			defaultLookbackDuration, nil, limiter)
+
+		// The range limit applies to the whole request.
+		now := time.Now()
+		_, _, err = engine.Admit(context.TODO(), models.RequestParams{
+			Start:  now.Add(-time.Hour),
+			End:    now,
+			Tenant: "foo",
+		})
+		require.Error(t, err)
+
+		ctx, done, err := engine.Admit(context.TODO(), models.RequestParams{
+			Start:  now.Add(-time.Minute),
+			End:    now,
+			Tenant: "foo",
+		})
+		require.NoError(t, err)
+		defer done()
+
+		// Queries of the request share its concurrency slot rather than
+		// waiting for one of their own.
+		for i := 0; i < 2; i++ {
+			results := make(chan Query)
+			go engine.ExecuteExpr(ctx, parser, &EngineOptions{}, models.RequestParams{
+				Start:  now.Add(-2 * time.Second),
+				End:    now,
+				Step:   time.Second,
+				Tenant: "foo",
+			}, results)
+
+			var resSl []Query
+			for r := range results {
+				resSl = append(resSl, r)
+			}
+			require.Len(t, resSl, 1)
+			require.NoError(t, resSl[0].Err)
+		}
+	})
 }
@@ -23,6 +23,7 @@ package executor
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/m3db/m3/src/query/executor/transform"
 	"github.com/m3db/m3/src/query/models"
@@ -102,11 +103,42 @@ type ScalarParams interface {
 	) parser.Source
 }
 
+// SubqueryParams are defined by subqueries, whose inner expression is
+// evaluated as a nested query and used as a source
+type SubqueryParams interface {
+	parser.Params
+	transform.BoundOp
+	// Subquery returns the DAG of the inner expression.
+	Subquery() (parser.Nodes, parser.Edges)
+	// SubqueryStep returns the subquery resolution given the step of the
+	// enclosing query.
+	SubqueryStep(step time.Duration) time.Duration
+}
+
 // GenerateExecutionState creates an execution state from the physical plan
 func GenerateExecutionState(
 	pplan plan.PhysicalPlan,
 	storage storage.Storage,
 ) (*ExecutionState, error) {
+	state, controller, err := newExecutionState(pplan, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	rNode := newResultNode()
+	state.resultNode = rNode
+	controller.AddTransform(rNode)
+
+	return state, nil
+}
+
+// newExecutionState creates an execution state from the physical plan and
+// returns the controller of its leaf node, so that callers can attach the
+// node that receives its results
+func newExecutionState(
+	pplan plan.PhysicalPlan,
+	storage storage.Storage,
+) (*ExecutionState, *transform.Controller, error) {
 	result := pplan.ResultStep
 	state := &ExecutionState{
 		plan:    pplan,
@@ -115,7 +147,7 @@ func GenerateExecutionState(
 
 	step, ok := pplan.Step(result.Parent)
 	if !ok {
-		return nil, fmt.Errorf("incorrect parent reference in result node, parentId: %s", result.Parent)
+		return nil, nil, fmt.Errorf("incorrect parent reference in result node, parentId: %s", result.Parent)
 	}
 
 	options := transform.Options{
@@ -126,18 +158,14 @@ func GenerateExecutionState(
 
 	controller, err := state.createNode(step, options)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if len(state.sources) == 0 {
-		return nil, errors.New("empty sources for the execution state")
+		return nil, nil, errors.New("empty sources for the execution state")
 	}
 
-	rNode := newResultNode()
-	state.resultNode = rNode
-	controller.AddTransform(rNode)
-
-	return state, nil
+	return state, controller, nil
 }
 
 // createNode helps to create an execution node recursively
@@ -147,17 +175,24 @@ func (s *ExecutionState) createNode(
 	options transform.Options,
 ) (*transform.Controller, error) {
 	// TODO: consider using a registry instead of casting to an interface
+	subqueryParams, ok := step.Transform.Op.(SubqueryParams)
+	if ok {
+		source, controller := s.createSubquerySource(step.ID(), subqueryParams, options)
+		s.addSource(step, source)
+		return controller, nil
+	}
+
 	sourceParams, ok := step.Transform.Op.(SourceParams)
 	if ok {
 		source, controller := CreateSource(step.ID(), sourceParams, s.storage, options)
-		s.sources = append(s.sources, source)
+		s.addSource(step, source)
 		return controller, nil
 	}
 
 	scalarParams, ok := step.Transform.Op.(ScalarParams)
 	if ok {
 		source, controller := CreateScalarSource(step.ID(), scalarParams, options)
-		s.sources = append(s.sources, source)
+		s.addSource(step, source)
 		return controller, nil
 	}
 
@@ -167,6 +202,7 @@ func (s *ExecutionState) createNode(
 	}
 
 	transformNode, controller := CreateTransform(step.ID(), transformParams, options)
+	transformNode = newStatsNode(step.ID(), transformParams.OpType(), transformNode)
 	for _, parentID := range step.Parents {
 		parentStep, ok := s.plan.Step(parentID)
 		if !ok {
@@ -184,6 +220,10 @@ func (s *ExecutionState) createNode(
 	return controller, nil
 }
 
+func (s *ExecutionState) addSource(step plan.LogicalStep, source parser.Source) {
+	s.sources = append(s.sources, newStatsSource(step.ID(), step.Transform.Op.OpType(), source))
+}
+
 // Execute the sources in parallel and return the first error
 func (s *ExecutionState) Execute(queryCtx *models.QueryContext) error {
 	requests := make([]execution.Request, len(s.sources))
@@ -24,12 +24,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/functions"
 	"github.com/m3db/m3/src/query/functions/aggregation"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
 	"github.com/m3db/m3/src/query/plan"
 	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/test"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,6 +66,47 @@ func TestValidState(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestExecuteRecordsStats(t *testing.T) {
+	fetchTransform := parser.NewTransformFromOperation(functions.FetchOp{}, 1)
+	agg, err := aggregation.NewAggregationOp(aggregation.CountType, aggregation.NodeParams{})
+	require.NoError(t, err)
+	countTransform := parser.NewTransformFromOperation(agg, 2)
+	transforms := parser.Nodes{fetchTransform, countTransform}
+	edges := parser.Edges{
+		parser.Edge{
+			ParentID: fetchTransform.ID,
+			ChildID:  countTransform.ID,
+		},
+	}
+
+	lp, err := plan.NewLogicalPlan(transforms, edges)
+	require.NoError(t, err)
+	store := mock.NewMockStorage()
+	now := time.Now()
+	bounds := models.Bounds{Start: now, Duration: 2 * time.Minute, StepSize: time.Minute}
+	store.SetFetchBlocksResult(block.Result{
+		Blocks: []block.Block{test.NewBlockFromValues(bounds, [][]float64{{1, 2}, {3, 4}})},
+	}, nil)
+
+	p, err := plan.NewPhysicalPlan(lp, store, models.RequestParams{Now: now}, defaultLookbackDuration)
+	require.NoError(t, err)
+	state, err := GenerateExecutionState(p, store)
+	require.NoError(t, err)
+
+	queryCtx := models.NoopQueryContext()
+	queryCtx.Stats = models.NewQueryStats()
+	require.NoError(t, state.Execute(queryCtx))
+
+	nodes := queryCtx.Stats.Summary().Nodes
+	require.Len(t, nodes, 2)
+	assert.Equal(t, "1", nodes[0].ID)
+	assert.Equal(t, functions.FetchType, nodes[0].Op)
+	assert.Equal(t, 1, nodes[0].Blocks)
+	assert.Equal(t, "2", nodes[1].ID)
+	assert.Equal(t, aggregation.CountType, nodes[1].Op)
+	assert.Equal(t, 1, nodes[1].Blocks)
+}
+
 func TestWithoutSources(t *testing.T) {
 	agg, err := aggregation.NewAggregationOp(aggregation.CountType, aggregation.NodeParams{})
 	require.NoError(t, err)
@@ -0,0 +1,82 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+// statsSource records the time spent executing a source in the query stats.
+type statsSource struct {
+	id     parser.NodeID
+	op     string
+	source parser.Source
+}
+
+func newStatsSource(id parser.NodeID, op string, source parser.Source) parser.Source {
+	return &statsSource{id: id, op: op, source: source}
+}
+
+func (s *statsSource) Execute(queryCtx *models.QueryContext) error {
+	start := time.Now()
+	err := s.source.Execute(queryCtx)
+	if queryCtx != nil {
+		queryCtx.Stats.RecordProcessing(string(s.id), s.op, time.Since(start))
+	}
+
+	return err
+}
+
+func (s *statsSource) String() string {
+	return fmt.Sprint(s.source)
+}
+
+// statsNode records the time spent processing blocks by a transform in the
+// query stats.
+type statsNode struct {
+	id   parser.NodeID
+	op   string
+	node transform.OpNode
+}
+
+func newStatsNode(id parser.NodeID, op string, node transform.OpNode) transform.OpNode {
+	return &statsNode{id: id, op: op, node: node}
+}
+
+func (n *statsNode) Process(
+	queryCtx *models.QueryContext,
+	ID parser.NodeID,
+	b block.Block,
+) error {
+	start := time.Now()
+	err := n.node.Process(queryCtx, ID, b)
+	if queryCtx != nil {
+		queryCtx.Stats.RecordProcessing(string(n.id), n.op, time.Since(start))
+	}
+
+	return err
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/plan"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3/src/query/util/logging"
+	"github.com/m3db/m3/src/query/util/opentracing"
+
+	"go.uber.org/zap"
+)
+
+// subquerySource evaluates the inner expression of a subquery as a nested
+// query at the subquery step, and emits the results as an unconsolidated
+// block on the time grid of the enclosing query. Each step of the block holds
+// the subquery points since the previous step, so temporal functions consume
+// it the same way as fetched series.
+type subquerySource struct {
+	params     SubqueryParams
+	controller *transform.Controller
+	storage    storage.Storage
+	timespec   transform.TimeSpec
+	lookback   time.Duration
+	debug      bool
+	blockType  models.FetchedBlockType
+}
+
+func (s *ExecutionState) createSubquerySource(
+	ID parser.NodeID,
+	params SubqueryParams,
+	options transform.Options,
+) (parser.Source, *transform.Controller) {
+	controller := &transform.Controller{ID: ID}
+	return &subquerySource{
+		params:     params,
+		controller: controller,
+		storage:    s.storage,
+		timespec:   options.TimeSpec,
+		lookback:   s.plan.LookbackDuration,
+		debug:      options.Debug,
+		blockType:  options.BlockType,
+	}, controller
+}
+
+// Execute runs the subquery and processes its results
+func (n *subquerySource) Execute(queryCtx *models.QueryContext) error {
+	sp, ctx := opentracingutil.StartSpanFromContext(queryCtx.Ctx, "subquery")
+	defer sp.Finish()
+	queryCtx = queryCtx.WithContext(ctx)
+
+	bounds := n.timespec.Bounds()
+	seriesList, err := n.evaluate(queryCtx, bounds)
+	if err != nil {
+		return err
+	}
+
+	// Points are only ever assigned to the step directly after them, so a
+	// lookback of at least one step keeps every point.
+	lookback := n.lookback
+	if lookback < bounds.StepSize {
+		lookback = bounds.StepSize
+	}
+
+	blockResult, err := storage.FetchResultToBlockResult(
+		&storage.FetchResult{SeriesList: seriesList},
+		&storage.FetchQuery{
+			Start:    n.timespec.Start,
+			End:      n.timespec.End,
+			Interval: n.timespec.Step,
+		},
+		lookback,
+		queryCtx.Enforcer,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, bl := range blockResult.Blocks {
+		if n.debug {
+			// Ignore any errors
+			iter, _ := bl.StepIter()
+			if iter != nil {
+				logging.WithContext(ctx).Info("subquery node", zap.Any("meta", iter.Meta()))
+			}
+		}
+
+		if err := n.controller.Process(queryCtx, bl); err != nil {
+			bl.Close()
+			// Fail on first error
+			return err
+		}
+
+		// NB: see the equivalent note in the fetch node on closing blocks.
+		if n.controller.HasMultipleOperations() {
+			bl.Close()
+		}
+	}
+
+	return nil
+}
+
+// evaluate evaluates the inner expression at the subquery step for every
+// point needed by the given bounds, returning the results shifted forward by
+// the subquery offset.
+func (n *subquerySource) evaluate(
+	queryCtx *models.QueryContext,
+	bounds models.Bounds,
+) (ts.SeriesList, error) {
+	steps := bounds.Steps()
+	if steps == 0 {
+		return nil, nil
+	}
+
+	var (
+		offset = n.params.Bounds().Offset
+		step   = n.params.SubqueryStep(bounds.StepSize)
+		// Points after the step preceding the first step, up to and including
+		// the last step, are needed.
+		first = bounds.Start.Add(-bounds.StepSize - offset)
+		last  = bounds.Start.Add(time.Duration(steps-1)*bounds.StepSize - offset)
+	)
+
+	// Subquery evaluation times are aligned to multiples of the step, the
+	// same as Prometheus, so results are stable across queries.
+	start := alignSubqueryTime(first, step).Add(step)
+	end := alignSubqueryTime(last, step)
+	if start.After(end) {
+		return nil, nil
+	}
+
+	nodes, edges := n.params.Subquery()
+	lp, err := plan.NewLogicalPlan(nodes, edges)
+	if err != nil {
+		return nil, err
+	}
+
+	pp, err := plan.NewPhysicalPlan(lp, n.storage, models.RequestParams{
+		Start:      start,
+		End:        end,
+		Now:        n.timespec.Now,
+		Step:       step,
+		IncludeEnd: true,
+		Debug:      n.debug,
+		BlockType:  n.blockType,
+	}, n.lookback)
+	if err != nil {
+		return nil, err
+	}
+
+	state, controller, err := newExecutionState(pp, n.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &subquerySink{}
+	controller.AddTransform(sink)
+	defer sink.close()
+
+	if err := state.Execute(queryCtx); err != nil {
+		return nil, err
+	}
+
+	return sink.seriesList(offset)
+}
+
+// alignSubqueryTime returns the latest multiple of step at or before t.
+func alignSubqueryTime(t time.Time, step time.Duration) time.Time {
+	nanos := t.UnixNano()
+	rem := nanos % int64(step)
+	if rem < 0 {
+		rem += int64(step)
+	}
+
+	return time.Unix(0, nanos-rem)
+}
+
+// subquerySink receives the result blocks of a subquery.
+type subquerySink struct {
+	sync.Mutex
+	blocks []block.Block
+}
+
+// Process the block
+func (s *subquerySink) Process(_ *models.QueryContext, _ parser.NodeID, b block.Block) error {
+	s.Lock()
+	s.blocks = append(s.blocks, b)
+	s.Unlock()
+	return nil
+}
+
+func (s *subquerySink) close() {
+	s.Lock()
+	defer s.Unlock()
+	for _, b := range s.blocks {
+		b.Close()
+	}
+
+	s.blocks = nil
+}
+
+// seriesList merges the result blocks into series of the non-NaN points,
+// with timestamps shifted forward by the offset.
+func (s *subquerySink) seriesList(offset time.Duration) (ts.SeriesList, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	var (
+		metas      []block.SeriesMeta
+		datapoints []ts.Datapoints
+		indices    = make(map[string]int)
+	)
+
+	for _, b := range s.blocks {
+		iter, err := b.SeriesIter()
+		if err != nil {
+			return nil, err
+		}
+
+		meta := iter.Meta()
+		seriesMetas := iter.SeriesMeta()
+		for i := 0; iter.Next(); i++ {
+			series := iter.Current()
+			tags := seriesMetas[i].Tags.AddTags(meta.Tags.Tags)
+			id := string(tags.ID())
+			idx, ok := indices[id]
+			if !ok {
+				idx = len(metas)
+				indices[id] = idx
+				metas = append(metas, block.SeriesMeta{
+					Name: seriesMetas[i].Name,
+					Tags: tags,
+				})
+				datapoints = append(datapoints, nil)
+			}
+
+			for j := 0; j < series.Len(); j++ {
+				value := series.ValueAtStep(j)
+				if math.IsNaN(value) {
+					continue
+				}
+
+				t, err := meta.Bounds.TimeForIndex(j)
+				if err != nil {
+					return nil, err
+				}
+
+				datapoints[idx] = append(datapoints[idx], ts.Datapoint{
+					Timestamp: t.Add(offset),
+					Value:     value,
+				})
+			}
+		}
+
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	seriesList := make(ts.SeriesList, 0, len(metas))
+	for i, meta := range metas {
+		dps := datapoints[i]
+		// Blocks may arrive out of order.
+		sort.Slice(dps, func(i, j int) bool {
+			return dps[i].Timestamp.Before(dps[j].Timestamp)
+		})
+		seriesList = append(seriesList, ts.NewSeries(meta.Name, dps, meta.Tags))
+	}
+
+	return seriesList, nil
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package executor
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/mock"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+// seriesStorage returns blocks of its series for the range of each fetch.
+type seriesStorage struct {
+	mock.Storage
+	series ts.SeriesList
+}
+
+func (s *seriesStorage) FetchBlocks(
+	_ context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (block.Result, error) {
+	return storage.FetchResultToBlockResult(&storage.FetchResult{
+		SeriesList: s.series,
+	}, query, defaultLookbackDuration, options.Enforcer)
+}
+
+// newTimestampSeries returns a series sampled every 10 seconds whose value is
+// the unix timestamp of the sample.
+func newTimestampSeries(start, end time.Time) *ts.Series {
+	var dps ts.Datapoints
+	for t := start; t.Before(end); t = t.Add(10 * time.Second) {
+		dps = append(dps, ts.Datapoint{Timestamp: t, Value: float64(t.Unix())})
+	}
+
+	tags := models.NewTags(1, models.NewTagOptions()).SetName([]byte("foo"))
+	return ts.NewSeries([]byte("foo"), dps, tags)
+}
+
+// executeRange executes the query and returns the values of each series by
+// step time.
+func executeRange(
+	t *testing.T,
+	store storage.Storage,
+	query string,
+	start, end time.Time,
+) []map[time.Time]float64 {
+	parser, err := promql.Parse(query, models.NewTagOptions())
+	require.NoError(t, err)
+
+	results := make(chan Query)
+	engine := NewEngine(store, tally.NoopScope, defaultLookbackDuration, nil, nil)
+	go engine.ExecuteExpr(context.TODO(), parser, &EngineOptions{},
+		models.RequestParams{
+			Start:      start,
+			End:        end,
+			Now:        end,
+			Step:       time.Minute,
+			IncludeEnd: true,
+		}, results)
+
+	var values []map[time.Time]float64
+	for r := range results {
+		require.NoError(t, r.Err)
+		for res := range r.Result.ResultChan() {
+			require.NoError(t, res.Err)
+			iter, err := res.Block.SeriesIter()
+			require.NoError(t, err)
+
+			bounds := iter.Meta().Bounds
+			for iter.Next() {
+				series := iter.Current()
+				byTime := make(map[time.Time]float64, series.Len())
+				for i := 0; i < series.Len(); i++ {
+					stepTime, err := bounds.TimeForIndex(i)
+					require.NoError(t, err)
+					byTime[stepTime] = series.ValueAtStep(i)
+				}
+
+				values = append(values, byTime)
+			}
+
+			require.NoError(t, iter.Err())
+		}
+	}
+
+	return values
+}
+
+func TestExecuteSubqueryUnaryAndVector(t *testing.T) {
+	// Aligned to the minute so that samples fall on subquery steps.
+	end := time.Unix(1543449600, 0)
+	start := end.Add(-5 * time.Minute)
+	store := &seriesStorage{
+		Storage: mock.NewMockStorage(),
+		series: ts.SeriesList{
+			newTimestampSeries(start.Add(-time.Hour), end.Add(time.Minute)),
+		},
+	}
+
+	tests := []struct {
+		query    string
+		expected func(t time.Time) float64
+	}{
+		{
+			query:    "-foo",
+			expected: func(t time.Time) float64 { return -float64(t.Unix()) },
+		},
+		{
+			query:    "vector(1)",
+			expected: func(t time.Time) float64 { return 1 },
+		},
+		{
+			query:    "max_over_time(foo[5m:1m])",
+			expected: func(t time.Time) float64 { return float64(t.Unix()) },
+		},
+		{
+			query:    "max_over_time(foo[5m:1m] offset 1m)",
+			expected: func(t time.Time) float64 { return float64(t.Unix() - 60) },
+		},
+		{
+			query:    "max_over_time(foo[5m:30s])",
+			expected: func(t time.Time) float64 { return float64(t.Unix()) },
+		},
+		{
+			query:    "-max_over_time(foo[5m:1m])",
+			expected: func(t time.Time) float64 { return -float64(t.Unix()) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			values := executeRange(t, store, tt.query, start, end)
+			require.Len(t, values, 1)
+
+			for step := start; !step.After(end); step = step.Add(time.Minute) {
+				v, ok := values[0][step]
+				require.True(t, ok, "missing step %v", step)
+				require.False(t, math.IsNaN(v), "NaN at step %v", step)
+				assert.Equal(t, tt.expected(step), v, "step %v", step)
+			}
+		})
+	}
+}
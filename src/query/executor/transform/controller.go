@@ -21,6 +21,8 @@
 package transform
 
 import (
+	"time"
+
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/parser"
@@ -39,12 +41,17 @@ func (t *Controller) AddTransform(node OpNode) {
 
 // Process performs processing on the underlying transforms
 func (t *Controller) Process(queryCtx *models.QueryContext, block block.Block) error {
+	start := time.Now()
 	for _, ts := range t.transforms {
 		if err := ts.Process(queryCtx, t.ID, block); err != nil {
 			return err
 		}
 	}
 
+	if queryCtx != nil {
+		queryCtx.Stats.RecordOutput(string(t.ID), time.Since(start))
+	}
+
 	return nil
 }
 
@@ -102,6 +102,10 @@ func (n *FetchNode) fetch(queryCtx *models.QueryContext) (block.Result, error) {
 	opts.BlockType = n.blockType
 	opts.Scope = queryCtx.Scope
 	opts.Enforcer = queryCtx.Enforcer
+	if queryCtx.SeriesEnforcer != nil {
+		opts.SeriesEnforcer = queryCtx.SeriesEnforcer
+	}
+	opts.Stats = queryCtx.Stats
 
 	return n.storage.FetchBlocks(ctx, &storage.FetchQuery{
 		Start:       startTime,
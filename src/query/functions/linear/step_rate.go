@@ -0,0 +1,168 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package linear
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+const (
+	// StepRateType calculates the per-second rate of change between the
+	// values of the steps of each series.
+	//
+	// NB: unlike the temporal rates, which are computed from the raw
+	// datapoints, this applies to already consolidated values such as the
+	// result of a temporal aggregation.
+	StepRateType = "step_rate"
+
+	// StepCounterRateType calculates the per-second rate of change between
+	// the values of the steps of each series, a decreasing value being
+	// treated as a counter reset.
+	StepCounterRateType = "step_counter_rate"
+)
+
+// NewStepRateOp creates a new step rate operation.
+func NewStepRateOp(opType string) (parser.Params, error) {
+	switch opType {
+	case StepRateType, StepCounterRateType:
+		return stepRateOp{opType: opType}, nil
+	}
+
+	return emptyOp, fmt.Errorf("operator not supported: %s", opType)
+}
+
+// stepRateOp stores required properties for step rate ops.
+type stepRateOp struct {
+	opType string
+}
+
+// OpType for the operator.
+func (o stepRateOp) OpType() string {
+	return o.opType
+}
+
+// String representation.
+func (o stepRateOp) String() string {
+	return fmt.Sprintf("type: %s", o.OpType())
+}
+
+// Node creates an execution node.
+func (o stepRateOp) Node(
+	controller *transform.Controller,
+	_ transform.Options,
+) transform.OpNode {
+	return &stepRateNode{
+		op:         o,
+		controller: controller,
+	}
+}
+
+type stepRateNode struct {
+	op         stepRateOp
+	controller *transform.Controller
+}
+
+func (n *stepRateNode) Params() parser.Params {
+	return n.op
+}
+
+// Process the block
+func (n *stepRateNode) Process(queryCtx *models.QueryContext, ID parser.NodeID, b block.Block) error {
+	return transform.ProcessSimpleBlock(n, n.controller, queryCtx, ID, b)
+}
+
+// ProcessBlock computes the rate of each step of the series from the previous
+// step with a value, the steps without a value or previous value being NaN.
+func (n *stepRateNode) ProcessBlock(
+	queryCtx *models.QueryContext,
+	ID parser.NodeID,
+	b block.Block,
+) (block.Block, error) {
+	stepIter, err := b.StepIter()
+	if err != nil {
+		return nil, err
+	}
+
+	builder, err := n.controller.BlockBuilder(queryCtx, stepIter.Meta(), stepIter.SeriesMeta())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := builder.AddCols(stepIter.StepCount()); err != nil {
+		return nil, err
+	}
+
+	var (
+		isCounter  = n.op.opType == StepCounterRateType
+		prevValues []float64
+		prevTimes  []time.Time
+		rates      []float64
+	)
+
+	for index := 0; stepIter.Next(); index++ {
+		step := stepIter.Current()
+		values := step.Values()
+		if prevValues == nil {
+			prevValues = make([]float64, len(values))
+			prevTimes = make([]time.Time, len(values))
+			rates = make([]float64, len(values))
+			for i := range prevValues {
+				prevValues[i] = math.NaN()
+			}
+		}
+
+		for i, v := range values {
+			rates[i] = math.NaN()
+			if math.IsNaN(v) {
+				continue
+			}
+
+			if prev := prevValues[i]; !math.IsNaN(prev) {
+				delta := v - prev
+				if isCounter && delta < 0 {
+					// Counter reset.
+					delta = v
+				}
+
+				rates[i] = delta / step.Time().Sub(prevTimes[i]).Seconds()
+			}
+
+			prevValues[i], prevTimes[i] = v, step.Time()
+		}
+
+		if err := builder.AppendValues(index, rates); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = stepIter.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package linear
+
+import (
+	"math"
+	"testing"
+
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+	"github.com/m3db/m3/src/query/test"
+	"github.com/m3db/m3/src/query/test/executor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStepRate(t *testing.T, opType string) [][]float64 {
+	nan := math.NaN()
+	values, bounds := test.GenerateValuesAndBounds([][]float64{
+		{60, 120, nan, 300, 60},
+		{nan, 600, 540, nan, nan},
+	}, nil)
+
+	block := test.NewBlockFromValues(bounds, values)
+	c, sink := executor.NewControllerWithSink(parser.NodeID(1))
+	op, err := NewStepRateOp(opType)
+	require.NoError(t, err)
+	node := op.Node(c, transform.Options{})
+	err = node.Process(models.NoopQueryContext(), parser.NodeID(0), block)
+	require.NoError(t, err)
+	assert.Len(t, sink.Values, 2)
+	return sink.Values
+}
+
+func TestStepRate(t *testing.T) {
+	nan := math.NaN()
+	test.EqualsWithNans(t, [][]float64{
+		{nan, 1, nan, 1.5, -4},
+		{nan, nan, -1, nan, nan},
+	}, testStepRate(t, StepRateType))
+}
+
+func TestStepCounterRate(t *testing.T) {
+	nan := math.NaN()
+	test.EqualsWithNans(t, [][]float64{
+		{nan, 1, nan, 1.5, 1},
+		{nan, nan, 9, nan, nan},
+	}, testStepRate(t, StepCounterRateType))
+}
+
+func TestStepRateInvalidType(t *testing.T) {
+	_, err := NewStepRateOp(TransformNullType)
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package linear
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/m3db/m3/src/query/executor/transform"
+)
+
+// TransformNullType replaces all NaNs with the provided argument
+const TransformNullType = "transformNull"
+
+// NewTransformNullOp creates a new transform null op based on the arguments
+func NewTransformNullOp(args []interface{}) (BaseOp, error) {
+	if len(args) != 1 {
+		return emptyOp, fmt.Errorf("invalid number of args for transformNull: %d", len(args))
+	}
+
+	value, ok := args[0].(float64)
+	if !ok {
+		return emptyOp, fmt.Errorf("unable to cast to scalar argument: %v", args[0])
+	}
+
+	return BaseOp{
+		operatorType: TransformNullType,
+		processorFn:  makeTransformNullProcessor(value),
+	}, nil
+}
+
+func makeTransformNullProcessor(value float64) makeProcessor {
+	return func(_ BaseOp, _ *transform.Controller) Processor {
+		return &transformNullNode{value: value}
+	}
+}
+
+type transformNullNode struct {
+	value float64
+}
+
+func (n *transformNullNode) Process(values []float64) []float64 {
+	for i, v := range values {
+		if math.IsNaN(v) {
+			values[i] = n.value
+		}
+	}
+
+	return values
+}
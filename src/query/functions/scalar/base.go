@@ -39,6 +39,11 @@ const (
 	// TimeType returns the number of seconds since January 1, 1970 UTC.
 	// Note that this does not actually return the current time, but the time at which the expression is to be evaluated.
 	TimeType = "time"
+
+	// VectorType returns the scalar as a vector with no labels. Scalar blocks
+	// already present as a single series without tags, so no transform is
+	// required.
+	VectorType = "vector"
 )
 
 type baseOp struct {
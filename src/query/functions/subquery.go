@@ -0,0 +1,79 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package functions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/query/executor/transform"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+// SubqueryType evaluates an inner expression over a range at a fixed step
+const SubqueryType = "subquery"
+
+// SubqueryOp stores required properties for a subquery. The inner expression
+// is kept as its own DAG since it is evaluated at the subquery step rather
+// than the step of the enclosing query.
+type SubqueryOp struct {
+	Nodes  parser.Nodes
+	Edges  parser.Edges
+	Range  time.Duration
+	Offset time.Duration
+	// Step is the subquery resolution, the step of the enclosing query is
+	// used if not set.
+	Step time.Duration
+}
+
+// OpType for the operator
+func (o SubqueryOp) OpType() string {
+	return SubqueryType
+}
+
+// Bounds returns the bounds for the spec
+func (o SubqueryOp) Bounds() transform.BoundSpec {
+	return transform.BoundSpec{
+		Range:  o.Range,
+		Offset: o.Offset,
+	}
+}
+
+// String representation
+func (o SubqueryOp) String() string {
+	return fmt.Sprintf("type: %s. range: %v, step: %v, offset: %v, nodes: %v",
+		o.OpType(), o.Range, o.Step, o.Offset, o.Nodes)
+}
+
+// Subquery returns the DAG of the inner expression
+func (o SubqueryOp) Subquery() (parser.Nodes, parser.Edges) {
+	return o.Nodes, o.Edges
+}
+
+// SubqueryStep returns the subquery resolution given the step of the
+// enclosing query
+func (o SubqueryOp) SubqueryStep(step time.Duration) time.Duration {
+	if o.Step > 0 {
+		return o.Step
+	}
+
+	return step
+}
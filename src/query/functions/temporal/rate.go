@@ -46,6 +46,10 @@ const (
 
 	// IncreaseType calculates the increase in the time series.
 	IncreaseType = "increase"
+
+	// IDerivType calculates the per-second rate of change of the time series
+	// based on the last two data points, without handling counter resets.
+	IDerivType = "ideriv"
 )
 
 type rateProcessor struct {
@@ -82,6 +86,10 @@ func NewRateOp(args []interface{}, optype string) (transform.Params, error) {
 
 	switch optype {
 	case IRateType:
+		isRate = true
+		isCounter = true
+		rateFn = irateFunc
+	case IDerivType:
 		isRate = true
 		rateFn = irateFunc
 	case IDeltaType:
@@ -213,7 +221,7 @@ func standardRateFunc(
 	return resultValue
 }
 
-func irateFunc(datapoints ts.Datapoints, isRate bool, _ bool, timeSpec transform.TimeSpec, _ time.Duration) float64 {
+func irateFunc(datapoints ts.Datapoints, isRate bool, isCounter bool, timeSpec transform.TimeSpec, _ time.Duration) float64 {
 	dpsLen := len(datapoints)
 	if dpsLen < 2 {
 		return math.NaN()
@@ -236,7 +244,7 @@ func irateFunc(datapoints ts.Datapoints, isRate bool, _ bool, timeSpec transform
 	lastSample := datapoints[indexLast]
 
 	var resultValue float64
-	if isRate && lastSample.Value < previousSample.Value {
+	if isCounter && lastSample.Value < previousSample.Value {
 		// Counter reset.
 		resultValue = lastSample.Value
 	} else {
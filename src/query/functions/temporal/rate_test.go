@@ -92,6 +92,22 @@ var testRateCases = []testRateCase{
 			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()},
 		},
 	},
+	{
+		name:   "ideriv",
+		opType: IDerivType,
+		vals: [][]float64{
+			{863682, 865910, 868138, 870366, 872594},
+			{1987036, 1988988, 1990940, 1992892, 1994844},
+		},
+		afterBlockOne: [][]float64{
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), 37.1333},
+			{math.NaN(), math.NaN(), math.NaN(), math.NaN(), 32.5333},
+		},
+		afterAllBlocks: [][]float64{
+			{-148.5333, 37.1333, 37.1333, 37.1333, 37.1333},
+			{-130.1333, 32.5333, 32.5333, 32.5333, 32.5333},
+		},
+	},
 	{
 		name:   "rate",
 		opType: RateType,
@@ -64,6 +64,68 @@ func (x LabelMatcher_Type) String() string {
 }
 func (LabelMatcher_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptorTypes, []int{4, 0} }
 
+type Chunk_Encoding int32
+
+const (
+	Chunk_UNKNOWN Chunk_Encoding = 0
+	Chunk_XOR     Chunk_Encoding = 1
+)
+
+var Chunk_Encoding_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "XOR",
+}
+var Chunk_Encoding_value = map[string]int32{
+	"UNKNOWN": 0,
+	"XOR":     1,
+}
+
+func (x Chunk_Encoding) String() string {
+	return proto.EnumName(Chunk_Encoding_name, int32(x))
+}
+func (Chunk_Encoding) EnumDescriptor() ([]byte, []int) { return fileDescriptorTypes, []int{5, 0} }
+
+type MetricMetadata_MetricType int32
+
+const (
+	MetricMetadata_UNKNOWN        MetricMetadata_MetricType = 0
+	MetricMetadata_COUNTER        MetricMetadata_MetricType = 1
+	MetricMetadata_GAUGE          MetricMetadata_MetricType = 2
+	MetricMetadata_HISTOGRAM      MetricMetadata_MetricType = 3
+	MetricMetadata_GAUGEHISTOGRAM MetricMetadata_MetricType = 4
+	MetricMetadata_SUMMARY        MetricMetadata_MetricType = 5
+	MetricMetadata_INFO           MetricMetadata_MetricType = 6
+	MetricMetadata_STATESET       MetricMetadata_MetricType = 7
+)
+
+var MetricMetadata_MetricType_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "COUNTER",
+	2: "GAUGE",
+	3: "HISTOGRAM",
+	4: "GAUGEHISTOGRAM",
+	5: "SUMMARY",
+	6: "INFO",
+	7: "STATESET",
+}
+var MetricMetadata_MetricType_value = map[string]int32{
+	"UNKNOWN":        0,
+	"COUNTER":        1,
+	"GAUGE":          2,
+	"HISTOGRAM":      3,
+	"GAUGEHISTOGRAM": 4,
+	"SUMMARY":        5,
+	"INFO":           6,
+	"STATESET":       7,
+}
+
+func (x MetricMetadata_MetricType) String() string {
+	return proto.EnumName(MetricMetadata_MetricType_name, int32(x))
+}
+func (MetricMetadata_MetricType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptorTypes, []int{7, 0}
+}
+
 type Sample struct {
 	Value     float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
 	Timestamp int64   `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
@@ -185,13 +247,128 @@ func (m *LabelMatcher) GetValue() []byte {
 	return nil
 }
 
+// Chunk represents a TSDB chunk, its time range [min, max] is inclusive.
+type Chunk struct {
+	MinTimeMs int64          `protobuf:"varint,1,opt,name=min_time_ms,json=minTimeMs,proto3" json:"min_time_ms,omitempty"`
+	MaxTimeMs int64          `protobuf:"varint,2,opt,name=max_time_ms,json=maxTimeMs,proto3" json:"max_time_ms,omitempty"`
+	Type      Chunk_Encoding `protobuf:"varint,3,opt,name=type,proto3,enum=prometheus.Chunk_Encoding" json:"type,omitempty"`
+	Data      []byte         `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *Chunk) Reset()                    { *m = Chunk{} }
+func (m *Chunk) String() string            { return proto.CompactTextString(m) }
+func (*Chunk) ProtoMessage()               {}
+func (*Chunk) Descriptor() ([]byte, []int) { return fileDescriptorTypes, []int{5} }
+
+func (m *Chunk) GetMinTimeMs() int64 {
+	if m != nil {
+		return m.MinTimeMs
+	}
+	return 0
+}
+
+func (m *Chunk) GetMaxTimeMs() int64 {
+	if m != nil {
+		return m.MaxTimeMs
+	}
+	return 0
+}
+
+func (m *Chunk) GetType() Chunk_Encoding {
+	if m != nil {
+		return m.Type
+	}
+	return Chunk_UNKNOWN
+}
+
+func (m *Chunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// ChunkedSeries represents a single encoded time series.
+type ChunkedSeries struct {
+	// Labels should be sorted.
+	Labels []*Label `protobuf:"bytes,1,rep,name=labels" json:"labels,omitempty"`
+	// Chunks will be in start time order and may overlap.
+	Chunks []*Chunk `protobuf:"bytes,2,rep,name=chunks" json:"chunks,omitempty"`
+}
+
+func (m *ChunkedSeries) Reset()                    { *m = ChunkedSeries{} }
+func (m *ChunkedSeries) String() string            { return proto.CompactTextString(m) }
+func (*ChunkedSeries) ProtoMessage()               {}
+func (*ChunkedSeries) Descriptor() ([]byte, []int) { return fileDescriptorTypes, []int{6} }
+
+func (m *ChunkedSeries) GetLabels() []*Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *ChunkedSeries) GetChunks() []*Chunk {
+	if m != nil {
+		return m.Chunks
+	}
+	return nil
+}
+
+// MetricMetadata describes the type, help and unit of a metric family.
+type MetricMetadata struct {
+	// Represents the metric type, these match the set from Prometheus.
+	Type             MetricMetadata_MetricType `protobuf:"varint,1,opt,name=type,proto3,enum=prometheus.MetricMetadata_MetricType" json:"type,omitempty"`
+	MetricFamilyName string                    `protobuf:"bytes,2,opt,name=metric_family_name,json=metricFamilyName,proto3" json:"metric_family_name,omitempty"`
+	Help             string                    `protobuf:"bytes,4,opt,name=help,proto3" json:"help,omitempty"`
+	Unit             string                    `protobuf:"bytes,5,opt,name=unit,proto3" json:"unit,omitempty"`
+}
+
+func (m *MetricMetadata) Reset()                    { *m = MetricMetadata{} }
+func (m *MetricMetadata) String() string            { return proto.CompactTextString(m) }
+func (*MetricMetadata) ProtoMessage()               {}
+func (*MetricMetadata) Descriptor() ([]byte, []int) { return fileDescriptorTypes, []int{7} }
+
+func (m *MetricMetadata) GetType() MetricMetadata_MetricType {
+	if m != nil {
+		return m.Type
+	}
+	return MetricMetadata_UNKNOWN
+}
+
+func (m *MetricMetadata) GetMetricFamilyName() string {
+	if m != nil {
+		return m.MetricFamilyName
+	}
+	return ""
+}
+
+func (m *MetricMetadata) GetHelp() string {
+	if m != nil {
+		return m.Help
+	}
+	return ""
+}
+
+func (m *MetricMetadata) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Sample)(nil), "prometheus.Sample")
 	proto.RegisterType((*TimeSeries)(nil), "prometheus.TimeSeries")
 	proto.RegisterType((*Label)(nil), "prometheus.Label")
 	proto.RegisterType((*Labels)(nil), "prometheus.Labels")
 	proto.RegisterType((*LabelMatcher)(nil), "prometheus.LabelMatcher")
+	proto.RegisterType((*Chunk)(nil), "prometheus.Chunk")
+	proto.RegisterType((*ChunkedSeries)(nil), "prometheus.ChunkedSeries")
+	proto.RegisterType((*MetricMetadata)(nil), "prometheus.MetricMetadata")
 	proto.RegisterEnum("prometheus.LabelMatcher_Type", LabelMatcher_Type_name, LabelMatcher_Type_value)
+	proto.RegisterEnum("prometheus.Chunk_Encoding", Chunk_Encoding_name, Chunk_Encoding_value)
+	proto.RegisterEnum("prometheus.MetricMetadata_MetricType", MetricMetadata_MetricType_name, MetricMetadata_MetricType_value)
 }
 func (m *Sample) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
@@ -359,6 +536,128 @@ func (m *LabelMatcher) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *Chunk) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Chunk) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.MinTimeMs != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(m.MinTimeMs))
+	}
+	if m.MaxTimeMs != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxTimeMs))
+	}
+	if m.Type != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(m.Type))
+	}
+	if len(m.Data) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Data)))
+		i += copy(dAtA[i:], m.Data)
+	}
+	return i, nil
+}
+
+func (m *ChunkedSeries) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ChunkedSeries) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Labels) > 0 {
+		for _, msg := range m.Labels {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintTypes(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Chunks) > 0 {
+		for _, msg := range m.Chunks {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintTypes(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *MetricMetadata) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *MetricMetadata) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Type != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(m.Type))
+	}
+	if len(m.MetricFamilyName) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.MetricFamilyName)))
+		i += copy(dAtA[i:], m.MetricFamilyName)
+	}
+	if len(m.Help) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Help)))
+		i += copy(dAtA[i:], m.Help)
+	}
+	if len(m.Unit) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Unit)))
+		i += copy(dAtA[i:], m.Unit)
+	}
+	return i, nil
+}
+
 func encodeVarintTypes(dAtA []byte, offset int, v uint64) int {
 	for v >= 1<<7 {
 		dAtA[offset] = uint8(v&0x7f | 0x80)
@@ -441,6 +740,64 @@ func (m *LabelMatcher) Size() (n int) {
 	return n
 }
 
+func (m *Chunk) Size() (n int) {
+	var l int
+	_ = l
+	if m.MinTimeMs != 0 {
+		n += 1 + sovTypes(uint64(m.MinTimeMs))
+	}
+	if m.MaxTimeMs != 0 {
+		n += 1 + sovTypes(uint64(m.MaxTimeMs))
+	}
+	if m.Type != 0 {
+		n += 1 + sovTypes(uint64(m.Type))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+
+func (m *ChunkedSeries) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Labels) > 0 {
+		for _, e := range m.Labels {
+			l = e.Size()
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if len(m.Chunks) > 0 {
+		for _, e := range m.Chunks {
+			l = e.Size()
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *MetricMetadata) Size() (n int) {
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovTypes(uint64(m.Type))
+	}
+	l = len(m.MetricFamilyName)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	l = len(m.Help)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	l = len(m.Unit)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+
 func sovTypes(x uint64) (n int) {
 	for {
 		n++
@@ -970,6 +1327,412 @@ func (m *LabelMatcher) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *Chunk) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Chunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Chunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinTimeMs", wireType)
+			}
+			m.MinTimeMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinTimeMs |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxTimeMs", wireType)
+			}
+			m.MaxTimeMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxTimeMs |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= (Chunk_Encoding(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ChunkedSeries) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ChunkedSeries: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ChunkedSeries: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Labels = append(m.Labels, &Label{})
+			if err := m.Labels[len(m.Labels)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Chunks", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Chunks = append(m.Chunks, &Chunk{})
+			if err := m.Chunks[len(m.Chunks)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *MetricMetadata) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: MetricMetadata: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: MetricMetadata: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= (MetricMetadata_MetricType(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MetricFamilyName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MetricFamilyName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Help", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Help = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Unit", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Unit = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipTypes(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -1080,28 +1843,45 @@ func init() {
 }
 
 var fileDescriptorTypes = []byte{
-	// 368 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0xc1, 0x8a, 0xdb, 0x30,
-	0x10, 0x86, 0x23, 0xdb, 0x71, 0xe8, 0x34, 0x14, 0x57, 0xf4, 0x60, 0x4a, 0xeb, 0x06, 0x9f, 0x5c,
-	0x68, 0x2d, 0x92, 0x9c, 0x0a, 0x85, 0x42, 0xc0, 0xb7, 0xb4, 0x10, 0x25, 0xa7, 0xde, 0xec, 0x64,
-	0xea, 0x18, 0xac, 0xd8, 0xb5, 0xe4, 0x42, 0xde, 0xa2, 0x97, 0xbe, 0x53, 0x8e, 0x7d, 0x82, 0x65,
-	0xc9, 0xbe, 0xc8, 0x62, 0x29, 0xd9, 0x04, 0x76, 0x61, 0x2f, 0x66, 0xe6, 0xf7, 0xff, 0xcf, 0x7c,
-	0x48, 0x82, 0x6f, 0x79, 0xa1, 0xb6, 0x6d, 0x16, 0xaf, 0x2b, 0xc1, 0xc4, 0x74, 0x93, 0x31, 0x31,
-	0x65, 0xb2, 0x59, 0xb3, 0xdf, 0x2d, 0x36, 0x7b, 0x96, 0xe3, 0x0e, 0x9b, 0x54, 0xe1, 0x86, 0xd5,
-	0x4d, 0xa5, 0xaa, 0xee, 0x2b, 0xea, 0x8c, 0xa9, 0x7d, 0x8d, 0x32, 0xd6, 0x12, 0x85, 0x4e, 0x43,
-	0xb5, 0xc5, 0x56, 0xbe, 0xfd, 0x7c, 0x35, 0x2c, 0xaf, 0xf2, 0xca, 0xa4, 0xb2, 0xf6, 0x97, 0xee,
-	0xcc, 0x88, 0xae, 0x32, 0xd1, 0xf0, 0x2b, 0xb8, 0xcb, 0x54, 0xd4, 0x25, 0xd2, 0x37, 0xd0, 0xff,
-	0x93, 0x96, 0x2d, 0xfa, 0x64, 0x44, 0x22, 0xc2, 0x4d, 0x43, 0xdf, 0xc1, 0x0b, 0x55, 0x08, 0x94,
-	0x2a, 0x15, 0xb5, 0x6f, 0x8d, 0x48, 0x64, 0xf3, 0x8b, 0x10, 0x22, 0xc0, 0xaa, 0x10, 0xb8, 0xc4,
-	0xa6, 0x40, 0x49, 0x3f, 0x82, 0x5b, 0xa6, 0x19, 0x96, 0xd2, 0x27, 0x23, 0x3b, 0x7a, 0x39, 0x79,
-	0x1d, 0x5f, 0xb8, 0xe2, 0x79, 0xf7, 0x87, 0x9f, 0x0c, 0xf4, 0x13, 0x0c, 0xa4, 0x5e, 0x2b, 0x7d,
-	0x4b, 0x7b, 0xe9, 0xb5, 0xd7, 0x10, 0xf1, 0xb3, 0x25, 0x1c, 0x43, 0x5f, 0xc7, 0x29, 0x05, 0x67,
-	0x97, 0x0a, 0x83, 0x38, 0xe4, 0xba, 0xbe, 0x70, 0x5b, 0x5a, 0x34, 0x4d, 0xf8, 0x05, 0xdc, 0xb9,
-	0x59, 0xc5, 0x9e, 0xa5, 0x9a, 0x39, 0x87, 0x9b, 0x0f, 0xbd, 0x33, 0x5b, 0xf8, 0x8f, 0xc0, 0x50,
-	0xeb, 0xdf, 0x53, 0xb5, 0xde, 0x62, 0x43, 0xc7, 0xe0, 0x74, 0xa7, 0xad, 0xb7, 0xbe, 0x9a, 0xbc,
-	0x7f, 0x94, 0x3f, 0xf9, 0xe2, 0xd5, 0xbe, 0x46, 0xae, 0xad, 0x0f, 0xa0, 0xd6, 0x53, 0xa0, 0xf6,
-	0x35, 0x68, 0x04, 0x4e, 0x97, 0xa3, 0x2e, 0x58, 0xc9, 0xc2, 0xeb, 0xd1, 0x01, 0xd8, 0x3f, 0x92,
-	0x85, 0x47, 0x3a, 0x81, 0x27, 0x9e, 0xa5, 0x05, 0x9e, 0x78, 0xf6, 0xcc, 0x3f, 0x1c, 0x03, 0xf2,
-	0xff, 0x18, 0x90, 0xdb, 0x63, 0x40, 0xfe, 0xde, 0x05, 0xbd, 0x9f, 0xae, 0x79, 0x0b, 0x99, 0xab,
-	0xef, 0x72, 0x7a, 0x1f, 0x00, 0x00, 0xff, 0xff, 0x6f, 0x4a, 0x7b, 0x68, 0x49, 0x02, 0x00, 0x00,
+	// 632 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6a, 0xdb, 0x4c,
+	0x14, 0x8d, 0x7e, 0x2c, 0xc7, 0xd7, 0x49, 0x50, 0x86, 0x6f, 0x21, 0xc2, 0x57, 0xd7, 0x08, 0x0a,
+	0x0e, 0xa4, 0x12, 0x49, 0x56, 0x81, 0x42, 0x71, 0x82, 0x92, 0x86, 0x46, 0x32, 0x19, 0xcb, 0xf4,
+	0x67, 0x63, 0xc6, 0xf6, 0xc4, 0x16, 0xf5, 0xc8, 0xaa, 0x7e, 0x4a, 0xfc, 0x16, 0xdd, 0x74, 0xd7,
+	0x57, 0xe8, 0x7b, 0x64, 0xd9, 0x27, 0x28, 0x25, 0x7d, 0x91, 0x32, 0x33, 0x72, 0x6c, 0x93, 0x42,
+	0xe9, 0x46, 0xdc, 0x7b, 0xee, 0xb9, 0x73, 0xcf, 0x9d, 0x23, 0x06, 0x5e, 0x8e, 0xa3, 0x7c, 0x52,
+	0x0c, 0x9c, 0xe1, 0x8c, 0xb9, 0xec, 0x78, 0x34, 0x70, 0xd9, 0xb1, 0x9b, 0xa5, 0x43, 0xf7, 0x63,
+	0x41, 0xd3, 0xb9, 0x3b, 0xa6, 0x31, 0x4d, 0x49, 0x4e, 0x47, 0x6e, 0x92, 0xce, 0xf2, 0x19, 0xff,
+	0xb2, 0x64, 0xe0, 0xe6, 0xf3, 0x84, 0x66, 0x8e, 0x80, 0x10, 0x70, 0x8c, 0xe6, 0x13, 0x5a, 0x64,
+	0x7b, 0xcf, 0x57, 0x0e, 0x1b, 0xcf, 0xc6, 0x33, 0xd9, 0x35, 0x28, 0x6e, 0x44, 0x26, 0x8f, 0xe0,
+	0x91, 0x6c, 0xb5, 0x5f, 0x80, 0xd1, 0x25, 0x2c, 0x99, 0x52, 0xf4, 0x1f, 0x54, 0x3e, 0x91, 0x69,
+	0x41, 0x2d, 0xa5, 0xa9, 0xb4, 0x14, 0x2c, 0x13, 0xf4, 0x3f, 0xd4, 0xf2, 0x88, 0xd1, 0x2c, 0x27,
+	0x2c, 0xb1, 0xd4, 0xa6, 0xd2, 0xd2, 0xf0, 0x12, 0xb0, 0x29, 0x40, 0x18, 0x31, 0xda, 0xa5, 0x69,
+	0x44, 0x33, 0xb4, 0x0f, 0xc6, 0x94, 0x0c, 0xe8, 0x34, 0xb3, 0x94, 0xa6, 0xd6, 0xaa, 0x1f, 0xed,
+	0x3a, 0x4b, 0x5d, 0xce, 0x15, 0xaf, 0xe0, 0x92, 0x80, 0x0e, 0xa0, 0x9a, 0x89, 0xb1, 0x99, 0xa5,
+	0x0a, 0x2e, 0x5a, 0xe5, 0x4a, 0x45, 0x78, 0x41, 0xb1, 0x0f, 0xa1, 0x22, 0xda, 0x11, 0x02, 0x3d,
+	0x26, 0x4c, 0x4a, 0xdc, 0xc2, 0x22, 0x5e, 0xea, 0x56, 0x05, 0x28, 0x13, 0xfb, 0x04, 0x8c, 0x2b,
+	0x39, 0xca, 0xfd, 0xab, 0xaa, 0x53, 0xfd, 0xee, 0xc7, 0xd3, 0x8d, 0x85, 0x36, 0xfb, 0x8b, 0x02,
+	0x5b, 0x02, 0xf7, 0x49, 0x3e, 0x9c, 0xd0, 0x14, 0x1d, 0x82, 0xce, 0x6f, 0x5b, 0x4c, 0xdd, 0x39,
+	0x7a, 0xf2, 0xa8, 0xbf, 0xe4, 0x39, 0xe1, 0x3c, 0xa1, 0x58, 0x50, 0x1f, 0x84, 0xaa, 0x7f, 0x12,
+	0xaa, 0xad, 0x0a, 0x6d, 0x81, 0xce, 0xfb, 0x90, 0x01, 0xaa, 0x77, 0x6d, 0x6e, 0xa0, 0x2a, 0x68,
+	0x81, 0x77, 0x6d, 0x2a, 0x1c, 0xc0, 0x9e, 0xa9, 0x0a, 0x00, 0x7b, 0xa6, 0x66, 0x7f, 0x53, 0xa0,
+	0x72, 0x36, 0x29, 0xe2, 0x0f, 0xa8, 0x01, 0x75, 0x16, 0xc5, 0x7d, 0xee, 0x43, 0x9f, 0x65, 0x42,
+	0x97, 0x86, 0x6b, 0x2c, 0x8a, 0xb9, 0x19, 0x7e, 0x26, 0xea, 0xe4, 0xf6, 0xa1, 0x5e, 0xda, 0xc6,
+	0xc8, 0x6d, 0x59, 0x77, 0xca, 0x85, 0x34, 0xb1, 0xd0, 0xde, 0xea, 0x42, 0x62, 0x80, 0xe3, 0xc5,
+	0xc3, 0xd9, 0x28, 0x8a, 0xc7, 0xcb, 0x6d, 0x46, 0x24, 0x27, 0x96, 0x2e, 0xb7, 0xe1, 0xb1, 0xdd,
+	0x84, 0xcd, 0x05, 0x0b, 0xd5, 0xa1, 0xda, 0x0b, 0x5e, 0x07, 0x9d, 0x37, 0x81, 0x5c, 0xe0, 0x6d,
+	0x07, 0x9b, 0x8a, 0x4d, 0x61, 0x5b, 0x9c, 0x46, 0x47, 0xff, 0xfe, 0x7f, 0xec, 0x83, 0x31, 0xe4,
+	0xbd, 0x8b, 0xdf, 0x63, 0xf7, 0x91, 0x46, 0x5c, 0x12, 0xec, 0xaf, 0x2a, 0xec, 0xf8, 0x34, 0x4f,
+	0xa3, 0xa1, 0x4f, 0x73, 0xc2, 0xb5, 0xa1, 0x93, 0x35, 0xc3, 0x9e, 0xad, 0xf6, 0xae, 0x33, 0xcb,
+	0x74, 0xc5, 0xb8, 0x03, 0x40, 0x4c, 0x60, 0xfd, 0x1b, 0xc2, 0xa2, 0xe9, 0xbc, 0xff, 0x60, 0x63,
+	0x0d, 0x9b, 0xb2, 0x72, 0x2e, 0x0a, 0x01, 0xb7, 0x14, 0x81, 0x3e, 0xa1, 0xd3, 0x44, 0x5c, 0x4c,
+	0x0d, 0x8b, 0x98, 0x63, 0x45, 0x1c, 0xe5, 0x56, 0x45, 0x62, 0x3c, 0xb6, 0xe7, 0x00, 0xcb, 0x49,
+	0xeb, 0xd7, 0x55, 0x87, 0xea, 0x59, 0xa7, 0x17, 0x84, 0x1e, 0x36, 0x15, 0x54, 0x83, 0xca, 0x45,
+	0xbb, 0x77, 0xc1, 0x6d, 0xdf, 0x86, 0xda, 0xab, 0xcb, 0x6e, 0xd8, 0xb9, 0xc0, 0x6d, 0xdf, 0xd4,
+	0x10, 0x82, 0x1d, 0x51, 0x59, 0x62, 0x3a, 0x6f, 0xed, 0xf6, 0x7c, 0xbf, 0x8d, 0xdf, 0x99, 0x15,
+	0xb4, 0x09, 0xfa, 0x65, 0x70, 0xde, 0x31, 0x0d, 0xb4, 0x05, 0x9b, 0xdd, 0xb0, 0x1d, 0x7a, 0x5d,
+	0x2f, 0x34, 0xab, 0xa7, 0xd6, 0xdd, 0x7d, 0x43, 0xf9, 0x7e, 0xdf, 0x50, 0x7e, 0xde, 0x37, 0x94,
+	0xcf, 0xbf, 0x1a, 0x1b, 0xef, 0x0d, 0xf9, 0x82, 0x0c, 0x0c, 0xf1, 0x02, 0x1c, 0xff, 0x1e, 0x00,
+	0x87, 0x17, 0xd3, 0x85, 0x7f, 0x04, 0x00, 0x00,
 }
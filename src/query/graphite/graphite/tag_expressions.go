@@ -0,0 +1,202 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/m3db/m3/src/query/graphite/errors"
+)
+
+const (
+	// SeriesByTagFunction is the name of the function selecting tagged series.
+	SeriesByTagFunction = "seriesByTag"
+
+	// TaggedNameTag is the tag holding the path of a tagged series.
+	TaggedNameTag = "name"
+
+	taggedNameSep      = ";"
+	taggedNameValueSep = "="
+
+	seriesByTagPrefix = SeriesByTagFunction + "("
+	seriesByTagSuffix = ")"
+	tagExprQuote      = "'"
+	tagExprSep        = tagExprQuote + "," + tagExprQuote
+)
+
+// TagMatchType is the type of match applied by a tag expression.
+type TagMatchType int
+
+const (
+	// TagMatchEqual matches tag values equal to the expression value.
+	TagMatchEqual TagMatchType = iota
+	// TagMatchNotEqual matches tag values not equal to the expression value.
+	TagMatchNotEqual
+	// TagMatchRegexp matches tag values starting with a match of the
+	// expression value.
+	TagMatchRegexp
+	// TagMatchNotRegexp matches tag values not starting with a match of the
+	// expression value.
+	TagMatchNotRegexp
+)
+
+// Ordered so that longer operators are tried first.
+var tagMatchOperators = []struct {
+	op        string
+	matchType TagMatchType
+}{
+	{op: "!=~", matchType: TagMatchNotRegexp},
+	{op: "=~", matchType: TagMatchRegexp},
+	{op: "!=", matchType: TagMatchNotEqual},
+	{op: "=", matchType: TagMatchEqual},
+}
+
+// TagExpression is a single tag expression of a seriesByTag query,
+// e.g. host=a or dc=~us-.*.
+type TagExpression struct {
+	Name  string
+	Value string
+	Type  TagMatchType
+}
+
+// ParseTagExpression parses a tag expression of the form tag<op>value, where
+// op is one of =, !=, =~ or !=~.
+func ParseTagExpression(expr string) (TagExpression, error) {
+	idx := strings.IndexAny(expr, "!=")
+	if idx <= 0 {
+		return TagExpression{}, errors.NewInvalidParamsError(
+			fmt.Errorf("invalid tag expression: %s", expr))
+	}
+
+	for _, o := range tagMatchOperators {
+		if strings.HasPrefix(expr[idx:], o.op) {
+			return TagExpression{
+				Name:  expr[:idx],
+				Value: expr[idx+len(o.op):],
+				Type:  o.matchType,
+			}, nil
+		}
+	}
+
+	return TagExpression{}, errors.NewInvalidParamsError(
+		fmt.Errorf("invalid tag expression: %s", expr))
+}
+
+// SeriesByTagQuery returns the canonical seriesByTag query for the given tag
+// expressions, e.g. seriesByTag('name=cpu.load','host=a').
+func SeriesByTagQuery(exprs []string) string {
+	return seriesByTagPrefix + tagExprQuote + strings.Join(exprs, tagExprSep) +
+		tagExprQuote + seriesByTagSuffix
+}
+
+// IsSeriesByTagQuery returns true if the query is a seriesByTag query rather
+// than a path query.
+func IsSeriesByTagQuery(query string) bool {
+	return strings.HasPrefix(query, seriesByTagPrefix)
+}
+
+// ParseSeriesByTagQuery parses the tag expressions of a seriesByTag query
+// generated by SeriesByTagQuery. At least one expression must select series by
+// a non-empty value, otherwise every series would be matched.
+func ParseSeriesByTagQuery(query string) ([]TagExpression, error) {
+	if !IsSeriesByTagQuery(query) || !strings.HasSuffix(query, seriesByTagSuffix) {
+		return nil, errors.NewInvalidParamsError(
+			fmt.Errorf("invalid seriesByTag query: %s", query))
+	}
+
+	args := strings.TrimSuffix(strings.TrimPrefix(query, seriesByTagPrefix),
+		seriesByTagSuffix)
+	if len(args) < 2*len(tagExprQuote) || !strings.HasPrefix(args, tagExprQuote) ||
+		!strings.HasSuffix(args, tagExprQuote) {
+		return nil, errors.NewInvalidParamsError(
+			fmt.Errorf("invalid seriesByTag query: %s", query))
+	}
+
+	var (
+		parts    = strings.Split(args[1:len(args)-1], tagExprSep)
+		exprs    = make([]TagExpression, 0, len(parts))
+		positive bool
+	)
+	for _, part := range parts {
+		expr, err := ParseTagExpression(part)
+		if err != nil {
+			return nil, err
+		}
+
+		switch expr.Type {
+		case TagMatchEqual, TagMatchRegexp:
+			if expr.Value != "" {
+				positive = true
+			}
+		}
+
+		exprs = append(exprs, expr)
+	}
+
+	if !positive {
+		return nil, errors.NewInvalidParamsError(fmt.Errorf(
+			"seriesByTag requires at least one = or =~ expression with a value: %s",
+			query))
+	}
+
+	return exprs, nil
+}
+
+// TaggedNameTags returns the tags of a tagged series name, e.g. the name
+// cpu.load;host=a yields {name: cpu.load, host: a}. Untagged names yield only
+// the name tag.
+func TaggedNameTags(name string) map[string]string {
+	parts := strings.Split(name, taggedNameSep)
+	tags := make(map[string]string, len(parts))
+	tags[TaggedNameTag] = parts[0]
+	for _, part := range parts[1:] {
+		if idx := strings.Index(part, taggedNameValueSep); idx > 0 {
+			tags[part[:idx]] = part[idx+1:]
+		}
+	}
+
+	return tags
+}
+
+// TaggedName returns the tagged series name for the given tags, with the
+// remaining tags sorted by name after the path, e.g. cpu.load;dc=x;host=a.
+func TaggedName(tags map[string]string) string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		if name != TaggedNameTag {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(tags[TaggedNameTag])
+	for _, name := range names {
+		b.WriteString(taggedNameSep)
+		b.WriteString(name)
+		b.WriteString(taggedNameValueSep)
+		b.WriteString(tags[name])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package graphite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagExpression(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected TagExpression
+	}{
+		{"host=a", TagExpression{Name: "host", Value: "a", Type: TagMatchEqual}},
+		{"host!=a", TagExpression{Name: "host", Value: "a", Type: TagMatchNotEqual}},
+		{"host=~a.*", TagExpression{Name: "host", Value: "a.*", Type: TagMatchRegexp}},
+		{"host!=~a.*", TagExpression{Name: "host", Value: "a.*", Type: TagMatchNotRegexp}},
+		{"host=", TagExpression{Name: "host", Value: "", Type: TagMatchEqual}},
+		{"host==a", TagExpression{Name: "host", Value: "=a", Type: TagMatchEqual}},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseTagExpression(test.expr)
+		require.NoError(t, err, test.expr)
+		assert.Equal(t, test.expected, actual, test.expr)
+	}
+
+	for _, expr := range []string{"host", "=a", "host!a"} {
+		_, err := ParseTagExpression(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestSeriesByTagQuery(t *testing.T) {
+	query := SeriesByTagQuery([]string{"name=cpu.load", "host!=a"})
+	assert.Equal(t, "seriesByTag('name=cpu.load','host!=a')", query)
+	assert.True(t, IsSeriesByTagQuery(query))
+	assert.False(t, IsSeriesByTagQuery("foo.bar.*"))
+
+	exprs, err := ParseSeriesByTagQuery(query)
+	require.NoError(t, err)
+	assert.Equal(t, []TagExpression{
+		{Name: "name", Value: "cpu.load", Type: TagMatchEqual},
+		{Name: "host", Value: "a", Type: TagMatchNotEqual},
+	}, exprs)
+}
+
+func TestParseSeriesByTagQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"foo.bar",
+		"seriesByTag(",
+		"seriesByTag()",
+		"seriesByTag('host')",
+		"seriesByTag('host!=a')",
+		"seriesByTag('host=')",
+	} {
+		_, err := ParseSeriesByTagQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestTaggedNameTags(t *testing.T) {
+	tags := TaggedNameTags("cpu.load;host=a;dc=x")
+	assert.Equal(t, map[string]string{
+		"name": "cpu.load",
+		"host": "a",
+		"dc":   "x",
+	}, tags)
+	assert.Equal(t, "cpu.load;dc=x;host=a", TaggedName(tags))
+
+	assert.Equal(t, map[string]string{"name": "foo.bar"}, TaggedNameTags("foo.bar"))
+	assert.Equal(t, "foo.bar", TaggedName(map[string]string{"name": "foo.bar"}))
+}
@@ -23,10 +23,12 @@ package native
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/m3db/m3/src/query/graphite/common"
 	"github.com/m3db/m3/src/query/graphite/errors"
+	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/graphite/ts"
 )
 
@@ -170,33 +172,10 @@ func combineSeriesWithWildcards(
 		return ts.SeriesList(series), nil
 	}
 
-	var (
-		toCombine = make(map[string][]*ts.Series)
-		wildcards = make(map[int]struct{})
-	)
-
-	for _, position := range positions {
-		wildcards[position] = struct{}{}
-	}
-
-	for _, series := range series.Values {
-		var (
-			parts    = strings.Split(series.Name(), ".")
-			newParts = make([]string, 0, len(parts))
-		)
-		for i, part := range parts {
-			if _, wildcard := wildcards[i]; !wildcard {
-				newParts = append(newParts, part)
-			}
-		}
-
-		newName := strings.Join(newParts, ".")
-		toCombine[newName] = append(toCombine[newName], series)
-	}
-
-	newSeries := make([]*ts.Series, 0, len(toCombine))
-	for name, series := range toCombine {
-		seriesList := ts.SeriesList{Values: series}
+	keys, toCombine := groupByWildcards(ts.SeriesList(series), positions)
+	newSeries := make([]*ts.Series, 0, len(keys))
+	for _, name := range keys {
+		seriesList := ts.SeriesList{Values: toCombine[name]}
 		combined, err := combineSeries(ctx, multiplePathSpecs(seriesList), name, f)
 		if err != nil {
 			return ts.SeriesList{}, err
@@ -209,8 +188,8 @@ func combineSeriesWithWildcards(
 
 	r.Values = newSeries
 
-	// Ranging over hash map to create results destroys
-	// any sort order on the incoming series list
+	// Grouping the series destroys any sort order on
+	// the incoming series list
 	r.SortApplied = false
 
 	return r, nil
@@ -274,6 +253,63 @@ func groupByNode(ctx *common.Context, series singlePathSpec, node int, fname str
 	return r, nil
 }
 
+// groupByTags takes a serieslist and maps a callback to subgroups within as
+// defined by the values of a set of tags
+//
+//    &target=groupByTags(seriesByTag('name=cpu','dc=dc1'),"sumSeries","host")
+//
+//  Would return multiple series which are each the result of applying the
+//  "sumSeries" function to the series sharing a host, named like
+//  sumSeries;host=a. If the name tag is one of the tags, its value is used as
+//  the name of each group instead of the function name.
+func groupByTags(ctx *common.Context, series singlePathSpec, fname string, tags ...string) (ts.SeriesList, error) {
+	if len(tags) == 0 {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(
+			fmt.Errorf("groupByTags requires at least one tag"))
+	}
+
+	f, fexists := summarizeFuncs[fname]
+	if !fexists {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf("invalid func %s", fname))
+	}
+
+	metaSeries := make(map[string][]*ts.Series)
+	for _, s := range series.Values {
+		var (
+			seriesTags = seriesNameTags(s.Name())
+			groupTags  = make(map[string]string, len(tags)+1)
+		)
+		groupTags[graphite.TaggedNameTag] = fname
+		for _, tag := range tags {
+			groupTags[tag] = seriesTags[tag]
+		}
+
+		key := graphite.TaggedName(groupTags)
+		metaSeries[key] = append(metaSeries[key], s)
+	}
+
+	newSeries := make([]*ts.Series, 0, len(metaSeries))
+	for key, series := range metaSeries {
+		seriesList := ts.SeriesList{Values: series}
+		output, err := combineSeries(ctx, multiplePathSpecs(seriesList), key, f.consolidationFunc)
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+		output.Values[0].Specification = f.specificationFunc(seriesList)
+		newSeries = append(newSeries, output.Values...)
+	}
+
+	r := ts.SeriesList(series)
+
+	r.Values = newSeries
+
+	// Ranging over hash map to create results destroys
+	// any sort order on the incoming series list
+	r.SortApplied = false
+
+	return r, nil
+}
+
 // combineSeries combines multiple series into a single series using a
 // consolidation func.  If the series use different time intervals, the
 // coarsest time will apply.
@@ -414,3 +450,527 @@ func countSeries(ctx *common.Context, seriesList multiplePathSpecs) (ts.SeriesLi
 	r.Values = count.Values
 	return r, nil
 }
+
+// aggregationFunc reduces a row of values, which may contain NaNs, to a
+// single value.
+type aggregationFunc func(values []float64) float64
+
+var (
+	aggregationFuncs = map[string]aggregationFunc{
+		"average":  aggregateAverage,
+		"avg_zero": aggregateAverageZero,
+		"median":   aggregateMedian,
+		"sum":      aggregateSum,
+		"min":      aggregateMin,
+		"max":      aggregateMax,
+		"diff":     aggregateDiff,
+		"stddev":   aggregateStdDev,
+		"count":    aggregateCount,
+		"range":    aggregateRange,
+		"multiply": aggregateMultiply,
+		"last":     aggregateLast,
+
+		// aliases
+		"avg":     aggregateAverage,
+		"total":   aggregateSum,
+		"rangeOf": aggregateRange,
+		"current": aggregateLast,
+	}
+)
+
+// getAggregationFunc returns the aggregation function with the given name,
+// which may also be given in its series form such as sumSeries, along with
+// the name stripped of any Series suffix.
+func getAggregationFunc(fname string) (aggregationFunc, string, error) {
+	fname = strings.TrimSuffix(fname, "Series")
+	f, exists := aggregationFuncs[fname]
+	if !exists {
+		return nil, "", errors.NewInvalidParamsError(fmt.Errorf("invalid func %s", fname))
+	}
+
+	return f, fname, nil
+}
+
+// safeValues returns the values which are not NaN.
+func safeValues(values []float64) []float64 {
+	safe := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			safe = append(safe, v)
+		}
+	}
+
+	return safe
+}
+
+// xFilesFactorSatisfied returns whether the ratio of non NaN values to the
+// total number of values is at least the xFilesFactor.
+func xFilesFactorSatisfied(values []float64, xFilesFactor float64) bool {
+	nonNaN := 0
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			nonNaN++
+		}
+	}
+
+	if nonNaN == 0 {
+		return false
+	}
+
+	return float64(nonNaN)/float64(len(values)) >= xFilesFactor
+}
+
+func aggregateAverage(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	return aggregateSum(safe) / float64(len(safe))
+}
+
+func aggregateAverageZero(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	return aggregateSum(values) / float64(len(values))
+}
+
+func aggregateMedian(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	sort.Float64s(safe)
+	mid := len(safe) / 2
+	if len(safe)%2 == 0 {
+		return (safe[mid-1] + safe[mid]) / 2
+	}
+
+	return safe[mid]
+}
+
+func aggregateSum(values []float64) float64 {
+	var (
+		sum    float64
+		nonNaN bool
+	)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += v
+			nonNaN = true
+		}
+	}
+
+	if !nonNaN {
+		return math.NaN()
+	}
+
+	return sum
+}
+
+func aggregateMin(values []float64) float64 {
+	min := math.NaN()
+	for _, v := range values {
+		if math.IsNaN(min) || v < min {
+			min = v
+		}
+	}
+
+	return min
+}
+
+func aggregateMax(values []float64) float64 {
+	max := math.NaN()
+	for _, v := range values {
+		if math.IsNaN(max) || v > max {
+			max = v
+		}
+	}
+
+	return max
+}
+
+// aggregateDiff subtracts all but the first value from the first value.
+func aggregateDiff(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	diff := safe[0]
+	for _, v := range safe[1:] {
+		diff -= v
+	}
+
+	return diff
+}
+
+func aggregateStdDev(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	avg := aggregateAverage(safe)
+	var sum float64
+	for _, v := range safe {
+		sum += (v - avg) * (v - avg)
+	}
+
+	return math.Sqrt(sum / float64(len(safe)))
+}
+
+func aggregateCount(values []float64) float64 {
+	return float64(len(safeValues(values)))
+}
+
+func aggregateRange(values []float64) float64 {
+	return aggregateMax(values) - aggregateMin(values)
+}
+
+func aggregateMultiply(values []float64) float64 {
+	safe := safeValues(values)
+	if len(safe) == 0 {
+		return math.NaN()
+	}
+
+	product := 1.0
+	for _, v := range safe {
+		product *= v
+	}
+
+	return product
+}
+
+func aggregateLast(values []float64) float64 {
+	for i := len(values) - 1; i >= 0; i-- {
+		if !math.IsNaN(values[i]) {
+			return values[i]
+		}
+	}
+
+	return math.NaN()
+}
+
+// aggregateSeriesValues combines multiple series into a single series by
+// applying the aggregation function to the values of the series at each
+// point.  If the series use different time intervals, the coarsest time will
+// apply.
+func aggregateSeriesValues(
+	ctx *common.Context,
+	series ts.SeriesList,
+	name string,
+	f aggregationFunc,
+	xFilesFactor float64,
+) (*ts.Series, error) {
+	normalized, start, _, millisPerStep, err := common.Normalize(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+
+	numSteps := normalized.Values[0].Len()
+	for _, s := range normalized.Values[1:] {
+		numSteps = min(numSteps, s.Len())
+	}
+
+	vals := ts.NewValues(ctx, millisPerStep, numSteps)
+	row := make([]float64, len(normalized.Values))
+	for i := 0; i < numSteps; i++ {
+		for j, s := range normalized.Values {
+			row[j] = s.ValueAt(i)
+		}
+		if xFilesFactorSatisfied(row, xFilesFactor) {
+			vals.SetValueAt(i, f(row))
+		}
+	}
+
+	return ts.NewSeries(ctx, name, start, vals), nil
+}
+
+// aggregate combines a list of series into a single series using the given
+// function, one of average, avg_zero, median, sum, min, max, diff, stddev,
+// count, range, multiply or last. Points where the ratio of non null values is
+// below the xFilesFactor are null.
+//
+//    &target=aggregate(host.cpu-[0-7].cpu-{user,system}.value, "sum")
+func aggregate(
+	ctx *common.Context,
+	series singlePathSpec,
+	fname string,
+	xFilesFactor float64,
+) (ts.SeriesList, error) {
+	f, fname, err := getAggregationFunc(fname)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	if len(series.Values) == 0 {
+		return ts.SeriesList(series), nil
+	}
+
+	name := wrapPathExpr(fname+"Series", ts.SeriesList(series))
+	result, err := aggregateSeriesValues(ctx, ts.SeriesList(series), name, f, xFilesFactor)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	r := ts.SeriesList(series)
+	r.Values = []*ts.Series{result}
+	return r, nil
+}
+
+// aggregateWithWildcards splits the given set of series into sub-groupings
+// based on wildcard matches in the hierarchy, then aggregates the values in
+// each grouping using the given function
+//
+//    &target=aggregateWithWildcards(host.cpu-[0-7].cpu-{user,system}.value, "sum", 1)
+func aggregateWithWildcards(
+	ctx *common.Context,
+	series singlePathSpec,
+	fname string,
+	positions ...int,
+) (ts.SeriesList, error) {
+	f, _, err := getAggregationFunc(fname)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	keys, groups := groupByWildcards(ts.SeriesList(series), positions)
+	newSeries := make([]*ts.Series, 0, len(keys))
+	for _, key := range keys {
+		output, err := aggregateSeriesValues(ctx, ts.SeriesList{Values: groups[key]}, key, f, 0)
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+		newSeries = append(newSeries, output)
+	}
+
+	r := ts.SeriesList(series)
+	r.Values = newSeries
+	r.SortApplied = false
+	return r, nil
+}
+
+// groupByWildcards groups series by their names with the nodes at the given
+// positions removed, returning the group keys in the order first seen.
+func groupByWildcards(series ts.SeriesList, positions []int) ([]string, map[string][]*ts.Series) {
+	var (
+		keys      []string
+		groups    = make(map[string][]*ts.Series)
+		wildcards = make(map[int]struct{})
+	)
+
+	for _, position := range positions {
+		wildcards[position] = struct{}{}
+	}
+
+	for _, series := range series.Values {
+		var (
+			parts    = strings.Split(series.Name(), ".")
+			newParts = make([]string, 0, len(parts))
+		)
+		for i, part := range parts {
+			if _, wildcard := wildcards[i]; !wildcard {
+				newParts = append(newParts, part)
+			}
+		}
+
+		newName := strings.Join(newParts, ".")
+		if _, exists := groups[newName]; !exists {
+			keys = append(keys, newName)
+		}
+		groups[newName] = append(groups[newName], series)
+	}
+
+	return keys, groups
+}
+
+// applyByNode takes a seriesList and applies the template function to each
+// group of series sharing the same path up to the given node, with each % in
+// the template replaced by that path.
+//
+//    &target=applyByNode(servers.*.disk.bytes_free,1,"divideSeries(%.disk.bytes_free,sumSeries(%.disk.bytes_*))")
+//
+//  Would return the fraction of free disk space for each server. If newName is
+//  given, the results are renamed to it with each % replaced by the path.
+func applyByNode(
+	ctx *common.Context,
+	series singlePathSpec,
+	nodeNum int,
+	templateFunction string,
+	newName string,
+) (ts.SeriesList, error) {
+	var (
+		prefixes []string
+		seen     = make(map[string]struct{})
+	)
+	for _, s := range series.Values {
+		parts := strings.Split(s.Name(), ".")
+		if nodeNum < 0 || nodeNum >= len(parts) {
+			err := errors.NewInvalidParamsError(fmt.Errorf(
+				"could not apply %s by node %d; not enough parts", s.Name(), nodeNum))
+			return ts.SeriesList{}, err
+		}
+
+		prefix := strings.Join(parts[:nodeNum+1], ".")
+		if _, exists := seen[prefix]; exists {
+			continue
+		}
+		seen[prefix] = struct{}{}
+		prefixes = append(prefixes, prefix)
+	}
+
+	newSeries := make([]*ts.Series, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		expr, err := compile(strings.Replace(templateFunction, "%", prefix, -1))
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		output, err := expr.Execute(ctx)
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		for _, s := range output.Values {
+			if newName != "" {
+				s = s.RenamedTo(strings.Replace(newName, "%", prefix, -1))
+			}
+			newSeries = append(newSeries, s)
+		}
+	}
+
+	r := ts.SeriesList(series)
+	r.Values = newSeries
+	r.SortApplied = false
+	return r, nil
+}
+
+// mapSeries groups the series by the given nodes, for use with reduceSeries.
+// Series sharing the same nodes are returned next to each other, with the
+// groups in the order first seen.
+//
+//    &target=mapSeries(servers.*.cpu.*,1)
+func mapSeries(_ *common.Context, series singlePathSpec, mapNodes ...int) (ts.SeriesList, error) {
+	var (
+		keys   []string
+		groups = make(map[string][]*ts.Series)
+	)
+	for _, s := range series.Values {
+		parts := strings.Split(s.Name(), ".")
+		keyParts := make([]string, 0, len(mapNodes))
+		for _, node := range mapNodes {
+			if node < 0 || node >= len(parts) {
+				err := errors.NewInvalidParamsError(fmt.Errorf(
+					"could not map %s by node %d; not enough parts", s.Name(), node))
+				return ts.SeriesList{}, err
+			}
+			keyParts = append(keyParts, parts[node])
+		}
+
+		key := strings.Join(keyParts, ".")
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], s)
+	}
+
+	newSeries := make([]*ts.Series, 0, len(series.Values))
+	for _, key := range keys {
+		newSeries = append(newSeries, groups[key]...)
+	}
+
+	r := ts.SeriesList(series)
+	r.Values = newSeries
+	r.SortApplied = false
+	return r, nil
+}
+
+// reduceSeries reduces each group of series sharing all nodes but the reduce
+// node by calling the reduce function with the series whose reduce node
+// matches each of the reduce matchers, in order.
+//
+//    &target=reduceSeries(mapSeries(servers.*.disk.bytes_{free,used},1),"asPercent",3,"bytes_used","bytes_free")
+//
+//  Would return the percentage of disk used for each server, named like
+//  servers.server1.disk.reduce.asPercent. Groups missing a series for any of
+//  the matchers are skipped.
+func reduceSeries(
+	ctx *common.Context,
+	series singlePathSpec,
+	reduceFunction string,
+	reduceNode int,
+	reduceMatchers ...string,
+) (ts.SeriesList, error) {
+	f := findFunction(reduceFunction)
+	if f == nil {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(
+			fmt.Errorf("invalid func %s", reduceFunction))
+	}
+
+	var (
+		keys   []string
+		groups = make(map[string][]*ts.Series)
+	)
+	for _, s := range series.Values {
+		parts := strings.Split(s.Name(), ".")
+		if reduceNode < 0 || reduceNode >= len(parts) {
+			err := errors.NewInvalidParamsError(fmt.Errorf(
+				"could not reduce %s by node %d; not enough parts", s.Name(), reduceNode))
+			return ts.SeriesList{}, err
+		}
+
+		keyParts := make([]string, 0, len(parts)+1)
+		keyParts = append(keyParts, parts[:reduceNode]...)
+		keyParts = append(keyParts, "reduce", reduceFunction)
+		keyParts = append(keyParts, parts[reduceNode+1:]...)
+		key := strings.Join(keyParts, ".")
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+			groups[key] = make([]*ts.Series, len(reduceMatchers))
+		}
+
+		for i, matcher := range reduceMatchers {
+			if parts[reduceNode] == matcher {
+				groups[key][i] = s
+			}
+		}
+	}
+
+	newSeries := make([]*ts.Series, 0, len(keys))
+	for _, key := range keys {
+		args := make([]interface{}, 0, len(reduceMatchers))
+		for _, s := range groups[key] {
+			if s == nil {
+				break
+			}
+			args = append(args, ts.SeriesList{Values: []*ts.Series{s}})
+		}
+		if len(args) != len(reduceMatchers) {
+			continue
+		}
+
+		output, err := f.call(ctx, args)
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		reduced, ok := output.(ts.SeriesList)
+		if !ok {
+			return ts.SeriesList{}, errors.NewInvalidParamsError(
+				fmt.Errorf("func %s cannot be used to reduce series", reduceFunction))
+		}
+		if reduced.Len() == 0 {
+			continue
+		}
+		newSeries = append(newSeries, reduced.Values[0].RenamedTo(key))
+	}
+
+	r := ts.SeriesList(series)
+	r.Values = newSeries
+	r.SortApplied = false
+	return r, nil
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -395,6 +396,73 @@ func TestGroupByNode(t *testing.T) {
 	}
 }
 
+func TestGroupByTags(t *testing.T) {
+	var (
+		start, _ = time.Parse(time.RFC1123, "Mon, 27 Jul 2015 19:41:19 GMT")
+		end, _   = time.Parse(time.RFC1123, "Mon, 27 Jul 2015 19:43:19 GMT")
+		ctx      = common.NewContext(common.ContextOptions{Start: start, End: end})
+		inputs   = []*ts.Series{
+			ts.NewSeries(ctx, "cpu.load;dc=x;host=a", start,
+				ts.NewConstantValues(ctx, 2, 12, 10000)),
+			ts.NewSeries(ctx, "cpu.load;dc=x;host=b", start,
+				ts.NewConstantValues(ctx, 4, 12, 10000)),
+			ts.NewSeries(ctx, "cpu.load;dc=y;host=c", start,
+				ts.NewConstantValues(ctx, 6, 12, 10000)),
+			ts.NewSeries(ctx, "cpu.idle;dc=y;host=c", start,
+				ts.NewConstantValues(ctx, 8, 12, 10000)),
+		}
+	)
+	defer ctx.Close()
+
+	type result struct {
+		name      string
+		sumOfVals float64
+	}
+
+	tests := []struct {
+		fname           string
+		tags            []string
+		expectedResults []result
+	}{
+		{"sumSeries", []string{"dc"}, []result{
+			{"sumSeries;dc=x", (2 + 4) * 12},
+			{"sumSeries;dc=y", (6 + 8) * 12},
+		}},
+		{"max", []string{"name", "dc"}, []result{
+			{"cpu.idle;dc=y", 8 * 12},
+			{"cpu.load;dc=x", 4 * 12},
+			{"cpu.load;dc=y", 6 * 12},
+		}},
+		{"min", []string{"rack"}, []result{
+			{"min;rack=", 2 * 12},
+		}},
+	}
+
+	for _, test := range tests {
+		outSeries, err := groupByTags(ctx, singlePathSpec{
+			Values: inputs,
+		}, test.fname, test.tags...)
+		require.NoError(t, err)
+		require.Equal(t, len(test.expectedResults), len(outSeries.Values))
+
+		outSeries, _ = sortByName(ctx, singlePathSpec(outSeries))
+
+		for i, expected := range test.expectedResults {
+			series := outSeries.Values[i]
+			assert.Equal(t, expected.name, series.Name(),
+				"wrong name for %v %s (%d)", test.tags, test.fname, i)
+			assert.Equal(t, expected.sumOfVals, series.SafeSum(),
+				"wrong result for %v %s (%d)", test.tags, test.fname, i)
+		}
+	}
+
+	_, err := groupByTags(ctx, singlePathSpec{Values: inputs}, "sumSeries")
+	require.Error(t, err)
+
+	_, err = groupByTags(ctx, singlePathSpec{Values: inputs}, "foo", "dc")
+	require.Error(t, err)
+}
+
 func TestWeightedAverage(t *testing.T) {
 	ctx, _ := newConsolidationTestSeries()
 	defer ctx.Close()
@@ -453,3 +521,196 @@ func TestCountSeries(t *testing.T) {
 	common.CompareOutputsAndExpected(t, input[1].MillisPerStep(), input[1].StartTime(),
 		[]common.TestSeries{expected}, results.Values)
 }
+
+func TestAggregate(t *testing.T) {
+	ctx, _ := newConsolidationTestSeries()
+	defer ctx.Close()
+
+	var (
+		nan   = math.NaN()
+		start = consolidationStartTime
+		step  = 10000
+		input = generateSeriesList(ctx, start, []common.TestSeries{
+			{"a", []float64{1, nan, 3, 4}},
+			{"b", []float64{2, nan, nan, 8}},
+			{"c", []float64{3, nan, 6, nan}},
+		}, step)
+	)
+
+	tests := []struct {
+		fname        string
+		xFilesFactor float64
+		expected     common.TestSeries
+	}{
+		{"sum", 0, common.TestSeries{"sumSeries(a,b,c)", []float64{6, nan, 9, 12}}},
+		{"sumSeries", 0, common.TestSeries{"sumSeries(a,b,c)", []float64{6, nan, 9, 12}}},
+		{"total", 0, common.TestSeries{"totalSeries(a,b,c)", []float64{6, nan, 9, 12}}},
+		{"average", 0, common.TestSeries{"averageSeries(a,b,c)", []float64{2, nan, 4.5, 6}}},
+		{"avg_zero", 0, common.TestSeries{"avg_zeroSeries(a,b,c)", []float64{2, nan, 3, 4}}},
+		{"median", 0, common.TestSeries{"medianSeries(a,b,c)", []float64{2, nan, 4.5, 6}}},
+		{"min", 0, common.TestSeries{"minSeries(a,b,c)", []float64{1, nan, 3, 4}}},
+		{"max", 0, common.TestSeries{"maxSeries(a,b,c)", []float64{3, nan, 6, 8}}},
+		{"diff", 0, common.TestSeries{"diffSeries(a,b,c)", []float64{-4, nan, -3, -4}}},
+		{"stddev", 0, common.TestSeries{"stddevSeries(a,b,c)", []float64{math.Sqrt(2.0 / 3), nan, 1.5, 2}}},
+		{"count", 0, common.TestSeries{"countSeries(a,b,c)", []float64{3, nan, 2, 2}}},
+		{"range", 0, common.TestSeries{"rangeSeries(a,b,c)", []float64{2, nan, 3, 4}}},
+		{"multiply", 0, common.TestSeries{"multiplySeries(a,b,c)", []float64{6, nan, 18, 32}}},
+		{"last", 0, common.TestSeries{"lastSeries(a,b,c)", []float64{3, nan, 6, 8}}},
+		{"sum", 0.7, common.TestSeries{"sumSeries(a,b,c)", []float64{6, nan, nan, nan}}},
+	}
+
+	for _, test := range tests {
+		output, err := aggregate(ctx, singlePathSpec{Values: input}, test.fname, test.xFilesFactor)
+		require.NoError(t, err, test.fname)
+		common.CompareOutputsAndExpected(t, step, start,
+			[]common.TestSeries{test.expected}, output.Values)
+	}
+
+	_, err := aggregate(ctx, singlePathSpec{Values: input}, "foo", 0)
+	require.Error(t, err)
+
+	output, err := aggregate(ctx, singlePathSpec{}, "sum", 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, output.Len())
+}
+
+func TestAggregateWithWildcards(t *testing.T) {
+	ctx, _ := newConsolidationTestSeries()
+	defer ctx.Close()
+
+	input := []common.TestSeries{
+		{"web.host-1.avg-response.value", []float64{70.0, 20.0, 30.0, 40.0, 50.0}},
+		{"web.host-2.avg-response.value", []float64{20.0, 30.0, 40.0, 50.0, 60.0}},
+		{"web.host-3.avg-response.value", []float64{30.0, 40.0, 80.0, 60.0, 70.0}},
+		{"web.host-4.num-requests.value", []float64{10.0, 10.0, 15.0, 10.0, 15.0}},
+	}
+	expected := []common.TestSeries{
+		{"web.avg-response", []float64{30.0, 30.0, 40.0, 50.0, 60.0}},
+		{"web.num-requests", []float64{10.0, 10.0, 15.0, 10.0, 15.0}},
+	}
+
+	start := consolidationStartTime
+	step := 12000
+	timeSeries := generateSeriesList(ctx, start, input, step)
+	output, err := aggregateWithWildcards(ctx, singlePathSpec{
+		Values: timeSeries,
+	}, "median", 1, 3)
+	require.NoError(t, err)
+	common.CompareOutputsAndExpected(t, step, start, expected, output.Values)
+
+	_, err = aggregateWithWildcards(ctx, singlePathSpec{
+		Values: timeSeries,
+	}, "foo", 1)
+	require.Error(t, err)
+}
+
+func newDiskTestEngine(values map[string]float64) mockEngine {
+	return mockEngine{fn: func(
+		ctx context.Context,
+		query string,
+		start, end time.Time,
+		timeout time.Duration,
+	) (*storage.FetchResult, error) {
+		var series []*ts.Series
+		for name, value := range values {
+			if name == query || strings.HasPrefix(name, strings.TrimSuffix(query, "*")) &&
+				strings.HasSuffix(query, "*") {
+				series = append(series, ts.NewSeries(ctx, name, start,
+					ts.NewConstantValues(ctx, value, 3, 10000)))
+			}
+		}
+		sort.Sort(TimeSeriesPtrVector(series))
+		return storage.NewFetchResult(ctx, series), nil
+	}}
+}
+
+func TestApplyByNode(t *testing.T) {
+	values := map[string]float64{
+		"servers.s1.disk.bytes_free": 25,
+		"servers.s1.disk.bytes_used": 75,
+		"servers.s2.disk.bytes_free": 10,
+		"servers.s2.disk.bytes_used": 40,
+	}
+
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+	ctx.Engine = newDiskTestEngine(values)
+
+	input := []*ts.Series{
+		ts.NewSeries(ctx, "servers.s1.disk.bytes_free", ctx.StartTime,
+			ts.NewConstantValues(ctx, 25, 3, 10000)),
+		ts.NewSeries(ctx, "servers.s2.disk.bytes_free", ctx.StartTime,
+			ts.NewConstantValues(ctx, 10, 3, 10000)),
+	}
+
+	template := "divideSeries(%.disk.bytes_free,sumSeries(%.disk.bytes_*))"
+	output, err := applyByNode(ctx, singlePathSpec{Values: input}, 1, template, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, output.Len())
+	assert.Equal(t, "divideSeries(servers.s1.disk.bytes_free,sumSeries(servers.s1.disk.bytes_*))",
+		output.Values[0].Name())
+	assert.Equal(t, []float64{0.25, 0.25, 0.25}, output.Values[0].SafeValues())
+
+	output, err = applyByNode(ctx, singlePathSpec{Values: input}, 1, template, "%.disk.pct_free")
+	require.NoError(t, err)
+	require.Equal(t, 2, output.Len())
+	assert.Equal(t, "servers.s1.disk.pct_free", output.Values[0].Name())
+	assert.Equal(t, []float64{0.25, 0.25, 0.25}, output.Values[0].SafeValues())
+	assert.Equal(t, "servers.s2.disk.pct_free", output.Values[1].Name())
+	assert.Equal(t, []float64{0.2, 0.2, 0.2}, output.Values[1].SafeValues())
+
+	_, err = applyByNode(ctx, singlePathSpec{Values: input}, 5, template, "")
+	require.Error(t, err)
+}
+
+func TestMapSeries(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{"servers.s1.cpu.user", []float64{1}},
+		{"servers.s2.cpu.user", []float64{2}},
+		{"servers.s1.cpu.system", []float64{3}},
+	}, 10000)
+
+	output, err := mapSeries(ctx, singlePathSpec{Values: input}, 1)
+	require.NoError(t, err)
+
+	var names []string
+	for _, series := range output.Values {
+		names = append(names, series.Name())
+	}
+	assert.Equal(t, []string{
+		"servers.s1.cpu.user",
+		"servers.s1.cpu.system",
+		"servers.s2.cpu.user",
+	}, names)
+
+	_, err = mapSeries(ctx, singlePathSpec{Values: input}, 4)
+	require.Error(t, err)
+}
+
+func TestReduceSeries(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{"servers.s1.disk.bytes_used", []float64{25, 50}},
+		{"servers.s1.disk.bytes_total", []float64{100, 100}},
+		{"servers.s2.disk.bytes_used", []float64{10, 20}},
+		{"servers.s2.disk.bytes_total", []float64{40, 40}},
+		{"servers.s3.disk.bytes_used", []float64{10, 20}},
+	}, 10000)
+
+	output, err := reduceSeries(ctx, singlePathSpec{Values: input},
+		"divideSeries", 3, "bytes_used", "bytes_total")
+	require.NoError(t, err)
+	common.CompareOutputsAndExpected(t, 10000, ctx.StartTime, []common.TestSeries{
+		{"servers.s1.disk.reduce.divideSeries", []float64{0.25, 0.5}},
+		{"servers.s2.disk.reduce.divideSeries", []float64{0.25, 0.5}},
+	}, output.Values)
+
+	_, err = reduceSeries(ctx, singlePathSpec{Values: input},
+		"foo", 3, "bytes_used", "bytes_total")
+	require.Error(t, err)
+}
@@ -21,7 +21,12 @@
 package native
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/m3db/m3/src/query/graphite/common"
+	"github.com/m3db/m3/src/query/graphite/errors"
+	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/graphite/ts"
 )
 
@@ -47,3 +52,67 @@ func aliasByNode(ctx *common.Context, seriesList singlePathSpec, nodes ...int) (
 func aliasSub(ctx *common.Context, input singlePathSpec, search, replace string) (ts.SeriesList, error) {
 	return common.AliasSub(ctx, ts.SeriesList(input), search, replace)
 }
+
+// aliasByTags renames a tagged time series according to the values of the given
+// tags, where each tag is either a tag name or the index of a node in the path.
+//
+//    &target=aliasByTags(seriesByTag('name=cpu.load'),'host',1)
+//
+// Would alias cpu.load;host=a as a.load.
+func aliasByTags(ctx *common.Context, seriesList singlePathSpec, tags ...genericInterface) (ts.SeriesList, error) {
+	renamed := make([]*ts.Series, 0, len(seriesList.Values))
+	for _, series := range seriesList.Values {
+		var (
+			seriesTags = seriesNameTags(series.Name())
+			nameParts  = strings.Split(seriesTags[graphite.TaggedNameTag], ".")
+			newParts   = make([]string, 0, len(tags))
+		)
+		for _, tag := range tags {
+			switch t := tag.(type) {
+			case string:
+				if v, ok := seriesTags[t]; ok {
+					newParts = append(newParts, v)
+				}
+			case float64, int:
+				node := toInt(t)
+				// Graphite supports negative indexing.
+				if node < 0 {
+					node += len(nameParts)
+				}
+				if node < 0 || node >= len(nameParts) {
+					continue
+				}
+				newParts = append(newParts, nameParts[node])
+			default:
+				return ts.SeriesList{}, errors.NewInvalidParamsError(
+					fmt.Errorf("invalid tag %v: must be a tag name or node index", tag))
+			}
+		}
+
+		renamed = append(renamed, series.RenamedTo(strings.Join(newParts, ".")))
+	}
+
+	r := ts.SeriesList(seriesList)
+	r.Values = renamed
+	return r, nil
+}
+
+// seriesNameTags returns the tags of a possibly tagged series name, ignoring any
+// functions which have wrapped the name.
+func seriesNameTags(name string) map[string]string {
+	left := strings.LastIndex(name, "(") + 1
+	name = name[left:]
+	if right := strings.IndexAny(name, ",)"); right != -1 {
+		name = name[:right]
+	}
+
+	return graphite.TaggedNameTags(name)
+}
+
+func toInt(v interface{}) int {
+	if n, ok := v.(float64); ok {
+		return int(n)
+	}
+
+	return v.(int)
+}
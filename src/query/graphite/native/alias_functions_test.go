@@ -194,6 +194,39 @@ func TestAliasByNode(t *testing.T) {
 	assert.Equal(t, "P75", results.Values[2].Name())
 }
 
+func TestAliasByTags(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	now := time.Now()
+	values := ts.NewConstantValues(ctx, 10.0, 1000, 10)
+	series := []*ts.Series{
+		ts.NewSeries(ctx, "cpu.load;dc=x;host=a", now, values),
+		ts.NewSeries(ctx, "scale(cpu.idle;dc=y;host=b,2)", now, values),
+		ts.NewSeries(ctx, "cpu.user", now, values),
+	}
+
+	results, err := aliasByTags(ctx, singlePathSpec{
+		Values: series,
+	}, "host", float64(1), "name")
+	require.NoError(t, err)
+	require.Equal(t, len(series), results.Len())
+	assert.Equal(t, "a.load.cpu.load", results.Values[0].Name())
+	assert.Equal(t, "b.idle.cpu.idle", results.Values[1].Name())
+	assert.Equal(t, "user.cpu.user", results.Values[2].Name())
+
+	results, err = aliasByTags(ctx, singlePathSpec{
+		Values: series,
+	}, -2, "dc")
+	require.NoError(t, err)
+	assert.Equal(t, "cpu.x", results.Values[0].Name())
+	assert.Equal(t, "cpu.y", results.Values[1].Name())
+	assert.Equal(t, "cpu", results.Values[2].Name())
+
+	_, err = aliasByTags(ctx, singlePathSpec{Values: series}, true)
+	require.Error(t, err)
+}
+
 func TestAliasByNodeWithComposition(t *testing.T) {
 	ctx := common.NewTestContext()
 	defer ctx.Close()
@@ -32,6 +32,7 @@ import (
 
 	"github.com/m3db/m3/src/query/graphite/common"
 	"github.com/m3db/m3/src/query/graphite/errors"
+	"github.com/m3db/m3/src/query/graphite/graphite"
 	"github.com/m3db/m3/src/query/graphite/ts"
 )
 
@@ -185,6 +186,37 @@ func identity(ctx *common.Context, name string) (ts.SeriesList, error) {
 	return common.Identity(ctx, name)
 }
 
+// seriesByTag returns the tagged series matching all of the given tag
+// expressions, each of the form tag=value, tag!=value, tag=~regex or
+// tag!=~regex. The path of a series is matched by the name tag.
+//
+//    &target=seriesByTag('name=cpu.load','host=~web-','dc!=test')
+func seriesByTag(ctx *common.Context, tagExpressions ...string) (ts.SeriesList, error) {
+	if len(tagExpressions) == 0 {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(
+			fmt.Errorf("seriesByTag requires at least one tag expression"))
+	}
+
+	for _, expr := range tagExpressions {
+		if _, err := graphite.ParseTagExpression(expr); err != nil {
+			return ts.SeriesList{}, err
+		}
+	}
+
+	query := graphite.SeriesByTagQuery(tagExpressions)
+	result, err := ctx.Engine.FetchByQuery(ctx, query, ctx.StartTime,
+		ctx.EndTime, ctx.Timeout)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	for _, r := range result.SeriesList {
+		r.Specification = query
+	}
+
+	return ts.SeriesList{Values: result.SeriesList}, nil
+}
+
 // limit takes one metric or a wildcard seriesList followed by an integer N, and draws
 // the first N metrics.
 func limit(_ *common.Context, series singlePathSpec, n int) (ts.SeriesList, error) {
@@ -241,6 +273,205 @@ func timeShift(
 	}, nil
 }
 
+// timeStack draws the selected metrics shifted back in time by each multiple of the time
+// shift unit from timeShiftStart up to, but not including, timeShiftEnd, stacked on top of
+// the original time range.
+//
+//    &target=timeStack(Sales.widgets.largeBlue,"1d",0,7)
+func timeStack(
+	ctx *common.Context,
+	input singlePathSpec,
+	timeShiftUnit string,
+	timeShiftStart, timeShiftEnd int,
+) (ts.SeriesList, error) {
+	if !(strings.HasPrefix(timeShiftUnit, "+") || strings.HasPrefix(timeShiftUnit, "-")) {
+		timeShiftUnit = "-" + timeShiftUnit
+	}
+
+	delta, err := common.ParseInterval(timeShiftUnit)
+	if err != nil {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf(
+			"invalid timeStack parameter %s: %v", timeShiftUnit, err))
+	}
+
+	if len(input.Values) == 0 {
+		return ts.SeriesList(input), nil
+	}
+
+	// NB: the series all share the same path expression, so re-evaluating
+	// the path expression of the first fetches all of them again.
+	expr, err := compile(input.Values[0].Specification)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+
+	var results []*ts.Series
+	for shift := timeShiftStart; shift < timeShiftEnd; shift++ {
+		innerDelta := delta * time.Duration(shift)
+		opts := common.NewChildContextOptions()
+		opts.AdjustTimeRange(innerDelta, innerDelta, 0, 0)
+		shifted, err := expr.Execute(ctx.NewChildContext(opts))
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		for _, series := range shifted.Values {
+			name := fmt.Sprintf("timeShift(%s, %s, %d)", series.Name(), timeShiftUnit, shift)
+			results = append(results, series.Shift(-innerDelta).RenamedTo(name))
+		}
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	r.SortApplied = false
+	return r, nil
+}
+
+// timeSlice takes one metric or a wildcard seriesList, and returns the values only between
+// the start and end times given, setting the values outside of them to None.
+//
+//    &target=timeSlice(network.core.port1,"00:00 20140101","11:59 20140630")
+func timeSlice(
+	ctx *common.Context,
+	input singlePathSpec,
+	startSliceAt, endSliceAt string,
+) (ts.SeriesList, error) {
+	now := time.Now()
+	start, err := graphite.ParseTime(startSliceAt, now, 0)
+	if err != nil {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf(
+			"invalid timeSlice start %s: %v", startSliceAt, err))
+	}
+	end, err := graphite.ParseTime(endSliceAt, now, 0)
+	if err != nil {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf(
+			"invalid timeSlice end %s: %v", endSliceAt, err))
+	}
+
+	results := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		numSteps := series.Len()
+		vals := ts.NewValues(ctx, series.MillisPerStep(), numSteps)
+		for i := 0; i < numSteps; i++ {
+			t := series.StartTimeForStep(i)
+			if !t.Before(start) && !t.After(end) {
+				vals.SetValueAt(i, series.ValueAt(i))
+			}
+		}
+
+		name := fmt.Sprintf("timeSlice(%s, %d, %d)", series.Name(), start.Unix(), end.Unix())
+		results = append(results, ts.NewSeries(ctx, name, series.StartTime(), vals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// linearRegression graphs the linear regression function by the least squares method,
+// fitted to the values of each series between startSourceAt and endSourceAt, which
+// default to the time range of the query.
+//
+//    &target=linearRegression(Server.instance01.threads.busy,"-1d","-1h")
+func linearRegression(
+	ctx *common.Context,
+	_ singlePathSpec,
+	startSourceAt, endSourceAt string,
+) (*binaryContextShifter, error) {
+	now := time.Now()
+	sourceStart, sourceEnd := ctx.StartTime, ctx.EndTime
+	if startSourceAt != "" {
+		t, err := graphite.ParseTime(startSourceAt, now, 0)
+		if err != nil {
+			return nil, errors.NewInvalidParamsError(fmt.Errorf(
+				"invalid linearRegression start %s: %v", startSourceAt, err))
+		}
+		sourceStart = t
+	}
+	if endSourceAt != "" {
+		t, err := graphite.ParseTime(endSourceAt, now, 0)
+		if err != nil {
+			return nil, errors.NewInvalidParamsError(fmt.Errorf(
+				"invalid linearRegression end %s: %v", endSourceAt, err))
+		}
+		sourceEnd = t
+	}
+
+	contextShiftingFn := func(c *common.Context) *common.Context {
+		opts := common.NewChildContextOptions()
+		opts.AdjustTimeRange(sourceStart.Sub(c.StartTime), sourceEnd.Sub(c.EndTime), 0, 0)
+		childCtx := c.NewChildContext(opts)
+		return childCtx
+	}
+
+	transformerFn := func(sources, original ts.SeriesList) (ts.SeriesList, error) {
+		nameToSource := make(map[string]*ts.Series, sources.Len())
+		for _, source := range sources.Values {
+			nameToSource[source.Name()] = source
+		}
+
+		results := make([]*ts.Series, 0, original.Len())
+		for _, series := range original.Values {
+			source, found := nameToSource[series.Name()]
+			if !found {
+				continue
+			}
+
+			factor, offset, ok := linearRegressionAnalysis(source)
+			if !ok {
+				continue
+			}
+
+			numSteps := series.Len()
+			vals := ts.NewValues(ctx, series.MillisPerStep(), numSteps)
+			for i := 0; i < numSteps; i++ {
+				t := float64(series.StartTimeForStep(i).Unix())
+				vals.SetValueAt(i, offset+t*factor)
+			}
+
+			name := fmt.Sprintf("linearRegression(%s, %d, %d)",
+				series.Name(), sourceStart.Unix(), sourceEnd.Unix())
+			results = append(results, ts.NewSeries(ctx, name, series.StartTime(), vals))
+		}
+
+		original.Values = results
+		return original, nil
+	}
+
+	return &binaryContextShifter{
+		ContextShiftFunc:  contextShiftingFn,
+		BinaryTransformer: transformerFn,
+	}, nil
+}
+
+// linearRegressionAnalysis returns the factor and offset of the least squares fit of the
+// values of the series against their timestamps in seconds, and false if there is none.
+func linearRegressionAnalysis(series *ts.Series) (float64, float64, bool) {
+	var n, sumI, sumV, sumII, sumIV float64
+	for i := 0; i < series.Len(); i++ {
+		v := series.ValueAt(i)
+		if math.IsNaN(v) {
+			continue
+		}
+		fi := float64(i)
+		n++
+		sumI += fi
+		sumV += v
+		sumII += fi * fi
+		sumIV += fi * v
+	}
+
+	denominator := n*sumII - sumI*sumI
+	if denominator == 0 {
+		return 0, 0, false
+	}
+
+	step := float64(series.MillisPerStep()) / millisPerSecond
+	factor := (n*sumIV - sumI*sumV) / denominator / step
+	offset := (sumII*sumV-sumIV*sumI)/denominator - factor*float64(series.StartTime().Unix())
+	return factor, offset, true
+}
+
 // absolute returns the absolute value of each element in the series.
 func absolute(ctx *common.Context, input singlePathSpec) (ts.SeriesList, error) {
 	return transform(ctx, input,
@@ -386,6 +617,46 @@ func keepLastValue(ctx *common.Context, input singlePathSpec, limit int) (ts.Ser
 	return r, nil
 }
 
+// interpolate takes one metric or a wildcard seriesList, and optionally a limit to the number of
+// NaN values to fill. NaN values between two non NaN values are replaced by the values on a line
+// between them. If not specified, limit has a default value of -1, meaning all gaps are filled.
+func interpolate(ctx *common.Context, input singlePathSpec, limit int) (ts.SeriesList, error) {
+	output := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		consecutiveNaNs := 0
+		numSteps := series.Len()
+		vals := ts.NewValues(ctx, series.MillisPerStep(), numSteps)
+		for i := 0; i < numSteps; i++ {
+			value := series.ValueAt(i)
+			vals.SetValueAt(i, value)
+			if i == 0 {
+				continue
+			}
+			if math.IsNaN(value) {
+				consecutiveNaNs++
+				continue
+			}
+			if consecutiveNaNs > 0 && (limit == -1 || consecutiveNaNs <= limit) {
+				prev := series.ValueAt(i - consecutiveNaNs - 1)
+				if !math.IsNaN(prev) {
+					step := (value - prev) / float64(consecutiveNaNs+1)
+					for index := i - consecutiveNaNs; index < i; index++ {
+						vals.SetValueAt(index, prev+step*float64(index-i+consecutiveNaNs+1))
+					}
+				}
+			}
+			consecutiveNaNs = 0
+		}
+		name := fmt.Sprintf("interpolate(%s)", series.Name())
+		newSeries := ts.NewSeries(ctx, name, series.StartTime(), vals)
+		output = append(output, newSeries)
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = output
+	return r, nil
+}
+
 type comparator func(float64, float64) bool
 
 // lessOrEqualFunc checks whether x is less than or equal to y
@@ -526,6 +797,49 @@ func fallbackSeries(_ *common.Context, input singlePathSpec, fallback singlePath
 	return ts.SeriesList(fallback), nil
 }
 
+// useSeriesAbove compares the maximum of each series against the given value. If the
+// maximum is greater than the value, the series name is rewritten by replacing the
+// search regular expression with replace, and the series of the new name is fetched
+// in its place.
+//
+//    &target=useSeriesAbove(ganglia.metric1.reqs,10,"reqs","time")
+func useSeriesAbove(
+	ctx *common.Context,
+	input singlePathSpec,
+	value float64,
+	search, replace string,
+) (ts.SeriesList, error) {
+	re, err := regexp.Compile(search)
+	if err != nil {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(err)
+	}
+
+	results := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		if !(series.SafeMax() > value) {
+			continue
+		}
+
+		expr, err := compile(re.ReplaceAllString(series.Name(), replace))
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		output, err := expr.Execute(ctx)
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+		if output.Len() > 0 {
+			results = append(results, output.Values[0])
+		}
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	r.SortApplied = false
+	return r, nil
+}
+
 // mostDeviant takes one metric or a wildcard seriesList followed by an integer
 // N. Draws the N most deviant metrics.  To find the deviants, the standard
 // deviation (sigma) of each series is taken and ranked.  The top N standard
@@ -551,55 +865,108 @@ func lowestCurrent(_ *common.Context, input singlePathSpec, n int) (ts.SeriesLis
 	return takeByFunction(input, n, sr, ts.Ascending)
 }
 
-// windowSizeFunc calculates window size for moving average calculation
-type windowSizeFunc func(stepSize int) int
+// aggregationReducer returns a SeriesReducer applying the aggregation
+// function to all of the values of a series.
+func aggregationReducer(f aggregationFunc) ts.SeriesReducer {
+	return func(series *ts.Series) float64 {
+		values := make([]float64, series.Len())
+		for i := range values {
+			values[i] = series.ValueAt(i)
+		}
+		return f(values)
+	}
+}
 
-// movingAverage calculates the moving average of a metric (or metrics) over a time interval.
-func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue genericInterface) (*binaryContextShifter, error) {
-	if len(input.Values) == 0 {
-		return nil, nil
+// highest takes one metric or a wildcard seriesList followed by an integer n
+// and an aggregation function.  Out of all metrics passed, draws only the N
+// metrics with the highest value of the function for the time period
+// specified.
+func highest(_ *common.Context, input singlePathSpec, n int, fname string) (ts.SeriesList, error) {
+	f, _, err := getAggregationFunc(fname)
+	if err != nil {
+		return ts.SeriesList{}, err
+	}
+	return takeByFunction(input, n, aggregationReducer(f), ts.Descending)
+}
+
+// lowest takes one metric or a wildcard seriesList followed by an integer n
+// and an aggregation function.  Out of all metrics passed, draws only the N
+// metrics with the lowest value of the function for the time period
+// specified.
+func lowest(_ *common.Context, input singlePathSpec, n int, fname string) (ts.SeriesList, error) {
+	f, _, err := getAggregationFunc(fname)
+	if err != nil {
+		return ts.SeriesList{}, err
 	}
+	return takeByFunction(input, n, aggregationReducer(f), ts.Ascending)
+}
+
+// windowSizeFunc calculates window size for moving average calculation
+type windowSizeFunc func(stepSize int) int
 
-	var delta time.Duration
-	var wf windowSizeFunc
-	var ws string
+// windowSize is a parsed moving window size.
+type windowSize struct {
+	delta time.Duration
+	wf    windowSizeFunc
+	ws    string
+}
 
+// parseWindowSize parses a moving window size given either as a string
+// interval or as a number of points of the series with the largest step.
+func parseWindowSize(windowSizeValue genericInterface, input singlePathSpec) (windowSize, error) {
 	switch windowSizeValue := windowSizeValue.(type) {
 	case string:
 		interval, err := common.ParseInterval(windowSizeValue)
 		if err != nil {
-			return nil, err
+			return windowSize{}, err
 		}
 		if interval <= 0 {
 			err := errors.NewInvalidParamsError(fmt.Errorf(
 				"windowSize must be positive but instead is %v",
 				interval))
-			return nil, err
+			return windowSize{}, err
 		}
-		wf = func(stepSize int) int { return int(int64(delta/time.Millisecond) / int64(stepSize)) }
-		ws = fmt.Sprintf("%q", windowSizeValue)
-		delta = interval
+		return windowSize{
+			delta: interval,
+			wf:    func(stepSize int) int { return int(int64(interval/time.Millisecond) / int64(stepSize)) },
+			ws:    fmt.Sprintf("%q", windowSizeValue),
+		}, nil
 	case float64:
 		windowSizeInt := int(windowSizeValue)
 		if windowSizeInt <= 0 {
 			err := errors.NewInvalidParamsError(fmt.Errorf(
 				"windowSize must be positive but instead is %d",
 				windowSizeInt))
-			return nil, err
+			return windowSize{}, err
 		}
-		wf = func(_ int) int { return windowSizeInt }
-		ws = fmt.Sprintf("%d", windowSizeInt)
 		maxStepSize := input.Values[0].MillisPerStep()
 		for i := 1; i < len(input.Values); i++ {
 			maxStepSize = int(math.Max(float64(maxStepSize), float64(input.Values[i].MillisPerStep())))
 		}
-		delta = time.Duration(maxStepSize*windowSizeInt) * time.Millisecond
+		return windowSize{
+			delta: time.Duration(maxStepSize*windowSizeInt) * time.Millisecond,
+			wf:    func(_ int) int { return windowSizeInt },
+			ws:    fmt.Sprintf("%d", windowSizeInt),
+		}, nil
 	default:
 		err := errors.NewInvalidParamsError(fmt.Errorf(
 			"windowSize must be either a string or an int but instead is a %T",
 			windowSizeValue))
+		return windowSize{}, err
+	}
+}
+
+// movingAverage calculates the moving average of a metric (or metrics) over a time interval.
+func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue genericInterface) (*binaryContextShifter, error) {
+	if len(input.Values) == 0 {
+		return nil, nil
+	}
+
+	window, err := parseWindowSize(windowSizeValue, input)
+	if err != nil {
 		return nil, err
 	}
+	delta, wf, ws := window.delta, window.wf, window.ws
 
 	contextShiftingFn := func(c *common.Context) *common.Context {
 		opts := common.NewChildContextOptions()
@@ -679,6 +1046,124 @@ func movingAverage(ctx *common.Context, input singlePathSpec, windowSizeValue ge
 	}, nil
 }
 
+// movingWindow graphs the result of applying the aggregation function to the
+// preceding datapoints of each point on the graph, over a window given either
+// as a time interval or a number of points. Points whose window has a ratio of
+// non null values below the xFilesFactor are set to None.
+func movingWindow(
+	ctx *common.Context,
+	input singlePathSpec,
+	windowSizeValue genericInterface,
+	fname string,
+	xFilesFactor float64,
+) (*binaryContextShifter, error) {
+	f, fname, err := getAggregationFunc(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(input.Values) == 0 {
+		return nil, nil
+	}
+
+	window, err := parseWindowSize(windowSizeValue, input)
+	if err != nil {
+		return nil, err
+	}
+
+	contextShiftingFn := func(c *common.Context) *common.Context {
+		opts := common.NewChildContextOptions()
+		opts.AdjustTimeRange(0, 0, window.delta, 0)
+		childCtx := c.NewChildContext(opts)
+		return childCtx
+	}
+
+	// NB: graphite names the results after the capitalized function name,
+	// for example movingSum.
+	movingName := "moving" + strings.ToUpper(fname[:1]) + strings.ToLower(fname[1:])
+	bootstrapStartTime, bootstrapEndTime := ctx.StartTime.Add(-window.delta), ctx.StartTime
+	transformerFn := func(bootstrapped, original ts.SeriesList) (ts.SeriesList, error) {
+		bootstrapList, err := combineBootstrapWithOriginal(ctx,
+			bootstrapStartTime, bootstrapEndTime,
+			bootstrapped, singlePathSpec(original))
+		if err != nil {
+			return ts.SeriesList{}, err
+		}
+
+		results := make([]*ts.Series, 0, original.Len())
+		for i, bootstrap := range bootstrapList.Values {
+			series := original.Values[i]
+			stepSize := series.MillisPerStep()
+			windowPoints := window.wf(stepSize)
+			if windowPoints == 0 {
+				err := errors.NewInvalidParamsError(fmt.Errorf(
+					"windowSize should not be smaller than stepSize, windowSize=%v, stepSize=%d",
+					windowSizeValue, stepSize))
+				return ts.SeriesList{}, err
+			}
+
+			numSteps := series.Len()
+			offset := bootstrap.Len() - numSteps
+			vals := ts.NewValues(ctx, series.MillisPerStep(), numSteps)
+			values := make([]float64, windowPoints)
+			for i := 0; i < numSteps; i++ {
+				// skip if the number of points received is less than the number of points
+				// in the lookback window.
+				if offset < windowPoints {
+					continue
+				}
+				for j := range values {
+					values[j] = bootstrap.ValueAt(i + offset - windowPoints + j)
+				}
+				if xFilesFactorSatisfied(values, xFilesFactor) {
+					vals.SetValueAt(i, f(values))
+				}
+			}
+			name := fmt.Sprintf("%s(%s,%s)", movingName, series.Name(), window.ws)
+			newSeries := ts.NewSeries(ctx, name, series.StartTime(), vals)
+			results = append(results, newSeries)
+		}
+
+		original.Values = results
+		return original, nil
+	}
+
+	return &binaryContextShifter{
+		ContextShiftFunc:  contextShiftingFn,
+		BinaryTransformer: transformerFn,
+	}, nil
+}
+
+// movingSum graphs the sum of the preceding datapoints for each point on the graph.
+func movingSum(
+	ctx *common.Context,
+	input singlePathSpec,
+	windowSizeValue genericInterface,
+	xFilesFactor float64,
+) (*binaryContextShifter, error) {
+	return movingWindow(ctx, input, windowSizeValue, "sum", xFilesFactor)
+}
+
+// movingMin graphs the minimum of the preceding datapoints for each point on the graph.
+func movingMin(
+	ctx *common.Context,
+	input singlePathSpec,
+	windowSizeValue genericInterface,
+	xFilesFactor float64,
+) (*binaryContextShifter, error) {
+	return movingWindow(ctx, input, windowSizeValue, "min", xFilesFactor)
+}
+
+// movingMax graphs the maximum of the preceding datapoints for each point on the graph.
+func movingMax(
+	ctx *common.Context,
+	input singlePathSpec,
+	windowSizeValue genericInterface,
+	xFilesFactor float64,
+) (*binaryContextShifter, error) {
+	return movingWindow(ctx, input, windowSizeValue, "max", xFilesFactor)
+}
+
 // totalFunc takes an index and returns a total value for that index
 type totalFunc func(int) float64
 
@@ -900,6 +1385,90 @@ func integral(ctx *common.Context, input singlePathSpec) (ts.SeriesList, error)
 	return r, nil
 }
 
+// integralByInterval shows the sum over time, like integral, with the sum
+// reset to zero at the start of every interval, aligned to the start of the
+// query.
+func integralByInterval(ctx *common.Context, input singlePathSpec, intervalUnit string) (ts.SeriesList, error) {
+	interval, err := common.ParseInterval(intervalUnit)
+	if err != nil {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf(
+			"invalid interval %s: %v", intervalUnit, err))
+	}
+	if interval < 0 {
+		interval = -interval
+	}
+	if interval == 0 {
+		return ts.SeriesList{}, errors.NewInvalidParamsError(fmt.Errorf(
+			"interval must not be zero: %s", intervalUnit))
+	}
+
+	var (
+		intervalMillis = int64(interval / time.Millisecond)
+		startMillis    = ctx.StartTime.UnixNano() / int64(time.Millisecond)
+		results        = make([]*ts.Series, 0, len(input.Values))
+	)
+	for _, series := range input.Values {
+		var (
+			outvals       = ts.NewValues(ctx, series.MillisPerStep(), series.Len())
+			millisPerStep = int64(series.MillisPerStep())
+			currentMillis = series.StartTime().UnixNano() / int64(time.Millisecond)
+			current       float64
+		)
+		for i := 0; i < series.Len(); i++ {
+			// reset the sum when crossing an interval boundary
+			if floorDiv(currentMillis-startMillis, intervalMillis) !=
+				floorDiv(currentMillis-startMillis-millisPerStep, intervalMillis) {
+				current = 0
+			}
+			if n := series.ValueAt(i); !math.IsNaN(n) {
+				current += n
+			}
+			outvals.SetValueAt(i, current)
+			currentMillis += millisPerStep
+		}
+
+		newName := fmt.Sprintf("integralByInterval(%s,'%s')", series.Name(), intervalUnit)
+		results = append(results, ts.NewSeries(ctx, newName, series.StartTime(), outvals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
+// floorDiv divides n by m, rounding towards negative infinity.
+// prerequisite: m is positive.
+func floorDiv(n, m int64) int64 {
+	quotient := n / m
+	if n%m < 0 {
+		quotient--
+	}
+	return quotient
+}
+
+// delay shifts the values of each series by the given number of steps,
+// filling the vacated steps with None. Positive steps delay the values, while
+// negative steps advance them.
+func delay(ctx *common.Context, input singlePathSpec, steps int) (ts.SeriesList, error) {
+	results := make([]*ts.Series, 0, len(input.Values))
+	for _, series := range input.Values {
+		numSteps := series.Len()
+		vals := ts.NewValues(ctx, series.MillisPerStep(), numSteps)
+		for i := 0; i < numSteps; i++ {
+			if j := i - steps; j >= 0 && j < numSteps {
+				vals.SetValueAt(i, series.ValueAt(j))
+			}
+		}
+
+		newName := fmt.Sprintf("delay(%s,%d)", series.Name(), steps)
+		results = append(results, ts.NewSeries(ctx, newName, series.StartTime(), vals))
+	}
+
+	r := ts.SeriesList(input)
+	r.Values = results
+	return r, nil
+}
+
 // This is the opposite of the integral function.  This is useful for taking a
 // running total metric and calculating the delta between subsequent data
 // points.
@@ -1820,13 +2389,21 @@ func threshold(ctx *common.Context, value float64, label string, color string) (
 func init() {
 	// functions - in alpha ordering
 	MustRegisterFunction(absolute)
+	MustRegisterFunction(aggregate).WithDefaultParams(map[uint8]interface{}{
+		3: 0.0, // xFilesFactor
+	})
 	MustRegisterFunction(aggregateLine).WithDefaultParams(map[uint8]interface{}{
 		2: "avg", // f
 	})
+	MustRegisterFunction(aggregateWithWildcards)
 	MustRegisterFunction(alias)
 	MustRegisterFunction(aliasByMetric)
 	MustRegisterFunction(aliasByNode)
+	MustRegisterFunction(aliasByTags)
 	MustRegisterFunction(aliasSub)
+	MustRegisterFunction(applyByNode).WithDefaultParams(map[uint8]interface{}{
+		4: "", // newName
+	})
 	MustRegisterFunction(asPercent).WithDefaultParams(map[uint8]interface{}{
 		2: []*ts.Series(nil), // total
 	})
@@ -1843,6 +2420,7 @@ func init() {
 	MustRegisterFunction(dashed).WithDefaultParams(map[uint8]interface{}{
 		2: 5.0, // dashLength
 	})
+	MustRegisterFunction(delay)
 	MustRegisterFunction(derivative)
 	MustRegisterFunction(diffSeries)
 	MustRegisterFunction(divideSeries)
@@ -1850,6 +2428,11 @@ func init() {
 	MustRegisterFunction(fallbackSeries)
 	MustRegisterFunction(group)
 	MustRegisterFunction(groupByNode)
+	MustRegisterFunction(groupByTags)
+	MustRegisterFunction(highest).WithDefaultParams(map[uint8]interface{}{
+		2: 1,         // n
+		3: "average", // f
+	})
 	MustRegisterFunction(highestAverage)
 	MustRegisterFunction(highestCurrent)
 	MustRegisterFunction(highestMax)
@@ -1859,24 +2442,50 @@ func init() {
 	MustRegisterFunction(holtWintersForecast)
 	MustRegisterFunction(identity)
 	MustRegisterFunction(integral)
+	MustRegisterFunction(integralByInterval)
+	MustRegisterFunction(interpolate).WithDefaultParams(map[uint8]interface{}{
+		2: -1, // limit
+	})
 	MustRegisterFunction(isNonNull)
 	MustRegisterFunction(keepLastValue).WithDefaultParams(map[uint8]interface{}{
 		2: -1, // limit
 	})
 	MustRegisterFunction(legendValue)
 	MustRegisterFunction(limit)
+	MustRegisterFunction(linearRegression).WithDefaultParams(map[uint8]interface{}{
+		2: "", // startSourceAt
+		3: "", // endSourceAt
+	})
 	MustRegisterFunction(logarithm).WithDefaultParams(map[uint8]interface{}{
 		2: 10, // base
 	})
+	MustRegisterFunction(lowest).WithDefaultParams(map[uint8]interface{}{
+		2: 1,         // n
+		3: "average", // f
+	})
 	MustRegisterFunction(lowestAverage)
 	MustRegisterFunction(lowestCurrent)
+	MustRegisterFunction(mapSeries)
 	MustRegisterFunction(maxSeries)
 	MustRegisterFunction(maximumAbove)
 	MustRegisterFunction(minSeries)
 	MustRegisterFunction(minimumAbove)
 	MustRegisterFunction(mostDeviant)
 	MustRegisterFunction(movingAverage)
+	MustRegisterFunction(movingMax).WithDefaultParams(map[uint8]interface{}{
+		3: 0.0, // xFilesFactor
+	})
 	MustRegisterFunction(movingMedian)
+	MustRegisterFunction(movingMin).WithDefaultParams(map[uint8]interface{}{
+		3: 0.0, // xFilesFactor
+	})
+	MustRegisterFunction(movingSum).WithDefaultParams(map[uint8]interface{}{
+		3: 0.0, // xFilesFactor
+	})
+	MustRegisterFunction(movingWindow).WithDefaultParams(map[uint8]interface{}{
+		3: "average", // fname
+		4: 0.0,       // xFilesFactor
+	})
 	MustRegisterFunction(multiplySeries)
 	MustRegisterFunction(nonNegativeDerivative).WithDefaultParams(map[uint8]interface{}{
 		2: math.NaN(), // maxValue
@@ -1894,6 +2503,7 @@ func init() {
 	MustRegisterFunction(randomWalkFunction).WithDefaultParams(map[uint8]interface{}{
 		2: 60, // step
 	})
+	MustRegisterFunction(reduceSeries)
 	MustRegisterFunction(removeAbovePercentile)
 	MustRegisterFunction(removeAboveValue)
 	MustRegisterFunction(removeBelowPercentile)
@@ -1901,6 +2511,7 @@ func init() {
 	MustRegisterFunction(removeEmptySeries)
 	MustRegisterFunction(scale)
 	MustRegisterFunction(scaleToSeconds)
+	MustRegisterFunction(seriesByTag)
 	MustRegisterFunction(sortByMaxima)
 	MustRegisterFunction(sortByName)
 	MustRegisterFunction(sortByTotal)
@@ -1930,18 +2541,29 @@ func init() {
 	MustRegisterFunction(timeShift).WithDefaultParams(map[uint8]interface{}{
 		3: true, // resetEnd
 	})
+	MustRegisterFunction(timeSlice).WithDefaultParams(map[uint8]interface{}{
+		3: "now", // endSliceAt
+	})
+	MustRegisterFunction(timeStack).WithDefaultParams(map[uint8]interface{}{
+		2: "1d", // timeShiftUnit
+		3: 0,    // timeShiftStart
+		4: 7,    // timeShiftEnd
+	})
 	MustRegisterFunction(transformNull).WithDefaultParams(map[uint8]interface{}{
 		2: 0.0, // defaultValue
 	})
+	MustRegisterFunction(useSeriesAbove)
 	MustRegisterFunction(weightedAverage)
 
 	// alias functions - in alpha ordering
 	MustRegisterAliasedFunction("abs", absolute)
 	MustRegisterAliasedFunction("avg", averageSeries)
 	MustRegisterAliasedFunction("log", logarithm)
+	MustRegisterAliasedFunction("map", mapSeries)
 	MustRegisterAliasedFunction("max", maxSeries)
 	MustRegisterAliasedFunction("min", minSeries)
 	MustRegisterAliasedFunction("randomWalk", randomWalkFunction)
+	MustRegisterAliasedFunction("reduce", reduceSeries)
 	// NB(jayp): Graphite docs say that smartSummarize is the "smarter experimental version of
 	// summarize". Well, I am not sure about smarter, but aliasing satisfies the experimental
 	// aspect.
@@ -1806,6 +1806,42 @@ func TestIdentity(t *testing.T) {
 	}
 }
 
+func TestSeriesByTag(t *testing.T) {
+	expr, err := compile("aliasByTags(seriesByTag('name=cpu.load', 'dc!=y'), 'host')")
+	require.NoError(t, err)
+
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	ctx.Engine = mockEngine{fn: func(
+		ctx xctx.Context,
+		query string,
+		start, end time.Time,
+		timeout time.Duration,
+	) (*storage.FetchResult, error) {
+		if query != "seriesByTag('name=cpu.load','dc!=y')" {
+			return nil, fmt.Errorf("unexpected query: %s", query)
+		}
+
+		return storage.NewFetchResult(ctx, []*ts.Series{
+			ts.NewSeries(ctx, "cpu.load;dc=x;host=a", start, ts.NewConstantValues(ctx, 1, 3, 1000)),
+			ts.NewSeries(ctx, "cpu.load;dc=x;host=b", start, ts.NewConstantValues(ctx, 2, 3, 1000)),
+		}), nil
+	}}
+
+	r, err := expr.Execute(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, r.Len())
+	assert.Equal(t, "a", r.Values[0].Name())
+	assert.Equal(t, "b", r.Values[1].Name())
+
+	_, err = seriesByTag(ctx)
+	require.Error(t, err)
+
+	_, err = seriesByTag(ctx, "dc")
+	require.Error(t, err)
+}
+
 func TestLimit(t *testing.T) {
 	ctx := common.NewTestContext()
 	defer ctx.Close()
@@ -2774,15 +2810,277 @@ func TestThreshold(t *testing.T) {
 	require.Equal(t, "1.000", results[0].Name())
 }
 
+func TestMovingWindowSuccess(t *testing.T) {
+	values := []float64{12.0, 19.0, -10.0, math.NaN(), 10.0}
+	bootstrap := []float64{3.0, 4.0, 5.0}
+	testMovingAverage(t, "movingSum(foo.bar.baz, '30s')", "movingSum(foo.bar.baz,\"30s\")",
+		values, bootstrap, []float64{12.0, 21.0, 36.0, 21.0, 9.0})
+	testMovingAverage(t, "movingSum(foo.bar.baz, 3, 0.9)", "movingSum(foo.bar.baz,3)",
+		values, bootstrap, []float64{12.0, 21.0, 36.0, 21.0, math.NaN()})
+	testMovingAverage(t, "movingMin(foo.bar.baz, 3)", "movingMin(foo.bar.baz,3)",
+		values, bootstrap, []float64{3.0, 4.0, 5.0, -10.0, -10.0})
+	testMovingAverage(t, "movingMax(foo.bar.baz, 3)", "movingMax(foo.bar.baz,3)",
+		values, bootstrap, []float64{5.0, 12.0, 19.0, 19.0, 19.0})
+	testMovingAverage(t, "movingWindow(foo.bar.baz, 3)", "movingAverage(foo.bar.baz,3)",
+		values, bootstrap, []float64{4.0, 7.0, 12.0, 7.0, 4.5})
+	testMovingAverage(t, "movingWindow(foo.bar.baz, '30s', 'median')", "movingMedian(foo.bar.baz,\"30s\")",
+		values, bootstrap, []float64{4.0, 5.0, 12.0, 12.0, 4.5})
+	testMovingAverage(t, "movingSum(foo.bar.baz, 3)", "movingSum(foo.bar.baz,3)", nil, nil, nil)
+}
+
+func TestMovingWindowError(t *testing.T) {
+	testMovingAverageError(t, "movingSum(foo.bar.baz, '-30s')")
+	testMovingAverageError(t, "movingMax(foo.bar.baz, 0)")
+	testMovingAverageError(t, "movingWindow(foo.bar.baz, 3, 'foo')")
+}
+
+func TestHighestAndLowest(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{"a", []float64{1, 2, 3}},
+		{"b", []float64{5, 0, 0}},
+		{"c", []float64{2, 2, 2.5}},
+	}, 10000)
+
+	tests := []struct {
+		f        func(*common.Context, singlePathSpec, int, string) (ts.SeriesList, error)
+		n        int
+		fname    string
+		expected []string
+	}{
+		{highest, 1, "max", []string{"b"}},
+		{highest, 2, "average", []string{"c", "a"}},
+		{lowest, 1, "average", []string{"b"}},
+		{lowest, 2, "max", []string{"c", "a"}},
+	}
+
+	for _, test := range tests {
+		output, err := test.f(ctx, singlePathSpec{Values: input}, test.n, test.fname)
+		require.NoError(t, err)
+
+		var names []string
+		for _, series := range output.Values {
+			names = append(names, series.Name())
+		}
+		assert.Equal(t, test.expected, names, test.fname)
+	}
+
+	_, err := highest(ctx, singlePathSpec{Values: input}, 1, "foo")
+	require.Error(t, err)
+}
+
+func TestDelay(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	nan := math.NaN()
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{"foo", []float64{1, 2, 3, 4}},
+	}, 10000)
+
+	tests := []struct {
+		steps    int
+		expected common.TestSeries
+	}{
+		{2, common.TestSeries{"delay(foo,2)", []float64{nan, nan, 1, 2}}},
+		{-1, common.TestSeries{"delay(foo,-1)", []float64{2, 3, 4, nan}}},
+		{5, common.TestSeries{"delay(foo,5)", []float64{nan, nan, nan, nan}}},
+	}
+
+	for _, test := range tests {
+		output, err := delay(ctx, singlePathSpec{Values: input}, test.steps)
+		require.NoError(t, err)
+		common.CompareOutputsAndExpected(t, 10000, ctx.StartTime,
+			[]common.TestSeries{test.expected}, output.Values)
+	}
+}
+
+func TestIntegralByInterval(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{"foo", []float64{1, 2, math.NaN(), 3, 4, 5}},
+	}, 10000)
+
+	output, err := integralByInterval(ctx, singlePathSpec{Values: input}, "20s")
+	require.NoError(t, err)
+	common.CompareOutputsAndExpected(t, 10000, ctx.StartTime, []common.TestSeries{
+		{"integralByInterval(foo,'20s')", []float64{1, 3, 0, 3, 4, 9}},
+	}, output.Values)
+
+	_, err = integralByInterval(ctx, singlePathSpec{Values: input}, "0s")
+	require.Error(t, err)
+}
+
+func TestInterpolate(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	nan := math.NaN()
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{"foo", []float64{nan, 1, nan, nan, 4, nan, nan, nan, 8, nan}},
+	}, 10000)
+
+	tests := []struct {
+		limit    int
+		expected []float64
+	}{
+		{-1, []float64{nan, 1, 2, 3, 4, 5, 6, 7, 8, nan}},
+		{2, []float64{nan, 1, 2, 3, 4, nan, nan, nan, 8, nan}},
+	}
+
+	for _, test := range tests {
+		output, err := interpolate(ctx, singlePathSpec{Values: input}, test.limit)
+		require.NoError(t, err)
+		common.CompareOutputsAndExpected(t, 10000, ctx.StartTime, []common.TestSeries{
+			{"interpolate(foo)", test.expected},
+		}, output.Values)
+	}
+}
+
+func TestTimeSlice(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	nan := math.NaN()
+	start := time.Unix(1500000000, 0)
+	input := generateSeriesList(ctx, start, []common.TestSeries{
+		{"foo", []float64{1, 2, 3, 4, 5, 6}},
+	}, 10000)
+
+	output, err := timeSlice(ctx, singlePathSpec{Values: input}, "1500000010", "1500000030")
+	require.NoError(t, err)
+	common.CompareOutputsAndExpected(t, 10000, start, []common.TestSeries{
+		{"timeSlice(foo, 1500000010, 1500000030)", []float64{nan, 2, 3, 4, nan, nan}},
+	}, output.Values)
+
+	_, err = timeSlice(ctx, singlePathSpec{Values: input}, "foo", "now")
+	require.Error(t, err)
+}
+
+func TestTimeStack(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	ctx := common.NewContext(common.ContextOptions{
+		Start: start,
+		End:   start.Add(30 * time.Second),
+	})
+	defer ctx.Close()
+
+	ctx.Engine = mockEngine{fn: func(
+		ctx xctx.Context,
+		query string,
+		start, end time.Time,
+		timeout time.Duration,
+	) (*storage.FetchResult, error) {
+		if query != "foo" {
+			return nil, fmt.Errorf("unexpected query: %s", query)
+		}
+		return storage.NewFetchResult(ctx, []*ts.Series{
+			ts.NewSeries(ctx, "foo", start,
+				ts.NewConstantValues(ctx, float64(start.Unix()), 3, 10000)),
+		}), nil
+	}}
+
+	input := generateSeriesList(ctx, start, []common.TestSeries{
+		{"foo", []float64{0, 0, 0}},
+	}, 10000)
+
+	output, err := timeStack(ctx, singlePathSpec{Values: input}, "10s", 0, 3)
+	require.NoError(t, err)
+	common.CompareOutputsAndExpected(t, 10000, start, []common.TestSeries{
+		{"timeShift(foo, -10s, 0)", []float64{1500000000, 1500000000, 1500000000}},
+		{"timeShift(foo, -10s, 1)", []float64{1499999990, 1499999990, 1499999990}},
+		{"timeShift(foo, -10s, 2)", []float64{1499999980, 1499999980, 1499999980}},
+	}, output.Values)
+}
+
+func TestLinearRegression(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+	ctx := common.NewContext(common.ContextOptions{
+		Start: start,
+		End:   start.Add(40 * time.Second),
+		Engine: mockEngine{fn: func(
+			ctx xctx.Context,
+			query string,
+			start, end time.Time,
+			timeout time.Duration,
+		) (*storage.FetchResult, error) {
+			return storage.NewFetchResult(ctx, []*ts.Series{
+				ts.NewSeries(ctx, query, start,
+					common.NewTestSeriesValues(ctx, 10000, []float64{1, 2, math.NaN(), 4})),
+			}), nil
+		}},
+	})
+	defer ctx.Close()
+
+	tests := []struct {
+		target   string
+		expected common.TestSeries
+	}{
+		{
+			"linearRegression(foo)",
+			common.TestSeries{"linearRegression(foo, 1500000000, 1500000040)", []float64{1, 2, 3, 4}},
+		},
+		{
+			"linearRegression(foo, '1499999960', '1500000000')",
+			common.TestSeries{"linearRegression(foo, 1499999960, 1500000000)", []float64{5, 6, 7, 8}},
+		},
+	}
+
+	for _, test := range tests {
+		expr, err := compile(test.target)
+		require.NoError(t, err)
+		output, err := expr.Execute(ctx)
+		require.NoError(t, err)
+		common.CompareOutputsAndExpected(t, 10000, start,
+			[]common.TestSeries{test.expected}, output.Values)
+	}
+}
+
+func TestUseSeriesAbove(t *testing.T) {
+	ctx := common.NewTestContext()
+	defer ctx.Close()
+
+	ctx.Engine = mockEngine{fn: func(
+		ctx xctx.Context,
+		query string,
+		start, end time.Time,
+		timeout time.Duration,
+	) (*storage.FetchResult, error) {
+		if query != "foo.time" {
+			return nil, fmt.Errorf("unexpected query: %s", query)
+		}
+		return storage.NewFetchResult(ctx, []*ts.Series{
+			ts.NewSeries(ctx, query, start, ts.NewConstantValues(ctx, 1, 3, 10000)),
+		}), nil
+	}}
+
+	input := generateSeriesList(ctx, ctx.StartTime, []common.TestSeries{
+		{"foo.reqs", []float64{1, 20, 3}},
+		{"bar.reqs", []float64{1, 5, 3}},
+	}, 10000)
+
+	output, err := useSeriesAbove(ctx, singlePathSpec{Values: input}, 10, "reqs", "time")
+	require.NoError(t, err)
+	require.Equal(t, 1, output.Len())
+	assert.Equal(t, "foo.time", output.Values[0].Name())
+}
+
 func TestFunctionsRegistered(t *testing.T) {
 	fnames := []string{
 		"abs",
 		"absolute",
+		"aggregate",
 		"aggregateLine",
+		"aggregateWithWildcards",
 		"alias",
 		"aliasByMetric",
 		"aliasByNode",
 		"aliasSub",
+		"applyByNode",
 		"asPercent",
 		"averageAbove",
 		"averageSeries",
@@ -2796,6 +3094,7 @@ func TestFunctionsRegistered(t *testing.T) {
 		"currentAbove",
 		"currentBelow",
 		"dashed",
+		"delay",
 		"derivative",
 		"diffSeries",
 		"divideSeries",
@@ -2803,6 +3102,7 @@ func TestFunctionsRegistered(t *testing.T) {
 		"fallbackSeries",
 		"group",
 		"groupByNode",
+		"highest",
 		"highestAverage",
 		"highestCurrent",
 		"highestMax",
@@ -2812,14 +3112,20 @@ func TestFunctionsRegistered(t *testing.T) {
 		"holtWintersForecast",
 		"identity",
 		"integral",
+		"integralByInterval",
+		"interpolate",
 		"isNonNull",
 		"keepLastValue",
 		"legendValue",
 		"limit",
+		"linearRegression",
 		"log",
 		"logarithm",
+		"lowest",
 		"lowestAverage",
 		"lowestCurrent",
+		"map",
+		"mapSeries",
 		"max",
 		"maxSeries",
 		"maximumAbove",
@@ -2828,7 +3134,11 @@ func TestFunctionsRegistered(t *testing.T) {
 		"minimumAbove",
 		"mostDeviant",
 		"movingAverage",
+		"movingMax",
 		"movingMedian",
+		"movingMin",
+		"movingSum",
+		"movingWindow",
 		"multiplySeries",
 		"nonNegativeDerivative",
 		"nPercentile",
@@ -2838,6 +3148,8 @@ func TestFunctionsRegistered(t *testing.T) {
 		"randomWalk",
 		"randomWalkFunction",
 		"rangeOfSeries",
+		"reduce",
+		"reduceSeries",
 		"removeAbovePercentile",
 		"removeAboveValue",
 		"removeBelowPercentile",
@@ -2858,7 +3170,10 @@ func TestFunctionsRegistered(t *testing.T) {
 		"time",
 		"timeFunction",
 		"timeShift",
+		"timeSlice",
+		"timeStack",
 		"transformNull",
+		"useSeriesAbove",
 		"weightedAverage",
 	}
 
@@ -45,6 +45,16 @@ var (
 type m3WrappedStore struct {
 	m3       storage.Storage
 	enforcer cost.ChainedEnforcer
+	resolver *PolicyResolver
+}
+
+// M3WrappedStorageOptions is the set of options for the graphite storage
+// wrapper.
+type M3WrappedStorageOptions struct {
+	// PolicyResolver, if set, restricts fetches for each path to the namespace
+	// that the path is written to rather than fanning out to all aggregated
+	// namespaces.
+	PolicyResolver *PolicyResolver
 }
 
 // NewM3WrappedStorage creates a graphite storage wrapper around an m3query
@@ -52,12 +62,17 @@ type m3WrappedStore struct {
 func NewM3WrappedStorage(
 	m3storage storage.Storage,
 	enforcer cost.ChainedEnforcer,
+	opts M3WrappedStorageOptions,
 ) Storage {
 	if enforcer == nil {
 		enforcer = cost.NoopChainedEnforcer()
 	}
 
-	return &m3WrappedStore{m3: m3storage, enforcer: enforcer}
+	return &m3WrappedStore{
+		m3:       m3storage,
+		enforcer: enforcer,
+		resolver: opts.PolicyResolver,
+	}
 }
 
 // TranslateQueryToMatchersWithTerminator converts a graphite query to tag
@@ -96,8 +111,58 @@ func GetQueryTerminatorTagName(query string) []byte {
 	return graphite.TagName(metricLength)
 }
 
+// TranslateTagExpressionsToMatchers converts graphite seriesByTag tag
+// expressions to tag matchers. Graphite regular expressions are only anchored
+// at the start of the value, so they are extended to match any suffix.
+func TranslateTagExpressionsToMatchers(
+	exprs []graphite.TagExpression,
+) (models.Matchers, error) {
+	matchers := make(models.Matchers, 0, len(exprs))
+	for _, expr := range exprs {
+		var (
+			matchType models.MatchType
+			value     = expr.Value
+		)
+		switch expr.Type {
+		case graphite.TagMatchEqual:
+			matchType = models.MatchEqual
+		case graphite.TagMatchNotEqual:
+			matchType = models.MatchNotEqual
+		case graphite.TagMatchRegexp:
+			matchType = models.MatchRegexp
+			value = "(?:" + value + ").*"
+		case graphite.TagMatchNotRegexp:
+			matchType = models.MatchNotRegexp
+			value = "(?:" + value + ").*"
+		default:
+			return nil, fmt.Errorf("unknown tag match type: %d", expr.Type)
+		}
+
+		matchers = append(matchers, models.Matcher{
+			Type:  matchType,
+			Name:  []byte(expr.Name),
+			Value: []byte(value),
+		})
+	}
+
+	return matchers, nil
+}
+
+func translateQueryToMatchers(query string) (models.Matchers, error) {
+	if !graphite.IsSeriesByTagQuery(query) {
+		return TranslateQueryToMatchersWithTerminator(query)
+	}
+
+	exprs, err := graphite.ParseSeriesByTagQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return TranslateTagExpressionsToMatchers(exprs)
+}
+
 func translateQuery(query string, opts FetchOptions) (*storage.FetchQuery, error) {
-	matchers, err := TranslateQueryToMatchersWithTerminator(query)
+	matchers, err := translateQueryToMatchers(query)
 	if err != nil {
 		return nil, err
 	}
@@ -172,16 +237,90 @@ func (s *m3WrappedStore) FetchByQuery(
 		FanoutAggregatedOptimized: storage.FanoutForceDisable,
 	}
 
-	m3result, err := s.m3.Fetch(m3ctx, m3query, fetchOptions)
+	// NB: tagged series are not matched by ingestion rule patterns, so are
+	// always fanned out to all aggregated namespaces.
+	if s.resolver == nil || graphite.IsSeriesByTagQuery(query) {
+		series, err := s.fetch(ctx, m3ctx, m3query, fetchOptions, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewFetchResult(ctx, series), nil
+	}
+
+	series, err := s.fetchWithPolicyResolver(ctx, m3ctx, query, m3query,
+		fetchOptions, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	series, err := translateTimeseries(ctx, m3result.SeriesList,
-		opts.StartTime, opts.EndTime)
+	return NewFetchResult(ctx, series), nil
+}
+
+// fetchWithPolicyResolver fetches each path from the namespace it is written
+// to. Exact paths are fetched from a single namespace, whereas globbed paths
+// are fetched from the namespace of each rule, keeping only the series that
+// the rules write to the namespace they were fetched from.
+func (s *m3WrappedStore) fetchWithPolicyResolver(
+	ctx xctx.Context,
+	m3ctx context.Context,
+	query string,
+	m3query *storage.FetchQuery,
+	fetchOptions *storage.FetchOptions,
+	opts FetchOptions,
+) ([]*ts.Series, error) {
+	_, isGlob, err := graphite.GlobToRegexPattern(query)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewFetchResult(ctx, series), nil
+	if !isGlob {
+		p, ok := s.resolver.Resolve(query, opts.StartTime)
+		if !ok {
+			// NB: no rule writes this path so there is nothing to fetch.
+			return []*ts.Series{}, nil
+		}
+
+		fetchOptions.RestrictFetchOptions = NewRestrictFetchOptions(p)
+		return s.fetch(ctx, m3ctx, m3query, fetchOptions, opts)
+	}
+
+	var results []*ts.Series
+	for _, p := range s.resolver.Policies(opts.StartTime) {
+		policyFetchOptions := *fetchOptions
+		policyFetchOptions.RestrictFetchOptions = NewRestrictFetchOptions(p)
+		series, err := s.fetch(ctx, m3ctx, m3query, &policyFetchOptions, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ser := range series {
+			resolved, ok := s.resolver.Resolve(ser.Name(), opts.StartTime)
+			if ok && resolved == p {
+				results = append(results, ser)
+			}
+		}
+	}
+
+	if results == nil {
+		results = []*ts.Series{}
+	}
+
+	return results, nil
+}
+
+func (s *m3WrappedStore) fetch(
+	ctx xctx.Context,
+	m3ctx context.Context,
+	m3query *storage.FetchQuery,
+	fetchOptions *storage.FetchOptions,
+	opts FetchOptions,
+) ([]*ts.Series, error) {
+	m3result, err := s.m3.Fetch(m3ctx, m3query, fetchOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return translateTimeseries(ctx, m3result.SeriesList,
+		opts.StartTime, opts.EndTime)
 }
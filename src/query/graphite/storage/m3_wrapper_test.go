@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/metrics/policy"
 	"github.com/m3db/m3/src/query/cost"
 	xctx "github.com/m3db/m3/src/query/graphite/context"
 	"github.com/m3db/m3/src/query/graphite/graphite"
@@ -95,6 +96,33 @@ func TestTranslateQueryTrailingDot(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestTranslateSeriesByTagQuery(t *testing.T) {
+	query := graphite.SeriesByTagQuery([]string{
+		"name=cpu.load", "host!=a", "dc=~us-", "env!=~dev|test",
+	})
+	end := time.Now()
+	start := end.Add(time.Hour * -2)
+	opts := FetchOptions{
+		StartTime: start,
+		EndTime:   end,
+		DataOptions: DataOptions{
+			Timeout: time.Minute,
+		},
+	}
+
+	translated, err := translateQuery(query, opts)
+	require.NoError(t, err)
+	assert.Equal(t, query, translated.Raw)
+	expected := models.Matchers{
+		{Type: models.MatchEqual, Name: []byte("name"), Value: []byte("cpu.load")},
+		{Type: models.MatchNotEqual, Name: []byte("host"), Value: []byte("a")},
+		{Type: models.MatchRegexp, Name: []byte("dc"), Value: []byte("(?:us-).*")},
+		{Type: models.MatchNotRegexp, Name: []byte("env"), Value: []byte("(?:dev|test).*")},
+	}
+
+	assert.Equal(t, expected, translated.TagMatchers)
+}
+
 func TestTranslateTimeseries(t *testing.T) {
 	ctx := xctx.New()
 	resolution := 10 * time.Second
@@ -172,7 +200,7 @@ func TestFetchByQuery(t *testing.T) {
 	enforcer := cost.NewMockChainedEnforcer(ctrl)
 	enforcer.EXPECT().Child(cost.QueryLevel).Return(childEnforcer).MinTimes(1)
 
-	wrapper := NewM3WrappedStorage(store, enforcer)
+	wrapper := NewM3WrappedStorage(store, enforcer, M3WrappedStorageOptions{})
 	ctx := xctx.New()
 	ctx.SetRequestContext(context.TODO())
 	end := time.Now()
@@ -196,6 +224,86 @@ func TestFetchByQuery(t *testing.T) {
 	assert.Equal(t, childEnforcer, store.LastFetchOptions().Enforcer)
 }
 
+func newTestFetchStore(start time.Time, names ...string) mock.Storage {
+	store := mock.NewMockStorage()
+	resolution := 10 * time.Second
+	seriesList := make(m3ts.SeriesList, 0, len(names))
+	for _, name := range names {
+		vals := m3ts.NewFixedStepValues(resolution, 3, 3, start)
+		series := m3ts.NewSeries([]byte(name), vals, models.NewTags(0, nil))
+		series.SetResolution(resolution)
+		seriesList = append(seriesList, series)
+	}
+
+	store.SetFetchResult(&storage.FetchResult{SeriesList: seriesList}, nil)
+	return store
+}
+
+func TestFetchByQueryWithPolicyResolver(t *testing.T) {
+	start := time.Now().Add(time.Hour * -1)
+	store := newTestFetchStore(start, "carbon.a", "servers.a")
+	resolver := newTestPolicyResolver(t, time.Now())
+	wrapper := NewM3WrappedStorage(store, nil, M3WrappedStorageOptions{
+		PolicyResolver: resolver,
+	})
+
+	ctx := xctx.New()
+	ctx.SetRequestContext(context.TODO())
+	opts := FetchOptions{
+		StartTime: start,
+		EndTime:   time.Now(),
+		DataOptions: DataOptions{
+			Timeout: time.Minute,
+		},
+	}
+
+	// NB: an exact path is fetched only from the namespace it is written to.
+	_, err := wrapper.FetchByQuery(ctx, "servers.a", opts)
+	require.NoError(t, err)
+	assert.Equal(t, NewRestrictFetchOptions(testPolicy1m2d),
+		store.LastFetchOptions().RestrictFetchOptions)
+
+	// NB: a globbed path is fetched from each rule's namespace, only keeping
+	// series written to the namespace they were fetched from.
+	result, err := wrapper.FetchByQuery(ctx, "*.a", opts)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(result.SeriesList))
+	assert.Equal(t, "carbon.a", result.SeriesList[0].Name())
+	assert.Equal(t, "servers.a", result.SeriesList[1].Name())
+	assert.Equal(t, NewRestrictFetchOptions(testPolicy1m2d),
+		store.LastFetchOptions().RestrictFetchOptions)
+}
+
+func TestFetchByQueryWithPolicyResolverNoMatchingRule(t *testing.T) {
+	start := time.Now().Add(time.Hour * -1)
+	store := newTestFetchStore(start, "servers.a")
+	resolver, err := NewPolicyResolver([]PolicyRule{
+		{
+			Pattern:  `^carbon\.`,
+			Policies: []policy.StoragePolicy{testPolicy10m30d},
+		},
+	})
+	require.NoError(t, err)
+
+	wrapper := NewM3WrappedStorage(store, nil, M3WrappedStorageOptions{
+		PolicyResolver: resolver,
+	})
+
+	ctx := xctx.New()
+	ctx.SetRequestContext(context.TODO())
+	opts := FetchOptions{
+		StartTime: start,
+		EndTime:   time.Now(),
+		DataOptions: DataOptions{
+			Timeout: time.Minute,
+		},
+	}
+
+	result, err := wrapper.FetchByQuery(ctx, "servers.a", opts)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(result.SeriesList))
+}
+
 func TestFetchByInvalidQuery(t *testing.T) {
 	logging.InitWithCores(nil)
 	store := mock.NewMockStorage()
@@ -211,7 +319,7 @@ func TestFetchByInvalidQuery(t *testing.T) {
 
 	query := "a."
 	ctx := xctx.New()
-	wrapper := NewM3WrappedStorage(store, nil)
+	wrapper := NewM3WrappedStorage(store, nil, M3WrappedStorageOptions{})
 	result, err := wrapper.FetchByQuery(ctx, query, opts)
 	assert.NoError(t, err)
 	require.Equal(t, 0, len(result.SeriesList))
@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package storage
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/storage"
+)
+
+// PolicyRule maps the metric paths matching a pattern to the storage policies
+// they are written to, mirroring a carbon ingestion rule.
+type PolicyRule struct {
+	Pattern    string
+	AndPattern string
+	Policies   []policy.StoragePolicy
+}
+
+type compiledPolicyRule struct {
+	regexp    *regexp.Regexp
+	andRegexp *regexp.Regexp
+	policies  []policy.StoragePolicy
+}
+
+func (r compiledPolicyRule) matches(path string) bool {
+	if !r.regexp.MatchString(path) {
+		return false
+	}
+
+	return r.andRegexp == nil || r.andRegexp.MatchString(path)
+}
+
+// PolicyResolver resolves the storage policy to query for a graphite metric
+// path, using the same first match semantics as the carbon ingester so that
+// a path is read from a namespace it was written to.
+type PolicyResolver struct {
+	rules []compiledPolicyRule
+	nowFn func() time.Time
+}
+
+// NewPolicyResolver returns a new policy resolver for the given rules, which
+// are matched in order.
+func NewPolicyResolver(rules []PolicyRule) (*PolicyResolver, error) {
+	compiled := make([]compiledPolicyRule, 0, len(rules))
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		var andRe *regexp.Regexp
+		if rule.AndPattern != "" {
+			andRe, err = regexp.Compile(rule.AndPattern)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		compiled = append(compiled, compiledPolicyRule{
+			regexp:    re,
+			andRegexp: andRe,
+			policies:  rule.Policies,
+		})
+	}
+
+	return &PolicyResolver{
+		rules: compiled,
+		nowFn: time.Now,
+	}, nil
+}
+
+// Resolve returns the storage policy to read the given metric path from for a
+// query starting at the given time, or false if no rule writes the path.
+func (r *PolicyResolver) Resolve(
+	path string,
+	start time.Time,
+) (policy.StoragePolicy, bool) {
+	for _, rule := range r.rules {
+		if rule.matches(path) {
+			// NB: only the first matching rule is applied on ingest.
+			return selectPolicy(rule.policies, r.nowFn().Sub(start))
+		}
+	}
+
+	return policy.StoragePolicy{}, false
+}
+
+// Policies returns the distinct storage policies that paths are read from for
+// a query starting at the given time, one per rule at most.
+func (r *PolicyResolver) Policies(start time.Time) []policy.StoragePolicy {
+	var (
+		queryRange = r.nowFn().Sub(start)
+		seen       = make(map[policy.StoragePolicy]struct{}, len(r.rules))
+		policies   = make([]policy.StoragePolicy, 0, len(r.rules))
+	)
+
+	for _, rule := range r.rules {
+		p, ok := selectPolicy(rule.policies, queryRange)
+		if !ok {
+			continue
+		}
+
+		if _, ok := seen[p]; ok {
+			continue
+		}
+
+		seen[p] = struct{}{}
+		policies = append(policies, p)
+	}
+
+	return policies
+}
+
+// selectPolicy picks the finest resolution policy which retains the entire
+// query range, falling back to the longest retention policy if none do.
+func selectPolicy(
+	policies []policy.StoragePolicy,
+	queryRange time.Duration,
+) (policy.StoragePolicy, bool) {
+	if len(policies) == 0 {
+		return policy.StoragePolicy{}, false
+	}
+
+	var (
+		longest = policies[0]
+		finest  policy.StoragePolicy
+		found   bool
+	)
+
+	for _, p := range policies {
+		if p.Retention().Duration() > longest.Retention().Duration() {
+			longest = p
+		}
+
+		if p.Retention().Duration() < queryRange {
+			continue
+		}
+
+		if !found || p.Resolution().Window < finest.Resolution().Window {
+			finest = p
+			found = true
+		}
+	}
+
+	if !found {
+		return longest, true
+	}
+
+	return finest, true
+}
+
+// NewRestrictFetchOptions returns fetch options restricting a fetch to the
+// aggregated namespace with the given storage policy.
+func NewRestrictFetchOptions(p policy.StoragePolicy) *storage.RestrictFetchOptions {
+	return &storage.RestrictFetchOptions{
+		MetricsType: storage.AggregatedMetricsType,
+		Retention:   p.Retention().Duration(),
+		Resolution:  p.Resolution().Window,
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/metrics/policy"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testPolicy1m2d   = policy.NewStoragePolicy(time.Minute, xtime.Second, 48*time.Hour)
+	testPolicy10m30d = policy.NewStoragePolicy(10*time.Minute, xtime.Second, 30*24*time.Hour)
+	testPolicy1h1y   = policy.NewStoragePolicy(time.Hour, xtime.Second, 365*24*time.Hour)
+)
+
+func newTestPolicyResolver(t *testing.T, now time.Time) *PolicyResolver {
+	resolver, err := NewPolicyResolver([]PolicyRule{
+		{
+			Pattern:  `^carbon\.`,
+			Policies: []policy.StoragePolicy{testPolicy10m30d},
+		},
+		{
+			Pattern:  ".*",
+			Policies: []policy.StoragePolicy{testPolicy1h1y, testPolicy1m2d},
+		},
+	})
+	require.NoError(t, err)
+	resolver.nowFn = func() time.Time { return now }
+	return resolver
+}
+
+func TestPolicyResolverResolve(t *testing.T) {
+	now := time.Now()
+	resolver := newTestPolicyResolver(t, now)
+
+	tests := []struct {
+		path     string
+		start    time.Time
+		expected policy.StoragePolicy
+	}{
+		{"carbon.agents.a.cpu", now.Add(-time.Hour), testPolicy10m30d},
+		{"carbon.agents.a.cpu", now.Add(-90 * 24 * time.Hour), testPolicy10m30d},
+		{"servers.a.cpu", now.Add(-time.Hour), testPolicy1m2d},
+		{"servers.a.cpu", now.Add(-7 * 24 * time.Hour), testPolicy1h1y},
+		{"servers.a.cpu", now.Add(-2 * 365 * 24 * time.Hour), testPolicy1h1y},
+	}
+
+	for _, tt := range tests {
+		p, ok := resolver.Resolve(tt.path, tt.start)
+		require.True(t, ok)
+		assert.Equal(t, tt.expected, p, tt.path)
+	}
+}
+
+func TestPolicyResolverNoMatch(t *testing.T) {
+	resolver, err := NewPolicyResolver([]PolicyRule{
+		{
+			Pattern:  `^carbon\.`,
+			Policies: []policy.StoragePolicy{testPolicy10m30d},
+		},
+	})
+	require.NoError(t, err)
+
+	_, ok := resolver.Resolve("servers.a.cpu", time.Now())
+	assert.False(t, ok)
+}
+
+func TestPolicyResolverPolicies(t *testing.T) {
+	now := time.Now()
+	resolver := newTestPolicyResolver(t, now)
+
+	assert.Equal(t, []policy.StoragePolicy{testPolicy10m30d, testPolicy1m2d},
+		resolver.Policies(now.Add(-time.Hour)))
+	assert.Equal(t, []policy.StoragePolicy{testPolicy10m30d, testPolicy1h1y},
+		resolver.Policies(now.Add(-7*24*time.Hour)))
+}
+
+func TestPolicyResolverAndPattern(t *testing.T) {
+	resolver, err := NewPolicyResolver([]PolicyRule{
+		{
+			Pattern:    `^stats\.`,
+			AndPattern: `\.count$`,
+			Policies:   []policy.StoragePolicy{testPolicy10m30d},
+		},
+		{
+			Pattern:  ".*",
+			Policies: []policy.StoragePolicy{testPolicy1h1y},
+		},
+	})
+	require.NoError(t, err)
+
+	p, ok := resolver.Resolve("stats.a.count", time.Now())
+	require.True(t, ok)
+	assert.Equal(t, testPolicy10m30d, p)
+
+	p, ok = resolver.Resolve("stats.a.mean", time.Now())
+	require.True(t, ok)
+	assert.Equal(t, testPolicy1h1y, p)
+}
+
+func TestPolicyResolverInvalidPattern(t *testing.T) {
+	_, err := NewPolicyResolver([]PolicyRule{{Pattern: "(", Policies: nil}})
+	require.Error(t, err)
+}
@@ -67,6 +67,8 @@ type RequestParams struct {
 	IncludeEnd bool
 	BlockType  FetchedBlockType
 	FormatType FormatType
+	// Tenant identifies the tenant issuing the query, if any.
+	Tenant string
 }
 
 // ExclusiveEnd returns the end exclusive
@@ -35,6 +35,11 @@ type QueryContext struct {
 	Ctx      context.Context
 	Scope    tally.Scope
 	Enforcer cost.ChainedEnforcer
+	// SeriesEnforcer enforces limits on the number of series fetched by the
+	// query if set.
+	SeriesEnforcer cost.ChainedEnforcer
+	// Stats collects execution statistics for the query if set.
+	Stats *QueryStats
 }
 
 // NewQueryContext constructs a QueryContext using the given Enforcer to
@@ -0,0 +1,182 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type queryStatsKey struct{}
+
+// QueryStats collects execution statistics for a query. It is safe for
+// concurrent use, and all methods are no-ops on a nil QueryStats so that
+// callers need not check whether statistics are being collected.
+type QueryStats struct {
+	seriesFetched     int64
+	datapointsDecoded int64
+
+	sync.Mutex
+	nodes map[string]*nodeStats
+}
+
+type nodeStats struct {
+	op         string
+	blocks     int
+	elapsed    time.Duration
+	downstream time.Duration
+}
+
+// QueryStatsSummary is a snapshot of the statistics of a query.
+type QueryStatsSummary struct {
+	SeriesFetched     int64              `json:"seriesFetched"`
+	DatapointsDecoded int64              `json:"datapointsDecoded"`
+	Nodes             []NodeStatsSummary `json:"nodes"`
+}
+
+// NodeStatsSummary is a snapshot of the statistics of a single node of the
+// query DAG.
+type NodeStatsSummary struct {
+	ID     string        `json:"id"`
+	Op     string        `json:"op"`
+	Blocks int           `json:"blocks"`
+	Time   time.Duration `json:"timeNanos"`
+}
+
+// NewQueryStats returns a new QueryStats.
+func NewQueryStats() *QueryStats {
+	return &QueryStats{nodes: make(map[string]*nodeStats)}
+}
+
+// NewQueryStatsContext returns a context carrying the query stats.
+func NewQueryStatsContext(ctx context.Context, stats *QueryStats) context.Context {
+	return context.WithValue(ctx, queryStatsKey{}, stats)
+}
+
+// QueryStatsFromContext returns the query stats carried by the context, or
+// nil if there are none.
+func QueryStatsFromContext(ctx context.Context) *QueryStats {
+	stats, _ := ctx.Value(queryStatsKey{}).(*QueryStats)
+	return stats
+}
+
+// AddSeriesFetched adds to the number of series fetched from storage.
+func (s *QueryStats) AddSeriesFetched(n int) {
+	if s == nil {
+		return
+	}
+
+	atomic.AddInt64(&s.seriesFetched, int64(n))
+}
+
+// SeriesFetched returns the number of series fetched from storage.
+func (s *QueryStats) SeriesFetched() int64 {
+	if s == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&s.seriesFetched)
+}
+
+// AddDatapointsDecoded adds to the number of datapoints decoded.
+func (s *QueryStats) AddDatapointsDecoded(n int) {
+	if s == nil {
+		return
+	}
+
+	atomic.AddInt64(&s.datapointsDecoded, int64(n))
+}
+
+// RecordProcessing records time spent by the node, including the time spent
+// by its downstream nodes processing its output.
+func (s *QueryStats) RecordProcessing(id, op string, elapsed time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	node := s.node(id)
+	node.op = op
+	node.elapsed += elapsed
+	s.Unlock()
+}
+
+// RecordOutput records a block output by the node along with the time spent
+// by its downstream nodes processing it, which is excluded from the time of
+// the node.
+func (s *QueryStats) RecordOutput(id string, downstream time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	node := s.node(id)
+	node.blocks++
+	node.downstream += downstream
+	s.Unlock()
+}
+
+func (s *QueryStats) node(id string) *nodeStats {
+	node, ok := s.nodes[id]
+	if !ok {
+		node = &nodeStats{}
+		s.nodes[id] = node
+	}
+
+	return node
+}
+
+// Summary returns a snapshot of the statistics, with nodes sorted by ID.
+func (s *QueryStats) Summary() QueryStatsSummary {
+	if s == nil {
+		return QueryStatsSummary{}
+	}
+
+	summary := QueryStatsSummary{
+		SeriesFetched:     atomic.LoadInt64(&s.seriesFetched),
+		DatapointsDecoded: atomic.LoadInt64(&s.datapointsDecoded),
+	}
+
+	s.Lock()
+	for id, node := range s.nodes {
+		elapsed := node.elapsed - node.downstream
+		if elapsed < 0 {
+			elapsed = 0
+		}
+
+		summary.Nodes = append(summary.Nodes, NodeStatsSummary{
+			ID:     id,
+			Op:     node.op,
+			Blocks: node.blocks,
+			Time:   elapsed,
+		})
+	}
+	s.Unlock()
+
+	sort.Slice(summary.Nodes, func(i, j int) bool {
+		return summary.Nodes[i].ID < summary.Nodes[j].ID
+	})
+
+	return summary
+}
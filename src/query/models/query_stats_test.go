@@ -0,0 +1,69 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryStats(t *testing.T) {
+	stats := NewQueryStats()
+	stats.AddSeriesFetched(2)
+	stats.AddDatapointsDecoded(10)
+
+	// The fetch spends 5s in total, 3s of which is spent by the sum.
+	stats.RecordProcessing("0", "fetch", 5*time.Second)
+	stats.RecordOutput("0", 3*time.Second)
+	stats.RecordProcessing("1", "sum", 3*time.Second)
+	stats.RecordOutput("1", time.Second)
+
+	summary := stats.Summary()
+	assert.Equal(t, int64(2), summary.SeriesFetched)
+	assert.Equal(t, int64(10), summary.DatapointsDecoded)
+	assert.Equal(t, []NodeStatsSummary{
+		{ID: "0", Op: "fetch", Blocks: 1, Time: 2 * time.Second},
+		{ID: "1", Op: "sum", Blocks: 1, Time: 2 * time.Second},
+	}, summary.Nodes)
+}
+
+func TestQueryStatsNil(t *testing.T) {
+	var stats *QueryStats
+	stats.AddSeriesFetched(1)
+	stats.AddDatapointsDecoded(1)
+	stats.RecordProcessing("0", "fetch", time.Second)
+	stats.RecordOutput("0", time.Second)
+	assert.Equal(t, int64(0), stats.SeriesFetched())
+	assert.Equal(t, QueryStatsSummary{}, stats.Summary())
+}
+
+func TestQueryStatsContext(t *testing.T) {
+	assert.Nil(t, QueryStatsFromContext(context.Background()))
+
+	stats := NewQueryStats()
+	ctx := NewQueryStatsContext(context.Background(), stats)
+	require.NotNil(t, QueryStatsFromContext(ctx))
+	assert.True(t, stats == QueryStatsFromContext(ctx))
+}
@@ -249,7 +249,15 @@ func (t Tags) prependMetaLen() (int, []int) {
 	return idLen + prefixLen, tagLengths
 }
 
+// GraphiteNameTag is the tag holding the path of a tagged graphite series,
+// e.g. cpu.load for the series cpu.load;host=a.
+var GraphiteNameTag = []byte("name")
+
 func (t Tags) graphiteID() []byte {
+	if _, tagged := t.Get(GraphiteNameTag); tagged {
+		return t.graphiteTaggedID()
+	}
+
 	// TODO: pool these bytes.
 	id := make([]byte, t.idLenGraphite())
 	idx := 0
@@ -264,6 +272,34 @@ func (t Tags) graphiteID() []byte {
 	return id
 }
 
+func (t Tags) graphiteTaggedID() []byte {
+	name, _ := t.Get(GraphiteNameTag)
+	idLen := len(name)
+	for _, tag := range t.Tags {
+		if !bytes.Equal(tag.Name, GraphiteNameTag) {
+			idLen += len(tag.Name) + len(tag.Value) + 2 // account for ; and =
+		}
+	}
+
+	// TODO: pool these bytes.
+	id := make([]byte, idLen)
+	idx := copy(id, name)
+	for _, tag := range t.Tags {
+		if bytes.Equal(tag.Name, GraphiteNameTag) {
+			continue
+		}
+
+		id[idx] = graphiteTag
+		idx++
+		idx += copy(id[idx:], tag.Name)
+		id[idx] = eq
+		idx++
+		idx += copy(id[idx:], tag.Value)
+	}
+
+	return id
+}
+
 func (t Tags) idLenGraphite() int {
 	idLen := t.Len() - 1 // account for separators
 	for _, tag := range t.Tags {
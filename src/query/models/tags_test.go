@@ -82,6 +82,18 @@ func TestLongTagNewIDOutOfOrderGraphite(t *testing.T) {
 	assert.Equal(t, []byte("v0.v1.v2.v3.v4.v5.v6.v7.v8.v9.v10.v11.v12"), actual)
 }
 
+func TestTaggedIDGraphite(t *testing.T) {
+	opts := NewTagOptions().SetIDSchemeType(TypeGraphite)
+	tags := NewTags(3, opts).AddTags([]Tag{
+		{Name: []byte("host"), Value: []byte("a")},
+		{Name: []byte("name"), Value: []byte("cpu.load")},
+		{Name: []byte("dc"), Value: []byte("x")},
+	})
+
+	actual := tags.ID()
+	assert.Equal(t, []byte("cpu.load;dc=x;host=a"), actual)
+}
+
 func TestHashedID(t *testing.T) {
 	tags := testLongTagIDOutOfOrder(t, TypeLegacy)
 	actual := tags.HashedID()
@@ -27,6 +27,7 @@ import (
 // Separators for tags.
 const (
 	graphiteSep  = byte('.')
+	graphiteTag  = byte(';')
 	sep          = byte(',')
 	finish       = byte('!')
 	eq           = byte('=')
@@ -72,6 +73,10 @@ const (
 	// used on non-graphite data.
 	// {__g0__:v1},{__g1__:v2} -> v1.v2
 	//
+	// Tagged graphite series carry their path in the name tag instead, and
+	// have their remaining tags appended using the graphite tagged syntax.
+	// {name:v1.v2},{dc:x},{host:a} -> v1.v2;dc=x;host=a
+	//
 	// NB: when TypeGraphite is specified, tags are ordered numerically rather
 	// than lexically.
 	//
@@ -0,0 +1,484 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3ql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/binary"
+	"github.com/m3db/m3/src/query/functions/linear"
+	"github.com/m3db/m3/src/query/functions/scalar"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+)
+
+const (
+	fetchType     = "fetch"
+	logarithmType = "logarithm"
+	nameKeyword   = "name"
+	globMetaChars = "*?[]{},"
+
+	// defaultLogarithmBase is the base of logarithms without a base argument,
+	// as in Graphite.
+	defaultLogarithmBase = 10
+)
+
+var (
+	aggregationTypes = map[string]string{
+		"sum":           aggregation.SumType,
+		"sumSeries":     aggregation.SumType,
+		"avg":           aggregation.AverageType,
+		"averageSeries": aggregation.AverageType,
+		"min":           aggregation.MinType,
+		"minSeries":     aggregation.MinType,
+		"max":           aggregation.MaxType,
+		"maxSeries":     aggregation.MaxType,
+		"count":         aggregation.CountType,
+	}
+
+	mathTypes = map[string]string{
+		"abs":        linear.AbsType,
+		"absolute":   linear.AbsType,
+		"sqrt":       linear.SqrtType,
+		"squareRoot": linear.SqrtType,
+	}
+
+	// scalarBinaryTypes are the functions applying a binary operation between
+	// each series and their scalar argument.
+	scalarBinaryTypes = map[string]string{
+		"eq":                 binary.EqType,
+		binary.EqType:        binary.EqType,
+		"ne":                 binary.NotEqType,
+		binary.NotEqType:     binary.NotEqType,
+		"gt":                 binary.GreaterType,
+		binary.GreaterType:   binary.GreaterType,
+		"lt":                 binary.LesserType,
+		binary.LesserType:    binary.LesserType,
+		"ge":                 binary.GreaterEqType,
+		binary.GreaterEqType: binary.GreaterEqType,
+		"le":                 binary.LesserEqType,
+		binary.LesserEqType:  binary.LesserEqType,
+		"removeBelowValue":   binary.GreaterEqType,
+		"removeAboveValue":   binary.LesserEqType,
+		"scale":              binary.MultiplyType,
+		"offset":             binary.PlusType,
+	}
+)
+
+type m3qlParser struct {
+	query   string
+	script  *script
+	tagOpts models.TagOptions
+}
+
+// Parse takes an M3QL string and parses it into a DAG
+func Parse(q string, tagOpts models.TagOptions) (parser.Parser, error) {
+	s, err := parseScript(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &m3qlParser{
+		query:   q,
+		script:  s,
+		tagOpts: tagOpts,
+	}, nil
+}
+
+func (p *m3qlParser) DAG() (parser.Nodes, parser.Edges, error) {
+	state := &parseState{
+		tagOpts:   p.tagOpts,
+		macros:    p.script.macros,
+		expanding: make(map[string]bool),
+	}
+
+	if _, err := state.walkPipeline(p.script.pipeline); err != nil {
+		return nil, nil, err
+	}
+
+	return state.transforms, state.edges, nil
+}
+
+func (p *m3qlParser) String() string {
+	return p.query
+}
+
+type parseState struct {
+	edges      parser.Edges
+	transforms parser.Nodes
+	tagOpts    models.TagOptions
+	macros     map[string]*pipeline
+	// expanding holds the macros being expanded, to detect recursion.
+	expanding map[string]bool
+}
+
+func (p *parseState) addTransform(op parser.Params, parents ...parser.NodeID) parser.NodeID {
+	opTransform := parser.NewTransformFromOperation(op, len(p.transforms))
+	for _, parent := range parents {
+		p.edges = append(p.edges, parser.Edge{
+			ParentID: parent,
+			ChildID:  opTransform.ID,
+		})
+	}
+
+	p.transforms = append(p.transforms, opTransform)
+	return opTransform.ID
+}
+
+// walkPipeline adds the nodes of the pipeline and returns the ID of its last
+// node. Only the first expression of a pipeline may be a source, that is a
+// fetch, a macro or a nested pipeline; every other expression is applied to
+// the result of the one before it.
+func (p *parseState) walkPipeline(pl *pipeline) (parser.NodeID, error) {
+	var id parser.NodeID
+	for i, expr := range pl.expressions {
+		var err error
+		if i == 0 {
+			id, err = p.walkSource(expr)
+		} else {
+			id, err = p.walkFunction(expr, id)
+		}
+
+		if err != nil {
+			return id, err
+		}
+	}
+
+	return id, nil
+}
+
+func (p *parseState) walkSource(expr *expression) (parser.NodeID, error) {
+	if expr.nested != nil {
+		return p.walkPipeline(expr.nested)
+	}
+
+	if macro, ok := p.macros[expr.name]; ok && len(expr.arguments) == 0 {
+		if p.expanding[expr.name] {
+			return "", fmt.Errorf("macro %s is recursive", expr.name)
+		}
+
+		p.expanding[expr.name] = true
+		defer delete(p.expanding, expr.name)
+		return p.walkPipeline(macro)
+	}
+
+	if expr.name != fetchType {
+		return "", fmt.Errorf("pipeline must start with a fetch, found: %s", expr.name)
+	}
+
+	op, err := p.newFetchOp(expr)
+	if err != nil {
+		return "", err
+	}
+
+	return p.addTransform(op), nil
+}
+
+func (p *parseState) walkFunction(
+	expr *expression,
+	parent parser.NodeID,
+) (parser.NodeID, error) {
+	if expr.nested != nil {
+		return "", fmt.Errorf("nested pipelines are only supported at the start of a pipeline")
+	}
+
+	if _, ok := p.macros[expr.name]; ok {
+		return "", fmt.Errorf("macro %s is only supported at the start of a pipeline", expr.name)
+	}
+
+	if op, ok := aggregationTypes[expr.name]; ok {
+		tags, err := tagArguments(expr)
+		if err != nil {
+			return "", err
+		}
+
+		matchingTags := make([][]byte, 0, len(tags))
+		for _, tag := range tags {
+			matchingTags = append(matchingTags, []byte(tag))
+		}
+
+		aggOp, err := aggregation.NewAggregationOp(op, aggregation.NodeParams{
+			MatchingTags: matchingTags,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return p.addTransform(aggOp, parent), nil
+	}
+
+	if op, ok := mathTypes[expr.name]; ok {
+		if len(expr.arguments) != 0 {
+			return "", fmt.Errorf("%s takes no arguments", expr.name)
+		}
+
+		mathOp, err := linear.NewMathOp(op)
+		if err != nil {
+			return "", err
+		}
+
+		return p.addTransform(mathOp, parent), nil
+	}
+
+	if op, ok := scalarBinaryTypes[expr.name]; ok {
+		return p.walkScalarBinary(expr, op, parent)
+	}
+
+	switch expr.name {
+	case linear.TransformNullType:
+		// The replacement value defaults to zero.
+		var value float64
+		if len(expr.arguments) > 0 {
+			v, err := scalarArgument(expr)
+			if err != nil {
+				return "", err
+			}
+
+			value = v
+		}
+
+		transformNullOp, err := linear.NewTransformNullOp([]interface{}{value})
+		if err != nil {
+			return "", err
+		}
+
+		return p.addTransform(transformNullOp, parent), nil
+
+	case logarithmType:
+		return p.walkLogarithm(expr, parent)
+
+	case fetchType:
+		return "", fmt.Errorf("fetch is only supported at the start of a pipeline")
+
+	default:
+		return "", fmt.Errorf("function not supported: %s", expr.name)
+	}
+}
+
+// walkScalarBinary applies a binary operation between the parent and the
+// scalar argument of the expression.
+func (p *parseState) walkScalarBinary(
+	expr *expression,
+	opType string,
+	parent parser.NodeID,
+) (parser.NodeID, error) {
+	value, err := scalarArgument(expr)
+	if err != nil {
+		return "", err
+	}
+
+	return p.addScalarBinary(opType, value, parent)
+}
+
+// walkLogarithm applies the logarithm in the optional base argument of the
+// expression, other bases than 10 and 2 dividing the natural logarithm by
+// the natural logarithm of the base.
+func (p *parseState) walkLogarithm(
+	expr *expression,
+	parent parser.NodeID,
+) (parser.NodeID, error) {
+	base := float64(defaultLogarithmBase)
+	if len(expr.arguments) > 0 {
+		v, err := scalarArgument(expr)
+		if err != nil {
+			return "", err
+		}
+
+		base = v
+	}
+
+	if base <= 0 || base == 1 {
+		return "", fmt.Errorf("invalid %s base: %v", expr.name, base)
+	}
+
+	opType := linear.LnType
+	switch base {
+	case 10:
+		opType = linear.Log10Type
+	case 2:
+		opType = linear.Log2Type
+	}
+
+	mathOp, err := linear.NewMathOp(opType)
+	if err != nil {
+		return "", err
+	}
+
+	id := p.addTransform(mathOp, parent)
+	if opType != linear.LnType {
+		return id, nil
+	}
+
+	return p.addScalarBinary(binary.DivType, math.Log(base), id)
+}
+
+// addScalarBinary applies a binary operation between the parent and a
+// scalar value.
+func (p *parseState) addScalarBinary(
+	opType string,
+	value float64,
+	parent parser.NodeID,
+) (parser.NodeID, error) {
+	scalarOp, err := scalar.NewScalarOp(
+		func(_ time.Time) float64 { return value },
+		scalar.ScalarType,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	scalarID := p.addTransform(scalarOp)
+	op, err := binary.NewOp(opType, binary.NodeParams{
+		LNode:     parent,
+		RNode:     scalarID,
+		RIsScalar: true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return p.addTransform(op, parent, scalarID), nil
+}
+
+// newFetchOp creates a fetch from the keyword arguments of the expression,
+// each of which matches the tag named by its keyword against a glob.
+func (p *parseState) newFetchOp(expr *expression) (parser.Params, error) {
+	var (
+		name     string
+		matchers = make(models.Matchers, 0, len(expr.arguments))
+	)
+
+	for _, arg := range expr.arguments {
+		if arg.keyword == "" {
+			return nil, fmt.Errorf("fetch arguments must be keyword arguments, found: %s", arg.value)
+		}
+
+		if arg.kind == pipelineArgument {
+			return nil, fmt.Errorf("invalid fetch argument for %s", arg.keyword)
+		}
+
+		tagName := []byte(arg.keyword)
+		if arg.keyword == nameKeyword {
+			tagName = p.tagOpts.MetricName()
+			name = arg.value
+		}
+
+		matcher, err := newGlobMatcher(tagName, arg.value)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	if len(matchers) == 0 {
+		return nil, fmt.Errorf("fetch requires at least one tag to match")
+	}
+
+	return functions.FetchOp{
+		Name:     name,
+		Matchers: matchers,
+	}, nil
+}
+
+// newGlobMatcher returns an equality matcher for plain values, and a regexp
+// matcher for values containing glob symbols.
+func newGlobMatcher(name []byte, glob string) (models.Matcher, error) {
+	if !strings.ContainsAny(glob, globMetaChars) {
+		return models.NewMatcher(models.MatchEqual, name, []byte(glob))
+	}
+
+	return models.NewMatcher(models.MatchRegexp, name, []byte(globToRegexp(glob)))
+}
+
+// globToRegexp converts a glob, supporting "*", "?", character classes and
+// "{a,b}" alternatives, to a regular expression.
+func globToRegexp(glob string) string {
+	var (
+		b            strings.Builder
+		inClass      bool
+		alternatives int
+	)
+
+	for _, r := range glob {
+		switch {
+		case inClass:
+			if r == ']' {
+				inClass = false
+			}
+
+			b.WriteRune(r)
+		case r == '[':
+			inClass = true
+			b.WriteRune(r)
+		case r == '*':
+			b.WriteString(".*")
+		case r == '?':
+			b.WriteString(".")
+		case r == '{':
+			alternatives++
+			b.WriteString("(")
+		case r == '}' && alternatives > 0:
+			alternatives--
+			b.WriteString(")")
+		case r == ',' && alternatives > 0:
+			b.WriteString("|")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	return b.String()
+}
+
+// tagArguments returns the values of the pattern and string literal
+// arguments of the expression.
+func tagArguments(expr *expression) ([]string, error) {
+	values := make([]string, 0, len(expr.arguments))
+	for _, arg := range expr.arguments {
+		if arg.keyword != "" ||
+			(arg.kind != patternArgument && arg.kind != stringLiteralArgument) {
+			return nil, fmt.Errorf("invalid argument for %s: %s", expr.name, arg.value)
+		}
+
+		values = append(values, arg.value)
+	}
+
+	return values, nil
+}
+
+// scalarArgument returns the single numeric argument of the expression.
+func scalarArgument(expr *expression) (float64, error) {
+	if len(expr.arguments) != 1 ||
+		expr.arguments[0].kind != numericArgument ||
+		expr.arguments[0].keyword != "" {
+		return 0, fmt.Errorf("%s takes a single numeric argument", expr.name)
+	}
+
+	return strconv.ParseFloat(expr.arguments[0].value, 64)
+}
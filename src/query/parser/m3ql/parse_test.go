@@ -0,0 +1,168 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3ql
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/query/functions"
+	"github.com/m3db/m3/src/query/functions/aggregation"
+	"github.com/m3db/m3/src/query/functions/binary"
+	"github.com/m3db/m3/src/query/functions/linear"
+	"github.com/m3db/m3/src/query/functions/scalar"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDAGWithPipeline(t *testing.T) {
+	q := "fetch name:foo host:bar* | transformNull 0 | sum host"
+	p, err := Parse(q, models.NewTagOptions())
+	require.NoError(t, err)
+	assert.Equal(t, q, p.String())
+
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 3)
+	assert.Equal(t, functions.FetchType, transforms[0].Op.OpType())
+	assert.Equal(t, linear.TransformNullType, transforms[1].Op.OpType())
+	assert.Equal(t, aggregation.SumType, transforms[2].Op.OpType())
+
+	fetch, ok := transforms[0].Op.(functions.FetchOp)
+	require.True(t, ok)
+	assert.Equal(t, "foo", fetch.Name)
+	require.Len(t, fetch.Matchers, 2)
+	assert.Equal(t, models.MatchEqual, fetch.Matchers[0].Type)
+	assert.Equal(t, "__name__", string(fetch.Matchers[0].Name))
+	assert.Equal(t, models.MatchRegexp, fetch.Matchers[1].Type)
+	assert.Equal(t, "host", string(fetch.Matchers[1].Name))
+	assert.True(t, fetch.Matchers[1].Matches([]byte("barbaz")))
+	assert.False(t, fetch.Matchers[1].Matches([]byte("foobar")))
+
+	require.Len(t, edges, 2)
+	assert.Equal(t, parser.Edge{ParentID: "0", ChildID: "1"}, edges[0])
+	assert.Equal(t, parser.Edge{ParentID: "1", ChildID: "2"}, edges[1])
+}
+
+func TestDAGWithComparison(t *testing.T) {
+	p, err := Parse("fetch name:foo | ge 5 | scale 2", models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 5)
+	assert.Equal(t, functions.FetchType, transforms[0].Op.OpType())
+	assert.Equal(t, scalar.ScalarType, transforms[1].Op.OpType())
+	assert.Equal(t, binary.GreaterEqType, transforms[2].Op.OpType())
+	assert.Equal(t, scalar.ScalarType, transforms[3].Op.OpType())
+	assert.Equal(t, binary.MultiplyType, transforms[4].Op.OpType())
+
+	assert.Equal(t, parser.Edges{
+		{ParentID: "0", ChildID: "2"},
+		{ParentID: "1", ChildID: "2"},
+		{ParentID: "2", ChildID: "4"},
+		{ParentID: "3", ChildID: "4"},
+	}, edges)
+}
+
+func TestDAGWithMacroAndNesting(t *testing.T) {
+	q := "a = fetch name:foo | abs; (a | maxSeries) | squareRoot"
+	p, err := Parse(q, models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 4)
+	assert.Equal(t, functions.FetchType, transforms[0].Op.OpType())
+	assert.Equal(t, linear.AbsType, transforms[1].Op.OpType())
+	assert.Equal(t, aggregation.MaxType, transforms[2].Op.OpType())
+	assert.Equal(t, linear.SqrtType, transforms[3].Op.OpType())
+	assert.Len(t, edges, 3)
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob, expected string
+	}{
+		{"foo*", "foo.*"},
+		{"f?o", "f.o"},
+		{"{foo,bar}.baz", `(foo|bar)\.baz`},
+		{"[a-c]x", "[a-c]x"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, globToRegexp(tt.glob), tt.glob)
+	}
+}
+
+func TestDAGWithLogarithm(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		ops   []string
+	}{
+		{"fetch name:foo | logarithm", []string{functions.FetchType, linear.Log10Type}},
+		{"fetch name:foo | logarithm 2", []string{functions.FetchType, linear.Log2Type}},
+		{"fetch name:foo | logarithm 3", []string{functions.FetchType, linear.LnType,
+			scalar.ScalarType, binary.DivType}},
+	} {
+		p, err := Parse(tt.query, models.NewTagOptions())
+		require.NoError(t, err, tt.query)
+		transforms, _, err := p.DAG()
+		require.NoError(t, err, tt.query)
+
+		ops := make([]string, 0, len(transforms))
+		for _, transform := range transforms {
+			ops = append(ops, transform.Op.OpType())
+		}
+
+		assert.Equal(t, tt.ops, ops, tt.query)
+	}
+}
+
+func TestDAGErrors(t *testing.T) {
+	tests := []string{
+		"sum host",
+		"fetch foo",
+		"fetch name:foo | fake",
+		"fetch name:foo | transformNull foo",
+		"fetch name:foo | scale foo",
+		"fetch name:foo | abs 1",
+		"fetch name:foo | logarithm 1",
+		"fetch name:foo | logarithm foo",
+		"fetch name:foo | fetch name:bar",
+		"a = a | abs; a",
+	}
+
+	for _, q := range tests {
+		p, err := Parse(q, models.NewTagOptions())
+		require.NoError(t, err, q)
+		_, _, err = p.DAG()
+		assert.Error(t, err, q)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse("fetch name:foo |", models.NewTagOptions())
+	assert.Error(t, err)
+
+	_, err = Parse("a = fetch name:foo; a = fetch name:bar; a", models.NewTagOptions())
+	assert.Error(t, err)
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package m3ql
+
+import (
+	"fmt"
+)
+
+type argumentKind int
+
+const (
+	booleanArgument argumentKind = iota
+	numericArgument
+	patternArgument
+	stringLiteralArgument
+	pipelineArgument
+)
+
+// script is a parsed M3QL query, consisting of its macro definitions and the
+// pipeline which is evaluated.
+type script struct {
+	macros   map[string]*pipeline
+	pipeline *pipeline
+}
+
+// pipeline is a sequence of expressions, each applied to the result of the
+// one before it.
+type pipeline struct {
+	expressions []*expression
+}
+
+// expression is either a function call or a nested pipeline.
+type expression struct {
+	name      string
+	arguments []argument
+	nested    *pipeline
+
+	// keyword is the pending keyword of the next argument.
+	keyword string
+}
+
+type argument struct {
+	keyword  string
+	kind     argumentKind
+	value    string
+	pipeline *pipeline
+}
+
+// builder implements scriptBuilder, assembling the script from the callbacks
+// of the generated parser.
+type builder struct {
+	script *script
+	macro  string
+	// stack holds the open pipelines and expressions, innermost last.
+	stack []interface{}
+	err   error
+}
+
+var _ scriptBuilder = (*builder)(nil)
+
+func newBuilder() *builder {
+	return &builder{
+		script: &script{macros: make(map[string]*pipeline)},
+	}
+}
+
+func (b *builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *builder) push(item interface{}) {
+	b.stack = append(b.stack, item)
+}
+
+func (b *builder) pop() interface{} {
+	if len(b.stack) == 0 {
+		return nil
+	}
+
+	item := b.stack[len(b.stack)-1]
+	b.stack = b.stack[:len(b.stack)-1]
+	return item
+}
+
+func (b *builder) peek() interface{} {
+	if len(b.stack) == 0 {
+		return nil
+	}
+
+	return b.stack[len(b.stack)-1]
+}
+
+func (b *builder) newMacro(name string) {
+	if _, ok := b.script.macros[name]; ok {
+		b.fail(fmt.Errorf("macro %s is defined more than once", name))
+	}
+
+	b.macro = name
+}
+
+func (b *builder) newPipeline() {
+	b.push(&pipeline{})
+}
+
+func (b *builder) endPipeline() {
+	p, ok := b.pop().(*pipeline)
+	if !ok {
+		b.fail(fmt.Errorf("unexpected end of pipeline"))
+		return
+	}
+
+	switch parent := b.peek().(type) {
+	case nil:
+		if b.macro != "" {
+			b.script.macros[b.macro] = p
+			b.macro = ""
+			return
+		}
+
+		b.script.pipeline = p
+
+	case *expression:
+		// A nested pipeline given as an argument.
+		parent.arguments = append(parent.arguments, argument{
+			keyword:  parent.keyword,
+			kind:     pipelineArgument,
+			pipeline: p,
+		})
+		parent.keyword = ""
+
+	case *pipeline:
+		// A nested pipeline used in place of an expression.
+		parent.expressions = append(parent.expressions, &expression{nested: p})
+	}
+}
+
+func (b *builder) newExpression(name string) {
+	b.push(&expression{name: name})
+}
+
+func (b *builder) endExpression() {
+	e, ok := b.pop().(*expression)
+	if !ok {
+		b.fail(fmt.Errorf("unexpected end of expression"))
+		return
+	}
+
+	p, ok := b.peek().(*pipeline)
+	if !ok {
+		b.fail(fmt.Errorf("expression %s outside of a pipeline", e.name))
+		return
+	}
+
+	p.expressions = append(p.expressions, e)
+}
+
+func (b *builder) newArgument(kind argumentKind, value string) {
+	e, ok := b.peek().(*expression)
+	if !ok {
+		b.fail(fmt.Errorf("argument %s outside of an expression", value))
+		return
+	}
+
+	e.arguments = append(e.arguments, argument{
+		keyword: e.keyword,
+		kind:    kind,
+		value:   value,
+	})
+	e.keyword = ""
+}
+
+func (b *builder) newBooleanArgument(value string) {
+	b.newArgument(booleanArgument, value)
+}
+
+func (b *builder) newNumericArgument(value string) {
+	b.newArgument(numericArgument, value)
+}
+
+func (b *builder) newPatternArgument(value string) {
+	b.newArgument(patternArgument, value)
+}
+
+func (b *builder) newStringLiteralArgument(value string) {
+	b.newArgument(stringLiteralArgument, value)
+}
+
+func (b *builder) newKeywordArgument(keyword string) {
+	e, ok := b.peek().(*expression)
+	if !ok {
+		b.fail(fmt.Errorf("keyword %s outside of an expression", keyword))
+		return
+	}
+
+	e.keyword = keyword
+}
+
+// parseScript parses the query into a script.
+func parseScript(query string) (*script, error) {
+	b := newBuilder()
+	p := &m3ql{Buffer: query, scriptBuilder: b}
+	p.Init()
+	if err := p.Parse(); err != nil {
+		return nil, err
+	}
+
+	p.Execute()
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if b.script.pipeline == nil {
+		return nil, fmt.Errorf("no pipeline in query: %s", query)
+	}
+
+	return b.script, nil
+}
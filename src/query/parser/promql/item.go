@@ -39,12 +39,14 @@ const (
 	itemRightBracket
 	itemComma
 	itemAssign
+	itemColon
 	itemSemicolon
 	itemString
 	itemNumber
 	itemDuration
 	itemBlank
 	itemTimes
+	itemSpace
 
 	operatorsStart
 	// Operators.
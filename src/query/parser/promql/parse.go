@@ -21,6 +21,7 @@
 package promql
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/m3db/m3/src/query/functions/scalar"
@@ -30,6 +31,9 @@ import (
 	pql "github.com/prometheus/prometheus/promql"
 )
 
+var errStringLiteralNotArgument = errors.New(
+	"string literals are only supported as function arguments")
+
 type promParser struct {
 	expr    pql.Expr
 	tagOpts models.TagOptions
@@ -131,7 +135,9 @@ func (p *parseState) walk(node pql.Node) error {
 		stringValues := make([]string, 0, len(expressions))
 		for i, argType := range argTypes {
 			expr := expressions[i]
-			if argType == pql.ValueTypeScalar {
+			// NB: the argument to vector is evaluated as a scalar block, which
+			// already presents as a single series without tags.
+			if argType == pql.ValueTypeScalar && n.Func.Name != scalar.VectorType {
 				val, err := resolveScalarArgument(expr)
 				if err != nil {
 					return err
@@ -139,9 +145,17 @@ func (p *parseState) walk(node pql.Node) error {
 
 				argValues = append(argValues, val)
 			} else if argType == pql.ValueTypeString {
-				stringValues = append(stringValues, expr.(*pql.StringLiteral).Val)
+				val, err := resolveStringArgument(expr)
+				if err != nil {
+					return err
+				}
+
+				stringValues = append(stringValues, val)
 			} else {
-				if e, ok := expr.(*pql.MatrixSelector); ok {
+				switch e := expr.(type) {
+				case *pql.MatrixSelector:
+					argValues = append(argValues, e.Range)
+				case *pql.SubqueryExpr:
 					argValues = append(argValues, e.Range)
 				}
 
@@ -155,7 +169,12 @@ func (p *parseState) walk(node pql.Node) error {
 			l := len(argTypes)
 			for _, expr := range expressions[l:] {
 				if argTypes[l-1] == pql.ValueTypeString {
-					stringValues = append(stringValues, expr.(*pql.StringLiteral).Val)
+					val, err := resolveStringArgument(expr)
+					if err != nil {
+						return err
+					}
+
+					stringValues = append(stringValues, val)
 				}
 			}
 		}
@@ -219,6 +238,57 @@ func (p *parseState) walk(node pql.Node) error {
 		p.transforms = append(p.transforms, opTransform)
 		return nil
 
+	case *pql.UnaryExpr:
+		if n.Op == pql.ItemType(itemADD) {
+			return p.walk(n.Expr)
+		}
+
+		// Negation is evaluated as multiplication by a scalar -1.
+		scalarOp, err := NewNegationScalarOperator()
+		if err != nil {
+			return err
+		}
+
+		scalarTransform := parser.NewTransformFromOperation(scalarOp, p.transformLen())
+		p.transforms = append(p.transforms, scalarTransform)
+		lhsID := scalarTransform.ID
+		if err := p.walk(n.Expr); err != nil {
+			return err
+		}
+
+		rhsID := p.lastTransformID()
+		op, err := NewUnaryOperator(n, lhsID, rhsID)
+		if err != nil {
+			return err
+		}
+
+		opTransform := parser.NewTransformFromOperation(op, p.transformLen())
+		p.edges = append(p.edges, parser.Edge{
+			ParentID: lhsID,
+			ChildID:  opTransform.ID,
+		})
+		p.edges = append(p.edges, parser.Edge{
+			ParentID: rhsID,
+			ChildID:  opTransform.ID,
+		})
+		p.transforms = append(p.transforms, opTransform)
+		return nil
+
+	case *pql.SubqueryExpr:
+		// The inner expression is evaluated separately at the subquery step,
+		// so it is walked into its own DAG.
+		inner := &parseState{tagOpts: p.tagOpts}
+		if err := inner.walk(n.Expr); err != nil {
+			return err
+		}
+
+		op := NewSubqueryOperator(n, inner.transforms, inner.edges)
+		p.transforms = append(p.transforms, parser.NewTransformFromOperation(op, p.transformLen()))
+		return nil
+
+	case *pql.StringLiteral:
+		return errStringLiteralNotArgument
+
 	case *pql.ParenExpr:
 		// Evaluate inside of paren expressions
 		return p.walk(n.Expr)
@@ -22,6 +22,7 @@ package promql
 
 import (
 	"testing"
+	"time"
 
 	"github.com/m3db/m3/src/query/functions"
 	"github.com/m3db/m3/src/query/functions/aggregation"
@@ -365,3 +366,78 @@ func TestFailedTemporalParse(t *testing.T) {
 	_, err := Parse(q, models.NewTagOptions())
 	require.Error(t, err)
 }
+
+func TestUnaryParses(t *testing.T) {
+	p, err := Parse("-up", models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 3)
+	assert.Equal(t, transforms[0].Op.OpType(), scalar.ScalarType)
+	assert.Equal(t, transforms[1].Op.OpType(), functions.FetchType)
+	assert.Equal(t, transforms[2].Op.OpType(), binary.MultiplyType)
+	require.Len(t, edges, 2)
+	assert.Equal(t, edges[0].ParentID, parser.NodeID("0"))
+	assert.Equal(t, edges[0].ChildID, parser.NodeID("2"))
+	assert.Equal(t, edges[1].ParentID, parser.NodeID("1"))
+	assert.Equal(t, edges[1].ChildID, parser.NodeID("2"))
+}
+
+func TestUnaryPlusParses(t *testing.T) {
+	p, err := Parse("+up", models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 1)
+	assert.Equal(t, transforms[0].Op.OpType(), functions.FetchType)
+	assert.Len(t, edges, 0)
+}
+
+func TestVectorParses(t *testing.T) {
+	p, err := Parse("vector(1)", models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 1)
+	assert.Equal(t, transforms[0].Op.OpType(), scalar.ScalarType)
+	assert.Len(t, edges, 0)
+}
+
+func TestSubqueryParses(t *testing.T) {
+	q := "max_over_time(rate(up[1m])[1h:1m] offset 5m)"
+	p, err := Parse(q, models.NewTagOptions())
+	require.NoError(t, err)
+	transforms, edges, err := p.DAG()
+	require.NoError(t, err)
+	require.Len(t, transforms, 2)
+	assert.Equal(t, transforms[0].Op.OpType(), functions.SubqueryType)
+	assert.Equal(t, transforms[1].Op.OpType(), temporal.MaxType)
+	require.Len(t, edges, 1)
+	assert.Equal(t, edges[0].ParentID, parser.NodeID("0"))
+	assert.Equal(t, edges[0].ChildID, parser.NodeID("1"))
+
+	subquery, ok := transforms[0].Op.(functions.SubqueryOp)
+	require.True(t, ok)
+	assert.Equal(t, time.Hour, subquery.Range)
+	assert.Equal(t, time.Minute, subquery.Step)
+	assert.Equal(t, 5*time.Minute, subquery.Offset)
+
+	require.Len(t, subquery.Nodes, 2)
+	assert.Equal(t, subquery.Nodes[0].Op.OpType(), functions.FetchType)
+	assert.Equal(t, subquery.Nodes[1].Op.OpType(), temporal.RateType)
+	require.Len(t, subquery.Edges, 1)
+	assert.Equal(t, subquery.Edges[0].ParentID, parser.NodeID("0"))
+	assert.Equal(t, subquery.Edges[0].ChildID, parser.NodeID("1"))
+}
+
+func TestStringLiteralParses(t *testing.T) {
+	p, err := Parse(`"foo"`, models.NewTagOptions())
+	require.NoError(t, err)
+	_, _, err = p.DAG()
+	assert.Equal(t, errStringLiteralNotArgument, err)
+
+	p, err = Parse(`count_values(("some_name"), up)`, models.NewTagOptions())
+	require.NoError(t, err)
+	_, _, err = p.DAG()
+	require.NoError(t, err)
+}
@@ -109,6 +109,18 @@ func resolveScalarArgumentWithNesting(expr pql.Expr, nesting int) (float64, int,
 	case *pql.NumberLiteral:
 		return n.Val, 0, nil
 
+	case *pql.UnaryExpr:
+		value, nesting, err := resolveScalarArgumentWithNesting(n.Expr, nesting)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if n.Op == pql.ItemType(itemSUB) {
+			value = -value
+		}
+
+		return value, nesting, nil
+
 	case *pql.ParenExpr:
 		// Evaluate inside of paren expressions
 		return resolveScalarArgumentWithNesting(n.Expr, nesting)
@@ -116,3 +128,17 @@ func resolveScalarArgumentWithNesting(expr pql.Expr, nesting int) (float64, int,
 
 	return 0, 0, fmt.Errorf("resolveScalarArgument: unhandled node type %T, %v", expr, expr)
 }
+
+// resolves an expression which should resolve to a string argument
+func resolveStringArgument(expr pql.Expr) (string, error) {
+	switch n := expr.(type) {
+	case *pql.StringLiteral:
+		return n.Val, nil
+
+	case *pql.ParenExpr:
+		// Evaluate inside of paren expressions
+		return resolveStringArgument(n.Expr)
+	}
+
+	return "", fmt.Errorf("resolveStringArgument: unhandled node type %T, %v", expr, expr)
+}
@@ -104,7 +104,12 @@ func NewAggregationOperator(expr *promql.AggregateExpr) (parser.Params, error) {
 	}
 
 	if op == aggregation.CountValuesType {
-		nodeInformation.StringParameter = expr.Param.String()
+		val, err := resolveStringArgument(expr.Param)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeInformation.StringParameter = val
 		return aggregation.NewCountValuesOp(op, nodeInformation)
 	}
 
@@ -149,6 +154,46 @@ func NewScalarOperator(expr *promql.NumberLiteral) (parser.Params, error) {
 	)
 }
 
+// NewNegationScalarOperator creates the scalar that unary negation multiplies
+// its expression by
+func NewNegationScalarOperator() (parser.Params, error) {
+	return scalar.NewScalarOp(
+		func(_ time.Time) float64 { return -1 },
+		scalar.ScalarType,
+	)
+}
+
+// NewUnaryOperator creates a new unary negation operator, which multiplies
+// the expression with the negation scalar on the left hand side
+func NewUnaryOperator(expr *promql.UnaryExpr, lhs, rhs parser.NodeID) (parser.Params, error) {
+	if expr.Op != promql.ItemType(itemSUB) {
+		return nil, fmt.Errorf("unary operator not supported: %s", expr.Op)
+	}
+
+	return binary.NewOp(binary.MultiplyType, binary.NodeParams{
+		LNode:     lhs,
+		RNode:     rhs,
+		LIsScalar: true,
+		RIsScalar: expr.Expr.Type() == promql.ValueTypeScalar,
+	})
+}
+
+// NewSubqueryOperator creates a new subquery operator from the DAG of its
+// inner expression
+func NewSubqueryOperator(
+	expr *promql.SubqueryExpr,
+	nodes parser.Nodes,
+	edges parser.Edges,
+) parser.Params {
+	return functions.SubqueryOp{
+		Nodes:  nodes,
+		Edges:  edges,
+		Range:  expr.Range,
+		Offset: expr.Offset,
+		Step:   expr.Step,
+	}
+}
+
 // NewBinaryOperator creates a new binary operator based on the type
 func NewBinaryOperator(expr *promql.BinaryExpr, lhs, rhs parser.NodeID) (parser.Params, error) {
 	matching := promMatchingToM3(expr.VectorMatching)
@@ -236,7 +281,7 @@ func NewFunctionExpr(
 	case linear.SortType, linear.SortDescType:
 		return nil, false, err
 
-	case scalar.ScalarType:
+	case scalar.ScalarType, scalar.VectorType:
 		return nil, false, err
 
 	case unconsolidated.TimestampType:
@@ -0,0 +1,232 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+)
+
+const (
+	alertNameLabel = "alertname"
+
+	// resolvedRetention is how long resolved alerts are kept so that their
+	// resolution is sent even if a notification fails.
+	resolvedRetention = 15 * time.Minute
+)
+
+type alertingRule struct {
+	name        string
+	expr        string
+	forDuration time.Duration
+	labels      map[string]string
+	annotations map[string]*template.Template
+	active      map[uint64]*Alert
+}
+
+func newAlertingRule(cfg RuleConfiguration) (*alertingRule, error) {
+	annotations := make(map[string]*template.Template, len(cfg.Annotations))
+	for name, text := range cfg.Annotations {
+		// Make the $labels and $value variables available to templates the
+		// same way Prometheus does.
+		tmpl, err := template.New(name).
+			Option("missingkey=zero").
+			Parse("{{$labels := .Labels}}{{$value := .Value}}" + text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid annotation %s for alert %s: %v",
+				name, cfg.Alert, err)
+		}
+		annotations[name] = tmpl
+	}
+
+	return &alertingRule{
+		name:        cfg.Alert,
+		expr:        cfg.Expr,
+		forDuration: cfg.For,
+		labels:      cfg.Labels,
+		annotations: annotations,
+		active:      make(map[uint64]*Alert),
+	}, nil
+}
+
+func (r *alertingRule) Name() string {
+	return r.name
+}
+
+func (r *alertingRule) Eval(
+	ctx context.Context,
+	t time.Time,
+	query QueryFunc,
+) error {
+	series, err := query(ctx, r.expr, t)
+	if err != nil {
+		return err
+	}
+
+	samples := samplesFromSeries(series)
+	current := make(map[uint64]struct{}, len(samples))
+	for _, s := range samples {
+		tags := withLabels(s.tags.WithoutName(), r.labels)
+		tags = tags.AddOrUpdateTag(models.Tag{
+			Name:  []byte(alertNameLabel),
+			Value: []byte(r.name),
+		})
+
+		id := tags.HashedID()
+		if _, ok := current[id]; ok {
+			return fmt.Errorf("alerting rule %s produced duplicate alert: %s",
+				r.name, tags.ID())
+		}
+		current[id] = struct{}{}
+
+		labels := tagsToLabels(tags)
+		annotations := r.expandAnnotations(labels, s.value)
+		if alert, ok := r.active[id]; ok && alert.State != AlertStateInactive {
+			alert.Value = s.value
+			alert.Annotations = annotations
+			continue
+		}
+
+		r.active[id] = &Alert{
+			Labels:      labels,
+			Annotations: annotations,
+			State:       AlertStatePending,
+			Value:       s.value,
+			ActiveAt:    t,
+		}
+	}
+
+	for id, alert := range r.active {
+		if _, ok := current[id]; !ok {
+			switch {
+			case alert.State == AlertStatePending:
+				delete(r.active, id)
+			case alert.State == AlertStateFiring:
+				alert.State = AlertStateInactive
+				alert.ResolvedAt = t
+			case t.Sub(alert.ResolvedAt) >= resolvedRetention:
+				delete(r.active, id)
+			}
+			continue
+		}
+
+		if alert.State == AlertStatePending && t.Sub(alert.ActiveAt) >= r.forDuration {
+			alert.State = AlertStateFiring
+			alert.FiredAt = t
+		}
+	}
+
+	return nil
+}
+
+// alertsToSend returns the firing alerts that have not been sent within the
+// resend delay and the resolved alerts whose resolution has not been sent.
+func (r *alertingRule) alertsToSend(t time.Time, resendDelay time.Duration) []*Alert {
+	var alerts []*Alert
+	for _, alert := range r.active {
+		switch alert.State {
+		case AlertStateFiring:
+			if alert.LastSentAt.IsZero() || t.Sub(alert.LastSentAt) >= resendDelay {
+				alerts = append(alerts, alert)
+			}
+		case AlertStateInactive:
+			if alert.LastSentAt.Before(alert.ResolvedAt) {
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+
+	return alerts
+}
+
+// alerts returns the current alerts of the rule.
+func (r *alertingRule) alerts() []*Alert {
+	alerts := make([]*Alert, 0, len(r.active))
+	for _, alert := range r.active {
+		alerts = append(alerts, alert)
+	}
+
+	return alerts
+}
+
+// restore replaces the current alerts of the rule with the given alerts.
+func (r *alertingRule) restore(alerts []*Alert, tagOpts models.TagOptions) {
+	r.active = make(map[uint64]*Alert, len(alerts))
+	for _, alert := range alerts {
+		tags := labelsToTags(alert.Labels, tagOpts)
+		r.active[tags.HashedID()] = alert
+	}
+}
+
+type templateData struct {
+	Labels map[string]string
+	Value  float64
+}
+
+func (r *alertingRule) expandAnnotations(
+	labels map[string]string,
+	value float64,
+) map[string]string {
+	annotations := make(map[string]string, len(r.annotations))
+	data := templateData{Labels: labels, Value: value}
+	for name, tmpl := range r.annotations {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			// Surface the failure in the annotation itself rather than
+			// dropping the alert.
+			annotations[name] = fmt.Sprintf("<error expanding template: %v>", err)
+			continue
+		}
+		annotations[name] = buf.String()
+	}
+
+	return annotations
+}
+
+func tagsToLabels(tags models.Tags) map[string]string {
+	labels := make(map[string]string, tags.Len())
+	for _, tag := range tags.Tags {
+		labels[string(tag.Name)] = string(tag.Value)
+	}
+
+	return labels
+}
+
+func labelsToTags(labels map[string]string, tagOpts models.TagOptions) models.Tags {
+	tags := models.NewTags(len(labels), tagOpts)
+	for name, value := range labels {
+		tags = tags.AddTag(models.Tag{Name: []byte(name), Value: []byte(value)})
+	}
+
+	return tags
+}
+
+// ruleKey returns the key that the alerts of the rule at the given index of
+// a group are persisted under.
+func ruleKey(idx int, name string) string {
+	return strconv.Itoa(idx) + "/" + name
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package rules evaluates Prometheus compatible recording and alerting rules.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	clusterclient "github.com/m3db/m3/src/cluster/client"
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	defaultEvaluationInterval = time.Minute
+	defaultQueryTimeout       = 30 * time.Second
+	defaultResendDelay        = time.Minute
+	defaultStateKeyPrefix     = "_rules/alert-state"
+	defaultElectionID         = "m3query-rules"
+)
+
+var (
+	errNoRuleFiles        = errors.New("no rule files specified")
+	errRuleNameRequired   = errors.New("exactly one of record or alert must be set")
+	errRuleExprRequired   = errors.New("rule expression must be set")
+	errRecordingRuleFor   = errors.New("recording rules do not support for")
+	errRecordingRuleAnnot = errors.New("recording rules do not support annotations")
+	errElectionNoCluster  = errors.New("rule leader election requires a cluster client")
+)
+
+// Configuration configures rule evaluation.
+type Configuration struct {
+	// RuleFiles are the paths of the Prometheus rule group files to load.
+	RuleFiles []string `yaml:"ruleFiles"`
+
+	// EvaluationInterval is the interval used for groups that do not
+	// specify their own.
+	EvaluationInterval *time.Duration `yaml:"evaluationInterval"`
+
+	// QueryTimeout is the timeout for evaluating a single rule.
+	QueryTimeout *time.Duration `yaml:"queryTimeout"`
+
+	// AlertmanagerURL is the Alertmanager compatible URL that alerts are
+	// posted to, alerts are not sent if empty.
+	AlertmanagerURL string `yaml:"alertmanagerURL"`
+
+	// ResendDelay is the minimum delay before resending a firing alert.
+	ResendDelay *time.Duration `yaml:"resendDelay"`
+
+	// StateKeyPrefix is the cluster KV key prefix that alert state is
+	// persisted under.
+	StateKeyPrefix string `yaml:"stateKeyPrefix"`
+
+	// Election configures leader election so that only one instance
+	// evaluates rules at a time, every instance evaluates rules if not set.
+	Election *ElectionConfiguration `yaml:"election"`
+}
+
+// ElectionConfiguration configures rule evaluation leader election.
+type ElectionConfiguration struct {
+	// Service is the service ID the election is held under.
+	Service services.ServiceIDConfiguration `yaml:"service"`
+
+	// ElectionID is the ID of the election.
+	ElectionID string `yaml:"electionID"`
+
+	// Options are the election options.
+	Options services.ElectionConfiguration `yaml:"options"`
+}
+
+// ElectionIDOrDefault returns the election ID or the default if not set.
+func (c ElectionConfiguration) ElectionIDOrDefault() string {
+	if c.ElectionID == "" {
+		return defaultElectionID
+	}
+	return c.ElectionID
+}
+
+// EvaluationIntervalOrDefault returns the evaluation interval or the
+// default if not set.
+func (c Configuration) EvaluationIntervalOrDefault() time.Duration {
+	if c.EvaluationInterval == nil {
+		return defaultEvaluationInterval
+	}
+	return *c.EvaluationInterval
+}
+
+// QueryTimeoutOrDefault returns the query timeout or the default if not set.
+func (c Configuration) QueryTimeoutOrDefault() time.Duration {
+	if c.QueryTimeout == nil {
+		return defaultQueryTimeout
+	}
+	return *c.QueryTimeout
+}
+
+// ResendDelayOrDefault returns the resend delay or the default if not set.
+func (c Configuration) ResendDelayOrDefault() time.Duration {
+	if c.ResendDelay == nil {
+		return defaultResendDelay
+	}
+	return *c.ResendDelay
+}
+
+// StateKeyPrefixOrDefault returns the state key prefix or the default if
+// not set.
+func (c Configuration) StateKeyPrefixOrDefault() string {
+	if c.StateKeyPrefix == "" {
+		return defaultStateKeyPrefix
+	}
+	return c.StateKeyPrefix
+}
+
+// LoadGroups loads and validates the rule groups from the rule files.
+func (c Configuration) LoadGroups(tagOpts models.TagOptions) ([]GroupConfiguration, error) {
+	if len(c.RuleFiles) == 0 {
+		return nil, errNoRuleFiles
+	}
+
+	var groups []GroupConfiguration
+	for _, file := range c.RuleFiles {
+		fileGroups, err := LoadFile(file, tagOpts)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, fileGroups...)
+	}
+
+	seen := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		if _, ok := seen[group.Name]; ok {
+			return nil, fmt.Errorf("duplicate rule group: %s", group.Name)
+		}
+		seen[group.Name] = struct{}{}
+	}
+	return groups, nil
+}
+
+// NewManager creates a rule manager from the configuration, the given
+// options must have the query function and storage set. Alert state is
+// persisted in cluster KV if a cluster client is given.
+func (c Configuration) NewManager(
+	opts Options,
+	clusterClient clusterclient.Client,
+) (Manager, error) {
+	groups, err := c.LoadGroups(opts.TagOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	opts = opts.
+		SetEvaluationInterval(c.EvaluationIntervalOrDefault()).
+		SetQueryTimeout(c.QueryTimeoutOrDefault()).
+		SetResendDelay(c.ResendDelayOrDefault())
+
+	if c.AlertmanagerURL != "" {
+		opts = opts.SetNotifier(NewWebhookNotifier(c.AlertmanagerURL, "",
+			c.ResendDelayOrDefault(), nil))
+	}
+
+	if clusterClient != nil {
+		store, err := clusterClient.KV()
+		if err != nil {
+			return nil, err
+		}
+		opts = opts.SetStateStore(NewKVStateStore(store, c.StateKeyPrefixOrDefault()))
+	}
+
+	if c.Election != nil {
+		if clusterClient == nil {
+			return nil, errElectionNoCluster
+		}
+
+		svcs, err := clusterClient.Services(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		leaderService, err := svcs.LeaderService(c.Election.Service.NewServiceID(),
+			c.Election.Options.NewOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		opts = opts.
+			SetLeaderService(leaderService).
+			SetElectionID(c.Election.ElectionIDOrDefault())
+	}
+
+	return NewManager(groups, opts)
+}
+
+// GroupsConfiguration is the Prometheus rule group file format.
+type GroupsConfiguration struct {
+	Groups []GroupConfiguration `yaml:"groups"`
+}
+
+// GroupConfiguration is a group of rules evaluated sequentially on the
+// same interval.
+type GroupConfiguration struct {
+	Name     string              `yaml:"name"`
+	Interval time.Duration       `yaml:"interval"`
+	Rules    []RuleConfiguration `yaml:"rules"`
+}
+
+// RuleConfiguration is a recording or alerting rule.
+type RuleConfiguration struct {
+	Record      string            `yaml:"record"`
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         time.Duration     `yaml:"for"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// LoadFile loads and validates the rule groups in a Prometheus rule file.
+func LoadFile(path string, tagOpts models.TagOptions) ([]GroupConfiguration, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := ParseGroups(data, tagOpts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule file %s: %v", path, err)
+	}
+	return groups, nil
+}
+
+// ParseGroups parses and validates Prometheus rule groups.
+func ParseGroups(data []byte, tagOpts models.TagOptions) ([]GroupConfiguration, error) {
+	var cfg GroupsConfiguration
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	for _, group := range cfg.Groups {
+		if err := group.Validate(tagOpts); err != nil {
+			return nil, err
+		}
+	}
+	return cfg.Groups, nil
+}
+
+// Validate validates the group and that each of its rule expressions parse.
+func (g GroupConfiguration) Validate(tagOpts models.TagOptions) error {
+	if g.Name == "" {
+		return errors.New("rule group name must be set")
+	}
+	if g.Interval < 0 {
+		return fmt.Errorf("rule group %s has negative interval", g.Name)
+	}
+
+	for i, rule := range g.Rules {
+		if err := rule.Validate(tagOpts); err != nil {
+			return fmt.Errorf("rule group %s rule %d: %v", g.Name, i, err)
+		}
+	}
+	return nil
+}
+
+// Validate validates the rule and that its expression parses.
+func (r RuleConfiguration) Validate(tagOpts models.TagOptions) error {
+	if (r.Record == "") == (r.Alert == "") {
+		return errRuleNameRequired
+	}
+	if r.Expr == "" {
+		return errRuleExprRequired
+	}
+	if r.Record != "" {
+		if r.For != 0 {
+			return errRecordingRuleFor
+		}
+		if len(r.Annotations) > 0 {
+			return errRecordingRuleAnnot
+		}
+	}
+	if r.For < 0 {
+		return fmt.Errorf("rule has negative for duration: %v", r.For)
+	}
+
+	if _, err := promql.Parse(r.Expr, tagOpts); err != nil {
+		return fmt.Errorf("invalid expression %q: %v", r.Expr, err)
+	}
+	return nil
+}
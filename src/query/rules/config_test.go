@@ -0,0 +1,117 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroups(t *testing.T) {
+	data := []byte(`
+groups:
+  - name: example
+    interval: 30s
+    rules:
+      - record: job:up:sum
+        expr: sum(up) by (job)
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        labels:
+          severity: page
+        annotations:
+          summary: "{{ $labels.instance }} down"
+`)
+
+	groups, err := ParseGroups(data, models.NewTagOptions())
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+
+	group := groups[0]
+	assert.Equal(t, "example", group.Name)
+	assert.Equal(t, 30*time.Second, group.Interval)
+	require.Len(t, group.Rules, 2)
+	assert.Equal(t, "job:up:sum", group.Rules[0].Record)
+	assert.Equal(t, "InstanceDown", group.Rules[1].Alert)
+	assert.Equal(t, 5*time.Minute, group.Rules[1].For)
+	assert.Equal(t, "page", group.Rules[1].Labels["severity"])
+}
+
+func TestParseGroupsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "record and alert",
+			data: `
+groups:
+  - name: example
+    rules:
+      - record: a
+        alert: b
+        expr: up
+`,
+		},
+		{
+			name: "invalid expression",
+			data: `
+groups:
+  - name: example
+    rules:
+      - record: a
+        expr: sum(up
+`,
+		},
+		{
+			name: "recording rule with for",
+			data: `
+groups:
+  - name: example
+    rules:
+      - record: a
+        expr: up
+        for: 1m
+`,
+		},
+		{
+			name: "unknown field",
+			data: `
+groups:
+  - name: example
+    unknown: true
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGroups([]byte(tt.data), models.NewTagOptions())
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+type groupMetrics struct {
+	evaluations        tally.Counter
+	evaluationErrors   tally.Counter
+	evaluationLatency  tally.Timer
+	notifications      tally.Counter
+	notificationErrors tally.Counter
+	stateErrors        tally.Counter
+}
+
+func newGroupMetrics(scope tally.Scope) groupMetrics {
+	return groupMetrics{
+		evaluations:        scope.Counter("evaluations"),
+		evaluationErrors:   scope.Counter("evaluation-errors"),
+		evaluationLatency:  scope.Timer("evaluation-latency"),
+		notifications:      scope.Counter("notifications"),
+		notificationErrors: scope.Counter("notification-errors"),
+		stateErrors:        scope.Counter("state-errors"),
+	}
+}
+
+type groupRule struct {
+	key      string
+	rule     Rule
+	alerting *alertingRule
+}
+
+type group struct {
+	name     string
+	interval time.Duration
+	rules    []groupRule
+	opts     Options
+	isLeader func() bool
+	logger   *zap.Logger
+	metrics  groupMetrics
+
+	// restored is only accessed by the evaluation loop and tracks whether
+	// alert state has been restored since this instance became leader.
+	restored bool
+
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newGroup(
+	cfg GroupConfiguration,
+	opts Options,
+	isLeader func() bool,
+) (*group, error) {
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = opts.EvaluationInterval()
+	}
+
+	rules := make([]groupRule, 0, len(cfg.Rules))
+	for i, ruleCfg := range cfg.Rules {
+		if ruleCfg.Record != "" {
+			rule := newRecordingRule(ruleCfg, opts.Storage())
+			rules = append(rules, groupRule{
+				key:  ruleKey(i, rule.Name()),
+				rule: rule,
+			})
+			continue
+		}
+
+		rule, err := newAlertingRule(ruleCfg)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, groupRule{
+			key:      ruleKey(i, rule.Name()),
+			rule:     rule,
+			alerting: rule,
+		})
+	}
+
+	iOpts := opts.InstrumentOptions()
+	scope := iOpts.MetricsScope().Tagged(map[string]string{
+		"rule-group": cfg.Name,
+	})
+	return &group{
+		name:     cfg.Name,
+		interval: interval,
+		rules:    rules,
+		opts:     opts,
+		isLeader: isLeader,
+		logger:   iOpts.ZapLogger().With(zap.String("ruleGroup", cfg.Name)),
+		metrics:  newGroupMetrics(scope),
+		closeCh:  make(chan struct{}),
+	}, nil
+}
+
+func (g *group) start() {
+	g.wg.Add(1)
+	go g.run()
+}
+
+func (g *group) close() {
+	close(g.closeCh)
+	g.wg.Wait()
+}
+
+func (g *group) run() {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.closeCh:
+			return
+		case <-ticker.C:
+			g.evaluate(g.opts.ClockOptions().NowFn()())
+		}
+	}
+}
+
+// evaluate evaluates each rule of the group in order, sends any alerts
+// that are due and persists the resulting alert state. Followers skip
+// evaluation and restore the persisted state when they become leader.
+func (g *group) evaluate(t time.Time) {
+	if !g.isLeader() {
+		g.restored = false
+		return
+	}
+
+	if !g.restored {
+		if err := g.restore(); err != nil {
+			// Do not evaluate until state is restored, otherwise firing
+			// alerts would be reset to pending.
+			g.metrics.stateErrors.Inc(1)
+			g.logger.Error("could not restore alert state", zap.Error(err))
+			return
+		}
+		g.restored = true
+	}
+
+	start := g.opts.ClockOptions().NowFn()()
+	for _, r := range g.rules {
+		ctx, cancel := context.WithTimeout(context.Background(),
+			g.opts.QueryTimeout())
+		err := r.rule.Eval(ctx, t, g.opts.QueryFunc())
+		cancel()
+
+		g.metrics.evaluations.Inc(1)
+		if err != nil {
+			g.metrics.evaluationErrors.Inc(1)
+			g.logger.Error("could not evaluate rule",
+				zap.String("rule", r.rule.Name()), zap.Error(err))
+		}
+	}
+	g.metrics.evaluationLatency.Record(g.opts.ClockOptions().NowFn()().Sub(start))
+
+	g.sendAlerts(t)
+
+	if err := g.opts.StateStore().Store(g.name, g.state()); err != nil {
+		g.metrics.stateErrors.Inc(1)
+		g.logger.Error("could not persist alert state", zap.Error(err))
+	}
+}
+
+func (g *group) sendAlerts(t time.Time) {
+	var alerts []*Alert
+	for _, r := range g.rules {
+		if r.alerting == nil {
+			continue
+		}
+		alerts = append(alerts, r.alerting.alertsToSend(t, g.opts.ResendDelay())...)
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.opts.QueryTimeout())
+	defer cancel()
+
+	if err := g.opts.Notifier().Send(ctx, t, alerts); err != nil {
+		g.metrics.notificationErrors.Inc(1)
+		g.logger.Error("could not send alerts", zap.Error(err))
+		return
+	}
+
+	g.metrics.notifications.Inc(int64(len(alerts)))
+	for _, alert := range alerts {
+		alert.LastSentAt = t
+	}
+}
+
+func (g *group) state() GroupState {
+	state := GroupState{Alerts: make(map[string][]*Alert)}
+	for _, r := range g.rules {
+		if r.alerting == nil {
+			continue
+		}
+		state.Alerts[r.key] = r.alerting.alerts()
+	}
+
+	return state
+}
+
+func (g *group) restore() error {
+	state, err := g.opts.StateStore().Load(g.name)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range g.rules {
+		if r.alerting == nil {
+			continue
+		}
+		r.alerting.restore(state.Alerts[r.key], g.opts.TagOptions())
+	}
+
+	return nil
+}
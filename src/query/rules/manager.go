@@ -0,0 +1,197 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/cluster/services/leader/campaign"
+
+	"go.uber.org/zap"
+)
+
+const campaignRetryDelay = 5 * time.Second
+
+var (
+	errManagerAlreadyStarted = errors.New("rule manager already started")
+	errManagerNotStarted     = errors.New("rule manager not started")
+)
+
+type manager struct {
+	sync.Mutex
+
+	opts    Options
+	groups  []*group
+	logger  *zap.Logger
+	leader  int32
+	started bool
+
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewManager returns a new rule manager for the given rule groups.
+func NewManager(groups []GroupConfiguration, opts Options) (Manager, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := &manager{
+		opts:    opts,
+		logger:  opts.InstrumentOptions().ZapLogger(),
+		closeCh: make(chan struct{}),
+	}
+	for _, cfg := range groups {
+		g, err := newGroup(cfg, opts, m.isLeader)
+		if err != nil {
+			return nil, err
+		}
+		m.groups = append(m.groups, g)
+	}
+
+	return m, nil
+}
+
+func (m *manager) Start() error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.started {
+		return errManagerAlreadyStarted
+	}
+	m.started = true
+
+	if m.opts.LeaderService() == nil {
+		atomic.StoreInt32(&m.leader, 1)
+	} else {
+		m.wg.Add(1)
+		go m.campaign()
+	}
+
+	for _, g := range m.groups {
+		g.start()
+	}
+
+	return nil
+}
+
+func (m *manager) Close() error {
+	m.Lock()
+	defer m.Unlock()
+
+	if !m.started {
+		return errManagerNotStarted
+	}
+	m.started = false
+
+	close(m.closeCh)
+	for _, g := range m.groups {
+		g.close()
+	}
+	m.wg.Wait()
+
+	return nil
+}
+
+func (m *manager) isLeader() bool {
+	return atomic.LoadInt32(&m.leader) == 1
+}
+
+// campaign campaigns for leadership until the manager is closed, starting
+// a new campaign whenever the current one ends.
+func (m *manager) campaign() {
+	defer m.wg.Done()
+
+	var (
+		leaderService = m.opts.LeaderService()
+		electionID    = m.opts.ElectionID()
+	)
+	for {
+		statusCh, err := m.startCampaign(leaderService, electionID)
+		if err != nil {
+			m.logger.Error("could not campaign for rule evaluation leadership",
+				zap.Error(err))
+		} else {
+			m.watchCampaign(leaderService, electionID, statusCh)
+		}
+		atomic.StoreInt32(&m.leader, 0)
+
+		select {
+		case <-m.closeCh:
+			return
+		case <-time.After(campaignRetryDelay):
+		}
+	}
+}
+
+func (m *manager) startCampaign(
+	leaderService services.LeaderService,
+	electionID string,
+) (<-chan campaign.Status, error) {
+	campaignOpts, err := services.NewCampaignOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	return leaderService.Campaign(electionID, campaignOpts)
+}
+
+func (m *manager) watchCampaign(
+	leaderService services.LeaderService,
+	electionID string,
+	statusCh <-chan campaign.Status,
+) {
+	closeCh := m.closeCh
+	for {
+		select {
+		case <-closeCh:
+			// Resign so that another instance takes over promptly, and keep
+			// consuming the status channel until it is closed.
+			closeCh = nil
+			atomic.StoreInt32(&m.leader, 0)
+			if err := leaderService.Resign(electionID); err != nil {
+				// The campaign will not end on its own so stop waiting on it.
+				m.logger.Warn("could not resign rule evaluation leadership",
+					zap.Error(err))
+				return
+			}
+		case status, ok := <-statusCh:
+			if !ok {
+				return
+			}
+
+			switch status.State {
+			case campaign.Leader:
+				atomic.StoreInt32(&m.leader, 1)
+			case campaign.Error:
+				atomic.StoreInt32(&m.leader, 0)
+				m.logger.Error("rule evaluation leadership campaign error",
+					zap.Error(status.Err))
+			default:
+				atomic.StoreInt32(&m.leader, 0)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// alertValidityResendMultiple is the multiple of the resend delay that a
+// firing alert is reported as valid for, so that Alertmanager does not
+// resolve alerts between resends.
+const alertValidityResendMultiple = 4
+
+type webhookAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+type webhookNotifier struct {
+	url          string
+	generatorURL string
+	resendDelay  time.Duration
+	client       *http.Client
+}
+
+// NewWebhookNotifier returns a notifier that posts alerts to an
+// Alertmanager compatible webhook URL.
+func NewWebhookNotifier(
+	url string,
+	generatorURL string,
+	resendDelay time.Duration,
+	client *http.Client,
+) Notifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &webhookNotifier{
+		url:          url,
+		generatorURL: generatorURL,
+		resendDelay:  resendDelay,
+		client:       client,
+	}
+}
+
+func (n *webhookNotifier) Send(
+	ctx context.Context,
+	t time.Time,
+	alerts []*Alert,
+) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	payload := make([]webhookAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		a := webhookAlert{
+			Labels:       alert.Labels,
+			Annotations:  alert.Annotations,
+			StartsAt:     alert.FiredAt,
+			GeneratorURL: n.generatorURL,
+		}
+		if alert.State == AlertStateInactive {
+			a.EndsAt = alert.ResolvedAt
+		} else {
+			a.EndsAt = t.Add(alertValidityResendMultiple * n.resendDelay)
+		}
+		payload = append(payload, a)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so that the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alertmanager returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+type noopNotifier struct{}
+
+func (noopNotifier) Send(context.Context, time.Time, []*Alert) error {
+	return nil
+}
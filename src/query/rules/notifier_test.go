@@ -0,0 +1,80 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifierSend(t *testing.T) {
+	var received []webhookAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	now := time.Unix(1000, 0).UTC()
+	notifier := NewWebhookNotifier(server.URL, "", time.Minute, nil)
+	err := notifier.Send(context.Background(), now, []*Alert{
+		{
+			Labels:  map[string]string{"alertname": "Firing"},
+			State:   AlertStateFiring,
+			FiredAt: now.Add(-time.Minute),
+		},
+		{
+			Labels:     map[string]string{"alertname": "Resolved"},
+			State:      AlertStateInactive,
+			FiredAt:    now.Add(-time.Hour),
+			ResolvedAt: now,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "Firing", received[0].Labels["alertname"])
+	assert.True(t, now.Add(-time.Minute).Equal(received[0].StartsAt))
+	assert.True(t, now.Add(4*time.Minute).Equal(received[0].EndsAt))
+	assert.Equal(t, "Resolved", received[1].Labels["alertname"])
+	assert.True(t, now.Equal(received[1].EndsAt))
+}
+
+func TestWebhookNotifierSendErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, "", time.Minute, nil)
+	err := notifier.Send(context.Background(), time.Now(), []*Alert{
+		{State: AlertStateFiring},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"errors"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/instrument"
+)
+
+var (
+	errNoQueryFunc         = errors.New("no query function set")
+	errNoStorage           = errors.New("no storage set")
+	errNoNotifier          = errors.New("no notifier set")
+	errNoStateStore        = errors.New("no state store set")
+	errNoElectionID        = errors.New("no election ID set")
+	errInvalidInterval     = errors.New("evaluation interval must be positive")
+	errInvalidQueryTimeout = errors.New("query timeout must be positive")
+)
+
+type options struct {
+	instrumentOpts     instrument.Options
+	clockOpts          clock.Options
+	tagOpts            models.TagOptions
+	queryFn            QueryFunc
+	storage            storage.Appender
+	notifier           Notifier
+	stateStore         StateStore
+	evaluationInterval time.Duration
+	queryTimeout       time.Duration
+	resendDelay        time.Duration
+	leaderService      services.LeaderService
+	electionID         string
+}
+
+// NewOptions returns new rule evaluation options.
+func NewOptions() Options {
+	return &options{
+		instrumentOpts:     instrument.NewOptions(),
+		clockOpts:          clock.NewOptions(),
+		tagOpts:            models.NewTagOptions(),
+		notifier:           noopNotifier{},
+		stateStore:         noopStateStore{},
+		evaluationInterval: defaultEvaluationInterval,
+		queryTimeout:       defaultQueryTimeout,
+		resendDelay:        defaultResendDelay,
+		electionID:         defaultElectionID,
+	}
+}
+
+func (o *options) Validate() error {
+	if o.queryFn == nil {
+		return errNoQueryFunc
+	}
+	if o.storage == nil {
+		return errNoStorage
+	}
+	if o.notifier == nil {
+		return errNoNotifier
+	}
+	if o.stateStore == nil {
+		return errNoStateStore
+	}
+	if o.leaderService != nil && o.electionID == "" {
+		return errNoElectionID
+	}
+	if o.evaluationInterval <= 0 {
+		return errInvalidInterval
+	}
+	if o.queryTimeout <= 0 {
+		return errInvalidQueryTimeout
+	}
+	return o.tagOpts.Validate()
+}
+
+func (o *options) SetInstrumentOptions(value instrument.Options) Options {
+	opts := *o
+	opts.instrumentOpts = value
+	return &opts
+}
+
+func (o *options) InstrumentOptions() instrument.Options {
+	return o.instrumentOpts
+}
+
+func (o *options) SetClockOptions(value clock.Options) Options {
+	opts := *o
+	opts.clockOpts = value
+	return &opts
+}
+
+func (o *options) ClockOptions() clock.Options {
+	return o.clockOpts
+}
+
+func (o *options) SetTagOptions(value models.TagOptions) Options {
+	opts := *o
+	opts.tagOpts = value
+	return &opts
+}
+
+func (o *options) TagOptions() models.TagOptions {
+	return o.tagOpts
+}
+
+func (o *options) SetQueryFunc(value QueryFunc) Options {
+	opts := *o
+	opts.queryFn = value
+	return &opts
+}
+
+func (o *options) QueryFunc() QueryFunc {
+	return o.queryFn
+}
+
+func (o *options) SetStorage(value storage.Appender) Options {
+	opts := *o
+	opts.storage = value
+	return &opts
+}
+
+func (o *options) Storage() storage.Appender {
+	return o.storage
+}
+
+func (o *options) SetNotifier(value Notifier) Options {
+	opts := *o
+	opts.notifier = value
+	return &opts
+}
+
+func (o *options) Notifier() Notifier {
+	return o.notifier
+}
+
+func (o *options) SetStateStore(value StateStore) Options {
+	opts := *o
+	opts.stateStore = value
+	return &opts
+}
+
+func (o *options) StateStore() StateStore {
+	return o.stateStore
+}
+
+func (o *options) SetEvaluationInterval(value time.Duration) Options {
+	opts := *o
+	opts.evaluationInterval = value
+	return &opts
+}
+
+func (o *options) EvaluationInterval() time.Duration {
+	return o.evaluationInterval
+}
+
+func (o *options) SetQueryTimeout(value time.Duration) Options {
+	opts := *o
+	opts.queryTimeout = value
+	return &opts
+}
+
+func (o *options) QueryTimeout() time.Duration {
+	return o.queryTimeout
+}
+
+func (o *options) SetResendDelay(value time.Duration) Options {
+	opts := *o
+	opts.resendDelay = value
+	return &opts
+}
+
+func (o *options) ResendDelay() time.Duration {
+	return o.resendDelay
+}
+
+func (o *options) SetLeaderService(value services.LeaderService) Options {
+	opts := *o
+	opts.leaderService = value
+	return &opts
+}
+
+func (o *options) LeaderService() services.LeaderService {
+	return o.leaderService
+}
+
+func (o *options) SetElectionID(value string) Options {
+	opts := *o
+	opts.electionID = value
+	return &opts
+}
+
+func (o *options) ElectionID() string {
+	return o.electionID
+}
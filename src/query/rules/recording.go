@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	xtime "github.com/m3db/m3x/time"
+)
+
+type recordingRule struct {
+	name    string
+	expr    string
+	labels  map[string]string
+	storage storage.Appender
+}
+
+func newRecordingRule(
+	cfg RuleConfiguration,
+	store storage.Appender,
+) *recordingRule {
+	return &recordingRule{
+		name:    cfg.Record,
+		expr:    cfg.Expr,
+		labels:  cfg.Labels,
+		storage: store,
+	}
+}
+
+func (r *recordingRule) Name() string {
+	return r.name
+}
+
+func (r *recordingRule) Eval(
+	ctx context.Context,
+	t time.Time,
+	query QueryFunc,
+) error {
+	series, err := query(ctx, r.expr, t)
+	if err != nil {
+		return err
+	}
+
+	samples := samplesFromSeries(series)
+	seen := make(map[uint64]struct{}, len(samples))
+	for _, s := range samples {
+		tags := withLabels(s.tags.SetName([]byte(r.name)), r.labels)
+		id := tags.HashedID()
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("recording rule %s produced duplicate series: %s",
+				r.name, tags.ID())
+		}
+		seen[id] = struct{}{}
+
+		err := r.storage.Write(ctx, &storage.WriteQuery{
+			Tags: tags,
+			Datapoints: ts.Datapoints{
+				{
+					Timestamp: t,
+					Value:     s.value,
+				},
+			},
+			Unit: xtime.Millisecond,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// samplesFromSeries returns the latest non-NaN value of each series along
+// with a copy of its tags that is safe to modify.
+func samplesFromSeries(series []*ts.Series) []sample {
+	samples := make([]sample, 0, len(series))
+	for _, s := range series {
+		vals := s.Values()
+		for i := vals.Len() - 1; i >= 0; i-- {
+			v := vals.ValueAt(i)
+			if math.IsNaN(v) {
+				continue
+			}
+
+			samples = append(samples, sample{
+				tags:  s.Tags.Clone(),
+				value: v,
+			})
+			break
+		}
+	}
+
+	return samples
+}
+
+func withLabels(tags models.Tags, labels map[string]string) models.Tags {
+	for name, value := range labels {
+		tags = tags.AddOrUpdateTag(models.Tag{
+			Name:  []byte(name),
+			Value: []byte(value),
+		})
+	}
+
+	return tags
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSeries struct {
+	labels map[string]string
+	value  float64
+}
+
+func newTestQueryFunc(results *[]testSeries) QueryFunc {
+	return func(_ context.Context, _ string, t time.Time) ([]*ts.Series, error) {
+		series := make([]*ts.Series, 0, len(*results))
+		for _, r := range *results {
+			tags := labelsToTags(r.labels, models.NewTagOptions())
+			vals := ts.NewFixedStepValues(time.Second, 2, r.value, t.Add(-time.Second))
+			vals.SetValueAt(1, math.NaN())
+			name, _ := tags.Name()
+			series = append(series, ts.NewSeries(name, vals, tags))
+		}
+		return series, nil
+	}
+}
+
+type testAppender struct {
+	writes []*storage.WriteQuery
+}
+
+func (a *testAppender) Write(_ context.Context, query *storage.WriteQuery) error {
+	a.writes = append(a.writes, query)
+	return nil
+}
+
+func TestRecordingRuleWritesSamples(t *testing.T) {
+	results := []testSeries{
+		{labels: map[string]string{"__name__": "up", "job": "a"}, value: 1},
+		{labels: map[string]string{"__name__": "up", "job": "b"}, value: 2},
+	}
+	appender := &testAppender{}
+	rule := newRecordingRule(RuleConfiguration{
+		Record: "job:up:sum",
+		Expr:   "up",
+		Labels: map[string]string{"team": "m3"},
+	}, appender)
+
+	now := time.Unix(1000, 0)
+	require.NoError(t, rule.Eval(context.Background(), now, newTestQueryFunc(&results)))
+	require.Len(t, appender.writes, 2)
+
+	for i, write := range appender.writes {
+		name, ok := write.Tags.Name()
+		require.True(t, ok)
+		assert.Equal(t, "job:up:sum", string(name))
+
+		team, ok := write.Tags.Get([]byte("team"))
+		require.True(t, ok)
+		assert.Equal(t, "m3", string(team))
+
+		require.Len(t, write.Datapoints, 1)
+		assert.Equal(t, now, write.Datapoints[0].Timestamp)
+		assert.Equal(t, results[i].value, write.Datapoints[0].Value)
+	}
+}
+
+func TestAlertingRuleStateTransitions(t *testing.T) {
+	results := []testSeries{
+		{labels: map[string]string{"__name__": "errors", "job": "a"}, value: 5},
+	}
+	query := newTestQueryFunc(&results)
+	rule, err := newAlertingRule(RuleConfiguration{
+		Alert:  "HighErrors",
+		Expr:   "errors > 1",
+		For:    time.Minute,
+		Labels: map[string]string{"severity": "page"},
+		Annotations: map[string]string{
+			"summary": "{{ $labels.job }} has {{ $value }} errors",
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Unix(1000, 0)
+	resendDelay := time.Minute
+
+	// Active but not yet for the for duration.
+	require.NoError(t, rule.Eval(context.Background(), start, query))
+	alerts := rule.alerts()
+	require.Len(t, alerts, 1)
+	alert := alerts[0]
+	assert.Equal(t, AlertStatePending, alert.State)
+	assert.Equal(t, map[string]string{
+		"alertname": "HighErrors",
+		"job":       "a",
+		"severity":  "page",
+	}, alert.Labels)
+	assert.Equal(t, "a has 5 errors", alert.Annotations["summary"])
+	assert.Empty(t, rule.alertsToSend(start, resendDelay))
+
+	// Fires once active for the for duration.
+	firedAt := start.Add(time.Minute)
+	require.NoError(t, rule.Eval(context.Background(), firedAt, query))
+	assert.Equal(t, AlertStateFiring, alert.State)
+	assert.Equal(t, firedAt, alert.FiredAt)
+	assert.Equal(t, []*Alert{alert}, rule.alertsToSend(firedAt, resendDelay))
+
+	// Not resent until the resend delay has passed.
+	alert.LastSentAt = firedAt
+	assert.Empty(t, rule.alertsToSend(firedAt.Add(30*time.Second), resendDelay))
+	assert.Len(t, rule.alertsToSend(firedAt.Add(resendDelay), resendDelay), 1)
+
+	// Resolved once no longer active, and the resolution is sent once.
+	results = nil
+	resolvedAt := firedAt.Add(2 * time.Minute)
+	require.NoError(t, rule.Eval(context.Background(), resolvedAt, query))
+	assert.Equal(t, AlertStateInactive, alert.State)
+	assert.Equal(t, resolvedAt, alert.ResolvedAt)
+	assert.Len(t, rule.alertsToSend(resolvedAt, resendDelay), 1)
+	alert.LastSentAt = resolvedAt
+	assert.Empty(t, rule.alertsToSend(resolvedAt, resendDelay))
+
+	// Resolved alerts are dropped after the retention.
+	require.NoError(t, rule.Eval(context.Background(),
+		resolvedAt.Add(resolvedRetention), query))
+	assert.Empty(t, rule.alerts())
+}
+
+func TestAlertingRulePendingAlertDroppedWhenInactive(t *testing.T) {
+	results := []testSeries{
+		{labels: map[string]string{"__name__": "errors", "job": "a"}, value: 5},
+	}
+	query := newTestQueryFunc(&results)
+	rule, err := newAlertingRule(RuleConfiguration{
+		Alert: "HighErrors",
+		Expr:  "errors > 1",
+		For:   time.Minute,
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+	require.NoError(t, rule.Eval(context.Background(), now, query))
+	require.Len(t, rule.alerts(), 1)
+
+	results = nil
+	require.NoError(t, rule.Eval(context.Background(), now.Add(time.Second), query))
+	assert.Empty(t, rule.alerts())
+}
+
+func TestAlertingRuleRestore(t *testing.T) {
+	results := []testSeries{
+		{labels: map[string]string{"__name__": "errors", "job": "a"}, value: 5},
+	}
+	query := newTestQueryFunc(&results)
+	cfg := RuleConfiguration{
+		Alert: "HighErrors",
+		Expr:  "errors > 1",
+		For:   time.Minute,
+	}
+
+	rule, err := newAlertingRule(cfg)
+	require.NoError(t, err)
+	start := time.Unix(1000, 0)
+	require.NoError(t, rule.Eval(context.Background(), start, query))
+
+	// A new leader restoring the alerts keeps the original active time so
+	// the alert fires once active for the for duration overall.
+	restored, err := newAlertingRule(cfg)
+	require.NoError(t, err)
+	restored.restore(rule.alerts(), models.NewTagOptions())
+	require.NoError(t, restored.Eval(context.Background(), start.Add(time.Minute), query))
+
+	alerts := restored.alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertStateFiring, alerts[0].State)
+	assert.Equal(t, start, alerts[0].ActiveAt)
+}
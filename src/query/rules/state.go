@@ -0,0 +1,88 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"encoding/json"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv"
+)
+
+type kvStateStore struct {
+	store  kv.Store
+	prefix string
+}
+
+// NewKVStateStore returns a state store that persists rule group state in
+// cluster KV under the given key prefix.
+func NewKVStateStore(store kv.Store, prefix string) StateStore {
+	return &kvStateStore{
+		store:  store,
+		prefix: prefix,
+	}
+}
+
+func (s *kvStateStore) key(group string) string {
+	return s.prefix + "/" + group
+}
+
+func (s *kvStateStore) Load(group string) (GroupState, error) {
+	value, err := s.store.Get(s.key(group))
+	if err == kv.ErrNotFound {
+		return GroupState{}, nil
+	}
+	if err != nil {
+		return GroupState{}, err
+	}
+
+	var proto commonpb.StringProto
+	if err := value.Unmarshal(&proto); err != nil {
+		return GroupState{}, err
+	}
+
+	var state GroupState
+	if err := json.Unmarshal([]byte(proto.Value), &state); err != nil {
+		return GroupState{}, err
+	}
+
+	return state, nil
+}
+
+func (s *kvStateStore) Store(group string, state GroupState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.Set(s.key(group), &commonpb.StringProto{Value: string(data)})
+	return err
+}
+
+type noopStateStore struct{}
+
+func (noopStateStore) Load(string) (GroupState, error) {
+	return GroupState{}, nil
+}
+
+func (noopStateStore) Store(string, GroupState) error {
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/kv/mem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVStateStoreRoundTrip(t *testing.T) {
+	store := NewKVStateStore(mem.NewStore(), "prefix")
+
+	state, err := store.Load("group")
+	require.NoError(t, err)
+	assert.Empty(t, state.Alerts)
+
+	activeAt := time.Unix(1000, 0).UTC()
+	expected := GroupState{
+		Alerts: map[string][]*Alert{
+			ruleKey(0, "HighErrors"): {
+				{
+					Labels:   map[string]string{"alertname": "HighErrors"},
+					State:    AlertStatePending,
+					Value:    5,
+					ActiveAt: activeAt,
+				},
+			},
+		},
+	}
+	require.NoError(t, store.Store("group", expected))
+
+	state, err = store.Load("group")
+	require.NoError(t, err)
+	assert.Equal(t, expected, state)
+}
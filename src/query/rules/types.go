@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/services"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	"github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/instrument"
+)
+
+// Manager evaluates rule groups on their intervals.
+type Manager interface {
+	// Start starts evaluating the rule groups.
+	Start() error
+
+	// Close stops evaluating the rule groups.
+	Close() error
+}
+
+// QueryFunc evaluates a PromQL expression as an instant query at the given
+// time.
+type QueryFunc func(ctx context.Context, query string, t time.Time) ([]*ts.Series, error)
+
+// Rule is a recording or alerting rule.
+type Rule interface {
+	// Name returns the name of the rule.
+	Name() string
+
+	// Eval evaluates the rule at the given time.
+	Eval(ctx context.Context, t time.Time, query QueryFunc) error
+}
+
+// AlertState is the state of an alert.
+type AlertState int
+
+const (
+	// AlertStateInactive is the state of a resolved alert.
+	AlertStateInactive AlertState = iota
+	// AlertStatePending is the state of an active alert that has not been
+	// active for the rule's for duration.
+	AlertStatePending
+	// AlertStateFiring is the state of an alert that has been active for the
+	// rule's for duration.
+	AlertStateFiring
+)
+
+func (s AlertState) String() string {
+	switch s {
+	case AlertStateInactive:
+		return "inactive"
+	case AlertStatePending:
+		return "pending"
+	case AlertStateFiring:
+		return "firing"
+	}
+	return "unknown"
+}
+
+// Alert is a single alert instance of an alerting rule.
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       AlertState        `json:"state"`
+	Value       float64           `json:"value"`
+	ActiveAt    time.Time         `json:"activeAt"`
+	FiredAt     time.Time         `json:"firedAt,omitempty"`
+	ResolvedAt  time.Time         `json:"resolvedAt,omitempty"`
+	LastSentAt  time.Time         `json:"lastSentAt,omitempty"`
+}
+
+// Notifier sends alerts to an Alertmanager compatible receiver.
+type Notifier interface {
+	// Send sends the alerts, evaluated at the given time.
+	Send(ctx context.Context, t time.Time, alerts []*Alert) error
+}
+
+// GroupState is the persisted alert state of a rule group, keyed by the
+// alerting rule key.
+type GroupState struct {
+	Alerts map[string][]*Alert `json:"alerts"`
+}
+
+// StateStore persists rule group alert state so that a new leader can
+// resume evaluation without resetting pending and firing alerts.
+type StateStore interface {
+	// Load loads the state of a group, returning an empty state if none
+	// has been stored.
+	Load(group string) (GroupState, error)
+
+	// Store stores the state of a group.
+	Store(group string, state GroupState) error
+}
+
+// sample is the latest value of a series returned by a rule expression.
+type sample struct {
+	tags  models.Tags
+	value float64
+}
+
+// Options are the rule evaluation options.
+type Options interface {
+	// SetInstrumentOptions sets the instrument options.
+	SetInstrumentOptions(value instrument.Options) Options
+	// InstrumentOptions returns the instrument options.
+	InstrumentOptions() instrument.Options
+	// SetClockOptions sets the clock options.
+	SetClockOptions(value clock.Options) Options
+	// ClockOptions returns the clock options.
+	ClockOptions() clock.Options
+	// SetTagOptions sets the tag options.
+	SetTagOptions(value models.TagOptions) Options
+	// TagOptions returns the tag options.
+	TagOptions() models.TagOptions
+	// SetQueryFunc sets the function used to evaluate rule expressions.
+	SetQueryFunc(value QueryFunc) Options
+	// QueryFunc returns the function used to evaluate rule expressions.
+	QueryFunc() QueryFunc
+	// SetStorage sets the storage that recording rule outputs are written to.
+	SetStorage(value storage.Appender) Options
+	// Storage returns the storage that recording rule outputs are written to.
+	Storage() storage.Appender
+	// SetNotifier sets the notifier that alerts are sent with.
+	SetNotifier(value Notifier) Options
+	// Notifier returns the notifier that alerts are sent with.
+	Notifier() Notifier
+	// SetStateStore sets the store that alert state is persisted to.
+	SetStateStore(value StateStore) Options
+	// StateStore returns the store that alert state is persisted to.
+	StateStore() StateStore
+	// SetEvaluationInterval sets the interval for groups without one.
+	SetEvaluationInterval(value time.Duration) Options
+	// EvaluationInterval returns the interval for groups without one.
+	EvaluationInterval() time.Duration
+	// SetQueryTimeout sets the timeout for evaluating a single rule.
+	SetQueryTimeout(value time.Duration) Options
+	// QueryTimeout returns the timeout for evaluating a single rule.
+	QueryTimeout() time.Duration
+	// SetResendDelay sets the minimum delay before resending a firing alert.
+	SetResendDelay(value time.Duration) Options
+	// ResendDelay returns the minimum delay before resending a firing alert.
+	ResendDelay() time.Duration
+	// SetLeaderService sets the leader service used to elect the evaluating
+	// instance, rules are always evaluated if nil.
+	SetLeaderService(value services.LeaderService) Options
+	// LeaderService returns the leader service used to elect the evaluating
+	// instance.
+	LeaderService() services.LeaderService
+	// SetElectionID sets the leader election ID.
+	SetElectionID(value string) Options
+	// ElectionID returns the leader election ID.
+	ElectionID() string
+
+	// Validate validates the options.
+	Validate() error
+}
@@ -37,22 +37,27 @@ import (
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/downsample"
 	"github.com/m3db/m3/src/cmd/services/m3coordinator/ingest"
 	ingestcarbon "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/carbon"
+	ingestopentsdb "github.com/m3db/m3/src/cmd/services/m3coordinator/ingest/opentsdb"
 	dbconfig "github.com/m3db/m3/src/cmd/services/m3dbnode/config"
 	"github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/metrics/aggregation"
 	"github.com/m3db/m3/src/metrics/policy"
+	"github.com/m3db/m3/src/query/api/v1/handler/prometheus/native"
 	"github.com/m3db/m3/src/query/api/v1/httpd"
 	m3dbcluster "github.com/m3db/m3/src/query/cluster/m3db"
 	"github.com/m3db/m3/src/query/executor"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/policy/filter"
 	"github.com/m3db/m3/src/query/pools"
+	"github.com/m3db/m3/src/query/rules"
 	"github.com/m3db/m3/src/query/storage"
 	"github.com/m3db/m3/src/query/storage/fanout"
 	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/storage/remote"
 	"github.com/m3db/m3/src/query/stores/m3db"
+	"github.com/m3db/m3/src/query/tenant"
+	"github.com/m3db/m3/src/query/ts"
 	tsdbRemote "github.com/m3db/m3/src/query/tsdb/remote"
 	"github.com/m3db/m3/src/query/util/logging"
 	"github.com/m3db/m3/src/x/serialize"
@@ -88,6 +93,7 @@ var (
 
 	defaultDownsamplerAndWriterWorkerPoolSize = 1024
 	defaultCarbonIngesterWorkerPoolSize       = 1024
+	defaultOpenTSDBIngesterWorkerPoolSize     = 1024
 )
 
 type cleanupFn func() error
@@ -246,7 +252,18 @@ func Run(runOpts RunOptions) {
 		logger.Fatal("unable to setup perQueryEnforcer", zap.Error(err))
 	}
 
-	engine := executor.NewEngine(backendStorage, scope.SubScope("engine"), *cfg.LookbackDuration, perQueryEnforcer)
+	tenantLimiter, err := tenant.NewLimiter(tenant.Options{
+		Limits:               cfg.Limits.PerTenant.AsLimits(),
+		MaxConcurrentQueries: cfg.Limits.Global.MaxConcurrentQueries,
+		Enforcer:             perQueryEnforcer,
+		InstrumentOptions:    instrumentOptions,
+	})
+	if err != nil {
+		logger.Fatal("unable to setup tenant limiter", zap.Error(err))
+	}
+
+	engine := executor.NewEngine(backendStorage, scope.SubScope("engine"),
+		*cfg.LookbackDuration, perQueryEnforcer, tenantLimiter)
 
 	downsamplerAndWriter, err := newDownsamplerAndWriter(backendStorage, downsampler)
 	if err != nil {
@@ -311,11 +328,31 @@ func Run(runOpts RunOptions) {
 		logger.Info("no m3msg server configured")
 	}
 
+	if cfg.Rules != nil {
+		logger.Info("rule evaluation enabled, starting rule manager")
+		rulesManager, err := newRulesManager(cfg.Rules, engine, backendStorage,
+			tagOptions, clusterClient, instrumentOptions)
+		if err != nil {
+			logger.Fatal("unable to create rule manager", zap.Error(err))
+		}
+
+		if err := rulesManager.Start(); err != nil {
+			logger.Fatal("unable to start rule manager", zap.Error(err))
+		}
+
+		defer rulesManager.Close()
+	}
+
 	if cfg.Carbon != nil && cfg.Carbon.Ingester != nil {
 		startCarbonIngestion(
 			cfg.Carbon, instrumentOptions, logger, m3dbClusters, downsamplerAndWriter)
 	}
 
+	if cfg.OpenTSDB != nil && cfg.OpenTSDB.Ingester != nil {
+		startOpenTSDBIngestion(
+			cfg.OpenTSDB, instrumentOptions, logger, tagOptions, downsamplerAndWriter)
+	}
+
 	var interruptCh <-chan error = make(chan error)
 	if runOpts.InterruptCh != nil {
 		interruptCh = runOpts.InterruptCh
@@ -761,6 +798,41 @@ func startGrpcServer(
 	return server, startErr
 }
 
+func newRulesManager(
+	cfg *rules.Configuration,
+	engine *executor.Engine,
+	backendStorage storage.Storage,
+	tagOptions models.TagOptions,
+	clusterClient clusterclient.Client,
+	iOpts instrument.Options,
+) (rules.Manager, error) {
+	queryTimeout := cfg.QueryTimeoutOrDefault()
+	queryFn := func(
+		ctx context.Context,
+		query string,
+		t time.Time,
+	) ([]*ts.Series, error) {
+		return native.Read(ctx, engine, tagOptions, models.RequestParams{
+			Start:      t,
+			End:        t,
+			Now:        t,
+			Timeout:    queryTimeout,
+			Step:       time.Second,
+			Query:      query,
+			IncludeEnd: true,
+		})
+	}
+
+	opts := rules.NewOptions().
+		SetInstrumentOptions(iOpts.SetMetricsScope(
+			iOpts.MetricsScope().SubScope("rules"))).
+		SetTagOptions(tagOptions).
+		SetQueryFunc(queryFn).
+		SetStorage(backendStorage)
+
+	return cfg.NewManager(opts, clusterClient)
+}
+
 func startCarbonIngestion(
 	cfg *config.CarbonConfiguration,
 	iOpts instrument.Options,
@@ -882,6 +954,90 @@ func startCarbonIngestion(
 			zap.String("listenAddress", carbonListenAddress), zap.Error(err))
 	}
 	logger.Info("started carbon ingestion server", zap.String("listenAddress", carbonListenAddress))
+
+	if udpListenAddress := strings.TrimSpace(ingesterCfg.UDPListenAddress); udpListenAddress != "" {
+		logger.Info("starting carbon udp ingestion server", zap.String("listenAddress", udpListenAddress))
+		udpServer := ingestcarbon.NewUDPServer(udpListenAddress, ingester, carbonIOpts)
+		if err := udpServer.ListenAndServe(); err != nil {
+			logger.Fatal("unable to start carbon udp ingestion server at listen address",
+				zap.String("listenAddress", udpListenAddress), zap.Error(err))
+		}
+		logger.Info("started carbon udp ingestion server", zap.String("listenAddress", udpListenAddress))
+	}
+
+	if pickleListenAddress := strings.TrimSpace(ingesterCfg.PickleListenAddress); pickleListenAddress != "" {
+		logger.Info("starting carbon pickle ingestion server", zap.String("listenAddress", pickleListenAddress))
+		pickleServer := xserver.NewServer(pickleListenAddress, ingester.PickleHandler(), serverOpts)
+		if err := pickleServer.ListenAndServe(); err != nil {
+			logger.Fatal("unable to start carbon pickle ingestion server at listen address",
+				zap.String("listenAddress", pickleListenAddress), zap.Error(err))
+		}
+		logger.Info("started carbon pickle ingestion server", zap.String("listenAddress", pickleListenAddress))
+	}
+}
+
+func startOpenTSDBIngestion(
+	cfg *config.OpenTSDBConfiguration,
+	iOpts instrument.Options,
+	logger *zap.Logger,
+	tagOptions models.TagOptions,
+	downsamplerAndWriter ingest.DownsamplerAndWriter,
+) {
+	ingesterCfg := cfg.Ingester
+	logger.Info("opentsdb ingestion enabled, configuring ingester")
+
+	// Setup worker pool.
+	var (
+		openTSDBIOpts = iOpts.SetMetricsScope(
+			iOpts.MetricsScope().SubScope("ingest-opentsdb"))
+		workerPoolOpts xsync.PooledWorkerPoolOptions
+		workerPoolSize int
+	)
+	if ingesterCfg.MaxConcurrency > 0 {
+		// Use a bounded worker pool if they requested a specific maximum concurrency.
+		workerPoolOpts = xsync.NewPooledWorkerPoolOptions().
+			SetGrowOnDemand(false).
+			SetInstrumentOptions(openTSDBIOpts)
+		workerPoolSize = ingesterCfg.MaxConcurrency
+	} else {
+		workerPoolOpts = xsync.NewPooledWorkerPoolOptions().
+			SetGrowOnDemand(true).
+			SetKillWorkerProbability(0.001)
+		workerPoolSize = defaultOpenTSDBIngesterWorkerPoolSize
+	}
+	workerPool, err := xsync.NewPooledWorkerPool(workerPoolSize, workerPoolOpts)
+	if err != nil {
+		logger.Fatal("unable to create worker pool for opentsdb ingester", zap.Error(err))
+	}
+	workerPool.Init()
+
+	// Create ingester.
+	ingester, err := ingestopentsdb.NewIngester(
+		downsamplerAndWriter, ingestopentsdb.Options{
+			InstrumentOptions: openTSDBIOpts,
+			WorkerPool:        workerPool,
+			TagOptions:        tagOptions,
+		})
+	if err != nil {
+		logger.Fatal("unable to create opentsdb ingester", zap.Error(err))
+	}
+
+	// Start server.
+	var (
+		serverOpts     = xserver.NewOptions().SetInstrumentOptions(openTSDBIOpts)
+		listenAddress  = ingesterCfg.ListenAddressOrDefault()
+		openTSDBServer = xserver.NewServer(listenAddress, ingester, serverOpts)
+	)
+	if strings.TrimSpace(listenAddress) == "" {
+		logger.Fatal("no listen address specified for opentsdb ingester")
+	}
+
+	logger.Info("starting opentsdb ingestion server", zap.String("listenAddress", listenAddress))
+	if err := openTSDBServer.ListenAndServe(); err != nil {
+		logger.Fatal("unable to start opentsdb ingestion server at listen address",
+			zap.String("listenAddress", listenAddress), zap.Error(err))
+	}
+	logger.Info("started opentsdb ingestion server", zap.String("listenAddress", listenAddress))
 }
 
 func newDownsamplerAndWriter(storage storage.Storage, downsampler downsample.Downsampler) (ingest.DownsamplerAndWriter, error) {
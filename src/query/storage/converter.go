@@ -35,6 +35,8 @@ import (
 	xcost "github.com/m3db/m3/src/x/cost"
 	xsync "github.com/m3db/m3x/sync"
 	xtime "github.com/m3db/m3x/time"
+
+	"github.com/prometheus/tsdb/chunkenc"
 )
 
 const (
@@ -266,6 +268,119 @@ func SeriesToPromSamples(series *ts.Series) []*prompb.Sample {
 	return samplesPointers
 }
 
+// SeriesToPromChunkedSeries converts a series to a prometheus chunked series,
+// encoding its datapoints to XOR chunks of at most samplesPerChunk samples.
+func SeriesToPromChunkedSeries(
+	series *ts.Series,
+	samplesPerChunk int,
+) (*prompb.ChunkedSeries, error) {
+	if samplesPerChunk <= 0 {
+		return nil, fmt.Errorf("invalid samples per chunk: %d", samplesPerChunk)
+	}
+
+	datapoints := series.Values().Datapoints()
+	chunks := make([]*prompb.Chunk, 0,
+		(len(datapoints)+samplesPerChunk-1)/samplesPerChunk)
+	for len(datapoints) > 0 {
+		n := samplesPerChunk
+		if n > len(datapoints) {
+			n = len(datapoints)
+		}
+
+		chunk, err := datapointsToPromXORChunk(datapoints[:n])
+		if err != nil {
+			return nil, err
+		}
+
+		chunks = append(chunks, chunk)
+		datapoints = datapoints[n:]
+	}
+
+	return &prompb.ChunkedSeries{
+		Labels: TagsToPromLabels(series.Tags),
+		Chunks: chunks,
+	}, nil
+}
+
+// SeriesIteratorToPromChunks decodes a series iterator, re-encoding its
+// datapoints to XOR chunks of at most samplesPerChunk samples. Each chunk is
+// passed to fn with the labels of the series as soon as it is complete, so
+// that only a single chunk of the series is held in memory at a time.
+func SeriesIteratorToPromChunks(
+	iter encoding.SeriesIterator,
+	samplesPerChunk int,
+	tagOptions models.TagOptions,
+	fn func(labels []*prompb.Label, chunk *prompb.Chunk) error,
+) error {
+	if samplesPerChunk <= 0 {
+		return fmt.Errorf("invalid samples per chunk: %d", samplesPerChunk)
+	}
+
+	metric, err := FromM3IdentToMetric(iter.ID(), iter.Tags(), tagOptions)
+	if err != nil {
+		return err
+	}
+
+	var (
+		labels     = TagsToPromLabels(metric.Tags)
+		datapoints = make(ts.Datapoints, 0, samplesPerChunk)
+	)
+
+	flush := func() error {
+		chunk, err := datapointsToPromXORChunk(datapoints)
+		if err != nil {
+			return err
+		}
+
+		datapoints = datapoints[:0]
+		return fn(labels, chunk)
+	}
+
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		datapoints = append(datapoints,
+			ts.Datapoint{Timestamp: dp.Timestamp, Value: dp.Value})
+		if len(datapoints) < samplesPerChunk {
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if len(datapoints) == 0 {
+		return nil
+	}
+
+	return flush()
+}
+
+// datapointsToPromXORChunk re-encodes non empty, time ordered datapoints to
+// a prometheus XOR chunk.
+func datapointsToPromXORChunk(datapoints ts.Datapoints) (*prompb.Chunk, error) {
+	chunk := chunkenc.NewXORChunk()
+	appender, err := chunk.Appender()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dp := range datapoints {
+		appender.Append(TimeToTimestamp(dp.Timestamp), dp.Value)
+	}
+
+	return &prompb.Chunk{
+		MinTimeMs: TimeToTimestamp(datapoints[0].Timestamp),
+		MaxTimeMs: TimeToTimestamp(datapoints[len(datapoints)-1].Timestamp),
+		Type:      prompb.Chunk_XOR,
+		Data:      chunk.Bytes(),
+	}, nil
+}
+
 func iteratorToTsSeries(
 	iter encoding.SeriesIterator,
 	enforcer cost.ChainedEnforcer,
@@ -38,6 +38,7 @@ import (
 	xsync "github.com/m3db/m3x/sync"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/tsdb/chunkenc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -339,6 +340,104 @@ func TestIteratorToTsSeries(t *testing.T) {
 	})
 }
 
+func TestSeriesToPromChunkedSeries(t *testing.T) {
+	start := time.Unix(1556813520, 0)
+	datapoints := make(ts.Datapoints, 0, 5)
+	for i := 0; i < 5; i++ {
+		datapoints = append(datapoints, ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * 10 * time.Second),
+			Value:     float64(i) + 0.5,
+		})
+	}
+
+	tags := models.NewTags(2, models.NewTagOptions()).
+		AddTag(models.Tag{Name: []byte("__name__"), Value: []byte("cpu")}).
+		AddTag(models.Tag{Name: []byte("host"), Value: []byte("a")})
+	series := ts.NewSeries([]byte("cpu"), datapoints, tags)
+
+	chunked, err := SeriesToPromChunkedSeries(series, 2)
+	require.NoError(t, err)
+	assert.Equal(t, TagsToPromLabels(tags), chunked.Labels)
+	require.Len(t, chunked.Chunks, 3)
+
+	var decoded ts.Datapoints
+	for i, chunk := range chunked.Chunks {
+		assert.Equal(t, prompb.Chunk_XOR, chunk.Type)
+		assert.Equal(t, TimeToTimestamp(datapoints[2*i].Timestamp), chunk.MinTimeMs)
+
+		c, err := chunkenc.FromData(chunkenc.EncXOR, chunk.Data)
+		require.NoError(t, err)
+
+		iter := c.Iterator()
+		for iter.Next() {
+			timestamp, v := iter.At()
+			decoded = append(decoded, ts.Datapoint{
+				Timestamp: TimestampToTime(timestamp),
+				Value:     v,
+			})
+		}
+
+		require.NoError(t, iter.Err())
+		assert.Equal(t, TimeToTimestamp(decoded[len(decoded)-1].Timestamp),
+			chunk.MaxTimeMs)
+	}
+
+	require.Len(t, decoded, len(datapoints))
+	for i, dp := range datapoints {
+		assert.True(t, dp.Timestamp.Equal(decoded[i].Timestamp))
+		assert.Equal(t, dp.Value, decoded[i].Value)
+	}
+
+	_, err = SeriesToPromChunkedSeries(series, 0)
+	assert.Error(t, err)
+}
+
+func TestSeriesIteratorToPromChunks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	iter := seriesiter.NewMockSeriesIterator(ctrl,
+		seriesiter.NewMockValidTagGenerator(ctrl), 5)
+
+	var (
+		labels  [][]*prompb.Label
+		samples []int
+		values  []float64
+	)
+
+	err := SeriesIteratorToPromChunks(iter, 2, models.NewTagOptions(),
+		func(l []*prompb.Label, chunk *prompb.Chunk) error {
+			assert.Equal(t, prompb.Chunk_XOR, chunk.Type)
+			c, err := chunkenc.FromData(chunkenc.EncXOR, chunk.Data)
+			require.NoError(t, err)
+
+			n, it := 0, c.Iterator()
+			for it.Next() {
+				_, v := it.At()
+				values = append(values, v)
+				n++
+			}
+
+			require.NoError(t, it.Err())
+			labels = append(labels, l)
+			samples = append(samples, n)
+			return nil
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{2, 2, 1}, samples)
+	assert.Equal(t, []float64{0, 1, 2, 3, 4}, values)
+	for _, l := range labels {
+		assert.Equal(t, []*prompb.Label{
+			{Name: []byte("foo"), Value: []byte("bar")},
+		}, l)
+	}
+
+	err = SeriesIteratorToPromChunks(iter, 0, models.NewTagOptions(),
+		func([]*prompb.Label, *prompb.Chunk) error { return nil })
+	assert.Error(t, err)
+}
+
 // BenchmarkFetchResultToPromResult-8   	     100	  10563444 ns/op	25368543 B/op	    4443 allocs/op
 func BenchmarkFetchResultToPromResult(b *testing.B) {
 	var (
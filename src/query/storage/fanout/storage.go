@@ -23,14 +23,17 @@ package fanout
 import (
 	"context"
 
+	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/policy/filter"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/util/execution"
 	"github.com/m3db/m3/src/query/util/logging"
+	xerrors "github.com/m3db/m3x/errors"
 
 	"go.uber.org/zap"
 )
@@ -76,6 +79,56 @@ func (s *fanoutStorage) Fetch(
 	return handleFetchResponses(requests)
 }
 
+// FetchCompressed fetches the compressed series of the stores matching the
+// query, it returns errors.ErrNotImplemented if any of them is unable to.
+func (s *fanoutStorage) FetchCompressed(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (encoding.SeriesIterators, m3.Cleanup, error) {
+	stores := filterStores(s.stores, s.fetchFilter, query)
+	fetchers := make([]m3.CompressedFetcher, 0, len(stores))
+	for _, store := range stores {
+		fetcher, ok := store.(m3.CompressedFetcher)
+		if !ok {
+			return nil, noop, errors.ErrNotImplemented
+		}
+
+		fetchers = append(fetchers, fetcher)
+	}
+
+	var (
+		iters    []encoding.SeriesIterator
+		cleanups = make([]m3.Cleanup, 0, len(fetchers))
+	)
+
+	cleanup := func() error {
+		multiErr := xerrors.NewMultiError()
+		for _, c := range cleanups {
+			multiErr = multiErr.Add(c())
+		}
+
+		return multiErr.FinalError()
+	}
+
+	for _, fetcher := range fetchers {
+		result, c, err := fetcher.FetchCompressed(ctx, query, options)
+		if err != nil {
+			cleanup()
+			return nil, noop, err
+		}
+
+		cleanups = append(cleanups, c)
+		iters = append(iters, result.Iters()...)
+	}
+
+	return encoding.NewSeriesIterators(iters, nil), cleanup, nil
+}
+
+func noop() error {
+	return nil
+}
+
 func (s *fanoutStorage) FetchBlocks(
 	ctx context.Context,
 	query *storage.FetchQuery,
@@ -115,6 +115,8 @@ func FetchOptionsToM3Options(fetchOptions *FetchOptions, fetchQuery *FetchQuery)
 		Limit:          fetchOptions.Limit,
 		StartInclusive: fetchQuery.Start,
 		EndExclusive:   fetchQuery.End,
+		OrderedByID:    fetchOptions.OrderedByID,
+		AfterID:        fetchOptions.AfterID,
 	}
 }
 
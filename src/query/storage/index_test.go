@@ -284,3 +284,22 @@ func TestFetchOptionsToAggregateOptions(t *testing.T) {
 	require.Equal(t, 1, len(aggOpts.TermFilter))
 	require.Equal(t, "filter", string(aggOpts.TermFilter[0]))
 }
+
+func TestFetchOptionsToM3Options(t *testing.T) {
+	fetchOptions := &FetchOptions{
+		Limit:       7,
+		OrderedByID: true,
+		AfterID:     []byte("foo"),
+	}
+
+	end := time.Now()
+	start := end.Add(-1 * time.Hour)
+	query := &FetchQuery{Start: start, End: end}
+
+	opts := FetchOptionsToM3Options(fetchOptions, query)
+	assert.Equal(t, 7, opts.Limit)
+	assert.Equal(t, start, opts.StartInclusive)
+	assert.Equal(t, end, opts.EndExclusive)
+	assert.True(t, opts.OrderedByID)
+	assert.Equal(t, "foo", string(opts.AfterID))
+}
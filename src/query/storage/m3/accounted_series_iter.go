@@ -23,6 +23,7 @@ package m3
 import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/models"
 
 	"github.com/uber-go/tally"
 )
@@ -36,15 +37,23 @@ type AccountedSeriesIter struct {
 	fetchedDps  tally.Counter
 	enforcer    cost.ChainedEnforcer
 	enforcerErr error
+	stats       *models.QueryStats
 }
 
-// NewAccountedSeriesIter constructs an AccountedSeriesIter which uses wrapped as its source.
-func NewAccountedSeriesIter(wrapped encoding.SeriesIterator, enforcer cost.ChainedEnforcer, scope tally.Scope) *AccountedSeriesIter {
+// NewAccountedSeriesIter constructs an AccountedSeriesIter which uses wrapped as its source. Decoded
+// datapoints are added to stats if it is not nil.
+func NewAccountedSeriesIter(
+	wrapped encoding.SeriesIterator,
+	enforcer cost.ChainedEnforcer,
+	scope tally.Scope,
+	stats *models.QueryStats,
+) *AccountedSeriesIter {
 	return &AccountedSeriesIter{
 		SeriesIterator: wrapped,
 		enforcer:       enforcer,
 		scope:          scope,
 		fetchedDps:     scope.Tagged(map[string]string{"type": "fetched"}).Counter("datapoints"),
+		stats:          stats,
 	}
 }
 
@@ -71,6 +80,7 @@ func (as *AccountedSeriesIter) Next() bool {
 	}
 
 	as.fetchedDps.Inc(1)
+	as.stats.AddDatapointsDecoded(1)
 	// we actually advanced the iterator; inform the enforcer
 	r := as.enforcer.Add(1.0)
 
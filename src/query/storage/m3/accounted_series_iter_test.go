@@ -27,6 +27,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/ts"
 	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/test/seriesiter"
 	"github.com/m3db/m3/src/x/cost"
 	"github.com/m3db/m3/src/x/cost/test"
@@ -66,7 +67,7 @@ func setupAccountedSeriesIter(t *testing.T, numValues int, limit cost.Cost) *acc
 	return &accountedSeriesIterSetup{
 		Ctrl:     ctrl,
 		Enforcer: enforcer,
-		Iter:     NewAccountedSeriesIter(mockWrappedIter, enforcer, tally.NoopScope),
+		Iter:     NewAccountedSeriesIter(mockWrappedIter, enforcer, tally.NoopScope, nil),
 	}
 }
 
@@ -105,10 +106,21 @@ func TestAccountedSeriesIter_Next(t *testing.T) {
 		test.AssertLimitErrorWithMsg(t, iter.Err(), "exceeded block limit", 2, 2)
 	})
 
+	t.Run("adds to stats", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockIter := seriesiter.NewMockSeriesIterator(ctrl, seriesiter.NewMockValidTagGenerator(ctrl), 3)
+		stats := models.NewQueryStats()
+		iter := NewAccountedSeriesIter(mockIter, newTestEnforcer(5), tally.NoopScope, stats)
+		for iter.Next() {
+		}
+
+		assert.Equal(t, int64(3), stats.Summary().DatapointsDecoded)
+	})
+
 	t.Run("delegates on wrapped error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockIter := mockSeriesIterWithErr(ctrl)
-		iter := NewAccountedSeriesIter(mockIter, newTestEnforcer(5), tally.NoopScope)
+		iter := NewAccountedSeriesIter(mockIter, newTestEnforcer(5), tally.NoopScope, nil)
 
 		assert.True(t, iter.Next(), "the wrapped iterator returns true, so the AcccountedSeriesIterator should return true")
 	})
@@ -123,7 +135,7 @@ func mockSeriesIterWithErr(ctrl *gomock.Controller) *encoding.MockSeriesIterator
 func TestAccountedSeriesIter_Err(t *testing.T) {
 	t.Run("returns wrapped error over enforcer error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
-		iter := NewAccountedSeriesIter(mockSeriesIterWithErr(ctrl), newTestEnforcer(1), tally.NoopScope)
+		iter := NewAccountedSeriesIter(mockSeriesIterWithErr(ctrl), newTestEnforcer(1), tally.NoopScope, nil)
 		iter.Next()
 		assert.EqualError(t, iter.Err(), "test error")
 	})
@@ -21,6 +21,7 @@
 package m3
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
@@ -199,6 +200,43 @@ func resolveClusterNamespacesForQuery(
 	return namespaceCoversPartialQueryRange, result, nil
 }
 
+// resolveClusterNamespacesForQueryWithRestrictFetchOptions returns the single
+// namespace that the restrict fetch options limit the query to.
+func resolveClusterNamespacesForQueryWithRestrictFetchOptions(
+	now time.Time,
+	start time.Time,
+	clusters Clusters,
+	restrict storage.RestrictFetchOptions,
+) (queryFanoutType, ClusterNamespaces, error) {
+	coversRange := func(namespace ClusterNamespace) queryFanoutType {
+		clusterStart := now.Add(-1 * namespace.Options().Attributes().Retention)
+		if clusterStart.After(start) {
+			return namespaceCoversPartialQueryRange
+		}
+		return namespaceCoversAllQueryRange
+	}
+
+	switch restrict.MetricsType {
+	case storage.UnaggregatedMetricsType:
+		namespace := clusters.UnaggregatedClusterNamespace()
+		return coversRange(namespace), ClusterNamespaces{namespace}, nil
+	case storage.AggregatedMetricsType:
+		namespace, ok := clusters.AggregatedClusterNamespace(RetentionResolution{
+			Retention:  restrict.Retention,
+			Resolution: restrict.Resolution,
+		})
+		if !ok {
+			return namespaceInvalid, nil, fmt.Errorf(
+				"could not find namespace for storage policy: %v:%v",
+				restrict.Resolution, restrict.Retention)
+		}
+		return coversRange(namespace), ClusterNamespaces{namespace}, nil
+	default:
+		return namespaceInvalid, nil, fmt.Errorf(
+			"unrecognized metrics type: %v", restrict.MetricsType)
+	}
+}
+
 type reusedAggregatedNamespaceSlices struct {
 	completeAggregated []ClusterNamespace
 	partialAggregated  []ClusterNamespace
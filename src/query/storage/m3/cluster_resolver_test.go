@@ -115,6 +115,55 @@ func TestGraphitePath(t *testing.T) {
 	}
 }
 
+func TestResolveClusterNamespacesWithRestrictFetchOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s, _ := setup(t, ctrl)
+	store, ok := s.(*m3storage)
+	assert.True(t, ok)
+
+	now := time.Now()
+	start := now.Add(time.Hour * 24 * -60)
+
+	fanout, clusters, err := resolveClusterNamespacesForQueryWithRestrictFetchOptions(
+		now, start, store.clusters, storage.RestrictFetchOptions{
+			MetricsType: storage.AggregatedMetricsType,
+			Retention:   time.Hour * 24 * 90,
+			Resolution:  time.Minute * 5,
+		})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(clusters))
+	assert.Equal(t, "metrics_aggregated_5m:90d", clusters[0].NamespaceID().String())
+	assert.Equal(t, namespaceCoversAllQueryRange, fanout)
+
+	fanout, clusters, err = resolveClusterNamespacesForQueryWithRestrictFetchOptions(
+		now, start, store.clusters, storage.RestrictFetchOptions{
+			MetricsType: storage.AggregatedMetricsType,
+			Retention:   time.Hour * 24 * 30,
+			Resolution:  time.Minute,
+		})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(clusters))
+	assert.Equal(t, "metrics_aggregated_1m:30d", clusters[0].NamespaceID().String())
+	assert.Equal(t, namespaceCoversPartialQueryRange, fanout)
+
+	_, clusters, err = resolveClusterNamespacesForQueryWithRestrictFetchOptions(
+		now, start, store.clusters, storage.RestrictFetchOptions{
+			MetricsType: storage.UnaggregatedMetricsType,
+		})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(clusters))
+	assert.Equal(t, "metrics_unaggregated", clusters[0].NamespaceID().String())
+
+	_, _, err = resolveClusterNamespacesForQueryWithRestrictFetchOptions(
+		now, start, store.clusters, storage.RestrictFetchOptions{
+			MetricsType: storage.AggregatedMetricsType,
+			Retention:   time.Hour,
+			Resolution:  time.Second,
+		})
+	require.Error(t, err)
+}
+
 func generateClusters(t *testing.T, ctrl *gomock.Controller) Clusters {
 	session := client.NewMockSession(ctrl)
 	retentionFiltered, retentionUnfiltered := time.Hour, time.Hour*10
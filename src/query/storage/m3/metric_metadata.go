@@ -0,0 +1,296 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package m3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/m3ninx/idx"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultMetadataRewriteInterval is how long written metadata is not
+	// written again for, it is then rewritten so that it stays within the
+	// retention of the namespace for as long as Prometheus keeps sending it.
+	defaultMetadataRewriteInterval = time.Hour
+	// defaultMetadataQueueSize is the number of metadata waiting to be written
+	// above which further metadata is dropped until the next remote write.
+	defaultMetadataQueueSize = 1024
+)
+
+var (
+	metadataTypeTag = []byte("type")
+	metadataHelpTag = []byte("help")
+	metadataUnitTag = []byte("unit")
+
+	errNoMetricFamilyName = errors.New("metric metadata has no metric family name")
+	errMetadataQueueFull  = errors.New("metric metadata write queue is full")
+)
+
+type metricMetadataStore struct {
+	sync.Mutex
+
+	namespaceID     ident.ID
+	session         client.Session
+	tagOptions      models.TagOptions
+	logger          *zap.Logger
+	metrics         metricMetadataStoreMetrics
+	nowFn           func() time.Time
+	rewriteInterval time.Duration
+
+	// written is the time each metadata, by ID, was last queued at.
+	written map[string]time.Time
+	queue   chan metadataWrite
+}
+
+type metadataWrite struct {
+	id   []byte
+	tags models.Tags
+}
+
+type metricMetadataStoreMetrics struct {
+	writeSuccess tally.Counter
+	writeErrors  tally.Counter
+	writeDropped tally.Counter
+}
+
+func newMetricMetadataStoreMetrics(scope tally.Scope) metricMetadataStoreMetrics {
+	return metricMetadataStoreMetrics{
+		writeSuccess: scope.Counter("write.success"),
+		writeErrors:  scope.Counter("write.errors"),
+		writeDropped: scope.Counter("write.dropped"),
+	}
+}
+
+// NewMetricMetadataStore returns a metric metadata store which writes each
+// distinct metadata of a metric family as a series of a dedicated namespace,
+// tagged with the metric family name, type, help and unit. The namespace is
+// not part of the cluster namespaces so it is never queried for series.
+//
+// Metadata is written in the background: since Prometheus sends the same
+// metadata with every remote write, metadata already written within the
+// last hour is skipped and the rest is queued for a single writer.
+func NewMetricMetadataStore(
+	namespaceID ident.ID,
+	session client.Session,
+	tagOptions models.TagOptions,
+	instrumentOpts instrument.Options,
+) storage.MetricMetadataStore {
+	s := &metricMetadataStore{
+		namespaceID: namespaceID,
+		session:     session,
+		tagOptions:  tagOptions,
+		logger:      instrumentOpts.ZapLogger(),
+		metrics: newMetricMetadataStoreMetrics(
+			instrumentOpts.MetricsScope().SubScope("metric-metadata")),
+		nowFn:           time.Now,
+		rewriteInterval: defaultMetadataRewriteInterval,
+		written:         make(map[string]time.Time),
+		queue:           make(chan metadataWrite, defaultMetadataQueueSize),
+	}
+
+	go s.writeLoop()
+	return s
+}
+
+func (s *metricMetadataStore) WriteMetadata(
+	ctx context.Context,
+	metadata []*prompb.MetricMetadata,
+) error {
+	// Check if the query was interrupted.
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	for _, m := range metadata {
+		if m.MetricFamilyName == "" {
+			return errNoMetricFamilyName
+		}
+	}
+
+	var (
+		now     = s.nowFn()
+		dropped int
+	)
+
+	s.Lock()
+	defer s.Unlock()
+	for _, m := range metadata {
+		tags := s.metadataToTags(m)
+		id := tags.ID()
+		if last, ok := s.written[string(id)]; ok && now.Sub(last) < s.rewriteInterval {
+			continue
+		}
+
+		select {
+		case s.queue <- metadataWrite{id: id, tags: tags}:
+			s.written[string(id)] = now
+		default:
+			// Never block the remote write on the metadata, it is sent again
+			// with the next one.
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		s.metrics.writeDropped.Inc(int64(dropped))
+		return errMetadataQueueFull
+	}
+
+	return nil
+}
+
+func (s *metricMetadataStore) writeLoop() {
+	for w := range s.queue {
+		id := ident.BytesID(w.id)
+		// Set id to NoFinalize to avoid cloning it in write operations
+		id.NoFinalize()
+		err := s.session.WriteTagged(s.namespaceID, id,
+			storage.TagsToIdentTagIterator(w.tags), s.nowFn(), 1, xtime.Second, nil)
+		if err == nil {
+			s.metrics.writeSuccess.Inc(1)
+			continue
+		}
+
+		s.metrics.writeErrors.Inc(1)
+		s.logger.Error("could not write metric metadata",
+			zap.String("id", string(w.id)), zap.Error(err))
+
+		// Forget the metadata so that it is queued again by the next write.
+		s.Lock()
+		delete(s.written, string(w.id))
+		s.Unlock()
+	}
+}
+
+func (s *metricMetadataStore) FetchMetadata(
+	ctx context.Context,
+	metric string,
+) ([]*prompb.MetricMetadata, error) {
+	// Check if the query was interrupted.
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	query := idx.NewFieldQuery(s.tagOptions.MetricName())
+	if metric != "" {
+		query = idx.NewTermQuery(s.tagOptions.MetricName(), []byte(metric))
+	}
+
+	// Metadata is kept for the retention of the namespace, so search all of it.
+	iter, _, err := s.session.FetchTaggedIDs(s.namespaceID,
+		index.Query{Query: query}, index.QueryOptions{
+			EndExclusive: s.nowFn(),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	defer iter.Finalize()
+
+	var metadata []*prompb.MetricMetadata
+	for iter.Next() {
+		_, _, tags := iter.Current()
+		m, err := s.tagsToMetadata(tags)
+		if err != nil {
+			return nil, err
+		}
+
+		metadata = append(metadata, m)
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+func (s *metricMetadataStore) metadataToTags(m *prompb.MetricMetadata) models.Tags {
+	tags := models.NewTags(4, s.tagOptions).
+		AddTagWithoutNormalizing(models.Tag{
+			Name:  s.tagOptions.MetricName(),
+			Value: []byte(m.MetricFamilyName),
+		}).
+		AddTagWithoutNormalizing(models.Tag{
+			Name:  metadataTypeTag,
+			Value: []byte(m.Type.String()),
+		})
+
+	// Empty help and unit are left out rather than written as empty values.
+	if m.Help != "" {
+		tags = tags.AddTagWithoutNormalizing(models.Tag{
+			Name:  metadataHelpTag,
+			Value: []byte(m.Help),
+		})
+	}
+
+	if m.Unit != "" {
+		tags = tags.AddTagWithoutNormalizing(models.Tag{
+			Name:  metadataUnitTag,
+			Value: []byte(m.Unit),
+		})
+	}
+
+	return tags.Normalize()
+}
+
+func (s *metricMetadataStore) tagsToMetadata(
+	tags ident.TagIterator,
+) (*prompb.MetricMetadata, error) {
+	m := &prompb.MetricMetadata{}
+	for tags.Next() {
+		tag := tags.Current()
+		name, value := tag.Name.Bytes(), tag.Value.String()
+		switch {
+		case bytes.Equal(name, s.tagOptions.MetricName()):
+			m.MetricFamilyName = value
+		case bytes.Equal(name, metadataTypeTag):
+			// Unrecognized types map to the zero value, which is UNKNOWN.
+			m.Type = prompb.MetricMetadata_MetricType(
+				prompb.MetricMetadata_MetricType_value[value])
+		case bytes.Equal(name, metadataHelpTag):
+			m.Help = value
+		case bytes.Equal(name, metadataUnitTag):
+			m.Unit = value
+		}
+	}
+
+	return m, tags.Err()
+}
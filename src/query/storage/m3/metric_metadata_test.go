@@ -0,0 +1,180 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package m3
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
+	"github.com/m3db/m3/src/query/models"
+	xclock "github.com/m3db/m3x/clock"
+	"github.com/m3db/m3x/ident"
+	"github.com/m3db/m3x/instrument"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricMetadataStoreWriteAndFetch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now       = time.Unix(1556813520, 0)
+		session   = client.NewMockSession(ctrl)
+		namespace = ident.StringID("prometheus_metadata")
+		written   []ident.Tags
+		wg        sync.WaitGroup
+	)
+
+	store := NewMetricMetadataStore(namespace, session, models.NewTagOptions(),
+		instrument.NewOptions())
+	store.(*metricMetadataStore).nowFn = func() time.Time { return now }
+
+	session.EXPECT().
+		WriteTagged(namespace, gomock.Any(), gomock.Any(), now, 1.0, xtime.Second, gomock.Any()).
+		Do(func(_, _ ident.ID, iter ident.TagIterator, _ time.Time, _ float64,
+			_ xtime.Unit, _ []byte) {
+			var tags ident.Tags
+			for iter.Next() {
+				tag := iter.Current()
+				tags.Append(ident.StringTag(tag.Name.String(), tag.Value.String()))
+			}
+
+			require.NoError(t, iter.Err())
+			written = append(written, tags)
+			wg.Done()
+		}).
+		Return(nil).
+		Times(2)
+
+	metadata := []*prompb.MetricMetadata{
+		{
+			Type:             prompb.MetricMetadata_COUNTER,
+			MetricFamilyName: "http_requests_total",
+			Help:             "Total number of HTTP requests.",
+		},
+		{
+			Type:             prompb.MetricMetadata_GAUGE,
+			MetricFamilyName: "process_resident_memory",
+			Help:             "Resident memory size.",
+			Unit:             "bytes",
+		},
+	}
+
+	wg.Add(2)
+	require.NoError(t, store.WriteMetadata(context.TODO(), metadata))
+	wg.Wait()
+	require.Len(t, written, 2)
+	assert.Equal(t, 3, len(written[0].Values()))
+	assert.Equal(t, 4, len(written[1].Values()))
+
+	iter := client.NewMockTaggedIDsIterator(ctrl)
+	gomock.InOrder(
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(namespace, ident.StringID("a"),
+			ident.NewTagsIterator(written[0])),
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(namespace, ident.StringID("b"),
+			ident.NewTagsIterator(written[1])),
+		iter.EXPECT().Next().Return(false),
+		iter.EXPECT().Err().Return(nil),
+		iter.EXPECT().Finalize(),
+	)
+
+	session.EXPECT().FetchTaggedIDs(namespace, gomock.Any(), gomock.Any()).
+		Return(iter, true, nil)
+
+	fetched, err := store.FetchMetadata(context.TODO(), "")
+	require.NoError(t, err)
+	assert.Equal(t, metadata, fetched)
+}
+
+func TestMetricMetadataStoreWriteNoMetricFamilyName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := NewMetricMetadataStore(ident.StringID("prometheus_metadata"),
+		client.NewMockSession(ctrl), models.NewTagOptions(), instrument.NewOptions())
+	err := store.WriteMetadata(context.TODO(), []*prompb.MetricMetadata{
+		{Type: prompb.MetricMetadata_COUNTER},
+	})
+	assert.Equal(t, errNoMetricFamilyName, err)
+}
+
+func TestMetricMetadataStoreWriteDeduplicated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		now       = time.Unix(1556813520, 0)
+		session   = client.NewMockSession(ctrl)
+		namespace = ident.StringID("prometheus_metadata")
+		writes    = make(chan error)
+	)
+
+	store := NewMetricMetadataStore(namespace, session, models.NewTagOptions(),
+		instrument.NewOptions())
+	store.(*metricMetadataStore).nowFn = func() time.Time { return now }
+
+	session.EXPECT().
+		WriteTagged(namespace, gomock.Any(), gomock.Any(), gomock.Any(), 1.0,
+			xtime.Second, gomock.Any()).
+		DoAndReturn(func(_, _ ident.ID, _ ident.TagIterator, _ time.Time,
+			_ float64, _ xtime.Unit, _ []byte) error {
+			return <-writes
+		}).
+		Times(3)
+
+	metadata := []*prompb.MetricMetadata{
+		{
+			Type:             prompb.MetricMetadata_COUNTER,
+			MetricFamilyName: "http_requests_total",
+		},
+	}
+
+	// Metadata already queued is not queued again.
+	require.NoError(t, store.WriteMetadata(context.TODO(), metadata))
+	require.NoError(t, store.WriteMetadata(context.TODO(), metadata))
+
+	// A failed write is forgotten so that the next write queues it again.
+	writes <- errors.New("boom")
+	require.True(t, xclock.WaitUntil(func() bool {
+		s := store.(*metricMetadataStore)
+		s.Lock()
+		defer s.Unlock()
+		return len(s.written) == 0
+	}, 5*time.Second))
+
+	require.NoError(t, store.WriteMetadata(context.TODO(), metadata))
+	writes <- nil
+
+	// Metadata is written again once the rewrite interval has passed.
+	now = now.Add(defaultMetadataRewriteInterval)
+	require.NoError(t, store.WriteMetadata(context.TODO(), metadata))
+	writes <- nil
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/m3db/m3/src/query/ts"
 	"github.com/m3db/m3/src/query/ts/m3db"
 	"github.com/m3db/m3/src/query/ts/m3db/consolidators"
+	xcost "github.com/m3db/m3/src/x/cost"
 	"github.com/m3db/m3x/ident"
 	xsync "github.com/m3db/m3x/sync"
 )
@@ -148,6 +149,15 @@ func (s *m3storage) FetchBlocks(
 			return block.Result{}, err
 		}
 
+		options.Stats.AddSeriesFetched(len(fetchResult.SeriesList))
+		for _, series := range fetchResult.SeriesList {
+			options.Stats.AddDatapointsDecoded(series.Len())
+		}
+
+		if err := enforceSeriesLimit(options, len(fetchResult.SeriesList)); err != nil {
+			return block.Result{}, err
+		}
+
 		return storage.FetchResultToBlockResult(fetchResult, query, s.opts.LookbackDuration(), options.Enforcer)
 	}
 
@@ -179,7 +189,12 @@ func (s *m3storage) FetchBlocks(
 	// and then return the original to the pool, which feels wasteful.
 	iters := raw.Iters()
 	for i, iter := range iters {
-		iters[i] = NewAccountedSeriesIter(iter, enforcer, options.Scope)
+		iters[i] = NewAccountedSeriesIter(iter, enforcer, options.Scope, options.Stats)
+	}
+
+	options.Stats.AddSeriesFetched(len(iters))
+	if err := enforceSeriesLimit(options, len(iters)); err != nil {
+		return block.Result{}, err
 	}
 
 	blocks, err := m3db.ConvertM3DBSeriesIterators(
@@ -197,6 +212,16 @@ func (s *m3storage) FetchBlocks(
 	}, nil
 }
 
+// enforceSeriesLimit adds the fetched series to the series enforcer of the
+// query, returning an error if they exceed its limits.
+func enforceSeriesLimit(options *storage.FetchOptions, fetched int) error {
+	if options.SeriesEnforcer == nil {
+		return nil
+	}
+
+	return options.SeriesEnforcer.Add(xcost.Cost(fetched)).Error
+}
+
 func (s *m3storage) FetchCompressed(
 	ctx context.Context,
 	query *storage.FetchQuery,
@@ -238,12 +263,10 @@ func (s *m3storage) fetchCompressed(
 	// cluster that can completely fulfill this range and then prefer the
 	// highest resolution (most fine grained) results.
 	// This needs to be optimized, however this is a start.
-	fanout, namespaces, err := resolveClusterNamespacesForQuery(
-		s.nowFn(),
-		s.clusters,
+	fanout, namespaces, err := s.resolveNamespacesForQuery(
 		query.Start,
 		query.End,
-		options.FanoutOptions,
+		options,
 	)
 
 	if err != nil {
@@ -254,6 +277,9 @@ func (s *m3storage) fetchCompressed(
 		opts = storage.FetchOptionsToM3Options(options, query)
 		wg   sync.WaitGroup
 	)
+
+	// Stop fetching from the database once the query is cancelled.
+	opts.Done = ctx.Done()
 	if len(namespaces) == 0 {
 		return nil, errNoNamespacesConfigured
 	}
@@ -291,6 +317,20 @@ func (s *m3storage) fetchCompressed(
 	return result, err
 }
 
+func (s *m3storage) resolveNamespacesForQuery(
+	start time.Time,
+	end time.Time,
+	options *storage.FetchOptions,
+) (queryFanoutType, ClusterNamespaces, error) {
+	if options.RestrictFetchOptions != nil {
+		return resolveClusterNamespacesForQueryWithRestrictFetchOptions(
+			s.nowFn(), start, s.clusters, *options.RestrictFetchOptions)
+	}
+
+	return resolveClusterNamespacesForQuery(s.nowFn(), s.clusters,
+		start, end, options.FanoutOptions)
+}
+
 func (s *m3storage) SearchSeries(
 	ctx context.Context,
 	query *storage.FetchQuery,
@@ -347,6 +387,14 @@ func (s *m3storage) CompleteTags(
 		wg              sync.WaitGroup
 	)
 
+	if options.RestrictFetchOptions != nil {
+		_, namespaces, err = resolveClusterNamespacesForQueryWithRestrictFetchOptions(
+			s.nowFn(), query.Start, s.clusters, *options.RestrictFetchOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if len(namespaces) == 0 {
 		return nil, errNoNamespacesConfigured
 	}
@@ -447,6 +495,16 @@ func (s *m3storage) SearchCompressed(
 		wg         sync.WaitGroup
 	)
 
+	if options.RestrictFetchOptions != nil {
+		_, namespaces, err = resolveClusterNamespacesForQueryWithRestrictFetchOptions(
+			s.nowFn(), query.Start, s.clusters, *options.RestrictFetchOptions)
+		if err != nil {
+			return nil, noop, err
+		}
+	}
+
+	m3opts.Done = ctx.Done()
+
 	if len(namespaces) == 0 {
 		return nil, noop, errNoNamespacesConfigured
 	}
@@ -44,12 +44,7 @@ type Storage interface {
 
 // Querier handles queries against an M3 instance.
 type Querier interface {
-	// FetchCompressed fetches timeseries data based on a query
-	FetchCompressed(
-		ctx context.Context,
-		query *genericstorage.FetchQuery,
-		options *genericstorage.FetchOptions,
-	) (encoding.SeriesIterators, Cleanup, error)
+	CompressedFetcher
 	// SearchCompressed fetches matching tags based on a query
 	SearchCompressed(
 		ctx context.Context,
@@ -58,6 +53,17 @@ type Querier interface {
 	) ([]MultiTagResult, Cleanup, error)
 }
 
+// CompressedFetcher fetches timeseries data without decompressing it, so
+// that it can be consumed one series at a time.
+type CompressedFetcher interface {
+	// FetchCompressed fetches timeseries data based on a query
+	FetchCompressed(
+		ctx context.Context,
+		query *genericstorage.FetchQuery,
+		options *genericstorage.FetchOptions,
+	) (encoding.SeriesIterators, Cleanup, error)
+}
+
 // MultiFetchResult is a deduping accumalator for series iterators
 // that allows merging using a given strategy.
 type MultiFetchResult interface {
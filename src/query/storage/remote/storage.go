@@ -23,9 +23,11 @@ package remote
 import (
 	"context"
 
+	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/errors"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/tsdb/remote"
 )
 
@@ -46,6 +48,14 @@ func (s *remoteStorage) Fetch(
 	return s.client.Fetch(ctx, query, options)
 }
 
+func (s *remoteStorage) FetchCompressed(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (encoding.SeriesIterators, m3.Cleanup, error) {
+	return s.client.FetchCompressed(ctx, query, options)
+}
+
 func (s *remoteStorage) FetchBlocks(
 	ctx context.Context,
 	query *storage.FetchQuery,
@@ -27,6 +27,7 @@ import (
 
 	"github.com/m3db/m3/src/query/block"
 	"github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/generated/proto/prompb"
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/ts"
 	xtime "github.com/m3db/m3x/time"
@@ -89,11 +90,25 @@ type FetchOptions struct {
 	BlockType models.FetchedBlockType
 	// FanoutOptions are the options for the fetch namespace fanout.
 	FanoutOptions *FanoutOptions
+	// RestrictFetchOptions restricts the fetch to a single namespace, taking
+	// precedence over the fanout options if set.
+	RestrictFetchOptions *RestrictFetchOptions
 	// Enforcer is used to enforce resource limits on the number of datapoints
 	// used by a given query. Limits are imposed at time of decompression.
 	Enforcer cost.ChainedEnforcer
+	// SeriesEnforcer is used to enforce resource limits on the number of
+	// series fetched by a given query.
+	SeriesEnforcer cost.ChainedEnforcer
 	// Scope is used to report metrics about the fetch.
 	Scope tally.Scope
+	// Stats collects statistics about the fetch for the query if set.
+	Stats *models.QueryStats
+	// OrderedByID fetches the first series in ID order up to the limit rather
+	// than the first series matched, so that a fetch can page through its
+	// series with AfterID.
+	OrderedByID bool
+	// AfterID only fetches the series with an ID ordered after it, optional.
+	AfterID []byte
 }
 
 // FanoutOptions describes which namespaces should be fanned out to for
@@ -122,6 +137,17 @@ const (
 	FanoutForceEnable
 )
 
+// RestrictFetchOptions restricts a fetch to the namespace with the given
+// attributes.
+type RestrictFetchOptions struct {
+	// MetricsType is the metrics type of the namespace to fetch from.
+	MetricsType MetricsType
+	// Retention and Resolution identify the aggregated namespace to fetch
+	// from, they are ignored when restricting to the unaggregated namespace.
+	Retention  time.Duration
+	Resolution time.Duration
+}
+
 // NewFetchOptions creates a new fetch options.
 func NewFetchOptions() *FetchOptions {
 	return &FetchOptions{
@@ -132,8 +158,9 @@ func NewFetchOptions() *FetchOptions {
 			FanoutAggregated:          FanoutDefault,
 			FanoutAggregatedOptimized: FanoutDefault,
 		},
-		Enforcer: cost.NoopChainedEnforcer(),
-		Scope:    tally.NoopScope,
+		Enforcer:       cost.NoopChainedEnforcer(),
+		SeriesEnforcer: cost.NoopChainedEnforcer(),
+		Scope:          tally.NoopScope,
 	}
 }
 
@@ -232,6 +259,17 @@ type Appender interface {
 	Write(ctx context.Context, query *WriteQuery) error
 }
 
+// MetricMetadataStore stores the type, help and unit metadata of metric
+// families sent by Prometheus.
+type MetricMetadataStore interface {
+	// WriteMetadata writes the metadata of metric families, possibly after
+	// it has returned.
+	WriteMetadata(ctx context.Context, metadata []*prompb.MetricMetadata) error
+	// FetchMetadata fetches the metadata of a metric family, or of every
+	// metric family if metric is empty.
+	FetchMetadata(ctx context.Context, metric string) ([]*prompb.MetricMetadata, error)
+}
+
 // SearchResults is the result from a search
 type SearchResults struct {
 	Metrics models.Metrics
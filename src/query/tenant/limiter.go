@@ -0,0 +1,408 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+// Package tenant enforces per tenant query limits and schedules queries
+// fairly across tenants.
+package tenant
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/x/cost"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"github.com/m3db/m3x/instrument"
+	"github.com/uber-go/tally"
+)
+
+// DefaultTenant is the tenant of queries which do not identify one.
+const DefaultTenant = "default"
+
+// Limits are the limits applied to each tenant. Zero or negative values
+// imply no limit.
+type Limits struct {
+	// MaxConcurrentQueries limits the number of queries a tenant can execute
+	// at once, with further queries queued until one completes.
+	MaxConcurrentQueries int
+
+	// MaxFetchedSeries limits the number of series fetched by the executing
+	// queries of a tenant.
+	MaxFetchedSeries int64
+
+	// MaxFetchedDatapoints limits the number of datapoints fetched by the
+	// executing queries of a tenant.
+	MaxFetchedDatapoints int64
+
+	// MaxQueryRange limits the time range of a query.
+	MaxQueryRange time.Duration
+}
+
+// Options are the options for a Limiter.
+type Options struct {
+	// Limits are the limits applied to each tenant.
+	Limits Limits
+
+	// MaxConcurrentQueries limits the number of queries executing at once
+	// across all tenants, with further queries queued and scheduled round
+	// robin across tenants. Zero or negative values imply no limit.
+	MaxConcurrentQueries int
+
+	// Enforcer is the global enforcer which the datapoints fetched by each
+	// tenant roll up to.
+	Enforcer qcost.ChainedEnforcer
+
+	// InstrumentOptions are the instrument options.
+	InstrumentOptions instrument.Options
+}
+
+type limiterMetrics struct {
+	admitted      tally.Counter
+	queued        tally.Counter
+	rangeExceeded tally.Counter
+	queueWait     tally.Timer
+}
+
+func newLimiterMetrics(scope tally.Scope) limiterMetrics {
+	return limiterMetrics{
+		admitted:      scope.Counter("admitted"),
+		queued:        scope.Counter("queued"),
+		rangeExceeded: scope.Counter("range-exceeded"),
+		queueWait:     scope.Timer("queue-wait"),
+	}
+}
+
+// Limiter admits queries subject to the limits of their tenant. Queries
+// which cannot execute yet are queued per tenant, and the queues are served
+// round robin so that a busy tenant cannot starve the others.
+type Limiter struct {
+	sync.Mutex
+
+	limits        Limits
+	maxConcurrent int
+	running       int
+	tenants       map[string]*tenantState
+	// queue holds the tenants with queued queries in round robin order.
+	queue  []*tenantState
+	cursor int
+
+	enforcer             qcost.ChainedEnforcer
+	tenantEnforcer       cost.Enforcer
+	seriesEnforcer       qcost.ChainedEnforcer
+	tenantSeriesEnforcer cost.Enforcer
+	metrics              limiterMetrics
+}
+
+type tenantState struct {
+	name           string
+	running        int
+	waiting        *list.List
+	enforcer       qcost.ChainedEnforcer
+	seriesEnforcer qcost.ChainedEnforcer
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// NewLimiter creates a new tenant limiter.
+func NewLimiter(opts Options) (*Limiter, error) {
+	globalEnforcer := opts.Enforcer
+	if globalEnforcer == nil {
+		globalEnforcer = qcost.NoopChainedEnforcer()
+	}
+
+	iOpts := opts.InstrumentOptions
+	if iOpts == nil {
+		iOpts = instrument.NewOptions()
+	}
+
+	limits := opts.Limits
+	seriesEnforcer, err := qcost.NewChainedEnforcer(qcost.GlobalLevel, []cost.Enforcer{
+		newEnforcer(0, ""),
+		newEnforcer(0, ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Limiter{
+		limits:        limits,
+		maxConcurrent: opts.MaxConcurrentQueries,
+		tenants:       make(map[string]*tenantState),
+		enforcer:      globalEnforcer,
+		tenantEnforcer: newEnforcer(limits.MaxFetchedDatapoints,
+			"limits.perTenant.maxFetchedDatapoints exceeded"),
+		seriesEnforcer: seriesEnforcer,
+		tenantSeriesEnforcer: newEnforcer(limits.MaxFetchedSeries,
+			"limits.perTenant.maxFetchedSeries exceeded"),
+		metrics: newLimiterMetrics(iOpts.MetricsScope().SubScope("tenant-limiter")),
+	}, nil
+}
+
+func newEnforcer(limit int64, msg string) cost.Enforcer {
+	return cost.NewEnforcer(
+		cost.NewStaticLimitManager(cost.NewLimitManagerOptions().SetDefaultLimit(cost.Limit{
+			Threshold: cost.Cost(limit),
+			Enabled:   limit > 0,
+		})),
+		cost.NewTracker(),
+		cost.NewEnforcerOptions().SetCostExceededMessage(msg),
+	)
+}
+
+type admissionKey struct{}
+
+// Admission is a query admitted by the Limiter, which must be closed once
+// the query completes.
+type Admission struct {
+	// Enforcer enforces the datapoints fetched by the query, rolling up to
+	// its tenant and the global enforcer.
+	Enforcer qcost.ChainedEnforcer
+
+	// SeriesEnforcer enforces the series fetched by the query, rolling up to
+	// its tenant.
+	SeriesEnforcer qcost.ChainedEnforcer
+
+	limiter *Limiter
+	tenant  *tenantState
+}
+
+// Close releases the resources held by the query, allowing queued queries
+// to execute.
+func (a *Admission) Close() {
+	a.Enforcer.Close()
+	a.SeriesEnforcer.Close()
+	a.limiter.release(a.tenant)
+}
+
+// NewContext returns a context carrying the admission, queries executed
+// with the context run under it rather than being admitted separately.
+func NewContext(ctx context.Context, admission *Admission) context.Context {
+	return context.WithValue(ctx, admissionKey{}, admission)
+}
+
+// FromContext returns the admission carried by the context, or nil if there
+// is none.
+func FromContext(ctx context.Context) *Admission {
+	admission, _ := ctx.Value(admissionKey{}).(*Admission)
+	return admission
+}
+
+// Admit waits until the query can execute under the limits of its tenant,
+// returning an error if it exceeds them or the context is done first. Requests
+// executing several queries, such as split or partially cached queries, should
+// be admitted once over their whole range.
+func (l *Limiter) Admit(
+	ctx context.Context,
+	params models.RequestParams,
+) (*Admission, error) {
+	if maxRange := l.limits.MaxQueryRange; maxRange > 0 {
+		if queryRange := params.End.Sub(params.Start); queryRange > maxRange {
+			l.metrics.rangeExceeded.Inc(1)
+			return nil, fmt.Errorf("%s: query range %v exceeds the limit of %v "+
+				"(`limits.perTenant.maxQueryRange`)", xhttp.ErrInvalidParams,
+				queryRange, maxRange)
+		}
+	}
+
+	name := params.Tenant
+	if name == "" {
+		name = DefaultTenant
+	}
+
+	l.Lock()
+	t := l.tenant(name)
+	if t.waiting.Len() == 0 && l.canRun(t) {
+		l.start(t)
+		l.Unlock()
+		l.metrics.admitted.Inc(1)
+		return l.newAdmission(t), nil
+	}
+
+	w := &waiter{ready: make(chan struct{})}
+	elem := t.waiting.PushBack(w)
+	if t.waiting.Len() == 1 {
+		l.queue = append(l.queue, t)
+	}
+	l.Unlock()
+
+	l.metrics.queued.Inc(1)
+	start := time.Now()
+	select {
+	case <-w.ready:
+		l.metrics.queueWait.Record(time.Since(start))
+		l.metrics.admitted.Inc(1)
+		return l.newAdmission(t), nil
+	case <-ctx.Done():
+	}
+
+	l.Lock()
+	if w.granted {
+		// Granted concurrently with the context finishing, so give the slot
+		// to the next query.
+		l.Unlock()
+		l.release(t)
+		return nil, ctx.Err()
+	}
+
+	t.waiting.Remove(elem)
+	if t.waiting.Len() == 0 {
+		l.dequeue(t)
+		l.removeIfIdle(t)
+	}
+	l.Unlock()
+	return nil, ctx.Err()
+}
+
+func (l *Limiter) newAdmission(t *tenantState) *Admission {
+	return &Admission{
+		Enforcer:       t.enforcer.Child(qcost.QueryLevel),
+		SeriesEnforcer: t.seriesEnforcer.Child(qcost.QueryLevel),
+		limiter:        l,
+		tenant:         t,
+	}
+}
+
+// tenant returns the state of the tenant, creating it if necessary. Callers
+// must hold the lock.
+func (l *Limiter) tenant(name string) *tenantState {
+	if t, ok := l.tenants[name]; ok {
+		return t
+	}
+
+	t := &tenantState{
+		name:           name,
+		waiting:        list.New(),
+		enforcer:       l.enforcer.ChildWithEnforcer(qcost.TenantLevel, l.tenantEnforcer),
+		seriesEnforcer: l.seriesEnforcer.ChildWithEnforcer(qcost.TenantLevel, l.tenantSeriesEnforcer),
+	}
+	l.tenants[name] = t
+	return t
+}
+
+func (l *Limiter) canRun(t *tenantState) bool {
+	if l.maxConcurrent > 0 && l.running >= l.maxConcurrent {
+		return false
+	}
+
+	maxQueries := l.limits.MaxConcurrentQueries
+	return maxQueries <= 0 || t.running < maxQueries
+}
+
+func (l *Limiter) start(t *tenantState) {
+	t.running++
+	l.running++
+}
+
+func (l *Limiter) release(t *tenantState) {
+	l.Lock()
+	t.running--
+	l.running--
+	l.schedule()
+	l.removeIfIdle(t)
+	l.Unlock()
+}
+
+// schedule starts queued queries while there is capacity, taking one query
+// from each tenant in turn. Callers must hold the lock.
+func (l *Limiter) schedule() {
+	for len(l.queue) > 0 {
+		if l.maxConcurrent > 0 && l.running >= l.maxConcurrent {
+			return
+		}
+
+		scheduled := false
+		for i := 0; i < len(l.queue); i++ {
+			idx := (l.cursor + i) % len(l.queue)
+			t := l.queue[idx]
+			if !l.canRun(t) {
+				continue
+			}
+
+			w := t.waiting.Remove(t.waiting.Front()).(*waiter)
+			w.granted = true
+			l.start(t)
+			close(w.ready)
+
+			if t.waiting.Len() == 0 {
+				// The next tenant moves into this position.
+				l.queue = append(l.queue[:idx], l.queue[idx+1:]...)
+				l.cursor = idx
+			} else {
+				l.cursor = idx + 1
+			}
+
+			if len(l.queue) > 0 {
+				l.cursor %= len(l.queue)
+			} else {
+				l.cursor = 0
+			}
+
+			scheduled = true
+			break
+		}
+
+		if !scheduled {
+			return
+		}
+	}
+}
+
+func (l *Limiter) dequeue(t *tenantState) {
+	for i, queued := range l.queue {
+		if queued != t {
+			continue
+		}
+
+		l.queue = append(l.queue[:i], l.queue[i+1:]...)
+		if i < l.cursor {
+			l.cursor--
+		}
+
+		if len(l.queue) > 0 {
+			l.cursor %= len(l.queue)
+		} else {
+			l.cursor = 0
+		}
+
+		return
+	}
+}
+
+// removeIfIdle forgets tenants without executing or queued queries so that
+// their state does not accumulate. Callers must hold the lock.
+func (l *Limiter) removeIfIdle(t *tenantState) {
+	if t.running > 0 || t.waiting.Len() > 0 {
+		return
+	}
+
+	if l.tenants[t.name] == t {
+		delete(l.tenants, t.name)
+	}
+
+	t.enforcer.Close()
+	t.seriesEnforcer.Close()
+}
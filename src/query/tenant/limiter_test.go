@@ -0,0 +1,260 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package tenant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	qcost "github.com/m3db/m3/src/query/cost"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/x/cost"
+	xhttp "github.com/m3db/m3/src/x/net/http"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(t *testing.T, opts Options) *Limiter {
+	l, err := NewLimiter(opts)
+	require.NoError(t, err)
+	return l
+}
+
+func paramsFor(tenant string) models.RequestParams {
+	now := time.Now()
+	return models.RequestParams{
+		Tenant: tenant,
+		Start:  now.Add(-time.Hour),
+		End:    now,
+	}
+}
+
+// admitAsync admits a query in the background, sending the admission on the
+// returned channel once it is scheduled.
+func admitAsync(
+	ctx context.Context,
+	l *Limiter,
+	tenant string,
+) <-chan *Admission {
+	ch := make(chan *Admission, 1)
+	go func() {
+		a, err := l.Admit(ctx, paramsFor(tenant))
+		if err != nil {
+			close(ch)
+			return
+		}
+
+		ch <- a
+	}()
+
+	return ch
+}
+
+func waitQueued(t *testing.T, l *Limiter, tenant string, n int) {
+	require.True(t, waitFor(func() bool {
+		l.Lock()
+		defer l.Unlock()
+		state, ok := l.tenants[tenant]
+		return ok && state.waiting.Len() == n
+	}), "expected %d queued queries for %s", n, tenant)
+}
+
+func waitFor(fn func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if fn() {
+			return true
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	return false
+}
+
+func TestLimiterRejectsLongQueries(t *testing.T) {
+	l := newTestLimiter(t, Options{
+		Limits: Limits{MaxQueryRange: 30 * time.Minute},
+	})
+
+	_, err := l.Admit(context.Background(), paramsFor("foo"))
+	require.Error(t, err)
+	assert.True(t, xhttp.IsInvalidParams(err))
+}
+
+func TestLimiterQueuesPerTenant(t *testing.T) {
+	l := newTestLimiter(t, Options{
+		Limits: Limits{MaxConcurrentQueries: 1},
+	})
+
+	ctx := context.Background()
+	first, err := l.Admit(ctx, paramsFor("foo"))
+	require.NoError(t, err)
+
+	// Other tenants are not affected by the limit of foo.
+	other, err := l.Admit(ctx, paramsFor("bar"))
+	require.NoError(t, err)
+	other.Close()
+
+	queued := admitAsync(ctx, l, "foo")
+	waitQueued(t, l, "foo", 1)
+
+	first.Close()
+	second, ok := <-queued
+	require.True(t, ok)
+	second.Close()
+
+	// Tenants are forgotten once idle.
+	assert.Empty(t, l.tenants)
+}
+
+func TestLimiterSchedulesRoundRobin(t *testing.T) {
+	l := newTestLimiter(t, Options{MaxConcurrentQueries: 1})
+
+	ctx := context.Background()
+	running, err := l.Admit(ctx, paramsFor("busy"))
+	require.NoError(t, err)
+
+	// The busy tenant queues several queries before the quiet one.
+	var busy []<-chan *Admission
+	for i := 0; i < 3; i++ {
+		busy = append(busy, admitAsync(ctx, l, "busy"))
+		waitQueued(t, l, "busy", i+1)
+	}
+
+	quiet := admitAsync(ctx, l, "quiet")
+	waitQueued(t, l, "quiet", 1)
+
+	// The busy tenant is served first since it queued first, then the
+	// quiet tenant takes its turn ahead of the remaining busy queries.
+	running.Close()
+	waitQueued(t, l, "busy", 2)
+	l.Lock()
+	assert.Equal(t, 1, l.tenants["quiet"].waiting.Len())
+	l.Unlock()
+
+	next := receiveAny(t, busy)
+	next.Close()
+
+	a, ok := <-quiet
+	require.True(t, ok)
+	assert.Equal(t, "quiet", a.tenant.name)
+	a.Close()
+
+	for i := 0; i < 2; i++ {
+		receiveAny(t, busy).Close()
+	}
+
+	assert.Empty(t, l.tenants)
+	assert.Equal(t, 0, l.running)
+}
+
+func receiveAny(t *testing.T, chs []<-chan *Admission) *Admission {
+	deadline := time.After(5 * time.Second)
+	for {
+		for _, ch := range chs {
+			select {
+			case a, ok := <-ch:
+				if ok {
+					return a
+				}
+			default:
+			}
+		}
+
+		select {
+		case <-deadline:
+			require.FailNow(t, "no admission received")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestLimiterCancelsQueuedQueries(t *testing.T) {
+	l := newTestLimiter(t, Options{MaxConcurrentQueries: 1})
+
+	running, err := l.Admit(context.Background(), paramsFor("foo"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := l.Admit(ctx, paramsFor("bar"))
+		errCh <- err
+	}()
+
+	waitQueued(t, l, "bar", 1)
+	cancel()
+	assert.Equal(t, context.Canceled, <-errCh)
+
+	l.Lock()
+	assert.Empty(t, l.queue)
+	_, ok := l.tenants["bar"]
+	l.Unlock()
+	assert.False(t, ok)
+
+	running.Close()
+	assert.Empty(t, l.tenants)
+}
+
+func TestLimiterEnforcesTenantLimits(t *testing.T) {
+	global, err := qcost.NewChainedEnforcer(qcost.GlobalLevel, []cost.Enforcer{
+		newEnforcer(0, ""),
+		newEnforcer(0, ""),
+	})
+	require.NoError(t, err)
+
+	l := newTestLimiter(t, Options{
+		Limits: Limits{
+			MaxFetchedSeries:     10,
+			MaxFetchedDatapoints: 100,
+		},
+		Enforcer: global,
+	})
+
+	ctx := context.Background()
+	first, err := l.Admit(ctx, paramsFor("foo"))
+	require.NoError(t, err)
+	second, err := l.Admit(ctx, paramsFor("foo"))
+	require.NoError(t, err)
+	other, err := l.Admit(ctx, paramsFor("bar"))
+	require.NoError(t, err)
+
+	// The limits apply to the queries of each tenant together.
+	require.NoError(t, first.Enforcer.Add(60).Error)
+	assert.Error(t, second.Enforcer.Add(60).Error)
+	require.NoError(t, other.Enforcer.Add(60).Error)
+
+	require.NoError(t, first.SeriesEnforcer.Add(6).Error)
+	assert.Error(t, second.SeriesEnforcer.Add(6).Error)
+	require.NoError(t, other.SeriesEnforcer.Add(6).Error)
+
+	report, _ := global.State()
+	assert.Equal(t, cost.Cost(180), report.Cost)
+
+	first.Close()
+	second.Close()
+	other.Close()
+
+	report, _ = global.State()
+	assert.Equal(t, cost.Cost(0), report.Cost)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var strict = flag.Bool("compliance.strict", false,
+	"fail on any mismatch with the recorded Prometheus results")
+
+// TestCompliance runs every script under testdata and logs the per-function
+// report. Mismatches only fail the test in strict mode, since the scripts
+// also record known gaps with Prometheus.
+func TestCompliance(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.test")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	var (
+		tagOptions = models.NewTagOptions()
+		runner     = NewRunner(tagOptions)
+		results    []EvalResult
+	)
+
+	for _, path := range paths {
+		script, err := ParseFile(path, tagOptions)
+		require.NoError(t, err)
+
+		scriptResults, err := runner.Run(context.Background(), script)
+		require.NoError(t, err)
+		results = append(results, scriptResults...)
+	}
+
+	report := NewReport(results)
+	var buf bytes.Buffer
+	_, err = report.WriteTo(&buf)
+	require.NoError(t, err)
+	t.Logf("%d of %d evals mismatched:\n%s",
+		report.Mismatches(), len(results), buf.String())
+
+	if !*strict {
+		return
+	}
+
+	for _, result := range results {
+		if !result.Passed() {
+			t.Errorf("%s:%d: %s: %v",
+				result.Script, result.Line, result.Expr, result.Err)
+		}
+	}
+}
+
+func newTestTags(name string, tags ...string) models.Tags {
+	result := models.NewTags(len(tags)/2+1, models.NewTagOptions()).
+		SetName([]byte(name))
+	for i := 0; i < len(tags); i += 2 {
+		result = result.AddTag(models.Tag{
+			Name:  []byte(tags[i]),
+			Value: []byte(tags[i+1]),
+		})
+	}
+
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	a := newTestTags("foo", "a", "1")
+	b := newTestTags("foo", "a", "2")
+	cmd := EvalCommand{Expected: []ExpectedSample{
+		{Tags: a, Value: 1},
+		{Tags: b, Value: math.NaN()},
+	}}
+
+	assert.NoError(t, compare(cmd, []resultSample{
+		{tags: b, value: math.NaN()},
+		{tags: a, value: 1.0000000001},
+	}))
+	assert.Error(t, compare(cmd, []resultSample{{tags: a, value: 1}}))
+	assert.Error(t, compare(cmd, []resultSample{
+		{tags: a, value: 2},
+		{tags: b, value: math.NaN()},
+	}))
+	assert.Error(t, compare(cmd, []resultSample{
+		{tags: a, value: 1},
+		{tags: b, value: math.NaN()},
+		{tags: newTestTags("bar"), value: 1},
+	}))
+
+	// NaN samples of series which are not expected are missing samples.
+	assert.NoError(t, compare(cmd, []resultSample{
+		{tags: newTestTags("bar"), value: math.NaN()},
+		{tags: a, value: 1},
+		{tags: b, value: math.NaN()},
+	}))
+
+	cmd.Ordered = true
+	assert.Error(t, compare(cmd, []resultSample{
+		{tags: b, value: math.NaN()},
+		{tags: a, value: 1},
+	}))
+	assert.NoError(t, compare(cmd, []resultSample{
+		{tags: a, value: 1},
+		{tags: newTestTags("bar"), value: math.NaN()},
+		{tags: b, value: math.NaN()},
+	}))
+
+	scalar := EvalCommand{Expected: []ExpectedSample{{Scalar: true, Value: 3}}}
+	assert.NoError(t, compare(scalar, []resultSample{
+		{tags: models.EmptyTags(), value: 3},
+	}))
+	assert.Error(t, compare(scalar, []resultSample{
+		{tags: models.EmptyTags(), value: 4},
+	}))
+	assert.Error(t, compare(scalar, nil))
+
+	nanScalar := EvalCommand{Expected: []ExpectedSample{{Scalar: true, Value: math.NaN()}}}
+	assert.NoError(t, compare(nanScalar, []resultSample{
+		{tags: models.EmptyTags(), value: math.NaN()},
+	}))
+}
+
+func TestFunctions(t *testing.T) {
+	assert.Equal(t, []string{selectorFunction}, functions(`foo{a="b"}[5m] offset 1m`))
+	assert.Equal(t, []string{"*", "abs", "rate", "sum"},
+		functions(`sum by (a) (abs(rate(foo[5m]))) * 2`))
+	assert.Equal(t, []string{"max_over_time", "subquery", "unary -"},
+		functions(`-max_over_time(foo[5m:1m])`))
+}
+
+func TestReport(t *testing.T) {
+	errMismatch := errors.New("mismatch")
+	report := NewReport([]EvalResult{
+		{Script: "a", Line: 1, Functions: []string{"sum"}},
+		{Script: "a", Line: 2, Functions: []string{"abs", "sum"}, Err: errMismatch},
+		{Script: "b", Line: 1, Functions: []string{"abs"}},
+	})
+
+	require.Len(t, report.Functions, 2)
+	assert.Equal(t, "abs", report.Functions[0].Function)
+	assert.Equal(t, 1, report.Functions[0].Passed)
+	require.Len(t, report.Functions[0].Failed, 1)
+	assert.Equal(t, "sum", report.Functions[1].Function)
+	assert.Equal(t, 1, report.Functions[1].Passed)
+	require.Len(t, report.Functions[1].Failed, 1)
+	assert.Equal(t, 1, report.Mismatches())
+
+	var buf bytes.Buffer
+	_, err := report.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "a:2")
+}
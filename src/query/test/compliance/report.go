@@ -0,0 +1,162 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compliance
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	pql "github.com/prometheus/prometheus/promql"
+)
+
+const selectorFunction = "selector"
+
+// FunctionReport summarizes the evals which use a function or operator.
+type FunctionReport struct {
+	Function string
+	Passed   int
+	Failed   []EvalResult
+}
+
+// Report summarizes eval results by function and operator.
+type Report struct {
+	Functions []FunctionReport
+}
+
+// NewReport groups the results by the functions and operators they use. An
+// eval counts towards each of its functions, so a failure in one function is
+// also visible in the others it is combined with.
+func NewReport(results []EvalResult) Report {
+	byFunction := make(map[string]*FunctionReport)
+	for _, result := range results {
+		for _, fn := range result.Functions {
+			report, ok := byFunction[fn]
+			if !ok {
+				report = &FunctionReport{Function: fn}
+				byFunction[fn] = report
+			}
+
+			if result.Passed() {
+				report.Passed++
+			} else {
+				report.Failed = append(report.Failed, result)
+			}
+		}
+	}
+
+	var report Report
+	for _, fn := range byFunction {
+		report.Functions = append(report.Functions, *fn)
+	}
+
+	sort.Slice(report.Functions, func(i, j int) bool {
+		return report.Functions[i].Function < report.Functions[j].Function
+	})
+
+	return report
+}
+
+// Mismatches returns the number of failed evals across all functions.
+func (r Report) Mismatches() int {
+	seen := make(map[string]struct{})
+	for _, fn := range r.Functions {
+		for _, failed := range fn.Failed {
+			seen[fmt.Sprintf("%s:%d", failed.Script, failed.Line)] = struct{}{}
+		}
+	}
+
+	return len(seen)
+}
+
+// WriteTo writes a summary line per function followed by its mismatches.
+func (r Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, fn := range r.Functions {
+		total := fn.Passed + len(fn.Failed)
+		fmt.Fprintf(&b, "%-24s %4d/%-4d passed\n", fn.Function, fn.Passed, total)
+		for _, failed := range fn.Failed {
+			fmt.Fprintf(&b, "    %s:%d: %s: %v\n",
+				failed.Script, failed.Line, failed.Expr, failed.Err)
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// functions returns the functions and operators used by the expression, or
+// the selector pseudo-function when it uses none.
+func functions(expr string) []string {
+	parsed, err := pql.ParseExpr(expr)
+	if err != nil {
+		// Unparseable queries are accounted for as a whole.
+		return []string{expr}
+	}
+
+	seen := make(map[string]struct{})
+	collectFunctions(parsed, seen)
+	if len(seen) == 0 {
+		return []string{selectorFunction}
+	}
+
+	result := make([]string, 0, len(seen))
+	for fn := range seen {
+		result = append(result, fn)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+func collectFunctions(node pql.Node, seen map[string]struct{}) {
+	switch n := node.(type) {
+	case *pql.AggregateExpr:
+		seen[n.Op.String()] = struct{}{}
+		collectFunctions(n.Expr, seen)
+		if n.Param != nil {
+			collectFunctions(n.Param, seen)
+		}
+
+	case *pql.BinaryExpr:
+		seen[n.Op.String()] = struct{}{}
+		collectFunctions(n.LHS, seen)
+		collectFunctions(n.RHS, seen)
+
+	case *pql.Call:
+		seen[n.Func.Name] = struct{}{}
+		for _, arg := range n.Args {
+			collectFunctions(arg, seen)
+		}
+
+	case *pql.ParenExpr:
+		collectFunctions(n.Expr, seen)
+
+	case *pql.UnaryExpr:
+		seen["unary "+n.Op.String()] = struct{}{}
+		collectFunctions(n.Expr, seen)
+
+	case *pql.SubqueryExpr:
+		seen["subquery"] = struct{}{}
+		collectFunctions(n.Expr, seen)
+	}
+}
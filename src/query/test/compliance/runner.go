@@ -0,0 +1,326 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/executor"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/parser/promql"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	// defaultLookbackDuration matches the Prometheus default staleness delta.
+	defaultLookbackDuration = 5 * time.Minute
+	// epsilon is the relative tolerance used by Prometheus to compare values.
+	epsilon = 0.000001
+)
+
+// EvalResult is the outcome of a single eval command.
+type EvalResult struct {
+	Script    string
+	Line      int
+	Expr      string
+	Functions []string
+	// Err describes the mismatch with the expected result, if any.
+	Err error
+}
+
+// Passed returns whether the eval matched its expected result.
+func (r EvalResult) Passed() bool {
+	return r.Err == nil
+}
+
+// Runner runs test scripts against the query engine.
+type Runner struct {
+	tagOptions models.TagOptions
+	lookback   time.Duration
+}
+
+// NewRunner creates a new runner.
+func NewRunner(tagOptions models.TagOptions) *Runner {
+	return &Runner{
+		tagOptions: tagOptions,
+		lookback:   defaultLookbackDuration,
+	}
+}
+
+// Run runs the script against a fresh in-memory storage and returns the
+// result of each of its evals. An error is returned only if the script could
+// not be loaded.
+func (r *Runner) Run(ctx context.Context, script *Script) ([]EvalResult, error) {
+	var (
+		store   = newMemStorage(r.lookback)
+		engine  = executor.NewEngine(store, tally.NoopScope, r.lookback, nil, nil)
+		results []EvalResult
+	)
+
+	for _, cmd := range script.Commands {
+		switch c := cmd.(type) {
+		case LoadCommand:
+			if err := load(ctx, store, c); err != nil {
+				return nil, fmt.Errorf("%s: %v", script.Name, err)
+			}
+
+		case ClearCommand:
+			store.clear()
+
+		case EvalCommand:
+			results = append(results, EvalResult{
+				Script:    script.Name,
+				Line:      c.Line,
+				Expr:      c.Expr,
+				Functions: functions(c.Expr),
+				Err:       r.eval(ctx, engine, c),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func load(ctx context.Context, store storage.Storage, cmd LoadCommand) error {
+	for _, series := range cmd.Series {
+		var datapoints ts.Datapoints
+		for i, sample := range series.Samples {
+			if sample.Omitted {
+				continue
+			}
+
+			datapoints = append(datapoints, ts.Datapoint{
+				Timestamp: time.Unix(0, 0).Add(time.Duration(i) * cmd.Interval),
+				Value:     sample.Value,
+			})
+		}
+
+		if err := store.Write(ctx, &storage.WriteQuery{
+			Tags:       series.Tags,
+			Datapoints: datapoints,
+			Unit:       xtime.Millisecond,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resultSample is a value of the query result at the eval time, NaN when the
+// series has no value then.
+type resultSample struct {
+	tags  models.Tags
+	value float64
+}
+
+func (r *Runner) eval(
+	ctx context.Context,
+	engine *executor.Engine,
+	cmd EvalCommand,
+) error {
+	samples, err := r.execute(ctx, engine, cmd)
+	if cmd.Fail {
+		if err == nil {
+			return fmt.Errorf("expected error evaluating query, got none")
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error evaluating query: %v", err)
+	}
+
+	return compare(cmd, samples)
+}
+
+func (r *Runner) execute(
+	ctx context.Context,
+	engine *executor.Engine,
+	cmd EvalCommand,
+) ([]resultSample, error) {
+	parser, err := promql.Parse(cmd.Expr, r.tagOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		at      = time.Unix(0, 0).Add(cmd.Time)
+		results = make(chan executor.Query)
+		samples []resultSample
+	)
+
+	go engine.ExecuteExpr(ctx, parser, &executor.EngineOptions{},
+		models.RequestParams{
+			Start:      at,
+			End:        at,
+			Now:        at,
+			Step:       time.Second,
+			Query:      cmd.Expr,
+			IncludeEnd: true,
+		}, results)
+
+	// Drain every channel so that the engine never blocks on a send.
+	for q := range results {
+		if q.Err != nil {
+			err = q.Err
+			continue
+		}
+
+		for res := range q.Result.ResultChan() {
+			if res.Err != nil {
+				err = res.Err
+				continue
+			}
+
+			if err != nil {
+				continue
+			}
+
+			var blockSamples []resultSample
+			blockSamples, err = samplesAt(res.Block, at)
+			samples = append(samples, blockSamples...)
+		}
+	}
+
+	return samples, err
+}
+
+func samplesAt(b block.Block, at time.Time) ([]resultSample, error) {
+	iter, err := b.SeriesIter()
+	if err != nil {
+		return nil, err
+	}
+
+	meta := iter.Meta()
+	offset := at.Sub(meta.Bounds.Start)
+	if offset < 0 || offset >= meta.Bounds.Duration {
+		return nil, nil
+	}
+
+	var (
+		idx     = int(offset / meta.Bounds.StepSize)
+		samples []resultSample
+	)
+
+	// NaN values are kept since blocks do not distinguish a NaN sample from
+	// a missing one, compare ignores the NaN samples that are not expected.
+	for iter.Next() {
+		series := iter.Current()
+		samples = append(samples, resultSample{
+			tags:  meta.Tags.Clone().Add(series.Meta.Tags),
+			value: series.ValueAtStep(idx),
+		})
+	}
+
+	return samples, iter.Err()
+}
+
+// compare checks the samples against the expected result of the command.
+func compare(cmd EvalCommand, samples []resultSample) error {
+	if len(cmd.Expected) == 1 && cmd.Expected[0].Scalar {
+		// Scalars are returned as a single series without tags.
+		if len(samples) != 1 {
+			return fmt.Errorf("expected scalar %v, got %d series",
+				cmd.Expected[0].Value, len(samples))
+		}
+
+		if !almostEqual(cmd.Expected[0].Value, samples[0].value) {
+			return fmt.Errorf("expected scalar %v, got %v",
+				cmd.Expected[0].Value, samples[0].value)
+		}
+
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(cmd.Expected))
+	for _, expected := range cmd.Expected {
+		seen[string(expected.Tags.ID())] = struct{}{}
+	}
+
+	// A NaN sample of a series which is not expected is a missing sample.
+	present := make([]resultSample, 0, len(samples))
+	for _, s := range samples {
+		if _, ok := seen[string(s.tags.ID())]; ok || !math.IsNaN(s.value) {
+			present = append(present, s)
+		}
+	}
+
+	samples = present
+	actual := make(map[string]int, len(samples))
+	for i, s := range samples {
+		actual[string(s.tags.ID())] = i
+	}
+
+	for pos, expected := range cmd.Expected {
+		id := string(expected.Tags.ID())
+		i, ok := actual[id]
+		if !ok {
+			return fmt.Errorf("expected series %s not found", id)
+		}
+
+		if cmd.Ordered && i != pos {
+			return fmt.Errorf("expected series %s at position %d, got %d",
+				id, pos, i)
+		}
+
+		if !almostEqual(expected.Value, samples[i].value) {
+			return fmt.Errorf("expected %v for series %s, got %v",
+				expected.Value, id, samples[i].value)
+		}
+	}
+
+	for _, s := range samples {
+		if _, ok := seen[string(s.tags.ID())]; !ok {
+			return fmt.Errorf("unexpected series %s with value %v",
+				s.tags.ID(), s.value)
+		}
+	}
+
+	return nil
+}
+
+// almostEqual compares values as Prometheus' test framework does.
+func almostEqual(a, b float64) bool {
+	if math.IsNaN(a) && math.IsNaN(b) {
+		return true
+	}
+
+	if a == b {
+		return true
+	}
+
+	diff := math.Abs(a - b)
+	if a == 0 || b == 0 || diff < math.SmallestNonzeroFloat64 {
+		return diff < epsilon*math.SmallestNonzeroFloat64
+	}
+
+	return diff/(math.Abs(a)+math.Abs(b)) < epsilon
+}
@@ -0,0 +1,374 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package compliance runs Prometheus test scripts against the query engine
+// and reports where its results differ from Prometheus.
+package compliance
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql"
+)
+
+var (
+	loadPattern = regexp.MustCompile(`^load\s+(.+)$`)
+	evalPattern = regexp.MustCompile(
+		`^eval(?:_(fail|ordered))?\s+instant\s+(?:at\s+(\S+?))?\s+(.+)$`)
+	rangePattern = regexp.MustCompile(
+		`^([+-]?[^+\-x]+)(?:([+-])([^x]+))?x([0-9]+)$`)
+)
+
+// Script is a parsed Prometheus test script.
+type Script struct {
+	Name     string
+	Commands []Command
+}
+
+// Command is a single command of a test script.
+type Command interface {
+	command()
+}
+
+// LoadCommand loads series sampled at a fixed interval from time zero.
+type LoadCommand struct {
+	Interval time.Duration
+	Series   []LoadedSeries
+}
+
+// LoadedSeries is a series and its samples, one per load interval.
+type LoadedSeries struct {
+	Tags    models.Tags
+	Samples []Sample
+}
+
+// Sample is a single value of a loaded series.
+type Sample struct {
+	Value float64
+	// Omitted is set for values given as "_", which are not written.
+	Omitted bool
+	// Stale is set for staleness markers, which are written as NaNs.
+	Stale bool
+}
+
+// ClearCommand removes all loaded series.
+type ClearCommand struct{}
+
+// EvalCommand evaluates an instant query and checks its result.
+type EvalCommand struct {
+	Line     int
+	Expr     string
+	Time     time.Duration
+	Fail     bool
+	Ordered  bool
+	Expected []ExpectedSample
+}
+
+// ExpectedSample is a value expected in the result of an eval command.
+type ExpectedSample struct {
+	Tags models.Tags
+	// Scalar is set when the expected result is a bare scalar.
+	Scalar bool
+	Value  float64
+}
+
+func (LoadCommand) command()  {}
+func (ClearCommand) command() {}
+func (EvalCommand) command()  {}
+
+// ParseFile parses the test script at the given path.
+func ParseFile(path string, tagOptions models.TagOptions) (*Script, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	return ParseScript(filepath.Base(path), f, tagOptions)
+}
+
+// ParseScript parses a test script in the Prometheus test format.
+func ParseScript(
+	name string,
+	r io.Reader,
+	tagOptions models.TagOptions,
+) (*Script, error) {
+	var (
+		lines   []string
+		scanner = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			line = ""
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	script := &Script{Name: name}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+
+		// Commands own the lines which follow them up to the next empty line.
+		start := i
+		for i+1 < len(lines) && lines[i+1] != "" {
+			i++
+		}
+
+		body := lines[start+1 : i+1]
+		cmd, err := parseCommand(line, body, start+1, tagOptions)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, start+1, err)
+		}
+
+		script.Commands = append(script.Commands, cmd)
+	}
+
+	return script, nil
+}
+
+func parseCommand(
+	line string,
+	body []string,
+	lineNum int,
+	tagOptions models.TagOptions,
+) (Command, error) {
+	if line == "clear" {
+		if len(body) > 0 {
+			return nil, fmt.Errorf("unexpected lines after clear")
+		}
+
+		return ClearCommand{}, nil
+	}
+
+	if m := loadPattern.FindStringSubmatch(line); m != nil {
+		return parseLoad(m[1], body, tagOptions)
+	}
+
+	if m := evalPattern.FindStringSubmatch(line); m != nil {
+		return parseEval(m, body, lineNum, tagOptions)
+	}
+
+	return nil, fmt.Errorf("invalid command: %q", line)
+}
+
+func parseLoad(
+	interval string,
+	body []string,
+	tagOptions models.TagOptions,
+) (Command, error) {
+	step, err := model.ParseDuration(interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid load interval %q: %v", interval, err)
+	}
+
+	cmd := LoadCommand{Interval: time.Duration(step)}
+	for _, line := range body {
+		metric, values := splitMetric(line)
+		tags, err := parseTags(metric, tagOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		samples, err := parseSamples(values)
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.Series = append(cmd.Series, LoadedSeries{
+			Tags:    tags,
+			Samples: samples,
+		})
+	}
+
+	return cmd, nil
+}
+
+func parseEval(
+	match []string,
+	body []string,
+	lineNum int,
+	tagOptions models.TagOptions,
+) (Command, error) {
+	cmd := EvalCommand{
+		Line:    lineNum,
+		Expr:    match[3],
+		Fail:    match[1] == "fail",
+		Ordered: match[1] == "ordered",
+	}
+
+	if match[2] != "" {
+		at, err := model.ParseDuration(match[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid eval time %q: %v", match[2], err)
+		}
+
+		cmd.Time = time.Duration(at)
+	}
+
+	if cmd.Fail && len(body) > 0 {
+		return nil, fmt.Errorf("unexpected results for failing eval")
+	}
+
+	for _, line := range body {
+		// A bare value is an expected scalar result.
+		metric, value := splitMetric(line)
+		v, err := parseValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		expected := ExpectedSample{Value: v, Scalar: metric == ""}
+		if metric != "" {
+			if expected.Tags, err = parseTags(metric, tagOptions); err != nil {
+				return nil, err
+			}
+		}
+
+		cmd.Expected = append(cmd.Expected, expected)
+	}
+
+	return cmd, nil
+}
+
+// splitMetric splits a line into its series description and values. The
+// description is empty when the line holds a single value.
+func splitMetric(line string) (string, string) {
+	if idx := strings.LastIndex(line, "}"); idx >= 0 {
+		return line[:idx+1], strings.TrimSpace(line[idx+1:])
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "", line
+	}
+
+	return fields[0], strings.Join(fields[1:], " ")
+}
+
+func parseTags(metric string, tagOptions models.TagOptions) (models.Tags, error) {
+	labels, err := promql.ParseMetric(metric)
+	if err != nil {
+		return models.EmptyTags(), fmt.Errorf("invalid series %q: %v", metric, err)
+	}
+
+	tags := models.NewTags(len(labels), tagOptions)
+	for _, l := range labels {
+		tags = tags.AddTag(models.Tag{Name: []byte(l.Name), Value: []byte(l.Value)})
+	}
+
+	return tags, nil
+}
+
+// parseSamples expands the series notation of a load command, where "_" is a
+// missing value, "stale" a staleness marker and "a+bxn" n+1 values counting
+// from a in steps of b.
+func parseSamples(values string) ([]Sample, error) {
+	var samples []Sample
+	for _, field := range strings.Fields(values) {
+		switch {
+		case field == "stale":
+			samples = append(samples, Sample{Value: math.NaN(), Stale: true})
+			continue
+		case field == "_":
+			samples = append(samples, Sample{Omitted: true})
+			continue
+		case strings.HasPrefix(field, "_x"):
+			n, err := strconv.Atoi(field[2:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q: %v", field, err)
+			}
+
+			for i := 0; i < n; i++ {
+				samples = append(samples, Sample{Omitted: true})
+			}
+
+			continue
+		}
+
+		m := rangePattern.FindStringSubmatch(field)
+		if m == nil {
+			v, err := parseValue(field)
+			if err != nil {
+				return nil, err
+			}
+
+			samples = append(samples, Sample{Value: v})
+			continue
+		}
+
+		start, err := parseValue(m[1])
+		if err != nil {
+			return nil, err
+		}
+
+		var delta float64
+		if m[3] != "" {
+			if delta, err = parseValue(m[3]); err != nil {
+				return nil, err
+			}
+
+			if m[2] == "-" {
+				delta = -delta
+			}
+		}
+
+		n, err := strconv.Atoi(m[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: %v", field, err)
+		}
+
+		for i := 0; i <= n; i++ {
+			samples = append(samples, Sample{Value: start + float64(i)*delta})
+		}
+	}
+
+	return samples, nil
+}
+
+func parseValue(value string) (float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q: %v", value, err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compliance
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/query/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testScript = `
+# Comments and blank lines are ignored.
+load 5m
+	http_requests{job="api", instance="0"}	0+10x3
+	http_requests{job="api", instance="1"}	1 _ -1-2x1 stale
+	missing	_x2 3x1
+
+eval instant at 10m sum by (job) (http_requests)
+	{job="api"} 20
+
+eval_ordered instant at 10m http_requests
+	http_requests{job="api", instance="0"} 20
+
+eval instant at 1h scalar(missing)
+	3
+
+eval_fail instant at 0m count_values(http_requests, "value")
+
+clear
+`
+
+func TestParseScript(t *testing.T) {
+	tagOptions := models.NewTagOptions()
+	script, err := ParseScript("test", strings.NewReader(testScript), tagOptions)
+	require.NoError(t, err)
+	require.Len(t, script.Commands, 6)
+
+	load, ok := script.Commands[0].(LoadCommand)
+	require.True(t, ok)
+	assert.Equal(t, 5*time.Minute, load.Interval)
+	require.Len(t, load.Series, 3)
+
+	name, ok := load.Series[0].Tags.Name()
+	require.True(t, ok)
+	assert.Equal(t, "http_requests", string(name))
+	instance, ok := load.Series[0].Tags.Get([]byte("instance"))
+	require.True(t, ok)
+	assert.Equal(t, "0", string(instance))
+	assert.Equal(t, []Sample{{Value: 0}, {Value: 10}, {Value: 20}, {Value: 30}},
+		load.Series[0].Samples)
+
+	samples := load.Series[1].Samples
+	require.Len(t, samples, 5)
+	assert.Equal(t, Sample{Value: 1}, samples[0])
+	assert.Equal(t, Sample{Omitted: true}, samples[1])
+	assert.Equal(t, Sample{Value: -1}, samples[2])
+	assert.Equal(t, Sample{Value: -3}, samples[3])
+	assert.True(t, samples[4].Stale)
+	assert.True(t, math.IsNaN(samples[4].Value))
+
+	assert.Equal(t, []Sample{{Omitted: true}, {Omitted: true}, {Value: 3}, {Value: 3}},
+		load.Series[2].Samples)
+
+	eval, ok := script.Commands[1].(EvalCommand)
+	require.True(t, ok)
+	assert.Equal(t, 8, eval.Line)
+	assert.Equal(t, "sum by (job) (http_requests)", eval.Expr)
+	assert.Equal(t, 10*time.Minute, eval.Time)
+	assert.False(t, eval.Fail)
+	assert.False(t, eval.Ordered)
+	require.Len(t, eval.Expected, 1)
+	assert.False(t, eval.Expected[0].Scalar)
+	assert.Equal(t, 20.0, eval.Expected[0].Value)
+	job, ok := eval.Expected[0].Tags.Get([]byte("job"))
+	require.True(t, ok)
+	assert.Equal(t, "api", string(job))
+
+	eval, ok = script.Commands[2].(EvalCommand)
+	require.True(t, ok)
+	assert.True(t, eval.Ordered)
+	require.Len(t, eval.Expected, 1)
+	assert.Equal(t, 3, eval.Expected[0].Tags.Len())
+
+	eval, ok = script.Commands[3].(EvalCommand)
+	require.True(t, ok)
+	assert.Equal(t, time.Hour, eval.Time)
+	require.Len(t, eval.Expected, 1)
+	assert.True(t, eval.Expected[0].Scalar)
+	assert.Equal(t, 3.0, eval.Expected[0].Value)
+
+	eval, ok = script.Commands[4].(EvalCommand)
+	require.True(t, ok)
+	assert.True(t, eval.Fail)
+	assert.Empty(t, eval.Expected)
+
+	_, ok = script.Commands[5].(ClearCommand)
+	assert.True(t, ok)
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	tests := []string{
+		"load foo\n\tfoo 1",
+		"load 5m\n\tfoo 1+x",
+		"load 5m\n\tfoo{ 1",
+		"eval instant at foo bar",
+		"eval_fail instant at 5m foo\n\t{} 1",
+		"evaluate foo",
+		"clear\n\tfoo 1",
+	}
+
+	for _, tt := range tests {
+		_, err := ParseScript("test", strings.NewReader(tt), models.NewTagOptions())
+		assert.Error(t, err, tt)
+	}
+}
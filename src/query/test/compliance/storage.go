@@ -0,0 +1,189 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package compliance
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/query/block"
+	"github.com/m3db/m3/src/query/models"
+	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/ts"
+)
+
+var errCompleteTagsUnsupported = errors.New(
+	"tag completion is not supported by the compliance storage")
+
+type memSeries struct {
+	tags       models.Tags
+	datapoints ts.Datapoints
+}
+
+// memStorage is an in-memory storage holding the series loaded by a script.
+type memStorage struct {
+	sync.RWMutex
+	lookback time.Duration
+	series   map[string]*memSeries
+}
+
+func newMemStorage(lookback time.Duration) *memStorage {
+	return &memStorage{
+		lookback: lookback,
+		series:   make(map[string]*memSeries),
+	}
+}
+
+func (s *memStorage) clear() {
+	s.Lock()
+	s.series = make(map[string]*memSeries)
+	s.Unlock()
+}
+
+// matches returns whether the tags match every matcher, treating absent tags
+// as empty values as Prometheus does.
+func matches(tags models.Tags, matchers models.Matchers) bool {
+	for _, m := range matchers {
+		value, _ := tags.Get(m.Name)
+		if !m.Matches(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matching returns the matching series in ID order.
+func (s *memStorage) matching(matchers models.Matchers) []*memSeries {
+	s.RLock()
+	ids := make([]string, 0, len(s.series))
+	for id, series := range s.series {
+		if matches(series.tags, matchers) {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+	result := make([]*memSeries, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, s.series[id])
+	}
+
+	s.RUnlock()
+	return result
+}
+
+func (s *memStorage) Fetch(
+	_ context.Context,
+	query *storage.FetchQuery,
+	_ *storage.FetchOptions,
+) (*storage.FetchResult, error) {
+	matched := s.matching(query.TagMatchers)
+	seriesList := make(ts.SeriesList, 0, len(matched))
+	for _, series := range matched {
+		var datapoints ts.Datapoints
+		for _, dp := range series.datapoints {
+			if dp.Timestamp.Before(query.Start) || dp.Timestamp.After(query.End) {
+				continue
+			}
+
+			datapoints = append(datapoints, dp)
+		}
+
+		if len(datapoints) == 0 {
+			continue
+		}
+
+		id := series.tags.ID()
+		seriesList = append(seriesList, ts.NewSeries(id, datapoints, series.tags))
+	}
+
+	return &storage.FetchResult{SeriesList: seriesList, LocalOnly: true}, nil
+}
+
+func (s *memStorage) FetchBlocks(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (block.Result, error) {
+	result, err := s.Fetch(ctx, query, options)
+	if err != nil {
+		return block.Result{}, err
+	}
+
+	return storage.FetchResultToBlockResult(result, query, s.lookback,
+		options.Enforcer)
+}
+
+func (s *memStorage) SearchSeries(
+	_ context.Context,
+	query *storage.FetchQuery,
+	_ *storage.FetchOptions,
+) (*storage.SearchResults, error) {
+	matched := s.matching(query.TagMatchers)
+	metrics := make(models.Metrics, 0, len(matched))
+	for _, series := range matched {
+		metrics = append(metrics, models.Metric{
+			ID:   series.tags.ID(),
+			Tags: series.tags,
+		})
+	}
+
+	return &storage.SearchResults{Metrics: metrics}, nil
+}
+
+func (s *memStorage) CompleteTags(
+	context.Context,
+	*storage.CompleteTagsQuery,
+	*storage.FetchOptions,
+) (*storage.CompleteTagsResult, error) {
+	return nil, errCompleteTagsUnsupported
+}
+
+func (s *memStorage) Write(_ context.Context, query *storage.WriteQuery) error {
+	id := string(query.Tags.ID())
+
+	s.Lock()
+	series, ok := s.series[id]
+	if !ok {
+		series = &memSeries{tags: query.Tags}
+		s.series[id] = series
+	}
+
+	series.datapoints = append(series.datapoints, query.Datapoints...)
+	sort.Slice(series.datapoints, func(i, j int) bool {
+		return series.datapoints[i].Timestamp.Before(series.datapoints[j].Timestamp)
+	})
+
+	s.Unlock()
+	return nil
+}
+
+func (s *memStorage) Type() storage.Type {
+	return storage.TypeLocalDC
+}
+
+func (s *memStorage) Close() error {
+	return nil
+}
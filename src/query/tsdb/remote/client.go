@@ -34,6 +34,7 @@ import (
 	"github.com/m3db/m3/src/query/models"
 	"github.com/m3db/m3/src/query/pools"
 	"github.com/m3db/m3/src/query/storage"
+	"github.com/m3db/m3/src/query/storage/m3"
 	"github.com/m3db/m3/src/query/util/logging"
 	xsync "github.com/m3db/m3x/sync"
 
@@ -43,6 +44,7 @@ import (
 // Client is the grpc client
 type Client interface {
 	storage.Querier
+	m3.CompressedFetcher
 	Close() error
 }
 
@@ -113,6 +115,27 @@ func (c *grpcClient) Fetch(
 		true, enforcer, c.tagOptions)
 }
 
+// FetchCompressed reads compressed series from remote client storage
+func (c *grpcClient) FetchCompressed(
+	ctx context.Context,
+	query *storage.FetchQuery,
+	options *storage.FetchOptions,
+) (encoding.SeriesIterators, m3.Cleanup, error) {
+	iters, err := c.fetchRaw(ctx, query, options)
+	if err != nil {
+		return nil, noop, err
+	}
+
+	return iters, func() error {
+		iters.Close()
+		return nil
+	}, nil
+}
+
+func noop() error {
+	return nil
+}
+
 func (c *grpcClient) waitForPools() (encoding.IteratorPools, error) {
 	c.once.Do(func() {
 		c.pools, c.poolErr = c.poolWrapper.WaitForIteratorPools(poolTimeout)